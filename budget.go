@@ -0,0 +1,93 @@
+package templ
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+type renderBudgetContextKey struct{}
+
+// RenderBudget returns a component that renders c with a soft deadline of d.
+// Once the deadline has passed, sections of the page rendered with Fallback
+// write their fallback content instead of waiting for their components, so
+// that the time taken to render the page has an upper bound.
+func RenderBudget(d time.Duration, c Component) Component {
+	return ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		deadline := time.Now().Add(d)
+		if existing, ok := renderBudgetDeadline(ctx); ok && existing.Before(deadline) {
+			deadline = existing
+		}
+		return c.Render(context.WithValue(ctx, renderBudgetContextKey{}, deadline), w)
+	})
+}
+
+func renderBudgetDeadline(ctx context.Context) (deadline time.Time, ok bool) {
+	deadline, ok = ctx.Value(renderBudgetContextKey{}).(time.Time)
+	return deadline, ok
+}
+
+// RenderBudgetExceeded returns true if the deadline set with RenderBudget has
+// passed.
+func RenderBudgetExceeded(ctx context.Context) bool {
+	deadline, ok := renderBudgetDeadline(ctx)
+	return ok && !time.Now().Before(deadline)
+}
+
+// Fallback returns a component that renders c, unless the deadline set with
+// RenderBudget passes first, in which case it renders fallback, e.g. a message
+// that the section is unavailable, or a placeholder that loads it later.
+//
+// Within a render budget, c is rendered concurrently to its own buffer. If the
+// deadline passes first, the context passed to c is cancelled, and its output
+// is discarded. If the deadline has already passed, c isn't rendered.
+// Outside of a render budget, c is rendered as usual.
+func Fallback(c, fallback Component) Component {
+	return ComponentFunc(func(ctx context.Context, w io.Writer) (err error) {
+		deadline, ok := renderBudgetDeadline(ctx)
+		if !ok {
+			return c.Render(ctx, w)
+		}
+		if !time.Now().Before(deadline) {
+			return fallback.Render(ctx, w)
+		}
+		ctx, v := getContext(ctx)
+
+		// c has its own copy of the state used during rendering, which isn't
+		// safe for concurrent use, see Parallel.
+		sctx, cancel := context.WithDeadline(ClearChildren(cloneContextValue(ctx)), deadline)
+		defer cancel()
+		_, sv := getContext(sctx)
+		// The buffer isn't returned to the pool, because c may still be
+		// writing to it if the deadline passes.
+		buf := GetBuffer()
+		done := make(chan error, 1)
+		go func() {
+			defer func() {
+				if r := recover(); r != nil {
+					done <- newPanicError(r)
+				}
+			}()
+			done <- c.Render(sctx, buf)
+		}()
+
+		select {
+		case err = <-done:
+			if err == nil {
+				v.merge(sv)
+				_, err = buf.WriteTo(w)
+				ReleaseBuffer(buf)
+				return err
+			}
+			// Errors caused by the deadline passing are replaced by the fallback.
+			if sctx.Err() == nil {
+				return err
+			}
+		case <-sctx.Done():
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fallback.Render(ctx, w)
+	})
+}
@@ -0,0 +1,104 @@
+package templ_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/a-h/templ"
+)
+
+func TestRenderBudget(t *testing.T) {
+	text := func(s string) templ.Component {
+		return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			_, err := io.WriteString(w, s)
+			return err
+		})
+	}
+	join := func(components ...templ.Component) templ.Component {
+		return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			for _, c := range components {
+				if err := c.Render(ctx, w); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	// slow waits for the context to be cancelled, like a database query that
+	// takes too long.
+	slow := templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	render := func(t *testing.T, c templ.Component) string {
+		t.Helper()
+		w := new(bytes.Buffer)
+		if err := c.Render(context.Background(), w); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return w.String()
+	}
+	t.Run("sections are rendered within the budget", func(t *testing.T) {
+		c := templ.RenderBudget(time.Minute, join(
+			text("<h1>Page</h1>"),
+			templ.Fallback(text("<p>Section</p>"), text("<p>Unavailable</p>")),
+		))
+		if actual := render(t, c); actual != "<h1>Page</h1><p>Section</p>" {
+			t.Errorf("unexpected output: %q", actual)
+		}
+	})
+	t.Run("fallbacks are rendered once the budget is exceeded", func(t *testing.T) {
+		var rendered bool
+		notRendered := templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			rendered = true
+			return nil
+		})
+		c := templ.RenderBudget(10*time.Millisecond, join(
+			templ.Fallback(slow, text("<p>Slow</p>")),
+			templ.Fallback(notRendered, text("<p>Next</p>")),
+		))
+		start := time.Now()
+		if actual := render(t, c); actual != "<p>Slow</p><p>Next</p>" {
+			t.Errorf("unexpected output: %q", actual)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("expected the page to be rendered within the budget, took %v", elapsed)
+		}
+		if rendered {
+			t.Error("expected sections to be skipped once the budget was exceeded")
+		}
+	})
+	t.Run("errors are returned", func(t *testing.T) {
+		expectedErr := errors.New("failed")
+		failing := templ.ComponentFunc(func(ctx context.Context, w io.Writer) error { return expectedErr })
+		err := templ.RenderBudget(time.Minute, templ.Fallback(failing, text("fallback"))).Render(context.Background(), io.Discard)
+		if !errors.Is(err, expectedErr) {
+			t.Errorf("expected %v, got %v", expectedErr, err)
+		}
+	})
+	t.Run("without a budget, the component is rendered", func(t *testing.T) {
+		if actual := render(t, templ.Fallback(text("content"), text("fallback"))); actual != "content" {
+			t.Errorf("unexpected output: %q", actual)
+		}
+	})
+	t.Run("nested budgets can't extend the deadline", func(t *testing.T) {
+		c := templ.RenderBudget(10*time.Millisecond, templ.RenderBudget(time.Minute, templ.Fallback(slow, text("fallback"))))
+		if actual := render(t, c); actual != "fallback" {
+			t.Errorf("unexpected output: %q", actual)
+		}
+	})
+	t.Run("the handler renders fallbacks once the budget is exceeded", func(t *testing.T) {
+		h := templ.Handler(templ.Fallback(slow, text("fallback")), templ.WithRenderBudget(10*time.Millisecond))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		if w.Code != http.StatusOK || w.Body.String() != "fallback" {
+			t.Errorf("unexpected response: %d %q", w.Code, w.Body.String())
+		}
+	})
+}
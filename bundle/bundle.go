@@ -0,0 +1,177 @@
+// Package bundle provides a registry of versioned bundles of components, which
+// can be swapped while a server is running, e.g. to update templates in
+// production without a full redeploy.
+//
+// Bundles are built as Go plugins with templ bundle, and loaded with
+// Registry.Load.
+//
+//	views := bundle.NewRegistry(nil)
+//	if _, err := views.Load("views-v2.so"); err != nil {
+//		log.Fatal(err)
+//	}
+//	http.Handle("/", templ.Handler(views.Render("Home")))
+package bundle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"plugin"
+	"reflect"
+	"sort"
+	"sync/atomic"
+
+	"github.com/a-h/templ"
+)
+
+// Symbol is the name of the *Bundle variable exported by bundle plugins.
+const Symbol = "Bundle"
+
+// Bundle is a version of a set of components.
+type Bundle struct {
+	// Version of the bundle, e.g. a commit hash.
+	Version string
+	// Components are the functions that create each component, keyed by name,
+	// e.g. "Home": views.Home.
+	Components map[string]any
+}
+
+// Names returns the names of the components in the bundle, in order.
+func (b *Bundle) Names() (names []string) {
+	for name := range b.Components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var componentType = reflect.TypeOf((*templ.Component)(nil)).Elem()
+
+// Validate returns an error if the bundle has no version, or if any of its
+// components aren't functions that return a templ.Component.
+func (b *Bundle) Validate() error {
+	if b.Version == "" {
+		return errors.New("bundle: version is missing")
+	}
+	var errs []error
+	for _, name := range b.Names() {
+		t := reflect.TypeOf(b.Components[name])
+		if t == nil || t.Kind() != reflect.Func || t.NumOut() != 1 || t.Out(0) != componentType {
+			errs = append(errs, fmt.Errorf("bundle: component %q is a %v, not a function that returns a templ.Component", name, t))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Registry holds the current bundle. It's safe for concurrent use.
+type Registry struct {
+	current atomic.Pointer[Bundle]
+}
+
+// NewRegistry creates a registry with the initial bundle, which may be nil.
+func NewRegistry(initial *Bundle) *Registry {
+	r := &Registry{}
+	if initial != nil {
+		r.current.Store(initial)
+	}
+	return r
+}
+
+// Current returns the current bundle, or nil if no bundle has been loaded.
+func (r *Registry) Current() *Bundle {
+	return r.current.Load()
+}
+
+// Swap validates the bundle, and makes it the current bundle. Renders that
+// are in progress complete with the previous bundle.
+func (r *Registry) Swap(b *Bundle) (previous *Bundle, err error) {
+	if b == nil {
+		return nil, errors.New("bundle: bundle is nil")
+	}
+	if err = b.Validate(); err != nil {
+		return nil, err
+	}
+	return r.current.Swap(b), nil
+}
+
+// Load opens the Go plugin at path, built with templ bundle, and swaps its
+// bundle in. A plugin can't be unloaded, so each version of a bundle remains
+// in memory until the program exits.
+func (r *Registry) Load(path string) (previous *Bundle, err error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("bundle: failed to open plugin: %w", err)
+	}
+	sym, err := p.Lookup(Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("bundle: failed to find bundle in plugin: %w", err)
+	}
+	b, ok := sym.(**Bundle)
+	if !ok {
+		return nil, fmt.Errorf("bundle: %s in plugin is a %T, not a *bundle.Bundle", Symbol, sym)
+	}
+	return r.Swap(*b)
+}
+
+// Component creates the named component of the current bundle with the args.
+func (r *Registry) Component(name string, args ...any) (templ.Component, error) {
+	b := r.current.Load()
+	if b == nil {
+		return nil, errors.New("bundle: no bundle has been loaded")
+	}
+	f, ok := b.Components[name]
+	if !ok {
+		return nil, fmt.Errorf("bundle: component %q not found in version %s", name, b.Version)
+	}
+	in, err := callArgs(reflect.TypeOf(f), args)
+	if err != nil {
+		return nil, fmt.Errorf("bundle: component %q of version %s: %w", name, b.Version, err)
+	}
+	c, _ := reflect.ValueOf(f).Call(in)[0].Interface().(templ.Component)
+	if c == nil {
+		return nil, fmt.Errorf("bundle: component %q of version %s returned nil", name, b.Version)
+	}
+	return c, nil
+}
+
+// Render returns a component that renders the named component of the bundle
+// that's current when it's rendered, e.g. to pass to templ.Handler.
+func (r *Registry) Render(name string, args ...any) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		c, err := r.Component(name, args...)
+		if err != nil {
+			return err
+		}
+		return c.Render(ctx, w)
+	})
+}
+
+// callArgs converts args to the parameters of the function type t. A nil arg
+// is the zero value of its parameter.
+func callArgs(t reflect.Type, args []any) (in []reflect.Value, err error) {
+	n := t.NumIn()
+	if t.IsVariadic() {
+		if len(args) < n-1 {
+			return nil, fmt.Errorf("expected at least %d args, got %d", n-1, len(args))
+		}
+	} else if len(args) != n {
+		return nil, fmt.Errorf("expected %d args, got %d", n, len(args))
+	}
+	for i, arg := range args {
+		pt := t.In(min(i, n-1))
+		if t.IsVariadic() && i >= n-1 {
+			pt = pt.Elem()
+		}
+		if arg == nil {
+			in = append(in, reflect.Zero(pt))
+			continue
+		}
+		v := reflect.ValueOf(arg)
+		if !v.Type().AssignableTo(pt) {
+			return nil, fmt.Errorf("arg %d is a %v, expected %v", i, v.Type(), pt)
+		}
+		in = append(in, v)
+	}
+	return in, nil
+}
@@ -0,0 +1,150 @@
+package bundle
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+)
+
+func hello(greeting string) func(name string) templ.Component {
+	return func(name string) templ.Component {
+		return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			_, err := fmt.Fprintf(w, "<p>%s %s</p>", greeting, name)
+			return err
+		})
+	}
+}
+
+func list(items ...string) templ.Component {
+	return templ.Raw("<ul><li>" + strings.Join(items, "</li><li>") + "</li></ul>")
+}
+
+func render(t *testing.T, c templ.Component) string {
+	t.Helper()
+	var sb strings.Builder
+	if err := c.Render(context.Background(), &sb); err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+	return sb.String()
+}
+
+func TestRegistry(t *testing.T) {
+	v1 := &Bundle{Version: "v1", Components: map[string]any{"Hello": hello("Hello"), "List": list}}
+	v2 := &Bundle{Version: "v2", Components: map[string]any{"Hello": hello("Hi")}}
+
+	t.Run("components are rendered with the current bundle", func(t *testing.T) {
+		r := NewRegistry(v1)
+		page := r.Render("Hello", "World")
+		if actual := render(t, page); actual != "<p>Hello World</p>" {
+			t.Errorf("unexpected output: %q", actual)
+		}
+		previous, err := r.Swap(v2)
+		if err != nil {
+			t.Fatalf("failed to swap: %v", err)
+		}
+		if previous != v1 {
+			t.Errorf("expected the previous bundle to be v1, got %v", previous)
+		}
+		if actual := render(t, page); actual != "<p>Hi World</p>" {
+			t.Errorf("unexpected output: %q", actual)
+		}
+	})
+	t.Run("variadic args are passed", func(t *testing.T) {
+		c, err := NewRegistry(v1).Component("List", "a", "b")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if actual := render(t, c); actual != "<ul><li>a</li><li>b</li></ul>" {
+			t.Errorf("unexpected output: %q", actual)
+		}
+	})
+
+	errorTests := []struct {
+		name        string
+		registry    *Registry
+		component   string
+		args        []any
+		expectedErr string
+	}{
+		{
+			name:        "no bundle",
+			registry:    NewRegistry(nil),
+			component:   "Hello",
+			args:        []any{"World"},
+			expectedErr: "no bundle has been loaded",
+		},
+		{
+			name:        "missing component",
+			registry:    NewRegistry(v2),
+			component:   "List",
+			expectedErr: `component "List" not found in version v2`,
+		},
+		{
+			name:        "wrong number of args",
+			registry:    NewRegistry(v1),
+			component:   "Hello",
+			expectedErr: "expected 1 args, got 0",
+		},
+		{
+			name:        "wrong type of arg",
+			registry:    NewRegistry(v1),
+			component:   "Hello",
+			args:        []any{1},
+			expectedErr: "arg 0 is a int, expected string",
+		},
+	}
+	for _, tt := range errorTests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.registry.Component(tt.component, tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Fatalf("expected error %q, got %v", tt.expectedErr, err)
+			}
+			err = tt.registry.Render(tt.component, tt.args...).Render(context.Background(), io.Discard)
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Fatalf("expected render error %q, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestSwapValidatesBundle(t *testing.T) {
+	tests := []struct {
+		name        string
+		bundle      *Bundle
+		expectedErr string
+	}{
+		{
+			name:        "nil",
+			expectedErr: "bundle is nil",
+		},
+		{
+			name:        "missing version",
+			bundle:      &Bundle{},
+			expectedErr: "version is missing",
+		},
+		{
+			name:        "components must be functions that return a component",
+			bundle:      &Bundle{Version: "v1", Components: map[string]any{"Hello": "<p>Hello</p>"}},
+			expectedErr: `component "Hello" is a string`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			current := &Bundle{Version: "v0"}
+			r := NewRegistry(current)
+			_, err := r.Swap(tt.bundle)
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Fatalf("expected error %q, got %v", tt.expectedErr, err)
+			}
+			if r.Current() != current {
+				t.Errorf("expected the current bundle to be unchanged")
+			}
+		})
+	}
+}
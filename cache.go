@@ -0,0 +1,436 @@
+package templ
+
+import (
+	"container/list"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// MemoryCache is an in-memory cache of rendered fragments, i.e. the output
+// of components. When the total size of the cached fragments exceeds the
+// maximum, the least recently used fragments are evicted.
+//
+// Concurrent renders of a fragment that isn't in the cache are combined, so
+// that the component is only rendered once.
+type MemoryCache struct {
+	maxBytes  int64
+	fragments *CachedFragments
+
+	m       sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	size    int64
+
+	evictions atomic.Int64
+}
+
+var _ FragmentCache = &MemoryCache{}
+
+type memoryCacheEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// CacheStats are the metrics of a fragment cache.
+type CacheStats struct {
+	// Hits is the number of fragments written from the cache.
+	Hits int64
+	// Misses is the number of fragments that weren't in the cache.
+	Misses int64
+	// Shared is the number of misses that waited for another render of the
+	// same fragment, instead of rendering it.
+	Shared int64
+	// Errors is the number of failed reads from, and writes to, the cache.
+	Errors int64
+	// Stale is the number of stale fragments written while the component was
+	// re-rendered in the background.
+	Stale int64
+	// Refreshes is the number of background renders of stale fragments,
+	// including failed renders.
+	Refreshes int64
+	// Evictions is the number of fragments removed from a MemoryCache to stay
+	// within the maximum size.
+	Evictions int64
+	// Entries is the number of fragments in a MemoryCache.
+	Entries int
+	// Bytes is the total size of the fragments in a MemoryCache.
+	Bytes int64
+}
+
+// NewMemoryCache creates a cache that holds up to maxBytes of rendered fragments.
+func NewMemoryCache(maxBytes int64) *MemoryCache {
+	c := &MemoryCache{
+		maxBytes: maxBytes,
+		entries:  map[string]*list.Element{},
+		lru:      list.New(),
+	}
+	c.fragments = NewCachedFragments(c)
+	return c
+}
+
+// Fragment returns a component that writes the cached output of the
+// component for the key, or renders it and caches its output for the ttl. A
+// ttl of zero caches the output until it's evicted. See CachedFragments.
+func (c *MemoryCache) Fragment(key string, ttl time.Duration, component Component) Component {
+	return c.fragments.Fragment(key, ttl, component)
+}
+
+// StaleWhileRevalidate returns a component that writes the cached output of
+// the component for the key, and re-renders it in the background once it's
+// older than fresh. See CachedFragments.StaleWhileRevalidate.
+func (c *MemoryCache) StaleWhileRevalidate(key string, fresh, stale time.Duration, component Component) Component {
+	return c.fragments.StaleWhileRevalidate(key, fresh, stale, component)
+}
+
+// Get returns the fragment for the key.
+func (c *MemoryCache) Get(ctx context.Context, key string) (value []byte, ok bool, err error) {
+	value, ok = c.get(key)
+	return value, ok, nil
+}
+
+// Set stores the fragment for the key.
+func (c *MemoryCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	c.set(key, value, ttl)
+	return nil
+}
+
+// Delete removes the fragment for the key from the cache.
+func (c *MemoryCache) Delete(key string) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if e, ok := c.entries[key]; ok {
+		c.remove(e)
+	}
+}
+
+// Stats returns the metrics of the cache.
+func (c *MemoryCache) Stats() CacheStats {
+	stats := c.fragments.Stats()
+	c.m.Lock()
+	stats.Entries, stats.Bytes = len(c.entries), c.size
+	c.m.Unlock()
+	stats.Evictions = c.evictions.Load()
+	return stats
+}
+
+func (c *MemoryCache) get(key string) (value []byte, ok bool) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := e.Value.(*memoryCacheEntry)
+	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
+		c.remove(e)
+		return nil, false
+	}
+	c.lru.MoveToFront(e)
+	return entry.value, true
+}
+
+func (c *MemoryCache) set(key string, value []byte, ttl time.Duration) {
+	if int64(len(value)) > c.maxBytes {
+		return
+	}
+	entry := &memoryCacheEntry{key: key, value: value}
+	if ttl > 0 {
+		entry.expires = time.Now().Add(ttl)
+	}
+	c.m.Lock()
+	defer c.m.Unlock()
+	if e, ok := c.entries[key]; ok {
+		c.remove(e)
+	}
+	c.entries[key] = c.lru.PushFront(entry)
+	c.size += int64(len(value))
+	for c.size > c.maxBytes {
+		c.remove(c.lru.Back())
+		c.evictions.Add(1)
+	}
+}
+
+func (c *MemoryCache) remove(e *list.Element) {
+	entry := c.lru.Remove(e).(*memoryCacheEntry)
+	delete(c.entries, entry.key)
+	c.size -= int64(len(entry.value))
+}
+
+// FragmentCache stores rendered fragments, e.g. in memory, or in a cache that's
+// shared between instances of an app, such as Redis.
+type FragmentCache interface {
+	// Get returns the fragment for the key, or ok=false if it's not in the cache.
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	// Set stores the fragment for the key for the ttl. A ttl of zero stores
+	// the fragment until it's evicted.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// WithCacheVersion returns a cache that prefixes keys with the version, e.g.
+// the commit hash of the app, so that fragments rendered by other versions of
+// the app are not used.
+func WithCacheVersion(cache FragmentCache, version string) FragmentCache {
+	return versionedCache{cache: cache, prefix: version + ":"}
+}
+
+type versionedCache struct {
+	cache  FragmentCache
+	prefix string
+}
+
+func (vc versionedCache) Get(ctx context.Context, key string) (value []byte, ok bool, err error) {
+	return vc.cache.Get(ctx, vc.prefix+key)
+}
+
+func (vc versionedCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return vc.cache.Set(ctx, vc.prefix+key, value, ttl)
+}
+
+// CachedFragments renders fragments, i.e. the output of components, through a
+// FragmentCache. Concurrent renders of a fragment that isn't in the cache are
+// combined, so that the component is only rendered once by each instance of
+// the app.
+//
+// If the cache returns an error, the component is rendered as if the fragment
+// wasn't cached, and the error is counted in the stats.
+type CachedFragments struct {
+	cache      FragmentCache
+	group      fragmentGroup
+	refreshing sync.Map
+
+	hits      atomic.Int64
+	misses    atomic.Int64
+	shared    atomic.Int64
+	errors    atomic.Int64
+	stale     atomic.Int64
+	refreshes atomic.Int64
+}
+
+// NewCachedFragments creates a CachedFragments that stores fragments in the cache.
+func NewCachedFragments(cache FragmentCache) *CachedFragments {
+	return &CachedFragments{
+		cache: cache,
+	}
+}
+
+// Fragment returns a component that writes the cached output of the
+// component for the key, or renders it and caches its output for the ttl.
+//
+// Errors are not cached. Cached output is written as is, so components that
+// are cached should not depend on being rendered with a particular context,
+// e.g. they shouldn't use templ.Once or CSS components. If the context has a
+// CSP nonce set with WithNonce, the component is rendered without the cache,
+// since the output would contain the nonce of a single request.
+func (cf *CachedFragments) Fragment(key string, ttl time.Duration, component Component) Component {
+	return ComponentFunc(func(ctx context.Context, w io.Writer) (err error) {
+		if GetNonce(ctx) != "" {
+			return component.Render(ctx, w)
+		}
+		value, ok, err := cf.cache.Get(ctx, key)
+		if err != nil {
+			cf.errors.Add(1)
+		}
+		if ok {
+			cf.hit(ctx)
+			_, err = w.Write(value)
+			return err
+		}
+		cf.miss(ctx)
+		value, shared, err := cf.group.do(ctx, key, func(ctx context.Context) ([]byte, error) {
+			buf := GetBuffer()
+			defer ReleaseBuffer(buf)
+			if err := component.Render(ctx, buf); err != nil {
+				return nil, err
+			}
+			value := append([]byte(nil), buf.Bytes()...)
+			if err := cf.cache.Set(ctx, key, value, ttl); err != nil {
+				cf.errors.Add(1)
+			}
+			return value, nil
+		})
+		if shared {
+			cf.shared.Add(1)
+		}
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(value)
+		return err
+	})
+}
+
+// StaleWhileRevalidate returns a component that writes the cached output of
+// the component for the key, or renders it and caches its output.
+//
+// Once the output is older than fresh, it's stale. Stale output is written
+// immediately, and the component is re-rendered in the background to update
+// the cache. Once the output is older than fresh+stale, it's rendered as if
+// it wasn't cached. A stale duration of zero writes stale output until it's
+// evicted.
+//
+// The background render uses a copy of the context that isn't cancelled when
+// the request ends. If it fails, the stale output continues to be written
+// until it expires. The cached output includes the time it becomes stale, so
+// keys must not be shared with Fragment.
+//
+// As with Fragment, if the context has a CSP nonce, the component is rendered
+// without the cache.
+func (cf *CachedFragments) StaleWhileRevalidate(key string, fresh, stale time.Duration, component Component) Component {
+	var ttl time.Duration
+	if stale > 0 {
+		ttl = fresh + stale
+	}
+	render := func(ctx context.Context) ([]byte, error) {
+		buf := GetBuffer()
+		defer ReleaseBuffer(buf)
+		if err := component.Render(ctx, buf); err != nil {
+			return nil, err
+		}
+		// The time that the output becomes stale is stored before the output.
+		value := make([]byte, 8, 8+buf.Len())
+		binary.BigEndian.PutUint64(value, uint64(time.Now().Add(fresh).UnixNano()))
+		value = append(value, buf.Bytes()...)
+		if err := cf.cache.Set(ctx, key, value, ttl); err != nil {
+			cf.errors.Add(1)
+		}
+		return value, nil
+	}
+	return ComponentFunc(func(ctx context.Context, w io.Writer) (err error) {
+		if GetNonce(ctx) != "" {
+			return component.Render(ctx, w)
+		}
+		value, ok, err := cf.cache.Get(ctx, key)
+		if err != nil {
+			cf.errors.Add(1)
+		}
+		if ok && len(value) >= 8 {
+			cf.hit(ctx)
+			staleAt := time.Unix(0, int64(binary.BigEndian.Uint64(value)))
+			if time.Now().After(staleAt) {
+				cf.stale.Add(1)
+				cf.refresh(ctx, key, render)
+			}
+			_, err = w.Write(value[8:])
+			return err
+		}
+		cf.miss(ctx)
+		value, shared, err := cf.group.do(ctx, key, render)
+		if shared {
+			cf.shared.Add(1)
+		}
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(value[8:])
+		return err
+	})
+}
+
+// refresh calls render in the background, unless a refresh of the key is
+// already in progress.
+func (cf *CachedFragments) refresh(ctx context.Context, key string, render func(ctx context.Context) ([]byte, error)) {
+	if _, loaded := cf.refreshing.LoadOrStore(key, struct{}{}); loaded {
+		return
+	}
+	// The background render has its own copy of the state used during
+	// rendering, and isn't included in render traces, render hooks, or render
+	// stats, because the request may have ended.
+	ctx = cloneContextValue(context.WithoutCancel(ctx))
+	ctx = context.WithValue(ctx, renderTraceContextKey{}, nil)
+	ctx = context.WithValue(ctx, renderHooksContextKey{}, nil)
+	_, v := getContext(ctx)
+	v.stats = nil
+	go func() {
+		defer cf.refreshes.Add(1)
+		defer cf.refreshing.Delete(key)
+		defer func() {
+			// A panic is treated like an error, and the stale output is kept.
+			_ = recover()
+		}()
+		_, _, _ = cf.group.do(ctx, key, render)
+	}()
+}
+
+// hit records a cache hit in the stats, and the render stats of the context.
+func (cf *CachedFragments) hit(ctx context.Context) {
+	cf.hits.Add(1)
+	if s := getRenderStats(ctx); s != nil {
+		s.cacheHits.Add(1)
+	}
+}
+
+// miss records a cache miss in the stats, and the render stats of the context.
+func (cf *CachedFragments) miss(ctx context.Context) {
+	cf.misses.Add(1)
+	if s := getRenderStats(ctx); s != nil {
+		s.cacheMisses.Add(1)
+	}
+}
+
+// Stats returns the hits, misses, shared renders, errors, and stale fragments.
+func (cf *CachedFragments) Stats() CacheStats {
+	return CacheStats{
+		Hits:      cf.hits.Load(),
+		Misses:    cf.misses.Load(),
+		Shared:    cf.shared.Load(),
+		Errors:    cf.errors.Load(),
+		Stale:     cf.stale.Load(),
+		Refreshes: cf.refreshes.Load(),
+	}
+}
+
+var errFragmentPanicked = errors.New("templ: cached component panicked")
+
+// fragmentGroup combines concurrent renders of the same fragment.
+type fragmentGroup struct {
+	m     sync.Mutex
+	calls map[string]*fragmentCall
+}
+
+type fragmentCall struct {
+	done  chan struct{}
+	value []byte
+	err   error
+}
+
+// do calls f, unless a call for the key is in progress, in which case it
+// waits for that call and returns its result, with shared set to true. If
+// the context is cancelled while waiting, the context's error is returned.
+//
+// Since the result is shared, f is called with a copy of the context that
+// isn't cancelled when the caller's context is, so that the waiting calls
+// don't fail if the request that started the render ends.
+func (g *fragmentGroup) do(ctx context.Context, key string, f func(ctx context.Context) ([]byte, error)) (value []byte, shared bool, err error) {
+	g.m.Lock()
+	if g.calls == nil {
+		g.calls = map[string]*fragmentCall{}
+	}
+	if call, ok := g.calls[key]; ok {
+		g.m.Unlock()
+		select {
+		case <-call.done:
+			return call.value, true, call.err
+		case <-ctx.Done():
+			return nil, true, ctx.Err()
+		}
+	}
+	// If f panics, the waiting calls return an error.
+	call := &fragmentCall{done: make(chan struct{}), err: errFragmentPanicked}
+	g.calls[key] = call
+	g.m.Unlock()
+
+	defer func() {
+		g.m.Lock()
+		delete(g.calls, key)
+		g.m.Unlock()
+		close(call.done)
+	}()
+	call.value, call.err = f(context.WithoutCancel(ctx))
+	return call.value, false, call.err
+}
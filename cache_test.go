@@ -0,0 +1,314 @@
+package templ_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMemoryCache(t *testing.T) {
+	counter := func(renders *atomic.Int64, text string) templ.Component {
+		return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			renders.Add(1)
+			_, err := io.WriteString(w, text)
+			return err
+		})
+	}
+	render := func(t *testing.T, c templ.Component) string {
+		t.Helper()
+		w := new(bytes.Buffer)
+		if err := c.Render(context.Background(), w); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return w.String()
+	}
+	t.Run("fragments are rendered once, and then written from the cache", func(t *testing.T) {
+		cache := templ.NewMemoryCache(1024)
+		var renders atomic.Int64
+		for i := 0; i < 3; i++ {
+			if actual := render(t, cache.Fragment("a", 0, counter(&renders, "<p>a</p>"))); actual != "<p>a</p>" {
+				t.Errorf("unexpected output: %q", actual)
+			}
+		}
+		if renders.Load() != 1 {
+			t.Errorf("expected 1 render, got %d", renders.Load())
+		}
+		expected := templ.CacheStats{Hits: 2, Misses: 1, Entries: 1, Bytes: 8}
+		if diff := cmp.Diff(expected, cache.Stats()); diff != "" {
+			t.Error(diff)
+		}
+	})
+	t.Run("concurrent renders of a fragment are combined", func(t *testing.T) {
+		cache := templ.NewMemoryCache(1024)
+		var renders atomic.Int64
+		release := make(chan struct{})
+		slow := templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			renders.Add(1)
+			<-release
+			_, err := io.WriteString(w, "slow")
+			return err
+		})
+		var wg sync.WaitGroup
+		outputs := make([]string, 10)
+		for i := range outputs {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				w := new(bytes.Buffer)
+				if err := cache.Fragment("slow", 0, slow).Render(context.Background(), w); err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				outputs[i] = w.String()
+			}(i)
+		}
+		// Wait for the requests to start before the render completes.
+		for cache.Stats().Misses < int64(len(outputs)) {
+			time.Sleep(time.Millisecond)
+		}
+		close(release)
+		wg.Wait()
+		if renders.Load() != 1 {
+			t.Errorf("expected 1 render, got %d", renders.Load())
+		}
+		if strings.Join(outputs, "") != strings.Repeat("slow", len(outputs)) {
+			t.Errorf("unexpected outputs: %v", outputs)
+		}
+		if stats := cache.Stats(); stats.Shared != int64(len(outputs)-1) {
+			t.Errorf("expected %d shared renders, got %d", len(outputs)-1, stats.Shared)
+		}
+	})
+	t.Run("shared renders aren't cancelled when the first request ends", func(t *testing.T) {
+		cache := templ.NewMemoryCache(1024)
+		started, release := make(chan struct{}), make(chan struct{})
+		slow := templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			close(started)
+			<-release
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			_, err := io.WriteString(w, "slow")
+			return err
+		})
+		ctx, cancel := context.WithCancel(context.Background())
+		first := make(chan error)
+		go func() {
+			first <- cache.Fragment("slow", 0, slow).Render(ctx, io.Discard)
+		}()
+		<-started
+		second := make(chan string)
+		go func() {
+			w := new(bytes.Buffer)
+			if err := cache.Fragment("slow", 0, slow).Render(context.Background(), w); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			second <- w.String()
+		}()
+		// Wait for the second request to start waiting for the render.
+		for cache.Stats().Misses < 2 {
+			time.Sleep(time.Millisecond)
+		}
+		cancel()
+		close(release)
+		if err := <-first; err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if actual := <-second; actual != "slow" {
+			t.Errorf("expected the shared output, got %q", actual)
+		}
+	})
+	t.Run("the least recently used fragments are evicted", func(t *testing.T) {
+		cache := templ.NewMemoryCache(10)
+		var renders atomic.Int64
+		render(t, cache.Fragment("a", 0, counter(&renders, "aaaa")))
+		render(t, cache.Fragment("b", 0, counter(&renders, "bbbb")))
+		render(t, cache.Fragment("a", 0, counter(&renders, "aaaa")))
+		render(t, cache.Fragment("c", 0, counter(&renders, "cccc")))
+		// b was evicted, a and c are cached.
+		render(t, cache.Fragment("a", 0, counter(&renders, "aaaa")))
+		render(t, cache.Fragment("c", 0, counter(&renders, "cccc")))
+		if renders.Load() != 3 {
+			t.Errorf("expected 3 renders, got %d", renders.Load())
+		}
+		render(t, cache.Fragment("b", 0, counter(&renders, "bbbb")))
+		if renders.Load() != 4 {
+			t.Errorf("expected b to be rendered again, got %d renders", renders.Load())
+		}
+		if stats := cache.Stats(); stats.Evictions != 2 || stats.Bytes != 8 {
+			t.Errorf("unexpected stats: %+v", stats)
+		}
+		t.Run("fragments larger than the cache aren't cached", func(t *testing.T) {
+			render(t, cache.Fragment("large", 0, counter(&renders, strings.Repeat("x", 11))))
+			if cache.Stats().Bytes != 8 {
+				t.Errorf("unexpected stats: %+v", cache.Stats())
+			}
+		})
+	})
+	t.Run("fragments expire after the ttl", func(t *testing.T) {
+		cache := templ.NewMemoryCache(1024)
+		var renders atomic.Int64
+		render(t, cache.Fragment("a", time.Millisecond, counter(&renders, "a")))
+		time.Sleep(5 * time.Millisecond)
+		render(t, cache.Fragment("a", time.Millisecond, counter(&renders, "a")))
+		if renders.Load() != 2 {
+			t.Errorf("expected 2 renders, got %d", renders.Load())
+		}
+	})
+	t.Run("errors aren't cached", func(t *testing.T) {
+		cache := templ.NewMemoryCache(1024)
+		expectedErr := errors.New("failed")
+		failing := templ.ComponentFunc(func(ctx context.Context, w io.Writer) error { return expectedErr })
+		for i := 0; i < 2; i++ {
+			if err := cache.Fragment("a", 0, failing).Render(context.Background(), io.Discard); !errors.Is(err, expectedErr) {
+				t.Errorf("expected %v, got %v", expectedErr, err)
+			}
+		}
+		if stats := cache.Stats(); stats.Misses != 2 || stats.Entries != 0 {
+			t.Errorf("unexpected stats: %+v", stats)
+		}
+	})
+	t.Run("fragments are rendered without the cache if the context has a nonce", func(t *testing.T) {
+		cache := templ.NewMemoryCache(1024)
+		script := templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			_, err := fmt.Fprintf(w, "<script%s></script>", templ.NonceAttribute(ctx))
+			return err
+		})
+		for _, nonce := range []string{"a", "b"} {
+			w := new(bytes.Buffer)
+			if err := cache.Fragment("script", 0, script).Render(templ.WithNonce(context.Background(), nonce), w); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if expected := `<script nonce="` + nonce + `"></script>`; w.String() != expected {
+				t.Errorf("expected %q, got %q", expected, w.String())
+			}
+		}
+		if stats := cache.Stats(); stats.Entries != 0 {
+			t.Errorf("expected no cached fragments, got %+v", stats)
+		}
+	})
+}
+
+func TestStaleWhileRevalidate(t *testing.T) {
+	// versioned returns a component that writes the number of times it's been rendered.
+	versioned := func() templ.Component {
+		var renders atomic.Int64
+		return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			_, err := fmt.Fprintf(w, "v%d", renders.Add(1))
+			return err
+		})
+	}
+	render := func(t *testing.T, c templ.Component) string {
+		t.Helper()
+		w := new(bytes.Buffer)
+		if err := c.Render(context.Background(), w); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return w.String()
+	}
+	waitForRefreshes := func(cache *templ.MemoryCache, n int64) {
+		for cache.Stats().Refreshes < n {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	t.Run("fresh fragments are written from the cache", func(t *testing.T) {
+		cache := templ.NewMemoryCache(1024)
+		v := versioned()
+		for i := 0; i < 3; i++ {
+			if actual := render(t, cache.StaleWhileRevalidate("a", time.Hour, time.Hour, v)); actual != "v1" {
+				t.Errorf("unexpected output: %q", actual)
+			}
+		}
+		if stats := cache.Stats(); stats.Hits != 2 || stats.Misses != 1 || stats.Stale != 0 {
+			t.Errorf("unexpected stats: %+v", stats)
+		}
+	})
+	t.Run("stale fragments are written, and re-rendered in the background", func(t *testing.T) {
+		cache := templ.NewMemoryCache(1024)
+		c := cache.StaleWhileRevalidate("a", 0, time.Hour, versioned())
+		if actual := render(t, c); actual != "v1" {
+			t.Errorf("unexpected output: %q", actual)
+		}
+		if actual := render(t, c); actual != "v1" {
+			t.Errorf("expected the stale output, got %q", actual)
+		}
+		waitForRefreshes(cache, 1)
+		if actual := render(t, c); actual != "v2" {
+			t.Errorf("expected the refreshed output, got %q", actual)
+		}
+		if stats := cache.Stats(); stats.Stale != 2 || stats.Misses != 1 {
+			t.Errorf("unexpected stats: %+v", stats)
+		}
+	})
+	t.Run("fragments older than fresh+stale are rendered", func(t *testing.T) {
+		cache := templ.NewMemoryCache(1024)
+		c := cache.StaleWhileRevalidate("a", time.Millisecond, time.Millisecond, versioned())
+		render(t, c)
+		time.Sleep(5 * time.Millisecond)
+		if actual := render(t, c); actual != "v2" {
+			t.Errorf("unexpected output: %q", actual)
+		}
+		if stats := cache.Stats(); stats.Misses != 2 || stats.Stale != 0 {
+			t.Errorf("unexpected stats: %+v", stats)
+		}
+	})
+	t.Run("the stale fragment is kept if the background render fails", func(t *testing.T) {
+		cache := templ.NewMemoryCache(1024)
+		var fail atomic.Bool
+		c := cache.StaleWhileRevalidate("a", 0, time.Hour, templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			if fail.Load() {
+				panic("failed")
+			}
+			_, err := io.WriteString(w, "ok")
+			return err
+		}))
+		render(t, c)
+		fail.Store(true)
+		render(t, c)
+		waitForRefreshes(cache, 1)
+		if actual := render(t, c); actual != "ok" {
+			t.Errorf("expected the stale output, got %q", actual)
+		}
+	})
+	t.Run("fragments are rendered without the cache if the context has a nonce", func(t *testing.T) {
+		cache := templ.NewMemoryCache(1024)
+		c := cache.StaleWhileRevalidate("a", time.Hour, time.Hour, versioned())
+		render(t, c)
+		w := new(bytes.Buffer)
+		if err := c.Render(templ.WithNonce(context.Background(), "nonce"), w); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if w.String() != "v2" {
+			t.Errorf("expected the component to be rendered, got %q", w.String())
+		}
+		if actual := render(t, c); actual != "v1" {
+			t.Errorf("expected the cached output, got %q", actual)
+		}
+	})
+	t.Run("the background render isn't cancelled when the request ends", func(t *testing.T) {
+		cache := templ.NewMemoryCache(1024)
+		var cancelled atomic.Bool
+		c := cache.StaleWhileRevalidate("a", 0, time.Hour, templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			cancelled.Store(ctx.Err() != nil)
+			return nil
+		}))
+		render(t, c)
+		ctx, cancel := context.WithCancel(context.Background())
+		if err := c.Render(ctx, io.Discard); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		cancel()
+		waitForRefreshes(cache, 1)
+		if cancelled.Load() {
+			t.Error("expected the background render's context not to be cancelled")
+		}
+	})
+}
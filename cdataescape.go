@@ -0,0 +1,10 @@
+package templ
+
+import "strings"
+
+// EscapeCDATA escapes s for use within a CDATA section, e.g.
+// <![CDATA[ {{ post.Body }} ]]>, by splitting the section at each ]]>, so
+// that the value can't end the section.
+func EscapeCDATA(s string) string {
+	return strings.ReplaceAll(s, "]]>", "]]]]><![CDATA[>")
+}
@@ -0,0 +1,40 @@
+package templ_test
+
+import (
+	"testing"
+
+	"github.com/a-h/templ"
+)
+
+func TestEscapeCDATA(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "text is unchanged",
+			input:    "<p>Hello & goodbye</p>",
+			expected: "<p>Hello & goodbye</p>",
+		},
+		{
+			name:     "the end of the section is split",
+			input:    "a]]>b",
+			expected: "a]]]]><![CDATA[>b",
+		},
+		{
+			name:     "each end is split",
+			input:    "]]>]]>",
+			expected: "]]]]><![CDATA[>]]]]><![CDATA[>",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			actual := templ.EscapeCDATA(tt.input)
+			if actual != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package bundlecmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/a-h/templ/cmd/templ/generatecmd/modcheck"
+	"github.com/a-h/templ/cmd/templ/previewcmd"
+	"golang.org/x/mod/modfile"
+)
+
+type Arguments struct {
+	// Path of the package that contains the components.
+	Path string
+	// Version of the bundle, e.g. a commit hash.
+	Version string
+	// Output is the file name of the plugin.
+	Output string
+}
+
+func Run(ctx context.Context, w io.Writer, args Arguments) (err error) {
+	if args.Version == "" {
+		return errors.New("a version is required")
+	}
+	components, err := previewcmd.Discover(args.Path)
+	if err != nil {
+		return fmt.Errorf("failed to discover components: %w", err)
+	}
+	// Only the exported components of the package in the path can be bundled.
+	var pkg []previewcmd.Component
+	for _, c := range components {
+		if c.Dir == "." && token.IsExported(c.Name) {
+			pkg = append(pkg, c)
+		}
+	}
+	if len(pkg) == 0 {
+		return fmt.Errorf("no exported components found in %s, run templ generate to update the generated code", args.Path)
+	}
+	b, err := newBuild(args.Path, args.Version)
+	if err != nil {
+		return err
+	}
+	output := args.Output
+	if output == "" {
+		output = pkg[0].Package + "-" + sanitize(args.Version) + ".so"
+	}
+	if output, err = filepath.Abs(output); err != nil {
+		return err
+	}
+	if err = b.run(ctx, pkg, output); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Bundled %d components of version %s to %s\n", len(pkg), args.Version, output)
+	return nil
+}
+
+// build of a bundle plugin.
+//
+// A Go program can't load two plugins that contain different versions of the
+// same package, so each version of the package is built with its own import
+// path, in a directory that only exists in the Go tool's -overlay.
+type build struct {
+	// moduleDir is the directory that contains the go.mod file.
+	moduleDir string
+	// packageDir is the directory of the package that's bundled.
+	packageDir string
+	// bundleDir is the directory that the package is copied to.
+	bundleDir string
+	// bundleImportPath is the import path of the copy of the package.
+	bundleImportPath string
+	version          string
+}
+
+func newBuild(dir, version string) (b build, err error) {
+	b.version = version
+	if b.packageDir, err = filepath.Abs(dir); err != nil {
+		return b, err
+	}
+	if b.moduleDir, err = modcheck.WalkUp(b.packageDir); err != nil {
+		return b, err
+	}
+	modFileName := filepath.Join(b.moduleDir, "go.mod")
+	m, err := os.ReadFile(modFileName)
+	if err != nil {
+		return b, fmt.Errorf("failed to read go.mod file: %w", err)
+	}
+	modulePath := modfile.ModulePath(m)
+	if modulePath == "" {
+		return b, fmt.Errorf("failed to find module path in %s", modFileName)
+	}
+	rel, err := filepath.Rel(b.moduleDir, b.packageDir)
+	if err != nil {
+		return b, err
+	}
+	versionDir := "templ_bundle_" + sanitize(version)
+	b.bundleDir = filepath.Join(b.moduleDir, versionDir, rel)
+	b.bundleImportPath = path.Join(modulePath, versionDir, filepath.ToSlash(rel))
+	return b, nil
+}
+
+var unsafeVersionCharacters = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// sanitize the version, so that it can be used in an import path.
+func sanitize(version string) string {
+	return unsafeVersionCharacters.ReplaceAllString(version, "_")
+}
+
+var mainTemplate = template.Must(template.New("main").Parse(`// Code generated by templ bundle. DO NOT EDIT.
+
+package main
+
+import (
+	"github.com/a-h/templ/bundle"
+
+	views {{ printf "%q" .ImportPath }}
+)
+
+var Bundle = &bundle.Bundle{
+	Version: {{ printf "%q" .Version }},
+	Components: map[string]any{
+{{- range .Components }}
+		{{ printf "%q" .Name }}: views.{{ .Name }},
+{{- end }}
+	},
+}
+
+func main() {}
+`))
+
+// mainSource returns the source of the plugin's main package, which exports
+// the bundle.
+func (b build) mainSource(components []previewcmd.Component) (src []byte, err error) {
+	data := struct {
+		ImportPath string
+		Version    string
+		Components []previewcmd.Component
+	}{
+		ImportPath: b.bundleImportPath,
+		Version:    b.version,
+		Components: components,
+	}
+	var buf bytes.Buffer
+	if err = mainTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// overlay returns the Go tool overlay that adds the copy of the package, and
+// the plugin's main package, which is read from mainFileName.
+func (b build) overlay(mainFileName string) (overlay map[string]string, err error) {
+	entries, err := os.ReadDir(b.packageDir)
+	if err != nil {
+		return nil, err
+	}
+	overlay = map[string]string{
+		filepath.Join(b.bundleDir, "templ_bundle", "main.go"): mainFileName,
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		overlay[filepath.Join(b.bundleDir, name)] = filepath.Join(b.packageDir, name)
+	}
+	return overlay, nil
+}
+
+// run builds the plugin to the output file.
+func (b build) run(ctx context.Context, components []previewcmd.Component, output string) (err error) {
+	src, err := b.mainSource(components)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.MkdirTemp("", "templ-bundle-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	mainFileName := filepath.Join(tmp, "main.go")
+	if err = os.WriteFile(mainFileName, src, 0o644); err != nil {
+		return err
+	}
+	replace, err := b.overlay(mainFileName)
+	if err != nil {
+		return err
+	}
+	overlay, err := json.Marshal(map[string]any{"Replace": replace})
+	if err != nil {
+		return err
+	}
+	overlayFileName := filepath.Join(tmp, "overlay.json")
+	if err = os.WriteFile(overlayFileName, overlay, 0o644); err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, "go", "build", "-buildmode=plugin", "-overlay", overlayFileName, "-o", output, b.bundleImportPath+"/templ_bundle")
+	cmd.Dir = b.moduleDir
+	buildOutput, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to build plugin: %w\n%s", err, strings.TrimSpace(string(buildOutput)))
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package bundlecmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ/cmd/templ/previewcmd"
+)
+
+func TestBuild(t *testing.T) {
+	moduleDir := t.TempDir()
+	packageDir := filepath.Join(moduleDir, "internal", "views")
+	if err := os.MkdirAll(packageDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		filepath.Join(moduleDir, "go.mod"):               "module example.com/site\n",
+		filepath.Join(packageDir, "views_templ.go"):      "package views\n",
+		filepath.Join(packageDir, "views.templ"):         "package views\n",
+		filepath.Join(packageDir, "views_templ_test.go"): "package views\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	b, err := newBuild(packageDir, "v1.2.3")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "example.com/site/templ_bundle_v1_2_3/internal/views"; b.bundleImportPath != expected {
+		t.Errorf("expected import path %q, got %q", expected, b.bundleImportPath)
+	}
+
+	t.Run("the overlay copies the package's Go files, and adds the plugin", func(t *testing.T) {
+		overlay, err := b.overlay("/tmp/main.go")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		bundleDir := filepath.Join(moduleDir, "templ_bundle_v1_2_3", "internal", "views")
+		expected := map[string]string{
+			filepath.Join(bundleDir, "views_templ.go"):          filepath.Join(packageDir, "views_templ.go"),
+			filepath.Join(bundleDir, "templ_bundle", "main.go"): "/tmp/main.go",
+		}
+		if len(overlay) != len(expected) {
+			t.Fatalf("expected %v, got %v", expected, overlay)
+		}
+		for k, v := range expected {
+			if overlay[k] != v {
+				t.Errorf("expected %s to be replaced by %s, got %q", k, v, overlay[k])
+			}
+		}
+	})
+	t.Run("the plugin exports the bundle", func(t *testing.T) {
+		src, err := b.mainSource([]previewcmd.Component{{Name: "Home"}, {Name: "List"}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, expected := range []string{
+			`views "example.com/site/templ_bundle_v1_2_3/internal/views"`,
+			`Version: "v1.2.3",`,
+			`"Home": views.Home,`,
+			`"List": views.List,`,
+		} {
+			if !strings.Contains(string(src), expected) {
+				t.Errorf("expected source to contain %q, got:\n%s", expected, src)
+			}
+		}
+	})
+}
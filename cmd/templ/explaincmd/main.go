@@ -0,0 +1,220 @@
+package explaincmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	goparser "go/parser"
+	"go/token"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/a-h/parse"
+	"github.com/a-h/templ/generator"
+	"github.com/a-h/templ/parser/v2"
+)
+
+type Arguments struct {
+	// FileName of the templ file to explain, or empty to read from stdin.
+	FileName string
+	// Line of the templ file to explain, or zero to explain the whole file.
+	Line int
+}
+
+// ParseTarget parses a "file.templ[:line]" argument.
+func ParseTarget(target string) (fileName string, line int, err error) {
+	fileName = target
+	if i := strings.LastIndex(target, ":"); i > 0 {
+		if line, err = strconv.Atoi(target[i+1:]); err == nil {
+			if line < 1 {
+				return "", 0, fmt.Errorf("invalid line %d, lines start at 1", line)
+			}
+			return target[:i], line, nil
+		}
+	}
+	return fileName, 0, nil
+}
+
+// Run prints the Go code generated for the templ file, with a marker after each
+// line of Go code that shows the position of the templ code it was generated from.
+func Run(stdin io.Reader, stdout io.Writer, args Arguments) (err error) {
+	var src []byte
+	name := "stdin"
+	if args.FileName == "" || args.FileName == "-" {
+		src, err = io.ReadAll(stdin)
+	} else {
+		src, err = os.ReadFile(args.FileName)
+		name = filepath.Base(args.FileName)
+	}
+	if err != nil {
+		return err
+	}
+	s := newSnippet(string(src))
+	tf, err := parser.ParseString(s.source)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, s.adjustError(err))
+	}
+	var buf bytes.Buffer
+	sm, _, err := generator.Generate(tf, &buf, generator.WithFileName(name))
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, s.adjustError(err))
+	}
+	goCode := buf.Bytes()
+	// Formatting the code doesn't change the line numbers in the source map.
+	if formatted, fmtErr := format.Source(goCode); fmtErr == nil {
+		goCode = formatted
+	}
+	e := explanation{
+		name:    name,
+		snippet: s,
+		lines:   strings.Split(strings.TrimSuffix(string(goCode), "\n"), "\n"),
+		sm:      sm,
+	}
+	if args.Line == 0 {
+		return e.write(stdout, 0, len(e.lines)-1, 0)
+	}
+	from, to, err := e.declarationOf(goCode, args.Line)
+	if err != nil {
+		return err
+	}
+	srcLines := strings.Split(string(src), "\n")
+	if args.Line > len(srcLines) {
+		return fmt.Errorf("%s has %d lines", name, len(srcLines))
+	}
+	if _, err = fmt.Fprintf(stdout, "// %s:%d\n%s\n\n", name, args.Line, srcLines[args.Line-1]); err != nil {
+		return err
+	}
+	return e.write(stdout, from, to, args.Line)
+}
+
+var declaration = regexp.MustCompile(`(?m)^\s*(templ|css|script)\s`)
+
+// snippet is templ source code that may have been completed with a package
+// declaration and a template, so that a fragment of templ code can be explained.
+type snippet struct {
+	source string
+	// offset is the number of lines added before the input.
+	offset int
+}
+
+func newSnippet(src string) (s snippet) {
+	s.source = src
+	if !declaration.MatchString(src) {
+		s.source = "templ snippet() {\n" + s.source + "\n}\n"
+		s.offset++
+	}
+	if !strings.HasPrefix(strings.TrimSpace(src), "package ") && !strings.Contains(src, "\npackage ") {
+		s.source = "package main\n\n" + s.source
+		s.offset += 2
+	}
+	return s
+}
+
+// adjustError updates the line numbers of parse errors to match the input.
+func (s snippet) adjustError(err error) error {
+	var pe parse.ParseError
+	if s.offset == 0 || !errors.As(err, &pe) {
+		return err
+	}
+	pe.Pos.Line -= min(s.offset, pe.Pos.Line)
+	return pe
+}
+
+type explanation struct {
+	name    string
+	snippet snippet
+	// lines of generated Go code.
+	lines []string
+	sm    *parser.SourceMap
+}
+
+// sourceOf returns the 1-based line and column of the templ code that the
+// 0-based Go line was generated from.
+func (e explanation) sourceOf(goLine int) (line, col int, ok bool) {
+	cols, ok := e.sm.TargetLinesToSource[uint32(goLine)]
+	if !ok {
+		return 0, 0, false
+	}
+	minCol := uint32(math.MaxUint32)
+	for c := range cols {
+		minCol = min(minCol, c)
+	}
+	pos := cols[minCol]
+	line = int(pos.Line) + 1 - e.snippet.offset
+	if line < 1 {
+		// The position is in code that was added to complete the snippet.
+		return 0, 0, false
+	}
+	return line, int(pos.Col) + 1, true
+}
+
+// declarationOf returns the 0-based range of Go lines of the declaration that
+// contains the code generated from the 1-based templ line.
+func (e explanation) declarationOf(goCode []byte, line int) (from, to int, err error) {
+	// Static content isn't in the source map, so use the closest preceding
+	// templ line that contains an expression.
+	target := -1
+	for l := line - 1 + e.snippet.offset; l >= 0 && target < 0; l-- {
+		for _, pos := range e.sm.SourceLinesToTarget[uint32(l)] {
+			if target < 0 || int(pos.Line) < target {
+				target = int(pos.Line)
+			}
+		}
+	}
+	if target < 0 {
+		return 0, 0, fmt.Errorf("%s:%d: no Go code is generated for the line", e.name, line)
+	}
+	fset := token.NewFileSet()
+	f, err := goparser.ParseFile(fset, "", goCode, goparser.SkipObjectResolution)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to parse generated code: %w", err)
+	}
+	for _, d := range f.Decls {
+		from, to = fset.Position(d.Pos()).Line-1, fset.Position(d.End()).Line-1
+		if from <= target && target <= to {
+			return from, to, nil
+		}
+	}
+	return target, target, nil
+}
+
+// write the Go lines from and to (inclusive) to w. Lines generated from the
+// highlighted templ line are prefixed with ">".
+func (e explanation) write(w io.Writer, from, to, highlight int) (err error) {
+	// Expand tabs so that the markers line up.
+	code := make([]string, 0, to-from+1)
+	var width int
+	for i := from; i <= to; i++ {
+		c := strings.ReplaceAll(e.lines[i], "\t", "    ")
+		code = append(code, c)
+		if _, _, ok := e.sourceOf(i); ok {
+			width = max(width, len(c))
+		}
+	}
+	for i, c := range code {
+		var sb strings.Builder
+		line, col, ok := e.sourceOf(from + i)
+		if highlight > 0 {
+			if ok && line == highlight {
+				sb.WriteString("> ")
+			} else {
+				sb.WriteString("  ")
+			}
+		}
+		sb.WriteString(c)
+		if ok {
+			sb.WriteString(strings.Repeat(" ", width-len(c)))
+			sb.WriteString(fmt.Sprintf("  // %s:%d:%d", e.name, line, col))
+		}
+		if _, err = io.WriteString(w, strings.TrimRight(sb.String(), " ")+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
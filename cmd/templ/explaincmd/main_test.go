@@ -0,0 +1,95 @@
+package explaincmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		target           string
+		expectedFileName string
+		expectedLine     int
+		expectedErr      bool
+	}{
+		{target: "", expectedFileName: ""},
+		{target: "header.templ", expectedFileName: "header.templ"},
+		{target: "header.templ:12", expectedFileName: "header.templ", expectedLine: 12},
+		{target: `C:\templates\header.templ:3`, expectedFileName: `C:\templates\header.templ`, expectedLine: 3},
+		{target: "header.templ:0", expectedErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.target, func(t *testing.T) {
+			fileName, line, err := ParseTarget(test.target)
+			if test.expectedErr != (err != nil) {
+				t.Fatalf("expected error %v, got %v", test.expectedErr, err)
+			}
+			if fileName != test.expectedFileName || line != test.expectedLine {
+				t.Errorf("expected %q, %d, got %q, %d", test.expectedFileName, test.expectedLine, fileName, line)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Run("snippets are completed with a package and template", func(t *testing.T) {
+		var stdout bytes.Buffer
+		err := Run(strings.NewReader(`<a href={ url }>Link</a>`), &stdout, Arguments{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := "var templ_7745c5c3_Var2 templ.SafeURL = url  // stdin:1:11\n"
+		if !strings.Contains(stdout.String(), expected) {
+			t.Errorf("expected %q in output:\n%s", expected, stdout.String())
+		}
+	})
+	t.Run("the code generated from a line is marked", func(t *testing.T) {
+		src := `package main
+
+templ header(title string) {
+	<h1>{ title }</h1>
+}
+
+templ footer() {
+	<footer></footer>
+}
+`
+		var stdout bytes.Buffer
+		err := Run(strings.NewReader(src), &stdout, Arguments{Line: 4})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		output := stdout.String()
+		for _, expected := range []string{
+			"// stdin:4\n\t<h1>{ title }</h1>\n\n",
+			"  func header(title string) templ.Component {",
+			"> ",
+		} {
+			if !strings.Contains(output, expected) {
+				t.Errorf("expected %q in output:\n%s", expected, output)
+			}
+		}
+		if strings.Contains(output, "func footer") {
+			t.Errorf("expected only the header template in output:\n%s", output)
+		}
+		var marked []string
+		for _, line := range strings.Split(output, "\n") {
+			if strings.HasPrefix(line, "> ") {
+				marked = append(marked, line)
+			}
+		}
+		if len(marked) != 1 || !strings.Contains(marked[0], "templ.JoinStringErrs(title)") || !strings.HasSuffix(marked[0], "// stdin:4:8") {
+			t.Errorf("expected the title expression to be marked, got %q", marked)
+		}
+	})
+	t.Run("parse errors in snippets refer to the input lines", func(t *testing.T) {
+		err := Run(strings.NewReader("<div>\n<p>{ </p>\n</div>"), &bytes.Buffer{}, Arguments{})
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+		if !strings.Contains(err.Error(), "line 1") {
+			t.Errorf("expected the error to refer to line 1 of the input, got %v", err)
+		}
+	})
+}
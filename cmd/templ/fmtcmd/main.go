@@ -19,12 +19,14 @@ type Arguments struct {
 	ToStdout    bool
 	Files       []string
 	WorkerCount int
+	// Options configure the output of the formatter.
+	Options parser.FormatOptions
 }
 
 func Run(log *slog.Logger, stdin io.Reader, stdout io.Writer, args Arguments) (err error) {
 	// If no files are provided, read from stdin and write to stdout.
 	if len(args.Files) == 0 {
-		return format(writeToWriter(stdout), readFromReader(stdin))
+		return format(writeToWriter(stdout), readFromReader(stdin), args.Options)
 	}
 	process := func(fileName string) error {
 		read := readFromFile(fileName)
@@ -32,7 +34,7 @@ func Run(log *slog.Logger, stdin io.Reader, stdout io.Writer, args Arguments) (e
 		if args.ToStdout {
 			write = writeToWriter(stdout)
 		}
-		return format(write, read)
+		return format(write, read, args.Options)
 	}
 	dir := args.Files[0]
 	return NewFormatter(log, dir, process, args.WorkerCount).Run()
@@ -119,7 +121,7 @@ func writeToFile(fileName, tgt string) error {
 	return atomic.WriteFile(fileName, bytes.NewBufferString(tgt))
 }
 
-func format(write writer, read reader) (err error) {
+func format(write writer, read reader, opts parser.FormatOptions) (err error) {
 	fileName, src, err := read()
 	if err != nil {
 		return err
@@ -129,7 +131,7 @@ func format(write writer, read reader) (err error) {
 		return err
 	}
 	w := new(bytes.Buffer)
-	if err = t.Write(w); err != nil {
+	if err = t.WriteWithOptions(w, opts); err != nil {
 		return fmt.Errorf("formatting error: %w", err)
 	}
 	return write(fileName, w.String())
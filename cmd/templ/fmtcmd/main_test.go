@@ -10,6 +10,7 @@ import (
 	"strings"
 	"testing"
 
+	parser "github.com/a-h/templ/parser/v2"
 	"github.com/google/go-cmp/cmp"
 	"golang.org/x/tools/txtar"
 )
@@ -112,4 +113,33 @@ func TestFormat(t *testing.T) {
 			t.Error(diff)
 		}
 	})
+	t.Run("applies format options", func(t *testing.T) {
+		stdin := strings.NewReader(`package test
+
+templ a() {
+	<a href="https://example.com" class="link">Link</a>
+}
+`)
+		expected := `package test
+
+templ a() {
+	<a
+		href="https://example.com"
+		class="link"
+	>Link</a>
+}
+`
+		stdout := new(strings.Builder)
+		if err := Run(log, stdin, stdout, Arguments{
+			ToStdout: true,
+			Options: parser.FormatOptions{
+				MaxLineWidth: 40,
+			},
+		}); err != nil {
+			t.Fatalf("failed to run format command: %v", err)
+		}
+		if diff := cmp.Diff(expected, stdout.String()); diff != "" {
+			t.Error(diff)
+		}
+	})
 }
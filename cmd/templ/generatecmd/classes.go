@@ -0,0 +1,86 @@
+package generatecmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// classCollector collects the class names of each templ file, and writes
+// them all to a single file, so that Tailwind can scan it instead of the
+// generated Go code.
+type classCollector struct {
+	fileName string
+
+	m       sync.Mutex
+	classes map[string][]string
+	written []byte
+}
+
+func newClassCollector(fileName string) *classCollector {
+	return &classCollector{
+		fileName: fileName,
+		classes:  map[string][]string{},
+	}
+}
+
+// Set the class names of a templ file.
+func (c *classCollector) Set(templFileName string, classes []string) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.classes[templFileName] = classes
+}
+
+// Delete the class names of a templ file that has been removed.
+func (c *classCollector) Delete(templFileName string) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	delete(c.classes, templFileName)
+}
+
+// Write the sorted class names of all of the templ files to the file, if
+// they've changed since it was last written. If the file name ends with
+// .json, the classes are written as a JSON array, otherwise they're written
+// one per line.
+func (c *classCollector) Write() (err error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	var classes []string
+	for _, fileClasses := range c.classes {
+		classes = append(classes, fileClasses...)
+	}
+	slices.Sort(classes)
+	classes = slices.Compact(classes)
+	var contents []byte
+	if strings.EqualFold(filepath.Ext(c.fileName), ".json") {
+		if classes == nil {
+			classes = []string{}
+		}
+		if contents, err = json.MarshalIndent(classes, "", "  "); err != nil {
+			return err
+		}
+		contents = append(contents, '\n')
+	} else {
+		for _, class := range classes {
+			contents = append(contents, class+"\n"...)
+		}
+	}
+	if c.written != nil && bytes.Equal(contents, c.written) {
+		return nil
+	}
+	if dir := filepath.Dir(c.fileName); dir != "" {
+		if err = os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	if err = os.WriteFile(c.fileName, contents, 0o644); err != nil {
+		return fmt.Errorf("failed to write class names to %q: %w", c.fileName, err)
+	}
+	c.written = contents
+	return nil
+}
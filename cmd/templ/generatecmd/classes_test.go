@@ -0,0 +1,46 @@
+package generatecmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestClassCollector(t *testing.T) {
+	t.Run("classes are written one per line", func(t *testing.T) {
+		fileName := filepath.Join(t.TempDir(), "tailwind", "tailwind-content.txt")
+		c := newClassCollector(fileName)
+		c.Set("a.templ", []string{"flex", "p-4"})
+		c.Set("b.templ", []string{"flex", "mt-2"})
+		if err := c.Write(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		assertFileContents(t, fileName, "flex\nmt-2\np-4\n")
+
+		c.Delete("b.templ")
+		if err := c.Write(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		assertFileContents(t, fileName, "flex\np-4\n")
+	})
+	t.Run("classes are written as a JSON array if the file is JSON", func(t *testing.T) {
+		fileName := filepath.Join(t.TempDir(), "classes.json")
+		c := newClassCollector(fileName)
+		c.Set("a.templ", []string{"flex", "p-4"})
+		if err := c.Write(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		assertFileContents(t, fileName, "[\n  \"flex\",\n  \"p-4\"\n]\n")
+	})
+}
+
+func assertFileContents(t *testing.T, fileName, expected string) {
+	t.Helper()
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", fileName, err)
+	}
+	if string(data) != expected {
+		t.Errorf("expected %q, got %q", expected, string(data))
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"path"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -20,6 +21,8 @@ import (
 	"github.com/a-h/templ/cmd/templ/generatecmd/run"
 	"github.com/a-h/templ/cmd/templ/generatecmd/watcher"
 	"github.com/a-h/templ/generator"
+	"github.com/a-h/templ/markdown"
+	"github.com/a-h/templ/parser/v2"
 	"github.com/cenkalti/backoff/v4"
 	"github.com/cli/browser"
 	"github.com/fsnotify/fsnotify"
@@ -84,21 +87,92 @@ func (cmd Generate) Run(ctx context.Context) (err error) {
 	if cmd.Args.IncludeTimestamp {
 		opts = append(opts, generator.WithTimestamp(time.Now()))
 	}
+	if cmd.Args.IncludeSourceMap {
+		opts = append(opts, generator.WithSourceMapRegistration())
+	}
+	if cmd.Args.Readable {
+		opts = append(opts, generator.WithReadable())
+	}
+	if cmd.Args.RenderHooks {
+		opts = append(opts, generator.WithRenderTrace())
+	}
+	if cmd.Args.Precompress {
+		opts = append(opts, generator.WithPrecompress())
+	}
+	if cmd.Args.MinifyJS {
+		opts = append(opts, generator.WithMinifyJS())
+	}
+	if cmd.Args.IntegrityDir != "" {
+		opts = append(opts, generator.WithIntegrity(cmd.Args.IntegrityDir))
+	}
+	if strings.TrimSpace(cmd.Args.StylePreprocessor) != "" {
+		opts = append(opts, generator.WithStylePreprocessor(stylePreprocessor(cmd.Args.StylePreprocessor)))
+	}
+	if cmd.Args.StaticMarkdown {
+		opts = append(opts, generator.WithMarkdownRenderer(markdown.String))
+	}
+	if entries := criticalCSSEntries(cmd.Args.CriticalCSS); len(entries) > 0 {
+		opts = append(opts, generator.WithCriticalCSS(entries...))
+	}
+	if cmd.Args.ScriptDir != "" {
+		if cmd.Args.ScriptURL == "" {
+			return fmt.Errorf("the URL that extracted scripts are served from must be set with -script-url")
+		}
+		opts = append(opts, generator.WithScriptExtraction(scriptExtractor(cmd.Args.ScriptDir, cmd.Args.ScriptURL)))
+	}
 
 	// Check the version of the templ module.
 	if err := modcheck.Check(cmd.Args.Path); err != nil {
 		cmd.Log.Warn("templ version check: " + err.Error())
 	}
 
+	// Debug markers and hot reloading are only enabled in watch mode, so that
+	// they're removed when the files are regenerated in production mode.
+	devOpts := append([]generator.GenerateOpt{}, opts...)
+	if cmd.Args.DebugMarkers {
+		if cmd.Args.Watch {
+			devOpts = append(devOpts, generator.WithDebugMarkers())
+		} else {
+			cmd.Log.Warn("Debug markers are only written in watch mode")
+		}
+	}
+	if cmd.Args.HotReload {
+		if cmd.Args.Watch {
+			devOpts = append(devOpts, generator.WithHotReload())
+		} else {
+			cmd.Log.Warn("Hot reloading is only enabled in watch mode")
+		}
+	}
+	// CSS is only minified in production mode, so that it's readable while
+	// developing in watch mode.
+	if cmd.Args.MinifyCSS {
+		opts = append(opts, generator.WithMinifyCSS())
+		if !cmd.Args.Watch {
+			devOpts = append(devOpts, generator.WithMinifyCSS())
+		}
+	}
+
 	fseh := NewFSEventHandler(
 		cmd.Log,
 		cmd.Args.Path,
 		cmd.Args.Watch,
-		opts,
+		devOpts,
 		cmd.Args.GenerateSourceMapVisualisations,
 		cmd.Args.KeepOrphanedFiles,
 		cmd.Args.FileWriter,
 	)
+	fseh.HotReload = cmd.Args.Watch && cmd.Args.HotReload
+	var classes *classCollector
+	if cmd.Args.ClassListFile != "" && cmd.Args.FileName == "" {
+		classes = newClassCollector(cmd.Args.ClassListFile)
+		fseh.classes = classes
+	}
+	// Show generation errors in the browser, using the proxy's error overlay.
+	overlay := newOverlayErrors()
+	if cmd.Args.Watch && cmd.Args.Proxy != "" {
+		fseh.OnError = overlay.Set
+		fseh.OnSourceMap = overlay.SetSourceMap
+	}
 
 	// If we're processing a single file, don't bother setting up the channels/multithreaing.
 	if cmd.Args.FileName != "" {
@@ -184,6 +258,7 @@ func (cmd Generate) Run(ctx context.Context) (err error) {
 			cmd.Args.KeepOrphanedFiles,
 			cmd.Args.FileWriter,
 		)
+		fseh.classes = classes
 		errorCount.Store(0)
 		if err := watcher.WalkFiles(ctx, cmd.Args.Path, events); err != nil {
 			cmd.Log.Error("Post dev mode WalkFiles failed", slog.Any("error", err))
@@ -259,6 +334,11 @@ func (cmd Generate) Run(ctx context.Context) (err error) {
 					break
 				}
 				postGenerationEventsWG.Add(1)
+				if classes != nil {
+					if err := classes.Write(); err != nil {
+						cmd.Log.Error("Error writing class names", slog.Any("error", err))
+					}
+				}
 				if cmd.Args.Command != "" && goUpdated {
 					cmd.Log.Debug("Executing command", slog.String("command", cmd.Args.Command))
 					if _, err := run.Run(ctx, cmd.Args.Path, cmd.Args.Command); err != nil {
@@ -272,6 +352,9 @@ func (cmd Generate) Run(ctx context.Context) (err error) {
 					if err != nil {
 						cmd.Log.Error("Failed to start proxy", slog.Any("error", err))
 					}
+					if p != nil {
+						overlay.Start(p)
+					}
 				}
 				// Send server-sent event.
 				if p != nil && (textUpdated || goUpdated) {
@@ -307,6 +390,11 @@ func (cmd Generate) Run(ctx context.Context) (err error) {
 	eventHandlerWG.Wait()
 	cmd.Log.Debug("Waiting for post-generation handler to complete")
 	postGenerationWG.Wait()
+	if classes != nil {
+		if err := classes.Write(); err != nil {
+			return err
+		}
+	}
 	if cmd.Args.Command != "" {
 		cmd.Log.Debug("Killing command", slog.String("command", cmd.Args.Command))
 		if err := run.KillAll(); err != nil {
@@ -378,3 +466,60 @@ func (cmd *Generate) StartProxy(ctx context.Context) (p *proxy.Handler, err erro
 	}()
 	return p, nil
 }
+
+// overlayErrors passes generation errors, and the source maps used to map
+// panics, to the proxy's error overlay. Those that occur before the proxy
+// starts are passed to it when it starts.
+type overlayErrors struct {
+	m          *sync.Mutex
+	proxy      *proxy.Handler
+	errors     map[string]error
+	sourceMaps map[string]*parser.SourceMap
+}
+
+func newOverlayErrors() *overlayErrors {
+	return &overlayErrors{
+		m:          &sync.Mutex{},
+		errors:     map[string]error{},
+		sourceMaps: map[string]*parser.SourceMap{},
+	}
+}
+
+// Set the error for the file, or clear it if err is nil, and reload the page.
+func (o *overlayErrors) Set(fileName string, err error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	delete(o.errors, fileName)
+	if err != nil {
+		o.errors[fileName] = err
+	}
+	if o.proxy != nil {
+		o.proxy.SetError(fileName, err)
+		o.proxy.SendSSE("message", "reload")
+	}
+}
+
+// Start passing errors to the proxy.
+func (o *overlayErrors) Start(p *proxy.Handler) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	o.proxy = p
+	for fileName, err := range o.errors {
+		p.SetError(fileName, err)
+	}
+	for fileName, sourceMap := range o.sourceMaps {
+		p.SetSourceMap(fileName, sourceMap)
+	}
+	o.sourceMaps = nil
+}
+
+// SetSourceMap sets the source map of the code generated from the file.
+func (o *overlayErrors) SetSourceMap(fileName string, sourceMap *parser.SourceMap) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.proxy != nil {
+		o.proxy.SetSourceMap(fileName, sourceMap)
+		return
+	}
+	o.sourceMaps[fileName] = sourceMap
+}
@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"context"
 	"crypto/sha256"
+	"errors"
 	"fmt"
 	"go/format"
 	"go/scanner"
@@ -14,6 +15,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -58,6 +60,8 @@ func NewFSEventHandler(
 		fileNameToErrorMutex:       &sync.Mutex{},
 		hashes:                     make(map[string][sha256.Size]byte),
 		hashesMutex:                &sync.Mutex{},
+		includers:                  make(map[string]map[string]struct{}),
+		includersMutex:             &sync.Mutex{},
 		genOpts:                    genOpts,
 		genSourceMapVis:            genSourceMapVis,
 		DevMode:                    devMode,
@@ -65,7 +69,7 @@ func NewFSEventHandler(
 		writer:                     fileWriter,
 	}
 	if devMode {
-		fseh.genOpts = append(fseh.genOpts, generator.WithExtractStrings())
+		fseh.genOpts = append(fseh.genOpts, generator.WithExtractStrings(), generator.WithRenderTrace())
 	}
 	return fseh
 }
@@ -86,6 +90,23 @@ type FSEventHandler struct {
 	Errors                     []error
 	keepOrphanedFiles          bool
 	writer                     func(string, []byte) error
+	// OnError is called when generating code for a file fails, and with a nil
+	// error when a file that previously failed is generated successfully.
+	OnError func(fileName string, err error)
+	// OnSourceMap is called with the absolute path of each templ file that's
+	// generated, and the source map of the Go code generated from it.
+	OnSourceMap func(fileName string, sourceMap *parser.SourceMap)
+	// HotReload reports changes to the contents of templates as text updates,
+	// so that the command isn't restarted, and the changed templates are hot
+	// reloaded instead. Changes to template signatures, or to the Go code
+	// outside templates, are still reported as Go updates.
+	HotReload bool
+	// classes collects the class names of the templ files, if set.
+	classes *classCollector
+	// includers maps the absolute path of each included file to the names of
+	// the templ files that include it.
+	includers      map[string]map[string]struct{}
+	includersMutex *sync.Mutex
 }
 
 func (h *FSEventHandler) HandleEvent(ctx context.Context, event fsnotify.Event) (goUpdated, textUpdated bool, err error) {
@@ -123,6 +144,9 @@ func (h *FSEventHandler) HandleEvent(ctx context.Context, event fsnotify.Event)
 	if !strings.HasSuffix(event.Name, ".templ") {
 		return false, false, nil
 	}
+	if event.Has(fsnotify.Remove) && h.classes != nil {
+		h.classes.Delete(event.Name)
+	}
 
 	// If the file hasn't been updated since the last time we processed it, ignore it.
 	if !h.UpsertLastModTime(event.Name) {
@@ -140,20 +164,31 @@ func (h *FSEventHandler) HandleEvent(ctx context.Context, event fsnotify.Event)
 			slog.Any("error", err),
 		)
 		h.SetError(event.Name, true)
+		if h.OnError != nil {
+			h.OnError(event.Name, err)
+		}
 		return goUpdated, textUpdated, fmt.Errorf("failed to generate code for %q: %w", event.Name, err)
 	}
+	// Diagnostics are warnings, so the file was generated successfully.
+	if errorCleared, errorCount := h.SetError(event.Name, false); errorCleared {
+		h.Log.Info("Error cleared", slog.String("file", event.Name), slog.Int("errors", errorCount))
+		if h.OnError != nil {
+			h.OnError(event.Name, nil)
+		}
+	}
 	if len(diag) > 0 {
 		for _, d := range diag {
-			h.Log.Warn(d.Message,
+			attrs := []any{
 				slog.String("from", fmt.Sprintf("%d:%d", d.Range.From.Line, d.Range.From.Col)),
 				slog.String("to", fmt.Sprintf("%d:%d", d.Range.To.Line, d.Range.To.Col)),
-			)
+			}
+			if d.Fix != nil {
+				attrs = append(attrs, slog.String("fix", d.Fix.Title))
+			}
+			h.Log.Warn(d.Message, attrs...)
 		}
 		return
 	}
-	if errorCleared, errorCount := h.SetError(event.Name, false); errorCleared {
-		h.Log.Info("Error cleared", slog.String("file", event.Name), slog.Int("errors", errorCount))
-	}
 	h.Log.Debug("Generated code", slog.String("file", event.Name), slog.Duration("in", time.Since(start)))
 
 	return goUpdated, textUpdated, nil
@@ -196,6 +231,91 @@ func (h *FSEventHandler) UpsertHash(fileName string, hash [sha256.Size]byte) (up
 	return true
 }
 
+// upsertSignatureHash returns true if the signature hash of the template file
+// has changed.
+func (h *FSEventHandler) upsertSignatureHash(fileName string, t parser.TemplateFile) (updated bool, err error) {
+	sum, err := SignatureHash(t)
+	if err != nil {
+		return false, err
+	}
+	return h.UpsertHash(fileName+":signatures", sum), nil
+}
+
+// SignatureHash hashes the parts of the template file that the compiled code
+// of other packages depends on, i.e. everything apart from the contents of
+// templates. If the hash of a changed file is unchanged, the changes can be
+// hot reloaded.
+func SignatureHash(t parser.TemplateFile) (sum [sha256.Size]byte, err error) {
+	hash := sha256.New()
+	if err = t.Package.Write(hash, 0); err != nil {
+		return sum, err
+	}
+	for _, n := range t.Nodes {
+		if ht, ok := n.(parser.HTMLTemplate); ok {
+			fmt.Fprintf(hash, "templ %s\n", ht.Expression.Value)
+			continue
+		}
+		if err = n.Write(hash, 0); err != nil {
+			return sum, err
+		}
+	}
+	copy(sum[:], hash.Sum(nil))
+	return sum, nil
+}
+
+// setIncludes records the files included by the templ file, replacing the
+// files it previously included.
+func (h *FSEventHandler) setIncludes(fileName string, includes []string) {
+	h.includersMutex.Lock()
+	defer h.includersMutex.Unlock()
+	for included, includers := range h.includers {
+		delete(includers, fileName)
+		if len(includers) == 0 {
+			delete(h.includers, included)
+		}
+	}
+	for _, included := range includes {
+		if abs, err := filepath.Abs(included); err == nil {
+			included = abs
+		}
+		if h.includers[included] == nil {
+			h.includers[included] = make(map[string]struct{})
+		}
+		h.includers[included][fileName] = struct{}{}
+	}
+}
+
+// getIncluders returns the names of the templ files that include the file.
+func (h *FSEventHandler) getIncluders(fileName string) (includers []string) {
+	if abs, err := filepath.Abs(fileName); err == nil {
+		fileName = abs
+	}
+	h.includersMutex.Lock()
+	defer h.includersMutex.Unlock()
+	for includer := range h.includers[fileName] {
+		includers = append(includers, includer)
+	}
+	slices.Sort(includers)
+	return includers
+}
+
+// generateIncluders regenerates the templ files that include the partial.
+func (h *FSEventHandler) generateIncluders(ctx context.Context, fileName string) (goUpdated, textUpdated bool, diagnostics []parser.Diagnostic, err error) {
+	var errs []error
+	for _, includer := range h.getIncluders(fileName) {
+		h.Log.Debug("Regenerating file that includes partial", slog.String("file", includer), slog.String("partial", fileName))
+		includerGoUpdated, includerTextUpdated, includerDiagnostics, err := h.generate(ctx, includer)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		goUpdated = goUpdated || includerGoUpdated
+		textUpdated = textUpdated || includerTextUpdated
+		diagnostics = append(diagnostics, includerDiagnostics...)
+	}
+	return goUpdated, textUpdated, diagnostics, errors.Join(errs...)
+}
+
 // generate Go code for a single template.
 // If a basePath is provided, the filename included in error messages is relative to it.
 func (h *FSEventHandler) generate(ctx context.Context, fileName string) (goUpdated, textUpdated bool, diagnostics []parser.Diagnostic, err error) {
@@ -203,6 +323,15 @@ func (h *FSEventHandler) generate(ctx context.Context, fileName string) (goUpdat
 	if err != nil {
 		return false, false, nil, fmt.Errorf("%s parsing error: %w", fileName, err)
 	}
+	if h.classes != nil {
+		h.classes.Set(fileName, t.ClassNames())
+	}
+	// Files without a package declaration are partials, which are spliced into
+	// other templates using @include, so there's no Go code to generate, but
+	// the templates that include them need to be regenerated.
+	if t.Package.Expression.Value == "" {
+		return h.generateIncluders(ctx, fileName)
+	}
 	targetFileName := strings.TrimSuffix(fileName, ".templ") + "_templ.go"
 
 	// Only use relative filenames to the basepath for filenames in runtime error messages.
@@ -218,7 +347,10 @@ func (h *FSEventHandler) generate(ctx context.Context, fileName string) (goUpdat
 	relFilePath = filepath.ToSlash(relFilePath)
 
 	var b bytes.Buffer
-	sourceMap, literals, err := generator.Generate(t, &b, append(h.genOpts, generator.WithFileName(relFilePath))...)
+	var includes []string
+	onInclude := func(fullPath string) { includes = append(includes, fullPath) }
+	sourceMap, literals, err := generator.Generate(t, &b, append(h.genOpts, generator.WithFileName(relFilePath), generator.WithIncludeDir(filepath.Dir(fileName)), generator.WithIncludeHandler(onInclude))...)
+	h.setIncludes(fileName, includes)
 	if err != nil {
 		return false, false, nil, fmt.Errorf("%s generation error: %w", fileName, err)
 	}
@@ -228,6 +360,9 @@ func (h *FSEventHandler) generate(ctx context.Context, fileName string) (goUpdat
 		err = remapErrorList(err, sourceMap, fileName)
 		return false, false, nil, fmt.Errorf("% source formatting error %w", fileName, err)
 	}
+	if h.OnSourceMap != nil {
+		h.OnSourceMap(absFilePath, sourceMap)
+	}
 
 	// Hash output, and write out the file if the goCodeHash has changed.
 	goCodeHash := sha256.Sum256(formattedGoCode)
@@ -238,6 +373,17 @@ func (h *FSEventHandler) generate(ctx context.Context, fileName string) (goUpdat
 		}
 	}
 
+	// If only the contents of templates have changed, they can be hot reloaded.
+	if h.HotReload {
+		signaturesUpdated, err := h.upsertSignatureHash(fileName, t)
+		if err != nil {
+			return false, false, nil, fmt.Errorf("%s signature hash error: %w", fileName, err)
+		}
+		if goUpdated && !signaturesUpdated {
+			goUpdated, textUpdated = false, true
+		}
+	}
+
 	// Add the txt file if it has changed.
 	if len(literals) > 0 {
 		txtFileName := strings.TrimSuffix(fileName, ".templ") + "_templ.txt"
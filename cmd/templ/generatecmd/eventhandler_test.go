@@ -0,0 +1,114 @@
+package generatecmd
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-h/templ/parser/v2"
+	"github.com/fsnotify/fsnotify"
+)
+
+func TestUpsertSignatureHash(t *testing.T) {
+	h := NewFSEventHandler(slog.New(slog.NewJSONHandler(io.Discard, nil)), t.TempDir(), true, nil, false, false, nil)
+	upsert := func(src string) bool {
+		tf, err := parser.ParseString(src)
+		if err != nil {
+			t.Fatalf("failed to parse template: %v", err)
+		}
+		updated, err := h.upsertSignatureHash("header.templ", tf)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return updated
+	}
+	if !upsert("package main\n\ntempl header(title string) {\n\t<h1>{ title }</h1>\n}\n") {
+		t.Error("expected the first hash to be an update")
+	}
+	if upsert("package main\n\ntempl header(title string) {\n\t<h2 class=\"title\">{ title }</h2>\n}\n") {
+		t.Error("expected changes to the contents of templates not to update the signatures")
+	}
+	if !upsert("package main\n\ntempl header(title string, level int) {\n\t<h2 class=\"title\">{ title }</h2>\n}\n") {
+		t.Error("expected changes to template parameters to update the signatures")
+	}
+	if !upsert("package main\n\nconst x = 1\n\ntempl header(title string, level int) {\n\t<h2 class=\"title\">{ title }</h2>\n}\n") {
+		t.Error("expected changes to Go code to update the signatures")
+	}
+}
+
+func TestPartialChangesRegenerateIncludingFiles(t *testing.T) {
+	dir := t.TempDir()
+	written := map[string]string{}
+	writer := func(name string, contents []byte) error {
+		written[name] = string(contents)
+		return nil
+	}
+	h := NewFSEventHandler(slog.New(slog.NewJSONHandler(io.Discard, nil)), dir, false, nil, false, false, writer)
+	sourceMaps := map[string]*parser.SourceMap{}
+	h.OnSourceMap = func(fileName string, sourceMap *parser.SourceMap) {
+		sourceMaps[fileName] = sourceMap
+	}
+
+	pageFileName := filepath.Join(dir, "page.templ")
+	partialFileName := filepath.Join(dir, "partial.templ")
+	otherFileName := filepath.Join(dir, "other.templ")
+	modTime := time.Now()
+	write := func(fileName, contents string) {
+		t.Helper()
+		if err := os.WriteFile(fileName, []byte(contents), 0o644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+		// Ensure that the modification time changes, so that the event isn't skipped.
+		modTime = modTime.Add(time.Second)
+		if err := os.Chtimes(fileName, modTime, modTime); err != nil {
+			t.Fatalf("failed to set modification time: %v", err)
+		}
+	}
+	handle := func(fileName string) (goUpdated bool) {
+		t.Helper()
+		goUpdated, _, err := h.HandleEvent(context.Background(), fsnotify.Event{Name: fileName, Op: fsnotify.Write})
+		if err != nil {
+			t.Fatalf("failed to handle event: %v", err)
+		}
+		return goUpdated
+	}
+
+	write(partialFileName, "<p>first</p>\n")
+	write(pageFileName, "package main\n\ntempl page() {\n\t@include \"partial.templ\"\n}\n")
+	write(otherFileName, "package main\n\ntempl other() {\n\t<p>other</p>\n}\n")
+	handle(partialFileName)
+	handle(pageFileName)
+	handle(otherFileName)
+	pageGoFileName := filepath.Join(dir, "page_templ.go")
+	if !strings.Contains(written[pageGoFileName], "first") {
+		t.Fatalf("expected the page to contain the partial, got:\n%s", written[pageGoFileName])
+	}
+	if sourceMaps[pageFileName] == nil {
+		t.Error("expected the source map of the page to be passed to OnSourceMap")
+	}
+
+	delete(written, filepath.Join(dir, "other_templ.go"))
+	write(partialFileName, "<p>second</p>\n")
+	if !handle(partialFileName) {
+		t.Error("expected changing the partial to update the Go code")
+	}
+	if !strings.Contains(written[pageGoFileName], "second") {
+		t.Errorf("expected the page to be regenerated with the changed partial, got:\n%s", written[pageGoFileName])
+	}
+	if _, ok := written[filepath.Join(dir, "other_templ.go")]; ok {
+		t.Error("expected files that don't include the partial not to be regenerated")
+	}
+
+	// Once the page no longer includes the partial, it isn't regenerated.
+	write(pageFileName, "package main\n\ntempl page() {\n\t<p>page</p>\n}\n")
+	handle(pageFileName)
+	write(partialFileName, "<p>third</p>\n")
+	if handle(partialFileName) {
+		t.Error("expected changing a partial that isn't included not to update the Go code")
+	}
+}
@@ -23,9 +23,40 @@ type Arguments struct {
 	GenerateSourceMapVisualisations bool
 	IncludeVersion                  bool
 	IncludeTimestamp                bool
+	// IncludeSourceMap registers the templ positions of the generated code, so that panic stack traces can be mapped to templ files.
+	IncludeSourceMap bool
+	// Readable generates code with comments that cite the templ source of each group of statements.
+	Readable bool
+	// RenderHooks generates code that calls the render hooks of the context as each component is rendered.
+	RenderHooks bool
+	// Precompress adds the gzip and brotli compressed output of components that have no dynamic content to the generated code.
+	Precompress bool
+	// MinifyJS removes the whitespace and comments of the JavaScript within script elements.
+	MinifyJS bool
+	// MinifyCSS removes the whitespace and comments of the CSS of css components and style elements, when not in watch mode.
+	MinifyCSS bool
+	// IntegrityDir is the directory of the local files that subresource integrity attributes are computed from.
+	IntegrityDir string
+	// ScriptDir is the directory that the JavaScript of script elements without Go expressions is extracted to.
+	ScriptDir string
+	// ScriptURL is the URL that the files in ScriptDir are served from.
+	ScriptURL string
+	// ClassListFile is the file that the class names used in templ files are written to, e.g. for Tailwind to scan.
+	ClassListFile string
+	// StylePreprocessor is the command that converts the contents of style elements with a lang attribute to CSS.
+	StylePreprocessor string
+	// StaticMarkdown renders the contents of markdown blocks when the code is generated.
+	StaticMarkdown bool
+	// CriticalCSS is the comma separated names of the page components that critical CSS functions are generated for.
+	CriticalCSS string
 	// PPROFPort is the port to run the pprof server on.
 	PPROFPort         int
 	KeepOrphanedFiles bool
+	// DebugMarkers wraps the output of each component in HTML comments in watch mode.
+	DebugMarkers bool
+	// HotReload makes components look up hot reloaded versions of themselves in watch
+	// mode, and skips running the command when only the contents of templates change.
+	HotReload bool
 }
 
 func Run(ctx context.Context, log *slog.Logger, args Arguments) (err error) {
@@ -0,0 +1,225 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go/scanner"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/a-h/parse"
+	"github.com/a-h/templ/parser/v2"
+)
+
+// overlayError is an error displayed in the error overlay.
+type overlayError struct {
+	Title    string
+	FileName string
+	Message  string
+	// Excerpt of the source around the error, if the position of the error is known.
+	Excerpt []sourceLine
+}
+
+type sourceLine struct {
+	Number    int
+	Text      string
+	Highlight bool
+}
+
+// excerptLines is the number of lines shown either side of an error.
+const excerptLines = 3
+
+// SetError displays an error overlay in place of proxied HTML pages, until
+// the error for the file is cleared by setting a nil error.
+func (h *Handler) SetError(fileName string, err error) {
+	h.errorsMutex.Lock()
+	defer h.errorsMutex.Unlock()
+	if err == nil {
+		delete(h.errors, fileName)
+		return
+	}
+	h.errors[fileName] = overlayError{
+		Title:    "Failed to generate code",
+		FileName: fileName,
+		Message:  err.Error(),
+		Excerpt:  sourceExcerpt(fileName, errorLine(err)),
+	}
+}
+
+func (h *Handler) getErrors() (errs []overlayError) {
+	h.errorsMutex.Lock()
+	defer h.errorsMutex.Unlock()
+	for _, e := range h.errors {
+		errs = append(errs, e)
+	}
+	return errs
+}
+
+// acceptsOverlay returns true if the request is for a page that can be
+// replaced with the error overlay.
+func acceptsOverlay(r *http.Request) bool {
+	return r.Method == http.MethodGet &&
+		strings.Contains(r.Header.Get("Accept"), "text/html") &&
+		r.Header.Get("HX-Request") != "true"
+}
+
+func renderOverlay(errs []overlayError) (body []byte, err error) {
+	var buf bytes.Buffer
+	if err = overlayPage(errs).Render(context.Background(), &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (h *Handler) serveOverlay(w http.ResponseWriter, errs []overlayError) {
+	body, err := renderOverlay(errs)
+	if err != nil {
+		h.log.Error("Failed to render error overlay", "error", err)
+		http.Error(w, errs[0].Message, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusInternalServerError)
+	_, _ = w.Write(body)
+}
+
+// serveTargetError displays the error overlay if the proxy can't connect to the target.
+func (h *Handler) serveTargetError(w http.ResponseWriter, r *http.Request, err error) {
+	h.log.Error("Proxy to target error", "error", err)
+	if !acceptsOverlay(r) {
+		w.WriteHeader(http.StatusBadGateway)
+		return
+	}
+	h.serveOverlay(w, []overlayError{{
+		Title:    "Failed to connect to the app",
+		FileName: h.Target.String(),
+		Message:  err.Error(),
+	}})
+}
+
+// errorLine returns the zero-based line of the error, or -1 if the position is unknown.
+func errorLine(err error) int {
+	var pe parse.ParseError
+	if errors.As(err, &pe) {
+		return pe.Pos.Line
+	}
+	var list scanner.ErrorList
+	if errors.As(err, &list) && len(list) > 0 {
+		return list[0].Pos.Line - 1
+	}
+	return -1
+}
+
+// sourceExcerpt returns the lines of the file around the zero-based line.
+func sourceExcerpt(fileName string, line int) (excerpt []sourceLine) {
+	if line < 0 {
+		return nil
+	}
+	contents, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil
+	}
+	lines := strings.Split(string(contents), "\n")
+	for i := max(line-excerptLines, 0); i <= min(line+excerptLines, len(lines)-1); i++ {
+		excerpt = append(excerpt, sourceLine{
+			Number:    i + 1,
+			Text:      lines[i],
+			Highlight: i == line,
+		})
+	}
+	return excerpt
+}
+
+var templStackFrame = regexp.MustCompile(`(\S+)_templ\.go:(\d+)`)
+
+// SetSourceMap sets the source map of the code generated from the templ
+// file, so that stack traces that refer to the generated code can be mapped.
+func (h *Handler) SetSourceMap(templFileName string, sourceMap *parser.SourceMap) {
+	h.sourceMapsMutex.Lock()
+	defer h.sourceMapsMutex.Unlock()
+	h.sourceMaps[templFileName] = sourceMap
+}
+
+func (h *Handler) getSourceMap(templFileName string) *parser.SourceMap {
+	h.sourceMapsMutex.Lock()
+	defer h.sourceMapsMutex.Unlock()
+	return h.sourceMaps[templFileName]
+}
+
+// mapStack adds the position within the templ file to each frame of a Go
+// stack trace that refers to a generated _templ.go file, using the source
+// maps returned by getSourceMap.
+func mapStack(stack string, getSourceMap func(templFileName string) *parser.SourceMap) (mapped string, ok bool) {
+	mapped = templStackFrame.ReplaceAllStringFunc(stack, func(frame string) string {
+		m := templStackFrame.FindStringSubmatch(frame)
+		templFileName := m[1] + ".templ"
+		line, _ := strconv.Atoi(m[2])
+		pos, posOK := templPosition(getSourceMap(templFileName), m[1]+"_templ.go", line-1)
+		if !posOK {
+			return frame
+		}
+		ok = true
+		return fmt.Sprintf("%s (%s:%d:%d)", frame, templFileName, pos.Line+1, pos.Col)
+	})
+	return mapped, ok
+}
+
+// templPosition returns the position in the templ file of the first mapped
+// expression on the zero-based line of the generated Go file.
+func templPosition(sourceMap *parser.SourceMap, goFileName string, line int) (pos parser.Position, ok bool) {
+	if sourceMap == nil || line < 0 {
+		return pos, false
+	}
+	contents, err := os.ReadFile(goFileName)
+	if err != nil {
+		return pos, false
+	}
+	lines := strings.Split(string(contents), "\n")
+	if line >= len(lines) {
+		return pos, false
+	}
+	for col := 0; col < len(lines[line]); col++ {
+		if pos, ok = sourceMap.SourcePositionFromTarget(uint32(line), uint32(col)); ok {
+			return pos, true
+		}
+	}
+	return pos, false
+}
+
+// modifyErrorResponse replaces server error responses that contain a stack
+// trace from a templ component with the error overlay, with the position of
+// each templ frame in the templ file.
+func (h *Handler) modifyErrorResponse(r *http.Response) error {
+	if r.Header.Get("Content-Encoding") != "" {
+		return nil
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	stack, ok := mapStack(string(body), h.getSourceMap)
+	if !ok {
+		return nil
+	}
+	overlay, err := renderOverlay([]overlayError{{
+		Title:    "Failed to render page",
+		FileName: r.Request.URL.String(),
+		Message:  stack,
+	}})
+	if err != nil {
+		return err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(overlay))
+	r.ContentLength = int64(len(overlay))
+	r.Header.Set("Content-Length", strconv.Itoa(len(overlay)))
+	r.Header.Set("Content-Type", "text/html; charset=utf-8")
+	return nil
+}
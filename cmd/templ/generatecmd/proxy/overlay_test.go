@@ -0,0 +1,138 @@
+package proxy
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ/generator"
+	"github.com/a-h/templ/parser/v2"
+)
+
+func TestErrorOverlay(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = io.WriteString(w, "target")
+	}))
+	defer target.Close()
+	targetURL, err := url.Parse(target.URL)
+	if err != nil {
+		t.Fatalf("failed to parse target URL: %v", err)
+	}
+	log := slog.New(slog.NewJSONHandler(io.Discard, nil))
+
+	fileName := filepath.Join(t.TempDir(), "broken.templ")
+	src := "package test\n\ntempl broken() {\n\t<div>\n}\n"
+	if err := os.WriteFile(fileName, []byte(src), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	_, parseErr := parser.Parse(fileName)
+	if parseErr == nil {
+		t.Fatal("expected a parse error")
+	}
+
+	get := func(h *Handler, accept string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept", accept)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("errors are displayed in place of HTML pages", func(t *testing.T) {
+		h := New(log, "127.0.0.1", 7474, targetURL)
+		h.SetError(fileName, fmt.Errorf("failed to generate code: %w", parseErr))
+
+		w := get(h, "text/html")
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("expected status %d, got %d", http.StatusInternalServerError, w.Code)
+		}
+		body := w.Body.String()
+		for _, expected := range []string{"Failed to generate code", "broken.templ", `<div class="highlight">`, scriptTag} {
+			if !strings.Contains(body, expected) {
+				t.Errorf("expected overlay to contain %q, got:\n%s", expected, body)
+			}
+		}
+	})
+	t.Run("requests that aren't for HTML pages are proxied", func(t *testing.T) {
+		h := New(log, "127.0.0.1", 7474, targetURL)
+		h.SetError(fileName, parseErr)
+
+		w := get(h, "application/json")
+		if w.Body.String() != "target" {
+			t.Errorf("expected the target response, got %q", w.Body.String())
+		}
+	})
+	t.Run("cleared errors are not displayed", func(t *testing.T) {
+		h := New(log, "127.0.0.1", 7474, targetURL)
+		h.SetError(fileName, parseErr)
+		h.SetError(fileName, nil)
+
+		w := get(h, "text/html")
+		if w.Body.String() != "target" {
+			t.Errorf("expected the target response, got %q", w.Body.String())
+		}
+	})
+}
+
+func TestMapStack(t *testing.T) {
+	dir := t.TempDir()
+	templFileName := filepath.Join(dir, "hello.templ")
+	goFileName := filepath.Join(dir, "hello_templ.go")
+	src := "package test\n\ntempl hello(name string) {\n\t@include \"partial.templ\"\n\t<div>{ name }</div>\n}\n"
+	if err := os.WriteFile(templFileName, []byte(src), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "partial.templ"), []byte("<p>one</p>\n<p>two</p>\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	tf, err := parser.ParseString(src)
+	if err != nil {
+		t.Fatalf("failed to parse template: %v", err)
+	}
+	var buf bytes.Buffer
+	sourceMap, _, err := generator.Generate(tf, &buf, generator.WithIncludeDir(dir))
+	if err != nil {
+		t.Fatalf("failed to generate code: %v", err)
+	}
+	if err = os.WriteFile(goFileName, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	var line int
+	for i, l := range strings.Split(buf.String(), "\n") {
+		if strings.Contains(l, "JoinStringErrs(name)") {
+			line = i + 1
+		}
+	}
+	getSourceMap := func(fileName string) *parser.SourceMap {
+		if fileName != templFileName {
+			return nil
+		}
+		return sourceMap
+	}
+
+	stack := fmt.Sprintf("panic: oops\n\ngoroutine 1 [running]:\ntest.hello.func1()\n\t%s:%d +0x1d\n", goFileName, line)
+	mapped, ok := mapStack(stack, getSourceMap)
+	if !ok {
+		t.Fatalf("expected the stack to be mapped, got:\n%s", mapped)
+	}
+	expected := fmt.Sprintf("%s:%d (%s:5:8) +0x1d", goFileName, line, templFileName)
+	if !strings.Contains(mapped, expected) {
+		t.Errorf("expected %q in mapped stack, got:\n%s", expected, mapped)
+	}
+
+	if _, ok := mapStack("panic: oops\n\tmain.go:10 +0x1d\n", getSourceMap); ok {
+		t.Error("expected stacks without templ frames not to be mapped")
+	}
+	if _, ok := mapStack(stack, func(string) *parser.SourceMap { return nil }); ok {
+		t.Error("expected stacks not to be mapped without a source map")
+	}
+}
@@ -14,9 +14,11 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/a-h/templ/cmd/templ/generatecmd/sse"
+	"github.com/a-h/templ/parser/v2"
 	"github.com/andybalholm/brotli"
 
 	_ "embed"
@@ -33,6 +35,13 @@ type Handler struct {
 	Target *url.URL
 	p      *httputil.ReverseProxy
 	sse    *sse.Handler
+	// errors to display in the error overlay, keyed by file name.
+	errors      map[string]overlayError
+	errorsMutex *sync.Mutex
+	// sourceMaps of the generated code, keyed by templ file name, used to map
+	// stack traces to positions in templ files.
+	sourceMaps      map[string]*parser.SourceMap
+	sourceMapsMutex *sync.Mutex
 }
 
 func insertScriptTagIntoBody(body string) (updated string) {
@@ -55,6 +64,9 @@ func (h *Handler) modifyResponse(r *http.Response) error {
 		log.Debug("Skipping response modification because templ-skip-modify header is set")
 		return nil
 	}
+	if r.StatusCode >= http.StatusInternalServerError && acceptsOverlay(r.Request) {
+		return h.modifyErrorResponse(r)
+	}
 	if contentType := r.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "text/html") {
 		log.Debug("Skipping response modification because content type is not text/html", slog.String("content-type", contentType))
 		return nil
@@ -137,13 +149,18 @@ func New(log *slog.Logger, bind string, port int, target *url.URL) (h *Handler)
 		backoffExponent: 1.5,
 	}
 	h = &Handler{
-		log:    log,
-		URL:    fmt.Sprintf("http://%s:%d", bind, port),
-		Target: target,
-		p:      p,
-		sse:    sse.New(),
+		log:             log,
+		URL:             fmt.Sprintf("http://%s:%d", bind, port),
+		Target:          target,
+		p:               p,
+		sse:             sse.New(),
+		errors:          map[string]overlayError{},
+		errorsMutex:     &sync.Mutex{},
+		sourceMaps:      map[string]*parser.SourceMap{},
+		sourceMapsMutex: &sync.Mutex{},
 	}
 	p.ModifyResponse = h.modifyResponse
+	p.ErrorHandler = h.serveTargetError
 	return h
 }
 
@@ -171,6 +188,10 @@ func (p *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "only GET or POST method allowed", http.StatusMethodNotAllowed)
 		return
 	}
+	if errs := p.getErrors(); len(errs) > 0 && acceptsOverlay(r) {
+		p.serveOverlay(w, errs)
+		return
+	}
 	p.p.ServeHTTP(w, r)
 }
 
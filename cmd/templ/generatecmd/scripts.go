@@ -0,0 +1,57 @@
+package generatecmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// scriptExtractor returns a function that writes scripts to files in dir, and
+// returns their URL. Files are named by the hash of their contents, so that
+// they can be cached until they change, e.g. script-3f8a9c0d1e2b4a5f.js.
+func scriptExtractor(dir, urlPrefix string) func(js string) (src string, err error) {
+	if !strings.HasSuffix(urlPrefix, "/") {
+		urlPrefix += "/"
+	}
+	return func(js string) (src string, err error) {
+		hash := sha256.Sum256([]byte(js))
+		name := "script-" + hex.EncodeToString(hash[:8]) + ".js"
+		fileName := filepath.Join(dir, name)
+		// Files with the same name have the same contents.
+		if _, err = os.Stat(fileName); err == nil {
+			return urlPrefix + name, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+		if err = os.MkdirAll(dir, 0o755); err != nil {
+			return "", err
+		}
+		// Write to a temporary file first, so that other workers don't see a
+		// partially written file.
+		f, err := os.CreateTemp(dir, name+".*")
+		if err != nil {
+			return "", err
+		}
+		defer os.Remove(f.Name())
+		if _, err = f.WriteString(js); err != nil {
+			f.Close()
+			return "", err
+		}
+		if err = f.Close(); err != nil {
+			return "", err
+		}
+		if err = os.Chmod(f.Name(), 0o644); err != nil {
+			return "", err
+		}
+		if err = os.Rename(f.Name(), fileName); err != nil {
+			return "", fmt.Errorf("failed to write %s: %w", fileName, err)
+		}
+		return urlPrefix + name, nil
+	}
+}
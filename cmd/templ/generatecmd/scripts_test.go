@@ -0,0 +1,56 @@
+package generatecmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScriptExtractor(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "scripts")
+	extract := scriptExtractor(dir, "/static/scripts")
+
+	src, err := extract("console.log(1);")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(src, "/static/scripts/script-") || !strings.HasSuffix(src, ".js") {
+		t.Errorf("unexpected URL %q", src)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, strings.TrimPrefix(src, "/static/scripts/")))
+	if err != nil {
+		t.Fatalf("failed to read script: %v", err)
+	}
+	if string(data) != "console.log(1);" {
+		t.Errorf("unexpected contents %q", string(data))
+	}
+
+	t.Run("the same script has the same URL", func(t *testing.T) {
+		again, err := extract("console.log(1);")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if again != src {
+			t.Errorf("expected %q, got %q", src, again)
+		}
+	})
+	t.Run("different scripts have different URLs", func(t *testing.T) {
+		other, err := extract("console.log(2);")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if other == src {
+			t.Errorf("expected a different URL to %q", src)
+		}
+	})
+	t.Run("only the scripts are written", func(t *testing.T) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatalf("failed to read dir: %v", err)
+		}
+		if len(entries) != 2 {
+			t.Errorf("expected 2 files, got %d", len(entries))
+		}
+	})
+}
@@ -0,0 +1,40 @@
+package generatecmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// stylePreprocessor returns a function that converts the contents of style
+// elements with a lang attribute to CSS, by running the command with the
+// contents as stdin, and reading the CSS from stdout. The language is passed
+// to the command in the TEMPL_STYLE_LANG environment variable.
+func stylePreprocessor(command string) func(lang, src string) (css string, err error) {
+	args := strings.Fields(command)
+	return func(lang, src string) (css string, err error) {
+		var stdout, stderr bytes.Buffer
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Env = append(os.Environ(), "TEMPL_STYLE_LANG="+lang)
+		cmd.Stdin = strings.NewReader(src)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		if err = cmd.Run(); err != nil {
+			return "", fmt.Errorf("%s: %w: %s", command, err, strings.TrimSpace(stderr.String()))
+		}
+		return stdout.String(), nil
+	}
+}
+
+// criticalCSSEntries returns the names of the components in the comma
+// separated list, e.g. "Page, Home".
+func criticalCSSEntries(list string) (entries []string) {
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			entries = append(entries, name)
+		}
+	}
+	return entries
+}
@@ -0,0 +1,46 @@
+package generatecmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStylePreprocessor(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil || runtime.GOOS == "windows" {
+		t.Skip("sh is not available")
+	}
+	script := filepath.Join(t.TempDir(), "preprocess.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\nprintf '/* %s */ ' \"$TEMPL_STYLE_LANG\"\ncat\n"), 0o755); err != nil {
+		t.Fatalf("failed to write script: %v", err)
+	}
+	t.Run("the contents are piped through the command", func(t *testing.T) {
+		actual, err := stylePreprocessor(script)("scss", ".a { .b { color: red; } }")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := "/* scss */ .a { .b { color: red; } }"
+		if actual != expected {
+			t.Errorf("expected %q, got %q", expected, actual)
+		}
+	})
+	t.Run("commands that fail return an error", func(t *testing.T) {
+		if _, err := stylePreprocessor("false")("scss", ""); err == nil {
+			t.Fatal("expected an error")
+		}
+	})
+}
+
+func TestCriticalCSSEntries(t *testing.T) {
+	actual := criticalCSSEntries(" Page, Home,,")
+	if diff := cmp.Diff([]string{"Page", "Home"}, actual); diff != "" {
+		t.Error(diff)
+	}
+	if actual := criticalCSSEntries(""); actual != nil {
+		t.Errorf("expected no entries, got %v", actual)
+	}
+}
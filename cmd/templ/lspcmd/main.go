@@ -12,6 +12,7 @@ import (
 	"github.com/a-h/templ/cmd/templ/lspcmd/httpdebug"
 	"github.com/a-h/templ/cmd/templ/lspcmd/pls"
 	"github.com/a-h/templ/cmd/templ/lspcmd/proxy"
+	"github.com/a-h/templ/parser/v2"
 	"go.lsp.dev/jsonrpc2"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -27,6 +28,8 @@ type Arguments struct {
 	PPROF bool
 	// HTTPDebug sets the HTTP endpoint to listen on. Leave empty for no web debug.
 	HTTPDebug string
+	// FormatOptions are used to format templ files.
+	FormatOptions parser.FormatOptions
 }
 
 func Run(stdin io.Reader, stdout, stderr io.Writer, args Arguments) (err error) {
@@ -100,7 +103,7 @@ func run(ctx context.Context, log *zap.Logger, templStream jsonrpc2.Stream, args
 
 	log.Info("creating proxy")
 	// Create the proxy to sit between.
-	serverProxy, serverInit := proxy.NewServer(log, goplsServer, cache, diagnosticCache)
+	serverProxy, serverInit := proxy.NewServer(log, goplsServer, cache, diagnosticCache, args.FormatOptions)
 
 	// Create templ server.
 	log.Info("creating templ server")
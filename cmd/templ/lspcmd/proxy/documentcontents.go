@@ -142,6 +142,27 @@ func (d *Document) DeleteLines(i, j int) {
 	d.Lines = append(d.Lines[:i], d.Lines[j:]...)
 }
 
+// Offset returns the byte offset of the position within the document.
+func (d *Document) Offset(pos lsp.Position) (offset int) {
+	r := lsp.Range{Start: pos, End: pos}
+	d.normalize(&r)
+	for _, l := range d.Lines[:r.Start.Line] {
+		offset += len(l) + 1
+	}
+	return offset + int(r.Start.Character)
+}
+
+// Position returns the position of the byte offset within the document.
+func (d *Document) Position(offset int) lsp.Position {
+	for i, l := range d.Lines {
+		if offset <= len(l) {
+			return lsp.Position{Line: uint32(i), Character: uint32(offset)}
+		}
+		offset -= len(l) + 1
+	}
+	return lsp.Position{Line: uint32(len(d.Lines) - 1), Character: uint32(len(d.Lines[len(d.Lines)-1]))}
+}
+
 func (d *Document) String() string {
 	return strings.Join(d.Lines, "\n")
 }
@@ -568,3 +568,26 @@ d`,
 		})
 	}
 }
+
+func TestDocumentOffsets(t *testing.T) {
+	d := NewDocument(zap.NewNop(), "ab\ncde\n\nf")
+	tests := []struct {
+		position lsp.Position
+		offset   int
+	}{
+		{position: lsp.Position{Line: 0, Character: 0}, offset: 0},
+		{position: lsp.Position{Line: 0, Character: 2}, offset: 2},
+		{position: lsp.Position{Line: 1, Character: 0}, offset: 3},
+		{position: lsp.Position{Line: 1, Character: 3}, offset: 6},
+		{position: lsp.Position{Line: 2, Character: 0}, offset: 7},
+		{position: lsp.Position{Line: 3, Character: 1}, offset: 9},
+	}
+	for _, tt := range tests {
+		if actual := d.Offset(tt.position); actual != tt.offset {
+			t.Errorf("Offset(%v): expected %d, got %d", tt.position, tt.offset, actual)
+		}
+		if actual := d.Position(tt.offset); actual != tt.position {
+			t.Errorf("Position(%d): expected %v, got %v", tt.offset, tt.position, actual)
+		}
+	}
+}
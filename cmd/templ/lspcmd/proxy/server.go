@@ -3,6 +3,7 @@ package proxy
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"regexp"
 	"strings"
 
@@ -37,9 +38,11 @@ type Server struct {
 	DiagnosticCache *DiagnosticCache
 	TemplSource     *DocumentContents
 	GoSource        map[string]string
+	// FormatOptions are used to format templ files.
+	FormatOptions parser.FormatOptions
 }
 
-func NewServer(log *zap.Logger, target lsp.Server, cache *SourceMapCache, diagnosticCache *DiagnosticCache) (s *Server, init func(lsp.Client)) {
+func NewServer(log *zap.Logger, target lsp.Server, cache *SourceMapCache, diagnosticCache *DiagnosticCache, formatOptions parser.FormatOptions) (s *Server, init func(lsp.Client)) {
 	s = &Server{
 		Log:             log,
 		Target:          target,
@@ -47,6 +50,7 @@ func NewServer(log *zap.Logger, target lsp.Server, cache *SourceMapCache, diagno
 		DiagnosticCache: diagnosticCache,
 		TemplSource:     newDocumentContents(log),
 		GoSource:        make(map[string]string),
+		FormatOptions:   formatOptions,
 	}
 	return s, func(client lsp.Client) {
 		s.Client = client
@@ -163,22 +167,7 @@ func (p *Server) parseTemplate(ctx context.Context, uri uri.URI, templateText st
 			URI: uri,
 		}
 		for _, d := range parsedDiagnostics {
-			msg.Diagnostics = append(msg.Diagnostics, lsp.Diagnostic{
-				Severity: lsp.DiagnosticSeverityWarning,
-				Code:     "",
-				Source:   "templ",
-				Message:  d.Message,
-				Range: lsp.Range{
-					Start: lsp.Position{
-						Line:      uint32(d.Range.From.Line),
-						Character: uint32(d.Range.From.Col),
-					},
-					End: lsp.Position{
-						Line:      uint32(d.Range.To.Line),
-						Character: uint32(d.Range.To.Col),
-					},
-				},
-			})
+			msg.Diagnostics = append(msg.Diagnostics, convertTemplDiagnostic(d))
 		}
 		msg.Diagnostics = p.DiagnosticCache.AddGoDiagnostics(string(uri), msg.Diagnostics)
 		err = p.Client.PublishDiagnostics(ctx, msg)
@@ -201,6 +190,73 @@ func (p *Server) parseTemplate(ctx context.Context, uri uri.URI, templateText st
 	return
 }
 
+func convertTemplDiagnostic(d parser.Diagnostic) lsp.Diagnostic {
+	return lsp.Diagnostic{
+		Severity: lsp.DiagnosticSeverityWarning,
+		Code:     "",
+		Source:   "templ",
+		Message:  d.Message,
+		Range:    convertTemplRange(d.Range),
+	}
+}
+
+func convertTemplRange(r parser.Range) lsp.Range {
+	return lsp.Range{
+		Start: lsp.Position{
+			Line:      r.From.Line,
+			Character: r.From.Col,
+		},
+		End: lsp.Position{
+			Line:      r.To.Line,
+			Character: r.To.Col,
+		},
+	}
+}
+
+// templCodeActions returns the code actions that apply the fixes of the
+// templ diagnostics within the range, e.g. making a void element with
+// children self-closing.
+func (p *Server) templCodeActions(templURI lsp.DocumentURI, r lsp.Range) (actions []lsp.CodeAction) {
+	d, ok := p.TemplSource.Get(string(templURI))
+	if !ok {
+		return nil
+	}
+	template, err := parser.ParseString(d.String())
+	if err != nil {
+		return nil
+	}
+	diagnostics, err := parser.Diagnose(template)
+	if err != nil {
+		return nil
+	}
+	for _, diag := range diagnostics {
+		if diag.Fix == nil || !rangesOverlap(r, convertTemplRange(diag.Range)) {
+			continue
+		}
+		edits := make([]lsp.TextEdit, len(diag.Fix.Edits))
+		for i, e := range diag.Fix.Edits {
+			edits[i] = lsp.TextEdit{Range: convertTemplRange(e.Range), NewText: e.NewText}
+		}
+		actions = append(actions, lsp.CodeAction{
+			Title:       diag.Fix.Title,
+			Kind:        lsp.QuickFix,
+			Diagnostics: []lsp.Diagnostic{convertTemplDiagnostic(diag)},
+			IsPreferred: true,
+			Edit: &lsp.WorkspaceEdit{
+				Changes: map[lsp.DocumentURI][]lsp.TextEdit{templURI: edits},
+			},
+		})
+	}
+	return actions
+}
+
+func rangesOverlap(a, b lsp.Range) bool {
+	before := func(x, y lsp.Position) bool {
+		return x.Line < y.Line || (x.Line == y.Line && x.Character < y.Character)
+	}
+	return !before(a.End, b.Start) && !before(b.End, a.Start)
+}
+
 func (p *Server) Initialize(ctx context.Context, params *lsp.InitializeParams) (result *lsp.InitializeResult, err error) {
 	p.Log.Info("client -> server: Initialize")
 	defer p.Log.Info("client -> server: Initialize end")
@@ -220,7 +276,7 @@ func (p *Server) Initialize(ctx context.Context, params *lsp.InitializeParams) (
 	result.Capabilities.ExecuteCommandProvider.Commands = []string{}
 	result.Capabilities.DocumentFormattingProvider = true
 	result.Capabilities.SemanticTokensProvider = nil
-	result.Capabilities.DocumentRangeFormattingProvider = false
+	result.Capabilities.DocumentRangeFormattingProvider = true
 	result.Capabilities.TextDocumentSync = lsp.TextDocumentSyncOptions{
 		OpenClose:         true,
 		Change:            lsp.TextDocumentSyncKindFull,
@@ -300,6 +356,7 @@ func (p *Server) CodeAction(ctx context.Context, params *lsp.CodeActionParams) (
 		}
 		result[i] = r
 	}
+	result = append(result, p.templCodeActions(templURI, params.Range)...)
 	return
 }
 
@@ -550,7 +607,7 @@ func (p *Server) DidChange(ctx context.Context, params *lsp.DidChangeTextDocumen
 		return
 	}
 	w := new(strings.Builder)
-	sm, _, err := generator.Generate(template, w)
+	sm, _, err := generator.Generate(template, w, generator.WithIncludeDir(filepath.Dir(params.TextDocument.URI.Filename())))
 	if err != nil {
 		p.Log.Error("generate failure", zap.Error(err))
 		return
@@ -623,7 +680,7 @@ func (p *Server) DidOpen(ctx context.Context, params *lsp.DidOpenTextDocumentPar
 	// Generate the output code and cache the source map and Go contents to use during completion
 	// requests.
 	w := new(strings.Builder)
-	sm, _, err := generator.Generate(template, w)
+	sm, _, err := generator.Generate(template, w, generator.WithIncludeDir(filepath.Dir(params.TextDocument.URI.Filename())))
 	if err != nil {
 		return
 	}
@@ -738,7 +795,7 @@ func (p *Server) Formatting(ctx context.Context, params *lsp.DocumentFormattingP
 		return
 	}
 	w := new(strings.Builder)
-	err = template.Write(w)
+	err = template.WriteWithOptions(w, p.FormatOptions)
 	if err != nil {
 		p.Log.Error("handleFormatting: faled to write template", zap.Error(err))
 		return
@@ -860,26 +917,29 @@ func (p *Server) PrepareRename(ctx context.Context, params *lsp.PrepareRenamePar
 func (p *Server) RangeFormatting(ctx context.Context, params *lsp.DocumentRangeFormattingParams) (result []lsp.TextEdit, err error) {
 	p.Log.Info("client -> server: RangeFormatting")
 	defer p.Log.Info("client -> server: RangeFormatting end")
-	templURI := params.TextDocument.URI
-	// Rewrite the request.
-	var isTemplURI bool
-	isTemplURI, params.TextDocument.URI = convertTemplToGoURI(params.TextDocument.URI)
-	if !isTemplURI {
-		err = fmt.Errorf("not a templ file")
+	// Format the templates within the range.
+	d, ok := p.TemplSource.Get(string(params.TextDocument.URI))
+	if !ok {
 		return
 	}
-	// Call gopls.
-	result, err = p.Target.RangeFormatting(ctx, params)
+	src := d.String()
+	start, end, formatted, err := parser.FormatRange(src, d.Offset(params.Range.Start), d.Offset(params.Range.End), p.FormatOptions)
 	if err != nil {
-		return
+		p.Log.Error("handleRangeFormatting: failed to format range", zap.Error(err))
+		return nil, nil
 	}
-	// Rewrite the response.
-	for i := 0; i < len(result); i++ {
-		r := result[i]
-		r.Range = p.convertGoRangeToTemplRange(templURI, r.Range)
-		result[i] = r
+	if start == end && formatted == "" {
+		return
 	}
-	return result, err
+	result = append(result, lsp.TextEdit{
+		Range: lsp.Range{
+			Start: d.Position(start),
+			End:   d.Position(end),
+		},
+		NewText: formatted,
+	})
+	d.Replace(src[:start] + formatted + src[end:])
+	return
 }
 
 func (p *Server) References(ctx context.Context, params *lsp.ReferenceParams) (result []lsp.Location, err error) {
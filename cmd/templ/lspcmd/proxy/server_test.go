@@ -0,0 +1,75 @@
+package proxy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/a-h/protocol"
+	"github.com/a-h/templ/parser/v2"
+	"go.uber.org/zap"
+)
+
+// diagnosticsClient is a client that discards published diagnostics.
+type diagnosticsClient struct {
+	protocol.Client
+}
+
+func (diagnosticsClient) PublishDiagnostics(ctx context.Context, params *protocol.PublishDiagnosticsParams) error {
+	return nil
+}
+
+func TestFormattingUsesFormatOptions(t *testing.T) {
+	const uri = "file:///example/templates.templ"
+	src := `package main
+
+templ example() {
+	<div class='a' id='b'>text</div>
+}
+`
+	expected := `package main
+
+templ example() {
+	<div id='b' class='a'>text</div>
+}
+`
+	s, init := NewServer(zap.NewNop(), nil, NewSourceMapCache(), NewDiagnosticCache(), parser.FormatOptions{
+		SortAttributes: parser.AttributeOrderGrouped,
+		QuoteStyle:     parser.QuoteStyleSingle,
+	})
+	init(diagnosticsClient{})
+
+	t.Run("Formatting", func(t *testing.T) {
+		s.TemplSource.Set(uri, NewDocument(s.Log, src))
+		edits, err := s.Formatting(context.Background(), &protocol.DocumentFormattingParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(edits) != 1 {
+			t.Fatalf("expected 1 edit, got %d", len(edits))
+		}
+		if edits[0].NewText != expected {
+			t.Errorf("expected:\n%s\ngot:\n%s", expected, edits[0].NewText)
+		}
+	})
+	t.Run("RangeFormatting", func(t *testing.T) {
+		s.TemplSource.Set(uri, NewDocument(s.Log, src))
+		edits, err := s.RangeFormatting(context.Background(), &protocol.DocumentRangeFormattingParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: uri},
+			Range: protocol.Range{
+				Start: protocol.Position{Line: 3, Character: 1},
+				End:   protocol.Position{Line: 3, Character: 2},
+			},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(edits) != 1 {
+			t.Fatalf("expected 1 edit, got %d", len(edits))
+		}
+		if edits[0].NewText != expected[len("package main\n\n"):len(expected)-1] {
+			t.Errorf("unexpected range edit:\n%s", edits[0].NewText)
+		}
+	})
+}
@@ -7,14 +7,23 @@ import (
 	"io"
 	"log/slog"
 	"os"
+	"os/exec"
 	"os/signal"
 	"runtime"
+	"strings"
 
 	"github.com/a-h/templ"
+	"github.com/a-h/templ/cmd/templ/bundlecmd"
+	"github.com/a-h/templ/cmd/templ/explaincmd"
 	"github.com/a-h/templ/cmd/templ/fmtcmd"
 	"github.com/a-h/templ/cmd/templ/generatecmd"
 	"github.com/a-h/templ/cmd/templ/lspcmd"
+	"github.com/a-h/templ/cmd/templ/previewcmd"
+	"github.com/a-h/templ/cmd/templ/profilecmd"
+	"github.com/a-h/templ/cmd/templ/servecmd"
 	"github.com/a-h/templ/cmd/templ/sloghandler"
+	"github.com/a-h/templ/cmd/templ/upgradecmd"
+	"github.com/a-h/templ/parser/v2"
 	"github.com/fatih/color"
 )
 
@@ -35,6 +44,12 @@ commands:
   generate   Generates Go code from templ files
   fmt        Formats templ files
   lsp        Starts a language server for templ files
+  serve      Generates, builds and runs an app, reloading the browser when files change
+  preview    Starts a server to preview templ components in a browser
+  explain    Prints the Go code generated from templ code
+  profile    Measures the performance of rendering a templ component
+  bundle     Builds a package of templ components into a bundle that a server can load
+  upgrade    Rewrites deprecated templ syntax and APIs
   version    Prints the version
 `
 
@@ -50,6 +65,18 @@ func run(stdin io.Reader, stdout, stderr io.Writer, args []string) (code int) {
 		return fmtCmd(stdin, stdout, stderr, args[2:])
 	case "lsp":
 		return lspCmd(stdin, stdout, stderr, args[2:])
+	case "serve":
+		return serveCmd(stdout, stderr, args[2:])
+	case "preview":
+		return previewCmd(stdout, stderr, args[2:])
+	case "explain":
+		return explainCmd(stdin, stdout, stderr, args[2:])
+	case "profile":
+		return profileCmd(stdout, stderr, args[2:])
+	case "bundle":
+		return bundleCmd(stdout, stderr, args[2:])
+	case "upgrade":
+		return upgradeCmd(stdout, stderr, args[2:])
 	case "version", "--version":
 		fmt.Fprintln(stdout, templ.Version())
 		return 0
@@ -80,6 +107,64 @@ func newLogger(logLevel string, verbose bool, stderr io.Writer) *slog.Logger {
 	}))
 }
 
+// configureElements sets the elements that the parser treats as raw text or
+// void elements from comma separated lists of names.
+// formatFlags are the flags that configure the formatter, which are shared
+// by the fmt and lsp commands.
+type formatFlags struct {
+	maxLineWidth        *int
+	maxBlankLines       *int
+	sortAttributes      *string
+	sortTailwindClasses *bool
+	inlineShortElements *bool
+	goFormatter         *string
+	quoteStyle          *string
+}
+
+func newFormatFlags(cmd *flag.FlagSet) formatFlags {
+	return formatFlags{
+		maxLineWidth:        cmd.Int("max-line-width", 0, ""),
+		maxBlankLines:       cmd.Int("max-blank-lines", 0, ""),
+		sortAttributes:      cmd.String("sort-attributes", "", ""),
+		sortTailwindClasses: cmd.Bool("sort-tailwind-classes", false, ""),
+		inlineShortElements: cmd.Bool("inline-short-elements", false, ""),
+		goFormatter:         cmd.String("go-formatter", "", ""),
+		quoteStyle:          cmd.String("quote-style", "", ""),
+	}
+}
+
+func (f formatFlags) options() (opts parser.FormatOptions, err error) {
+	sortAttributes, err := parser.ParseAttributeOrder(*f.sortAttributes)
+	if err != nil {
+		return opts, err
+	}
+	quoteStyle, err := parser.ParseQuoteStyle(*f.quoteStyle)
+	if err != nil {
+		return opts, err
+	}
+	if goFormatter := strings.Fields(*f.goFormatter); len(goFormatter) > 0 {
+		if _, err = exec.LookPath(goFormatter[0]); err != nil {
+			return opts, err
+		}
+	}
+	return parser.FormatOptions{
+		MaxLineWidth:        *f.maxLineWidth,
+		MaxBlankLines:       *f.maxBlankLines,
+		SortAttributes:      sortAttributes,
+		SortTailwindClasses: *f.sortTailwindClasses,
+		InlineShortElements: *f.inlineShortElements,
+		GoFormatter:         *f.goFormatter,
+		QuoteStyle:          quoteStyle,
+	}, nil
+}
+
+func configureElements(rawText, void string) {
+	parser.ConfigureElements(parser.ElementConfig{
+		RawTextElements: strings.Split(rawText, ","),
+		VoidElements:    strings.Split(void, ","),
+	})
+}
+
 const generateUsageText = `usage: templ generate [<args>...]
 
 Generates Go code from templ files.
@@ -98,6 +183,36 @@ Args:
     Set to false to skip inclusion of the templ version in the generated code. (default true)
   -include-timestamp
     Set to true to include the current time in the generated code.
+  -include-source-map
+    Set to true to include the templ positions of the generated code, so that panic stack traces can be mapped to templ files. (default false)
+  -readable
+    Set to true to generate code that's easier to audit and step through, with comments that cite the templ source line of each group of statements. (default false)
+  -render-hooks
+    Set to true to generate code that calls the render hooks set with templ.WithRenderHooks as each component starts and finishes rendering. (default false)
+  -precompress
+    Set to true to compress the output of components that have no dynamic content with gzip and brotli, so that templ.Handler writes it without rendering it. (default false)
+  -minify-js
+    Set to true to remove the whitespace and comments of the JavaScript within script elements. (default false)
+  -minify-css
+    Set to true to remove the whitespace and comments of the CSS of css components and style elements, and shorten values such as colors. CSS is only minified when not in watch mode, so it's readable during development. (default false)
+  -integrity-dir <dir>
+    Adds integrity attributes to <script src> and <link rel="stylesheet"> elements whose URL is a path, by hashing the file at the path within dir, e.g. -integrity-dir ./public
+  -script-dir <dir>
+    Moves the JavaScript of script elements that don't contain Go expressions to files in dir, named by the hash of their contents, and replaces it with a src attribute.
+  -script-url <url>
+    The URL that the files in -script-dir are served from, e.g. /static/scripts/
+  -class-list <file>
+    Writes the literal class names used in templ files to file, one per line, or as a JSON array if the file name ends with .json, so that Tailwind can scan it instead of the generated Go code, e.g. -class-list tailwind-content.txt
+  -style-preprocessor <cmd>
+    Command used to convert the contents of style elements with a lang attribute, e.g. <style lang="scss">, to CSS, e.g. "sass --stdin". The command reads the contents from stdin, and writes CSS to stdout. The lang attribute is in the TEMPL_STYLE_LANG environment variable.
+  -static-markdown
+    Set to true to render the contents of markdown blocks with goldmark when the code is generated, instead of when the templates are rendered, so that the generated code doesn't depend on goldmark. (default false)
+  -critical-css <names>
+    Comma separated names of page components to generate critical CSS functions for, e.g. "Page,Home". For each component, a function such as PageCriticalCSS is generated, which returns the css components used by the page, so that their CSS can be rendered in the <head> element.
+  -raw-text-elements <names>
+    Comma separated names of elements whose contents aren't parsed, like <script> and <style>, e.g. "x-template".
+  -void-elements <names>
+    Comma separated names of elements that don't have children or a close tag, like <br>, e.g. "x-icon".
   -watch
     Set to true to watch the path for changes and regenerate code.
   -cmd <cmd>
@@ -116,6 +231,10 @@ Args:
     Port to run the pprof server on.
   -keep-orphaned-files
     Keeps orphaned generated templ files. (default false)
+  -debug-markers
+    Set to true to wrap the output of each component in HTML comments containing the component name, and its file and line. Only applies in watch mode. (default false)
+  -hot-reload
+    Set to true to render changed templates with an interpreter, instead of running the command again, when only the contents of templates change. Requires the app to be built with the templ_hotreload build tag. Only applies in watch mode. (default false)
   -v
     Set log verbosity level to "debug". (default "info")
   -log-level
@@ -146,6 +265,21 @@ func generateCmd(stdout, stderr io.Writer, args []string) (code int) {
 	sourceMapVisualisationsFlag := cmd.Bool("source-map-visualisations", false, "")
 	includeVersionFlag := cmd.Bool("include-version", true, "")
 	includeTimestampFlag := cmd.Bool("include-timestamp", false, "")
+	includeSourceMapFlag := cmd.Bool("include-source-map", false, "")
+	readableFlag := cmd.Bool("readable", false, "")
+	renderHooksFlag := cmd.Bool("render-hooks", false, "")
+	precompressFlag := cmd.Bool("precompress", false, "")
+	minifyJSFlag := cmd.Bool("minify-js", false, "")
+	minifyCSSFlag := cmd.Bool("minify-css", false, "")
+	integrityDirFlag := cmd.String("integrity-dir", "", "")
+	scriptDirFlag := cmd.String("script-dir", "", "")
+	scriptURLFlag := cmd.String("script-url", "", "")
+	classListFlag := cmd.String("class-list", "", "")
+	stylePreprocessorFlag := cmd.String("style-preprocessor", "", "")
+	staticMarkdownFlag := cmd.Bool("static-markdown", false, "")
+	criticalCSSFlag := cmd.String("critical-css", "", "")
+	rawTextElementsFlag := cmd.String("raw-text-elements", "", "")
+	voidElementsFlag := cmd.String("void-elements", "", "")
 	watchFlag := cmd.Bool("watch", false, "")
 	openBrowserFlag := cmd.Bool("open-browser", true, "")
 	cmdFlag := cmd.String("cmd", "", "")
@@ -156,6 +290,8 @@ func generateCmd(stdout, stderr io.Writer, args []string) (code int) {
 	workerCountFlag := cmd.Int("w", runtime.NumCPU(), "")
 	pprofPortFlag := cmd.Int("pprof", 0, "")
 	keepOrphanedFilesFlag := cmd.Bool("keep-orphaned-files", false, "")
+	debugMarkersFlag := cmd.Bool("debug-markers", false, "")
+	hotReloadFlag := cmd.Bool("hot-reload", false, "")
 	verboseFlag := cmd.Bool("v", false, "")
 	logLevelFlag := cmd.String("log-level", "info", "")
 	helpFlag := cmd.Bool("help", false, "")
@@ -168,6 +304,13 @@ func generateCmd(stdout, stderr io.Writer, args []string) (code int) {
 		fmt.Fprint(stdout, generateUsageText)
 		return
 	}
+	if stylePreprocessor := strings.Fields(*stylePreprocessorFlag); len(stylePreprocessor) > 0 {
+		if _, err = exec.LookPath(stylePreprocessor[0]); err != nil {
+			fmt.Fprintln(stderr, err)
+			fmt.Fprint(stderr, generateUsageText)
+			return 64 // EX_USAGE
+		}
+	}
 
 	log := newLogger(*logLevelFlag, *verboseFlag, stderr)
 
@@ -180,6 +323,8 @@ func generateCmd(stdout, stderr io.Writer, args []string) (code int) {
 		cancel()
 	}()
 
+	configureElements(*rawTextElementsFlag, *voidElementsFlag)
+
 	var fw generatecmd.FileWriterFunc
 	if *toStdoutFlag {
 		fw = generatecmd.WriterFileWriter(stdout)
@@ -200,8 +345,23 @@ func generateCmd(stdout, stderr io.Writer, args []string) (code int) {
 		GenerateSourceMapVisualisations: *sourceMapVisualisationsFlag,
 		IncludeVersion:                  *includeVersionFlag,
 		IncludeTimestamp:                *includeTimestampFlag,
+		IncludeSourceMap:                *includeSourceMapFlag,
+		Readable:                        *readableFlag,
+		RenderHooks:                     *renderHooksFlag,
+		Precompress:                     *precompressFlag,
+		MinifyJS:                        *minifyJSFlag,
+		MinifyCSS:                       *minifyCSSFlag,
+		IntegrityDir:                    *integrityDirFlag,
+		ScriptDir:                       *scriptDirFlag,
+		ScriptURL:                       *scriptURLFlag,
+		ClassListFile:                   *classListFlag,
+		StylePreprocessor:               *stylePreprocessorFlag,
+		StaticMarkdown:                  *staticMarkdownFlag,
+		CriticalCSS:                     *criticalCSSFlag,
 		PPROFPort:                       *pprofPortFlag,
 		KeepOrphanedFiles:               *keepOrphanedFilesFlag,
+		DebugMarkers:                    *debugMarkersFlag,
+		HotReload:                       *hotReloadFlag,
 	})
 	if err != nil {
 		color.New(color.FgRed).Fprint(stderr, "(✗) ")
@@ -228,6 +388,24 @@ Format file or directory to stdout:
 Args:
   -stdout
     Prints to stdout instead of in-place format
+  -max-line-width
+    Maximum width of an element's open tag before its attributes are placed on separate lines. (default 0, attributes are only placed on separate lines if they are in the source)
+  -max-blank-lines
+    Maximum number of consecutive blank lines to preserve between nodes within templates, and between declarations. (default 0, blank lines are removed)
+  -sort-attributes
+    Sort element attributes. (default "", attributes are kept in source order, options: "alphabetical", "grouped")
+  -sort-tailwind-classes
+    Sort the classes of constant class attributes into Tailwind CSS order. (default false)
+  -inline-short-elements
+    Write elements on a single line if they fit within the max line width, or 80 columns if it's not set. (default false)
+  -quote-style
+    Quotes used around constant attribute values. (default "double", options: "double", "single", "preserve")
+  -go-formatter
+    Command used to format Go code outside templates, e.g. "gofumpt". The command reads Go code from stdin, and writes formatted code to stdout. (default "", uses go/format)
+  -raw-text-elements <names>
+    Comma separated names of elements whose contents aren't parsed, like <script> and <style>, e.g. "x-template".
+  -void-elements <names>
+    Comma separated names of elements that don't have children or a close tag, like <br>, e.g. "x-icon".
   -v
     Set log verbosity level to "debug". (default "info")
   -log-level
@@ -245,11 +423,20 @@ func fmtCmd(stdin io.Reader, stdout, stderr io.Writer, args []string) (code int)
 	verboseFlag := cmd.Bool("v", false, "")
 	logLevelFlag := cmd.String("log-level", "info", "")
 	stdoutFlag := cmd.Bool("stdout", false, "")
+	formatFlags := newFormatFlags(cmd)
+	rawTextElementsFlag := cmd.String("raw-text-elements", "", "")
+	voidElementsFlag := cmd.String("void-elements", "", "")
 	err := cmd.Parse(args)
 	if err != nil {
 		fmt.Fprint(stderr, fmtUsageText)
 		return 64 // EX_USAGE
 	}
+	formatOptions, err := formatFlags.options()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		fmt.Fprint(stderr, fmtUsageText)
+		return 64 // EX_USAGE
+	}
 	if *helpFlag {
 		fmt.Fprint(stdout, fmtUsageText)
 		return
@@ -257,10 +444,13 @@ func fmtCmd(stdin io.Reader, stdout, stderr io.Writer, args []string) (code int)
 
 	log := newLogger(*logLevelFlag, *verboseFlag, stderr)
 
+	configureElements(*rawTextElementsFlag, *voidElementsFlag)
+
 	err = fmtcmd.Run(log, stdin, stdout, fmtcmd.Arguments{
 		ToStdout:    *stdoutFlag,
 		Files:       cmd.Args(),
 		WorkerCount: *workerCountFlag,
+		Options:     formatOptions,
 	})
 	if err != nil {
 		return 1
@@ -285,6 +475,24 @@ Args:
     Enable pprof web server (default address is localhost:9999)
   -http string
     Enable http debug server by setting a listen address (e.g. localhost:7474)
+  -max-line-width
+    Maximum width of an element's open tag before its attributes are placed on separate lines, when formatting. (default 0, attributes are only placed on separate lines if they are in the source)
+  -max-blank-lines
+    Maximum number of consecutive blank lines to preserve between nodes within templates, and between declarations, when formatting. (default 0, blank lines are removed)
+  -sort-attributes
+    Sort element attributes when formatting. (default "", attributes are kept in source order, options: "alphabetical", "grouped")
+  -sort-tailwind-classes
+    Sort the classes of constant class attributes into Tailwind CSS order when formatting. (default false)
+  -inline-short-elements
+    Write elements on a single line when formatting, if they fit within the max line width, or 80 columns if it's not set. (default false)
+  -quote-style
+    Quotes used around constant attribute values when formatting. (default "double", options: "double", "single", "preserve")
+  -go-formatter
+    Command used to format Go code outside templates, e.g. "gofumpt". The command reads Go code from stdin, and writes formatted code to stdout. (default "", uses go/format)
+  -raw-text-elements <names>
+    Comma separated names of elements whose contents aren't parsed, like <script> and <style>, e.g. "x-template".
+  -void-elements <names>
+    Comma separated names of elements that don't have children or a close tag, like <br>, e.g. "x-icon".
 `
 
 func lspCmd(stdin io.Reader, stdout, stderr io.Writer, args []string) (code int) {
@@ -295,22 +503,34 @@ func lspCmd(stdin io.Reader, stdout, stderr io.Writer, args []string) (code int)
 	helpFlag := cmd.Bool("help", false, "")
 	pprofFlag := cmd.Bool("pprof", false, "")
 	httpDebugFlag := cmd.String("http", "", "")
+	rawTextElementsFlag := cmd.String("raw-text-elements", "", "")
+	voidElementsFlag := cmd.String("void-elements", "", "")
+	formatFlags := newFormatFlags(cmd)
 	err := cmd.Parse(args)
 	if err != nil {
 		fmt.Fprint(stderr, lspUsageText)
 		return 64 // EX_USAGE
 	}
+	formatOptions, err := formatFlags.options()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		fmt.Fprint(stderr, lspUsageText)
+		return 64 // EX_USAGE
+	}
 	if *helpFlag {
 		fmt.Fprint(stdout, lspUsageText)
 		return
 	}
 
+	configureElements(*rawTextElementsFlag, *voidElementsFlag)
+
 	err = lspcmd.Run(stdin, stdout, stderr, lspcmd.Arguments{
 		Log:           *logFlag,
 		GoplsLog:      *goplsLog,
 		GoplsRPCTrace: *goplsRPCTrace,
 		PPROF:         *pprofFlag,
 		HTTPDebug:     *httpDebugFlag,
+		FormatOptions: formatOptions,
 	})
 	if err != nil {
 		fmt.Fprintln(stderr, err.Error())
@@ -318,3 +538,404 @@ func lspCmd(stdin io.Reader, stdout, stderr io.Writer, args []string) (code int)
 	}
 	return 0
 }
+
+const previewUsageText = `usage: templ preview [<args> ...]
+
+Starts a server to preview templ components in a browser.
+
+Components are discovered in the generated Go code of templ files, so run
+templ generate, or templ generate -watch, alongside templ preview.
+
+Select a component to render it in isolation. Arguments are entered as JSON,
+and the component is re-rendered as they're changed. templ.Component
+arguments are entered as a JSON string of HTML.
+
+Args:
+  -path <path>
+    Discovers components in all packages in path. (default .)
+  -port
+    The port the server will listen on. (default 7332)
+  -bind
+    The address the server will listen on. (default 127.0.0.1)
+  -open-browser
+    Set to false to skip opening the preview in a browser. (default true)
+  -v
+    Set log verbosity level to "debug". (default "info")
+  -log-level
+    Set log verbosity level. (default "info", options: "debug", "info", "warn", "error")
+  -help
+    Print help and exit.
+`
+
+func previewCmd(stdout, stderr io.Writer, args []string) (code int) {
+	cmd := flag.NewFlagSet("preview", flag.ExitOnError)
+	pathFlag := cmd.String("path", ".", "")
+	portFlag := cmd.Int("port", 7332, "")
+	bindFlag := cmd.String("bind", "127.0.0.1", "")
+	openBrowserFlag := cmd.Bool("open-browser", true, "")
+	verboseFlag := cmd.Bool("v", false, "")
+	logLevelFlag := cmd.String("log-level", "info", "")
+	helpFlag := cmd.Bool("help", false, "")
+	err := cmd.Parse(args)
+	if err != nil {
+		fmt.Fprint(stderr, previewUsageText)
+		return 64 // EX_USAGE
+	}
+	if *helpFlag {
+		fmt.Fprint(stdout, previewUsageText)
+		return
+	}
+
+	log := newLogger(*logLevelFlag, *verboseFlag, stderr)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	signalChan := make(chan os.Signal, 1)
+	signal.Notify(signalChan, os.Interrupt)
+	go func() {
+		<-signalChan
+		fmt.Fprintln(stderr, "Stopping...")
+		cancel()
+	}()
+
+	err = previewcmd.Run(ctx, log, previewcmd.Arguments{
+		Path:        *pathFlag,
+		Bind:        *bindFlag,
+		Port:        *portFlag,
+		OpenBrowser: *openBrowserFlag,
+	})
+	if err != nil {
+		color.New(color.FgRed).Fprint(stderr, "(✗) ")
+		fmt.Fprintln(stderr, "Command failed: "+err.Error())
+		return 1
+	}
+	return 0
+}
+
+const explainUsageText = `usage: templ explain [<args> ...] [<file>[:<line>]]
+
+Prints the Go code generated from a templ file, with a marker after each line
+of Go code that shows the position of the templ code it was generated from.
+
+If a line is given, only the Go code generated for the template that contains
+the line is printed, and the Go code generated from the line is marked with >.
+
+If no file is given, or the file is -, templ code is read from stdin. A
+snippet of templ code without a package or template declaration can be
+explained, e.g. echo '<a href={ url }>Link</a>' | templ explain
+
+Examples:
+
+  templ explain header.templ
+  templ explain header.templ:12
+
+Args:
+  -help
+    Print help and exit.
+`
+
+func explainCmd(stdin io.Reader, stdout, stderr io.Writer, args []string) (code int) {
+	cmd := flag.NewFlagSet("explain", flag.ExitOnError)
+	helpFlag := cmd.Bool("help", false, "")
+	err := cmd.Parse(args)
+	if err != nil || cmd.NArg() > 1 {
+		fmt.Fprint(stderr, explainUsageText)
+		return 64 // EX_USAGE
+	}
+	if *helpFlag {
+		fmt.Fprint(stdout, explainUsageText)
+		return
+	}
+
+	fileName, line, err := explaincmd.ParseTarget(cmd.Arg(0))
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		fmt.Fprint(stderr, explainUsageText)
+		return 64 // EX_USAGE
+	}
+
+	err = explaincmd.Run(stdin, stdout, explaincmd.Arguments{
+		FileName: fileName,
+		Line:     line,
+	})
+	if err != nil {
+		color.New(color.FgRed).Fprint(stderr, "(✗) ")
+		fmt.Fprintln(stderr, "Command failed: "+err.Error())
+		return 1
+	}
+	return 0
+}
+
+const profileUsageText = `usage: templ profile [<args> ...] <component>
+
+Measures the performance of rendering a templ component, by rendering it
+repeatedly, and reports the time and memory taken by each render, and the
+size of the output.
+
+The component is rendered by building its package with go test, so run
+templ generate before templ profile.
+
+Examples:
+
+  templ profile -path ./components -props '{"name": "World"}' hello
+  templ profile -n 10000 -cpuprofile cpu.pprof page
+
+Args:
+  -path <path>
+    The package that contains the component. (default .)
+  -props <json>
+    The component's arguments, as a JSON object keyed by parameter name, or a JSON array. (default "[]")
+  -n
+    The number of times to render the component. (default 1000)
+  -cpuprofile <file>
+    Write a pprof CPU profile of the renders to file.
+  -memprofile <file>
+    Write a pprof allocation profile of the renders to file.
+  -help
+    Print help and exit.
+`
+
+func profileCmd(stdout, stderr io.Writer, args []string) (code int) {
+	cmd := flag.NewFlagSet("profile", flag.ExitOnError)
+	pathFlag := cmd.String("path", ".", "")
+	propsFlag := cmd.String("props", "", "")
+	iterationsFlag := cmd.Int("n", 1000, "")
+	cpuProfileFlag := cmd.String("cpuprofile", "", "")
+	memProfileFlag := cmd.String("memprofile", "", "")
+	helpFlag := cmd.Bool("help", false, "")
+	err := cmd.Parse(args)
+	if err != nil {
+		fmt.Fprint(stderr, profileUsageText)
+		return 64 // EX_USAGE
+	}
+	if *helpFlag {
+		fmt.Fprint(stdout, profileUsageText)
+		return
+	}
+	if cmd.NArg() != 1 || *iterationsFlag < 1 {
+		fmt.Fprint(stderr, profileUsageText)
+		return 64 // EX_USAGE
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	signalChan := make(chan os.Signal, 1)
+	signal.Notify(signalChan, os.Interrupt)
+	go func() {
+		<-signalChan
+		fmt.Fprintln(stderr, "Stopping...")
+		cancel()
+	}()
+
+	err = profilecmd.Run(ctx, stdout, profilecmd.Arguments{
+		Path:       *pathFlag,
+		Component:  cmd.Arg(0),
+		Props:      *propsFlag,
+		Iterations: *iterationsFlag,
+		CPUProfile: *cpuProfileFlag,
+		MemProfile: *memProfileFlag,
+	})
+	if err != nil {
+		color.New(color.FgRed).Fprint(stderr, "(✗) ")
+		fmt.Fprintln(stderr, "Command failed: "+err.Error())
+		return 1
+	}
+	return 0
+}
+
+const bundleUsageText = `usage: templ bundle [<args> ...]
+
+Builds the exported components of a package into a Go plugin, which a running
+server can load with the github.com/a-h/templ/bundle package, to update its
+templates without a redeploy.
+
+The plugin must be built with the same Go version, build flags, and module
+versions as the server, and can only be loaded on Linux, macOS and FreeBSD.
+Run templ generate before templ bundle.
+
+Examples:
+
+  templ bundle -path ./views -version $(git rev-parse --short HEAD)
+  templ bundle -path ./views -version v2 -o views-v2.so
+
+Args:
+  -path <path>
+    The package that contains the components. (default .)
+  -version <version>
+    The version of the bundle, e.g. a commit hash. Required.
+  -o <file>
+    The file name of the plugin. (default <package>-<version>.so)
+  -help
+    Print help and exit.
+`
+
+func bundleCmd(stdout, stderr io.Writer, args []string) (code int) {
+	cmd := flag.NewFlagSet("bundle", flag.ExitOnError)
+	pathFlag := cmd.String("path", ".", "")
+	versionFlag := cmd.String("version", "", "")
+	outputFlag := cmd.String("o", "", "")
+	helpFlag := cmd.Bool("help", false, "")
+	err := cmd.Parse(args)
+	if err != nil {
+		fmt.Fprint(stderr, bundleUsageText)
+		return 64 // EX_USAGE
+	}
+	if *helpFlag {
+		fmt.Fprint(stdout, bundleUsageText)
+		return
+	}
+	if cmd.NArg() != 0 || *versionFlag == "" {
+		fmt.Fprint(stderr, bundleUsageText)
+		return 64 // EX_USAGE
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	signalChan := make(chan os.Signal, 1)
+	signal.Notify(signalChan, os.Interrupt)
+	go func() {
+		<-signalChan
+		fmt.Fprintln(stderr, "Stopping...")
+		cancel()
+	}()
+
+	err = bundlecmd.Run(ctx, stdout, bundlecmd.Arguments{
+		Path:    *pathFlag,
+		Version: *versionFlag,
+		Output:  *outputFlag,
+	})
+	if err != nil {
+		color.New(color.FgRed).Fprint(stderr, "(✗) ")
+		fmt.Fprintln(stderr, "Command failed: "+err.Error())
+		return 1
+	}
+	return 0
+}
+
+const upgradeUsageText = `usage: templ upgrade [<args> ...]
+
+Rewrites deprecated syntax and API usage in templ and Go files:
+
+  - {! component } calls are replaced with @component.
+  - templ.Class, templ.SafeClass and templ.ConstantCSSClass calls in class
+    attributes, templ.Classes and templ.KV are replaced with their argument.
+
+Generated _templ.go files are skipped. Run templ generate after templ upgrade.
+
+Examples:
+
+  templ upgrade -dry-run
+  templ upgrade -path ./views
+
+Args:
+  -path <path>
+    The directory to upgrade files within. (default .)
+  -dry-run
+    Print the changes that would be made, without writing files.
+  -help
+    Print help and exit.
+`
+
+func upgradeCmd(stdout, stderr io.Writer, args []string) (code int) {
+	cmd := flag.NewFlagSet("upgrade", flag.ExitOnError)
+	pathFlag := cmd.String("path", ".", "")
+	dryRunFlag := cmd.Bool("dry-run", false, "")
+	helpFlag := cmd.Bool("help", false, "")
+	err := cmd.Parse(args)
+	if err != nil {
+		fmt.Fprint(stderr, upgradeUsageText)
+		return 64 // EX_USAGE
+	}
+	if *helpFlag {
+		fmt.Fprint(stdout, upgradeUsageText)
+		return
+	}
+	if cmd.NArg() != 0 {
+		fmt.Fprint(stderr, upgradeUsageText)
+		return 64 // EX_USAGE
+	}
+	err = upgradecmd.Run(stdout, upgradecmd.Arguments{
+		Path:   *pathFlag,
+		DryRun: *dryRunFlag,
+	})
+	if err != nil {
+		color.New(color.FgRed).Fprint(stderr, "(✗) ")
+		fmt.Fprintln(stderr, "Command failed: "+err.Error())
+		return 1
+	}
+	return 0
+}
+
+const serveUsageText = `usage: templ serve [<args> ...]
+
+Generates Go code from templ files, builds and runs the app, and serves it
+through a proxy that reloads the browser when files change.
+
+Changes to templ files regenerate code, changes to Go files rebuild and
+restart the app, and changes to static assets reload the browser. The app is
+configured with a templ.serve.json file, e.g.:
+
+  {
+    "build": {"package": "./cmd/server"},
+    "run": {"env": {"PORT": "8080"}},
+    "proxy": {"target": "http://localhost:8080"},
+    "static": {"dir": "static", "prefix": "/static/"}
+  }
+
+Args:
+  -config <file>
+    The config file. (default templ.serve.json, if it exists)
+  -open-browser
+    Open the browser when the app starts. (default true)
+  -w
+    Number of workers to use when generating code. (default runtime.NumCPUs)
+  -v
+    Set log verbosity level to "debug". (default "info")
+  -log-level
+    Set log verbosity level. (default "info", options: "debug", "info", "warn", "error")
+  -help
+    Print help and exit.
+`
+
+func serveCmd(stdout, stderr io.Writer, args []string) (code int) {
+	cmd := flag.NewFlagSet("serve", flag.ExitOnError)
+	configFlag := cmd.String("config", "", "")
+	openBrowserFlag := cmd.Bool("open-browser", true, "")
+	workerCountFlag := cmd.Int("w", runtime.NumCPU(), "")
+	verboseFlag := cmd.Bool("v", false, "")
+	logLevelFlag := cmd.String("log-level", "info", "")
+	helpFlag := cmd.Bool("help", false, "")
+	err := cmd.Parse(args)
+	if err != nil {
+		fmt.Fprint(stderr, serveUsageText)
+		return 64 // EX_USAGE
+	}
+	if *helpFlag {
+		fmt.Fprint(stdout, serveUsageText)
+		return
+	}
+	if cmd.NArg() != 0 {
+		fmt.Fprint(stderr, serveUsageText)
+		return 64 // EX_USAGE
+	}
+
+	log := newLogger(*logLevelFlag, *verboseFlag, stderr)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	signalChan := make(chan os.Signal, 1)
+	signal.Notify(signalChan, os.Interrupt)
+	go func() {
+		<-signalChan
+		fmt.Fprintln(stderr, "Stopping...")
+		cancel()
+	}()
+
+	err = servecmd.Run(ctx, log, servecmd.Arguments{
+		ConfigFile:  *configFlag,
+		OpenBrowser: *openBrowserFlag,
+		WorkerCount: *workerCountFlag,
+	})
+	if err != nil {
+		color.New(color.FgRed).Fprint(stderr, "(✗) ")
+		fmt.Fprintln(stderr, "Command failed: "+err.Error())
+		return 1
+	}
+	return 0
+}
@@ -65,6 +65,42 @@ func TestMain(t *testing.T) {
 			expectedStdout: lspUsageText,
 			expectedCode:   0,
 		},
+		{
+			name:           `"templ preview --help" prints usage`,
+			args:           []string{"templ", "preview", "--help"},
+			expectedStdout: previewUsageText,
+			expectedCode:   0,
+		},
+		{
+			name:           `"templ explain --help" prints usage`,
+			args:           []string{"templ", "explain", "--help"},
+			expectedStdout: explainUsageText,
+			expectedCode:   0,
+		},
+		{
+			name:           `"templ profile --help" prints usage`,
+			args:           []string{"templ", "profile", "--help"},
+			expectedStdout: profileUsageText,
+			expectedCode:   0,
+		},
+		{
+			name:           `"templ bundle --help" prints usage`,
+			args:           []string{"templ", "bundle", "--help"},
+			expectedStdout: bundleUsageText,
+			expectedCode:   0,
+		},
+		{
+			name:           `"templ serve --help" prints usage`,
+			args:           []string{"templ", "serve", "--help"},
+			expectedStdout: serveUsageText,
+			expectedCode:   0,
+		},
+		{
+			name:           `"templ upgrade --help" prints usage`,
+			args:           []string{"templ", "upgrade", "--help"},
+			expectedStdout: upgradeUsageText,
+			expectedCode:   0,
+		},
 	}
 
 	for _, test := range tests {
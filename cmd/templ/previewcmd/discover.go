@@ -0,0 +1,225 @@
+package previewcmd
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Component is a templ component that can be previewed.
+type Component struct {
+	// Dir is the directory of the package, relative to the preview path.
+	Dir string
+	// Package name of the component.
+	Package string
+	// Name of the component's function.
+	Name string
+	// FileName of the generated Go file the component is declared in.
+	FileName string
+	Params   []Param
+	// Imports of the generated Go file that are used by the parameter types.
+	Imports []Import
+}
+
+// Param is a parameter of a component.
+type Param struct {
+	Name string
+	// Type of the parameter as Go source, e.g. "[]string".
+	Type     string
+	Variadic bool
+	// Sample is the JSON value used for the parameter until another value is entered.
+	Sample string
+}
+
+// Component returns true if the parameter is a templ.Component, which is
+// entered as a string of HTML.
+func (p Param) Component() bool {
+	return !p.Variadic && p.Type == "templ.Component"
+}
+
+type Import struct {
+	Name string
+	Path string
+}
+
+// String returns the import as Go source.
+func (imp Import) String() string {
+	if imp.Name != "" {
+		return imp.Name + " " + strconv.Quote(imp.Path)
+	}
+	return strconv.Quote(imp.Path)
+}
+
+// Discover finds the components declared in the generated templ files within root.
+func Discover(root string) (components []Component, err error) {
+	err = filepath.WalkDir(root, func(currentPath string, info fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && currentPath != root && shouldSkipDir(currentPath) {
+			return filepath.SkipDir
+		}
+		if info.IsDir() || !strings.HasSuffix(currentPath, "_templ.go") {
+			return nil
+		}
+		dir, err := filepath.Rel(root, filepath.Dir(currentPath))
+		if err != nil {
+			return err
+		}
+		fc, err := discoverFile(currentPath)
+		if err != nil {
+			return err
+		}
+		for i := range fc {
+			fc[i].Dir = filepath.ToSlash(dir)
+		}
+		components = append(components, fc...)
+		return nil
+	})
+	sort.SliceStable(components, func(i, j int) bool {
+		if components[i].Dir != components[j].Dir {
+			return components[i].Dir < components[j].Dir
+		}
+		return components[i].Name < components[j].Name
+	})
+	return components, err
+}
+
+func shouldSkipDir(dir string) bool {
+	_, name := path.Split(filepath.ToSlash(dir))
+	if name == "vendor" || name == "node_modules" || name == "testdata" {
+		return true
+	}
+	// These directories are ignored by the Go tool.
+	return strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")
+}
+
+func discoverFile(fileName string) (components []Component, err error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, fileName, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	imports := map[string]Import{}
+	for _, spec := range f.Imports {
+		imp := Import{Path: strings.Trim(spec.Path.Value, "\"`")}
+		if spec.Name != nil {
+			imp.Name = spec.Name.Name
+		}
+		imports[importName(imp)] = imp
+	}
+	for _, decl := range f.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil || fn.Type.TypeParams != nil || !returnsComponent(fn.Type) {
+			continue
+		}
+		c := Component{
+			Package:  f.Name.Name,
+			Name:     fn.Name.Name,
+			FileName: fileName,
+		}
+		used := map[string]bool{}
+		for _, field := range fn.Type.Params.List {
+			typ := field.Type
+			var variadic bool
+			if ellipsis, ok := typ.(*ast.Ellipsis); ok {
+				typ, variadic = ellipsis.Elt, true
+			}
+			ast.Inspect(typ, func(n ast.Node) bool {
+				if sel, ok := n.(*ast.SelectorExpr); ok {
+					if id, ok := sel.X.(*ast.Ident); ok {
+						used[id.Name] = true
+					}
+				}
+				return true
+			})
+			var buf bytes.Buffer
+			if err = printer.Fprint(&buf, fset, typ); err != nil {
+				return nil, err
+			}
+			names := field.Names
+			if len(names) == 0 {
+				names = []*ast.Ident{ast.NewIdent("_")}
+			}
+			for _, name := range names {
+				c.Params = append(c.Params, Param{
+					Name:     name.Name,
+					Type:     buf.String(),
+					Variadic: variadic,
+					Sample:   sampleValue(typ, variadic),
+				})
+			}
+		}
+		for name := range used {
+			if imp, ok := imports[name]; ok {
+				c.Imports = append(c.Imports, imp)
+			}
+		}
+		sort.Slice(c.Imports, func(i, j int) bool { return c.Imports[i].Path < c.Imports[j].Path })
+		components = append(components, c)
+	}
+	return components, nil
+}
+
+// importName returns the name that an import is referred to by in code.
+func importName(imp Import) string {
+	if imp.Name != "" {
+		return imp.Name
+	}
+	elems := strings.Split(imp.Path, "/")
+	name := elems[len(elems)-1]
+	// Major version suffixes aren't part of the package name, e.g. example.com/pkg/v2.
+	if len(elems) > 1 && len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = elems[len(elems)-2]
+	}
+	return name
+}
+
+func returnsComponent(fn *ast.FuncType) bool {
+	if fn.Results == nil || len(fn.Results.List) != 1 || len(fn.Results.List[0].Names) > 1 {
+		return false
+	}
+	sel, ok := fn.Results.List[0].Type.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	return ok && pkg.Name == "templ" && sel.Sel.Name == "Component"
+}
+
+// sampleValue returns a JSON value that can be decoded into the type.
+func sampleValue(typ ast.Expr, variadic bool) string {
+	if variadic {
+		return "[]"
+	}
+	switch t := typ.(type) {
+	case *ast.Ident:
+		switch t.Name {
+		case "string":
+			return `""`
+		case "bool":
+			return "false"
+		case "int", "int8", "int16", "int32", "int64",
+			"uint", "uint8", "uint16", "uint32", "uint64",
+			"float32", "float64", "byte", "rune":
+			return "0"
+		}
+	case *ast.ArrayType:
+		return "[]"
+	case *ast.MapType:
+		return "{}"
+	case *ast.SelectorExpr:
+		if pkg, ok := t.X.(*ast.Ident); ok && pkg.Name == "templ" && t.Sel.Name == "Component" {
+			return `""`
+		}
+	}
+	return "null"
+}
@@ -0,0 +1,76 @@
+package previewcmd
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestDiscover(t *testing.T) {
+	components, err := Discover("testdata")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []Component{
+		{
+			Dir:     "components",
+			Package: "components",
+			Name:    "card",
+			Params: []Param{
+				{Name: "title", Type: "string", Sample: `""`},
+				{Name: "body", Type: "templ.Component", Sample: `""`},
+			},
+			Imports: []Import{{Path: "github.com/a-h/templ"}},
+		},
+		{
+			Dir:     "components",
+			Package: "components",
+			Name:    "hello",
+			Params: []Param{
+				{Name: "name", Type: "string", Sample: `""`},
+			},
+		},
+		{
+			Dir:     "components",
+			Package: "components",
+			Name:    "list",
+			Params: []Param{
+				{Name: "items", Type: "string", Variadic: true, Sample: "[]"},
+			},
+		},
+		{
+			Dir:     "components",
+			Package: "components",
+			Name:    "profile",
+			Params: []Param{
+				{Name: "u", Type: "User", Sample: "null"},
+				{Name: "since", Type: "time.Duration", Sample: "null"},
+			},
+			Imports: []Import{{Path: "time"}},
+		},
+	}
+	if diff := cmp.Diff(expected, components, cmpopts.IgnoreFields(Component{}, "FileName")); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestImportName(t *testing.T) {
+	tests := []struct {
+		imp      Import
+		expected string
+	}{
+		{imp: Import{Path: "time"}, expected: "time"},
+		{imp: Import{Path: "github.com/a-h/templ"}, expected: "templ"},
+		{imp: Import{Name: "tr", Path: "github.com/a-h/templ/runtime"}, expected: "tr"},
+		{imp: Import{Path: "example.com/pkg/v2"}, expected: "pkg"},
+		{imp: Import{Path: "example.com/v2"}, expected: "example.com"},
+	}
+	for _, test := range tests {
+		t.Run(test.imp.String(), func(t *testing.T) {
+			if actual := importName(test.imp); actual != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package previewcmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path/filepath"
+
+	"github.com/a-h/templ"
+)
+
+// Handler serves the preview UI, and renders components.
+type Handler struct {
+	Log *slog.Logger
+	// Path to discover components in.
+	Path string
+	// Render renders a component, defaults to Render.
+	Render func(ctx context.Context, dir string, components []Component, name string, args []byte) (html []byte, err error)
+}
+
+func NewHandler(log *slog.Logger, path string) *Handler {
+	return &Handler{
+		Log:    log,
+		Path:   path,
+		Render: Render,
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/":
+		h.serveIndex(w, r)
+	case "/render":
+		h.serveRender(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (h *Handler) serveIndex(w http.ResponseWriter, r *http.Request) {
+	components, err := Discover(h.Path)
+	if err != nil {
+		h.Log.Error("Failed to discover components", slog.Any("error", err))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var selected *Component
+	dir, name := r.URL.Query().Get("dir"), r.URL.Query().Get("name")
+	for i, c := range components {
+		if c.Dir == dir && c.Name == name {
+			selected = &components[i]
+			break
+		}
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err = previewPage(components, selected).Render(r.Context(), w); err != nil {
+		h.Log.Error("Failed to render preview page", slog.Any("error", err))
+	}
+}
+
+func (h *Handler) serveRender(w http.ResponseWriter, r *http.Request) {
+	components, err := Discover(h.Path)
+	if err != nil {
+		h.Log.Error("Failed to discover components", slog.Any("error", err))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	// Only render components of the package that was discovered, so that
+	// the dir parameter can't refer to other directories.
+	dir, name := r.URL.Query().Get("dir"), r.URL.Query().Get("name")
+	var pkg []Component
+	var found bool
+	for _, c := range components {
+		if c.Dir == dir {
+			pkg = append(pkg, c)
+			found = found || c.Name == name
+		}
+	}
+	if !found {
+		http.Error(w, fmt.Sprintf("component %q not found in %q", name, dir), http.StatusNotFound)
+		return
+	}
+	args := r.URL.Query().Get("args")
+	if args == "" {
+		args = "[]"
+	}
+	h.Log.Debug("Rendering component", slog.String("dir", dir), slog.String("name", name), slog.String("args", args))
+	html, err := h.Render(r.Context(), filepath.Join(h.Path, filepath.FromSlash(dir)), pkg, name, []byte(args))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(html)
+}
+
+func componentURL(c Component) templ.SafeURL {
+	q := url.Values{}
+	q.Set("dir", c.Dir)
+	q.Set("name", c.Name)
+	return templ.SafeURL("/?" + q.Encode())
+}
+
+func paramType(p Param) string {
+	if p.Variadic {
+		return "..." + p.Type
+	}
+	return p.Type
+}
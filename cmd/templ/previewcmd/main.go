@@ -0,0 +1,46 @@
+package previewcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/cli/browser"
+)
+
+type Arguments struct {
+	// Path to discover components in.
+	Path        string
+	Bind        string
+	Port        int
+	OpenBrowser bool
+}
+
+func Run(ctx context.Context, log *slog.Logger, args Arguments) (err error) {
+	url := fmt.Sprintf("http://%s:%d", args.Bind, args.Port)
+	server := &http.Server{
+		Addr:    fmt.Sprintf("%s:%d", args.Bind, args.Port),
+		Handler: NewHandler(log, args.Path),
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+	log.Info("Serving component previews", slog.String("url", url), slog.String("path", args.Path))
+	if args.OpenBrowser {
+		go func() {
+			if err := browser.OpenURL(url); err != nil {
+				log.Error("Failed to open browser", slog.Any("error", err))
+			}
+		}()
+	}
+	if err = server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,293 @@
+package previewcmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// previewTestFileName is the name of the test file that renders components.
+// It's added to the package using the Go tool's -overlay flag, so it's never
+// written to the package directory.
+const previewTestFileName = "templ_preview_test.go"
+
+var previewTestTemplate = template.Must(template.New("preview").Parse(`// Code generated by templ preview. DO NOT EDIT.
+
+package {{ .Package }}
+
+import (
+	templpreviewbytes "bytes"
+	templpreviewcontext "context"
+	templpreviewjson "encoding/json"
+	templpreviewos "os"
+	templpreviewruntime "runtime"
+	templpreviewpprof "runtime/pprof"
+	templpreviewstrconv "strconv"
+	templpreviewtesting "testing"
+	templpreviewtime "time"
+
+	templpreviewtempl "github.com/a-h/templ"
+{{ range .Imports }}
+	{{ . }}
+{{- end }}
+)
+
+func TestTemplPreview(t *templpreviewtesting.T) {
+	var args []templpreviewjson.RawMessage
+	if err := templpreviewjson.Unmarshal([]byte(templpreviewos.Getenv("TEMPL_PREVIEW_ARGS")), &args); err != nil {
+		t.Fatalf("failed to decode arguments: %v", err)
+	}
+	arg := func(i int, name string, v any) {
+		if i >= len(args) {
+			return
+		}
+		if err := templpreviewjson.Unmarshal(args[i], v); err != nil {
+			t.Fatalf("failed to decode %s: %v", name, err)
+		}
+	}
+	_ = arg
+	var c templpreviewtempl.Component
+	switch name := templpreviewos.Getenv("TEMPL_PREVIEW_COMPONENT"); name {
+{{- range .Components }}
+	case {{ printf "%q" .Name }}:
+	{{- range $i, $p := .Params }}
+		{{- if $p.Component }}
+		var p{{ $i }}html string
+		arg({{ $i }}, {{ printf "%q" $p.Name }}, &p{{ $i }}html)
+		var p{{ $i }} {{ $p.Type }} = templpreviewtempl.Raw(p{{ $i }}html)
+		{{- else if $p.Variadic }}
+		var p{{ $i }} []{{ $p.Type }}
+		arg({{ $i }}, {{ printf "%q" $p.Name }}, &p{{ $i }})
+		{{- else }}
+		var p{{ $i }} {{ $p.Type }}
+		arg({{ $i }}, {{ printf "%q" $p.Name }}, &p{{ $i }})
+		{{- end }}
+	{{- end }}
+		c = {{ .Name }}({{ range $i, $p := .Params }}{{ if $i }}, {{ end }}p{{ $i }}{{ if $p.Variadic }}...{{ end }}{{ end }})
+{{- end }}
+	default:
+		t.Fatalf("component %q not found", name)
+	}
+	ctx := templpreviewcontext.Background()
+	if iterations := templpreviewos.Getenv("TEMPL_PREVIEW_ITERATIONS"); iterations != "" {
+		templPreviewProfile(t, ctx, c, iterations)
+		return
+	}
+	f, err := templpreviewos.Create(templpreviewos.Getenv("TEMPL_PREVIEW_OUTPUT"))
+	if err != nil {
+		t.Fatalf("failed to create output file: %v", err)
+	}
+	defer f.Close()
+	if err = c.Render(ctx, f); err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+}
+
+func templPreviewProfile(t *templpreviewtesting.T, ctx templpreviewcontext.Context, c templpreviewtempl.Component, iterations string) {
+	n, err := templpreviewstrconv.Atoi(iterations)
+	if err != nil || n < 1 {
+		t.Fatalf("invalid number of iterations %q", iterations)
+	}
+	// Render once before measuring, so that the buffer has grown to the size of the output.
+	var buf templpreviewbytes.Buffer
+	if err = c.Render(ctx, &buf); err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+	outputBytes := buf.Len()
+	var cpuProfile *templpreviewos.File
+	if name := templpreviewos.Getenv("TEMPL_PREVIEW_CPUPROFILE"); name != "" {
+		if cpuProfile, err = templpreviewos.Create(name); err != nil {
+			t.Fatalf("failed to create CPU profile: %v", err)
+		}
+		defer cpuProfile.Close()
+		if err = templpreviewpprof.StartCPUProfile(cpuProfile); err != nil {
+			t.Fatalf("failed to start CPU profile: %v", err)
+		}
+	}
+	var before, after templpreviewruntime.MemStats
+	templpreviewruntime.GC()
+	templpreviewruntime.ReadMemStats(&before)
+	start := templpreviewtime.Now()
+	for i := 0; i < n; i++ {
+		buf.Reset()
+		if err = c.Render(ctx, &buf); err != nil {
+			t.Fatalf("failed to render: %v", err)
+		}
+	}
+	elapsed := templpreviewtime.Since(start)
+	templpreviewruntime.ReadMemStats(&after)
+	if cpuProfile != nil {
+		templpreviewpprof.StopCPUProfile()
+	}
+	if name := templpreviewos.Getenv("TEMPL_PREVIEW_MEMPROFILE"); name != "" {
+		f, err := templpreviewos.Create(name)
+		if err != nil {
+			t.Fatalf("failed to create memory profile: %v", err)
+		}
+		defer f.Close()
+		if err = templpreviewpprof.Lookup("allocs").WriteTo(f, 0); err != nil {
+			t.Fatalf("failed to write memory profile: %v", err)
+		}
+	}
+	result, err := templpreviewjson.Marshal(map[string]any{
+		"Iterations":  n,
+		"NsPerOp":     elapsed.Nanoseconds() / int64(n),
+		"AllocsPerOp": (after.Mallocs - before.Mallocs) / uint64(n),
+		"BytesPerOp":  (after.TotalAlloc - before.TotalAlloc) / uint64(n),
+		"OutputBytes": outputBytes,
+	})
+	if err != nil {
+		t.Fatalf("failed to encode result: %v", err)
+	}
+	if err = templpreviewos.WriteFile(templpreviewos.Getenv("TEMPL_PREVIEW_OUTPUT"), result, 0o644); err != nil {
+		t.Fatalf("failed to write result: %v", err)
+	}
+}
+`))
+
+// previewTestSource returns the source of a test that renders one of the
+// components of a package, selected by environment variables.
+func previewTestSource(components []Component) (src []byte, err error) {
+	if len(components) == 0 {
+		return nil, errors.New("no components to preview")
+	}
+	data := struct {
+		Package    string
+		Imports    []Import
+		Components []Component
+	}{
+		Package:    components[0].Package,
+		Components: components,
+	}
+	seen := map[Import]bool{}
+	for _, c := range components {
+		for _, imp := range c.Imports {
+			if !seen[imp] {
+				seen[imp] = true
+				data.Imports = append(data.Imports, imp)
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err = previewTestTemplate.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// Render renders the named component of the package in dir, using the Go
+// tool to build the package. args is a JSON array of the component's arguments.
+func Render(ctx context.Context, dir string, components []Component, name string, args []byte) (html []byte, err error) {
+	html, err = run(ctx, dir, components,
+		"TEMPL_PREVIEW_COMPONENT="+name,
+		"TEMPL_PREVIEW_ARGS="+string(args),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render %s: %w", name, err)
+	}
+	return html, nil
+}
+
+// ProfileOptions configure how a component is profiled.
+type ProfileOptions struct {
+	// Iterations is the number of times the component is rendered.
+	Iterations int
+	// CPUProfile is the path that a pprof CPU profile is written to, if set.
+	CPUProfile string
+	// MemProfile is the path that a pprof allocation profile is written to, if set.
+	MemProfile string
+}
+
+// ProfileResult is the performance of a component.
+type ProfileResult struct {
+	Iterations  int
+	NsPerOp     int64
+	AllocsPerOp int64
+	BytesPerOp  int64
+	// OutputBytes is the size of the rendered output.
+	OutputBytes int
+}
+
+// Profile renders the named component of the package in dir repeatedly, and
+// measures how long each render takes, and how much memory it allocates.
+func Profile(ctx context.Context, dir string, components []Component, name string, args []byte, opts ProfileOptions) (result ProfileResult, err error) {
+	env := []string{
+		"TEMPL_PREVIEW_COMPONENT=" + name,
+		"TEMPL_PREVIEW_ARGS=" + string(args),
+		"TEMPL_PREVIEW_ITERATIONS=" + strconv.Itoa(opts.Iterations),
+	}
+	for _, profile := range []struct{ env, fileName string }{
+		{env: "TEMPL_PREVIEW_CPUPROFILE", fileName: opts.CPUProfile},
+		{env: "TEMPL_PREVIEW_MEMPROFILE", fileName: opts.MemProfile},
+	} {
+		if profile.fileName == "" {
+			continue
+		}
+		// The test runs in the package directory, so relative paths must be resolved first.
+		abs, err := filepath.Abs(profile.fileName)
+		if err != nil {
+			return result, err
+		}
+		env = append(env, profile.env+"="+abs)
+	}
+	output, err := run(ctx, dir, components, env...)
+	if err != nil {
+		return result, fmt.Errorf("failed to profile %s: %w", name, err)
+	}
+	err = json.Unmarshal(output, &result)
+	return result, err
+}
+
+// run builds the package in dir with the preview test added, and runs the
+// test with the environment variables, returning the test's output file.
+func run(ctx context.Context, dir string, components []Component, env ...string) (output []byte, err error) {
+	src, err := previewTestSource(components)
+	if err != nil {
+		return nil, err
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	tmp, err := os.MkdirTemp("", "templ-preview-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	testFileName := filepath.Join(tmp, previewTestFileName)
+	if err = os.WriteFile(testFileName, src, 0o644); err != nil {
+		return nil, err
+	}
+	overlay, err := json.Marshal(map[string]any{
+		"Replace": map[string]string{
+			filepath.Join(absDir, previewTestFileName): testFileName,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	overlayFileName := filepath.Join(tmp, "overlay.json")
+	if err = os.WriteFile(overlayFileName, overlay, 0o644); err != nil {
+		return nil, err
+	}
+	outputFileName := filepath.Join(tmp, "output")
+
+	cmd := exec.CommandContext(ctx, "go", "test", "-count=1", "-run", "^TestTemplPreview$", "-overlay", overlayFileName, ".")
+	cmd.Dir = absDir
+	cmd.Env = append(append(os.Environ(), env...), "TEMPL_PREVIEW_OUTPUT="+outputFileName)
+	testOutput, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("%w\n%s", err, strings.TrimSpace(string(testOutput)))
+	}
+	return os.ReadFile(outputFileName)
+}
@@ -0,0 +1,147 @@
+package previewcmd
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test that builds a package in short mode")
+	}
+	components, err := Discover("testdata")
+	if err != nil {
+		t.Fatalf("failed to discover components: %v", err)
+	}
+	dir := filepath.Join("testdata", "components")
+	tests := []struct {
+		name     string
+		args     string
+		expected string
+	}{
+		{
+			name:     "hello",
+			args:     `["World"]`,
+			expected: "<p>Hello, World</p>",
+		},
+		{
+			name:     "list",
+			args:     `[["a", "b"]]`,
+			expected: "<ul><li>a</li><li>b</li></ul>",
+		},
+		{
+			name:     "card",
+			args:     `["Title", "<b>Body</b>"]`,
+			expected: "<div><h1>Title</h1><b>Body</b></div>",
+		},
+		{
+			name:     "profile",
+			args:     `[{"name": "Alice"}, 60000000000]`,
+			expected: "<p>Alice has been a member for 1m0s</p>",
+		},
+		{
+			name:     "hello",
+			args:     `[]`,
+			expected: "<p>Hello, </p>",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			html, err := Render(context.Background(), dir, components, test.name, []byte(test.args))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(html) != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, string(html))
+			}
+		})
+	}
+	t.Run("invalid arguments return an error", func(t *testing.T) {
+		_, err := Render(context.Background(), dir, components, "hello", []byte(`[123]`))
+		if err == nil || !strings.Contains(err.Error(), "failed to decode name") {
+			t.Errorf("expected decode error, got %v", err)
+		}
+	})
+}
+
+func TestProfile(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test that builds a package in short mode")
+	}
+	components, err := Discover("testdata")
+	if err != nil {
+		t.Fatalf("failed to discover components: %v", err)
+	}
+	cpuProfile := filepath.Join(t.TempDir(), "cpu.pprof")
+	result, err := Profile(context.Background(), filepath.Join("testdata", "components"), components, "hello", []byte(`["World"]`), ProfileOptions{
+		Iterations: 10,
+		CPUProfile: cpuProfile,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Iterations != 10 {
+		t.Errorf("expected 10 iterations, got %d", result.Iterations)
+	}
+	if expected := len("<p>Hello, World</p>"); result.OutputBytes != expected {
+		t.Errorf("expected output of %d bytes, got %d", expected, result.OutputBytes)
+	}
+	if result.NsPerOp <= 0 {
+		t.Errorf("expected a positive duration, got %d", result.NsPerOp)
+	}
+	if _, err = os.Stat(cpuProfile); err != nil {
+		t.Errorf("expected CPU profile to be written: %v", err)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	h := NewHandler(slog.New(slog.NewJSONHandler(io.Discard, nil)), "testdata")
+	var renderedDir, renderedName, renderedArgs string
+	h.Render = func(ctx context.Context, dir string, components []Component, name string, args []byte) ([]byte, error) {
+		renderedDir, renderedName, renderedArgs = dir, name, string(args)
+		return []byte("<p>rendered</p>"), nil
+	}
+	t.Run("the index lists components", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?dir=components&name=hello", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", w.Code)
+		}
+		body := w.Body.String()
+		for _, expected := range []string{">card</a>", ">profile</a>", "<h2>components.hello</h2>", "<textarea rows=\"2\">&#34;&#34;</textarea>"} {
+			if !strings.Contains(body, expected) {
+				t.Errorf("expected %q in body:\n%s", expected, body)
+			}
+		}
+	})
+	t.Run("components are rendered", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		q := url.Values{"dir": {"components"}, "name": {"hello"}, "args": {`["World"]`}}
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/render?"+q.Encode(), nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", w.Code)
+		}
+		if w.Body.String() != "<p>rendered</p>" {
+			t.Errorf("unexpected body %q", w.Body.String())
+		}
+		if renderedDir != filepath.Join("testdata", "components") || renderedName != "hello" || renderedArgs != `["World"]` {
+			t.Errorf("unexpected render of %q %q %q", renderedDir, renderedName, renderedArgs)
+		}
+	})
+	t.Run("unknown components are not found", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		q := url.Values{"dir": {".."}, "name": {"hello"}}
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/render?"+q.Encode(), nil))
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected status 404, got %d", w.Code)
+		}
+	})
+}
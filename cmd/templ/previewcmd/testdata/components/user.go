@@ -0,0 +1,5 @@
+package components
+
+type User struct {
+	Name string `json:"name"`
+}
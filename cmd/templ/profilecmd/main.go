@@ -0,0 +1,107 @@
+package profilecmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/a-h/templ/cmd/templ/previewcmd"
+)
+
+type Arguments struct {
+	// Path of the package that contains the component.
+	Path string
+	// Component is the name of the component's function.
+	Component string
+	// Props are the component's arguments, as a JSON array, or a JSON object
+	// keyed by parameter name.
+	Props      string
+	Iterations int
+	CPUProfile string
+	MemProfile string
+}
+
+func Run(ctx context.Context, w io.Writer, args Arguments) (err error) {
+	components, err := previewcmd.Discover(args.Path)
+	if err != nil {
+		return fmt.Errorf("failed to discover components: %w", err)
+	}
+	// Only the components of the package in the path can be profiled.
+	var pkg []previewcmd.Component
+	for _, c := range components {
+		if c.Dir == "." {
+			pkg = append(pkg, c)
+		}
+	}
+	c, ok := find(pkg, args.Component)
+	if !ok {
+		return fmt.Errorf("component %q not found in %s, run templ generate to update the generated code", args.Component, args.Path)
+	}
+	props, err := positionalProps(c, args.Props)
+	if err != nil {
+		return err
+	}
+	result, err := previewcmd.Profile(ctx, args.Path, pkg, c.Name, props, previewcmd.ProfileOptions{
+		Iterations: args.Iterations,
+		CPUProfile: args.CPUProfile,
+		MemProfile: args.MemProfile,
+	})
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 1, ' ', 0)
+	fmt.Fprintf(tw, "Component:\t%s.%s\n", c.Package, c.Name)
+	fmt.Fprintf(tw, "Iterations:\t%d\n", result.Iterations)
+	fmt.Fprintf(tw, "Time:\t%d ns/op\n", result.NsPerOp)
+	fmt.Fprintf(tw, "Allocations:\t%d allocs/op\n", result.AllocsPerOp)
+	fmt.Fprintf(tw, "Memory:\t%d B/op\n", result.BytesPerOp)
+	fmt.Fprintf(tw, "Output size:\t%d bytes\n", result.OutputBytes)
+	if args.CPUProfile != "" {
+		fmt.Fprintf(tw, "CPU profile:\t%s\n", args.CPUProfile)
+	}
+	if args.MemProfile != "" {
+		fmt.Fprintf(tw, "Memory profile:\t%s\n", args.MemProfile)
+	}
+	return tw.Flush()
+}
+
+func find(components []previewcmd.Component, name string) (c previewcmd.Component, ok bool) {
+	for _, c := range components {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return c, false
+}
+
+// positionalProps converts props that are a JSON object keyed by parameter
+// name to a JSON array of arguments.
+func positionalProps(c previewcmd.Component, props string) (args []byte, err error) {
+	props = strings.TrimSpace(props)
+	if props == "" {
+		return []byte("[]"), nil
+	}
+	if !strings.HasPrefix(props, "{") {
+		return []byte(props), nil
+	}
+	var named map[string]json.RawMessage
+	if err = json.Unmarshal([]byte(props), &named); err != nil {
+		return nil, fmt.Errorf("invalid props: %w", err)
+	}
+	positional := make([]json.RawMessage, len(c.Params))
+	for i, p := range c.Params {
+		v, ok := named[p.Name]
+		if !ok {
+			v = json.RawMessage(p.Sample)
+		}
+		positional[i] = v
+		delete(named, p.Name)
+	}
+	for name := range named {
+		return nil, fmt.Errorf("invalid props: %s has no parameter named %q", c.Name, name)
+	}
+	return json.Marshal(positional)
+}
@@ -0,0 +1,81 @@
+package profilecmd
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ/cmd/templ/previewcmd"
+)
+
+func TestPositionalProps(t *testing.T) {
+	c := previewcmd.Component{
+		Name: "profile",
+		Params: []previewcmd.Param{
+			{Name: "u", Type: "User", Sample: "null"},
+			{Name: "since", Type: "time.Duration", Sample: "0"},
+		},
+	}
+	tests := []struct {
+		name        string
+		props       string
+		expected    string
+		expectedErr string
+	}{
+		{name: "no props", props: "", expected: "[]"},
+		{name: "arrays are unchanged", props: `[{"name": "Alice"}, 1]`, expected: `[{"name": "Alice"}, 1]`},
+		{name: "objects are converted to arrays", props: `{"since": 1, "u": {"name": "Alice"}}`, expected: `[{"name":"Alice"},1]`},
+		{name: "missing props use the sample value", props: `{"since": 1}`, expected: `[null,1]`},
+		{name: "unknown props are an error", props: `{"user": {}}`, expectedErr: `profile has no parameter named "user"`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := positionalProps(c, test.props)
+			if test.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.expectedErr) {
+					t.Fatalf("expected error %q, got %v", test.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(actual) != test.expected {
+				t.Errorf("expected %s, got %s", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test that builds a package in short mode")
+	}
+	var stdout bytes.Buffer
+	err := Run(context.Background(), &stdout, Arguments{
+		Path:       filepath.Join("..", "previewcmd", "testdata", "components"),
+		Component:  "hello",
+		Props:      `{"name": "World"}`,
+		Iterations: 10,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, expected := range []string{"Component:   components.hello\n", "Iterations:  10\n", "Output size: 19 bytes\n"} {
+		if !strings.Contains(stdout.String(), expected) {
+			t.Errorf("expected %q in output:\n%s", expected, stdout.String())
+		}
+	}
+	t.Run("unknown components are an error", func(t *testing.T) {
+		err := Run(context.Background(), &bytes.Buffer{}, Arguments{
+			Path:       filepath.Join("..", "previewcmd", "testdata", "components"),
+			Component:  "missing",
+			Iterations: 1,
+		})
+		if err == nil || !strings.Contains(err.Error(), `component "missing" not found`) {
+			t.Errorf("expected not found error, got %v", err)
+		}
+	})
+}
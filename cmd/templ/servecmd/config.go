@@ -0,0 +1,139 @@
+package servecmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// DefaultConfigFileName is the name of the config file that's loaded if no
+// config file is specified.
+const DefaultConfigFileName = "templ.serve.json"
+
+// Config of templ serve.
+type Config struct {
+	// Path to watch, and generate templ files within. Relative paths are
+	// relative to the directory of the config file. (default .)
+	Path string `json:"path"`
+	// Build configures how the app is built.
+	Build BuildConfig `json:"build"`
+	// Run configures how the app is run.
+	Run RunConfig `json:"run"`
+	// Proxy configures the live reload proxy in front of the app.
+	Proxy ProxyConfig `json:"proxy"`
+	// Static configures the static assets served by the proxy.
+	Static StaticConfig `json:"static"`
+	// Generate configures templ generate.
+	Generate GenerateConfig `json:"generate"`
+	// Watch configures the files that are watched.
+	Watch WatchConfig `json:"watch"`
+}
+
+type BuildConfig struct {
+	// Package to build, relative to the path. (default .)
+	Package string `json:"package"`
+	// Output is the file name of the binary. (default a temporary file)
+	Output string `json:"output"`
+	// Flags are passed to go build, e.g. ["-tags", "dev"].
+	Flags []string `json:"flags"`
+}
+
+type RunConfig struct {
+	// Args are passed to the app.
+	Args []string `json:"args"`
+	// Env is added to the environment of the app, e.g. {"PORT": "8080"}.
+	Env map[string]string `json:"env"`
+}
+
+type ProxyConfig struct {
+	// Target is the URL of the app. (default http://127.0.0.1:8080)
+	Target string `json:"target"`
+	// Bind is the address the proxy listens on. (default 127.0.0.1)
+	Bind string `json:"bind"`
+	// Port is the port the proxy listens on. (default 7331)
+	Port int `json:"port"`
+}
+
+type StaticConfig struct {
+	// Dir is the directory of static assets, relative to the path. Changes
+	// to the files within it reload the browser without rebuilding the app.
+	Dir string `json:"dir"`
+	// Prefix is the URL path the assets are served from by the proxy, e.g.
+	// /static/. If it's empty, the app serves the assets.
+	Prefix string `json:"prefix"`
+}
+
+type GenerateConfig struct {
+	// HotReload renders changed templates with an interpreter, instead of
+	// rebuilding the app, when only the contents of templates change.
+	HotReload bool `json:"hotReload"`
+	// DebugMarkers wraps the output of each component in HTML comments.
+	DebugMarkers bool `json:"debugMarkers"`
+}
+
+type WatchConfig struct {
+	// Extensions of files, in addition to .go files, whose changes rebuild
+	// the app, e.g. [".sql"] for files that are embedded in the binary.
+	Extensions []string `json:"extensions"`
+	// Exclude is the names of directories that aren't watched, in addition
+	// to vendor, node_modules, and directories that start with . or _.
+	Exclude []string `json:"exclude"`
+}
+
+// LoadConfig loads the config file, and sets the defaults of any values that
+// aren't set. If fileName is empty, the default config file is loaded from
+// the current directory, if it exists.
+func LoadConfig(fileName string) (c Config, err error) {
+	required := fileName != ""
+	if !required {
+		fileName = DefaultConfigFileName
+	}
+	data, err := os.ReadFile(fileName)
+	if err != nil && (required || !errors.Is(err, fs.ErrNotExist)) {
+		return c, fmt.Errorf("failed to read config: %w", err)
+	}
+	if err == nil {
+		if err = json.Unmarshal(data, &c); err != nil {
+			return c, fmt.Errorf("failed to parse config %s: %w", fileName, err)
+		}
+	}
+	if c.Path == "" {
+		c.Path = "."
+	}
+	if !filepath.IsAbs(c.Path) {
+		c.Path = filepath.Join(filepath.Dir(fileName), c.Path)
+	}
+	if c.Path, err = filepath.Abs(c.Path); err != nil {
+		return c, fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	if c.Build.Package == "" {
+		c.Build.Package = "."
+	}
+	if c.Build.Output == "" {
+		name := "app"
+		if runtime.GOOS == "windows" {
+			name += ".exe"
+		}
+		c.Build.Output = filepath.Join(os.TempDir(), "templ-serve", filepath.Base(c.Path), name)
+	}
+	if !filepath.IsAbs(c.Build.Output) {
+		c.Build.Output = filepath.Join(c.Path, c.Build.Output)
+	}
+	if c.Proxy.Target == "" {
+		c.Proxy.Target = "http://127.0.0.1:8080"
+	}
+	if c.Proxy.Bind == "" {
+		c.Proxy.Bind = "127.0.0.1"
+	}
+	if c.Proxy.Port == 0 {
+		c.Proxy.Port = 7331
+	}
+	if c.Static.Dir != "" && !filepath.IsAbs(c.Static.Dir) {
+		c.Static.Dir = filepath.Join(c.Path, c.Static.Dir)
+	}
+	return c, nil
+}
@@ -0,0 +1,95 @@
+package servecmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("values are relative to the config file", func(t *testing.T) {
+		dir := t.TempDir()
+		fileName := filepath.Join(dir, "templ.serve.json")
+		config := `{
+			"path": "site",
+			"build": {"package": "./cmd/server", "output": "tmp/server", "flags": ["-tags", "dev"]},
+			"run": {"args": ["-dev"], "env": {"PORT": "8080"}},
+			"proxy": {"target": "http://localhost:8080", "port": 7000},
+			"static": {"dir": "assets", "prefix": "/assets/"},
+			"watch": {"extensions": [".sql"]}
+		}`
+		if err := os.WriteFile(fileName, []byte(config), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		c, err := LoadConfig(fileName)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := Config{
+			Path: filepath.Join(dir, "site"),
+			Build: BuildConfig{
+				Package: "./cmd/server",
+				Output:  filepath.Join(dir, "site", "tmp", "server"),
+				Flags:   []string{"-tags", "dev"},
+			},
+			Run: RunConfig{
+				Args: []string{"-dev"},
+				Env:  map[string]string{"PORT": "8080"},
+			},
+			Proxy: ProxyConfig{
+				Target: "http://localhost:8080",
+				Bind:   "127.0.0.1",
+				Port:   7000,
+			},
+			Static: StaticConfig{
+				Dir:    filepath.Join(dir, "site", "assets"),
+				Prefix: "/assets/",
+			},
+			Watch: WatchConfig{
+				Extensions: []string{".sql"},
+			},
+		}
+		if diff := cmp.Diff(expected, c); diff != "" {
+			t.Error(diff)
+		}
+	})
+	t.Run("defaults are set", func(t *testing.T) {
+		dir := t.TempDir()
+		fileName := filepath.Join(dir, "templ.serve.json")
+		if err := os.WriteFile(fileName, []byte(`{}`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		c, err := LoadConfig(fileName)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if c.Path != dir {
+			t.Errorf("expected the path to default to %q, got %q", dir, c.Path)
+		}
+		if !filepath.IsAbs(c.Build.Output) {
+			t.Errorf("expected the output to be an absolute path, got %q", c.Build.Output)
+		}
+		if c.Build.Package != "." || c.Proxy.Target != "http://127.0.0.1:8080" || c.Proxy.Bind != "127.0.0.1" || c.Proxy.Port != 7331 {
+			t.Errorf("expected defaults to be set, got %#v", c)
+		}
+	})
+	t.Run("specified config files must exist", func(t *testing.T) {
+		_, err := LoadConfig(filepath.Join(t.TempDir(), "missing.json"))
+		if err == nil {
+			t.Error("expected an error")
+		}
+	})
+	t.Run("invalid config files return an error", func(t *testing.T) {
+		fileName := filepath.Join(t.TempDir(), "templ.serve.json")
+		if err := os.WriteFile(fileName, []byte(`{"path": 1}`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := LoadConfig(fileName)
+		if err == nil || !strings.Contains(err.Error(), "failed to parse config") {
+			t.Errorf("expected a parse error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,246 @@
+package servecmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/a-h/templ/cmd/templ/generatecmd"
+	"github.com/a-h/templ/cmd/templ/generatecmd/proxy"
+	"github.com/cli/browser"
+)
+
+type Arguments struct {
+	// ConfigFile is the name of the config file. If it's empty, the default
+	// config file is loaded, if it exists.
+	ConfigFile  string
+	OpenBrowser bool
+	WorkerCount int
+}
+
+// buildErrorKey is the key of build errors in the proxy's error overlay.
+const buildErrorKey = "go build"
+
+// Run generates code from templ files, builds and runs the app, and serves it
+// through a live reload proxy, rebuilding the app and reloading the browser
+// when files change.
+func Run(ctx context.Context, log *slog.Logger, args Arguments) (err error) {
+	c, err := LoadConfig(args.ConfigFile)
+	if err != nil {
+		return err
+	}
+	target, err := url.Parse(c.Proxy.Target)
+	if err != nil {
+		return fmt.Errorf("failed to parse proxy target: %w", err)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Start watching before code is generated, so that the changes made by
+	// the first generation rebuild the app.
+	w, err := c.newWatcher(log)
+	if err != nil {
+		return fmt.Errorf("failed to watch files: %w", err)
+	}
+	actions := make(chan action)
+	go w.run(ctx, actions)
+
+	// Generate code, and regenerate it when templ files change. The generated
+	// files are watched to rebuild the app.
+	var wg sync.WaitGroup
+	generateErr := make(chan error, 1)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		generateErr <- generatecmd.Run(ctx, log, generatecmd.Arguments{
+			Path:             c.Path,
+			Watch:            true,
+			WorkerCount:      args.WorkerCount,
+			IncludeVersion:   true,
+			IncludeSourceMap: true,
+			DebugMarkers:     c.Generate.DebugMarkers,
+			HotReload:        c.Generate.HotReload,
+		})
+	}()
+	defer func() {
+		// templ generate regenerates the code in production mode once the
+		// context is cancelled.
+		cancel()
+		wg.Wait()
+	}()
+
+	p := proxy.New(log, c.Proxy.Bind, c.Proxy.Port, target)
+	server := &http.Server{
+		Addr:    fmt.Sprintf("%s:%d", c.Proxy.Bind, c.Proxy.Port),
+		Handler: c.handler(p),
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer shutdownCancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+	go func() {
+		log.Info("Proxying", slog.String("from", p.URL), slog.String("to", target.String()))
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("Proxy failed", slog.Any("error", err))
+			cancel()
+		}
+	}()
+
+	app := &app{log: log, config: c}
+	defer app.stop()
+	app.rebuild(ctx, p)
+	if args.OpenBrowser {
+		go func() {
+			if err := browser.OpenURL(p.URL); err != nil {
+				log.Error("Failed to open browser", slog.Any("error", err))
+			}
+		}()
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-generateErr:
+			return err
+		case a := <-actions:
+			if a == actionRebuild {
+				app.rebuild(ctx, p)
+				continue
+			}
+			log.Debug("Reloading browser")
+			p.SendSSE("message", "reload")
+		}
+	}
+}
+
+// handler returns the handler of the proxy, which serves static assets, if
+// configured, and proxies all other requests to the app.
+func (c Config) handler(p *proxy.Handler) http.Handler {
+	if c.Static.Dir == "" || c.Static.Prefix == "" {
+		return p
+	}
+	prefix := "/" + strings.Trim(c.Static.Prefix, "/") + "/"
+	mux := http.NewServeMux()
+	mux.Handle(prefix, http.StripPrefix(prefix, http.FileServer(http.Dir(c.Static.Dir))))
+	mux.Handle("/", p)
+	return mux
+}
+
+// app builds and runs the app.
+type app struct {
+	log    *slog.Logger
+	config Config
+	cmd    *exec.Cmd
+	exited chan struct{}
+}
+
+// rebuild the app, and restart it if the build succeeds. Build errors are
+// shown in the browser.
+func (a *app) rebuild(ctx context.Context, p *proxy.Handler) {
+	start := time.Now()
+	a.log.Info("Building", slog.String("package", a.config.Build.Package))
+	if err := a.build(ctx); err != nil {
+		a.log.Error("Build failed", slog.Any("error", err))
+		p.SetError(buildErrorKey, err)
+		p.SendSSE("message", "reload")
+		return
+	}
+	p.SetError(buildErrorKey, nil)
+	a.stop()
+	if err := a.start(); err != nil {
+		a.log.Error("Failed to start app", slog.Any("error", err))
+		return
+	}
+	a.log.Info("Started", slog.Duration("duration", time.Since(start)))
+	// The proxy retries requests until the app is listening.
+	p.SendSSE("message", "reload")
+}
+
+func (a *app) build(ctx context.Context) error {
+	if err := os.MkdirAll(filepath.Dir(a.config.Build.Output), 0o755); err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, "go", a.config.buildArgs()...)
+	cmd.Dir = a.config.Path
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%w\n%s", err, output.String())
+	}
+	return nil
+}
+
+// buildArgs returns the arguments of go build. Hot reloading requires the
+// templ_hotreload build tag, which is added to the build flags.
+func (c Config) buildArgs() []string {
+	flags := slices.Clone(c.Build.Flags)
+	if c.Generate.HotReload {
+		flags = addBuildTag(flags, "templ_hotreload")
+	}
+	args := append([]string{"build", "-o", c.Build.Output}, flags...)
+	return append(args, c.Build.Package)
+}
+
+// addBuildTag adds the tag to the -tags flag, or adds a -tags flag if there
+// isn't one.
+func addBuildTag(flags []string, tag string) []string {
+	for i, f := range flags {
+		if (f == "-tags" || f == "--tags") && i+1 < len(flags) {
+			flags[i+1] += "," + tag
+			return flags
+		}
+		if strings.HasPrefix(f, "-tags=") || strings.HasPrefix(f, "--tags=") {
+			flags[i] += "," + tag
+			return flags
+		}
+	}
+	return append(flags, "-tags", tag)
+}
+
+func (a *app) start() error {
+	cmd := exec.Command(a.config.Build.Output, a.config.Run.Args...)
+	cmd.Dir = a.config.Path
+	cmd.Env = os.Environ()
+	for k, v := range a.config.Run.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		if err := cmd.Wait(); err != nil {
+			a.log.Debug("App exited", slog.Any("error", err))
+		}
+	}()
+	a.cmd, a.exited = cmd, exited
+	return nil
+}
+
+// stop the app, if it's running, and wait for it to exit.
+func (a *app) stop() {
+	if a.cmd == nil {
+		return
+	}
+	a.log.Debug("Stopping app", slog.Int("pid", a.cmd.Process.Pid))
+	_ = a.cmd.Process.Kill()
+	<-a.exited
+	a.cmd, a.exited = nil, nil
+}
@@ -0,0 +1,54 @@
+package servecmd
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBuildArgs(t *testing.T) {
+	tests := []struct {
+		name      string
+		flags     []string
+		hotReload bool
+		expected  []string
+	}{
+		{
+			name:     "flags are passed to go build",
+			flags:    []string{"-race"},
+			expected: []string{"build", "-o", "tmp/app", "-race", "."},
+		},
+		{
+			name:      "hot reloading adds the build tag",
+			hotReload: true,
+			expected:  []string{"build", "-o", "tmp/app", "-tags", "templ_hotreload", "."},
+		},
+		{
+			name:      "hot reloading adds the build tag to the existing tags",
+			flags:     []string{"-tags", "dev"},
+			hotReload: true,
+			expected:  []string{"build", "-o", "tmp/app", "-tags", "dev,templ_hotreload", "."},
+		},
+		{
+			name:      "hot reloading adds the build tag to the existing tags with an equals sign",
+			flags:     []string{"-tags=dev"},
+			hotReload: true,
+			expected:  []string{"build", "-o", "tmp/app", "-tags=dev,templ_hotreload", "."},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			c := Config{
+				Build:    BuildConfig{Package: ".", Output: "tmp/app", Flags: tt.flags},
+				Generate: GenerateConfig{HotReload: tt.hotReload},
+			}
+			if diff := cmp.Diff(tt.expected, c.buildArgs()); diff != "" {
+				t.Error(diff)
+			}
+			if diff := cmp.Diff(tt.flags, c.Build.Flags); diff != "" {
+				t.Errorf("expected the configured flags not to be modified: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package servecmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/a-h/templ/cmd/templ/generatecmd"
+	"github.com/a-h/templ/parser/v2"
+	"github.com/fsnotify/fsnotify"
+)
+
+// action is what to do when a file changes.
+type action int
+
+const (
+	actionNone action = iota
+	// actionReload reloads the browser.
+	actionReload
+	// actionRebuild rebuilds and restarts the app, then reloads the browser.
+	actionRebuild
+)
+
+// actionForFile returns what to do when the file changes.
+func (c Config) actionForFile(fileName string) action {
+	switch {
+	case strings.HasSuffix(fileName, ".templ"):
+		// templ generate updates the generated files, which are watched.
+		return actionNone
+	case strings.HasSuffix(fileName, "_templ.txt"):
+		// The text of templates is read from the file in watch mode.
+		return actionReload
+	case strings.HasSuffix(fileName, ".go"):
+		return actionRebuild
+	case c.Static.Dir != "" && isWithin(c.Static.Dir, fileName):
+		return actionReload
+	case slices.Contains(c.Watch.Extensions, filepath.Ext(fileName)):
+		return actionRebuild
+	}
+	return actionNone
+}
+
+func isWithin(dir, fileName string) bool {
+	rel, err := filepath.Rel(dir, fileName)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// shouldSkipDir returns true if the directory isn't watched.
+func (c Config) shouldSkipDir(dir string) bool {
+	if dir == c.Path {
+		return false
+	}
+	name := filepath.Base(dir)
+	if name == "vendor" || name == "node_modules" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+		return true
+	}
+	return slices.Contains(c.Watch.Exclude, name)
+}
+
+// watcher watches the path for changes.
+type watcher struct {
+	config Config
+	log    *slog.Logger
+	w      *fsnotify.Watcher
+	// signatures are the signature hashes of the templ files whose generated
+	// code has been written, used to hot reload changes to templates.
+	signatures map[string][sha256.Size]byte
+}
+
+// action returns what to do when the file changes. If hot reloading is
+// enabled, generated code that's written because the contents of templates
+// have changed reloads the browser, since the changes are hot reloaded, but
+// other changes to the generated code rebuild the app.
+func (w *watcher) action(fileName string) action {
+	a := w.config.actionForFile(fileName)
+	if a != actionRebuild || !w.config.Generate.HotReload || !strings.HasSuffix(fileName, "_templ.go") {
+		return a
+	}
+	templFileName := strings.TrimSuffix(fileName, "_templ.go") + ".templ"
+	t, err := parser.Parse(templFileName)
+	if err != nil {
+		return actionRebuild
+	}
+	sum, err := generatecmd.SignatureHash(t)
+	if err != nil {
+		return actionRebuild
+	}
+	previous, ok := w.signatures[templFileName]
+	w.signatures[templFileName] = sum
+	if ok && previous == sum {
+		return actionReload
+	}
+	return actionRebuild
+}
+
+// newWatcher starts watching the path, so that changes made after it returns
+// aren't missed.
+func (c Config) newWatcher(log *slog.Logger) (w *watcher, err error) {
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	w = &watcher{config: c, log: log, w: fw, signatures: make(map[string][sha256.Size]byte)}
+	if err = w.add(c.Path); err != nil {
+		fw.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// add the directory, and its subdirectories, to the watcher.
+func (w *watcher) add(root string) error {
+	return filepath.WalkDir(root, func(dir string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if w.config.shouldSkipDir(dir) {
+			return filepath.SkipDir
+		}
+		return w.w.Add(dir)
+	})
+}
+
+// run sends the action to take for each batch of changes, once the files
+// have stopped changing, until the context is cancelled.
+func (w *watcher) run(ctx context.Context, actions chan<- action) {
+	defer w.w.Close()
+	var pending action
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case err := <-w.w.Errors:
+			w.log.Error("Watcher error", slog.Any("error", err))
+		case event := <-w.w.Events:
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if err := w.add(event.Name); err != nil {
+						w.log.Error("Failed to watch directory", slog.String("dir", event.Name), slog.Any("error", err))
+					}
+					continue
+				}
+			}
+			a := w.action(event.Name)
+			if a == actionNone {
+				continue
+			}
+			w.log.Debug("File changed", slog.String("file", event.Name))
+			pending = max(pending, a)
+			// Wait for changes to settle, e.g. while templ generate writes
+			// several files.
+			timer.Reset(100 * time.Millisecond)
+		case <-timer.C:
+			select {
+			case actions <- pending:
+			case <-ctx.Done():
+				return
+			}
+			pending = actionNone
+		}
+	}
+}
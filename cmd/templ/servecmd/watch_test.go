@@ -0,0 +1,91 @@
+package servecmd
+
+import (
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestActionForFile(t *testing.T) {
+	root := filepath.Join(string(filepath.Separator), "site")
+	c := Config{
+		Path:   root,
+		Static: StaticConfig{Dir: filepath.Join(root, "static")},
+		Watch:  WatchConfig{Extensions: []string{".sql"}, Exclude: []string{"tmp"}},
+	}
+	tests := []struct {
+		fileName string
+		expected action
+	}{
+		{fileName: "main.go", expected: actionRebuild},
+		{fileName: "views/home_templ.go", expected: actionRebuild},
+		{fileName: "views/home.templ", expected: actionNone},
+		{fileName: "views/home_templ.txt", expected: actionReload},
+		{fileName: "static/site.css", expected: actionReload},
+		{fileName: "staticfiles/site.css", expected: actionNone},
+		{fileName: "db/queries.sql", expected: actionRebuild},
+		{fileName: "README.md", expected: actionNone},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.fileName, func(t *testing.T) {
+			if actual := c.actionForFile(filepath.Join(root, filepath.FromSlash(tt.fileName))); actual != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, actual)
+			}
+		})
+	}
+	t.Run("directories are skipped", func(t *testing.T) {
+		for _, dir := range []string{".git", "node_modules", "vendor", "_build", "tmp"} {
+			if !c.shouldSkipDir(filepath.Join(root, dir)) {
+				t.Errorf("expected %s to be skipped", dir)
+			}
+		}
+		for _, dir := range []string{"", "views"} {
+			if c.shouldSkipDir(filepath.Join(root, dir)) {
+				t.Errorf("expected %q to be watched", dir)
+			}
+		}
+	})
+}
+
+func TestHotReloadAction(t *testing.T) {
+	dir := t.TempDir()
+	templFileName := filepath.Join(dir, "home.templ")
+	goFileName := filepath.Join(dir, "home_templ.go")
+	write := func(contents string) {
+		t.Helper()
+		if err := os.WriteFile(templFileName, []byte(contents), 0o644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+	newWatcher := func(hotReload bool) *watcher {
+		c := Config{Path: dir, Generate: GenerateConfig{HotReload: hotReload}}
+		return &watcher{config: c, signatures: make(map[string][sha256.Size]byte)}
+	}
+
+	t.Run("changes to the contents of templates are hot reloaded", func(t *testing.T) {
+		w := newWatcher(true)
+		write("package main\n\ntempl home(name string) {\n\t<h1>{ name }</h1>\n}\n")
+		if a := w.action(goFileName); a != actionRebuild {
+			t.Errorf("expected the first generated code to rebuild the app, got %v", a)
+		}
+		write("package main\n\ntempl home(name string) {\n\t<h2>Hello, { name }</h2>\n}\n")
+		if a := w.action(goFileName); a != actionReload {
+			t.Errorf("expected changes to the contents of templates to reload the browser, got %v", a)
+		}
+		write("package main\n\ntempl home(name string, age int) {\n\t<h2>Hello, { name }</h2>\n}\n")
+		if a := w.action(goFileName); a != actionRebuild {
+			t.Errorf("expected changes to template parameters to rebuild the app, got %v", a)
+		}
+	})
+	t.Run("without hot reloading, changes to generated code rebuild the app", func(t *testing.T) {
+		w := newWatcher(false)
+		write("package main\n\ntempl home(name string) {\n\t<h1>{ name }</h1>\n}\n")
+		w.action(goFileName)
+		write("package main\n\ntempl home(name string) {\n\t<h2>Hello, { name }</h2>\n}\n")
+		if a := w.action(goFileName); a != actionRebuild {
+			t.Errorf("expected changes to rebuild the app, got %v", a)
+		}
+	})
+}
@@ -0,0 +1,127 @@
+package upgradecmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	parser "github.com/a-h/templ/parser/v2"
+	"github.com/natefinch/atomic"
+)
+
+type Arguments struct {
+	// Path to upgrade the templ and Go files within.
+	Path string
+	// DryRun prints the changes that would be made, without writing files.
+	DryRun bool
+}
+
+func Run(w io.Writer, args Arguments) (err error) {
+	var fileNames []string
+	err = filepath.WalkDir(args.Path, func(fileName string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if shouldSkipDir(fileName) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		// Generated code is updated by templ generate.
+		if strings.HasSuffix(fileName, ".templ") || strings.HasSuffix(fileName, ".go") && !strings.HasSuffix(fileName, "_templ.go") {
+			fileNames = append(fileNames, fileName)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	var errs []error
+	var changedCount int
+	for _, fileName := range fileNames {
+		changed, err := upgradeFile(w, fileName, args.DryRun)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", fileName, err))
+			continue
+		}
+		if changed {
+			changedCount++
+		}
+	}
+	switch {
+	case args.DryRun:
+		fmt.Fprintf(w, "%d files would be upgraded\n", changedCount)
+	default:
+		fmt.Fprintf(w, "%d files upgraded, run templ generate to update the generated code\n", changedCount)
+	}
+	return errors.Join(errs...)
+}
+
+// shouldSkipDir returns true for directories that are ignored by the Go tool.
+func shouldSkipDir(dir string) bool {
+	if dir == "." {
+		return false
+	}
+	_, name := path.Split(filepath.ToSlash(dir))
+	return name == "vendor" || name == "node_modules" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")
+}
+
+func upgradeFile(w io.Writer, fileName string, dryRun bool) (changed bool, err error) {
+	src, err := os.ReadFile(fileName)
+	if err != nil {
+		return false, err
+	}
+	var output []byte
+	var c changes
+	if strings.HasSuffix(fileName, ".templ") {
+		output, c, err = upgradeTemplateSource(src)
+	} else {
+		output, c, err = upgradeGoSource(fileName, src)
+	}
+	if err != nil || len(c) == 0 {
+		return false, err
+	}
+	fmt.Fprintf(w, "%s: %s\n", fileName, c)
+	if dryRun {
+		return true, nil
+	}
+	return true, atomic.WriteFile(fileName, bytes.NewReader(output))
+}
+
+func upgradeTemplateSource(src []byte) (output []byte, c changes, err error) {
+	tf, err := parser.ParseString(string(src))
+	if err != nil {
+		if errors.Is(err, parser.ErrLegacyFileFormat) {
+			return nil, nil, errors.New("the legacy file format can't be upgraded automatically, upgrade to templ v0.2.x first, and run templ migrate")
+		}
+		return nil, nil, err
+	}
+	if c = upgradeTemplate(&tf); len(c) == 0 {
+		return src, c, nil
+	}
+	// The template is written with the formatter, as with templ fmt.
+	var buf bytes.Buffer
+	if err = tf.Write(&buf); err != nil {
+		return nil, nil, err
+	}
+	return buf.Bytes(), c, nil
+}
+
+func upgradeGoSource(fileName string, src []byte) (output []byte, c changes, err error) {
+	if output, c, err = upgradeGo(fileName, src); err != nil || len(c) == 0 {
+		return src, c, err
+	}
+	// Removing calls may change the alignment of comments and fields.
+	if formatted, err := format.Source(output); err == nil {
+		output = formatted
+	}
+	return output, c, nil
+}
@@ -0,0 +1,264 @@
+package upgradecmd
+
+import (
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+
+	parser "github.com/a-h/templ/parser/v2"
+)
+
+// deprecatedClassFuncs are the templ functions that wrap CSS class names, which
+// are now written as strings.
+var deprecatedClassFuncs = map[string]bool{
+	"Class":            true,
+	"SafeClass":        true,
+	"ConstantCSSClass": true,
+}
+
+// classFuncs are the templ functions whose arguments are CSS class names.
+var classFuncs = map[string]bool{
+	"Classes": true,
+	"KV":      true,
+}
+
+// changes counts the changes made to a file, by description.
+type changes map[string]int
+
+func (c changes) add(description string, count int) {
+	if count > 0 {
+		c[description] += count
+	}
+}
+
+// String returns the changes, sorted by description, e.g.
+// "replaced {! } call syntax with @ (2)".
+func (c changes) String() string {
+	var list []string
+	for description, count := range c {
+		list = append(list, fmt.Sprintf("%s (%d)", description, count))
+	}
+	sort.Strings(list)
+	return strings.Join(list, ", ")
+}
+
+const (
+	legacyCallSyntax    = "replaced {! } call syntax with @"
+	deprecatedClassCall = "replaced deprecated CSS class functions with strings"
+)
+
+// upgradeTemplate rewrites the deprecated syntax and API usage of a template
+// file in place.
+func upgradeTemplate(tf *parser.TemplateFile) (c changes) {
+	c = changes{}
+	for i, n := range tf.Nodes {
+		if t, ok := n.(parser.HTMLTemplate); ok {
+			t.Children = rewriteNodes(t.Children, c)
+			tf.Nodes[i] = t
+		}
+	}
+	return c
+}
+
+// rewriteNodes returns the nodes, with their deprecated syntax rewritten.
+func rewriteNodes(nodes []parser.Node, c changes) []parser.Node {
+	for i, n := range nodes {
+		switch n := n.(type) {
+		case parser.CallTemplateExpression:
+			c.add(legacyCallSyntax, 1)
+			nodes[i] = parser.TemplElementExpression{Expression: n.Expression}
+		case parser.Element:
+			n.Attributes = rewriteAttributes(n.Attributes, c)
+			n.Children = rewriteNodes(n.Children, c)
+			nodes[i] = n
+		case parser.TemplElementExpression:
+			n.Children = rewriteNodes(n.Children, c)
+			nodes[i] = n
+		case parser.IfExpression:
+			n.Then = rewriteNodes(n.Then, c)
+			for j := range n.ElseIfs {
+				n.ElseIfs[j].Then = rewriteNodes(n.ElseIfs[j].Then, c)
+			}
+			n.Else = rewriteNodes(n.Else, c)
+			nodes[i] = n
+		case parser.SwitchExpression:
+			for j := range n.Cases {
+				n.Cases[j].Children = rewriteNodes(n.Cases[j].Children, c)
+			}
+			nodes[i] = n
+		case parser.ForExpression:
+			n.Children = rewriteNodes(n.Children, c)
+			n.Else = rewriteNodes(n.Else, c)
+			nodes[i] = n
+		case parser.DeferExpression:
+			n.Children = rewriteNodes(n.Children, c)
+			nodes[i] = n
+		case parser.InlineTemplate:
+			n.Children = rewriteNodes(n.Children, c)
+			nodes[i] = n
+		case parser.BlockExpression:
+			n.Children = rewriteNodes(n.Children, c)
+			nodes[i] = n
+		}
+	}
+	return nodes
+}
+
+func rewriteAttributes(attrs []parser.Attribute, c changes) []parser.Attribute {
+	for i, attr := range attrs {
+		switch attr := attr.(type) {
+		case parser.ExpressionAttribute:
+			if !strings.EqualFold(attr.Name, "class") {
+				continue
+			}
+			// Class attributes are lists of class names, which are passed to
+			// templ.Classes.
+			value, count := rewriteClassNames(attr.Expression.Value)
+			c.add(deprecatedClassCall, count)
+			attr.Expression.Value = value
+			attrs[i] = attr
+		case parser.ConditionalAttribute:
+			attr.Then = rewriteAttributes(attr.Then, c)
+			attr.Else = rewriteAttributes(attr.Else, c)
+			attrs[i] = attr
+		}
+	}
+	return attrs
+}
+
+// rewriteClassNames rewrites a comma separated list of Go expressions that
+// are class names, e.g. `"a", templ.Class("b")`.
+func rewriteClassNames(src string) (string, int) {
+	const prefix = "[]any{"
+	// Multi-line lists end with a comma, and a newline is required before the
+	// closing brace.
+	suffix := "}"
+	if strings.HasSuffix(strings.TrimSpace(src), ",") {
+		suffix = "\n}"
+	}
+	expr, err := goparser.ParseExpr(prefix + src + suffix)
+	if err != nil {
+		return src, 0
+	}
+	lit, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return src, 0
+	}
+	var e edits
+	offset := -len(prefix) - 1
+	for _, arg := range lit.Elts {
+		e.unwrapDeprecatedClass(src, arg, "templ", offset)
+		e.unwrapClassFuncArgs(src, arg, "templ", offset)
+	}
+	return e.apply(src), len(e)
+}
+
+// upgradeGo rewrites the deprecated API usage of a Go file, returning the new
+// source.
+func upgradeGo(fileName string, src []byte) (output []byte, c changes, err error) {
+	c = changes{}
+	fset := token.NewFileSet()
+	f, err := goparser.ParseFile(fset, fileName, src, goparser.SkipObjectResolution)
+	if err != nil {
+		return src, c, err
+	}
+	name, ok := templImportName(f)
+	if !ok {
+		return src, c, nil
+	}
+	var e edits
+	e.unwrapClassFuncArgs(string(src), f, name, -fset.File(f.Pos()).Base())
+	c.add(deprecatedClassCall, len(e))
+	return []byte(e.apply(string(src))), c, nil
+}
+
+// templImportName returns the name that templ is imported as.
+func templImportName(f *ast.File) (name string, ok bool) {
+	for _, imp := range f.Imports {
+		if path, _ := strconv.Unquote(imp.Path.Value); path != "github.com/a-h/templ" {
+			continue
+		}
+		if imp.Name != nil {
+			return imp.Name.Name, imp.Name.Name != "_" && imp.Name.Name != "."
+		}
+		return "templ", true
+	}
+	return "", false
+}
+
+// isTemplCall returns true if the call is to one of the named templ functions.
+func isTemplCall(call *ast.CallExpr, templName string, funcs map[string]bool) bool {
+	fun := call.Fun
+	// Generic functions can be instantiated, e.g. templ.KV[string].
+	if index, ok := fun.(*ast.IndexExpr); ok {
+		fun = index.X
+	}
+	sel, ok := fun.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	return ok && pkg.Name == templName && funcs[sel.Sel.Name]
+}
+
+// edit replaces the source from start to end.
+type edit struct {
+	start, end  int
+	replacement string
+}
+
+type edits []edit
+
+// unwrapDeprecatedClass adds an edit that replaces the expression with its
+// argument, if it's a call to a deprecated class function, e.g.
+// templ.Class("a") becomes "a". offset converts positions in the parsed source
+// to indexes in src.
+func (e *edits) unwrapDeprecatedClass(src string, expr ast.Expr, templName string, offset int) {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 || !isTemplCall(call, templName, deprecatedClassFuncs) {
+		return
+	}
+	inner := call.Args[0]
+	// Nested calls are unwrapped too, e.g. templ.Class(templ.SafeClass("a")).
+	for {
+		next, ok := inner.(*ast.CallExpr)
+		if !ok || len(next.Args) != 1 || !isTemplCall(next, templName, deprecatedClassFuncs) {
+			break
+		}
+		inner = next.Args[0]
+	}
+	*e = append(*e, edit{
+		start:       int(call.Pos()) + offset,
+		end:         int(call.End()) + offset,
+		replacement: src[int(inner.Pos())+offset : int(inner.End())+offset],
+	})
+}
+
+// unwrapClassFuncArgs adds edits that unwrap the deprecated class functions
+// passed to templ.Classes and templ.KV within n.
+func (e *edits) unwrapClassFuncArgs(src string, n ast.Node, templName string, offset int) {
+	ast.Inspect(n, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || !isTemplCall(call, templName, classFuncs) {
+			return true
+		}
+		for _, arg := range call.Args {
+			e.unwrapDeprecatedClass(src, arg, templName, offset)
+		}
+		return true
+	})
+}
+
+// apply the edits to src. Edits don't overlap.
+func (e edits) apply(src string) string {
+	sorted := append(edits(nil), e...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].start > sorted[j].start })
+	for _, ed := range sorted {
+		src = src[:ed.start] + ed.replacement + src[ed.end:]
+	}
+	return src
+}
@@ -0,0 +1,132 @@
+package upgradecmd
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestUpgradeTemplate(t *testing.T) {
+	tests := []struct {
+		name            string
+		input           string
+		expected        string
+		expectedChanges string
+	}{
+		{
+			name: "legacy call syntax is replaced",
+			input: `package main
+
+templ page() {
+	<div>
+		if true {
+			{! header() }
+		}
+	</div>
+}
+`,
+			expected: `package main
+
+templ page() {
+	<div>
+		if true {
+			@header()
+		}
+	</div>
+}
+`,
+			expectedChanges: "replaced {! } call syntax with @ (1)",
+		},
+		{
+			name: "deprecated class functions are replaced with strings",
+			input: `package main
+
+templ page(name string, active bool) {
+	<div class={ "a", templ.Class("b"), templ.KV(templ.SafeClass(name), active) }></div>
+	<p
+		if active {
+			class={ templ.Class(templ.SafeClass("c")) }
+		}
+	></p>
+	<span title={ templ.Class("d").ClassName() }></span>
+}
+`,
+			expected: `package main
+
+templ page(name string, active bool) {
+	<div class={ "a", "b", templ.KV(name, active) }></div>
+	<p
+		if active {
+			class={ "c" }
+		}
+	></p>
+	<span title={ templ.Class("d").ClassName() }></span>
+}
+`,
+			expectedChanges: "replaced deprecated CSS class functions with strings (3)",
+		},
+		{
+			name: "templates without deprecated syntax are unchanged",
+			input: `package main
+
+templ page() {
+	<div   class="a">Hello</div>
+}
+`,
+			expected: `package main
+
+templ page() {
+	<div   class="a">Hello</div>
+}
+`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			output, c, err := upgradeTemplateSource([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, string(output)); diff != "" {
+				t.Error(diff)
+			}
+			if actual := c.String(); actual != tt.expectedChanges {
+				t.Errorf("expected changes %q, got %q", tt.expectedChanges, actual)
+			}
+		})
+	}
+}
+
+func TestUpgradeGo(t *testing.T) {
+	input := `package main
+
+import (
+	t "github.com/a-h/templ"
+)
+
+var classes = t.Classes(t.Class("a"), t.KV(t.ConstantCSSClass("b"), true), "c")
+
+var class = t.Class("d")
+`
+	expected := `package main
+
+import (
+	t "github.com/a-h/templ"
+)
+
+var classes = t.Classes("a", t.KV("b", true), "c")
+
+var class = t.Class("d")
+`
+	output, c, err := upgradeGoSource("main.go", []byte(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(expected, string(output)); diff != "" {
+		t.Error(diff)
+	}
+	if expected := "replaced deprecated CSS class functions with strings (2)"; c.String() != expected {
+		t.Errorf("expected changes %q, got %q", expected, c.String())
+	}
+}
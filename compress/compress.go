@@ -0,0 +1,195 @@
+// Package compress provides HTTP middleware that compresses responses with
+// brotli or gzip, and flushes the compressor when the response is flushed, so
+// that streamed pages are sent to the client as they're rendered.
+//
+//	http.Handle("/", compress.Middleware(handler))
+package compress
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+)
+
+// BrotliLevel is the brotli compression level. Higher levels are too slow for
+// responses that are compressed on each request.
+const BrotliLevel = 4
+
+var gzipWriters = sync.Pool{
+	New: func() any {
+		return gzip.NewWriter(io.Discard)
+	},
+}
+
+var brotliWriters = sync.Pool{
+	New: func() any {
+		return brotli.NewWriterLevel(io.Discard, BrotliLevel)
+	},
+}
+
+// Middleware compresses the responses of next with brotli or gzip, if the
+// request accepts either encoding.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cw := NewResponseWriter(w, r)
+		defer cw.Close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+type compressor interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// ResponseWriter is a http.ResponseWriter that compresses the response.
+//
+// Responses that already have a Content-Encoding, e.g. precompressed
+// components, and responses without a body, are not compressed.
+type ResponseWriter struct {
+	http.ResponseWriter
+	encoding string
+
+	started    bool
+	compressor compressor
+}
+
+// NewResponseWriter creates a ResponseWriter that compresses the response to r
+// with the encoding accepted by the request. Close must be called once the
+// response has been written.
+func NewResponseWriter(w http.ResponseWriter, r *http.Request) *ResponseWriter {
+	w.Header().Add("Vary", "Accept-Encoding")
+	accepted := acceptedEncodings(r.Header.Get("Accept-Encoding"))
+	var encoding string
+	if r.Method != http.MethodHead {
+		switch {
+		case accepted["br"]:
+			encoding = "br"
+		case accepted["gzip"]:
+			encoding = "gzip"
+		}
+	}
+	return &ResponseWriter{
+		ResponseWriter: w,
+		encoding:       encoding,
+	}
+}
+
+// start decides whether to compress the response, once the headers and status
+// are known.
+func (cw *ResponseWriter) start(status int) {
+	if cw.started {
+		return
+	}
+	cw.started = true
+	h := cw.Header()
+	if cw.encoding == "" || h.Get("Content-Encoding") != "" || !bodyAllowed(status) {
+		return
+	}
+	h.Set("Content-Encoding", cw.encoding)
+	h.Del("Content-Length")
+	switch cw.encoding {
+	case "br":
+		cw.compressor = brotliWriters.Get().(*brotli.Writer)
+	case "gzip":
+		cw.compressor = gzipWriters.Get().(*gzip.Writer)
+	}
+	cw.compressor.Reset(cw.ResponseWriter)
+}
+
+func bodyAllowed(status int) bool {
+	return !(status >= 100 && status < 200 || status == http.StatusNoContent || status == http.StatusNotModified)
+}
+
+// WriteHeader writes the headers, with the Content-Encoding, if the response
+// is compressed.
+func (cw *ResponseWriter) WriteHeader(status int) {
+	if status >= 100 && status < 200 {
+		// Informational responses are followed by another response.
+		cw.ResponseWriter.WriteHeader(status)
+		return
+	}
+	cw.start(status)
+	cw.ResponseWriter.WriteHeader(status)
+}
+
+// Write compresses p, and writes it to the response.
+func (cw *ResponseWriter) Write(p []byte) (n int, err error) {
+	if !cw.started {
+		if cw.Header().Get("Content-Type") == "" {
+			// Detect the content type before it's compressed.
+			cw.Header().Set("Content-Type", http.DetectContentType(p))
+		}
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.compressor == nil {
+		return cw.ResponseWriter.Write(p)
+	}
+	return cw.compressor.Write(p)
+}
+
+// Flush writes the data that's been compressed so far to the client. Without
+// flushing the compressor, data written before a flush could stay in the
+// compressor's buffer, and streamed content wouldn't be sent until the
+// response ends.
+func (cw *ResponseWriter) Flush() {
+	if !cw.started {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.compressor != nil {
+		_ = cw.compressor.Flush()
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Close writes the end of the compressed response. It doesn't close the
+// underlying http.ResponseWriter.
+func (cw *ResponseWriter) Close() (err error) {
+	if cw.compressor == nil {
+		return nil
+	}
+	err = cw.compressor.Close()
+	cw.compressor.Reset(io.Discard)
+	switch c := cw.compressor.(type) {
+	case *brotli.Writer:
+		brotliWriters.Put(c)
+	case *gzip.Writer:
+		gzipWriters.Put(c)
+	}
+	cw.compressor = nil
+	return err
+}
+
+// Unwrap returns the underlying http.ResponseWriter, for use by
+// http.ResponseController.
+func (cw *ResponseWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// acceptedEncodings parses an Accept-Encoding header, e.g. "gzip, br;q=0.8".
+// Encodings with a quality of zero are not accepted.
+func acceptedEncodings(header string) (accepted map[string]bool) {
+	accepted = map[string]bool{}
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if v, err := strconv.ParseFloat(q, 64); err == nil && v == 0 {
+				continue
+			}
+		}
+		accepted[name] = true
+	}
+	return accepted
+}
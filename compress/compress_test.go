@@ -0,0 +1,157 @@
+package compress
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/andybalholm/brotli"
+)
+
+func decompress(t *testing.T, encoding string, r io.Reader) io.Reader {
+	t.Helper()
+	switch encoding {
+	case "gzip":
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			t.Fatalf("failed to read gzip response: %v", err)
+		}
+		return gr
+	case "br":
+		return brotli.NewReader(r)
+	}
+	return r
+}
+
+func TestMiddleware(t *testing.T) {
+	page := templ.Handler(templ.Raw("<p>Hello</p>"))
+	tests := []struct {
+		name             string
+		acceptEncoding   string
+		handler          http.Handler
+		expectedEncoding string
+		expectedBody     string
+	}{
+		{
+			name:             "gzip",
+			acceptEncoding:   "gzip",
+			handler:          page,
+			expectedEncoding: "gzip",
+			expectedBody:     "<p>Hello</p>",
+		},
+		{
+			name:             "brotli is preferred",
+			acceptEncoding:   "gzip, deflate, br",
+			handler:          page,
+			expectedEncoding: "br",
+			expectedBody:     "<p>Hello</p>",
+		},
+		{
+			name:             "encodings with a quality of zero are not accepted",
+			acceptEncoding:   "br;q=0, gzip",
+			handler:          page,
+			expectedEncoding: "gzip",
+			expectedBody:     "<p>Hello</p>",
+		},
+		{
+			name:         "responses aren't compressed if the client doesn't accept compression",
+			handler:      page,
+			expectedBody: "<p>Hello</p>",
+		},
+		{
+			name:           "responses that are already compressed aren't compressed again",
+			acceptEncoding: "br",
+			handler: templ.Handler(templ.Precompressed(templ.PrecompressedContent{
+				Brotli: "precompressed",
+			}, templ.Raw("<p>Hello</p>"))),
+			expectedEncoding: "br",
+			expectedBody:     "precompressed",
+		},
+		{
+			name:           "responses without a body aren't compressed",
+			acceptEncoding: "gzip",
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			}),
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.acceptEncoding != "" {
+				r.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			Middleware(tt.handler).ServeHTTP(w, r)
+			if actual := w.Header().Get("Content-Encoding"); actual != tt.expectedEncoding {
+				t.Fatalf("expected encoding %q, got %q", tt.expectedEncoding, actual)
+			}
+			if actual := w.Header().Values("Vary"); len(actual) == 0 || actual[0] != "Accept-Encoding" {
+				t.Errorf("expected Vary: Accept-Encoding, got %q", actual)
+			}
+			body := w.Body.String()
+			if tt.expectedBody != "precompressed" {
+				b, err := io.ReadAll(decompress(t, tt.expectedEncoding, w.Body))
+				if err != nil {
+					t.Fatalf("failed to read body: %v", err)
+				}
+				body = string(b)
+			}
+			if body != tt.expectedBody {
+				t.Errorf("expected body %q, got %q", tt.expectedBody, body)
+			}
+		})
+	}
+}
+
+func TestFlush(t *testing.T) {
+	for _, encoding := range []string{"gzip", "br"} {
+		encoding := encoding
+		t.Run(encoding, func(t *testing.T) {
+			release := make(chan struct{})
+			s := httptest.NewServer(Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html")
+				io.WriteString(w, "<header>Header</header>\n")
+				w.(http.Flusher).Flush()
+				<-release
+				io.WriteString(w, "<main>Content</main>\n")
+			})))
+			defer s.Close()
+			defer close(release)
+
+			req, _ := http.NewRequest(http.MethodGet, s.URL, nil)
+			req.Header.Set("Accept-Encoding", encoding)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.Header.Get("Content-Encoding") != encoding {
+				t.Fatalf("expected encoding %q, got %q", encoding, resp.Header.Get("Content-Encoding"))
+			}
+			// The flushed content is received before the rest of the page is written.
+			br := bufio.NewReader(decompress(t, encoding, resp.Body))
+			line, err := br.ReadString('\n')
+			if err != nil {
+				t.Fatalf("failed to read flushed content: %v", err)
+			}
+			if line != "<header>Header</header>\n" {
+				t.Errorf("unexpected flushed content: %q", line)
+			}
+			release <- struct{}{}
+			rest, err := io.ReadAll(br)
+			if err != nil {
+				t.Fatalf("failed to read the rest of the response: %v", err)
+			}
+			if !strings.Contains(string(rest), "<main>Content</main>") {
+				t.Errorf("unexpected content: %q", rest)
+			}
+		})
+	}
+}
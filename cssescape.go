@@ -0,0 +1,40 @@
+package templ
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/a-h/templ/safehtml"
+)
+
+// EscapeCSSString escapes s for use within a CSS string in a style element,
+// e.g. content: "{{ label }}";. Characters that could end the string, the
+// style element or a comment are written as CSS escape sequences.
+func EscapeCSSString(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch r {
+		case '\\', '"', '\'', '<', '>', '&', '\n', '\r', '\f', 0:
+			// The trailing space ends the escape sequence, and isn't output.
+			fmt.Fprintf(&sb, `\%x `, r)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// EscapeCSSValue returns s if it's safe to use as a CSS value in a style
+// element, e.g. color: {{ color }};, or an innocuous value if it could
+// change the meaning of the CSS, e.g. red; } body { display: none.
+func EscapeCSSValue(s string) string {
+	return safehtml.SanitizeCSSValue("", s)
+}
+
+// EscapeCSSPropertyValue returns s if it's safe to use as the value of the
+// CSS property in a style element, e.g. --brand: {{ color }};, or an innocuous
+// value if it could change the meaning of the CSS. Values of custom
+// properties can contain functions such as rgb() and calc().
+func EscapeCSSPropertyValue(property, s string) string {
+	return safehtml.SanitizeCSSValue(safehtml.SanitizeCSSProperty(property), s)
+}
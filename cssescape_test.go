@@ -0,0 +1,159 @@
+package templ_test
+
+import (
+	"testing"
+
+	"github.com/a-h/templ"
+)
+
+func TestEscapeCSSString(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "text is unchanged",
+			input:    "Hello, World",
+			expected: "Hello, World",
+		},
+		{
+			name:     "quotes are escaped",
+			input:    `"a" 'b'`,
+			expected: `\22 a\22  \27 b\27 `,
+		},
+		{
+			name:     "backslashes are escaped",
+			input:    `a\b`,
+			expected: `a\5c b`,
+		},
+		{
+			name:     "newlines are escaped",
+			input:    "a\nb",
+			expected: `a\a b`,
+		},
+		{
+			name:     "end tags are escaped",
+			input:    "</style>",
+			expected: `\3c /style\3e `,
+		},
+		{
+			name:     "unicode is unchanged",
+			input:    "→",
+			expected: "→",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			actual := templ.EscapeCSSString(tt.input)
+			if actual != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestEscapeCSSValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "colors are unchanged",
+			input:    "#ff0000",
+			expected: "#ff0000",
+		},
+		{
+			name:     "lengths are unchanged",
+			input:    "10px 1.5em",
+			expected: "10px 1.5em",
+		},
+		{
+			name:     "values that end the declaration are replaced",
+			input:    "red; } body { display: none",
+			expected: "zTemplUnsafeCSSPropertyValue",
+		},
+		{
+			name:     "end tags are replaced",
+			input:    "</style>",
+			expected: "zTemplUnsafeCSSPropertyValue",
+		},
+		{
+			name:     "comments are replaced",
+			input:    "red /* a */",
+			expected: "zTemplUnsafeCSSPropertyValue",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			actual := templ.EscapeCSSValue(tt.input)
+			if actual != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestEscapeCSSPropertyValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		property string
+		input    string
+		expected string
+	}{
+		{
+			name:     "regular properties are sanitized",
+			property: "color",
+			input:    "#ff0000",
+			expected: "#ff0000",
+		},
+		{
+			name:     "functions are not allowed in regular properties",
+			property: "width",
+			input:    "calc(100% - 10px)",
+			expected: "zTemplUnsafeCSSPropertyValue",
+		},
+		{
+			name:     "custom properties can contain color functions",
+			property: "--brand",
+			input:    "rgb(0, 128, 255)",
+			expected: "rgb(0, 128, 255)",
+		},
+		{
+			name:     "custom properties can contain calculations",
+			property: "--gap",
+			input:    "calc(var(--base) * 2)",
+			expected: "calc(var(--base) * 2)",
+		},
+		{
+			name:     "custom properties can't load resources",
+			property: "--background",
+			input:    "url(https://example.com/a.png)",
+			expected: "zTemplUnsafeCSSPropertyValue",
+		},
+		{
+			name:     "custom property values that end the declaration are replaced",
+			property: "--brand",
+			input:    "red; } body { display: none",
+			expected: "zTemplUnsafeCSSPropertyValue",
+		},
+		{
+			name:     "custom property values with unbalanced parentheses are replaced",
+			property: "--brand",
+			input:    "rgb(0, 0, 0))",
+			expected: "zTemplUnsafeCSSPropertyValue",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			actual := templ.EscapeCSSPropertyValue(tt.property, tt.input)
+			if actual != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, actual)
+			}
+		})
+	}
+}
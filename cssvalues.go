@@ -0,0 +1,88 @@
+package templ
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// CSSValue is a CSS property value that is safe by construction, e.g.
+// templ.Px(10), so it isn't sanitized when it's used in a style attribute.
+type CSSValue interface {
+	CSSValue() SafeCSSProperty
+}
+
+// Px is a length in pixels, e.g. templ.Px(10) is 10px.
+type Px float64
+
+func (v Px) CSSValue() SafeCSSProperty { return SafeCSSProperty(formatCSSNumber(float64(v)) + "px") }
+
+// Em is a length relative to the font size of the element, e.g. templ.Em(1.5) is 1.5em.
+type Em float64
+
+func (v Em) CSSValue() SafeCSSProperty { return SafeCSSProperty(formatCSSNumber(float64(v)) + "em") }
+
+// Rem is a length relative to the font size of the root element, e.g. templ.Rem(2) is 2rem.
+type Rem float64
+
+func (v Rem) CSSValue() SafeCSSProperty { return SafeCSSProperty(formatCSSNumber(float64(v)) + "rem") }
+
+// Percent is a percentage, e.g. templ.Percent(50) is 50%.
+type Percent float64
+
+func (v Percent) CSSValue() SafeCSSProperty { return SafeCSSProperty(formatCSSNumber(float64(v)) + "%") }
+
+// formatCSSNumber formats a number without an exponent. NaN and infinite
+// values aren't valid CSS, and are formatted as 0.
+func formatCSSNumber(f float64) string {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "0"
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// Color is an sRGB color. Use templ.RGB, templ.RGBA or templ.ParseHexColor to
+// create a color.
+type Color struct {
+	r, g, b uint8
+	// a is the opacity, from 0 to 1.
+	a float64
+}
+
+// RGB returns an opaque color, e.g. templ.RGB(255, 0, 0) is #ff0000.
+func RGB(r, g, b uint8) Color {
+	return Color{r: r, g: g, b: b, a: 1}
+}
+
+// RGBA returns a color with an opacity from 0 to 1, e.g.
+// templ.RGBA(255, 0, 0, 0.5) is rgba(255,0,0,0.5).
+func RGBA(r, g, b uint8, a float64) Color {
+	if math.IsNaN(a) {
+		a = 0
+	}
+	return Color{r: r, g: g, b: b, a: min(max(a, 0), 1)}
+}
+
+// ParseHexColor parses a color in the #rgb or #rrggbb format.
+func ParseHexColor(s string) (c Color, err error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return c, fmt.Errorf("templ: invalid hex color %q", s)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return c, fmt.Errorf("templ: invalid hex color %q", s)
+	}
+	return RGB(uint8(v>>16), uint8(v>>8), uint8(v)), nil
+}
+
+func (c Color) CSSValue() SafeCSSProperty {
+	if c.a >= 1 {
+		return SafeCSSProperty(fmt.Sprintf("#%02x%02x%02x", c.r, c.g, c.b))
+	}
+	return SafeCSSProperty(fmt.Sprintf("rgba(%d,%d,%d,%s)", c.r, c.g, c.b, formatCSSNumber(c.a)))
+}
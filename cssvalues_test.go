@@ -0,0 +1,108 @@
+package templ_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/a-h/templ"
+)
+
+func TestCSSValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    templ.CSSValue
+		expected templ.SafeCSSProperty
+	}{
+		{
+			name:     "pixels",
+			input:    templ.Px(10),
+			expected: "10px",
+		},
+		{
+			name:     "fractional pixels",
+			input:    templ.Px(0.5),
+			expected: "0.5px",
+		},
+		{
+			name:     "large numbers don't have an exponent",
+			input:    templ.Px(1e21),
+			expected: "1000000000000000000000px",
+		},
+		{
+			name:     "NaN is zero",
+			input:    templ.Px(math.NaN()),
+			expected: "0px",
+		},
+		{
+			name:     "ems",
+			input:    templ.Em(1.5),
+			expected: "1.5em",
+		},
+		{
+			name:     "rems",
+			input:    templ.Rem(-2),
+			expected: "-2rem",
+		},
+		{
+			name:     "percentages",
+			input:    templ.Percent(50),
+			expected: "50%",
+		},
+		{
+			name:     "opaque colors are hex",
+			input:    templ.RGB(255, 0, 16),
+			expected: "#ff0010",
+		},
+		{
+			name:     "transparent colors are rgba",
+			input:    templ.RGBA(255, 0, 0, 0.25),
+			expected: "rgba(255,0,0,0.25)",
+		},
+		{
+			name:     "opacity is clamped",
+			input:    templ.RGBA(255, 0, 0, 2),
+			expected: "#ff0000",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := tt.input.CSSValue(); actual != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestParseHexColor(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    templ.SafeCSSProperty
+		expectedErr bool
+	}{
+		{input: "#ff0000", expected: "#ff0000"},
+		{input: "00FF00", expected: "#00ff00"},
+		{input: "#00f", expected: "#0000ff"},
+		{input: "#ff00", expectedErr: true},
+		{input: "red", expectedErr: true},
+		{input: "#gg0000", expectedErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.input, func(t *testing.T) {
+			c, err := templ.ParseHexColor(tt.input)
+			if tt.expectedErr {
+				if err == nil {
+					t.Errorf("expected an error, got %q", c.CSSValue())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual := c.CSSValue(); actual != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, actual)
+			}
+		})
+	}
+}
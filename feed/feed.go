@@ -0,0 +1,297 @@
+// Package feed provides components that render RSS 2.0 and Atom 1.0 feeds.
+//
+//	templ.Handler(feed.RSS(channel), templ.WithContentType(feed.RSSContentType))
+package feed
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/a-h/templ/internal/xmlwriter"
+)
+
+const (
+	// RSSContentType is the content type of RSS feeds.
+	RSSContentType = "application/rss+xml; charset=utf-8"
+	// AtomContentType is the content type of Atom feeds.
+	AtomContentType = "application/atom+xml; charset=utf-8"
+)
+
+// Channel is an RSS feed.
+type Channel struct {
+	Title string
+	// Link is the URL of the website that the feed is for.
+	Link        string
+	Description string
+	// Self is the URL of the feed itself, which feed validators recommend is included.
+	Self          string
+	Language      string
+	Copyright     string
+	LastBuildDate time.Time
+	Items         []Item
+}
+
+// Item is an item in an RSS feed.
+type Item struct {
+	Title string
+	Link  string
+	// Description is a plain text summary of the item.
+	Description string
+	// Content is the full HTML content of the item, which is rendered and
+	// written as content:encoded.
+	Content templ.Component
+	// GUID uniquely identifies the item. If it's not set, the Link is used.
+	GUID string
+	// GUIDIsPermaLink is true if the GUID is a URL that links to the item.
+	GUIDIsPermaLink bool
+	PubDate         time.Time
+	// Author is the email address of the author of the item.
+	Author     string
+	Categories []string
+	Enclosure  *Enclosure
+}
+
+// Enclosure is a media file attached to an item, e.g. a podcast episode.
+type Enclosure struct {
+	URL string
+	// Length of the file in bytes.
+	Length int64
+	// Type is the MIME type of the file, e.g. "audio/mpeg".
+	Type string
+}
+
+// RSS renders an RSS 2.0 feed of the channel and its items. Children of the
+// component are rendered after the items, so that items can also be rendered
+// with the RSSItem component.
+func RSS(ch Channel) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) (err error) {
+		children := templ.GetChildren(ctx)
+		ctx = templ.ClearChildren(ctx)
+		xw := xmlwriter.New(w)
+		xw.Raw(xmlwriter.Declaration)
+		xw.Start("rss",
+			xmlwriter.Attr{Name: "version", Value: "2.0"},
+			xmlwriter.Attr{Name: "xmlns:atom", Value: "http://www.w3.org/2005/Atom"},
+			xmlwriter.Attr{Name: "xmlns:content", Value: "http://purl.org/rss/1.0/modules/content/"},
+		)
+		xw.Start("channel")
+		xw.Text("title", ch.Title)
+		xw.Text("link", ch.Link)
+		xw.Text("description", ch.Description)
+		if ch.Self != "" {
+			xw.Empty("atom:link",
+				xmlwriter.Attr{Name: "href", Value: ch.Self},
+				xmlwriter.Attr{Name: "rel", Value: "self"},
+				xmlwriter.Attr{Name: "type", Value: "application/rss+xml"},
+			)
+		}
+		xw.Text("language", ch.Language)
+		xw.Text("copyright", ch.Copyright)
+		xw.Text("lastBuildDate", rssDate(ch.LastBuildDate))
+		if err = xw.Err(); err != nil {
+			return err
+		}
+		for _, item := range ch.Items {
+			if err = RSSItem(item).Render(ctx, w); err != nil {
+				return err
+			}
+		}
+		if children != nil {
+			if err = children.Render(ctx, w); err != nil {
+				return err
+			}
+		}
+		xw.End("channel")
+		xw.End("rss")
+		return xw.Err()
+	})
+}
+
+// RSSItem renders an item of an RSS feed.
+func RSSItem(item Item) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) (err error) {
+		content, err := renderContent(ctx, item.Content)
+		if err != nil {
+			return err
+		}
+		xw := xmlwriter.New(w)
+		xw.Start("item")
+		xw.Text("title", item.Title)
+		xw.Text("link", item.Link)
+		xw.Text("description", item.Description)
+		xw.CDATA("content:encoded", content)
+		guid, isPermaLink := item.GUID, item.GUIDIsPermaLink
+		if guid == "" {
+			guid, isPermaLink = item.Link, true
+		}
+		xw.Text("guid", guid, xmlwriter.Attr{Name: "isPermaLink", Value: strconv.FormatBool(isPermaLink)})
+		xw.Text("pubDate", rssDate(item.PubDate))
+		xw.Text("author", item.Author)
+		for _, c := range item.Categories {
+			xw.Text("category", c)
+		}
+		if e := item.Enclosure; e != nil {
+			xw.Empty("enclosure",
+				xmlwriter.Attr{Name: "url", Value: e.URL},
+				xmlwriter.Attr{Name: "length", Value: strconv.FormatInt(e.Length, 10)},
+				xmlwriter.Attr{Name: "type", Value: e.Type},
+			)
+		}
+		xw.End("item")
+		return xw.Err()
+	})
+}
+
+// AtomFeed is an Atom feed.
+type AtomFeed struct {
+	// ID is a permanent, unique identifier of the feed, e.g. its URL.
+	ID       string
+	Title    string
+	Subtitle string
+	// Link is the URL of the website that the feed is for.
+	Link string
+	// Self is the URL of the feed itself.
+	Self    string
+	Updated time.Time
+	Author  *Person
+	Entries []Entry
+}
+
+// Person is the author of an Atom feed or entry.
+type Person struct {
+	Name  string
+	Email string
+	URI   string
+}
+
+// Entry is an entry in an Atom feed.
+type Entry struct {
+	// ID is a permanent, unique identifier of the entry, e.g. its URL.
+	ID        string
+	Title     string
+	Link      string
+	Updated   time.Time
+	Published time.Time
+	// Summary is a plain text summary of the entry.
+	Summary string
+	// Content is the HTML content of the entry.
+	Content    templ.Component
+	Author     *Person
+	Categories []string
+	Enclosure  *Enclosure
+}
+
+// Atom renders an Atom 1.0 feed and its entries. Children of the component
+// are rendered after the entries, so that entries can also be rendered with
+// the AtomEntry component.
+func Atom(f AtomFeed) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) (err error) {
+		children := templ.GetChildren(ctx)
+		ctx = templ.ClearChildren(ctx)
+		xw := xmlwriter.New(w)
+		xw.Raw(xmlwriter.Declaration)
+		xw.Start("feed", xmlwriter.Attr{Name: "xmlns", Value: "http://www.w3.org/2005/Atom"})
+		xw.Text("id", f.ID)
+		xw.Text("title", f.Title)
+		xw.Text("subtitle", f.Subtitle)
+		xw.Text("updated", atomDate(f.Updated))
+		if f.Link != "" {
+			xw.Empty("link", xmlwriter.Attr{Name: "href", Value: f.Link})
+		}
+		if f.Self != "" {
+			xw.Empty("link", xmlwriter.Attr{Name: "href", Value: f.Self}, xmlwriter.Attr{Name: "rel", Value: "self"})
+		}
+		writePerson(xw, "author", f.Author)
+		if err = xw.Err(); err != nil {
+			return err
+		}
+		for _, e := range f.Entries {
+			if err = AtomEntry(e).Render(ctx, w); err != nil {
+				return err
+			}
+		}
+		if children != nil {
+			if err = children.Render(ctx, w); err != nil {
+				return err
+			}
+		}
+		xw.End("feed")
+		return xw.Err()
+	})
+}
+
+// AtomEntry renders an entry of an Atom feed.
+func AtomEntry(e Entry) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) (err error) {
+		content, err := renderContent(ctx, e.Content)
+		if err != nil {
+			return err
+		}
+		xw := xmlwriter.New(w)
+		xw.Start("entry")
+		xw.Text("id", e.ID)
+		xw.Text("title", e.Title)
+		if e.Link != "" {
+			xw.Empty("link", xmlwriter.Attr{Name: "href", Value: e.Link})
+		}
+		xw.Text("updated", atomDate(e.Updated))
+		xw.Text("published", atomDate(e.Published))
+		writePerson(xw, "author", e.Author)
+		xw.Text("summary", e.Summary)
+		xw.CDATA("content", content, xmlwriter.Attr{Name: "type", Value: "html"})
+		for _, c := range e.Categories {
+			xw.Empty("category", xmlwriter.Attr{Name: "term", Value: c})
+		}
+		if enc := e.Enclosure; enc != nil {
+			xw.Empty("link",
+				xmlwriter.Attr{Name: "rel", Value: "enclosure"},
+				xmlwriter.Attr{Name: "href", Value: enc.URL},
+				xmlwriter.Attr{Name: "length", Value: strconv.FormatInt(enc.Length, 10)},
+				xmlwriter.Attr{Name: "type", Value: enc.Type},
+			)
+		}
+		xw.End("entry")
+		return xw.Err()
+	})
+}
+
+func writePerson(xw *xmlwriter.Writer, name string, p *Person) {
+	if p == nil {
+		return
+	}
+	xw.Start(name)
+	xw.Text("name", p.Name)
+	xw.Text("email", p.Email)
+	xw.Text("uri", p.URI)
+	xw.End(name)
+}
+
+// renderContent renders HTML content, so that it can be written in a CDATA section.
+func renderContent(ctx context.Context, c templ.Component) (string, error) {
+	if c == nil {
+		return "", nil
+	}
+	html, err := templ.ToGoHTML(ctx, c)
+	return string(html), err
+}
+
+// rssDate formats a time in the RFC 822 format used by RSS, or returns an
+// empty string for the zero time.
+func rssDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC1123Z)
+}
+
+// atomDate formats a time in the RFC 3339 format used by Atom, or returns an
+// empty string for the zero time.
+func atomDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
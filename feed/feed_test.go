@@ -0,0 +1,119 @@
+package feed
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/google/go-cmp/cmp"
+)
+
+var published = time.Date(2024, time.March, 1, 9, 30, 0, 0, time.UTC)
+
+func html(s string) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		_, err := io.WriteString(w, s)
+		return err
+	})
+}
+
+func render(t *testing.T, c templ.Component) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := c.Render(context.Background(), &buf); err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+	// Check that the output is well-formed.
+	d := xml.NewDecoder(strings.NewReader(buf.String()))
+	for {
+		if _, err := d.Token(); err != nil {
+			if err == io.EOF {
+				break
+			}
+			t.Fatalf("invalid XML: %v\n%s", err, buf.String())
+		}
+	}
+	return buf.String()
+}
+
+func TestRSS(t *testing.T) {
+	actual := render(t, RSS(Channel{
+		Title:         "Tom & Jerry's blog",
+		Link:          "https://example.com",
+		Description:   "Posts <weekly>",
+		Self:          "https://example.com/rss.xml",
+		LastBuildDate: published,
+		Items: []Item{
+			{
+				Title:      "First post",
+				Link:       "https://example.com/posts/1",
+				Content:    html("<p>Contains ]]> in the content</p>"),
+				PubDate:    published,
+				Categories: []string{"news"},
+				Enclosure:  &Enclosure{URL: "https://example.com/1.mp3", Length: 1024, Type: "audio/mpeg"},
+			},
+		},
+	}))
+	expected := `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+		`<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom" xmlns:content="http://purl.org/rss/1.0/modules/content/"><channel>` +
+		`<title>Tom &amp; Jerry&#39;s blog</title><link>https://example.com</link><description>Posts &lt;weekly&gt;</description>` +
+		`<atom:link href="https://example.com/rss.xml" rel="self" type="application/rss+xml"/>` +
+		`<lastBuildDate>Fri, 01 Mar 2024 09:30:00 +0000</lastBuildDate>` +
+		`<item><title>First post</title><link>https://example.com/posts/1</link>` +
+		`<content:encoded><![CDATA[<p>Contains ]]]]><![CDATA[> in the content</p>]]></content:encoded>` +
+		`<guid isPermaLink="true">https://example.com/posts/1</guid><pubDate>Fri, 01 Mar 2024 09:30:00 +0000</pubDate>` +
+		`<category>news</category><enclosure url="https://example.com/1.mp3" length="1024" type="audio/mpeg"/></item>` +
+		`</channel></rss>`
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestRSSChildren(t *testing.T) {
+	ctx := templ.WithChildren(context.Background(), RSSItem(Item{Title: "Child", GUID: "1"}))
+	var buf bytes.Buffer
+	if err := RSS(Channel{Title: "Feed"}).Render(ctx, &buf); err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+	expected := `<item><title>Child</title><guid isPermaLink="false">1</guid></item></channel></rss>`
+	if !strings.HasSuffix(buf.String(), expected) {
+		t.Errorf("expected children to be rendered within the channel, got:\n%s", buf.String())
+	}
+}
+
+func TestAtom(t *testing.T) {
+	actual := render(t, Atom(AtomFeed{
+		ID:      "https://example.com/",
+		Title:   "Blog",
+		Link:    "https://example.com/",
+		Self:    "https://example.com/atom.xml",
+		Updated: published,
+		Author:  &Person{Name: "Alice"},
+		Entries: []Entry{
+			{
+				ID:         "https://example.com/posts/1",
+				Title:      "First post",
+				Link:       "https://example.com/posts/1",
+				Updated:    published,
+				Summary:    "A summary",
+				Content:    html("<p>Hello</p>"),
+				Categories: []string{"news"},
+			},
+		},
+	}))
+	expected := `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+		`<feed xmlns="http://www.w3.org/2005/Atom"><id>https://example.com/</id><title>Blog</title>` +
+		`<updated>2024-03-01T09:30:00Z</updated><link href="https://example.com/"/><link href="https://example.com/atom.xml" rel="self"/>` +
+		`<author><name>Alice</name></author>` +
+		`<entry><id>https://example.com/posts/1</id><title>First post</title><link href="https://example.com/posts/1"/>` +
+		`<updated>2024-03-01T09:30:00Z</updated><summary>A summary</summary>` +
+		`<content type="html"><![CDATA[<p>Hello</p>]]></content><category term="news"/></entry></feed>`
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Error(diff)
+	}
+}
@@ -1,25 +1,61 @@
 package generator
 
 import (
+	"bytes"
+	"compress/gzip"
 	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
 	"encoding/hex"
 	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"go/scanner"
+	"go/token"
 	"html"
 	"io"
+	"math"
+	"os"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 	"unicode"
+	"unicode/utf8"
 
 	_ "embed"
 
+	"github.com/a-h/templ/internal/css"
+	"github.com/a-h/templ/internal/javascript"
+	"github.com/a-h/templ/internal/typescript"
 	"github.com/a-h/templ/parser/v2"
+	"github.com/a-h/templ/parser/v2/goexpression"
+	"github.com/andybalholm/brotli"
 )
 
 type GenerateOpt func(g *generator) error
 
+// WithIncludeDir sets the directory that paths in @include expressions are relative to.
+// This is usually the directory containing the templ file.
+func WithIncludeDir(dir string) GenerateOpt {
+	return func(g *generator) error {
+		g.includeDir = dir
+		return nil
+	}
+}
+
+// WithIncludeHandler calls include with the full path of each file included
+// by the template file, including the files included by included files, so
+// that the template file can be regenerated when they change.
+func WithIncludeHandler(include func(fullPath string)) GenerateOpt {
+	return func(g *generator) error {
+		g.onInclude = include
+		return nil
+	}
+}
+
 // WithVersion enables the version to be included in the generated code.
 func WithVersion(v string) GenerateOpt {
 	return func(g *generator) error {
@@ -57,6 +93,142 @@ func WithExtractStrings() GenerateOpt {
 	}
 }
 
+// WithDebugMarkers wraps the output of each component in HTML comments that
+// contain the name of the component, and the file and line it's declared on,
+// so that regions of the DOM can be mapped back to templ files in devtools.
+func WithDebugMarkers() GenerateOpt {
+	return func(g *generator) error {
+		g.debugMarkers = true
+		return nil
+	}
+}
+
+// WithRenderTrace adds each component to the render trace of the context it's
+// rendered with, and calls its render hooks, if there are any, see
+// templ.WithRenderTrace and templ.WithRenderHooks.
+func WithRenderTrace() GenerateOpt {
+	return func(g *generator) error {
+		g.renderTrace = true
+		return nil
+	}
+}
+
+// WithReadable generates code that's easier to audit and step through in a
+// debugger. Each group of statements is preceded by a comment that cites the
+// templ source line it was generated from, and static content is written
+// with one write per source line, instead of being combined.
+func WithReadable() GenerateOpt {
+	return func(g *generator) error {
+		g.readable = true
+		return nil
+	}
+}
+
+// WithHotReload makes each component look up a hot reloaded version of itself
+// before rendering the compiled code, see templ.HotReload. Hot reloading is
+// only enabled in programs built with the templ_hotreload build tag.
+func WithHotReload() GenerateOpt {
+	return func(g *generator) error {
+		g.hotReload = true
+		return nil
+	}
+}
+
+// WithSourceMapRegistration registers the positions of the templ expressions
+// in the generated code at program start, so that panic stack traces can be
+// mapped back to templ files, see templ.MapStack.
+func WithSourceMapRegistration() GenerateOpt {
+	return func(g *generator) error {
+		g.registerSourceMap = true
+		return nil
+	}
+}
+
+// WithPrecompress compresses the output of components that have no dynamic
+// content with gzip and brotli, so that templ.Handler can write it without
+// rendering or compressing it, if the client accepts the encoding. See
+// templ.Precompressed.
+func WithPrecompress() GenerateOpt {
+	return func(g *generator) error {
+		g.precompress = true
+		return nil
+	}
+}
+
+// WithMinifyJS removes the whitespace and comments of the JavaScript within
+// script elements. Go expressions within template literals are kept.
+func WithMinifyJS() GenerateOpt {
+	return func(g *generator) error {
+		g.minifyJS = true
+		return nil
+	}
+}
+
+// WithMinifyCSS removes the whitespace and comments of the CSS of css
+// components, keyframes components and style elements, and shortens values,
+// e.g. #ffffff becomes #fff. Go expressions within style elements are kept.
+func WithMinifyCSS() GenerateOpt {
+	return func(g *generator) error {
+		g.minifyCSS = true
+		return nil
+	}
+}
+
+// WithStylePreprocessor preprocesses the contents of style elements that have
+// a lang attribute, e.g. <style lang="scss">, with the preprocess function,
+// which returns CSS. Go expressions within the style elements are kept.
+func WithStylePreprocessor(preprocess func(lang, src string) (css string, err error)) GenerateOpt {
+	return func(g *generator) error {
+		g.preprocessStyle = preprocess
+		return nil
+	}
+}
+
+// WithCriticalCSS adds a function for each of the entry components in the
+// file, e.g. PageCriticalCSS for Page, which returns the css components that
+// are used by the component, and by the components of the file that it calls,
+// so that their CSS can be rendered in the <head> element.
+func WithCriticalCSS(entries ...string) GenerateOpt {
+	return func(g *generator) error {
+		g.criticalCSSEntries = append(g.criticalCSSEntries, entries...)
+		return nil
+	}
+}
+
+// WithIntegrity adds subresource integrity attributes to <script src> and
+// <link rel="stylesheet" href> elements whose URL is a path, by hashing the
+// file at the path within dir, e.g. /static/app.js is read from
+// dir/static/app.js.
+func WithIntegrity(dir string) GenerateOpt {
+	return func(g *generator) error {
+		g.integrityDir = dir
+		return nil
+	}
+}
+
+// WithScriptExtraction moves the JavaScript of script elements that don't
+// contain Go expressions out of the generated code, so that a content security
+// policy that doesn't allow inline scripts can be used. extract is called
+// with the contents of each script, e.g. to write them to a file, and returns
+// the URL that's set as the src attribute of the element.
+func WithScriptExtraction(extract func(js string) (src string, err error)) GenerateOpt {
+	return func(g *generator) error {
+		g.extractScript = extract
+		return nil
+	}
+}
+
+// WithMarkdownRenderer renders the contents of markdown blocks with render
+// when the code is generated, so that the generated code doesn't import the
+// markdown package. By default, markdown blocks are rendered when the
+// templates are rendered, with the renderer of the markdown package.
+func WithMarkdownRenderer(render func(src string) (html string, err error)) GenerateOpt {
+	return func(g *generator) error {
+		g.renderMarkdown = render
+		return nil
+	}
+}
+
 // Generate generates Go code from the input template file to w, and returns a map of the location of Go expressions in the template
 // to the location of the generated Go code in the output.
 func Generate(template parser.TemplateFile, w io.Writer, opts ...GenerateOpt) (sm *parser.SourceMap, literals string, err error) {
@@ -82,6 +254,17 @@ type generator struct {
 	sourceMap   *parser.SourceMap
 	variableID  int
 	childrenVar string
+	// deferredVar is the name of the variable that collects the output of
+	// defer blocks in the current component, if it has any.
+	deferredVar string
+	// blocksVar is the name of the variable that holds the blocks passed to
+	// the current component, if it renders any.
+	blocksVar string
+	// cssScope is the scope of the classes of the scoped style elements of
+	// the current component.
+	cssScope parser.CSSScope
+	// xml is true if the current component generates XML instead of HTML.
+	xml bool
 
 	// version of templ.
 	version string
@@ -89,6 +272,44 @@ type generator struct {
 	generatedDate string
 	// fileName to include in error messages if string expressions return an error.
 	fileName string
+	// includeDir is the directory that @include paths are relative to.
+	includeDir string
+	// includeStack contains the files currently being included, to detect cycles.
+	includeStack []string
+	// onInclude is called with the full path of each included file, if set.
+	onInclude func(fullPath string)
+	// debugMarkers wraps the output of each component in HTML comments.
+	debugMarkers bool
+	// renderTrace adds each component to the render trace of the context.
+	renderTrace bool
+	// registerSourceMap adds an init function that registers the source map with the runtime.
+	registerSourceMap bool
+	// readable adds comments that cite the templ source of the generated code.
+	readable bool
+	// hotReload makes components look up a hot reloaded version of themselves.
+	hotReload bool
+	// precompress adds the compressed output of static components to the generated code.
+	precompress bool
+	// minifyJS minifies the JavaScript within script elements.
+	minifyJS bool
+	// minifyCSS minifies the CSS of css components and style elements.
+	minifyCSS bool
+	// preprocessStyle converts the contents of style elements with a lang
+	// attribute to CSS, if set.
+	preprocessStyle func(lang, src string) (css string, err error)
+	// criticalCSSEntries are the names of the components that CriticalCSS
+	// functions are written for.
+	criticalCSSEntries []string
+	// integrityDir is the directory of the files that integrity attributes
+	// are computed from, if set.
+	integrityDir string
+	// extractScript moves the contents of script elements to a URL, if set.
+	extractScript func(js string) (src string, err error)
+	// renderMarkdown renders markdown blocks when the code is generated, if
+	// set, instead of when the templates are rendered.
+	renderMarkdown func(src string) (html string, err error)
+	// lastSourceComment is the location of the last comment written in readable mode.
+	lastSourceComment string
 }
 
 func (g *generator) generate() (err error) {
@@ -110,9 +331,97 @@ func (g *generator) generate() (err error) {
 	if err = g.writeImports(); err != nil {
 		return
 	}
+	// The critical CSS is collected before the templates are written, because
+	// writing class expressions replaces them with variables.
+	criticalCSS := g.collectCriticalCSS()
 	if err = g.writeTemplateNodes(); err != nil {
 		return
 	}
+	if err = g.writeCriticalCSS(criticalCSS); err != nil {
+		return
+	}
+	if err = g.writeSourceMapRegistration(); err != nil {
+		return
+	}
+	return err
+}
+
+// criticalCSS is the css components used by an entry component.
+type criticalCSS struct {
+	entry   string
+	classes []string
+}
+
+// collectCriticalCSS returns the css components used by each of the critical
+// CSS entry components in the file.
+func (g *generator) collectCriticalCSS() (entries []criticalCSS) {
+	for _, entry := range g.criticalCSSEntries {
+		if classes, ok := g.tf.CriticalCSS(entry); ok {
+			entries = append(entries, criticalCSS{entry: entry, classes: classes})
+		}
+	}
+	return entries
+}
+
+// writeCriticalCSS writes a function for each of the critical CSS entry
+// components in the file, which returns the css components that it uses.
+func (g *generator) writeCriticalCSS(entries []criticalCSS) (err error) {
+	for _, e := range entries {
+		entry, classes := e.entry, e.classes
+		name := entry + "CriticalCSS"
+		if _, err = g.w.Write(fmt.Sprintf("\n// %s returns the CSS of the css components used by %s, to render in the <head> element.\n", name, entry)); err != nil {
+			return err
+		}
+		if _, err = g.w.Write(fmt.Sprintf("func %s() templ.CriticalCSS {\n", name)); err != nil {
+			return err
+		}
+		if _, err = g.w.WriteIndent(1, "return templ.CriticalCSS{\n"); err != nil {
+			return err
+		}
+		for _, class := range classes {
+			if _, err = g.w.WriteIndent(2, class+"(),\n"); err != nil {
+				return err
+			}
+		}
+		if _, err = g.w.WriteIndent(1, "}\n"); err != nil {
+			return err
+		}
+		if _, err = g.w.Write("}\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeSourceMapRegistration writes an init function that registers the templ
+// position of each generated line that contains a templ expression.
+func (g *generator) writeSourceMapRegistration() (err error) {
+	if !g.registerSourceMap {
+		return nil
+	}
+	goLines := make([]uint32, 0, len(g.sourceMap.TargetLinesToSource))
+	for line := range g.sourceMap.TargetLinesToSource {
+		goLines = append(goLines, line)
+	}
+	slices.Sort(goLines)
+	entries := make([]string, 0, len(goLines))
+	for _, line := range goLines {
+		// Use the start of the first expression on the line.
+		cols := g.sourceMap.TargetLinesToSource[line]
+		minCol := uint32(math.MaxUint32)
+		for col := range cols {
+			minCol = min(minCol, col)
+		}
+		src := cols[minCol]
+		entries = append(entries, fmt.Sprintf("%d:%d:%d", line+1, src.Line+1, src.Col+1))
+	}
+	if _, err = g.w.Write("\nfunc init() {\n"); err != nil {
+		return err
+	}
+	if _, err = g.w.WriteIndent(1, fmt.Sprintf("templ.RegisterSourceMap(%q)\n", strings.Join(entries, ","))); err != nil {
+		return err
+	}
+	_, err = g.w.Write("}\n")
 	return err
 }
 
@@ -163,10 +472,16 @@ func (g *generator) writePackage() error {
 
 func (g *generator) templateNodeInfo() (hasTemplates bool, hasCSS bool) {
 	for _, n := range g.tf.Nodes {
-		switch n.(type) {
+		switch n := n.(type) {
 		case parser.HTMLTemplate:
 			hasTemplates = true
-		case parser.CSSTemplate:
+		case parser.TemplateFileGoExpression:
+			// The RenderAttributes methods of attribute structs use the same
+			// imports as templates.
+			if structs, _ := attributeStructs(n); len(structs) > 0 {
+				hasTemplates = true
+			}
+		case parser.CSSTemplate, parser.KeyframesTemplate:
 			hasCSS = true
 		}
 		if hasTemplates && hasCSS {
@@ -196,6 +511,18 @@ func (g *generator) writeImports() error {
 		if _, err = g.w.Write("import \"bytes\"\n"); err != nil {
 			return err
 		}
+		// The interpreter renders hot reloaded templates.
+		if g.hotReload {
+			if _, err = g.w.Write("import _ \"github.com/a-h/templ/interpreter\"\n"); err != nil {
+				return err
+			}
+		}
+	}
+	// Markdown blocks are rendered by the markdown package.
+	if hasTemplates && g.renderMarkdown == nil && g.hasMarkdownBlocks() {
+		if _, err = g.w.Write("import templ_7745c5c3_markdown \"github.com/a-h/templ/markdown\"\n"); err != nil {
+			return err
+		}
 	}
 	if hasCSS {
 		// strings.Builder is used to create CSS.
@@ -213,6 +540,11 @@ func (g *generator) writeTemplateNodes() error {
 	for i := 0; i < len(g.tf.Nodes); i++ {
 		switch n := g.tf.Nodes[i].(type) {
 		case parser.TemplateFileGoExpression:
+			// The methods are written first, so that a comment at the end of
+			// the Go code remains the doc comment of the next template.
+			if err := g.writeAttributers(n); err != nil {
+				return err
+			}
 			if err := g.writeGoExpression(n); err != nil {
 				return err
 			}
@@ -224,6 +556,10 @@ func (g *generator) writeTemplateNodes() error {
 			if err := g.writeCSS(n); err != nil {
 				return err
 			}
+		case parser.KeyframesTemplate:
+			if err := g.writeKeyframes(n); err != nil {
+				return err
+			}
 		case parser.ScriptTemplate:
 			if err := g.writeScript(n); err != nil {
 				return err
@@ -258,30 +594,34 @@ func (g *generator) writeCSS(n parser.CSSTemplate) error {
 		if _, err = g.w.WriteIndent(indentLevel, "var templ_7745c5c3_CSSBuilder strings.Builder\n"); err != nil {
 			return err
 		}
-		for i := 0; i < len(n.Properties); i++ {
-			switch p := n.Properties[i].(type) {
-			case parser.ConstantCSSProperty:
-				// Constant CSS property values are not sanitized.
-				if _, err = g.w.WriteIndent(indentLevel, "templ_7745c5c3_CSSBuilder.WriteString("+createGoString(p.String(true))+")\n"); err != nil {
-					return err
-				}
-			case parser.ExpressionCSSProperty:
-				// templ_7745c5c3_CSSBuilder.WriteString(templ.SanitizeCSS('name', p.Expression()))
-				if _, err = g.w.WriteIndent(indentLevel, fmt.Sprintf("templ_7745c5c3_CSSBuilder.WriteString(string(templ.SanitizeCSS(`%s`, ", p.Name)); err != nil {
-					return err
-				}
-				if r, err = g.w.Write(p.Value.Expression.Value); err != nil {
-					return err
-				}
-				g.sourceMap.Add(p.Value.Expression, r)
-				if _, err = g.w.Write(")))\n"); err != nil {
-					return err
-				}
-			default:
-				return fmt.Errorf("unknown CSS property type: %v", reflect.TypeOf(p))
+		// The @keyframes rules of keyframes components used in animations
+		// are output before the class.
+		hasKeyframes := hasCSSAnimation(n.Properties)
+		if hasKeyframes {
+			// var templ_7745c5c3_CSSKeyframesBuilder strings.Builder
+			if _, err = g.w.WriteIndent(indentLevel, "var templ_7745c5c3_CSSKeyframesBuilder strings.Builder\n"); err != nil {
+				return err
 			}
 		}
-		if _, err = g.w.WriteIndent(indentLevel, fmt.Sprintf("templ_7745c5c3_CSSID := templ.CSSID(`%s`, templ_7745c5c3_CSSBuilder.String())\n", n.Name)); err != nil {
+		if err = g.writeCSSProperties(indentLevel, "templ_7745c5c3_CSSBuilder", n.Properties, hasKeyframes); err != nil {
+			return err
+		}
+		// Nested rules are written after the class, with a placeholder for
+		// the class selector, because the ID of the class is the hash of all
+		// of the CSS.
+		hasRules := slices.ContainsFunc(n.Properties, isCSSRule)
+		css := "templ_7745c5c3_CSSBuilder.String()"
+		if hasRules {
+			// var templ_7745c5c3_CSSRuleBuilder strings.Builder
+			if _, err = g.w.WriteIndent(indentLevel, "var templ_7745c5c3_CSSRuleBuilder strings.Builder\n"); err != nil {
+				return err
+			}
+			if err = g.writeCSSRules(indentLevel, n.Properties, nil, cssClassPlaceholder, hasKeyframes); err != nil {
+				return err
+			}
+			css += "+templ_7745c5c3_CSSRuleBuilder.String()"
+		}
+		if _, err = g.w.WriteIndent(indentLevel, fmt.Sprintf("templ_7745c5c3_CSSID := templ.CSSID(`%s`, %s)\n", n.Name, css)); err != nil {
 			return err
 		}
 		// return templ.CSS {
@@ -295,7 +635,14 @@ func (g *generator) writeCSS(n parser.CSSTemplate) error {
 				return err
 			}
 			// Class: templ.SafeCSS(".cssID{" + templ.CSSBuilder.String() + "}"),
-			if _, err = g.w.WriteIndent(indentLevel, "Class: templ.SafeCSS(`.` + templ_7745c5c3_CSSID + `{` + templ_7745c5c3_CSSBuilder.String() + `}`),\n"); err != nil {
+			class := "`.` + templ_7745c5c3_CSSID + `{` + templ_7745c5c3_CSSBuilder.String() + `}`"
+			if hasKeyframes {
+				class = "templ_7745c5c3_CSSKeyframesBuilder.String() + " + class
+			}
+			if hasRules {
+				class += " + strings.ReplaceAll(templ_7745c5c3_CSSRuleBuilder.String(), `" + cssClassPlaceholder + "`, `.`+templ_7745c5c3_CSSID)"
+			}
+			if _, err = g.w.WriteIndent(indentLevel, "Class: templ.SafeCSS("+class+"),\n"); err != nil {
 				return err
 			}
 			indentLevel--
@@ -312,122 +659,56 @@ func (g *generator) writeCSS(n parser.CSSTemplate) error {
 	return nil
 }
 
-func (g *generator) writeGoExpression(n parser.TemplateFileGoExpression) (err error) {
-	r, err := g.w.Write(n.Expression.Value)
-	if err != nil {
-		return err
-	}
-	g.sourceMap.Add(n.Expression, r)
-	v := n.Expression.Value
-	lineSlice := strings.Split(v, "\n")
-	lastLine := lineSlice[len(lineSlice)-1]
-	if strings.HasPrefix(lastLine, "//") {
-		if _, err = g.w.WriteIndent(0, "\n"); err != nil {
-			return err
-		}
-		return err
-	}
-	if _, err = g.w.WriteIndent(0, "\n\n"); err != nil {
-		return err
-	}
-	return err
-}
-
-func (g *generator) writeTemplBuffer(indentLevel int) (err error) {
-	// templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := w.(*bytes.Buffer)
-	if _, err = g.w.WriteIndent(indentLevel, "templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templ_7745c5c3_W.(*bytes.Buffer)\n"); err != nil {
-		return err
-	}
-	if _, err = g.w.WriteIndent(indentLevel, "if !templ_7745c5c3_IsBuffer {\n"); err != nil {
-		return err
-	}
-	{
-		indentLevel++
-		// templ_7745c5c3_Buffer = templ.GetBuffer()
-		if _, err = g.w.WriteIndent(indentLevel, "templ_7745c5c3_Buffer = templ.GetBuffer()\n"); err != nil {
-			return err
-		}
-		// defer templ.ReleaseBuffer(templ_7745c5c3_Buffer)
-		if _, err = g.w.WriteIndent(indentLevel, "defer templ.ReleaseBuffer(templ_7745c5c3_Buffer)\n"); err != nil {
-			return err
-		}
-		indentLevel--
-	}
-	if _, err = g.w.WriteIndent(indentLevel, "}\n"); err != nil {
-		return err
-	}
-	return
-}
-
-func (g *generator) writeTemplate(nodeIdx int, t parser.HTMLTemplate) error {
+func (g *generator) writeKeyframes(n parser.KeyframesTemplate) (err error) {
 	var r parser.Range
-	var err error
 	var indentLevel int
 
 	// func
 	if _, err = g.w.Write("func "); err != nil {
 		return err
 	}
-	// (r *Receiver) Name(params []string)
-	if r, err = g.w.Write(t.Expression.Value); err != nil {
-		return err
-	}
-	g.sourceMap.Add(t.Expression, r)
-	// templ.Component {
-	if _, err = g.w.Write(" templ.Component {\n"); err != nil {
+	if r, err = g.w.Write(n.Expression.Value); err != nil {
 		return err
 	}
-	indentLevel++
-	// return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
-	if _, err = g.w.WriteIndent(indentLevel, "return templ.ComponentFunc(func(ctx context.Context, templ_7745c5c3_W io.Writer) (templ_7745c5c3_Err error) {\n"); err != nil {
+	g.sourceMap.Add(n.Expression, r)
+	// templ.CSSKeyframes {
+	if _, err = g.w.Write(" templ.CSSKeyframes {\n"); err != nil {
 		return err
 	}
 	{
 		indentLevel++
-		if err := g.writeTemplBuffer(indentLevel); err != nil {
-			return err
-		}
-		// ctx = templ.InitializeContext(ctx)
-		if _, err = g.w.WriteIndent(indentLevel, "ctx = templ.InitializeContext(ctx)\n"); err != nil {
-			return err
-		}
-		g.childrenVar = g.createVariableName()
-		// templ_7745c5c3_Var1 := templ.GetChildren(ctx)
-		// if templ_7745c5c3_Var1 == nil {
-		//  	templ_7745c5c3_Var1 = templ.NopComponent
-		// }
-		if _, err = g.w.WriteIndent(indentLevel, fmt.Sprintf("%s := templ.GetChildren(ctx)\n", g.childrenVar)); err != nil {
-			return err
-		}
-		if _, err = g.w.WriteIndent(indentLevel, fmt.Sprintf("if %s == nil {\n", g.childrenVar)); err != nil {
+		// var templ_7745c5c3_CSSBuilder strings.Builder
+		if _, err = g.w.WriteIndent(indentLevel, "var templ_7745c5c3_CSSBuilder strings.Builder\n"); err != nil {
 			return err
 		}
-		{
-			indentLevel++
-			if _, err = g.w.WriteIndent(indentLevel, fmt.Sprintf("%s = templ.NopComponent\n", g.childrenVar)); err != nil {
+		for _, kf := range n.Keyframes {
+			// templ_7745c5c3_CSSBuilder.WriteString(`from{`)
+			if _, err = g.w.WriteIndent(indentLevel, "templ_7745c5c3_CSSBuilder.WriteString("+createGoString(kf.Selector+"{")+")\n"); err != nil {
+				return err
+			}
+			if err = g.writeCSSProperties(indentLevel, "templ_7745c5c3_CSSBuilder", kf.Properties, false); err != nil {
+				return err
+			}
+			// templ_7745c5c3_CSSBuilder.WriteString(`}`)
+			if _, err = g.w.WriteIndent(indentLevel, "templ_7745c5c3_CSSBuilder.WriteString(`}`)\n"); err != nil {
 				return err
 			}
-			indentLevel--
-		}
-		if _, err = g.w.WriteIndent(indentLevel, "}\n"); err != nil {
-			return err
-		}
-		// ctx = templ.ClearChildren(children)
-		if _, err = g.w.WriteIndent(indentLevel, "ctx = templ.ClearChildren(ctx)\n"); err != nil {
-			return err
 		}
-		// Nodes.
-		if err = g.writeNodes(indentLevel, stripWhitespace(t.Children), nil); err != nil {
+		if _, err = g.w.WriteIndent(indentLevel, fmt.Sprintf("templ_7745c5c3_CSSID := templ.CSSID(`%s`, templ_7745c5c3_CSSBuilder.String())\n", n.Name)); err != nil {
 			return err
 		}
-		// Return the buffer.
-		if _, err = g.w.WriteIndent(indentLevel, "if !templ_7745c5c3_IsBuffer {\n"); err != nil {
+		// return templ.CSSKeyframes{
+		if _, err = g.w.WriteIndent(indentLevel, "return templ.CSSKeyframes{\n"); err != nil {
 			return err
 		}
 		{
 			indentLevel++
-			// _, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteTo(templ_7745c5c3_W)
-			if _, err = g.w.WriteIndent(indentLevel, "_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteTo(templ_7745c5c3_W)\n"); err != nil {
+			// ID: templ_7745c5c3_CSSID,
+			if _, err = g.w.WriteIndent(indentLevel, "ID: templ_7745c5c3_CSSID,\n"); err != nil {
+				return err
+			}
+			// Keyframes: templ.SafeCSS("@keyframes " + templ_7745c5c3_CSSID + "{" + templ_7745c5c3_CSSBuilder.String() + "}"),
+			if _, err = g.w.WriteIndent(indentLevel, "Keyframes: templ.SafeCSS(`@keyframes ` + templ_7745c5c3_CSSID + `{` + templ_7745c5c3_CSSBuilder.String() + `}`),\n"); err != nil {
 				return err
 			}
 			indentLevel--
@@ -435,18 +716,465 @@ func (g *generator) writeTemplate(nodeIdx int, t parser.HTMLTemplate) error {
 		if _, err = g.w.WriteIndent(indentLevel, "}\n"); err != nil {
 			return err
 		}
-		// return templ_7745c5c3_Err
-		if _, err = g.w.WriteIndent(indentLevel, "return templ_7745c5c3_Err\n"); err != nil {
-			return err
-		}
 		indentLevel--
 	}
-	// })
-	if _, err = g.w.WriteIndent(indentLevel, "})\n"); err != nil {
+	// }
+	if _, err = g.w.WriteIndent(indentLevel, "}\n\n"); err != nil {
 		return err
 	}
-	indentLevel--
-	// }
+	return nil
+}
+
+// cssClassPlaceholder is written in place of the class selector of the nested
+// rules of css components, and replaced with the class once its ID is known.
+const cssClassPlaceholder = "templ_7745c5c3_CSSClass"
+
+func isCSSRule(p parser.CSSProperty) bool {
+	_, ok := p.(parser.CSSRule)
+	return ok
+}
+
+// isCSSAnimation returns true if the value of the property can be keyframes
+// created by a keyframes component.
+func isCSSAnimation(p parser.CSSProperty) bool {
+	ep, ok := p.(parser.ExpressionCSSProperty)
+	return ok && (strings.EqualFold(ep.Name, "animation") || strings.EqualFold(ep.Name, "animation-name"))
+}
+
+// hasCSSAnimation returns true if the properties, or their nested rules,
+// contain an animation whose value is an expression.
+func hasCSSAnimation(properties []parser.CSSProperty) bool {
+	return slices.ContainsFunc(properties, func(p parser.CSSProperty) bool {
+		if rule, ok := p.(parser.CSSRule); ok {
+			return hasCSSAnimation(rule.Properties)
+		}
+		return isCSSAnimation(p)
+	})
+}
+
+// writeCSSProperties writes the declarations of the properties to the
+// builder, skipping nested rules. If hasKeyframes is true, the @keyframes
+// rules of animations are written to the keyframes builder.
+func (g *generator) writeCSSProperties(indentLevel int, builder string, properties []parser.CSSProperty, hasKeyframes bool) (err error) {
+	var r parser.Range
+	for _, p := range properties {
+		switch p := p.(type) {
+		case parser.ConstantCSSProperty:
+			// Constant CSS property values are not sanitized.
+			declaration := p.String(true)
+			if g.minifyCSS {
+				if declaration, err = minifyCSSDeclaration(declaration); err != nil {
+					return fmt.Errorf("failed to minify CSS property %q: %w", p.Name, err)
+				}
+			}
+			if _, err = g.w.WriteIndent(indentLevel, builder+".WriteString("+createGoString(declaration)+")\n"); err != nil {
+				return err
+			}
+		case parser.ExpressionCSSProperty:
+			// templ_7745c5c3_CSSBuilder.WriteString(templ.SanitizeCSS('name', p.Expression()))
+			sanitize := "templ.SanitizeCSS("
+			if hasKeyframes && isCSSAnimation(p) {
+				// templ_7745c5c3_CSSBuilder.WriteString(templ.SanitizeCSSAnimation(&templ_7745c5c3_CSSKeyframesBuilder, 'name', p.Expression()))
+				sanitize = "templ.SanitizeCSSAnimation(&templ_7745c5c3_CSSKeyframesBuilder, "
+			}
+			if _, err = g.w.WriteIndent(indentLevel, fmt.Sprintf("%s.WriteString(string(%s`%s`, ", builder, sanitize, p.Name)); err != nil {
+				return err
+			}
+			if r, err = g.w.Write(p.Value.Expression.Value); err != nil {
+				return err
+			}
+			g.sourceMap.Add(p.Value.Expression, r)
+			if _, err = g.w.Write(")))\n"); err != nil {
+				return err
+			}
+		case parser.CSSRule:
+			// Nested rules are written by writeCSSRules.
+		default:
+			return fmt.Errorf("unknown CSS property type: %v", reflect.TypeOf(p))
+		}
+	}
+	return nil
+}
+
+// minifyCSSDeclaration minifies a constant declaration of a css component,
+// e.g. "border:1px  solid #FFFFFF;" becomes "border:1px solid #fff;".
+func minifyCSSDeclaration(declaration string) (string, error) {
+	minified, err := css.Minify("a{" + declaration + "}")
+	if err != nil {
+		return declaration, err
+	}
+	minified = strings.TrimSpace(minified)
+	if !strings.HasPrefix(minified, "a{") || !strings.HasSuffix(minified, "}") {
+		// The declaration was removed or split, so keep it as it is.
+		return declaration, nil
+	}
+	return minified[len("a{"):len(minified)-len("}")] + ";", nil
+}
+
+// minifyCSSRuleStart minifies the selector and at-rules at the start of a
+// nested rule of a css component, e.g. "@media (min-width: 768px){.a > .b{"
+// becomes "@media(min-width:768px){.a>.b{". The end closes the rule.
+func minifyCSSRuleStart(start, end string) (string, error) {
+	const marker = "templ_7745c5c3_minify:0"
+	minified, err := css.Minify(start + marker + end)
+	if err != nil {
+		return start, err
+	}
+	index := strings.Index(minified, marker)
+	if index < 0 {
+		return start, nil
+	}
+	return minified[:index], nil
+}
+
+// writeCSSRules writes the nested rules of the properties to the rule
+// builder as flat CSS, e.g. &:hover within @media (min-width: 768px) becomes
+// @media (min-width: 768px){.class:hover{...}}, so that browsers that don't
+// support CSS nesting can use the rules.
+func (g *generator) writeCSSRules(indentLevel int, properties []parser.CSSProperty, atRules []string, selector string, hasKeyframes bool) (err error) {
+	for _, p := range properties {
+		rule, ok := p.(parser.CSSRule)
+		if !ok {
+			continue
+		}
+		ruleAtRules, ruleSelector := atRules, selector
+		if rule.IsAtRule() {
+			ruleAtRules = append(slices.Clone(atRules), rule.Selector)
+		} else {
+			ruleSelector = strings.ReplaceAll(rule.Selector, "&", selector)
+		}
+		if slices.ContainsFunc(rule.Properties, func(p parser.CSSProperty) bool { return !isCSSRule(p) }) {
+			// @media (min-width: 768px){.class:hover{
+			open := strings.Join(ruleAtRules, "{") + "{" + ruleSelector + "{"
+			if len(ruleAtRules) == 0 {
+				open = ruleSelector + "{"
+			}
+			if g.minifyCSS {
+				if open, err = minifyCSSRuleStart(open, strings.Repeat("}", len(ruleAtRules)+1)); err != nil {
+					return fmt.Errorf("failed to minify CSS rule %q: %w", rule.Selector, err)
+				}
+			}
+			if _, err = g.w.WriteIndent(indentLevel, "templ_7745c5c3_CSSRuleBuilder.WriteString("+createGoString(open)+")\n"); err != nil {
+				return err
+			}
+			if err = g.writeCSSProperties(indentLevel, "templ_7745c5c3_CSSRuleBuilder", rule.Properties, hasKeyframes); err != nil {
+				return err
+			}
+			// }}
+			if _, err = g.w.WriteIndent(indentLevel, "templ_7745c5c3_CSSRuleBuilder.WriteString("+createGoString(strings.Repeat("}", len(ruleAtRules)+1))+")\n"); err != nil {
+				return err
+			}
+		}
+		if err = g.writeCSSRules(indentLevel, rule.Properties, ruleAtRules, ruleSelector, hasKeyframes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *generator) writeGoExpression(n parser.TemplateFileGoExpression) (err error) {
+	r, err := g.w.Write(n.Expression.Value)
+	if err != nil {
+		return err
+	}
+	g.sourceMap.Add(n.Expression, r)
+	v := n.Expression.Value
+	lineSlice := strings.Split(v, "\n")
+	lastLine := lineSlice[len(lineSlice)-1]
+	if strings.HasPrefix(lastLine, "//") {
+		if _, err = g.w.WriteIndent(0, "\n"); err != nil {
+			return err
+		}
+		return err
+	}
+	if _, err = g.w.WriteIndent(0, "\n\n"); err != nil {
+		return err
+	}
+	return err
+}
+
+// attributeStruct is a struct type with fields that have attr tags, e.g.
+//
+//	type ButtonProps struct {
+//		ID string `attr:"id"`
+//	}
+type attributeStruct struct {
+	name   string
+	fields []attributeStructField
+}
+
+type attributeStructField struct {
+	name      string
+	attribute string
+}
+
+// attributeStructs returns the struct types declared in the Go code that have
+// fields with attr tags. Generic types aren't included, because their
+// RenderAttributes methods would need type parameters, so they're rendered
+// with reflection instead. An error is returned if a tag names an event
+// handler, or isn't a valid attribute name, since the attribute can't be
+// rendered.
+func attributeStructs(n parser.TemplateFileGoExpression) (structs []attributeStruct, err error) {
+	if !strings.Contains(n.Expression.Value, "attr:") {
+		return nil, nil
+	}
+	f, err := goparser.ParseFile(token.NewFileSet(), "", "package p\n"+n.Expression.Value, goparser.SkipObjectResolution)
+	if err != nil {
+		return nil, nil
+	}
+	for _, decl := range f.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			ts, ok := spec.(*ast.TypeSpec)
+			if !ok || ts.TypeParams != nil || ts.Assign.IsValid() {
+				continue
+			}
+			st, ok := ts.Type.(*ast.StructType)
+			if !ok {
+				continue
+			}
+			s := attributeStruct{name: ts.Name.Name}
+			for _, field := range st.Fields.List {
+				if field.Tag == nil {
+					continue
+				}
+				tag, err := strconv.Unquote(field.Tag.Value)
+				if err != nil {
+					continue
+				}
+				attribute, ok := reflect.StructTag(tag).Lookup("attr")
+				if !ok || attribute == "-" || attribute == "" {
+					continue
+				}
+				for _, name := range field.Names {
+					if !name.IsExported() {
+						continue
+					}
+					if !isValidAttributeStructTag(attribute) {
+						return nil, fmt.Errorf("field %s of %s has the attr tag %q, which can't be rendered because it's an event handler or an invalid attribute name", name.Name, ts.Name.Name, attribute)
+					}
+					s.fields = append(s.fields, attributeStructField{name: name.Name, attribute: attribute})
+				}
+			}
+			if len(s.fields) > 0 {
+				structs = append(structs, s)
+			}
+		}
+	}
+	return structs, nil
+}
+
+// isValidAttributeStructTag returns true if the attr tag of a struct field is
+// an attribute that templ.RenderAttribute renders, i.e. a valid attribute
+// name that isn't an event handler.
+func isValidAttributeStructTag(name string) bool {
+	lower := strings.ToLower(name)
+	if strings.HasPrefix(lower, "on") || strings.HasPrefix(lower, "hx-on") {
+		return false
+	}
+	return !strings.ContainsFunc(name, func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsControl(r) || strings.ContainsRune("\"'<>/=`", r) || r == utf8.RuneError
+	})
+}
+
+// writeAttributers writes a RenderAttributes method for each struct type in
+// the Go code that has fields with attr tags, which renders the fields one by
+// one, so that the struct implements templ.Attributer, and can be spread into
+// the attributes of an element without creating a templ.Attributes map.
+func (g *generator) writeAttributers(n parser.TemplateFileGoExpression) (err error) {
+	structs, err := attributeStructs(n)
+	if err != nil {
+		return err
+	}
+	for _, s := range structs {
+		if _, err = g.w.Write(fmt.Sprintf("// RenderAttributes renders the fields of %s that have attr tags as attributes.\n", s.name)); err != nil {
+			return err
+		}
+		if _, err = g.w.Write(fmt.Sprintf("func (templ_7745c5c3_Attrs %s) RenderAttributes(ctx context.Context, templ_7745c5c3_W io.Writer) (templ_7745c5c3_Err error) {\n", s.name)); err != nil {
+			return err
+		}
+		for _, f := range s.fields {
+			// templ_7745c5c3_Err = templ.RenderAttribute(ctx, templ_7745c5c3_W, "id", templ_7745c5c3_Attrs.ID)
+			if _, err = g.w.WriteIndent(1, fmt.Sprintf("templ_7745c5c3_Err = templ.RenderAttribute(ctx, templ_7745c5c3_W, %s, templ_7745c5c3_Attrs.%s)\n", strconv.Quote(f.attribute), f.name)); err != nil {
+				return err
+			}
+			if err = g.writeErrorHandler(1); err != nil {
+				return err
+			}
+		}
+		if _, err = g.w.WriteIndent(1, "return nil\n"); err != nil {
+			return err
+		}
+		if _, err = g.w.Write("}\n\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *generator) writeTemplBuffer(indentLevel int) (err error) {
+	// templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := w.(*bytes.Buffer)
+	if _, err = g.w.WriteIndent(indentLevel, "templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templ_7745c5c3_W.(*bytes.Buffer)\n"); err != nil {
+		return err
+	}
+	if _, err = g.w.WriteIndent(indentLevel, "if !templ_7745c5c3_IsBuffer {\n"); err != nil {
+		return err
+	}
+	{
+		indentLevel++
+		// templ_7745c5c3_Buffer = templ.GetBuffer()
+		if _, err = g.w.WriteIndent(indentLevel, "templ_7745c5c3_Buffer = templ.GetBuffer()\n"); err != nil {
+			return err
+		}
+		// defer templ.ReleaseBuffer(templ_7745c5c3_Buffer)
+		if _, err = g.w.WriteIndent(indentLevel, "defer templ.ReleaseBuffer(templ_7745c5c3_Buffer)\n"); err != nil {
+			return err
+		}
+		indentLevel--
+	}
+	if _, err = g.w.WriteIndent(indentLevel, "}\n"); err != nil {
+		return err
+	}
+	return
+}
+
+func (g *generator) writeTemplate(nodeIdx int, t parser.HTMLTemplate) error {
+	var err error
+	var indentLevel int
+
+	// func
+	if _, err = g.w.Write("func "); err != nil {
+		return err
+	}
+	// (r *Receiver) Name(params []string)
+	if err = g.writeTemplateSignature(t); err != nil {
+		return err
+	}
+	// templ.Component {
+	if _, err = g.w.Write(" templ.Component {\n"); err != nil {
+		return err
+	}
+	g.cssScope = t.CSSScope()
+	defer func() { g.cssScope = parser.CSSScope{} }()
+	g.xml = g.tf.IsXML(nodeIdx)
+	defer func() { g.xml = false }()
+	indentLevel++
+	if err = g.writeDefaults(indentLevel, t); err != nil {
+		return err
+	}
+	if err = g.writeHotReload(indentLevel, t); err != nil {
+		return err
+	}
+	precompressedVar, precompress := g.precompressedVar(t)
+	if precompress {
+		// return templ.Precompressed(templ_7745c5c3_Precompressed_name, templ.ComponentFunc(...))
+		if _, err = g.w.WriteIndent(indentLevel, fmt.Sprintf("return templ.Precompressed(%s, templ.ComponentFunc(func(ctx context.Context, templ_7745c5c3_W io.Writer) (templ_7745c5c3_Err error) {\n", precompressedVar)); err != nil {
+			return err
+		}
+		g.w.capture = &strings.Builder{}
+	} else {
+		// return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		if _, err = g.w.WriteIndent(indentLevel, "return templ.ComponentFunc(func(ctx context.Context, templ_7745c5c3_W io.Writer) (templ_7745c5c3_Err error) {\n"); err != nil {
+			return err
+		}
+	}
+	{
+		indentLevel++
+		if err := g.writeTemplBuffer(indentLevel); err != nil {
+			return err
+		}
+		// ctx = templ.InitializeContext(ctx)
+		if _, err = g.w.WriteIndent(indentLevel, "ctx = templ.InitializeContext(ctx)\n"); err != nil {
+			return err
+		}
+		g.childrenVar = g.createVariableName()
+		// templ_7745c5c3_Var1 := templ.GetChildren(ctx)
+		// if templ_7745c5c3_Var1 == nil {
+		//  	templ_7745c5c3_Var1 = templ.NopComponent
+		// }
+		if _, err = g.w.WriteIndent(indentLevel, fmt.Sprintf("%s := templ.GetChildren(ctx)\n", g.childrenVar)); err != nil {
+			return err
+		}
+		if _, err = g.w.WriteIndent(indentLevel, fmt.Sprintf("if %s == nil {\n", g.childrenVar)); err != nil {
+			return err
+		}
+		{
+			indentLevel++
+			if _, err = g.w.WriteIndent(indentLevel, fmt.Sprintf("%s = templ.NopComponent\n", g.childrenVar)); err != nil {
+				return err
+			}
+			indentLevel--
+		}
+		if _, err = g.w.WriteIndent(indentLevel, "}\n"); err != nil {
+			return err
+		}
+		defer func() { g.blocksVar = "" }()
+		if err = g.writeBlocksVar(indentLevel, t.Children); err != nil {
+			return err
+		}
+		// ctx = templ.ClearChildren(children)
+		if _, err = g.w.WriteIndent(indentLevel, "ctx = templ.ClearChildren(ctx)\n"); err != nil {
+			return err
+		}
+		if g.renderTrace {
+			// ctx, templ_7745c5c3_TraceEnd := templ.TraceStart(ctx, "name", "file.templ:1", templ_7745c5c3_Buffer)
+			if _, err = g.w.WriteIndent(indentLevel, fmt.Sprintf("ctx, templ_7745c5c3_TraceEnd := templ.TraceStart(ctx, %q, %q, templ_7745c5c3_Buffer)\n", templateName(t.Expression.Value), g.templateLocation(t))); err != nil {
+				return err
+			}
+		}
+		if g.debugMarkers {
+			if _, err = g.w.WriteStringLiteral(indentLevel, g.debugMarker(t, true)); err != nil {
+				return err
+			}
+		}
+		// Nodes.
+		if err = g.writeNodesWithDeferred(indentLevel, stripWhitespace(t.Children)); err != nil {
+			return err
+		}
+		if g.debugMarkers {
+			if _, err = g.w.WriteStringLiteral(indentLevel, g.debugMarker(t, false)); err != nil {
+				return err
+			}
+		}
+		if g.renderTrace {
+			if _, err = g.w.WriteIndent(indentLevel, "templ_7745c5c3_TraceEnd()\n"); err != nil {
+				return err
+			}
+		}
+		// Return the buffer.
+		if _, err = g.w.WriteIndent(indentLevel, "if !templ_7745c5c3_IsBuffer {\n"); err != nil {
+			return err
+		}
+		{
+			indentLevel++
+			// _, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteTo(templ_7745c5c3_W)
+			if _, err = g.w.WriteIndent(indentLevel, "_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteTo(templ_7745c5c3_W)\n"); err != nil {
+				return err
+			}
+			indentLevel--
+		}
+		if _, err = g.w.WriteIndent(indentLevel, "}\n"); err != nil {
+			return err
+		}
+		// return templ_7745c5c3_Err
+		if _, err = g.w.WriteIndent(indentLevel, "return templ_7745c5c3_Err\n"); err != nil {
+			return err
+		}
+		indentLevel--
+	}
+	// })
+	closingFunc := "})\n"
+	if precompress {
+		closingFunc = "}))\n"
+	}
+	if _, err = g.w.WriteIndent(indentLevel, closingFunc); err != nil {
+		return err
+	}
+	indentLevel--
+	// }
 
 	// Note: gofmt wants to remove a single empty line at the end of a file
 	// so we have to make sure we don't output one if this is the last node.
@@ -454,385 +1182,1429 @@ func (g *generator) writeTemplate(nodeIdx int, t parser.HTMLTemplate) error {
 	if nodeIdx+1 >= len(g.tf.Nodes) {
 		closingBrace = "}\n"
 	}
-
-	if _, err = g.w.WriteIndent(indentLevel, closingBrace); err != nil {
+	if precompress {
+		if _, err = g.w.WriteIndent(indentLevel, "}\n\n"); err != nil {
+			return err
+		}
+		if err = g.writePrecompressedContent(precompressedVar); err != nil {
+			return err
+		}
+		closingBrace = strings.TrimPrefix(closingBrace, "}\n")
+	}
+	if len(t.Defaults) > 0 {
+		if !precompress {
+			if _, err = g.w.WriteIndent(indentLevel, "}\n\n"); err != nil {
+				return err
+			}
+			closingBrace = strings.TrimPrefix(closingBrace, "}\n")
+		}
+		if err = g.writeOptionsType(t); err != nil {
+			return err
+		}
+	}
+
+	if _, err = g.w.WriteIndent(indentLevel, closingBrace); err != nil {
+		return err
+	}
+	return nil
+}
+
+// writeTemplateSignature writes the name and parameters of the template. The
+// parameters that have default values are replaced by a variadic options
+// parameter, e.g. `Button(label string, variant string = "primary")` becomes
+// `Button(label string, templ_7745c5c3_Options ...ButtonOptions)`.
+func (g *generator) writeTemplateSignature(t parser.HTMLTemplate) (err error) {
+	var r parser.Range
+	if len(t.Defaults) == 0 {
+		if r, err = g.w.Write(t.Expression.Value); err != nil {
+			return err
+		}
+		g.sourceMap.Add(t.Expression, r)
+		return nil
+	}
+	required := parser.Expression{
+		Value: t.Expression.Value[:t.Defaults[0].Name.Range.From.Index-t.Expression.Range.From.Index],
+		Range: parser.Range{From: t.Expression.Range.From, To: t.Defaults[0].Name.Range.From},
+	}
+	if r, err = g.w.Write(required.Value); err != nil {
+		return err
+	}
+	g.sourceMap.Add(required, r)
+	_, err = g.w.Write(fmt.Sprintf("templ_7745c5c3_Options ...%s)", optionsTypeName(t)))
+	return err
+}
+
+// writeDefaults declares a variable for each parameter with a default value,
+// and sets it from the options passed to the template, unless the option is
+// nil.
+func (g *generator) writeDefaults(indentLevel int, t parser.HTMLTemplate) (err error) {
+	if len(t.Defaults) == 0 {
+		return nil
+	}
+	var r parser.Range
+	for _, d := range t.Defaults {
+		// var variant string = "primary"
+		if _, err = g.w.WriteIndent(indentLevel, fmt.Sprintf("var %s %s = ", d.Name.Value, d.Type)); err != nil {
+			return err
+		}
+		if r, err = g.w.Write(d.Value.Value); err != nil {
+			return err
+		}
+		g.sourceMap.Add(d.Value, r)
+		if _, err = g.w.Write("\n"); err != nil {
+			return err
+		}
+	}
+	// for _, templ_7745c5c3_Option := range templ_7745c5c3_Options {
+	if _, err = g.w.WriteIndent(indentLevel, "for _, templ_7745c5c3_Option := range templ_7745c5c3_Options {\n"); err != nil {
+		return err
+	}
+	for _, d := range t.Defaults {
+		// variant = templ.Default(templ_7745c5c3_Option.Variant, variant)
+		if _, err = g.w.WriteIndent(indentLevel+1, fmt.Sprintf("%[1]s = templ.Default(templ_7745c5c3_Option.%[2]s, %[1]s)\n", d.Name.Value, optionsFieldName(d.Name.Value))); err != nil {
+			return err
+		}
+	}
+	_, err = g.w.WriteIndent(indentLevel, "}\n")
+	return err
+}
+
+// writeOptionsType writes the struct that holds the optional parameters of the
+// template, i.e. the parameters that have default values.
+func (g *generator) writeOptionsType(t parser.HTMLTemplate) (err error) {
+	name := optionsTypeName(t)
+	// // ButtonOptions are the optional parameters of Button.
+	if _, err = g.w.Write(fmt.Sprintf("// %s are the optional parameters of %s.\n", name, templateName(t.Expression.Value))); err != nil {
+		return err
+	}
+	if _, err = g.w.Write("// Fields that are nil use the default value of the parameter.\n"); err != nil {
+		return err
+	}
+	if _, err = g.w.Write(fmt.Sprintf("type %s struct {\n", name)); err != nil {
+		return err
+	}
+	for _, d := range t.Defaults {
+		if _, err = g.w.WriteIndent(1, fmt.Sprintf("%s *%s\n", optionsFieldName(d.Name.Value), d.Type)); err != nil {
+			return err
+		}
+	}
+	_, err = g.w.Write("}\n")
+	return err
+}
+
+// optionsTypeName returns the name of the struct that holds the optional
+// parameters of a template, e.g. "ButtonOptions".
+func optionsTypeName(t parser.HTMLTemplate) string {
+	return templateName(t.Expression.Value) + "Options"
+}
+
+// optionsFieldName returns the exported field name of an optional parameter,
+// e.g. "Variant" for "variant".
+func optionsFieldName(param string) string {
+	r, size := utf8.DecodeRuneInString(param)
+	return string(unicode.ToUpper(r)) + param[size:]
+}
+
+// precompressedVar returns the name of the variable that holds the compressed
+// output of the template, if the template's output can be precompressed.
+//
+// The output of templates with dynamic content can't be precompressed, nor can
+// the output of templates that can change without regenerating the Go code,
+// i.e. in watch mode, or that call render hooks.
+func (g *generator) precompressedVar(t parser.HTMLTemplate) (name string, ok bool) {
+	if !g.precompress || g.hotReload || g.renderTrace {
+		return "", false
+	}
+	if _, extractingStrings := g.w.literalWriter.(*watchLiteralWriter); extractingStrings {
+		return "", false
+	}
+	if !isStatic(t.Children) {
+		return "", false
+	}
+	return "templ_7745c5c3_Precompressed_" + strings.ReplaceAll(templateName(t.Expression.Value), ".", "_"), true
+}
+
+// isStatic returns true if the nodes only contain static content, i.e. their
+// output is a single string literal.
+func isStatic(nodes []parser.Node) bool {
+	for _, n := range nodes {
+		switch n := n.(type) {
+		case parser.DocType, parser.ProcessingInstruction, parser.HTMLComment, parser.RawBlock, parser.Text, parser.Whitespace, parser.GoComment:
+			continue
+		case parser.Element:
+			if !isStaticAttributes(n.Attributes) || !isStatic(n.Children) {
+				return false
+			}
+		case parser.RawElement:
+			if !isStaticAttributes(n.Attributes) || len(n.Expressions) > 0 {
+				return false
+			}
+		case parser.CDATA:
+			if len(n.Expressions) > 0 {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func isStaticAttributes(attrs []parser.Attribute) bool {
+	for _, attr := range attrs {
+		switch attr.(type) {
+		case parser.ConstantAttribute, parser.BoolConstantAttribute:
+			continue
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// writePrecompressedContent writes a variable that holds the output captured
+// while the template was written, compressed with gzip and brotli.
+func (g *generator) writePrecompressedContent(name string) (err error) {
+	output, err := strconv.Unquote(`"` + g.w.capture.String() + `"`)
+	g.w.capture = nil
+	if err != nil {
+		return fmt.Errorf("failed to precompress %s: %w", name, err)
+	}
+	var gz, br bytes.Buffer
+	gzw, _ := gzip.NewWriterLevel(&gz, gzip.BestCompression)
+	if _, err = io.WriteString(gzw, output); err != nil {
+		return err
+	}
+	if err = gzw.Close(); err != nil {
+		return err
+	}
+	brw := brotli.NewWriterLevel(&br, brotli.BestCompression)
+	if _, err = io.WriteString(brw, output); err != nil {
+		return err
+	}
+	if err = brw.Close(); err != nil {
+		return err
+	}
+	_, err = g.w.Write(fmt.Sprintf("var %s = templ.PrecompressedContent{\n\tGzip:   %s,\n\tBrotli: %s,\n}\n", name, strconv.QuoteToASCII(gz.String()), strconv.QuoteToASCII(br.String())))
+	return err
+}
+
+// debugMarker returns the HTML comment written before or after the output of
+// a component, e.g. <!-- templ: header (components.templ:12) -->.
+func (g *generator) debugMarker(t parser.HTMLTemplate, start bool) string {
+	name := templateName(t.Expression.Value)
+	if !start {
+		return "<!-- /templ: " + name + " -->"
+	}
+	marker := fmt.Sprintf("<!-- templ: %s (%s) -->", name, g.templateLocation(t))
+	quoted := strconv.Quote(marker)
+	return quoted[1 : len(quoted)-1]
+}
+
+// writeHotReload writes code that returns the hot reloaded version of the
+// template, if there is one. Templates that the interpreter can't call, i.e.
+// methods, and templates with type parameters, variadic or unnamed
+// parameters, always use the compiled code.
+func (g *generator) writeHotReload(indentLevel int, t parser.HTMLTemplate) (err error) {
+	if !g.hotReload {
+		return nil
+	}
+	name, params, ok := hotReloadSignature(t.Signature())
+	if !ok {
+		return nil
+	}
+	args := append([]string{strconv.Quote(name)}, params...)
+	// if templ_7745c5c3_HotReload, templ_7745c5c3_HotReloaded := templ.HotReload("name", params...); templ_7745c5c3_HotReloaded {
+	if _, err = g.w.WriteIndent(indentLevel, fmt.Sprintf("if templ_7745c5c3_HotReload, templ_7745c5c3_HotReloaded := templ.HotReload(%s); templ_7745c5c3_HotReloaded {\n", strings.Join(args, ", "))); err != nil {
+		return err
+	}
+	if _, err = g.w.WriteIndent(indentLevel+1, "return templ_7745c5c3_HotReload\n"); err != nil {
+		return err
+	}
+	_, err = g.w.WriteIndent(indentLevel, "}\n")
+	return err
+}
+
+// hotReloadSignature returns the name and parameter names of a template that
+// can be hot reloaded.
+func hotReloadSignature(signature string) (name string, params []string, ok bool) {
+	f, err := goparser.ParseFile(token.NewFileSet(), "", "package p\nfunc "+signature+" {}", goparser.SkipObjectResolution)
+	if err != nil || len(f.Decls) != 1 {
+		return "", nil, false
+	}
+	fn, ok := f.Decls[0].(*ast.FuncDecl)
+	if !ok || fn.Recv != nil || fn.Type.TypeParams != nil {
+		return "", nil, false
+	}
+	for _, field := range fn.Type.Params.List {
+		if _, isVariadic := field.Type.(*ast.Ellipsis); isVariadic || len(field.Names) == 0 {
+			return "", nil, false
+		}
+		for _, n := range field.Names {
+			if n.Name == "_" {
+				return "", nil, false
+			}
+			params = append(params, n.Name)
+		}
+	}
+	return fn.Name.Name, params, true
+}
+
+// templateLocation returns the file and line that a template is declared on,
+// e.g. "components.templ:12".
+func (g *generator) templateLocation(t parser.HTMLTemplate) string {
+	fileName := g.fileName
+	if fileName == "" {
+		fileName = "templ"
+	}
+	return fmt.Sprintf("%s:%d", fileName, t.Expression.Range.From.Line+1)
+}
+
+// templateName returns the name of a template from its signature, e.g.
+// "(p Page) header(title string)" returns "Page.header".
+func templateName(signature string) string {
+	signature = strings.TrimSpace(signature)
+	var receiver string
+	if strings.HasPrefix(signature, "(") {
+		end := strings.Index(signature, ")")
+		if end < 0 {
+			return signature
+		}
+		fields := strings.Fields(signature[1:end])
+		if len(fields) > 0 {
+			receiver = strings.TrimPrefix(fields[len(fields)-1], "*") + "."
+		}
+		signature = strings.TrimSpace(signature[end+1:])
+	}
+	if end := strings.IndexAny(signature, "[("); end >= 0 {
+		signature = signature[:end]
+	}
+	return receiver + strings.TrimSpace(signature)
+}
+
+func stripWhitespace(input []parser.Node) (output []parser.Node) {
+	for i, n := range input {
+		if _, isWhiteSpace := n.(parser.Whitespace); !isWhiteSpace {
+			output = append(output, input[i])
+		}
+	}
+	return output
+}
+
+func stripLeadingWhitespace(nodes []parser.Node) []parser.Node {
+	for i := 0; i < len(nodes); i++ {
+		n := nodes[i]
+		if _, isWhiteSpace := n.(parser.Whitespace); !isWhiteSpace {
+			return nodes[i:]
+		}
+	}
+	return []parser.Node{}
+}
+
+func stripTrailingWhitespace(nodes []parser.Node) []parser.Node {
+	for i := len(nodes) - 1; i >= 0; i-- {
+		n := nodes[i]
+		if _, isWhiteSpace := n.(parser.Whitespace); !isWhiteSpace {
+			return nodes[0 : i+1]
+		}
+	}
+	return []parser.Node{}
+}
+
+func stripLeadingAndTrailingWhitespace(nodes []parser.Node) []parser.Node {
+	return stripTrailingWhitespace(stripLeadingWhitespace(nodes))
+}
+
+func (g *generator) writeNodes(indentLevel int, nodes []parser.Node, next parser.Node) error {
+	for i, curr := range nodes {
+		var nextNode parser.Node
+		if i+1 < len(nodes) {
+			nextNode = nodes[i+1]
+		}
+		if nextNode == nil {
+			nextNode = next
+		}
+		if err := g.writeNode(indentLevel, curr, nextNode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *generator) writeNode(indentLevel int, current parser.Node, next parser.Node) (err error) {
+	if g.readable {
+		if err = g.writeSourceComment(indentLevel, current); err != nil {
+			return err
+		}
+	}
+	switch n := current.(type) {
+	case parser.DocType:
+		err = g.writeDocType(indentLevel, n)
+	case parser.ProcessingInstruction:
+		err = g.writeProcessingInstruction(indentLevel, n)
+	case parser.Element:
+		err = g.writeElement(indentLevel, n)
+	case parser.HTMLComment:
+		err = g.writeComment(indentLevel, n)
+	case parser.CDATA:
+		err = g.writeCDATA(indentLevel, n)
+	case parser.RawBlock:
+		err = g.writeText(indentLevel, parser.Text{Value: n.Contents})
+	case parser.MarkdownBlock:
+		err = g.writeMarkdownBlock(indentLevel, n)
+	case parser.ChildrenExpression:
+		err = g.writeChildrenExpression(indentLevel)
+	case parser.RawElement:
+		err = g.writeRawElement(indentLevel, n)
+	case parser.ForExpression:
+		err = g.writeForExpression(indentLevel, n, next)
+	case parser.CallTemplateExpression:
+		err = g.writeCallTemplateExpression(indentLevel, n)
+	case parser.TemplElementExpression:
+		err = g.writeTemplElementExpression(indentLevel, n)
+	case parser.IfExpression:
+		err = g.writeIfExpression(indentLevel, n, next)
+	case parser.SwitchExpression:
+		err = g.writeSwitchExpression(indentLevel, n, next)
+	case parser.StringExpression:
+		err = g.writeStringExpression(indentLevel, n.Expression)
+	case parser.GoCode:
+		err = g.writeGoCode(indentLevel, n.Expression)
+	case parser.BranchStatement:
+		err = g.writeGoCode(indentLevel, n.Expression)
+	case parser.DeferExpression:
+		err = g.writeDeferExpression(indentLevel, n)
+	case parser.InlineTemplate:
+		err = g.writeInlineTemplate(indentLevel, n)
+	case parser.BlockExpression:
+		err = g.writeBlockExpression(indentLevel, n)
+	case parser.IncludeExpression:
+		err = g.writeIncludeExpression(indentLevel, n, next)
+	case parser.Whitespace:
+		err = g.writeWhitespace(indentLevel, n)
+	case parser.Text:
+		if trimsWhitespace(n, next) {
+			n.Value = strings.TrimRightFunc(n.Value, unicode.IsSpace)
+		}
+		err = g.writeText(indentLevel, n)
+	case parser.GoComment:
+		// Do not render Go comments in the output HTML.
+		return
+	default:
+		return fmt.Errorf("unhandled type: %v", reflect.TypeOf(n))
+	}
+	// Write trailing whitespace, if there is a next node that might need the space.
+	// If the next node is inline or text, we might need it.
+	// If the current node is a block element, we don't need it.
+	// Trim markers remove the whitespace.
+	needed := (isInlineOrText(current) && isInlineOrText(next)) && !trimsWhitespace(current, next)
+	if ws, ok := current.(parser.WhitespaceTrailer); ok && needed {
+		if err := g.writeWhitespaceTrailer(indentLevel, ws.Trailing()); err != nil {
+			return err
+		}
+	}
+	return
+}
+
+// writeSourceComment writes a comment that cites the templ source line of the
+// node, if it's on a different line to the previous node that has a position.
+// Since the comment ends any string literal that's being written, static
+// content is written with one write per source line.
+func (g *generator) writeSourceComment(indentLevel int, n parser.Node) (err error) {
+	line, description, ok := describeNode(n)
+	if !ok {
+		return nil
+	}
+	fileName := g.fileName
+	if fileName == "" {
+		fileName = "templ"
+	}
+	location := fmt.Sprintf("%s:%d", fileName, line+1)
+	if location == g.lastSourceComment {
+		return nil
+	}
+	g.lastSourceComment = location
+	_, err = g.w.WriteIndent(indentLevel, "// "+location+": "+description+"\n")
+	return err
+}
+
+// describeNode returns the zero-based line that a node starts on, and a short
+// description of the node, e.g. "<div>" or "if len(items) > 0".
+func describeNode(n parser.Node) (line uint32, description string, ok bool) {
+	var expr parser.Expression
+	switch n := n.(type) {
+	case parser.Element:
+		return n.NameRange.From.Line, "<" + n.Name + ">", true
+	case parser.StringExpression:
+		expr, description = n.Expression, "{ %s }"
+	case parser.IfExpression:
+		expr, description = n.Expression, "if %s"
+	case parser.ForExpression:
+		expr, description = n.Expression, "for %s"
+	case parser.SwitchExpression:
+		expr, description = n.Expression, "switch %s"
+	case parser.CallTemplateExpression:
+		expr, description = n.Expression, "@%s"
+	case parser.TemplElementExpression:
+		expr, description = n.Expression, "@%s"
+	case parser.GoCode:
+		expr, description = n.Expression, "{{ %s }}"
+	case parser.BranchStatement:
+		expr, description = n.Expression, "%s"
+	case parser.IncludeExpression:
+		expr, description = n.Path, "@include %s"
+	default:
+		return 0, "", false
+	}
+	value := strings.TrimSpace(expr.Value)
+	if i := strings.IndexByte(value, '\n'); i >= 0 {
+		value = strings.TrimSpace(value[:i]) + " ..."
+	}
+	const maxLength = 60
+	if utf8.RuneCountInString(value) > maxLength {
+		value = string([]rune(value)[:maxLength]) + "..."
+	}
+	return expr.Range.From.Line, fmt.Sprintf(description, value), true
+}
+
+// trimsWhitespace returns true if a trim marker, i.e. the {- or -} of a string
+// expression, removes the whitespace between the nodes.
+func trimsWhitespace(current, next parser.Node) bool {
+	if se, ok := current.(parser.StringExpression); ok && se.TrimAfter {
+		return true
+	}
+	if se, ok := next.(parser.StringExpression); ok && se.TrimBefore {
+		return true
+	}
+	return false
+}
+
+func isInlineOrText(next parser.Node) bool {
+	// While these are formatted as blocks when they're written in the HTML template.
+	// They're inline - i.e. there's no whitespace rendered around them at runtime for minification.
+	if next == nil {
+		return false
+	}
+	switch n := next.(type) {
+	case parser.IfExpression:
+		return true
+	case parser.SwitchExpression:
+		return true
+	case parser.ForExpression:
+		return true
+	case parser.Element:
+		// Whitespace between the elements within <math> is ignored, but the
+		// <math> element itself is inline.
+		return !n.IsBlockElement() && (n.Name == "math" || !parser.IsMathMLElement(n.Name))
+	case parser.Text:
+		return true
+	case parser.StringExpression:
+		return true
+	case parser.CDATA:
+		return true
+	}
+	return false
+}
+
+func (g *generator) writeWhitespaceTrailer(indentLevel int, n parser.TrailingSpace) (err error) {
+	if n == parser.SpaceNone {
+		return nil
+	}
+	// Normalize whitespace for minified output. In HTML, a single space is equivalent to
+	// any number of spaces, tabs, or newlines.
+	if n.IsVertical() {
+		n = parser.SpaceHorizontal
+	}
+	if _, err = g.w.WriteStringLiteral(indentLevel, string(n)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (g *generator) writeDocType(indentLevel int, n parser.DocType) (err error) {
+	if _, err = g.w.WriteStringLiteral(indentLevel, fmt.Sprintf("<!doctype %s>", n.Value)); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (g *generator) writeProcessingInstruction(indentLevel int, n parser.ProcessingInstruction) (err error) {
+	// The instruction's attributes are quoted, so it's written as text.
+	return g.writeText(indentLevel, parser.Text{Value: "<?" + n.Value + "?>"})
+}
+
+func (g *generator) writeIfExpression(indentLevel int, n parser.IfExpression, nextNode parser.Node) (err error) {
+	var r parser.Range
+	// if
+	if _, err = g.w.WriteIndent(indentLevel, `if `); err != nil {
+		return err
+	}
+	// x == y {
+	if r, err = g.w.Write(n.Expression.Value); err != nil {
+		return err
+	}
+	g.sourceMap.Add(n.Expression, r)
+	// {
+	if _, err = g.w.Write(` {` + "\n"); err != nil {
+		return err
+	}
+	{
+		indentLevel++
+		if err = g.writeNodes(indentLevel, stripLeadingAndTrailingWhitespace(n.Then), nextNode); err != nil {
+			return err
+		}
+		indentLevel--
+	}
+	for _, elseIf := range n.ElseIfs {
+		// } else if {
+		if _, err = g.w.WriteIndent(indentLevel, `} else if `); err != nil {
+			return err
+		}
+		// x == y {
+		if r, err = g.w.Write(elseIf.Expression.Value); err != nil {
+			return err
+		}
+		g.sourceMap.Add(elseIf.Expression, r)
+		// {
+		if _, err = g.w.Write(` {` + "\n"); err != nil {
+			return err
+		}
+		{
+			indentLevel++
+			if err = g.writeNodes(indentLevel, stripLeadingAndTrailingWhitespace(elseIf.Then), nextNode); err != nil {
+				return err
+			}
+			indentLevel--
+		}
+	}
+	if len(n.Else) > 0 {
+		// } else {
+		if _, err = g.w.WriteIndent(indentLevel, `} else {`+"\n"); err != nil {
+			return err
+		}
+		{
+			indentLevel++
+			if err = g.writeNodes(indentLevel, stripLeadingAndTrailingWhitespace(n.Else), nextNode); err != nil {
+				return err
+			}
+			indentLevel--
+		}
+	}
+	// }
+	if _, err = g.w.WriteIndent(indentLevel, `}`+"\n"); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (g *generator) writeSwitchExpression(indentLevel int, n parser.SwitchExpression, next parser.Node) (err error) {
+	var r parser.Range
+	// outer:
+	if err = g.writeLabel(indentLevel, n.Label); err != nil {
+		return err
+	}
+	// switch
+	if _, err = g.w.WriteIndent(indentLevel, `switch `); err != nil {
+		return err
+	}
+	// val
+	if r, err = g.w.Write(n.Expression.Value); err != nil {
+		return err
+	}
+	g.sourceMap.Add(n.Expression, r)
+	// {
+	if _, err = g.w.Write(` {` + "\n"); err != nil {
+		return err
+	}
+
+	if len(n.Cases) > 0 {
+		for _, c := range n.Cases {
+			// case x:
+			// default:
+			if r, err = g.w.WriteIndent(indentLevel, c.Expression.Value); err != nil {
+				return err
+			}
+			g.sourceMap.Add(c.Expression, r)
+			indentLevel++
+			if err = g.writeNodes(indentLevel, stripLeadingAndTrailingWhitespace(c.Children), next); err != nil {
+				return err
+			}
+			indentLevel--
+		}
+	}
+	// }
+	if _, err = g.w.WriteIndent(indentLevel, `}`+"\n"); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (g *generator) writeChildrenExpression(indentLevel int) (err error) {
+	if _, err = g.w.WriteIndent(indentLevel, fmt.Sprintf("templ_7745c5c3_Err = %s.Render(ctx, templ_7745c5c3_Buffer)\n", g.childrenVar)); err != nil {
+		return err
+	}
+	if err = g.writeErrorHandler(indentLevel); err != nil {
 		return err
 	}
 	return nil
 }
 
-func stripWhitespace(input []parser.Node) (output []parser.Node) {
-	for i, n := range input {
-		if _, isWhiteSpace := n.(parser.Whitespace); !isWhiteSpace {
-			output = append(output, input[i])
-		}
+func (g *generator) writeTemplElementExpression(indentLevel int, n parser.TemplElementExpression) (err error) {
+	if len(n.Children) == 0 {
+		return g.writeSelfClosingTemplElementExpression(indentLevel, n)
 	}
-	return output
+	return g.writeBlockTemplElementExpression(indentLevel, n)
 }
 
-func stripLeadingWhitespace(nodes []parser.Node) []parser.Node {
-	for i := 0; i < len(nodes); i++ {
-		n := nodes[i]
-		if _, isWhiteSpace := n.(parser.Whitespace); !isWhiteSpace {
-			return nodes[i:]
+func (g *generator) writeBlockTemplElementExpression(indentLevel int, n parser.TemplElementExpression) (err error) {
+	var r parser.Range
+	// Blocks that are children of the element override the blocks of the
+	// component, the other nodes are its children.
+	var children []parser.Node
+	var blocks []parser.BlockExpression
+	for _, child := range n.Children {
+		if b, ok := child.(parser.BlockExpression); ok {
+			blocks = append(blocks, b)
+			continue
+		}
+		children = append(children, child)
+	}
+	ctx := "ctx"
+	if len(blocks) == 0 || len(stripLeadingAndTrailingWhitespace(children)) > 0 {
+		childrenName, err := g.writeComponentFunc(indentLevel, children)
+		if err != nil {
+			return err
 		}
+		ctx = "templ.WithChildren(ctx, " + childrenName + ")"
 	}
-	return []parser.Node{}
+	if len(blocks) > 0 {
+		overrides := make([]string, len(blocks))
+		for i, b := range blocks {
+			for _, prev := range blocks[:i] {
+				if prev.Name == b.Name {
+					return fmt.Errorf("block %s: overridden more than once", b.Name)
+				}
+			}
+			name, err := g.writeComponentFunc(indentLevel, b.Children)
+			if err != nil {
+				return err
+			}
+			overrides[i] = strconv.Quote(b.Name) + ": " + name
+		}
+		ctx = "templ.WithBlocks(" + ctx + ", templ.Slots{" + strings.Join(overrides, ", ") + "})"
+	}
+	if _, err = g.w.WriteIndent(indentLevel, `templ_7745c5c3_Err = `); err != nil {
+		return err
+	}
+	if r, err = g.w.Write(n.Expression.Value); err != nil {
+		return err
+	}
+	g.sourceMap.Add(n.Expression, r)
+	// .Render(templ.WithChildren(ctx, children), templ_7745c5c3_Buffer)
+	if _, err = g.w.Write(".Render(" + ctx + ", templ_7745c5c3_Buffer)\n"); err != nil {
+		return err
+	}
+	if err = g.writeErrorHandler(indentLevel); err != nil {
+		return err
+	}
+	return nil
 }
 
-func stripTrailingWhitespace(nodes []parser.Node) []parser.Node {
-	for i := len(nodes) - 1; i >= 0; i-- {
-		n := nodes[i]
-		if _, isWhiteSpace := n.(parser.Whitespace); !isWhiteSpace {
-			return nodes[0 : i+1]
+// writeComponentFunc writes a variable that holds a component that renders
+// the nodes, e.g. the children of a templ element, and returns its name.
+func (g *generator) writeComponentFunc(indentLevel int, nodes []parser.Node) (name string, err error) {
+	name = g.createVariableName()
+	if _, err = g.w.WriteIndent(indentLevel, name+" := templ.ComponentFunc(func(ctx context.Context, templ_7745c5c3_W io.Writer) (templ_7745c5c3_Err error) {\n"); err != nil {
+		return name, err
+	}
+	indentLevel++
+	if err := g.writeTemplBuffer(indentLevel); err != nil {
+		return name, err
+	}
+	if err = g.writeNodesWithDeferred(indentLevel, stripLeadingAndTrailingWhitespace(nodes)); err != nil {
+		return name, err
+	}
+	// Return the buffer.
+	if _, err = g.w.WriteIndent(indentLevel, "if !templ_7745c5c3_IsBuffer {\n"); err != nil {
+		return name, err
+	}
+	{
+		indentLevel++
+		// _, templ_7745c5c3_Err = io.Copy(templ_7745c5c3_W, templ_7745c5c3_Buffer)
+		if _, err = g.w.WriteIndent(indentLevel, "_, templ_7745c5c3_Err = io.Copy(templ_7745c5c3_W, templ_7745c5c3_Buffer)\n"); err != nil {
+			return name, err
 		}
+		indentLevel--
 	}
-	return []parser.Node{}
+	if _, err = g.w.WriteIndent(indentLevel, "}\n"); err != nil {
+		return name, err
+	}
+	// return nil
+	if _, err = g.w.WriteIndent(indentLevel, "return templ_7745c5c3_Err\n"); err != nil {
+		return name, err
+	}
+	indentLevel--
+	if _, err = g.w.WriteIndent(indentLevel, "})\n"); err != nil {
+		return name, err
+	}
+	return name, nil
 }
 
-func stripLeadingAndTrailingWhitespace(nodes []parser.Node) []parser.Node {
-	return stripTrailingWhitespace(stripLeadingWhitespace(nodes))
+func (g *generator) writeSelfClosingTemplElementExpression(indentLevel int, n parser.TemplElementExpression) (err error) {
+	if _, err = g.w.WriteIndent(indentLevel, `templ_7745c5c3_Err = `); err != nil {
+		return err
+	}
+	// Template expression.
+	var r parser.Range
+	if r, err = g.w.Write(n.Expression.Value); err != nil {
+		return err
+	}
+	g.sourceMap.Add(n.Expression, r)
+	// .Render(ctx, templ_7745c5c3_Buffer)
+	if _, err = g.w.Write(".Render(ctx, templ_7745c5c3_Buffer)\n"); err != nil {
+		return err
+	}
+	if err = g.writeErrorHandler(indentLevel); err != nil {
+		return err
+	}
+	return nil
 }
 
-func (g *generator) writeNodes(indentLevel int, nodes []parser.Node, next parser.Node) error {
-	for i, curr := range nodes {
-		var nextNode parser.Node
-		if i+1 < len(nodes) {
-			nextNode = nodes[i+1]
-		}
-		if nextNode == nil {
-			nextNode = next
+func (g *generator) writeCallTemplateExpression(indentLevel int, n parser.CallTemplateExpression) (err error) {
+	if _, err = g.w.WriteIndent(indentLevel, `templ_7745c5c3_Err = `); err != nil {
+		return err
+	}
+	// Template expression.
+	var r parser.Range
+	if r, err = g.w.Write(n.Expression.Value); err != nil {
+		return err
+	}
+	g.sourceMap.Add(n.Expression, r)
+	// .Render(ctx, templ_7745c5c3_Buffer)
+	if _, err = g.w.Write(".Render(ctx, templ_7745c5c3_Buffer)\n"); err != nil {
+		return err
+	}
+	if err = g.writeErrorHandler(indentLevel); err != nil {
+		return err
+	}
+	return nil
+}
+
+// writeNodesWithDeferred writes the nodes of a component, followed by the
+// output of any defer blocks within them, in reverse order.
+func (g *generator) writeNodesWithDeferred(indentLevel int, nodes []parser.Node) (err error) {
+	prev := g.deferredVar
+	defer func() {
+		g.deferredVar = prev
+	}()
+	g.deferredVar = ""
+	if !containsDeferExpression(nodes) {
+		return g.writeNodes(indentLevel, nodes, nil)
+	}
+	g.deferredVar = g.createVariableName()
+	// var templ_7745c5c3_Var2 []*bytes.Buffer
+	if _, err = g.w.WriteIndent(indentLevel, "var "+g.deferredVar+" []*bytes.Buffer\n"); err != nil {
+		return err
+	}
+	if err = g.writeNodes(indentLevel, nodes, nil); err != nil {
+		return err
+	}
+	// for i := len(templ_7745c5c3_Var2) - 1; i >= 0; i-- {
+	if _, err = g.w.WriteIndent(indentLevel, "for templ_7745c5c3_Index := len("+g.deferredVar+") - 1; templ_7745c5c3_Index >= 0; templ_7745c5c3_Index-- {\n"); err != nil {
+		return err
+	}
+	{
+		indentLevel++
+		// _, templ_7745c5c3_Err = templ_7745c5c3_Var2[i].WriteTo(templ_7745c5c3_Buffer)
+		if _, err = g.w.WriteIndent(indentLevel, "_, templ_7745c5c3_Err = "+g.deferredVar+"[templ_7745c5c3_Index].WriteTo(templ_7745c5c3_Buffer)\n"); err != nil {
+			return err
 		}
-		if err := g.writeNode(indentLevel, curr, nextNode); err != nil {
+		if err = g.writeErrorHandler(indentLevel); err != nil {
 			return err
 		}
+		indentLevel--
+	}
+	if _, err = g.w.WriteIndent(indentLevel, "}\n"); err != nil {
+		return err
 	}
 	return nil
 }
 
-func (g *generator) writeNode(indentLevel int, current parser.Node, next parser.Node) (err error) {
-	switch n := current.(type) {
-	case parser.DocType:
-		err = g.writeDocType(indentLevel, n)
-	case parser.Element:
-		err = g.writeElement(indentLevel, n)
-	case parser.HTMLComment:
-		err = g.writeComment(indentLevel, n)
-	case parser.ChildrenExpression:
-		err = g.writeChildrenExpression(indentLevel)
-	case parser.RawElement:
-		err = g.writeRawElement(indentLevel, n)
-	case parser.ForExpression:
-		err = g.writeForExpression(indentLevel, n, next)
-	case parser.CallTemplateExpression:
-		err = g.writeCallTemplateExpression(indentLevel, n)
-	case parser.TemplElementExpression:
-		err = g.writeTemplElementExpression(indentLevel, n)
-	case parser.IfExpression:
-		err = g.writeIfExpression(indentLevel, n, next)
-	case parser.SwitchExpression:
-		err = g.writeSwitchExpression(indentLevel, n, next)
-	case parser.StringExpression:
-		err = g.writeStringExpression(indentLevel, n.Expression)
-	case parser.GoCode:
-		err = g.writeGoCode(indentLevel, n.Expression)
-	case parser.Whitespace:
-		err = g.writeWhitespace(indentLevel, n)
-	case parser.Text:
-		err = g.writeText(indentLevel, n)
-	case parser.GoComment:
-		// Do not render Go comments in the output HTML.
-		return
-	default:
-		return fmt.Errorf("unhandled type: %v", reflect.TypeOf(n))
+// containsDeferExpression returns true if the nodes contain a defer block
+// that belongs to the current component. The children of templ elements are
+// separate components, so they're not searched.
+func containsDeferExpression(nodes []parser.Node) bool {
+	for _, n := range nodes {
+		switch n := n.(type) {
+		case parser.DeferExpression:
+			return true
+		case parser.TemplElementExpression, parser.InlineTemplate:
+			continue
+		case parser.CompositeNode:
+			if containsDeferExpression(n.ChildNodes()) {
+				return true
+			}
+		}
 	}
-	// Write trailing whitespace, if there is a next node that might need the space.
-	// If the next node is inline or text, we might need it.
-	// If the current node is a block element, we don't need it.
-	needed := (isInlineOrText(current) && isInlineOrText(next))
-	if ws, ok := current.(parser.WhitespaceTrailer); ok && needed {
-		if err := g.writeWhitespaceTrailer(indentLevel, ws.Trailing()); err != nil {
+	return false
+}
+
+func (g *generator) writeDeferExpression(indentLevel int, n parser.DeferExpression) (err error) {
+	children := stripLeadingAndTrailingWhitespace(n.Children)
+	// Defer blocks within defer blocks are already deferred, so write them inline.
+	if g.deferredVar == "" {
+		return g.writeNodes(indentLevel, children, nil)
+	}
+	deferredVar := g.deferredVar
+	g.deferredVar = ""
+	defer func() {
+		g.deferredVar = deferredVar
+	}()
+	if _, err = g.w.WriteIndent(indentLevel, "{\n"); err != nil {
+		return err
+	}
+	{
+		indentLevel++
+		// templ_7745c5c3_Buffer := templ.GetBuffer()
+		if _, err = g.w.WriteIndent(indentLevel, "templ_7745c5c3_Buffer := templ.GetBuffer()\n"); err != nil {
+			return err
+		}
+		// defer templ.ReleaseBuffer(templ_7745c5c3_Buffer)
+		if _, err = g.w.WriteIndent(indentLevel, "defer templ.ReleaseBuffer(templ_7745c5c3_Buffer)\n"); err != nil {
+			return err
+		}
+		// templ_7745c5c3_Var2 = append(templ_7745c5c3_Var2, templ_7745c5c3_Buffer)
+		if _, err = g.w.WriteIndent(indentLevel, deferredVar+" = append("+deferredVar+", templ_7745c5c3_Buffer)\n"); err != nil {
 			return err
 		}
+		if err = g.writeNodes(indentLevel, children, nil); err != nil {
+			return err
+		}
+		indentLevel--
 	}
-	return
+	if _, err = g.w.WriteIndent(indentLevel, "}\n"); err != nil {
+		return err
+	}
+	return nil
 }
 
-func isInlineOrText(next parser.Node) bool {
-	// While these are formatted as blocks when they're written in the HTML template.
-	// They're inline - i.e. there's no whitespace rendered around them at runtime for minification.
-	if next == nil {
-		return false
+// hasMarkdownBlocks returns true if the templates of the file, or the files
+// that they include, contain markdown blocks.
+func (g *generator) hasMarkdownBlocks() bool {
+	seen := map[string]bool{}
+	for _, n := range g.tf.Nodes {
+		if t, ok := n.(parser.HTMLTemplate); ok && containsMarkdownBlock(g.includeDir, t.Children, seen) {
+			return true
+		}
 	}
-	switch n := next.(type) {
-	case parser.IfExpression:
-		return true
-	case parser.SwitchExpression:
-		return true
-	case parser.ForExpression:
-		return true
-	case parser.Element:
-		return !n.IsBlockElement()
-	case parser.Text:
-		return true
-	case parser.StringExpression:
-		return true
+	return false
+}
+
+// containsMarkdownBlock returns true if the nodes, or the files that they
+// include, contain markdown blocks. Files that can't be included are skipped,
+// since they're reported when the include expression is written.
+func containsMarkdownBlock(dir string, nodes []parser.Node, seen map[string]bool) bool {
+	for _, n := range nodes {
+		switch n := n.(type) {
+		case parser.MarkdownBlock:
+			return true
+		case parser.IncludeExpression:
+			_, fullPath, included, err := readInclude(dir, n)
+			if err != nil || seen[fullPath] {
+				continue
+			}
+			seen[fullPath] = true
+			if containsMarkdownBlock(filepath.Dir(fullPath), included, seen) {
+				return true
+			}
+		case parser.CompositeNode:
+			if containsMarkdownBlock(dir, n.ChildNodes(), seen) {
+				return true
+			}
+		}
 	}
 	return false
 }
 
-func (g *generator) writeWhitespaceTrailer(indentLevel int, n parser.TrailingSpace) (err error) {
-	if n == parser.SpaceNone {
+// writeMarkdownBlock writes the HTML of the Markdown, which is rendered when
+// the code is generated if a renderer is set, or when the template is
+// rendered.
+func (g *generator) writeMarkdownBlock(indentLevel int, n parser.MarkdownBlock) (err error) {
+	if g.renderMarkdown != nil {
+		html, err := g.renderMarkdown(n.Contents)
+		if err != nil {
+			return fmt.Errorf("markdown: %w", err)
+		}
+		return g.writeText(indentLevel, parser.Text{Value: html})
+	}
+	// templ_7745c5c3_Err = templ_7745c5c3_markdown.Component("# Title").Render(ctx, templ_7745c5c3_Buffer)
+	if _, err = g.w.WriteIndent(indentLevel, "templ_7745c5c3_Err = templ_7745c5c3_markdown.Component("+createGoString(n.Contents)+").Render(ctx, templ_7745c5c3_Buffer)\n"); err != nil {
+		return err
+	}
+	return g.writeErrorHandler(indentLevel)
+}
+
+// writeBlocksVar writes a variable that holds the blocks passed to the
+// component, if the nodes render any blocks.
+func (g *generator) writeBlocksVar(indentLevel int, nodes []parser.Node) (err error) {
+	g.blocksVar = ""
+	if !containsBlockExpression(nodes) {
 		return nil
 	}
-	// Normalize whitespace for minified output. In HTML, a single space is equivalent to
-	// any number of spaces, tabs, or newlines.
-	if n == parser.SpaceVertical {
-		n = parser.SpaceHorizontal
+	g.blocksVar = g.createVariableName()
+	// templ_7745c5c3_Var2 := templ.GetBlocks(ctx)
+	_, err = g.w.WriteIndent(indentLevel, g.blocksVar+" := templ.GetBlocks(ctx)\n")
+	return err
+}
+
+// containsBlockExpression returns true if the nodes render a block of the
+// current component. Blocks that are the children of a templ element override
+// the blocks of that component instead, but their contents can render blocks
+// of the current component. Inline templates are separate components, so
+// they're not searched.
+func containsBlockExpression(nodes []parser.Node) bool {
+	for _, n := range nodes {
+		switch n := n.(type) {
+		case parser.BlockExpression:
+			return true
+		case parser.InlineTemplate:
+			continue
+		case parser.TemplElementExpression:
+			for _, child := range n.Children {
+				if b, ok := child.(parser.BlockExpression); ok {
+					child = parser.DeferExpression{Children: b.Children}
+				}
+				if containsBlockExpression([]parser.Node{child}) {
+					return true
+				}
+			}
+		case parser.CompositeNode:
+			if containsBlockExpression(n.ChildNodes()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// writeBlockExpression writes the block of the same name that's passed to the
+// component, or the children of the block if there isn't one.
+func (g *generator) writeBlockExpression(indentLevel int, n parser.BlockExpression) (err error) {
+	children := stripLeadingAndTrailingWhitespace(n.Children)
+	if g.blocksVar == "" {
+		return g.writeNodes(indentLevel, children, nil)
+	}
+	// if templ_7745c5c3_Var2.Has("content") {
+	if _, err = g.w.WriteIndent(indentLevel, fmt.Sprintf("if %s.Has(%q) {\n", g.blocksVar, n.Name)); err != nil {
+		return err
+	}
+	{
+		indentLevel++
+		// templ_7745c5c3_Err = templ_7745c5c3_Var2.Get("content").Render(ctx, templ_7745c5c3_Buffer)
+		if _, err = g.w.WriteIndent(indentLevel, fmt.Sprintf("templ_7745c5c3_Err = %s.Get(%q).Render(ctx, templ_7745c5c3_Buffer)\n", g.blocksVar, n.Name)); err != nil {
+			return err
+		}
+		if err = g.writeErrorHandler(indentLevel); err != nil {
+			return err
+		}
+		indentLevel--
+	}
+	// } else {
+	if _, err = g.w.WriteIndent(indentLevel, "} else {\n"); err != nil {
+		return err
 	}
-	if _, err = g.w.WriteStringLiteral(indentLevel, string(n)); err != nil {
+	if err = g.writeNodes(indentLevel+1, children, nil); err != nil {
 		return err
 	}
-	return nil
-}
-
-func (g *generator) writeDocType(indentLevel int, n parser.DocType) (err error) {
-	if _, err = g.w.WriteStringLiteral(indentLevel, fmt.Sprintf("<!doctype %s>", n.Value)); err != nil {
+	// }
+	if _, err = g.w.WriteIndent(indentLevel, "}\n"); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (g *generator) writeIfExpression(indentLevel int, n parser.IfExpression, nextNode parser.Node) (err error) {
+// writeInlineTemplate writes a template that's declared within another
+// template as a function literal, so that it can use the parameters and
+// variables of the template.
+func (g *generator) writeInlineTemplate(indentLevel int, n parser.InlineTemplate) (err error) {
+	paramsStart := strings.IndexByte(n.Expression.Value, '(')
+	if paramsStart < 0 {
+		return fmt.Errorf("inline templ %s: missing parameters", n.Expression.Value)
+	}
+	name := subExpression(n.Expression, 0, len(strings.TrimRight(n.Expression.Value[:paramsStart], " ")))
+	params := subExpression(n.Expression, paramsStart, len(n.Expression.Value))
 	var r parser.Range
-	// if
-	if _, err = g.w.WriteIndent(indentLevel, `if `); err != nil {
+	// row := func(item Item) templ.Component {
+	if r, err = g.w.WriteIndent(indentLevel, name.Value); err != nil {
 		return err
 	}
-	// x == y {
-	if r, err = g.w.Write(n.Expression.Value); err != nil {
+	g.sourceMap.Add(name, r)
+	if _, err = g.w.Write(" := func"); err != nil {
 		return err
 	}
-	g.sourceMap.Add(n.Expression, r)
-	// {
-	if _, err = g.w.Write(` {` + "\n"); err != nil {
+	if r, err = g.w.Write(params.Value); err != nil {
+		return err
+	}
+	g.sourceMap.Add(params, r)
+	if _, err = g.w.Write(" templ.Component {\n"); err != nil {
+		return err
+	}
+	indentLevel++
+	// return templ.ComponentFunc(func(ctx context.Context, templ_7745c5c3_W io.Writer) (templ_7745c5c3_Err error) {
+	if _, err = g.w.WriteIndent(indentLevel, "return templ.ComponentFunc(func(ctx context.Context, templ_7745c5c3_W io.Writer) (templ_7745c5c3_Err error) {\n"); err != nil {
 		return err
 	}
 	{
 		indentLevel++
-		if err = g.writeNodes(indentLevel, stripLeadingAndTrailingWhitespace(n.Then), nextNode); err != nil {
+		if err = g.writeTemplBuffer(indentLevel); err != nil {
 			return err
 		}
-		indentLevel--
-	}
-	for _, elseIf := range n.ElseIfs {
-		// } else if {
-		if _, err = g.w.WriteIndent(indentLevel, `} else if `); err != nil {
+		// The children of the inline template replace the children of the
+		// template it's declared in.
+		prevChildrenVar := g.childrenVar
+		defer func() { g.childrenVar = prevChildrenVar }()
+		g.childrenVar = g.createVariableName()
+		// templ_7745c5c3_Var2 := templ.GetChildren(ctx)
+		if _, err = g.w.WriteIndent(indentLevel, fmt.Sprintf("%s := templ.GetChildren(ctx)\n", g.childrenVar)); err != nil {
 			return err
 		}
-		// x == y {
-		if r, err = g.w.Write(elseIf.Expression.Value); err != nil {
+		if _, err = g.w.WriteIndent(indentLevel, fmt.Sprintf("if %s == nil {\n", g.childrenVar)); err != nil {
 			return err
 		}
-		g.sourceMap.Add(elseIf.Expression, r)
-		// {
-		if _, err = g.w.Write(` {` + "\n"); err != nil {
+		if _, err = g.w.WriteIndent(indentLevel+1, fmt.Sprintf("%s = templ.NopComponent\n", g.childrenVar)); err != nil {
 			return err
 		}
-		{
-			indentLevel++
-			if err = g.writeNodes(indentLevel, stripLeadingAndTrailingWhitespace(elseIf.Then), nextNode); err != nil {
-				return err
-			}
-			indentLevel--
+		if _, err = g.w.WriteIndent(indentLevel, "}\n"); err != nil {
+			return err
 		}
-	}
-	if len(n.Else) > 0 {
-		// } else {
-		if _, err = g.w.WriteIndent(indentLevel, `} else {`+"\n"); err != nil {
+		prevBlocksVar := g.blocksVar
+		defer func() { g.blocksVar = prevBlocksVar }()
+		if err = g.writeBlocksVar(indentLevel, n.Children); err != nil {
 			return err
 		}
-		{
-			indentLevel++
-			if err = g.writeNodes(indentLevel, stripLeadingAndTrailingWhitespace(n.Else), nextNode); err != nil {
-				return err
-			}
-			indentLevel--
+		// ctx = templ.ClearChildren(ctx)
+		if _, err = g.w.WriteIndent(indentLevel, "ctx = templ.ClearChildren(ctx)\n"); err != nil {
+			return err
+		}
+		if err = g.writeNodesWithDeferred(indentLevel, stripWhitespace(n.Children)); err != nil {
+			return err
+		}
+		if _, err = g.w.WriteIndent(indentLevel, "if !templ_7745c5c3_IsBuffer {\n"); err != nil {
+			return err
+		}
+		// _, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteTo(templ_7745c5c3_W)
+		if _, err = g.w.WriteIndent(indentLevel+1, "_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteTo(templ_7745c5c3_W)\n"); err != nil {
+			return err
+		}
+		if _, err = g.w.WriteIndent(indentLevel, "}\n"); err != nil {
+			return err
+		}
+		// return templ_7745c5c3_Err
+		if _, err = g.w.WriteIndent(indentLevel, "return templ_7745c5c3_Err\n"); err != nil {
+			return err
 		}
+		indentLevel--
+	}
+	// })
+	if _, err = g.w.WriteIndent(indentLevel, "})\n"); err != nil {
+		return err
 	}
+	indentLevel--
 	// }
-	if _, err = g.w.WriteIndent(indentLevel, `}`+"\n"); err != nil {
+	if _, err = g.w.WriteIndent(indentLevel, "}\n"); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (g *generator) writeSwitchExpression(indentLevel int, n parser.SwitchExpression, next parser.Node) (err error) {
-	var r parser.Range
-	// switch
-	if _, err = g.w.WriteIndent(indentLevel, `switch `); err != nil {
-		return err
+// readInclude reads and parses the file of an include expression, whose path is
+// relative to dir.
+func readInclude(dir string, n parser.IncludeExpression) (path, fullPath string, nodes []parser.Node, err error) {
+	if path, err = n.FilePath(); err != nil {
+		return path, fullPath, nil, fmt.Errorf("include %s: %w", n.Path.Value, err)
 	}
-	// val
-	if r, err = g.w.Write(n.Expression.Value); err != nil {
-		return err
+	fullPath = path
+	if !filepath.IsAbs(fullPath) {
+		fullPath = filepath.Join(dir, path)
 	}
-	g.sourceMap.Add(n.Expression, r)
-	// {
-	if _, err = g.w.Write(` {` + "\n"); err != nil {
-		return err
+	contents, err := os.ReadFile(fullPath)
+	if err != nil {
+		return path, fullPath, nil, fmt.Errorf("include %s: %w", n.Path.Value, err)
 	}
+	if nodes, err = parser.ParseNodes(string(contents)); err != nil {
+		return path, fullPath, nil, fmt.Errorf("include %s: %w", n.Path.Value, err)
+	}
+	return path, fullPath, nodes, nil
+}
 
-	if len(n.Cases) > 0 {
-		for _, c := range n.Cases {
-			// case x:
-			// default:
-			if r, err = g.w.WriteIndent(indentLevel, c.Expression.Value); err != nil {
-				return err
-			}
-			g.sourceMap.Add(c.Expression, r)
-			indentLevel++
-			if err = g.writeNodes(indentLevel, stripLeadingAndTrailingWhitespace(c.Children), next); err != nil {
-				return err
-			}
-			indentLevel--
-		}
+func (g *generator) writeIncludeExpression(indentLevel int, n parser.IncludeExpression, next parser.Node) (err error) {
+	path, fullPath, nodes, err := readInclude(g.includeDir, n)
+	// Report the file even if it can't be read, since creating it fixes the
+	// template file.
+	if g.onInclude != nil && fullPath != "" {
+		g.onInclude(fullPath)
 	}
-	// }
-	if _, err = g.w.WriteIndent(indentLevel, `}`+"\n"); err != nil {
+	if err != nil {
 		return err
 	}
-	return nil
+	for _, included := range g.includeStack {
+		if included == fullPath {
+			return fmt.Errorf("include %s: file includes itself", n.Path.Value)
+		}
+	}
+
+	// The included nodes have positions within the included file, so they
+	// can't be mapped back to the current file.
+	prevSourceMap, prevFileName, prevIncludeDir := g.sourceMap, g.fileName, g.includeDir
+	defer func() {
+		g.sourceMap, g.fileName, g.includeDir = prevSourceMap, prevFileName, prevIncludeDir
+		g.includeStack = g.includeStack[:len(g.includeStack)-1]
+	}()
+	g.sourceMap = parser.NewSourceMap()
+	if !filepath.IsAbs(path) {
+		g.fileName = filepath.ToSlash(filepath.Join(filepath.Dir(g.fileName), path))
+	} else {
+		g.fileName = filepath.Base(path)
+	}
+	g.includeDir = filepath.Dir(fullPath)
+	g.includeStack = append(g.includeStack, fullPath)
+
+	return g.writeNodes(indentLevel, stripLeadingAndTrailingWhitespace(nodes), next)
 }
 
-func (g *generator) writeChildrenExpression(indentLevel int) (err error) {
-	if _, err = g.w.WriteIndent(indentLevel, fmt.Sprintf("templ_7745c5c3_Err = %s.Render(ctx, templ_7745c5c3_Buffer)\n", g.childrenVar)); err != nil {
+func (g *generator) writeLabel(indentLevel int, label parser.Expression) (err error) {
+	if label.Value == "" {
+		return nil
+	}
+	var r parser.Range
+	if r, err = g.w.WriteIndent(indentLevel, label.Value); err != nil {
 		return err
 	}
-	if err = g.writeErrorHandler(indentLevel); err != nil {
+	g.sourceMap.Add(label, r)
+	if _, err = g.w.Write(":\n"); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (g *generator) writeTemplElementExpression(indentLevel int, n parser.TemplElementExpression) (err error) {
-	if len(n.Children) == 0 {
-		return g.writeSelfClosingTemplElementExpression(indentLevel, n)
+func (g *generator) writeForExpression(indentLevel int, n parser.ForExpression, next parser.Node) (err error) {
+	// The else nodes are rendered if the body of the loop isn't, which is
+	// tracked with a variable that's set by the body.
+	var ranVar string
+	if len(n.Else) > 0 {
+		ranVar = g.createVariableName()
+		// templ_7745c5c3_Var2 := false
+		if _, err = g.w.WriteIndent(indentLevel, ranVar+" := false\n"); err != nil {
+			return err
+		}
 	}
-	return g.writeBlockTemplElementExpression(indentLevel, n)
-}
-
-func (g *generator) writeBlockTemplElementExpression(indentLevel int, n parser.TemplElementExpression) (err error) {
-	var r parser.Range
-	childrenName := g.createVariableName()
-	if _, err = g.w.WriteIndent(indentLevel, childrenName+" := templ.ComponentFunc(func(ctx context.Context, templ_7745c5c3_W io.Writer) (templ_7745c5c3_Err error) {\n"); err != nil {
-		return err
+	if sr, ok := parseSortedRange(n.Expression); ok {
+		err = g.writeSortedForExpression(indentLevel, n, sr, ranVar, next)
+	} else {
+		err = g.writeRangeForExpression(indentLevel, n, ranVar, next)
 	}
-	indentLevel++
-	if err := g.writeTemplBuffer(indentLevel); err != nil {
+	if err != nil || ranVar == "" {
 		return err
 	}
-	if err = g.writeNodes(indentLevel, stripLeadingAndTrailingWhitespace(n.Children), nil); err != nil {
+	// if !templ_7745c5c3_Var2 {
+	if _, err = g.w.WriteIndent(indentLevel, "if !"+ranVar+" {\n"); err != nil {
 		return err
 	}
-	// Return the buffer.
-	if _, err = g.w.WriteIndent(indentLevel, "if !templ_7745c5c3_IsBuffer {\n"); err != nil {
+	if err = g.writeNodes(indentLevel+1, stripLeadingAndTrailingWhitespace(n.Else), next); err != nil {
 		return err
 	}
-	{
-		indentLevel++
-		// _, templ_7745c5c3_Err = io.Copy(templ_7745c5c3_W, templ_7745c5c3_Buffer)
-		if _, err = g.w.WriteIndent(indentLevel, "_, templ_7745c5c3_Err = io.Copy(templ_7745c5c3_W, templ_7745c5c3_Buffer)\n"); err != nil {
-			return err
-		}
-		indentLevel--
-	}
+	// }
 	if _, err = g.w.WriteIndent(indentLevel, "}\n"); err != nil {
 		return err
 	}
-	// return nil
-	if _, err = g.w.WriteIndent(indentLevel, "return templ_7745c5c3_Err\n"); err != nil {
-		return err
+	return nil
+}
+
+// writeForChildren writes the body of a for loop, which sets ranVar, if the
+// loop has else nodes.
+func (g *generator) writeForChildren(indentLevel int, n parser.ForExpression, ranVar string, next parser.Node) (err error) {
+	if ranVar != "" {
+		// templ_7745c5c3_Var2 = true
+		if _, err = g.w.WriteIndent(indentLevel, ranVar+" = true\n"); err != nil {
+			return err
+		}
 	}
-	indentLevel--
-	if _, err = g.w.WriteIndent(indentLevel, "})\n"); err != nil {
+	return g.writeNodes(indentLevel, stripLeadingAndTrailingWhitespace(n.Children), next)
+}
+
+func (g *generator) writeRangeForExpression(indentLevel int, n parser.ForExpression, ranVar string, next parser.Node) (err error) {
+	var r parser.Range
+	// outer:
+	if err = g.writeLabel(indentLevel, n.Label); err != nil {
 		return err
 	}
-	if _, err = g.w.WriteIndent(indentLevel, `templ_7745c5c3_Err = `); err != nil {
+	// for
+	if _, err = g.w.WriteIndent(indentLevel, `for `); err != nil {
 		return err
 	}
+	// i, v := range p.Stuff
 	if r, err = g.w.Write(n.Expression.Value); err != nil {
 		return err
 	}
 	g.sourceMap.Add(n.Expression, r)
-	// .Render(templ.WithChildren(ctx, children), templ_7745c5c3_Buffer)
-	if _, err = g.w.Write(".Render(templ.WithChildren(ctx, " + childrenName + "), templ_7745c5c3_Buffer)\n"); err != nil {
+	// {
+	if _, err = g.w.Write(` {` + "\n"); err != nil {
 		return err
 	}
-	if err = g.writeErrorHandler(indentLevel); err != nil {
+	// Children.
+	indentLevel++
+	if err = g.writeForChildren(indentLevel, n, ranVar, next); err != nil {
+		return err
+	}
+	indentLevel--
+	// }
+	if _, err = g.w.WriteIndent(indentLevel, `}`+"\n"); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (g *generator) writeSelfClosingTemplElementExpression(indentLevel int, n parser.TemplElementExpression) (err error) {
-	if _, err = g.w.WriteIndent(indentLevel, `templ_7745c5c3_Err = `); err != nil {
-		return err
+// sortedRange is a for expression in the form `k, v := range templ.Sorted(m)`.
+type sortedRange struct {
+	// Key and Value are the loop variables, either may be empty.
+	Key, Value parser.Expression
+	// Define is true if the loop variables are declared with `:=`.
+	Define bool
+	// Map is the expression passed to templ.Sorted.
+	Map parser.Expression
+}
+
+// parseSortedRange checks whether the for expression ranges over a call to
+// templ.Sorted, e.g. `for k, v := range templ.Sorted(m)`.
+func parseSortedRange(e parser.Expression) (sr sortedRange, ok bool) {
+	if !strings.Contains(e.Value, "templ.Sorted(") {
+		return sr, false
 	}
-	// Template expression.
-	var r parser.Range
-	if r, err = g.w.Write(n.Expression.Value); err != nil {
-		return err
+	const prefix = "package main\nfunc templ_container() {\nfor "
+	src := prefix + e.Value + " {}\n}"
+	f, err := goparser.ParseFile(token.NewFileSet(), "", src, 0)
+	if err != nil {
+		return sr, false
 	}
-	g.sourceMap.Add(n.Expression, r)
-	// .Render(ctx, templ_7745c5c3_Buffer)
-	if _, err = g.w.Write(".Render(ctx, templ_7745c5c3_Buffer)\n"); err != nil {
-		return err
+	fn, ok := f.Decls[0].(*ast.FuncDecl)
+	if !ok || len(fn.Body.List) != 1 {
+		return sr, false
 	}
-	if err = g.writeErrorHandler(indentLevel); err != nil {
-		return err
+	rs, ok := fn.Body.List[0].(*ast.RangeStmt)
+	if !ok {
+		return sr, false
 	}
-	return nil
-}
-
-func (g *generator) writeCallTemplateExpression(indentLevel int, n parser.CallTemplateExpression) (err error) {
-	if _, err = g.w.WriteIndent(indentLevel, `templ_7745c5c3_Err = `); err != nil {
-		return err
+	call, ok := rs.X.(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 || call.Ellipsis.IsValid() {
+		return sr, false
 	}
-	// Template expression.
-	var r parser.Range
-	if r, err = g.w.Write(n.Expression.Value); err != nil {
-		return err
+	if sel, ok := call.Fun.(*ast.SelectorExpr); !ok || sel.Sel.Name != "Sorted" {
+		return sr, false
+	} else if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != "templ" {
+		return sr, false
 	}
-	g.sourceMap.Add(n.Expression, r)
-	// .Render(ctx, templ_7745c5c3_Buffer)
-	if _, err = g.w.Write(".Render(ctx, templ_7745c5c3_Buffer)\n"); err != nil {
-		return err
+	// Positions are 1-based offsets into src.
+	offset := func(p token.Pos) int { return int(p) - 1 - len(prefix) }
+	sub := func(n ast.Node) parser.Expression {
+		return subExpression(e, offset(n.Pos()), offset(n.End()))
 	}
-	if err = g.writeErrorHandler(indentLevel); err != nil {
-		return err
+	if rs.Key != nil {
+		sr.Key = sub(rs.Key)
 	}
-	return nil
+	if rs.Value != nil {
+		sr.Value = sub(rs.Value)
+	}
+	sr.Define = rs.Tok == token.DEFINE
+	sr.Map = sub(call.Args[0])
+	return sr, true
 }
 
-func (g *generator) writeForExpression(indentLevel int, n parser.ForExpression, next parser.Node) (err error) {
+// subExpression returns the part of e between the from and to byte offsets,
+// with the range adjusted to match.
+func subExpression(e parser.Expression, from, to int) parser.Expression {
+	positionAt := func(offset int) parser.Position {
+		p := e.Range.From
+		for _, r := range e.Value[:offset] {
+			if r == '\n' {
+				p.Line++
+				p.Col = 0
+				continue
+			}
+			p.Col += uint32(utf8.RuneLen(r))
+		}
+		p.Index += int64(offset)
+		return p
+	}
+	return parser.Expression{
+		Value: e.Value[from:to],
+		Range: parser.Range{
+			From: positionAt(from),
+			To:   positionAt(to),
+		},
+	}
+}
+
+func (g *generator) writeSortedForExpression(indentLevel int, n parser.ForExpression, sr sortedRange, ranVar string, next parser.Node) (err error) {
 	var r parser.Range
-	// for
-	if _, err = g.w.WriteIndent(indentLevel, `for `); err != nil {
+	kvName := g.createVariableName()
+	hasVars := sr.Key.Value != "" || sr.Value.Value != ""
+	// outer:
+	if err = g.writeLabel(indentLevel, n.Label); err != nil {
 		return err
 	}
-	// i, v := range p.Stuff
-	if r, err = g.w.Write(n.Expression.Value); err != nil {
+	// for _, templ_7745c5c3_Var1 := range templ.SortedMap(
+	if hasVars {
+		if _, err = g.w.WriteIndent(indentLevel, "for _, "+kvName+" := range templ.SortedMap("); err != nil {
+			return err
+		}
+	} else {
+		if _, err = g.w.WriteIndent(indentLevel, "for range templ.SortedMap("); err != nil {
+			return err
+		}
+	}
+	// m
+	if r, err = g.w.Write(sr.Map.Value); err != nil {
 		return err
 	}
-	g.sourceMap.Add(n.Expression, r)
-	// {
-	if _, err = g.w.Write(` {` + "\n"); err != nil {
+	g.sourceMap.Add(sr.Map, r)
+	// ) {
+	if _, err = g.w.Write(") {\n"); err != nil {
 		return err
 	}
+	indentLevel++
+	if hasVars {
+		// k, v := templ_7745c5c3_Var1.Key, templ_7745c5c3_Var1.Value
+		if _, err = g.w.WriteIndent(indentLevel, ""); err != nil {
+			return err
+		}
+		var values []string
+		if sr.Key.Value != "" {
+			if r, err = g.w.Write(sr.Key.Value); err != nil {
+				return err
+			}
+			g.sourceMap.Add(sr.Key, r)
+			values = append(values, kvName+".Key")
+		}
+		if sr.Value.Value != "" {
+			if _, err = g.w.Write(", "); err != nil {
+				return err
+			}
+			if r, err = g.w.Write(sr.Value.Value); err != nil {
+				return err
+			}
+			g.sourceMap.Add(sr.Value, r)
+			values = append(values, kvName+".Value")
+		}
+		op := " = "
+		if sr.Define {
+			op = " := "
+		}
+		if _, err = g.w.Write(op + strings.Join(values, ", ") + "\n"); err != nil {
+			return err
+		}
+	}
 	// Children.
-	indentLevel++
-	if err = g.writeNodes(indentLevel, stripLeadingAndTrailingWhitespace(n.Children), next); err != nil {
+	if err = g.writeForChildren(indentLevel, n, ranVar, next); err != nil {
 		return err
 	}
 	indentLevel--
@@ -882,9 +2654,18 @@ func (g *generator) writeExpressionErrorHandler(indentLevel int, expression pars
 }
 
 func (g *generator) writeElement(indentLevel int, n parser.Element) (err error) {
+	if n.Attributes, err = g.addIntegrity(n.Name, n.Attributes); err != nil {
+		return err
+	}
+	// XML elements without children are self-closing, e.g. <link href="/"/>.
+	selfClosing := g.xml && len(stripWhitespace(n.Children)) == 0
+	openTagEnd := ">"
+	if selfClosing {
+		openTagEnd = "/>"
+	}
 	if len(n.Attributes) == 0 {
 		// <div>
-		if _, err = g.w.WriteStringLiteral(indentLevel, fmt.Sprintf(`<%s>`, html.EscapeString(n.Name))); err != nil {
+		if _, err = g.w.WriteStringLiteral(indentLevel, fmt.Sprintf(`<%s%s`, html.EscapeString(n.Name), openTagEnd)); err != nil {
 			return err
 		}
 	} else {
@@ -904,12 +2685,15 @@ func (g *generator) writeElement(indentLevel int, n parser.Element) (err error)
 			return err
 		}
 		// >
-		if _, err = g.w.WriteStringLiteral(indentLevel, `>`); err != nil {
+		if _, err = g.w.WriteStringLiteral(indentLevel, openTagEnd); err != nil {
 			return err
 		}
 	}
+	if selfClosing {
+		return nil
+	}
 	// Skip children and close tag for void elements.
-	if n.IsVoidElement() && len(n.Children) == 0 {
+	if !g.xml && n.IsVoidElement() && len(n.Children) == 0 {
 		return nil
 	}
 	// Children.
@@ -924,7 +2708,6 @@ func (g *generator) writeElement(indentLevel int, n parser.Element) (err error)
 }
 
 func (g *generator) writeAttributeCSS(indentLevel int, attr parser.ExpressionAttribute) (result parser.ExpressionAttribute, ok bool, err error) {
-	var r parser.Range
 	name := html.EscapeString(attr.Name)
 	if name != "class" {
 		ok = false
@@ -937,11 +2720,10 @@ func (g *generator) writeAttributeCSS(indentLevel int, attr parser.ExpressionAtt
 	if _, err = g.w.WriteIndent(indentLevel, "var "+classesName+" = []any{"); err != nil {
 		return
 	}
-	// p.Name()
-	if r, err = g.w.Write(attr.Expression.Value); err != nil {
+	// "btn", templ.KV("btn-primary", isPrimary)
+	if err = g.writeClassArgs(attr.Expression); err != nil {
 		return
 	}
-	g.sourceMap.Add(attr.Expression, r)
 	// }\n
 	if _, err = g.w.Write("}\n"); err != nil {
 		return
@@ -958,9 +2740,68 @@ func (g *generator) writeAttributeCSS(indentLevel int, attr parser.ExpressionAtt
 	attr.Expression = parser.Expression{
 		Value: "templ.CSSClasses(" + classesName + ").String()",
 	}
+	if len(g.cssScope.Classes) > 0 {
+		// templ.ScopeCSSClasses(templ.CSSClasses(templ_7745c5c3_CSSClassess).String(), "_1a2b3c4d", "button")
+		args := []string{attr.Expression.Value, strconv.Quote(g.cssScope.Suffix)}
+		for _, c := range g.cssScope.Classes {
+			args = append(args, strconv.Quote(c))
+		}
+		attr.Expression.Value = "templ.ScopeCSSClasses(" + strings.Join(args, ", ") + ")"
+	}
 	return attr, true, nil
 }
 
+// writeClassArgs writes the arguments of a class attribute expression, lowering
+// each "name": condition pair to templ.KV("name", condition).
+func (g *generator) writeClassArgs(e parser.Expression) (err error) {
+	kvs, err := goexpression.KeyValueArgs(e.Value)
+	if err != nil || len(kvs) == 0 {
+		var r parser.Range
+		if r, err = g.w.Write(e.Value); err != nil {
+			return err
+		}
+		g.sourceMap.Add(e, r)
+		return nil
+	}
+	write := func(from, to int) error {
+		if from == to {
+			return nil
+		}
+		part := subExpression(e, from, to)
+		r, err := g.w.Write(part.Value)
+		if err != nil {
+			return err
+		}
+		g.sourceMap.Add(part, r)
+		return nil
+	}
+	var from int
+	for _, kv := range kvs {
+		// "btn",
+		if err = write(from, kv.KeyIndex); err != nil {
+			return err
+		}
+		// templ.KV("btn-primary", isPrimary)
+		if _, err = g.w.Write("templ.KV("); err != nil {
+			return err
+		}
+		if err = write(kv.KeyIndex, kv.KeyIndex+len(kv.Key)); err != nil {
+			return err
+		}
+		if _, err = g.w.Write(", "); err != nil {
+			return err
+		}
+		if err = write(kv.ValueIndex, kv.ValueIndex+len(kv.Value)); err != nil {
+			return err
+		}
+		if _, err = g.w.Write(")"); err != nil {
+			return err
+		}
+		from = kv.ValueIndex + len(kv.Value)
+	}
+	return write(from, len(e.Value))
+}
+
 func (g *generator) writeAttributesCSS(indentLevel int, attrs []parser.Attribute) (err error) {
 	for i := 0; i < len(attrs); i++ {
 		if attr, ok := attrs[i].(parser.ExpressionAttribute); ok {
@@ -1046,6 +2887,9 @@ func (g *generator) writeBoolConstantAttribute(indentLevel int, attr parser.Bool
 }
 
 func (g *generator) writeConstantAttribute(indentLevel int, attr parser.ConstantAttribute) (err error) {
+	if strings.EqualFold(attr.Name, "class") {
+		attr.Value = g.cssScope.Class(attr.Value)
+	}
 	name := html.EscapeString(attr.Name)
 	value := html.EscapeString(attr.Value)
 	value = strconv.Quote(value)
@@ -1086,6 +2930,38 @@ func (g *generator) writeBoolExpressionAttribute(indentLevel int, attr parser.Bo
 	return nil
 }
 
+// writeStyleAttributeValue writes the value of a style attribute, whose
+// declarations are sanitized, e.g. style={ map[string]any{"width": templ.Px(10)} }.
+func (g *generator) writeStyleAttributeValue(indentLevel int, e parser.Expression) (err error) {
+	vn := g.createVariableName()
+	// var vn string
+	if _, err = g.w.WriteIndent(indentLevel, "var "+vn+" string\n"); err != nil {
+		return err
+	}
+	// vn, templ_7745c5c3_Err = templ.SanitizeStyleAttributeValues(
+	if _, err = g.w.WriteIndent(indentLevel, vn+", templ_7745c5c3_Err = templ.SanitizeStyleAttributeValues("); err != nil {
+		return err
+	}
+	// p.Name()
+	var r parser.Range
+	if r, err = g.w.Write(e.Value); err != nil {
+		return err
+	}
+	g.sourceMap.Add(e, r)
+	// )
+	if _, err = g.w.Write(")\n"); err != nil {
+		return err
+	}
+	if err = g.writeExpressionErrorHandler(indentLevel, e); err != nil {
+		return err
+	}
+	// _, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(vn))
+	if _, err = g.w.WriteIndent(indentLevel, "_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString("+vn+"))\n"); err != nil {
+		return err
+	}
+	return g.writeErrorHandler(indentLevel)
+}
+
 func (g *generator) writeExpressionAttribute(indentLevel int, elementName string, attr parser.ExpressionAttribute) (err error) {
 	attrName := html.EscapeString(attr.Name)
 	// Name
@@ -1118,6 +2994,10 @@ func (g *generator) writeExpressionAttribute(indentLevel int, elementName string
 		if err = g.writeErrorHandler(indentLevel); err != nil {
 			return err
 		}
+	} else if strings.EqualFold(attr.Name, "style") {
+		if err = g.writeStyleAttributeValue(indentLevel, attr.Expression); err != nil {
+			return err
+		}
 	} else {
 		if isScriptAttribute(attr.Name) {
 			// It's a JavaScript handler, and requires special handling, because we expect a JavaScript expression.
@@ -1183,9 +3063,72 @@ func (g *generator) writeExpressionAttribute(indentLevel int, elementName string
 	return nil
 }
 
+func (g *generator) writeInterpolatedAttribute(indentLevel int, attr parser.InterpolatedAttribute) (err error) {
+	// Name and open quote.
+	if _, err = g.w.WriteStringLiteral(indentLevel, fmt.Sprintf(` %s=\"`, html.EscapeString(attr.Name))); err != nil {
+		return err
+	}
+	for i, part := range attr.Parts {
+		if part.Expression == nil {
+			value := strconv.Quote(html.EscapeString(part.Value))
+			if _, err = g.w.WriteStringLiteral(indentLevel, value[1:len(value)-1]); err != nil {
+				return err
+			}
+			continue
+		}
+		vn := g.createVariableName()
+		// var vn string
+		if _, err = g.w.WriteIndent(indentLevel, "var "+vn+" string\n"); err != nil {
+			return err
+		}
+		// vn, templ_7745c5c3_Err = templ.JoinStringErrs(
+		if _, err = g.w.WriteIndent(indentLevel, vn+", templ_7745c5c3_Err = templ.JoinStringErrs("); err != nil {
+			return err
+		}
+		// p.Name()
+		var r parser.Range
+		if r, err = g.w.Write(part.Expression.Value); err != nil {
+			return err
+		}
+		g.sourceMap.Add(*part.Expression, r)
+		// )
+		if _, err = g.w.Write(")\n"); err != nil {
+			return err
+		}
+		if err = g.writeExpressionErrorHandler(indentLevel, *part.Expression); err != nil {
+			return err
+		}
+		// Within URLs, the start of the URL is sanitized, and later parts are
+		// escaped, so that they can't change the structure of the URL.
+		value := vn
+		if parser.IsURLAttribute(attr.Name) {
+			switch {
+			case i == 0:
+				value = "string(templ.URL(" + vn + "))"
+			case attr.IsURLQuery(i):
+				value = "templ.URLQueryValue(" + vn + ")"
+			default:
+				value = "templ.URLPathSegment(" + vn + ")"
+			}
+		}
+		// _, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(vn))
+		if _, err = g.w.WriteIndent(indentLevel, "_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString("+value+"))\n"); err != nil {
+			return err
+		}
+		if err = g.writeErrorHandler(indentLevel); err != nil {
+			return err
+		}
+	}
+	// Close quote.
+	if _, err = g.w.WriteStringLiteral(indentLevel, `\"`); err != nil {
+		return err
+	}
+	return nil
+}
+
 func (g *generator) writeSpreadAttributes(indentLevel int, attr parser.SpreadAttributes) (err error) {
-	// templ.RenderAttributes(ctx, w, spreadAttrs)
-	if _, err = g.w.WriteIndent(indentLevel, `templ_7745c5c3_Err = templ.RenderAttributes(ctx, templ_7745c5c3_Buffer, `); err != nil {
+	// templ.RenderSpreadAttributes(ctx, w, spreadAttrs)
+	if _, err = g.w.WriteIndent(indentLevel, `templ_7745c5c3_Err = templ.RenderSpreadAttributes(ctx, templ_7745c5c3_Buffer, `); err != nil {
 		return err
 	}
 	// spreadAttrs
@@ -1204,6 +3147,32 @@ func (g *generator) writeSpreadAttributes(indentLevel int, attr parser.SpreadAtt
 	return nil
 }
 
+func (g *generator) writeDynamicAttribute(indentLevel int, attr parser.DynamicAttribute) (err error) {
+	// templ.RenderAttribute(ctx, w, name, value)
+	if _, err = g.w.WriteIndent(indentLevel, `templ_7745c5c3_Err = templ.RenderAttribute(ctx, templ_7745c5c3_Buffer, `); err != nil {
+		return err
+	}
+	// name
+	var r parser.Range
+	if r, err = g.w.Write(attr.Name.Value); err != nil {
+		return err
+	}
+	g.sourceMap.Add(attr.Name, r)
+	// , value
+	if _, err = g.w.Write(", "); err != nil {
+		return err
+	}
+	if r, err = g.w.Write(attr.Value.Value); err != nil {
+		return err
+	}
+	g.sourceMap.Add(attr.Value, r)
+	// )
+	if _, err = g.w.Write(")\n"); err != nil {
+		return err
+	}
+	return g.writeErrorHandler(indentLevel)
+}
+
 func (g *generator) writeConditionalAttribute(indentLevel int, elementName string, attr parser.ConditionalAttribute) (err error) {
 	// if
 	if _, err = g.w.WriteIndent(indentLevel, `if `); err != nil {
@@ -1257,8 +3226,12 @@ func (g *generator) writeElementAttributes(indentLevel int, name string, attrs [
 			err = g.writeBoolExpressionAttribute(indentLevel, attr)
 		case parser.ExpressionAttribute:
 			err = g.writeExpressionAttribute(indentLevel, name, attr)
+		case parser.InterpolatedAttribute:
+			err = g.writeInterpolatedAttribute(indentLevel, attr)
 		case parser.SpreadAttributes:
 			err = g.writeSpreadAttributes(indentLevel, attr)
+		case parser.DynamicAttribute:
+			err = g.writeDynamicAttribute(indentLevel, attr)
 		case parser.ConditionalAttribute:
 			err = g.writeConditionalAttribute(indentLevel, name, attr)
 		default:
@@ -1269,7 +3242,44 @@ func (g *generator) writeElementAttributes(indentLevel int, name string, attrs [
 }
 
 func (g *generator) writeRawElement(indentLevel int, n parser.RawElement) (err error) {
-	if len(n.Attributes) == 0 {
+	n = g.cssScope.Style(n)
+	if n.IsTypeScript() {
+		if n.Contents, err = typescript.Transpile(n.Contents); err != nil {
+			return fmt.Errorf("<%s>: failed to transpile TypeScript: %w", n.Name, err)
+		}
+		n.Attributes = n.JavaScriptAttributes()
+	}
+	if g.minifyJS && n.IsJavaScript() {
+		if n, err = n.TransformContents(javascript.Minify); err != nil {
+			return fmt.Errorf("<%s>: failed to minify JavaScript: %w", n.Name, err)
+		}
+	}
+	if lang := n.StyleLang(); lang != "" && g.preprocessStyle != nil {
+		if n, err = n.TransformContents(func(src string) (string, error) { return g.preprocessStyle(lang, src) }); err != nil {
+			return fmt.Errorf("<%s lang=%q>: failed to preprocess style: %w", n.Name, lang, err)
+		}
+		n.Attributes = n.CSSAttributes()
+	}
+	if g.minifyCSS && n.IsCSS() {
+		if n, err = n.TransformContents(css.Minify); err != nil {
+			return fmt.Errorf("<%s>: failed to minify CSS: %w", n.Name, err)
+		}
+	}
+	if g.extractScript != nil && n.IsJavaScript() && len(n.Expressions) == 0 &&
+		!parser.HasAttribute(n.Attributes, "src") && strings.TrimSpace(n.Contents) != "" {
+		src, err := g.extractScript(n.Contents)
+		if err != nil {
+			return fmt.Errorf("<%s>: failed to extract script: %w", n.Name, err)
+		}
+		n.Attributes = append(slices.Clone(n.Attributes), parser.ConstantAttribute{Name: "src", Value: src})
+		n.Contents = ""
+	}
+	if n.Attributes, err = g.addIntegrity(n.Name, n.Attributes); err != nil {
+		return err
+	}
+	// Script and style elements have the CSP nonce of the context, if any.
+	addNonce := (strings.EqualFold(n.Name, "script") || strings.EqualFold(n.Name, "style")) && !parser.HasAttribute(n.Attributes, "nonce")
+	if len(n.Attributes) == 0 && !addNonce {
 		// <div>
 		if _, err = g.w.WriteStringLiteral(indentLevel, fmt.Sprintf(`<%s>`, html.EscapeString(n.Name))); err != nil {
 			return err
@@ -1286,14 +3296,30 @@ func (g *generator) writeRawElement(indentLevel int, n parser.RawElement) (err e
 		if err = g.writeElementAttributes(indentLevel, n.Name, n.Attributes); err != nil {
 			return err
 		}
+		if addNonce {
+			// _, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.NonceAttribute(ctx))
+			if _, err = g.w.WriteIndent(indentLevel, "_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.NonceAttribute(ctx))\n"); err != nil {
+				return err
+			}
+			if err = g.writeErrorHandler(indentLevel); err != nil {
+				return err
+			}
+		}
 		// >
 		if _, err = g.w.WriteStringLiteral(indentLevel, `>`); err != nil {
 			return err
 		}
 	}
 	// Contents.
-	if err = g.writeText(indentLevel, parser.Text{Value: n.Contents}); err != nil {
-		return err
+	for _, c := range n.SplitContents() {
+		if c.Expression != nil {
+			err = g.writeScriptExpression(indentLevel, n, c)
+		} else {
+			err = g.writeText(indentLevel, parser.Text{Value: c.Value})
+		}
+		if err != nil {
+			return err
+		}
 	}
 	// </div>
 	if _, err = g.w.WriteStringLiteral(indentLevel, fmt.Sprintf(`</%s>`, html.EscapeString(n.Name))); err != nil {
@@ -1302,6 +3328,129 @@ func (g *generator) writeRawElement(indentLevel int, n parser.RawElement) (err e
 	return err
 }
 
+// writeScriptExpression writes a Go expression within a JavaScript template
+// literal, e.g. `Hello, {{ name }}`, the expression that is the contents of
+// a JSON script element, which is encoded as JSON, an expression within a
+// style element, which is escaped for the CSS string or value it's within, or
+// a string expression within a textarea or title element, which is HTML
+// escaped.
+func (g *generator) writeScriptExpression(indentLevel int, n parser.RawElement, c parser.ScriptContents) (err error) {
+	e := *c.Expression
+	encode, escape := "templ.JoinStringErrs", "templ.EscapeJSTemplateLiteral"
+	// escapeArgs are written before the value in the call to escape.
+	var escapeArgs string
+	switch {
+	case n.IsJSON():
+		// JSON encoding escapes <, > and &, so the value can't close the
+		// script element.
+		encode, escape = "templ.JSONString", ""
+	case n.IsCSS() && c.InString:
+		escape = "templ.EscapeCSSString"
+	case n.IsCSS() && c.Property != "":
+		escape, escapeArgs = "templ.EscapeCSSPropertyValue", createGoString(c.Property)+", "
+	case n.IsCSS():
+		escape = "templ.EscapeCSSValue"
+	case n.IsEscapableRawText():
+		escape = "templ.EscapeString"
+	}
+	if escape != "" {
+		escape += "(" + escapeArgs
+	}
+	return g.writeEncodedExpression(indentLevel, e, encode, escape)
+}
+
+// writeEncodedExpression writes the value of a Go expression, converted to a
+// string by the encode function, and escaped by the escape call, which is
+// the start of a call that the string is the last argument of, e.g.
+// "templ.EscapeCSSPropertyValue(`color`, ". If escape is empty, the string
+// is written as it is.
+func (g *generator) writeEncodedExpression(indentLevel int, e parser.Expression, encode, escape string) (err error) {
+	var r parser.Range
+	vn := g.createVariableName()
+	// var vn string
+	if _, err = g.w.WriteIndent(indentLevel, "var "+vn+" string\n"); err != nil {
+		return err
+	}
+	// vn, templ_7745c5c3_Err = templ.JoinStringErrs(
+	if _, err = g.w.WriteIndent(indentLevel, vn+", templ_7745c5c3_Err = "+encode+"("); err != nil {
+		return err
+	}
+	// name
+	if r, err = g.w.Write(e.Value); err != nil {
+		return err
+	}
+	g.sourceMap.Add(e, r)
+	// )
+	if _, err = g.w.Write(")\n"); err != nil {
+		return err
+	}
+	if err = g.writeExpressionErrorHandler(indentLevel, e); err != nil {
+		return err
+	}
+	value := vn
+	if escape != "" {
+		value = escape + vn + ")"
+	}
+	// _, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeJSTemplateLiteral(vn))
+	if _, err = g.w.WriteIndent(indentLevel, "_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("+value+")\n"); err != nil {
+		return err
+	}
+	return g.writeErrorHandler(indentLevel)
+}
+
+// addIntegrity returns the attributes, with an integrity attribute added if
+// the element is a script or stylesheet whose URL is the path of a file within
+// the integrity directory, and it doesn't already have an integrity attribute.
+func (g *generator) addIntegrity(elementName string, attrs []parser.Attribute) ([]parser.Attribute, error) {
+	if g.integrityDir == "" {
+		return attrs, nil
+	}
+	var urlAttr string
+	switch strings.ToLower(elementName) {
+	case "script":
+		urlAttr = "src"
+	case "link":
+		rel, _ := constantAttributeValue(attrs, "rel")
+		if !slices.Contains(strings.Fields(strings.ToLower(rel)), "stylesheet") {
+			return attrs, nil
+		}
+		urlAttr = "href"
+	default:
+		return attrs, nil
+	}
+	if parser.HasAttribute(attrs, "integrity") {
+		return attrs, nil
+	}
+	u, ok := constantAttributeValue(attrs, urlAttr)
+	if !ok || u == "" || strings.Contains(u, ":") || strings.HasPrefix(u, "//") {
+		// The file isn't local, e.g. https://example.com/app.js.
+		return attrs, nil
+	}
+	if i := strings.IndexAny(u, "?#"); i >= 0 {
+		u = u[:i]
+	}
+	fileName := filepath.Join(g.integrityDir, filepath.FromSlash(strings.TrimPrefix(u, "/")))
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return attrs, fmt.Errorf("<%s>: failed to compute integrity attribute: %w", elementName, err)
+	}
+	hash := sha512.Sum384(data)
+	return append(slices.Clone(attrs), parser.ConstantAttribute{
+		Name:  "integrity",
+		Value: "sha384-" + base64.StdEncoding.EncodeToString(hash[:]),
+	}), nil
+}
+
+// constantAttributeValue returns the value of the named constant attribute.
+func constantAttributeValue(attrs []parser.Attribute, name string) (value string, ok bool) {
+	for _, attr := range attrs {
+		if ca, isConstant := attr.(parser.ConstantAttribute); isConstant && strings.EqualFold(ca.Name, name) {
+			return ca.Value, true
+		}
+	}
+	return "", false
+}
+
 func (g *generator) writeComment(indentLevel int, c parser.HTMLComment) (err error) {
 	// <!--
 	if _, err = g.w.WriteStringLiteral(indentLevel, "<!--"); err != nil {
@@ -1318,6 +3467,29 @@ func (g *generator) writeComment(indentLevel int, c parser.HTMLComment) (err err
 	return err
 }
 
+func (g *generator) writeCDATA(indentLevel int, c parser.CDATA) (err error) {
+	// <![CDATA[
+	if _, err = g.w.WriteStringLiteral(indentLevel, "<![CDATA["); err != nil {
+		return err
+	}
+	// Contents.
+	for _, sc := range c.SplitContents() {
+		if sc.Expression != nil {
+			err = g.writeEncodedExpression(indentLevel, *sc.Expression, "templ.JoinStringErrs", "templ.EscapeCDATA(")
+		} else {
+			err = g.writeText(indentLevel, parser.Text{Value: sc.Value})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	// ]]>
+	if _, err = g.w.WriteStringLiteral(indentLevel, "]]>"); err != nil {
+		return err
+	}
+	return err
+}
+
 func (g *generator) createVariableName() string {
 	g.variableID++
 	return "templ_7745c5c3_Var" + strconv.Itoa(g.variableID)
@@ -1345,6 +3517,13 @@ func (g *generator) writeStringExpression(indentLevel int, e parser.Expression)
 	if _, err = g.w.WriteIndent(indentLevel, "var "+vn+" string\n"); err != nil {
 		return err
 	}
+	oc, isOptionalChain, err := parseOptionalChain(e)
+	if err != nil {
+		return err
+	}
+	if isOptionalChain {
+		return g.writeOptionalChainStringExpression(indentLevel, vn, e, oc)
+	}
 	// vn, templ_7745c5c3_Err = templ.JoinStringErrs(
 	if _, err = g.w.WriteIndent(indentLevel, vn+", templ_7745c5c3_Err = templ.JoinStringErrs("); err != nil {
 		return err
@@ -1375,6 +3554,129 @@ func (g *generator) writeStringExpression(indentLevel int, e parser.Expression)
 	return nil
 }
 
+// optionalChain is a string expression that uses the ?. operator to access
+// fields and methods of values that may be nil, e.g. user?.Profile?.Name.
+type optionalChain struct {
+	// Checks are the values that must not be nil, e.g. user, user.Profile.
+	Checks []string
+	// Parts of the expression, with the ? operators removed.
+	Parts []parser.Expression
+}
+
+func parseOptionalChain(e parser.Expression) (oc optionalChain, ok bool, err error) {
+	if !strings.Contains(e.Value, "?.") {
+		return oc, false, nil
+	}
+	var s scanner.Scanner
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(e.Value))
+	s.Init(file, []byte(e.Value), nil, 0)
+	var offsets []int
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		offset := file.Offset(pos)
+		if tok == token.ILLEGAL && lit == "?" && strings.HasPrefix(e.Value[offset+1:], ".") {
+			offsets = append(offsets, offset)
+		}
+	}
+	if len(offsets) == 0 {
+		return oc, false, nil
+	}
+	var value strings.Builder
+	var prev int
+	for _, offset := range offsets {
+		oc.Parts = append(oc.Parts, subExpression(e, prev, offset))
+		value.WriteString(e.Value[prev:offset])
+		oc.Checks = append(oc.Checks, strings.TrimSpace(value.String()))
+		prev = offset + 1
+	}
+	oc.Parts = append(oc.Parts, subExpression(e, prev, len(e.Value)))
+	value.WriteString(e.Value[prev:])
+	// Each ?. operator must select from the chain of fields, methods and
+	// indexes that makes up the whole expression, e.g. a?.b + c?.d isn't
+	// allowed, since a.b + c would be checked.
+	selectors, err := chainSelectorOffsets(value.String())
+	if err != nil {
+		return oc, false, fmt.Errorf("optional chain %q: %w", e.Value, err)
+	}
+	for i, offset := range offsets {
+		// The offsets within the value are reduced by the ? operators removed before them.
+		if !selectors[offset-i] {
+			return oc, false, fmt.Errorf("optional chain %q: the ?. operator can only be used within a single chain of fields, method calls and indexes, e.g. user?.Profile?.Name", e.Value)
+		}
+	}
+	return oc, true, nil
+}
+
+// chainSelectorOffsets returns the end offsets of the values that fields or
+// methods are selected from, along the chain of selectors, calls and indexes
+// of the expression, e.g. for a.b().c[0].d, the offsets of a, a.b() and
+// a.b().c[0].
+func chainSelectorOffsets(src string) (offsets map[int]bool, err error) {
+	fset := token.NewFileSet()
+	x, err := goparser.ParseExprFrom(fset, "", src, 0)
+	if err != nil {
+		return nil, err
+	}
+	offsets = map[int]bool{}
+	for {
+		switch n := x.(type) {
+		case *ast.SelectorExpr:
+			offsets[fset.Position(n.X.End()).Offset] = true
+			x = n.X
+		case *ast.CallExpr:
+			x = n.Fun
+		case *ast.IndexExpr:
+			x = n.X
+		case *ast.IndexListExpr:
+			x = n.X
+		default:
+			return offsets, nil
+		}
+	}
+}
+
+func (g *generator) writeOptionalChainStringExpression(indentLevel int, vn string, e parser.Expression, oc optionalChain) (err error) {
+	var r parser.Range
+	// if user != nil && user.Profile != nil {
+	if _, err = g.w.WriteIndent(indentLevel, "if "+strings.Join(oc.Checks, " != nil && ")+" != nil {\n"); err != nil {
+		return err
+	}
+	{
+		indentLevel++
+		// vn, templ_7745c5c3_Err = templ.JoinStringErrs(
+		if _, err = g.w.WriteIndent(indentLevel, vn+", templ_7745c5c3_Err = templ.JoinStringErrs("); err != nil {
+			return err
+		}
+		// user.Profile.Name
+		for _, part := range oc.Parts {
+			if r, err = g.w.Write(part.Value); err != nil {
+				return err
+			}
+			g.sourceMap.Add(part, r)
+		}
+		// )
+		if _, err = g.w.Write(")\n"); err != nil {
+			return err
+		}
+		if err = g.writeExpressionErrorHandler(indentLevel, e); err != nil {
+			return err
+		}
+		indentLevel--
+	}
+	if _, err = g.w.WriteIndent(indentLevel, "}\n"); err != nil {
+		return err
+	}
+	// _, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(vn)
+	if _, err = g.w.WriteIndent(indentLevel, "_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString("+vn+"))\n"); err != nil {
+		return err
+	}
+	return g.writeErrorHandler(indentLevel)
+}
+
 func (g *generator) writeWhitespace(indentLevel int, n parser.Whitespace) (err error) {
 	if len(n.Value) == 0 {
 		return
@@ -1433,37 +3735,35 @@ func (g *generator) writeScript(t parser.ScriptTemplate) error {
 		return err
 	}
 	indentLevel++
-	// return templ.ComponentScript{
-	if _, err = g.w.WriteIndent(indentLevel, "return templ.ComponentScript{\n"); err != nil {
+	// return templ.NewComponentScript(
+	if _, err = g.w.WriteIndent(indentLevel, "return templ.NewComponentScript(\n"); err != nil {
 		return err
 	}
 	{
 		indentLevel++
 		fn := functionName(t.Name.Value, t.Value)
-		goFn := createGoString(fn)
-		// Name: "scriptName",
-		if _, err = g.w.WriteIndent(indentLevel, "Name: "+goFn+",\n"); err != nil {
+		params := scriptParameterNames(t.Parameters.Value)
+		// "scriptName",
+		if _, err = g.w.WriteIndent(indentLevel, createGoString(fn)+",\n"); err != nil {
 			return err
 		}
-		// Function: `function scriptName(a, b, c){` + `constantScriptValue` + `}`,
-		prefix := "function " + fn + "(" + stripTypes(t.Parameters.Value) + "){"
+		// `function scriptName(a, b, c){` + `constantScriptValue` + `}`,
+		prefix := "function " + fn + "(" + strings.Join(params, ", ") + "){"
 		body := strings.TrimLeftFunc(t.Value, unicode.IsSpace)
 		suffix := "}"
-		if _, err = g.w.WriteIndent(indentLevel, "Function: "+createGoString(prefix+body+suffix)+",\n"); err != nil {
-			return err
-		}
-		// Call: templ.SafeScript(scriptName, a, b, c)
-		if _, err = g.w.WriteIndent(indentLevel, "Call: templ.SafeScript("+goFn+", "+stripTypes(t.Parameters.Value)+"),\n"); err != nil {
+		if _, err = g.w.WriteIndent(indentLevel, createGoString(prefix+body+suffix)+",\n"); err != nil {
 			return err
 		}
-		// CallInline: templ.SafeScriptInline(scriptName, a, b, c)
-		if _, err = g.w.WriteIndent(indentLevel, "CallInline: templ.SafeScriptInline("+goFn+", "+stripTypes(t.Parameters.Value)+"),\n"); err != nil {
-			return err
+		// a, b, c,
+		if len(params) > 0 {
+			if _, err = g.w.WriteIndent(indentLevel, strings.Join(params, ", ")+",\n"); err != nil {
+				return err
+			}
 		}
 		indentLevel--
 	}
-	// }
-	if _, err = g.w.WriteIndent(indentLevel, "}\n"); err != nil {
+	// )
+	if _, err = g.w.WriteIndent(indentLevel, ")\n"); err != nil {
 		return err
 	}
 	indentLevel--
@@ -1481,12 +3781,38 @@ func functionName(name string, body string) string {
 	return "__templ_" + name + "_" + hp
 }
 
-func stripTypes(parameters string) string {
+// scriptParameterNames returns the names of the parameters of a script
+// template, e.g. "a, b string, c int" returns a, b and c.
+func scriptParameterNames(parameters string) (names []string) {
+	if strings.TrimSpace(parameters) == "" {
+		return nil
+	}
+	expr, err := goparser.ParseExpr("func(" + parameters + ")")
+	if err != nil {
+		return stripTypes(parameters)
+	}
+	ft, ok := expr.(*ast.FuncType)
+	if !ok {
+		return stripTypes(parameters)
+	}
+	for _, field := range ft.Params.List {
+		// Unnamed parameters can't be used by the script.
+		if len(field.Names) == 0 {
+			return stripTypes(parameters)
+		}
+		for _, name := range field.Names {
+			names = append(names, name.Name)
+		}
+	}
+	return names
+}
+
+func stripTypes(parameters string) []string {
 	variableNames := []string{}
 	params := strings.Split(parameters, ",")
 	for i := 0; i < len(params); i++ {
 		p := strings.Split(strings.TrimSpace(params[i]), " ")
 		variableNames = append(variableNames, strings.TrimSpace(p[0]))
 	}
-	return strings.Join(variableNames, ", ")
+	return variableNames
 }
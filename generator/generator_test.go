@@ -2,8 +2,14 @@ package generator
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/a-h/templ/cfg"
 	"github.com/a-h/templ/parser/v2"
 	"github.com/google/go-cmp/cmp"
 )
@@ -47,3 +53,1004 @@ templ h1() {
 		t.Fatalf("failed to write Go expression: %v", err)
 	}
 }
+
+func TestGeneratorGoCodeSourceMap(t *testing.T) {
+	flagVal := cfg.Experiment.RawGo
+	cfg.Experiment.RawGo = true
+	defer func() {
+		cfg.Experiment.RawGo = flagVal
+	}()
+
+	tf, err := parser.ParseString(`package main
+
+templ list(items []string) {
+	{{ total := len(items)
+		var names []string
+		for _, item := range items {
+			names = append(names, item)
+		}
+	}}
+	<p>{ total }</p>
+}
+`)
+	if err != nil {
+		t.Fatalf("failed to parse template: %v", err)
+	}
+	w := new(bytes.Buffer)
+	sm, _, err := Generate(tf, w)
+	if err != nil {
+		t.Fatalf("failed to generate: %v", err)
+	}
+	lines := strings.Split(w.String(), "\n")
+	// Each line of the block maps to the same code in the output.
+	for _, src := range []struct {
+		line, col uint32
+		code      string
+	}{
+		{line: 3, col: 4, code: "total := len(items)"},
+		{line: 4, col: 2, code: "var names []string"},
+		{line: 5, col: 2, code: "for _, item := range items {"},
+		{line: 6, col: 3, code: "names = append(names, item)"},
+	} {
+		tgt, ok := sm.TargetPositionFromSource(src.line, src.col)
+		if !ok {
+			t.Errorf("%d:%d: expected a target position", src.line, src.col)
+			continue
+		}
+		if actual := lines[tgt.Line][tgt.Col:]; !strings.HasPrefix(actual, src.code) {
+			t.Errorf("%d:%d: expected target to start with %q, got %q", src.line, src.col, src.code, actual)
+		}
+	}
+}
+
+func TestGeneratorIfInitSourceMap(t *testing.T) {
+	tf, err := parser.ParseString(`package main
+
+templ price(prices map[string]int, name string) {
+	if p, ok := prices[name]; ok {
+		<p>{ p }</p>
+	} else if n := len(prices); n > 0 {
+		<p>{ n } others</p>
+	}
+}
+`)
+	if err != nil {
+		t.Fatalf("failed to parse template: %v", err)
+	}
+	w := new(bytes.Buffer)
+	sm, _, err := Generate(tf, w)
+	if err != nil {
+		t.Fatalf("failed to generate: %v", err)
+	}
+	lines := strings.Split(w.String(), "\n")
+	// The init statements and the variables that they declare map to the same
+	// code in the output.
+	for _, src := range []struct {
+		line, col uint32
+		code      string
+	}{
+		{line: 3, col: 4, code: "p, ok := prices[name]; ok {"},
+		{line: 3, col: 13, code: "prices[name]; ok {"},
+		{line: 3, col: 27, code: "ok {"},
+		{line: 4, col: 7, code: "p)"},
+		{line: 5, col: 11, code: "n := len(prices); n > 0 {"},
+		{line: 6, col: 7, code: "n)"},
+	} {
+		tgt, ok := sm.TargetPositionFromSource(src.line, src.col)
+		if !ok {
+			t.Errorf("%d:%d: expected a target position", src.line, src.col)
+			continue
+		}
+		if actual := lines[tgt.Line][tgt.Col:]; !strings.HasPrefix(actual, src.code) {
+			t.Errorf("%d:%d: expected target to start with %q, got %q", src.line, src.col, src.code, actual)
+		}
+	}
+}
+
+func TestGeneratorDefaultsSourceMap(t *testing.T) {
+	tf, err := parser.ParseString(`package main
+
+templ button(label string, variant string = "primary") {
+	<button class={ variant }>{ label }</button>
+}
+`)
+	if err != nil {
+		t.Fatalf("failed to parse template: %v", err)
+	}
+	w := new(bytes.Buffer)
+	sm, _, err := Generate(tf, w)
+	if err != nil {
+		t.Fatalf("failed to generate: %v", err)
+	}
+	lines := strings.Split(w.String(), "\n")
+	// The required parameters and the default values map to the generated code.
+	for _, src := range []struct {
+		line, col uint32
+		code      string
+	}{
+		{line: 2, col: 13, code: "label string, templ_7745c5c3_Options"},
+		{line: 2, col: 44, code: `"primary"`},
+	} {
+		tgt, ok := sm.TargetPositionFromSource(src.line, src.col)
+		if !ok {
+			t.Errorf("%d:%d: expected a target position", src.line, src.col)
+			continue
+		}
+		if actual := lines[tgt.Line][tgt.Col:]; !strings.HasPrefix(actual, src.code) {
+			t.Errorf("%d:%d: expected target to start with %q, got %q", src.line, src.col, src.code, actual)
+		}
+	}
+}
+
+func TestGeneratorClassConditionsSourceMap(t *testing.T) {
+	tf, err := parser.ParseString(`package main
+
+templ button(isPrimary bool) {
+	<button class={ "btn", "btn-primary": isPrimary }>Save</button>
+}
+`)
+	if err != nil {
+		t.Fatalf("failed to parse template: %v", err)
+	}
+	w := new(bytes.Buffer)
+	sm, _, err := Generate(tf, w)
+	if err != nil {
+		t.Fatalf("failed to generate: %v", err)
+	}
+	lines := strings.Split(w.String(), "\n")
+	// The class names and conditions map to the arguments of templ.KV.
+	for _, src := range []struct {
+		line, col uint32
+		code      string
+	}{
+		{line: 3, col: 17, code: `"btn", templ.KV(`},
+		{line: 3, col: 24, code: `"btn-primary", isPrimary)`},
+		{line: 3, col: 39, code: `isPrimary)`},
+	} {
+		tgt, ok := sm.TargetPositionFromSource(src.line, src.col)
+		if !ok {
+			t.Errorf("%d:%d: expected a target position", src.line, src.col)
+			continue
+		}
+		if actual := lines[tgt.Line][tgt.Col:]; !strings.HasPrefix(actual, src.code) {
+			t.Errorf("%d:%d: expected target to start with %q, got %q", src.line, src.col, src.code, actual)
+		}
+	}
+}
+
+func TestGeneratorRangeOverIntAndFuncSourceMap(t *testing.T) {
+	tf, err := parser.ParseString(`package main
+
+templ list(n int, seq iter.Seq[string]) {
+	for i := range n {
+		<b>{ i }</b>
+	}
+	for v := range seq {
+		<i>{ v }</i>
+	}
+}
+`)
+	if err != nil {
+		t.Fatalf("failed to parse template: %v", err)
+	}
+	w := new(bytes.Buffer)
+	sm, _, err := Generate(tf, w)
+	if err != nil {
+		t.Fatalf("failed to generate: %v", err)
+	}
+	lines := strings.Split(w.String(), "\n")
+	for _, src := range []struct {
+		line, col uint32
+		code      string
+	}{
+		{line: 3, col: 5, code: "i := range n {"},
+		{line: 3, col: 16, code: "n {"},
+		{line: 6, col: 5, code: "v := range seq {"},
+		{line: 6, col: 16, code: "seq {"},
+	} {
+		tgt, ok := sm.TargetPositionFromSource(src.line, src.col)
+		if !ok {
+			t.Errorf("%d:%d: expected a target position", src.line, src.col)
+			continue
+		}
+		if actual := lines[tgt.Line][tgt.Col:]; !strings.HasPrefix(actual, src.code) {
+			t.Errorf("%d:%d: expected target to start with %q, got %q", src.line, src.col, src.code, actual)
+		}
+	}
+}
+
+func TestParseSortedRange(t *testing.T) {
+	expr := parser.Expression{
+		Value: "k, v := range templ.Sorted(p.Items)",
+		Range: parser.Range{
+			From: parser.Position{Index: 10, Line: 2, Col: 5},
+			To:   parser.Position{Index: 45, Line: 2, Col: 40},
+		},
+	}
+	sr, ok := parseSortedRange(expr)
+	if !ok {
+		t.Fatalf("expected sorted range to be found")
+	}
+	expected := sortedRange{
+		Key: parser.Expression{
+			Value: "k",
+			Range: parser.Range{
+				From: parser.Position{Index: 10, Line: 2, Col: 5},
+				To:   parser.Position{Index: 11, Line: 2, Col: 6},
+			},
+		},
+		Value: parser.Expression{
+			Value: "v",
+			Range: parser.Range{
+				From: parser.Position{Index: 13, Line: 2, Col: 8},
+				To:   parser.Position{Index: 14, Line: 2, Col: 9},
+			},
+		},
+		Define: true,
+		Map: parser.Expression{
+			Value: "p.Items",
+			Range: parser.Range{
+				From: parser.Position{Index: 37, Line: 2, Col: 32},
+				To:   parser.Position{Index: 44, Line: 2, Col: 39},
+			},
+		},
+	}
+	if diff := cmp.Diff(expected, sr); diff != "" {
+		t.Error(diff)
+	}
+	for _, value := range []string{"k, v := range items", "k, v := range sorted(m)", "k, v := range other.Sorted(m)", "k, v := range templ.SortedMap(m)", "i := 0; i < 10; i++"} {
+		if _, ok := parseSortedRange(parser.Expression{Value: value}); ok {
+			t.Errorf("%q: expected no sorted range", value)
+		}
+	}
+}
+
+func TestGeneratorDoesNotRewriteOtherFunctionsNamedSorted(t *testing.T) {
+	tf, err := parser.ParseString(`package main
+
+templ list(items []string, sorted func([]string) []string) {
+	for _, item := range sorted(items) {
+		<li>{ item }</li>
+	}
+}
+`)
+	if err != nil {
+		t.Fatalf("failed to parse template: %v", err)
+	}
+	w := new(bytes.Buffer)
+	if _, _, err = Generate(tf, w); err != nil {
+		t.Fatalf("failed to generate: %v", err)
+	}
+	if actual := w.String(); !strings.Contains(actual, "range sorted(items)") || strings.Contains(actual, "SortedMap") {
+		t.Errorf("expected sorted to be left as is, got:\n%s", actual)
+	}
+}
+
+func TestGeneratorRejectsEventHandlerAttributeStructTags(t *testing.T) {
+	tf, err := parser.ParseString("package main\n\ntype ButtonProps struct {\n\tID string `attr:\"id\"`\n\tOnClick string `attr:\"onClick\"`\n}\n")
+	if err != nil {
+		t.Fatalf("failed to parse template: %v", err)
+	}
+	_, _, err = Generate(tf, new(bytes.Buffer))
+	if err == nil || !strings.Contains(err.Error(), `"onClick"`) {
+		t.Errorf("expected an error for the onClick field, got %v", err)
+	}
+}
+
+func TestIncludeCycle(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.templ"), []byte(`@include "b.templ"`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "b.templ"), []byte(`<p>b</p>
+@include "a.templ"`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tf, err := parser.ParseString(`package main
+
+templ page() {
+	@include "a.templ"
+}
+`)
+	if err != nil {
+		t.Fatalf("failed to parse template: %v", err)
+	}
+	_, _, err = Generate(tf, new(bytes.Buffer), WithIncludeDir(dir))
+	if err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+	if !strings.Contains(err.Error(), "file includes itself") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestParseOptionalChain(t *testing.T) {
+	t.Run("expressions without the ?. operator are not optional chains", func(t *testing.T) {
+		_, ok, err := parseOptionalChain(parser.Expression{Value: `user.Name + "?."`})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ok {
+			t.Error("expected not to be an optional chain")
+		}
+	})
+	t.Run("each link in the chain is checked", func(t *testing.T) {
+		oc, ok, err := parseOptionalChain(parser.Expression{Value: `users[0]?.Profile?.Name`})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !ok {
+			t.Fatal("expected an optional chain")
+		}
+		if diff := cmp.Diff([]string{"users[0]", "users[0].Profile"}, oc.Checks); diff != "" {
+			t.Error(diff)
+		}
+		var value string
+		for _, part := range oc.Parts {
+			value += part.Value
+		}
+		if value != "users[0].Profile.Name" {
+			t.Errorf("expected %q, got %q", "users[0].Profile.Name", value)
+		}
+	})
+	t.Run("the ?. operator can't be used within function arguments", func(t *testing.T) {
+		_, _, err := parseOptionalChain(parser.Expression{Value: `strings.ToUpper(user?.Name)`})
+		if err == nil {
+			t.Error("expected an error, got nil")
+		}
+	})
+	t.Run("the ?. operator can't be used outside a single chain", func(t *testing.T) {
+		for _, value := range []string{`a?.b + c?.d`, `a.b + c?.d`, `user?.Name + "!"`} {
+			if _, _, err := parseOptionalChain(parser.Expression{Value: value}); err == nil {
+				t.Errorf("%s: expected an error, got nil", value)
+			}
+		}
+	})
+	t.Run("the chain can include method calls and indexes", func(t *testing.T) {
+		oc, ok, err := parseOptionalChain(parser.Expression{Value: ` getUser(ctx)?.Friends()[0]?.Name `})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !ok {
+			t.Fatal("expected an optional chain")
+		}
+		if diff := cmp.Diff([]string{"getUser(ctx)", "getUser(ctx).Friends()[0]"}, oc.Checks); diff != "" {
+			t.Error(diff)
+		}
+	})
+}
+
+func TestScriptParameterNames(t *testing.T) {
+	tests := []struct {
+		parameters string
+		expected   []string
+	}{
+		{parameters: "", expected: nil},
+		{parameters: "a string, b int", expected: []string{"a", "b"}},
+		{parameters: "a, b string", expected: []string{"a", "b"}},
+		{parameters: "f func(x, y int) error, m map[string]int", expected: []string{"f", "m"}},
+		{parameters: "items ...string", expected: []string{"items"}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.parameters, func(t *testing.T) {
+			if diff := cmp.Diff(tt.expected, scriptParameterNames(tt.parameters)); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestDebugMarkers(t *testing.T) {
+	tf, err := parser.ParseString(`package main
+
+templ header(title string) {
+	<h1>{ title }</h1>
+}
+
+templ (p Page) footer() {
+	<footer></footer>
+}
+`)
+	if err != nil {
+		t.Fatalf("failed to parse template: %v", err)
+	}
+	var w bytes.Buffer
+	if _, _, err = Generate(tf, &w, WithDebugMarkers(), WithFileName("components/header.templ")); err != nil {
+		t.Fatalf("failed to generate: %v", err)
+	}
+	for _, expected := range []string{
+		`<!-- templ: header (components/header.templ:3) -->`,
+		`<!-- /templ: header -->`,
+		`<!-- templ: Page.footer (components/header.templ:7) -->`,
+		`<!-- /templ: Page.footer -->`,
+	} {
+		if !strings.Contains(w.String(), expected) {
+			t.Errorf("expected %q in generated code:\n%s", expected, w.String())
+		}
+	}
+}
+
+func TestRenderTrace(t *testing.T) {
+	tf, err := parser.ParseString(`package main
+
+templ header(title string) {
+	<h1>{ title }</h1>
+}
+`)
+	if err != nil {
+		t.Fatalf("failed to parse template: %v", err)
+	}
+	var w bytes.Buffer
+	if _, _, err = Generate(tf, &w, WithRenderTrace(), WithFileName("header.templ")); err != nil {
+		t.Fatalf("failed to generate: %v", err)
+	}
+	for _, expected := range []string{
+		`ctx, templ_7745c5c3_TraceEnd := templ.TraceStart(ctx, "header", "header.templ:3", templ_7745c5c3_Buffer)`,
+		`templ_7745c5c3_TraceEnd()`,
+	} {
+		if !strings.Contains(w.String(), expected) {
+			t.Errorf("expected %q in generated code:\n%s", expected, w.String())
+		}
+	}
+}
+
+func TestSourceMapRegistration(t *testing.T) {
+	tf, err := parser.ParseString(`package main
+
+templ header(title string) {
+	<h1>{ title }</h1>
+}
+`)
+	if err != nil {
+		t.Fatalf("failed to parse template: %v", err)
+	}
+	var w bytes.Buffer
+	if _, _, err = Generate(tf, &w, WithSourceMapRegistration()); err != nil {
+		t.Fatalf("failed to generate: %v", err)
+	}
+	var goLine int
+	for i, line := range strings.Split(w.String(), "\n") {
+		if strings.Contains(line, "templ.JoinStringErrs(title)") {
+			goLine = i + 1
+		}
+	}
+	if goLine == 0 {
+		t.Fatalf("expected the title expression in generated code:\n%s", w.String())
+	}
+	expected := fmt.Sprintf(",%d:4:8", goLine)
+	if !strings.Contains(w.String(), "func init() {\n\ttempl.RegisterSourceMap(") || !strings.Contains(w.String(), expected) {
+		t.Errorf("expected source map registration containing %q in generated code:\n%s", expected, w.String())
+	}
+}
+
+func TestReadable(t *testing.T) {
+	tf, err := parser.ParseString(`package main
+
+templ list(items []string) {
+	<h1>Items</h1>
+	<ul>
+		for _, item := range items {
+			<li>{ item }</li>
+		}
+	</ul>
+}
+`)
+	if err != nil {
+		t.Fatalf("failed to parse template: %v", err)
+	}
+	var w bytes.Buffer
+	if _, _, err = Generate(tf, &w, WithReadable(), WithFileName("list.templ")); err != nil {
+		t.Fatalf("failed to generate: %v", err)
+	}
+	for _, expected := range []string{
+		"// list.templ:4: <h1>\n",
+		`WriteString("<h1>Items</h1>")`,
+		"// list.templ:5: <ul>\n",
+		"// list.templ:6: for _, item := range items\n",
+		"// list.templ:7: <li>\n",
+	} {
+		if !strings.Contains(w.String(), expected) {
+			t.Errorf("expected %q in generated code:\n%s", expected, w.String())
+		}
+	}
+	// Static content on different lines isn't combined into a single write.
+	if strings.Contains(w.String(), "</h1><ul>") {
+		t.Errorf("expected static content of each line to be written separately:\n%s", w.String())
+	}
+}
+
+func TestHotReload(t *testing.T) {
+	tf, err := parser.ParseString(`package main
+
+templ header(title string, level int) {
+	<h1>{ title }</h1>
+}
+
+templ list(items ...string) {
+	<ul></ul>
+}
+`)
+	if err != nil {
+		t.Fatalf("failed to parse template: %v", err)
+	}
+	var w bytes.Buffer
+	if _, _, err = Generate(tf, &w, WithHotReload()); err != nil {
+		t.Fatalf("failed to generate: %v", err)
+	}
+	for _, expected := range []string{
+		`import _ "github.com/a-h/templ/interpreter"`,
+		`if templ_7745c5c3_HotReload, templ_7745c5c3_HotReloaded := templ.HotReload("header", title, level); templ_7745c5c3_HotReloaded {`,
+	} {
+		if !strings.Contains(w.String(), expected) {
+			t.Errorf("expected %q in generated code:\n%s", expected, w.String())
+		}
+	}
+	if strings.Contains(w.String(), `templ.HotReload("list"`) {
+		t.Errorf("expected variadic templates to use the compiled code:\n%s", w.String())
+	}
+}
+
+func TestPrecompress(t *testing.T) {
+	tf, err := parser.ParseString(`package main
+
+templ about() {
+	<h1 class="title">About</h1>
+}
+
+templ header(title string) {
+	<h1>{ title }</h1>
+}
+`)
+	if err != nil {
+		t.Fatalf("failed to parse template: %v", err)
+	}
+	t.Run("static templates are precompressed", func(t *testing.T) {
+		var w bytes.Buffer
+		if _, _, err = Generate(tf, &w, WithPrecompress()); err != nil {
+			t.Fatalf("failed to generate: %v", err)
+		}
+		for _, expected := range []string{
+			"return templ.Precompressed(templ_7745c5c3_Precompressed_about, templ.ComponentFunc(",
+			"var templ_7745c5c3_Precompressed_about = templ.PrecompressedContent{\n\tGzip:   \"\\x1f\\x8b",
+		} {
+			if !strings.Contains(w.String(), expected) {
+				t.Errorf("expected %q in generated code:\n%s", expected, w.String())
+			}
+		}
+		if strings.Contains(w.String(), "templ_7745c5c3_Precompressed_header") {
+			t.Errorf("expected templates with dynamic content not to be precompressed:\n%s", w.String())
+		}
+	})
+	t.Run("templates aren't precompressed with hot reloading", func(t *testing.T) {
+		var w bytes.Buffer
+		if _, _, err = Generate(tf, &w, WithPrecompress(), WithHotReload()); err != nil {
+			t.Fatalf("failed to generate: %v", err)
+		}
+		if strings.Contains(w.String(), "templ.Precompressed(") {
+			t.Errorf("expected templates not to be precompressed:\n%s", w.String())
+		}
+	})
+}
+
+func TestMinifyJS(t *testing.T) {
+	tf, err := parser.ParseString(`package main
+
+templ greeting(name string) {
+	<script type="module">
+		// Greet the user.
+		const message = ` + "`Hello,  {{ name }}!`" + `;
+		console.log(message);
+	</script>
+	<style>
+		p { color: red; }
+	</style>
+}
+`)
+	if err != nil {
+		t.Fatalf("failed to parse template: %v", err)
+	}
+	var w bytes.Buffer
+	if _, _, err = Generate(tf, &w, WithMinifyJS()); err != nil {
+		t.Fatalf("failed to generate: %v", err)
+	}
+	for _, expected := range []string{
+		"const message=`Hello,  \")",
+		"templ.JoinStringErrs(name)",
+		"templ.EscapeJSTemplateLiteral(",
+		"!`;console.log(message);</script>",
+		"p { color: red; }",
+	} {
+		if !strings.Contains(w.String(), expected) {
+			t.Errorf("expected %q in generated code:\n%s", expected, w.String())
+		}
+	}
+	if strings.Contains(w.String(), "Greet the user") {
+		t.Errorf("expected comments to be removed:\n%s", w.String())
+	}
+
+	t.Run("syntax errors are returned", func(t *testing.T) {
+		tf, err := parser.ParseString(`package main
+
+templ broken() {
+	<script>const a = ;</script>
+}
+`)
+		if err != nil {
+			t.Fatalf("failed to parse template: %v", err)
+		}
+		if _, _, err = Generate(tf, &bytes.Buffer{}, WithMinifyJS()); err == nil {
+			t.Error("expected an error")
+		}
+	})
+}
+
+func TestMinifyCSS(t *testing.T) {
+	tf, err := parser.ParseString(`package main
+
+css card() {
+	background-color: #FFFFFF;
+	&:hover > .title {
+		color: #AABBCC;
+	}
+}
+
+templ greeting(color string) {
+	<style>
+		/* Headings. */
+		h1 {
+			color: {{ color }};
+			margin: 0px 0px;
+		}
+	</style>
+	<script>
+		const  a = 1;
+	</script>
+}
+`)
+	if err != nil {
+		t.Fatalf("failed to parse template: %v", err)
+	}
+	var w bytes.Buffer
+	if _, _, err = Generate(tf, &w, WithMinifyCSS()); err != nil {
+		t.Fatalf("failed to generate: %v", err)
+	}
+	for _, expected := range []string{
+		"background-color:#fff;",
+		"templ_7745c5c3_CSSClass:hover>.title{",
+		"color:#abc;",
+		">h1{color:",
+		"templ.EscapeCSSPropertyValue(`color`, ",
+		";margin:0}</style>",
+		"const  a = 1;",
+	} {
+		if !strings.Contains(w.String(), expected) {
+			t.Errorf("expected %q in generated code:\n%s", expected, w.String())
+		}
+	}
+	if strings.Contains(w.String(), "Headings") {
+		t.Errorf("expected comments to be removed:\n%s", w.String())
+	}
+}
+
+func TestStylePreprocessor(t *testing.T) {
+	tf, err := parser.ParseString(`package main
+
+templ card(color string) {
+	<style lang="scss">
+		.card { .title { color: {{ color }}; } }
+	</style>
+	<style>
+		.plain { color: red; }
+	</style>
+}
+`)
+	if err != nil {
+		t.Fatalf("failed to parse template: %v", err)
+	}
+	var langs []string
+	preprocess := func(lang, src string) (string, error) {
+		langs = append(langs, lang)
+		// Flatten the nested rule, as a SCSS compiler would.
+		return strings.NewReplacer(".card { .title {", ".card .title {", "; } }", "; }").Replace(src), nil
+	}
+	var w bytes.Buffer
+	if _, _, err = Generate(tf, &w, WithStylePreprocessor(preprocess)); err != nil {
+		t.Fatalf("failed to generate: %v", err)
+	}
+	if diff := cmp.Diff([]string{"scss"}, langs); diff != "" {
+		t.Errorf("expected only the scss style element to be preprocessed: %s", diff)
+	}
+	for _, expected := range []string{
+		".card .title { color: ",
+		"templ.JoinStringErrs(color)",
+		".plain { color: red; }",
+	} {
+		if !strings.Contains(w.String(), expected) {
+			t.Errorf("expected %q in generated code:\n%s", expected, w.String())
+		}
+	}
+	if strings.Contains(w.String(), "lang=") {
+		t.Errorf("expected the lang attribute to be removed:\n%s", w.String())
+	}
+
+	t.Run("errors are returned", func(t *testing.T) {
+		failing := func(lang, src string) (string, error) {
+			return "", errors.New("invalid scss")
+		}
+		if _, _, err = Generate(tf, &bytes.Buffer{}, WithStylePreprocessor(failing)); err == nil {
+			t.Error("expected an error")
+		}
+	})
+}
+
+func TestMarkdownRenderer(t *testing.T) {
+	tf, err := parser.ParseString(`package main
+
+templ page() {
+	@markdown {
+		# Title
+	}
+}
+`)
+	if err != nil {
+		t.Fatalf("failed to parse template: %v", err)
+	}
+	t.Run("markdown blocks are rendered at runtime by default", func(t *testing.T) {
+		var w bytes.Buffer
+		if _, _, err = Generate(tf, &w); err != nil {
+			t.Fatalf("failed to generate: %v", err)
+		}
+		for _, expected := range []string{
+			`import templ_7745c5c3_markdown "github.com/a-h/templ/markdown"`,
+			"templ_7745c5c3_markdown.Component(`# Title`)",
+		} {
+			if !strings.Contains(w.String(), expected) {
+				t.Errorf("expected %q in generated code:\n%s", expected, w.String())
+			}
+		}
+	})
+	t.Run("markdown blocks can be rendered when the code is generated", func(t *testing.T) {
+		render := func(src string) (string, error) {
+			return "<h1>" + strings.TrimPrefix(src, "# ") + "</h1>", nil
+		}
+		var w bytes.Buffer
+		if _, _, err = Generate(tf, &w, WithMarkdownRenderer(render)); err != nil {
+			t.Fatalf("failed to generate: %v", err)
+		}
+		if !strings.Contains(w.String(), `"<h1>Title</h1>"`) {
+			t.Errorf("expected the rendered HTML in generated code:\n%s", w.String())
+		}
+		if strings.Contains(w.String(), "templ/markdown") {
+			t.Errorf("expected the markdown package not to be imported:\n%s", w.String())
+		}
+	})
+	t.Run("errors are returned", func(t *testing.T) {
+		failing := func(src string) (string, error) {
+			return "", errors.New("invalid markdown")
+		}
+		if _, _, err = Generate(tf, &bytes.Buffer{}, WithMarkdownRenderer(failing)); err == nil {
+			t.Error("expected an error")
+		}
+	})
+	t.Run("markdown blocks in included files are imported", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "intro.templ"), []byte("@markdown {\n\t# Intro\n}\n"), 0o644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+		tf, err := parser.ParseString("package main\n\ntempl page() {\n\t@include \"intro.templ\"\n}\n")
+		if err != nil {
+			t.Fatalf("failed to parse template: %v", err)
+		}
+		var w bytes.Buffer
+		if _, _, err = Generate(tf, &w, WithIncludeDir(dir)); err != nil {
+			t.Fatalf("failed to generate: %v", err)
+		}
+		if !strings.Contains(w.String(), `import templ_7745c5c3_markdown "github.com/a-h/templ/markdown"`) {
+			t.Errorf("expected the markdown package to be imported:\n%s", w.String())
+		}
+	})
+}
+
+func TestIntegrity(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "static"), 0o755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	for name, contents := range map[string]string{
+		"static/app.js":    "alert(1);",
+		"static/style.css": "p { color: red; }",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+	generate := func(t *testing.T, template string) (string, error) {
+		tf, err := parser.ParseString("package main\n\ntempl page() {\n" + template + "\n}\n")
+		if err != nil {
+			t.Fatalf("failed to parse template: %v", err)
+		}
+		var w bytes.Buffer
+		_, _, err = Generate(tf, &w, WithIntegrity(dir))
+		return w.String(), err
+	}
+	// The hashes are the output of:
+	// printf 'alert(1);' | openssl dgst -sha384 -binary | openssl base64 -A
+	const appHash = "sha384-dnux3uAPxaf+IhCrFG1D/XVNzP1XLDNcn3Pe3jyxouEAoot5kfwC5u8rMwNhE5oi"
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{
+			name:     "scripts with local paths have integrity attributes",
+			template: `<script src="/static/app.js?v=1" defer></script>`,
+			expected: `<script src=\"/static/app.js?v=1\" defer integrity=\"` + appHash + `\"`,
+		},
+		{
+			name:     "stylesheets with local paths have integrity attributes",
+			template: `<link rel="stylesheet" href="static/style.css">`,
+			expected: `<link rel=\"stylesheet\" href=\"static/style.css\" integrity=\"sha384-`,
+		},
+		{
+			name:     "other links don't have integrity attributes",
+			template: `<link rel="icon" href="/static/app.js">`,
+			expected: `<link rel=\"icon\" href=\"/static/app.js\">`,
+		},
+		{
+			name:     "external scripts don't have integrity attributes",
+			template: `<script src="https://example.com/app.js"></script>`,
+			expected: `<script src=\"https://example.com/app.js\"`,
+		},
+		{
+			name:     "existing integrity attributes are kept",
+			template: `<script src="/static/app.js" integrity="sha384-abc"></script>`,
+			expected: `<script src=\"/static/app.js\" integrity=\"sha384-abc\"`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := generate(t, tt.template)
+			if err != nil {
+				t.Fatalf("failed to generate: %v", err)
+			}
+			if !strings.Contains(actual, tt.expected) {
+				t.Errorf("expected %q in generated code:\n%s", tt.expected, actual)
+			}
+		})
+	}
+	t.Run("missing files are an error", func(t *testing.T) {
+		if _, err := generate(t, `<script src="/static/missing.js"></script>`); err == nil {
+			t.Error("expected an error")
+		}
+	})
+}
+
+func TestScriptExtraction(t *testing.T) {
+	tf, err := parser.ParseString(`package main
+
+templ page(name string) {
+	<script type="module">
+		console.log("static");
+	</script>
+	<script>
+		console.log(` + "`Hello, {{ name }}`" + `);
+	</script>
+	<script type="application/json">{"a": 1}</script>
+	<script src="/app.js"></script>
+}
+`)
+	if err != nil {
+		t.Fatalf("failed to parse template: %v", err)
+	}
+	var extracted []string
+	extract := func(js string) (string, error) {
+		extracted = append(extracted, js)
+		return "/scripts/static.js", nil
+	}
+	var w bytes.Buffer
+	if _, _, err = Generate(tf, &w, WithScriptExtraction(extract)); err != nil {
+		t.Fatalf("failed to generate: %v", err)
+	}
+	if diff := cmp.Diff([]string{"\n\t\tconsole.log(\"static\");\n\t"}, extracted); diff != "" {
+		t.Error(diff)
+	}
+	for _, expected := range []string{
+		`<script type=\"module\" src=\"/scripts/static.js\"`,
+		"templ.JoinStringErrs(name)",
+		`{\"a\": 1}</script>`,
+		`<script src=\"/app.js\"`,
+	} {
+		if !strings.Contains(w.String(), expected) {
+			t.Errorf("expected %q in generated code:\n%s", expected, w.String())
+		}
+	}
+	if strings.Contains(w.String(), `console.log(\"static\")`) {
+		t.Errorf("expected the static script to be extracted:\n%s", w.String())
+	}
+}
+
+func TestHotReloadSignature(t *testing.T) {
+	tests := []struct {
+		signature      string
+		expectedName   string
+		expectedParams []string
+		expectedOK     bool
+	}{
+		{signature: "header()", expectedName: "header", expectedOK: true},
+		{signature: "header(title, subtitle string, level int)", expectedName: "header", expectedParams: []string{"title", "subtitle", "level"}, expectedOK: true},
+		{signature: "(p Page) footer()"},
+		{signature: "list[T any](items []T)"},
+		{signature: "list(items ...string)"},
+		{signature: "header(_ string)"},
+	}
+	for _, test := range tests {
+		t.Run(test.signature, func(t *testing.T) {
+			name, params, ok := hotReloadSignature(test.signature)
+			if ok != test.expectedOK || name != test.expectedName || strings.Join(params, ",") != strings.Join(test.expectedParams, ",") {
+				t.Errorf("expected %q %v %v, got %q %v %v", test.expectedName, test.expectedParams, test.expectedOK, name, params, ok)
+			}
+		})
+	}
+}
+
+func TestTemplateName(t *testing.T) {
+	tests := []struct {
+		signature string
+		expected  string
+	}{
+		{signature: "header(title string)", expected: "header"},
+		{signature: "(p Page) footer()", expected: "Page.footer"},
+		{signature: "(p *Page) footer()", expected: "Page.footer"},
+		{signature: "list[T any](items []T)", expected: "list"},
+	}
+	for _, test := range tests {
+		t.Run(test.signature, func(t *testing.T) {
+			if actual := templateName(test.signature); actual != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestCriticalCSS(t *testing.T) {
+	tf, err := parser.ParseString(`package main
+
+css bold() {
+	font-weight: bold;
+}
+
+css red() {
+	color: red;
+}
+
+templ header() {
+	<h1 class={ bold() }>Title</h1>
+}
+
+templ Page() {
+	@header()
+	<p class={ red(), bold() }>Text</p>
+}
+`)
+	if err != nil {
+		t.Fatalf("failed to parse template: %v", err)
+	}
+	var w bytes.Buffer
+	if _, _, err = Generate(tf, &w, WithCriticalCSS("Page", "Missing")); err != nil {
+		t.Fatalf("failed to generate: %v", err)
+	}
+	expected := `func PageCriticalCSS() templ.CriticalCSS {
+	return templ.CriticalCSS{
+		bold(),
+		red(),
+	}
+}`
+	if !strings.Contains(w.String(), expected) {
+		t.Errorf("expected %q in generated code:\n%s", expected, w.String())
+	}
+	if strings.Contains(w.String(), "MissingCriticalCSS") {
+		t.Errorf("expected no function for a component that isn't in the file:\n%s", w.String())
+	}
+}
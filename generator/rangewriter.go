@@ -23,6 +23,9 @@ type RangeWriter struct {
 
 	// Extract strings.
 	literalWriter literalWriter
+
+	// capture records the string literals that are written, if it's set.
+	capture *strings.Builder
 }
 
 type literalWriter interface {
@@ -96,6 +99,9 @@ func (rw *RangeWriter) WriteIndent(level int, s string) (r parser.Range, err err
 }
 
 func (rw *RangeWriter) WriteStringLiteral(level int, s string) (r parser.Range, err error) {
+	if rw.capture != nil {
+		rw.capture.WriteString(s)
+	}
 	if !rw.inLiteral {
 		_, err = rw.write(strings.Repeat("\t", level))
 		if err != nil {
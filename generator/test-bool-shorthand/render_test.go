@@ -0,0 +1,41 @@
+package testboolshorthand
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name      string
+		component templ.Component
+		expected  string
+	}{
+		{
+			name:      "attributes are rendered when the expression is true",
+			component: input(false, true),
+			expected:  `<input type="checkbox" disabled checked>`,
+		},
+		{
+			name:      "attributes are omitted when the expression is false",
+			component: input(true, false),
+			expected:  `<input type="checkbox">`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			if err := tt.component.Render(context.Background(), &sb); err != nil {
+				t.Fatalf("failed to render: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, sb.String()); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
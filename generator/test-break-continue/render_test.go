@@ -0,0 +1,42 @@
+package testbreakcontinue
+
+import (
+	"context"
+	_ "embed"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ/generator/htmldiff"
+	"github.com/google/go-cmp/cmp"
+)
+
+//go:embed expected.html
+var expected string
+
+func Test(t *testing.T) {
+	component := render([][]string{
+		{"a", "b"},
+		{"c", "", "skipped"},
+		{"d", "stop", "skipped"},
+		{"skipped"},
+	})
+
+	diff, err := htmldiff.Diff(component, expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestBranchStatementsOutsideOfLoopsAreText(t *testing.T) {
+	var sb strings.Builder
+	if err := text([]string{"a", "", "b"}).Render(context.Background(), &sb); err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+	expected := `<button>continue</button> <p>break</p> <span>a</span><p>break</p><p>break</p> <span>b</span>`
+	if diff := cmp.Diff(expected, sb.String()); diff != "" {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,21 @@
+package testbuildconstraints
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRender(t *testing.T) {
+	// The debug component of debug_dev.templ is only built with the templ_dev
+	// build tag, so the empty debug component of debug.templ is used.
+	w := new(strings.Builder)
+	if err := page().Render(context.Background(), w); err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+	if diff := cmp.Diff(`<main><p>Content</p></main>`, w.String()); diff != "" {
+		t.Error(diff)
+	}
+}
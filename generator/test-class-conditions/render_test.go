@@ -0,0 +1,51 @@
+package testclassconditions
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name      string
+		component templ.Component
+		expected  string
+	}{
+		{
+			name:      "true conditions add classes",
+			component: button("Save", true, true),
+			expected:  `<button class="btn btn-primary">Save</button>`,
+		},
+		{
+			name:      "false conditions omit classes",
+			component: button("Save", false, false),
+			expected:  `<button class="btn disabled">Save</button>`,
+		},
+		{
+			name:      "CSS components can be used as class names",
+			component: styled(true),
+			expected:  `<style type="text/css">.red_9b87{color:#ff0000;}</style><p class="text red_9b87">Hello</p>`,
+		},
+		{
+			name:      "CSS components are omitted when the condition is false",
+			component: styled(false),
+			expected:  `<p class="text">Hello</p>`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			if err := tt.component.Render(context.Background(), &sb); err != nil {
+				t.Fatalf("failed to render: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, sb.String()); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package testdefaultparams
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name      string
+		component templ.Component
+		expected  string
+	}{
+		{
+			name:      "omitted parameters use their default values",
+			component: button("Save"),
+			expected:  `<button class="primary" title="Save">Save</button>`,
+		},
+		{
+			name:      "options override the default values",
+			component: button("Save", buttonOptions{Variant: templ.Ptr("secondary"), Title: templ.Ptr("Save changes")}),
+			expected:  `<button class="secondary" title="Save changes">Save</button>`,
+		},
+		{
+			name:      "nil options use the default values",
+			component: button("Save", buttonOptions{Title: templ.Ptr("Save changes")}),
+			expected:  `<button class="primary" title="Save changes">Save</button>`,
+		},
+		{
+			name:      "options can override the default values with the zero value",
+			component: button("Save", buttonOptions{Title: templ.Ptr("")}),
+			expected:  `<button class="primary" title="">Save</button>`,
+		},
+		{
+			name:      "boolean and number defaults",
+			component: toggle("Notify"),
+			expected:  `<input type="checkbox" aria-label="Notify" checked tabindex="10">`,
+		},
+		{
+			name:      "boolean and number defaults can be overridden with false and zero",
+			component: toggle("Notify", toggleOptions{Checked: templ.Ptr(false), TabIndex: templ.Ptr(0)}),
+			expected:  `<input type="checkbox" aria-label="Notify" tabindex="0">`,
+		},
+		{
+			name:      "multiline signatures",
+			component: list([]string{"a", "b"}),
+			expected:  `<p>a, b</p>`,
+		},
+		{
+			name:      "multiline signatures with options",
+			component: list([]string{"a", "b"}, listOptions{Sep: templ.Ptr(" | ")}),
+			expected:  `<p>a | b</p>`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			w := new(strings.Builder)
+			if err := tt.component.Render(context.Background(), w); err != nil {
+				t.Fatalf("failed to render: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, w.String()); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
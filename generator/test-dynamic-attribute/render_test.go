@@ -0,0 +1,46 @@
+package testdynamicattribute
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name     string
+		attrName string
+		value    string
+		required bool
+		expected string
+	}{
+		{
+			name:     "attributes are rendered",
+			attrName: "title",
+			value:    `"Name"`,
+			required: true,
+			expected: `<input type="text" data-title="&#34;Name&#34;" required title="&#34;Name&#34;">`,
+		},
+		{
+			name:     "invalid names aren't rendered",
+			attrName: `x" onfocus="alert(1)`,
+			value:    "a",
+			expected: `<input type="text">`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			w := new(strings.Builder)
+			err := field(tt.attrName, tt.value, tt.required).Render(context.Background(), w)
+			if err != nil {
+				t.Fatalf("failed to render: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, w.String()); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
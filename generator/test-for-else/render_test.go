@@ -0,0 +1,66 @@
+package testforelse
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name      string
+		component templ.Component
+		expected  string
+	}{
+		{
+			name:      "the loop body is rendered for each item",
+			component: list([]string{"a", "b"}),
+			expected:  `<ul><li>a</li><li>b</li></ul>`,
+		},
+		{
+			name:      "else is rendered if there are no items",
+			component: list(nil),
+			expected:  `<ul><li>No items</li></ul>`,
+		},
+		{
+			name:      "sorted maps",
+			component: prices(map[string]int{"b": 2, "a": 1}),
+			expected:  `<dl><dt>a</dt><dd>1</dd><dt>b</dt><dd>2</dd></dl>`,
+		},
+		{
+			name:      "else is rendered for empty sorted maps",
+			component: prices(map[string]int{}),
+			expected:  `<dl><dt>Free</dt></dl>`,
+		},
+		{
+			name:      "else isn't rendered if the loop body ran, even if it rendered nothing",
+			component: firstLong([]string{"a", "b"}),
+			expected:  `<p></p>`,
+		},
+		{
+			name:      "else isn't rendered when the loop breaks",
+			component: firstLong([]string{"a", "long"}),
+			expected:  `<p>long</p>`,
+		},
+		{
+			name:      "else is rendered for empty slices",
+			component: firstLong(nil),
+			expected:  `<p>None</p>`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			w := new(strings.Builder)
+			if err := tt.component.Render(context.Background(), w); err != nil {
+				t.Fatalf("failed to render: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, w.String()); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
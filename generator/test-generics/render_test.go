@@ -0,0 +1,25 @@
+package testgenerics
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const expected = `<ul><li><b>1</b></li><li><b>2</b></li></ul>` +
+	`<ul><li>a</li></ul>` +
+	`<p>4</p>` +
+	`<tr><td>3</td></tr>`
+
+func TestRender(t *testing.T) {
+	w := new(strings.Builder)
+	err := page().Render(context.Background(), w)
+	if err != nil {
+		t.Errorf("failed to render: %v", err)
+	}
+	if diff := cmp.Diff(expected, w.String()); diff != "" {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,57 @@
+package testifinit
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRender(t *testing.T) {
+	prices := map[string]int{"apple": 3, "pear": 2}
+	tests := []struct {
+		name      string
+		component templ.Component
+		expected  string
+	}{
+		{
+			name:      "variables declared by the init statement can be used",
+			component: price(prices, "apple"),
+			expected:  `<p>apple: 3</p>`,
+		},
+		{
+			name:      "else if branches can have init statements",
+			component: price(prices, "fig"),
+			expected:  `<p>fig isn't one of 2 items</p>`,
+		},
+		{
+			name:      "else branches are rendered if no condition is true",
+			component: price(nil, "fig"),
+			expected:  `<p>No prices</p>`,
+		},
+		{
+			name:      "type assertions",
+			component: label(name("templ")),
+			expected:  `<span>Name: templ</span>`,
+		},
+		{
+			name:      "failed type assertions",
+			component: label(42),
+			expected:  `<span>42</span>`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			w := new(strings.Builder)
+			if err := tt.component.Render(context.Background(), w); err != nil {
+				t.Fatalf("failed to render: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, w.String()); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
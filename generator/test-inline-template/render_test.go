@@ -0,0 +1,25 @@
+package testinlinetemplate
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const expected = `<table>` +
+	`<tr><td>Apple</td><td><span class="price">£1</span></td><td><button>Remove</button></td></tr>` +
+	`<tr><td>Pear &amp; Fig</td><td><span class="price">£2</span></td><td><button>Remove</button></td></tr>` +
+	`</table><p>templ is great</p>`
+
+func TestRender(t *testing.T) {
+	w := new(strings.Builder)
+	err := basket("£", []Item{{Name: "Apple", Price: 1}, {Name: "Pear & Fig", Price: 2}}).Render(context.Background(), w)
+	if err != nil {
+		t.Errorf("failed to render: %v", err)
+	}
+	if diff := cmp.Diff(expected, w.String()); diff != "" {
+		t.Error(diff)
+	}
+}
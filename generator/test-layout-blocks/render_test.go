@@ -0,0 +1,49 @@
+package testlayoutblocks
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name      string
+		component templ.Component
+		expected  string
+	}{
+		{
+			name:      "blocks that aren't overridden render their defaults",
+			component: home(),
+			expected: `<html><head><title>Home</title><meta name="robots" content="index"></head>` +
+				`<body><nav>Home</nav><main><p>Hello</p></main></body></html>`,
+		},
+		{
+			name:      "blocks can be overridden",
+			component: section("Docs"),
+			expected: `<html><head><title>Docs</title><meta name="section" content="Docs"></head>` +
+				`<body><nav>Docs</nav><main></main></body></html>`,
+		},
+		{
+			name:      "overrides can render the blocks passed to the component",
+			component: page(),
+			expected: `<html><head><title>Docs</title><meta name="robots" content="noindex"></head>` +
+				`<body><nav>Docs</nav><main><p>Welcome</p></main></body></html>`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			w := new(strings.Builder)
+			if err := tt.component.Render(context.Background(), w); err != nil {
+				t.Fatalf("failed to render: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, w.String()); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
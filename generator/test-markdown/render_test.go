@@ -0,0 +1,38 @@
+package testmarkdown
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ/markdown"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRender(t *testing.T) {
+	t.Run("markdown is rendered with goldmark by default", func(t *testing.T) {
+		const expected = `<article><h1>Docs</h1><p>Install templ with <code>go install</code>:</p>` + "\n" +
+			`<pre><code class="language-go">func main() {` + "\n}\n" + `</code></pre>` + "\n" + `</article>`
+		w := new(strings.Builder)
+		if err := page("Docs").Render(context.Background(), w); err != nil {
+			t.Fatalf("failed to render: %v", err)
+		}
+		if diff := cmp.Diff(expected, w.String()); diff != "" {
+			t.Error(diff)
+		}
+	})
+	t.Run("the markdown renderer can be replaced", func(t *testing.T) {
+		ctx := markdown.WithRenderer(context.Background(), func(w io.Writer, src string) error {
+			_, err := io.WriteString(w, "<pre>"+strings.SplitN(src, "\n", 2)[0]+"</pre>")
+			return err
+		})
+		w := new(strings.Builder)
+		if err := page("Docs").Render(ctx, w); err != nil {
+			t.Fatalf("failed to render: %v", err)
+		}
+		if diff := cmp.Diff("<article><h1>Docs</h1><pre>Install templ with `go install`:</pre></article>", w.String()); diff != "" {
+			t.Error(diff)
+		}
+	})
+}
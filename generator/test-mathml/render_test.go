@@ -0,0 +1,23 @@
+package testmathml
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const expected = `<p>The sum of the first <math><mi>n</mi></math> numbers is ` +
+	`<math display="inline"><mfrac><mrow><mi>n</mi><mo>(</mo><mi>n</mi><mo>+</mo><mn>1</mn><mo>)</mo></mrow><mn>2</mn></mfrac></math>.</p>`
+
+func TestRender(t *testing.T) {
+	w := new(strings.Builder)
+	err := formula("n").Render(context.Background(), w)
+	if err != nil {
+		t.Errorf("failed to render: %v", err)
+	}
+	if diff := cmp.Diff(expected, w.String()); diff != "" {
+		t.Error(diff)
+	}
+}
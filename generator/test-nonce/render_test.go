@@ -0,0 +1,24 @@
+package testnonce
+
+import (
+	"context"
+	_ "embed"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/a-h/templ/generator/htmldiff"
+)
+
+//go:embed expected.html
+var expected string
+
+func Test(t *testing.T) {
+	ctx := templ.WithNonce(context.Background(), "abc")
+	diff, err := htmldiff.DiffCtx(ctx, Page(), expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff != "" {
+		t.Error(diff)
+	}
+}
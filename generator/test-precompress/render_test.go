@@ -0,0 +1,81 @@
+package testprecompress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	_ "embed"
+
+	"github.com/a-h/templ"
+	"github.com/a-h/templ/generator/htmldiff"
+	"github.com/andybalholm/brotli"
+)
+
+//go:embed expected.html
+var expected string
+
+func Test(t *testing.T) {
+	component := page()
+
+	diff, err := htmldiff.Diff(component, expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestPrecompressed(t *testing.T) {
+	var rendered bytes.Buffer
+	if err := page().Render(context.Background(), &rendered); err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+	pc, ok := page().(*templ.PrecompressedComponent)
+	if !ok {
+		t.Fatalf("expected a static component to be precompressed, got %T", page())
+	}
+	if _, ok := greeting("Name").(*templ.PrecompressedComponent); ok {
+		t.Error("expected a component with dynamic content not to be precompressed")
+	}
+	t.Run("gzip", func(t *testing.T) {
+		r, err := gzip.NewReader(strings.NewReader(pc.Content.Gzip))
+		if err != nil {
+			t.Fatalf("failed to read gzip content: %v", err)
+		}
+		actual, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("failed to read gzip content: %v", err)
+		}
+		if string(actual) != rendered.String() {
+			t.Errorf("expected %q, got %q", rendered.String(), actual)
+		}
+	})
+	t.Run("brotli", func(t *testing.T) {
+		actual, err := io.ReadAll(brotli.NewReader(strings.NewReader(pc.Content.Brotli)))
+		if err != nil {
+			t.Fatalf("failed to read brotli content: %v", err)
+		}
+		if string(actual) != rendered.String() {
+			t.Errorf("expected %q, got %q", rendered.String(), actual)
+		}
+	})
+	t.Run("the handler writes the encoding accepted by the client", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept-Encoding", "gzip, deflate")
+		templ.Handler(page()).ServeHTTP(w, r)
+		if w.Header().Get("Content-Encoding") != "gzip" {
+			t.Errorf("expected gzip encoding, got %q", w.Header().Get("Content-Encoding"))
+		}
+		if w.Body.String() != pc.Content.Gzip {
+			t.Error("expected the precompressed content to be written")
+		}
+	})
+}
@@ -0,0 +1,64 @@
+//go:build go1.23
+
+package testrangeoverfunc
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name      string
+		component templ.Component
+		expected  string
+	}{
+		{
+			name:      "range over int without variables",
+			component: stars(3),
+			expected:  `<span>*</span><span>*</span><span>*</span>`,
+		},
+		{
+			name:      "range over int",
+			component: numbered(2),
+			expected:  `<ol><li>1</li><li>2</li></ol>`,
+		},
+		{
+			name:      "range over iter.Seq",
+			component: names(slices.Values([]string{"Alice", "Bob"})),
+			expected:  `<ul><li>Alice</li><li>Bob</li></ul>`,
+		},
+		{
+			name:      "the else branch is rendered if the iterator yields nothing",
+			component: names(slices.Values([]string(nil))),
+			expected:  `<ul><li>None</li></ul>`,
+		},
+		{
+			name:      "range over an iterator returned by a function",
+			component: prices(map[string]int{"b": 2, "a": 1}),
+			expected:  `<dl><dt>a</dt><dd>1</dd><dt>b</dt><dd>2</dd></dl>`,
+		},
+		{
+			name:      "break stops the iterator",
+			component: indexed(slices.All([]string{"a", "b", "c"})),
+			expected:  `<p>0: a</p><p>1: b</p>`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			if err := tt.component.Render(context.Background(), &sb); err != nil {
+				t.Fatalf("failed to render: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, sb.String()); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package testrawblock
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const expected = `<div id="list"><script type="text/x-handlebars-template">{{#each items}}<li class=item>@{{name}}{{/each}}</script><p>&lt;items&gt;</p></div>`
+
+func TestRender(t *testing.T) {
+	w := new(strings.Builder)
+	err := list("<items>").Render(context.Background(), w)
+	if err != nil {
+		t.Errorf("failed to render: %v", err)
+	}
+	if diff := cmp.Diff(expected, w.String()); diff != "" {
+		t.Error(diff)
+	}
+}
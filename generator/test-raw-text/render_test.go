@@ -0,0 +1,23 @@
+package testrawtext
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const expected = `<head><title>Tom &amp; Jerry | <b>Site</b></title></head>` +
+	`<form><textarea name="bio">` + "\n  Line one <em>is text</em>\n    " + `&lt;/textarea&gt;` + "\n" + `</textarea></form>`
+
+func TestRender(t *testing.T) {
+	w := new(strings.Builder)
+	err := page("Tom & Jerry", "</textarea>").Render(context.Background(), w)
+	if err != nil {
+		t.Errorf("failed to render: %v", err)
+	}
+	if diff := cmp.Diff(expected, w.String()); diff != "" {
+		t.Error(diff)
+	}
+}
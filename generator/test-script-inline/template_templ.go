@@ -10,23 +10,20 @@ import "io"
 import "bytes"
 
 func withParameters(a string, b string, c int) templ.ComponentScript {
-	return templ.ComponentScript{
-		Name: `__templ_withParameters_1056`,
-		Function: `function __templ_withParameters_1056(a, b, c){console.log(a, b, c);
+	return templ.NewComponentScript(
+		`__templ_withParameters_1056`,
+		`function __templ_withParameters_1056(a, b, c){console.log(a, b, c);
 }`,
-		Call:       templ.SafeScript(`__templ_withParameters_1056`, a, b, c),
-		CallInline: templ.SafeScriptInline(`__templ_withParameters_1056`, a, b, c),
-	}
+		a, b, c,
+	)
 }
 
 func withoutParameters() templ.ComponentScript {
-	return templ.ComponentScript{
-		Name: `__templ_withoutParameters_6bbf`,
-		Function: `function __templ_withoutParameters_6bbf(){alert("hello");
+	return templ.NewComponentScript(
+		`__templ_withoutParameters_6bbf`,
+		`function __templ_withoutParameters_6bbf(){alert("hello");
 }`,
-		Call:       templ.SafeScript(`__templ_withoutParameters_6bbf`),
-		CallInline: templ.SafeScriptInline(`__templ_withoutParameters_6bbf`),
-	}
+	)
 }
 
 func InlineJavascript(a string) templ.Component {
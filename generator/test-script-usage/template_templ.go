@@ -10,33 +10,28 @@ import "io"
 import "bytes"
 
 func withParameters(a string, b string, c int) templ.ComponentScript {
-	return templ.ComponentScript{
-		Name: `__templ_withParameters_1056`,
-		Function: `function __templ_withParameters_1056(a, b, c){console.log(a, b, c);
+	return templ.NewComponentScript(
+		`__templ_withParameters_1056`,
+		`function __templ_withParameters_1056(a, b, c){console.log(a, b, c);
 }`,
-		Call:       templ.SafeScript(`__templ_withParameters_1056`, a, b, c),
-		CallInline: templ.SafeScriptInline(`__templ_withParameters_1056`, a, b, c),
-	}
+		a, b, c,
+	)
 }
 
 func withoutParameters() templ.ComponentScript {
-	return templ.ComponentScript{
-		Name: `__templ_withoutParameters_6bbf`,
-		Function: `function __templ_withoutParameters_6bbf(){alert("hello");
+	return templ.NewComponentScript(
+		`__templ_withoutParameters_6bbf`,
+		`function __templ_withoutParameters_6bbf(){alert("hello");
 }`,
-		Call:       templ.SafeScript(`__templ_withoutParameters_6bbf`),
-		CallInline: templ.SafeScriptInline(`__templ_withoutParameters_6bbf`),
-	}
+	)
 }
 
 func onClick() templ.ComponentScript {
-	return templ.ComponentScript{
-		Name: `__templ_onClick_657d`,
-		Function: `function __templ_onClick_657d(){alert("clicked");
+	return templ.NewComponentScript(
+		`__templ_onClick_657d`,
+		`function __templ_onClick_657d(){alert("clicked");
 }`,
-		Call:       templ.SafeScript(`__templ_onClick_657d`),
-		CallInline: templ.SafeScriptInline(`__templ_onClick_657d`),
-	}
+	)
 }
 
 func Button(text string) templ.Component {
@@ -99,13 +94,11 @@ func Button(text string) templ.Component {
 }
 
 func withComment() templ.ComponentScript {
-	return templ.ComponentScript{
-		Name: `__templ_withComment_9cf8`,
-		Function: `function __templ_withComment_9cf8(){//'
+	return templ.NewComponentScript(
+		`__templ_withComment_9cf8`,
+		`function __templ_withComment_9cf8(){//'
 }`,
-		Call:       templ.SafeScript(`__templ_withComment_9cf8`),
-		CallInline: templ.SafeScriptInline(`__templ_withComment_9cf8`),
-	}
+	)
 }
 
 func ThreeButtons() templ.Component {
@@ -166,13 +159,11 @@ func ThreeButtons() templ.Component {
 }
 
 func conditionalScript() templ.ComponentScript {
-	return templ.ComponentScript{
-		Name: `__templ_conditionalScript_de41`,
-		Function: `function __templ_conditionalScript_de41(){alert("conditional");
+	return templ.NewComponentScript(
+		`__templ_conditionalScript_de41`,
+		`function __templ_conditionalScript_de41(){alert("conditional");
 }`,
-		Call:       templ.SafeScript(`__templ_conditionalScript_de41`),
-		CallInline: templ.SafeScriptInline(`__templ_conditionalScript_de41`),
-	}
+	)
 }
 
 func Conditional(show bool) templ.Component {
@@ -223,13 +214,11 @@ func Conditional(show bool) templ.Component {
 }
 
 func alertTest() templ.ComponentScript {
-	return templ.ComponentScript{
-		Name: `__templ_alertTest_eadf`,
-		Function: `function __templ_alertTest_eadf(){alert('testing');
+	return templ.NewComponentScript(
+		`__templ_alertTest_eadf`,
+		`function __templ_alertTest_eadf(){alert('testing');
 }`,
-		Call:       templ.SafeScript(`__templ_alertTest_eadf`),
-		CallInline: templ.SafeScriptInline(`__templ_alertTest_eadf`),
-	}
+	)
 }
 
 func ScriptOnLoad() templ.Component {
@@ -258,7 +247,15 @@ func ScriptOnLoad() templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("\" src=\"url.to.some.script\"></script>")
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("\" src=\"url.to.some.script\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.NonceAttribute(ctx))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString("></script>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -26,7 +26,7 @@ func BasicTemplate(spread templ.Attributes) templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templ.RenderAttributes(ctx, templ_7745c5c3_Buffer, spread)
+		templ_7745c5c3_Err = templ.RenderSpreadAttributes(ctx, templ_7745c5c3_Buffer, spread)
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -35,7 +35,7 @@ func BasicTemplate(spread templ.Attributes) templ.Component {
 			return templ_7745c5c3_Err
 		}
 		if true {
-			templ_7745c5c3_Err = templ.RenderAttributes(ctx, templ_7745c5c3_Buffer, spread)
+			templ_7745c5c3_Err = templ.RenderSpreadAttributes(ctx, templ_7745c5c3_Buffer, spread)
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
@@ -45,7 +45,7 @@ func BasicTemplate(spread templ.Attributes) templ.Component {
 			return templ_7745c5c3_Err
 		}
 		if false {
-			templ_7745c5c3_Err = templ.RenderAttributes(ctx, templ_7745c5c3_Buffer, spread)
+			templ_7745c5c3_Err = templ.RenderSpreadAttributes(ctx, templ_7745c5c3_Buffer, spread)
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
@@ -0,0 +1,70 @@
+package testspreadstructs
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/google/go-cmp/cmp"
+)
+
+// The generated RenderAttributes method implements templ.Attributer.
+var _ templ.Attributer = ButtonProps{}
+
+func TestRender(t *testing.T) {
+	title := "Home page"
+	tests := []struct {
+		name      string
+		component templ.Component
+		expected  string
+	}{
+		{
+			name: "fields with attr tags are rendered in order",
+			component: button(ButtonProps{
+				ID:       "home",
+				Href:     "/",
+				Disabled: true,
+				Title:    &title,
+				TabIndex: 2,
+				Variant:  "primary",
+				Label:    "Home",
+				internal: "hidden",
+				OnClick:  "alert(1)",
+			}),
+			expected: `<a id="home" href="/" disabled title="Home page" tabindex="2" data-variant="primary">Home</a>`,
+		},
+		{
+			name:      "URLs are sanitized",
+			component: button(ButtonProps{Href: "javascript:alert(1)"}),
+			expected:  `<a id="" href="about:invalid#TemplFailedSanitizationURL" tabindex="0" data-variant=""></a>`,
+		},
+		{
+			name:      "pointers to structs can be spread",
+			component: pointer(&ButtonProps{ID: "home", TabIndex: -1}),
+			expected:  `<a id="home" href="" tabindex="-1" data-variant=""></a>`,
+		},
+		{
+			name:      "nil pointers render no attributes",
+			component: pointer(nil),
+			expected:  `<a></a>`,
+		},
+		{
+			name:      "generic structs are rendered with reflection",
+			component: generic(Pair[string, int]{Key: "a", Value: 1}),
+			expected:  `<div data-key="a" data-value="1"></div>`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			w := new(strings.Builder)
+			if err := tt.component.Render(context.Background(), w); err != nil {
+				t.Fatalf("failed to render: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, w.String()); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package teststringconversion
+
+import (
+	_ "embed"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/a-h/templ/generator/htmldiff"
+)
+
+//go:embed expected.html
+var expected string
+
+func Test(t *testing.T) {
+	templ.RegisterStringConverter(func(t Temperature) (string, error) {
+		return strconv.FormatFloat(float64(t), 'f', 1, 64) + "°C", nil
+	})
+	component := render(42, 0.5, true, Status(1), time.Millisecond*1500, Temperature(21.5))
+
+	diff, err := htmldiff.Diff(component, expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff != "" {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,32 @@
+package testswitchfallthrough
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		role     string
+		expected string
+	}{
+		{role: "Admin", expected: `<ul><li>Delete</li><li>Edit</li><li>View as admin</li></ul>`},
+		{role: "editor", expected: `<ul><li>Edit</li><li>View as editor</li></ul>`},
+		{role: "guest", expected: `<ul><li>View as guest</li></ul>`},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.role, func(t *testing.T) {
+			w := new(strings.Builder)
+			if err := permissions(tt.role).Render(context.Background(), w); err != nil {
+				t.Fatalf("failed to render: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, w.String()); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -40,6 +40,11 @@ func TestTextWhitespace(t *testing.T) {
 			input:    WhiteSpaceAroundTemplatedValues("templ", "allows whitespace around templated values."),
 			expected: WhiteSpaceAroundTemplatedValuesExpected,
 		},
+		{
+			name:     "blank lines are normalised",
+			input:    BlankLinesAreNormalised(),
+			expected: BlankLinesAreNormalisedExpected,
+		},
 	} {
 		w := new(strings.Builder)
 		err := test.input.Render(context.Background(), w)
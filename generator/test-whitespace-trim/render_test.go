@@ -0,0 +1,41 @@
+package testwhitespacetrim
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name      string
+		component templ.Component
+		expected  string
+	}{
+		{
+			name:      "trim markers remove the whitespace around expressions",
+			component: greeting("World"),
+			expected:  `<p>Hello,World!</p>`,
+		},
+		{
+			name:      "empty expressions with trim markers remove the whitespace between elements",
+			component: links(),
+			expected:  `<a href="/1">1</a><a href="/2">2</a> <a href="/3">3</a>`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			w := new(strings.Builder)
+			if err := tt.component.Render(context.Background(), w); err != nil {
+				t.Fatalf("failed to render: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, w.String()); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
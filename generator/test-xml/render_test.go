@@ -0,0 +1,40 @@
+package testxml
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFeed(t *testing.T) {
+	posts := []post{
+		{Title: "Hello & welcome", URL: "https://example.com/hello"},
+	}
+	expected := `<?xml version="1.0" encoding="UTF-8"?>` +
+		`<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom"><channel>` +
+		`<title>Blog</title><link>https://example.com/</link>` +
+		`<atom:link href="https://example.com/feed.xml" rel="self" type="application/rss+xml"/> ` +
+		`<item><title>Hello &amp; welcome</title><link>https://example.com/hello</link>` +
+		`<source url="https://example.com/feed.xml"/></item>` +
+		`</channel></rss>`
+	w := new(strings.Builder)
+	if err := feed(posts).Render(context.Background(), w); err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+	if diff := cmp.Diff(expected, w.String()); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestPragma(t *testing.T) {
+	expected := `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 10 10"><circle cx="5" cy="5" r="4"/></svg>`
+	w := new(strings.Builder)
+	if err := icon().Render(context.Background(), w); err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+	if diff := cmp.Diff(expected, w.String()); diff != "" {
+		t.Error(diff)
+	}
+}
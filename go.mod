@@ -11,11 +11,13 @@ require (
 	github.com/andybalholm/brotli v1.1.0
 	github.com/cenkalti/backoff/v4 v4.3.0
 	github.com/cli/browser v1.3.0
+	github.com/evanw/esbuild v0.25.12
 	github.com/fatih/color v1.16.0
 	github.com/fsnotify/fsnotify v1.7.0
 	github.com/google/go-cmp v0.6.0
 	github.com/natefinch/atomic v1.0.1
 	github.com/rs/cors v1.11.0
+	github.com/yuin/goldmark v1.7.8
 	go.lsp.dev/jsonrpc2 v0.10.0
 	go.lsp.dev/uri v0.3.0
 	go.uber.org/zap v1.27.0
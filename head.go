@@ -0,0 +1,247 @@
+package templ
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"sync"
+)
+
+// headMarker is written by HeadContents, and replaced with the registered tags
+// once the page has been rendered. Text is escaped by templ, so the marker
+// can't be written by page content, other than by raw HTML.
+const headMarker = "<!--templ:head-contents-->"
+
+type headContextKeyType int
+
+const headContextKey = headContextKeyType(0)
+
+type headKind int
+
+const (
+	headKindTitle headKind = iota
+	headKindMeta
+	headKindLink
+	headKindScript
+	headKindCount
+)
+
+type headTag struct {
+	key  string
+	html string
+}
+
+// headManager collects the tags registered by components during rendering.
+type headManager struct {
+	m    sync.Mutex
+	tags [headKindCount][]headTag
+	// frozen is set after the collect pass of a TwoPass render, when the tags
+	// have all been registered.
+	frozen bool
+}
+
+func (hm *headManager) freeze() {
+	hm.m.Lock()
+	defer hm.m.Unlock()
+	hm.frozen = true
+}
+
+func (hm *headManager) isFrozen() bool {
+	hm.m.Lock()
+	defer hm.m.Unlock()
+	return hm.frozen
+}
+
+// add registers a tag. If a tag of the same kind with the same key has been
+// registered, it's replaced, keeping its position.
+func (hm *headManager) add(kind headKind, key, html string) {
+	hm.m.Lock()
+	defer hm.m.Unlock()
+	if hm.frozen {
+		return
+	}
+	for i, t := range hm.tags[kind] {
+		if t.key == key {
+			hm.tags[kind][i].html = html
+			return
+		}
+	}
+	hm.tags[kind] = append(hm.tags[kind], headTag{key: key, html: html})
+}
+
+func (hm *headManager) String() string {
+	hm.m.Lock()
+	defer hm.m.Unlock()
+	var sb strings.Builder
+	for _, tags := range hm.tags {
+		for _, t := range tags {
+			sb.WriteString(t.html)
+		}
+	}
+	return sb.String()
+}
+
+func getHeadManager(ctx context.Context) *headManager {
+	hm, _ := ctx.Value(headContextKey).(*headManager)
+	return hm
+}
+
+// HeadManager returns a component that renders c, and writes the title, meta,
+// link and script tags registered by HeadTitle, HeadMeta, HeadLink and
+// HeadScript during rendering in place of HeadContents.
+//
+// The output of c is buffered until rendering is complete.
+func HeadManager(c Component) Component {
+	return ComponentFunc(func(ctx context.Context, w io.Writer) (err error) {
+		if getHeadManager(ctx) != nil {
+			// An outer head manager collects the tags.
+			return c.Render(ctx, w)
+		}
+		hm := &headManager{}
+		ctx = context.WithValue(ctx, headContextKey, hm)
+		buf := GetBuffer()
+		defer ReleaseBuffer(buf)
+		if err = c.Render(ctx, buf); err != nil {
+			return err
+		}
+		output := buf.Bytes()
+		if i := bytes.Index(output, []byte(headMarker)); i >= 0 {
+			if _, err = w.Write(output[:i]); err != nil {
+				return err
+			}
+			if _, err = io.WriteString(w, hm.String()); err != nil {
+				return err
+			}
+			output = output[i+len(headMarker):]
+		}
+		_, err = w.Write(output)
+		return err
+	})
+}
+
+// HeadContents renders the tags registered during rendering. It should be
+// placed within the <head> element of a layout that's rendered by HeadManager
+// or TwoPass.
+//
+// Outside of HeadManager and TwoPass, it renders nothing.
+func HeadContents() Component {
+	return ComponentFunc(func(ctx context.Context, w io.Writer) (err error) {
+		hm := getHeadManager(ctx)
+		if hm == nil {
+			return nil
+		}
+		if hm.isFrozen() {
+			// Within TwoPass, the tags were registered during the collect pass.
+			_, err = io.WriteString(w, hm.String())
+			return err
+		}
+		_, err = io.WriteString(w, headMarker)
+		return err
+	})
+}
+
+// HeadTitle registers the <title> of the page. If more than one title is
+// registered, the last one is used, so pages can replace a title set by their
+// layout.
+//
+// Outside of HeadManager and TwoPass, the tag is rendered in place.
+func HeadTitle(title string) Component {
+	return headTagComponent(headKindTitle, func(Attributes) string { return "title" }, "<title>"+EscapeString(title)+"</title>", nil)
+}
+
+// HeadMeta registers a <meta> tag. Tags with the same name, property,
+// http-equiv, charset or itemprop attribute replace earlier tags.
+//
+// Outside of HeadManager and TwoPass, the tag is rendered in place.
+func HeadMeta(attrs Attributes) Component {
+	return headTagComponent(headKindMeta, metaKey, "<meta", attrs)
+}
+
+// HeadLink registers a <link> tag. Tags with the same rel and href replace
+// earlier tags, and only one canonical link is kept.
+//
+// Outside of HeadManager and TwoPass, the tag is rendered in place.
+func HeadLink(attrs Attributes) Component {
+	return headTagComponent(headKindLink, linkKey, "<link", attrs)
+}
+
+// HeadScript registers an external <script> tag. Tags with the same src
+// replace earlier tags. If a CSP nonce is set in the context, it's added.
+//
+// Outside of HeadManager and TwoPass, the tag is rendered in place.
+func HeadScript(attrs Attributes) Component {
+	return headTagComponent(headKindScript, scriptKey, "<script", attrs)
+}
+
+func headTagComponent(kind headKind, key func(Attributes) string, start string, attrs Attributes) Component {
+	return ComponentFunc(func(ctx context.Context, w io.Writer) (err error) {
+		html := start
+		if attrs != nil {
+			if kind == headKindScript {
+				if nonce := GetNonce(ctx); nonce != "" {
+					if _, ok := attrs["nonce"]; !ok {
+						attrs = cloneAttributes(attrs)
+						attrs["nonce"] = nonce
+					}
+				}
+			}
+			var sb strings.Builder
+			sb.WriteString(start)
+			if err = RenderAttributes(ctx, &sb, attrs); err != nil {
+				return err
+			}
+			sb.WriteString(">")
+			if kind == headKindScript {
+				sb.WriteString("</script>")
+			}
+			html = sb.String()
+		}
+		hm := getHeadManager(ctx)
+		if hm == nil {
+			_, err = io.WriteString(w, html)
+			return err
+		}
+		k := key(attrs)
+		if k == "" {
+			// Tags without a key are only deduplicated if they're identical.
+			k = html
+		}
+		hm.add(kind, k, html)
+		return nil
+	})
+}
+
+func cloneAttributes(attrs Attributes) Attributes {
+	clone := make(Attributes, len(attrs)+1)
+	for k, v := range attrs {
+		clone[k] = v
+	}
+	return clone
+}
+
+func attributeString(attrs Attributes, name string) string {
+	s, _ := attrs[name].(string)
+	return s
+}
+
+func metaKey(attrs Attributes) string {
+	for _, name := range []string{"charset", "name", "property", "http-equiv", "itemprop"} {
+		if _, ok := attrs[name]; ok {
+			return name + "=" + attributeString(attrs, name)
+		}
+	}
+	return ""
+}
+
+func linkKey(attrs Attributes) string {
+	rel := attributeString(attrs, "rel")
+	if rel == "canonical" {
+		return rel
+	}
+	return rel + " " + attributeString(attrs, "href")
+}
+
+func scriptKey(attrs Attributes) string {
+	return attributeString(attrs, "src")
+}
@@ -0,0 +1,106 @@
+package templ_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestHeadManager(t *testing.T) {
+	text := func(s string) templ.Component {
+		return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			_, err := io.WriteString(w, s)
+			return err
+		})
+	}
+	join := func(components ...templ.Component) templ.Component {
+		return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			for _, c := range components {
+				if err := c.Render(ctx, w); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	layout := func(body templ.Component) templ.Component {
+		return join(
+			text("<html><head>"),
+			templ.HeadTitle("Site"),
+			templ.HeadMeta(templ.Attributes{"name": "description", "content": "A site"}),
+			templ.HeadContents(),
+			text("</head><body>"),
+			body,
+			text("</body></html>"),
+		)
+	}
+	page := join(
+		templ.HeadTitle("Page & more"),
+		templ.HeadScript(templ.Attributes{"src": "/app.js", "defer": true}),
+		templ.HeadLink(templ.Attributes{"rel": "stylesheet", "href": "/page.css"}),
+		templ.HeadMeta(templ.Attributes{"name": "description", "content": "A page"}),
+		templ.HeadMeta(templ.Attributes{"property": "og:title", "content": "Page"}),
+		text("<h1>Page</h1>"),
+		templ.HeadScript(templ.Attributes{"src": "/app.js", "defer": true}),
+		templ.HeadLink(templ.Attributes{"rel": "stylesheet", "href": "/page.css"}),
+	)
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		c        templ.Component
+		expected string
+	}{
+		{
+			name: "tags registered by nested components are written in the head",
+			c:    templ.HeadManager(layout(page)),
+			expected: `<html><head>` +
+				`<title>Page &amp; more</title>` +
+				`<meta content="A page" name="description">` +
+				`<meta content="Page" property="og:title">` +
+				`<link href="/page.css" rel="stylesheet">` +
+				`<script defer src="/app.js"></script>` +
+				`</head><body><h1>Page</h1></body></html>`,
+		},
+		{
+			name: "scripts use the nonce from the context",
+			ctx:  templ.WithNonce(context.Background(), "abc"),
+			c: templ.HeadManager(join(
+				templ.HeadContents(),
+				templ.HeadScript(templ.Attributes{"src": "/app.js"}),
+			)),
+			expected: `<script nonce="abc" src="/app.js"></script>`,
+		},
+		{
+			name: "nested head managers use the outer manager",
+			c: templ.HeadManager(join(
+				templ.HeadContents(),
+				templ.HeadManager(templ.HeadTitle("Inner")),
+			)),
+			expected: `<title>Inner</title>`,
+		},
+		{
+			name:     "without a head manager, tags are rendered in place",
+			c:        join(templ.HeadContents(), templ.HeadTitle("Title"), templ.HeadLink(templ.Attributes{"rel": "icon", "href": "/favicon.ico"})),
+			expected: `<title>Title</title><link href="/favicon.ico" rel="icon">`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := tt.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			w := new(bytes.Buffer)
+			if err := tt.c.Render(ctx, w); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, w.String()); diff != "" {
+				t.Errorf("unexpected output (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
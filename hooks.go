@@ -0,0 +1,120 @@
+package templ
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// RenderHooks are called as components are rendered, e.g. to report the
+// progress of a long page or export to the browser over server-sent events.
+//
+// Components call the hooks if they're generated by templ generate with the
+// -render-hooks flag, or in watch mode.
+type RenderHooks struct {
+	// ComponentStart is called when a component starts rendering.
+	ComponentStart func(ctx context.Context, e ComponentEvent)
+	// ComponentEnd is called when a component has been rendered. The event
+	// includes the duration and the number of bytes written.
+	ComponentEnd func(ctx context.Context, e ComponentEvent)
+	// Written is called after output is written to a writer returned by
+	// HookWriter, with the number of bytes written, and the total so far.
+	Written func(ctx context.Context, n int, total int64)
+}
+
+// ComponentEvent describes a component passed to RenderHooks.
+type ComponentEvent struct {
+	// Name of the component, e.g. "header".
+	Name string
+	// Location of the component's template, e.g. "components.templ:12".
+	Location string
+	// Depth of the component, 0 for the first component rendered with the
+	// hooks, 1 for its children, and so on.
+	Depth int
+	// Duration of the render, set in ComponentEnd.
+	Duration time.Duration
+	// Bytes written by the component, set in ComponentEnd.
+	Bytes int
+}
+
+type renderHooksContextKey struct{}
+
+type hooksFrame struct {
+	hooks *RenderHooks
+	depth int
+}
+
+// WithRenderHooks calls the hooks as components are rendered using the context.
+func WithRenderHooks(ctx context.Context, hooks RenderHooks) context.Context {
+	return context.WithValue(ctx, renderHooksContextKey{}, &hooksFrame{hooks: &hooks})
+}
+
+// hooksStart calls the ComponentStart hook of the context, if there is one,
+// and returns a function that calls the ComponentEnd hook.
+func hooksStart(ctx context.Context, name, location string, w *bytes.Buffer) (context.Context, func()) {
+	f, ok := ctx.Value(renderHooksContextKey{}).(*hooksFrame)
+	if !ok {
+		return ctx, func() {}
+	}
+	e := ComponentEvent{
+		Name:     name,
+		Location: location,
+		Depth:    f.depth,
+	}
+	if f.hooks.ComponentStart != nil {
+		f.hooks.ComponentStart(ctx, e)
+	}
+	start := time.Now()
+	startLen := w.Len()
+	ctx = context.WithValue(ctx, renderHooksContextKey{}, &hooksFrame{hooks: f.hooks, depth: f.depth + 1})
+	return ctx, func() {
+		if f.hooks.ComponentEnd == nil {
+			return
+		}
+		e.Duration = time.Since(start)
+		e.Bytes = w.Len() - startLen
+		f.hooks.ComponentEnd(ctx, e)
+	}
+}
+
+// HookWriter returns a writer that calls the Written hook of the context
+// after each write to w. If w is a http.Flusher, so is the returned writer.
+//
+// Components buffer their output, and write it once they've been rendered,
+// so to report progress of a streamed page, write each section of the page
+// to the writer, and flush it.
+func HookWriter(ctx context.Context, w io.Writer) io.Writer {
+	f, ok := ctx.Value(renderHooksContextKey{}).(*hooksFrame)
+	if !ok || f.hooks.Written == nil {
+		return w
+	}
+	hw := &hookWriter{ctx: ctx, w: w, written: f.hooks.Written}
+	if _, ok := w.(http.Flusher); ok {
+		return flushingHookWriter{hw}
+	}
+	return hw
+}
+
+type hookWriter struct {
+	ctx     context.Context
+	w       io.Writer
+	written func(ctx context.Context, n int, total int64)
+	total   int64
+}
+
+func (hw *hookWriter) Write(p []byte) (n int, err error) {
+	n, err = hw.w.Write(p)
+	hw.total += int64(n)
+	hw.written(hw.ctx, n, hw.total)
+	return n, err
+}
+
+type flushingHookWriter struct {
+	*hookWriter
+}
+
+func (fw flushingHookWriter) Flush() {
+	fw.w.(http.Flusher).Flush()
+}
@@ -0,0 +1,100 @@
+package templ_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRenderHooks(t *testing.T) {
+	// traced behaves like a component generated with the -render-hooks flag.
+	traced := func(name string, children ...templ.Component) templ.Component {
+		return templ.ComponentFunc(func(ctx context.Context, w io.Writer) (err error) {
+			buf, isBuffer := w.(*bytes.Buffer)
+			if !isBuffer {
+				buf = new(bytes.Buffer)
+				defer buf.WriteTo(w)
+			}
+			ctx, end := templ.TraceStart(ctx, name, name+".templ:1", buf)
+			buf.WriteString("<" + name + ">")
+			for _, c := range children {
+				if err = c.Render(ctx, buf); err != nil {
+					return err
+				}
+			}
+			buf.WriteString("</" + name + ">")
+			end()
+			return nil
+		})
+	}
+	var events []string
+	ctx := templ.WithRenderHooks(context.Background(), templ.RenderHooks{
+		ComponentStart: func(ctx context.Context, e templ.ComponentEvent) {
+			events = append(events, fmt.Sprintf("start %s %s %d", e.Name, e.Location, e.Depth))
+		},
+		ComponentEnd: func(ctx context.Context, e templ.ComponentEvent) {
+			events = append(events, fmt.Sprintf("end %s %d %d", e.Name, e.Depth, e.Bytes))
+		},
+	})
+	page := traced("page", traced("a"), traced("b"))
+	if err := page.Render(ctx, new(bytes.Buffer)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{
+		"start page page.templ:1 0",
+		"start a a.templ:1 1",
+		"end a 1 7",
+		"start b b.templ:1 1",
+		"end b 1 7",
+		"end page 0 27",
+	}
+	if diff := cmp.Diff(expected, events); diff != "" {
+		t.Error(diff)
+	}
+
+	t.Run("hooks are not called in the collect pass of a two-pass render", func(t *testing.T) {
+		events = nil
+		if err := templ.TwoPass(traced("a")).Render(ctx, new(bytes.Buffer)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if diff := cmp.Diff([]string{"start a a.templ:1 0", "end a 0 7"}, events); diff != "" {
+			t.Error(diff)
+		}
+	})
+}
+
+func TestHookWriter(t *testing.T) {
+	var written []string
+	ctx := templ.WithRenderHooks(context.Background(), templ.RenderHooks{
+		Written: func(ctx context.Context, n int, total int64) {
+			written = append(written, fmt.Sprintf("%d %d", n, total))
+		},
+	})
+	rec := httptest.NewRecorder()
+	w := templ.HookWriter(ctx, rec)
+	io.WriteString(w, "<p>")
+	w.(http.Flusher).Flush()
+	io.WriteString(w, "Hello</p>")
+	if diff := cmp.Diff([]string{"3 3", "9 12"}, written); diff != "" {
+		t.Error(diff)
+	}
+	if !rec.Flushed {
+		t.Error("expected the writer to be flushed")
+	}
+	if rec.Body.String() != "<p>Hello</p>" {
+		t.Errorf("unexpected output: %q", rec.Body.String())
+	}
+	t.Run("without hooks, the writer is returned", func(t *testing.T) {
+		var buf bytes.Buffer
+		if w := templ.HookWriter(context.Background(), &buf); w != &buf {
+			t.Error("expected the writer to be returned")
+		}
+	})
+}
@@ -0,0 +1,39 @@
+package templ
+
+import (
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+// HotReloader returns a component that renders the latest version of the
+// named template of a templ file, if the template has changed since the
+// program was built. If it hasn't, ok is false, and the compiled code is used.
+type HotReloader func(templFileName, name string, args ...any) (c Component, ok bool)
+
+var hotReloader atomic.Pointer[HotReloader]
+
+// SetHotReloader sets the HotReloader used by HotReload. The interpreter
+// package sets it when the program is built with the templ_hotreload build tag.
+func SetHotReloader(r HotReloader) {
+	hotReloader.Store(&r)
+}
+
+// HotReload is used by code generated with templ generate -watch -hot-reload
+// to look up the latest version of a component, so that changes to templates
+// are shown without restarting the program. It always returns false unless
+// the program is built with the templ_hotreload build tag.
+func HotReload(name string, args ...any) (c Component, ok bool) {
+	if !hotReloadEnabled {
+		return nil, false
+	}
+	r := hotReloader.Load()
+	if r == nil {
+		return nil, false
+	}
+	_, goFileName, _, ok := runtime.Caller(1)
+	if !ok || !strings.HasSuffix(goFileName, "_templ.go") {
+		return nil, false
+	}
+	return (*r)(strings.TrimSuffix(goFileName, "_templ.go")+".templ", name, args...)
+}
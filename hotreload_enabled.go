@@ -0,0 +1,5 @@
+//go:build templ_hotreload
+
+package templ
+
+const hotReloadEnabled = true
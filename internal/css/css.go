@@ -0,0 +1,37 @@
+// Package css minifies the CSS of css components and <style> elements.
+package css
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+// Minify removes the whitespace and comments of the CSS source, and shortens
+// values where it doesn't change their meaning, e.g. #ffffff becomes #fff.
+// Selectors and property names aren't renamed.
+func Minify(src string) (string, error) {
+	result := api.Transform(src, api.TransformOptions{
+		Loader:           api.LoaderCSS,
+		MinifyWhitespace: true,
+		MinifySyntax:     true,
+		Charset:          api.CharsetUTF8,
+	})
+	if len(result.Errors) > 0 {
+		errs := make([]error, len(result.Errors))
+		for i, msg := range result.Errors {
+			errs[i] = formatMessage(msg)
+		}
+		return "", errors.Join(errs...)
+	}
+	return string(result.Code), nil
+}
+
+func formatMessage(msg api.Message) error {
+	if msg.Location == nil {
+		return errors.New(msg.Text)
+	}
+	// Lines are 1-based, and columns are 0-based.
+	return fmt.Errorf("%d:%d: %s", msg.Location.Line, msg.Location.Column+1, msg.Text)
+}
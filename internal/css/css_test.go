@@ -0,0 +1,38 @@
+package css
+
+import (
+	"testing"
+)
+
+func TestMinify(t *testing.T) {
+	t.Run("whitespace and comments are removed", func(t *testing.T) {
+		src := `
+			/* Cards. */
+			.card > .title {
+				margin: 0px 0px;
+				color: #FFFFFF;
+			}
+			@media (min-width: 768px) {
+				.card { padding: 1.5em }
+			}
+		`
+		actual, err := Minify(src)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := `.card>.title{margin:0;color:#fff}@media(min-width:768px){.card{padding:1.5em}}` + "\n"
+		if actual != expected {
+			t.Errorf("expected %q, got %q", expected, actual)
+		}
+	})
+	t.Run("strings are unchanged", func(t *testing.T) {
+		actual, err := Minify(`a::before { content: "Hello,   ü"; }`)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := `a:before{content:"Hello,   ü"}` + "\n"
+		if actual != expected {
+			t.Errorf("expected %q, got %q", expected, actual)
+		}
+	})
+}
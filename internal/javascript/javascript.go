@@ -0,0 +1,36 @@
+// Package javascript minifies the JavaScript within <script> elements.
+package javascript
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+// Minify removes the whitespace and comments of the JavaScript source.
+// Identifiers aren't renamed, and literals aren't rewritten, so the contents
+// of strings and template literals are unchanged.
+func Minify(src string) (string, error) {
+	result := api.Transform(src, api.TransformOptions{
+		Loader:           api.LoaderJS,
+		MinifyWhitespace: true,
+		Charset:          api.CharsetUTF8,
+	})
+	if len(result.Errors) > 0 {
+		errs := make([]error, len(result.Errors))
+		for i, msg := range result.Errors {
+			errs[i] = formatMessage(msg)
+		}
+		return "", errors.Join(errs...)
+	}
+	return string(result.Code), nil
+}
+
+func formatMessage(msg api.Message) error {
+	if msg.Location == nil {
+		return errors.New(msg.Text)
+	}
+	// Lines are 1-based, and columns are 0-based.
+	return fmt.Errorf("%d:%d: %s", msg.Location.Line, msg.Location.Column+1, msg.Text)
+}
@@ -0,0 +1,45 @@
+package javascript
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMinify(t *testing.T) {
+	t.Run("whitespace and comments are removed", func(t *testing.T) {
+		src := `
+			// Greet the user.
+			function greet(name) {
+				/* Say hello. */
+				return "Hello,   " + name;
+			}
+			console.log(greet("World"));
+		`
+		actual, err := Minify(src)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := `function greet(name){return"Hello,   "+name}console.log(greet("World"));` + "\n"
+		if actual != expected {
+			t.Errorf("expected %q, got %q", expected, actual)
+		}
+	})
+	t.Run("template literals are unchanged", func(t *testing.T) {
+		actual, err := Minify("const msg = `Hello,   templ_placeholder ü`;")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(actual, "`Hello,   templ_placeholder ü`") {
+			t.Errorf("expected the template literal to be unchanged, got %q", actual)
+		}
+	})
+	t.Run("syntax errors include the position", func(t *testing.T) {
+		_, err := Minify("const a = 1;\nconst b = ;")
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+		if !strings.HasPrefix(err.Error(), "2:11: ") {
+			t.Errorf("expected the error to start with the position, got %q", err.Error())
+		}
+	})
+}
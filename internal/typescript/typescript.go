@@ -0,0 +1,35 @@
+// Package typescript transpiles the TypeScript within <script> elements to
+// JavaScript, so that it can be run by browsers.
+package typescript
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+// Transpile the TypeScript source to JavaScript. Types are removed, but newer
+// syntax isn't rewritten, so the output runs in the browsers that support
+// the syntax used.
+func Transpile(src string) (string, error) {
+	result := api.Transform(src, api.TransformOptions{
+		Loader: api.LoaderTS,
+	})
+	if len(result.Errors) > 0 {
+		errs := make([]error, len(result.Errors))
+		for i, msg := range result.Errors {
+			errs[i] = formatMessage(msg)
+		}
+		return "", errors.Join(errs...)
+	}
+	return string(result.Code), nil
+}
+
+func formatMessage(msg api.Message) error {
+	if msg.Location == nil {
+		return errors.New(msg.Text)
+	}
+	// Lines are 1-based, and columns are 0-based.
+	return fmt.Errorf("%d:%d: %s", msg.Location.Line, msg.Location.Column+1, msg.Text)
+}
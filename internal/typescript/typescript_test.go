@@ -0,0 +1,38 @@
+package typescript
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTranspile(t *testing.T) {
+	t.Run("types are removed", func(t *testing.T) {
+		src := `
+			interface User { name: string }
+			const user: User = { name: "Alice" };
+			function greet(u: User): string { return "Hello " + u.name; }
+			console.log(greet(user));
+		`
+		actual, err := Transpile(src)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, unexpected := range []string{"interface", ": User", ": string"} {
+			if strings.Contains(actual, unexpected) {
+				t.Errorf("expected %q to be removed, got:\n%s", unexpected, actual)
+			}
+		}
+		if !strings.Contains(actual, `console.log(greet(user));`) {
+			t.Errorf("expected the code to be kept, got:\n%s", actual)
+		}
+	})
+	t.Run("syntax errors include the position", func(t *testing.T) {
+		_, err := Transpile("const a = 1;\nconst b: = 2;")
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+		if !strings.HasPrefix(err.Error(), "2:10: ") {
+			t.Errorf("expected the error to start with the position, got %q", err.Error())
+		}
+	})
+}
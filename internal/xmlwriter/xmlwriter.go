@@ -0,0 +1,132 @@
+// Package xmlwriter writes the XML documents rendered by the feed and sitemap
+// packages. templ templates are parsed as HTML, where elements such as <link>
+// are void, so XML documents are written directly instead.
+package xmlwriter
+
+import (
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Declaration is the XML declaration written at the start of documents.
+const Declaration = `<?xml version="1.0" encoding="UTF-8"?>` + "\n"
+
+// Attr is an attribute of an element. Attributes with an empty value aren't written.
+type Attr struct {
+	Name  string
+	Value string
+}
+
+// Writer writes XML elements. After the first error, writes are ignored, and
+// the error is returned by Err.
+type Writer struct {
+	w   io.Writer
+	err error
+}
+
+// New creates a Writer that writes to w.
+func New(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// Err returns the first error that occurred while writing.
+func (w *Writer) Err() error {
+	return w.err
+}
+
+// Raw writes s without escaping.
+func (w *Writer) Raw(s string) {
+	if w.err != nil {
+		return
+	}
+	_, w.err = io.WriteString(w.w, s)
+}
+
+// escape writes s with the XML special characters escaped. Unlike
+// xml.EscapeText, newlines are kept. Characters that aren't allowed in XML
+// documents are replaced with U+FFFD.
+func (w *Writer) escape(s string) {
+	var sb strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '&':
+			sb.WriteString("&amp;")
+		case r == '<':
+			sb.WriteString("&lt;")
+		case r == '>':
+			sb.WriteString("&gt;")
+		case r == '"':
+			sb.WriteString("&#34;")
+		case r == '\'':
+			sb.WriteString("&#39;")
+		case !isXMLChar(r):
+			sb.WriteRune(utf8.RuneError)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	w.Raw(sb.String())
+}
+
+// isXMLChar returns true if the rune is in the Char production of the XML specification.
+func isXMLChar(r rune) bool {
+	return r == 0x09 || r == 0x0A || r == 0x0D ||
+		r >= 0x20 && r <= 0xD7FF ||
+		r >= 0xE000 && r <= 0xFFFD ||
+		r >= 0x10000 && r <= 0x10FFFF
+}
+
+func (w *Writer) open(name string, attrs []Attr) {
+	w.Raw("<" + name)
+	for _, a := range attrs {
+		if a.Value == "" {
+			continue
+		}
+		w.Raw(" " + a.Name + `="`)
+		w.escape(a.Value)
+		w.Raw(`"`)
+	}
+}
+
+// Start writes the start tag of an element.
+func (w *Writer) Start(name string, attrs ...Attr) {
+	w.open(name, attrs)
+	w.Raw(">")
+}
+
+// End writes the end tag of an element.
+func (w *Writer) End(name string) {
+	w.Raw("</" + name + ">")
+}
+
+// Empty writes a self-closing element.
+func (w *Writer) Empty(name string, attrs ...Attr) {
+	w.open(name, attrs)
+	w.Raw("/>")
+}
+
+// Text writes an element that contains the escaped value. Nothing is written
+// if the value is empty.
+func (w *Writer) Text(name, value string, attrs ...Attr) {
+	if value == "" {
+		return
+	}
+	w.Start(name, attrs...)
+	w.escape(value)
+	w.End(name)
+}
+
+// CDATA writes an element that contains the value in a CDATA section, which
+// is used for HTML content. Nothing is written if the value is empty.
+func (w *Writer) CDATA(name, value string, attrs ...Attr) {
+	if value == "" {
+		return
+	}
+	w.Start(name, attrs...)
+	w.Raw("<![CDATA[")
+	// A CDATA section can't contain its terminator, so split it across two sections.
+	w.Raw(strings.ReplaceAll(value, "]]>", "]]]]><![CDATA[>"))
+	w.Raw("]]>")
+	w.End(name)
+}
@@ -0,0 +1,24 @@
+package xmlwriter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	var sb strings.Builder
+	w := New(&sb)
+	w.Start("a", Attr{Name: "title", Value: `"quoted"`}, Attr{Name: "empty"})
+	w.Text("b", "1 < 2\nand \x00")
+	w.Text("c", "")
+	w.CDATA("d", "<p>]]></p>")
+	w.Empty("e")
+	w.End("a")
+	if err := w.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `<a title="&#34;quoted&#34;"><b>1 &lt; 2` + "\n" + `and ` + "�" + `</b><d><![CDATA[<p>]]]]><![CDATA[></p>]]></d><e/></a>`
+	if sb.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, sb.String())
+	}
+}
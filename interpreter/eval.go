@@ -0,0 +1,798 @@
+package interpreter
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/a-h/templ"
+	"github.com/a-h/templ/parser/v2"
+)
+
+// scope holds the variables that are visible to an expression.
+type scope struct {
+	vars   map[string]reflect.Value
+	parent *scope
+}
+
+func (s *scope) child() *scope {
+	return &scope{vars: map[string]reflect.Value{}, parent: s}
+}
+
+func (s *scope) lookup(name string) (v reflect.Value, ok bool) {
+	for ; s != nil; s = s.parent {
+		if v, ok = s.vars[name]; ok {
+			return v, true
+		}
+	}
+	return v, false
+}
+
+func (s *scope) has(name string) bool {
+	_, ok := s.lookup(name)
+	return ok
+}
+
+func emptyFileSet() *token.FileSet {
+	return token.NewFileSet()
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// valueInterface returns the value as an interface, or nil if the value is invalid.
+func valueInterface(v reflect.Value) any {
+	if !v.IsValid() || !v.CanInterface() {
+		return nil
+	}
+	return v.Interface()
+}
+
+func typeName(v reflect.Value) string {
+	if !v.IsValid() {
+		return "nil"
+	}
+	return v.Type().String()
+}
+
+// eval parses and evaluates a Go expression.
+func (r *renderer) eval(s *scope, e parser.Expression) (v reflect.Value, err error) {
+	expr, err := goparser.ParseExpr(e.Value)
+	if err != nil {
+		return v, r.errorAt(e, err)
+	}
+	return r.evalExpr(s, e, expr)
+}
+
+func (r *renderer) evalBool(s *scope, e parser.Expression) (ok bool, err error) {
+	v, err := r.eval(s, e)
+	if err != nil {
+		return false, err
+	}
+	if !v.IsValid() || v.Kind() != reflect.Bool {
+		return false, r.errorAt(e, fmt.Errorf("expected a bool, got %s", typeName(v)))
+	}
+	return v.Bool(), nil
+}
+
+// evalClasses evaluates the arguments of a class attribute expression, e.g.
+// `"btn", "btn-primary": isPrimary`, where each "name": condition pair is
+// equivalent to templ.KV("name", condition).
+func (r *renderer) evalClasses(s *scope, e parser.Expression) (classes []any, err error) {
+	expr, err := goparser.ParseExpr("[]any{" + e.Value + "}")
+	if err != nil {
+		return nil, r.errorAt(e, err)
+	}
+	for _, elt := range expr.(*ast.CompositeLit).Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			v, err := r.evalExpr(s, e, elt)
+			if err != nil {
+				return nil, err
+			}
+			classes = append(classes, valueInterface(v))
+			continue
+		}
+		name, err := r.evalExpr(s, e, kv.Key)
+		if err != nil {
+			return nil, err
+		}
+		if name.Kind() != reflect.String {
+			return nil, r.errorAt(e, fmt.Errorf("class names must be strings, got %s", typeName(name)))
+		}
+		cond, err := r.evalExpr(s, e, kv.Value)
+		if err != nil {
+			return nil, err
+		}
+		if cond.Kind() != reflect.Bool {
+			return nil, r.errorAt(e, fmt.Errorf("expected a bool, got %s", typeName(cond)))
+		}
+		classes = append(classes, templ.KV(name.String(), cond.Bool()))
+	}
+	return classes, nil
+}
+
+// evalIf evaluates the expression of an if statement, e.g. `ok`, or
+// `v, ok := m[k]; ok`. The variables declared by the init statement are in the
+// returned scope, so that they can be used by the branches of the statement.
+func (r *renderer) evalIf(s *scope, e parser.Expression) (ok bool, is *scope, err error) {
+	stmt, err := parseStatement("if " + e.Value + " {}")
+	if err != nil {
+		return false, s, r.errorAt(e, err)
+	}
+	ifStmt := stmt.(*ast.IfStmt)
+	if ifStmt.Init != nil {
+		s = s.child()
+		if err = r.evalInit(s, e, ifStmt.Init); err != nil {
+			return false, s, err
+		}
+	}
+	v, err := r.evalExpr(s, e, ifStmt.Cond)
+	if err != nil {
+		return false, s, err
+	}
+	if !v.IsValid() || v.Kind() != reflect.Bool {
+		return false, s, r.errorAt(e, fmt.Errorf("expected a bool, got %s", typeName(v)))
+	}
+	return v.Bool(), s, nil
+}
+
+// evalInit evaluates the init statement of an if statement, which declares
+// variables in s, e.g. `v := f()`, or `v, ok := m[k]`.
+func (r *renderer) evalInit(s *scope, e parser.Expression, init ast.Stmt) (err error) {
+	as, ok := init.(*ast.AssignStmt)
+	if !ok || as.Tok != token.DEFINE {
+		return r.errorAt(e, errors.New("only init statements that declare variables, e.g. `v, ok := m[k]`, are supported by the interpreter"))
+	}
+	var values []reflect.Value
+	switch {
+	case len(as.Lhs) == len(as.Rhs):
+		if values, err = r.evalArgs(s, e, as.Rhs); err != nil {
+			return err
+		}
+	case len(as.Lhs) == 2 && len(as.Rhs) == 1:
+		if values, err = r.evalCommaOk(s, e, as.Rhs[0]); err != nil {
+			return err
+		}
+	default:
+		return r.errorAt(e, fmt.Errorf("assignment mismatch: %d variables but %d values", len(as.Lhs), len(as.Rhs)))
+	}
+	for i, lhs := range as.Lhs {
+		if id, ok := lhs.(*ast.Ident); ok && id.Name != "_" {
+			s.vars[id.Name] = values[i]
+		}
+	}
+	return nil
+}
+
+// evalCommaOk evaluates a map lookup that returns whether the key is present,
+// e.g. `v, ok := m[k]`.
+func (r *renderer) evalCommaOk(s *scope, e parser.Expression, expr ast.Expr) (values []reflect.Value, err error) {
+	index, ok := expr.(*ast.IndexExpr)
+	if !ok {
+		return nil, r.errorAt(e, errors.New("only map lookups, e.g. `v, ok := m[k]`, can return two values in the interpreter"))
+	}
+	m, err := r.evalExpr(s, e, index.X)
+	if err != nil {
+		return nil, err
+	}
+	if m = indirect(m); m.Kind() != reflect.Map {
+		return nil, r.errorAt(e, fmt.Errorf("cannot use %s in a comma-ok lookup", typeName(m)))
+	}
+	key, err := r.evalExpr(s, e, index.Index)
+	if err != nil {
+		return nil, err
+	}
+	if key, err = convert(key, m.Type().Key()); err != nil {
+		return nil, r.errorAt(e, err)
+	}
+	v := m.MapIndex(key)
+	if !v.IsValid() {
+		return []reflect.Value{reflect.Zero(m.Type().Elem()), reflect.ValueOf(false)}, nil
+	}
+	return []reflect.Value{v, reflect.ValueOf(true)}, nil
+}
+
+// evalExpr evaluates an expression that was parsed from e.
+func (r *renderer) evalExpr(s *scope, e parser.Expression, expr ast.Expr) (v reflect.Value, err error) {
+	v, err = r.evalAST(s, expr)
+	return v, r.errorAt(e, err)
+}
+
+func (r *renderer) evalArgs(s *scope, e parser.Expression, exprs []ast.Expr) (args []reflect.Value, err error) {
+	args = make([]reflect.Value, len(exprs))
+	for i, expr := range exprs {
+		if args[i], err = r.evalExpr(s, e, expr); err != nil {
+			return nil, err
+		}
+	}
+	return args, nil
+}
+
+func (r *renderer) evalAST(s *scope, expr ast.Expr) (v reflect.Value, err error) {
+	switch expr := expr.(type) {
+	case *ast.BasicLit:
+		return evalLiteral(expr)
+	case *ast.Ident:
+		switch expr.Name {
+		case "true":
+			return reflect.ValueOf(true), nil
+		case "false":
+			return reflect.ValueOf(false), nil
+		case "nil":
+			return reflect.Value{}, nil
+		}
+		if v, ok := s.lookup(expr.Name); ok {
+			return v, nil
+		}
+		if f, ok := r.in.Funcs[expr.Name]; ok {
+			return reflect.ValueOf(f), nil
+		}
+		return v, fmt.Errorf("undefined: %s", expr.Name)
+	case *ast.ParenExpr:
+		return r.evalAST(s, expr.X)
+	case *ast.SelectorExpr:
+		if pkg, ok := expr.X.(*ast.Ident); ok && !s.has(pkg.Name) {
+			if f, ok := r.in.Funcs[pkg.Name+"."+expr.Sel.Name]; ok {
+				return reflect.ValueOf(f), nil
+			}
+			return v, fmt.Errorf("undefined: %s.%s", pkg.Name, expr.Sel.Name)
+		}
+		x, err := r.evalAST(s, expr.X)
+		if err != nil {
+			return v, err
+		}
+		return selectField(x, expr.Sel.Name)
+	case *ast.IndexExpr:
+		x, err := r.evalAST(s, expr.X)
+		if err != nil {
+			return v, err
+		}
+		index, err := r.evalAST(s, expr.Index)
+		if err != nil {
+			return v, err
+		}
+		return indexValue(x, index)
+	case *ast.StarExpr:
+		x, err := r.evalAST(s, expr.X)
+		if err != nil {
+			return v, err
+		}
+		if x.Kind() != reflect.Pointer || x.IsNil() {
+			return v, fmt.Errorf("invalid indirect of %s", typeName(x))
+		}
+		return x.Elem(), nil
+	case *ast.CallExpr:
+		return r.evalCall(s, expr)
+	case *ast.UnaryExpr:
+		x, err := r.evalAST(s, expr.X)
+		if err != nil {
+			return v, err
+		}
+		return unaryOp(expr.Op, x)
+	case *ast.BinaryExpr:
+		return r.evalBinary(s, expr)
+	}
+	return v, fmt.Errorf("%T expressions aren't supported by the interpreter", expr)
+}
+
+func evalLiteral(lit *ast.BasicLit) (v reflect.Value, err error) {
+	switch lit.Kind {
+	case token.INT:
+		i, err := strconv.ParseInt(lit.Value, 0, 64)
+		if err != nil {
+			return v, err
+		}
+		return reflect.ValueOf(int(i)), nil
+	case token.FLOAT:
+		f, err := strconv.ParseFloat(lit.Value, 64)
+		if err != nil {
+			return v, err
+		}
+		return reflect.ValueOf(f), nil
+	case token.STRING:
+		s, err := strconv.Unquote(lit.Value)
+		if err != nil {
+			return v, err
+		}
+		return reflect.ValueOf(s), nil
+	case token.CHAR:
+		s, _, _, err := strconv.UnquoteChar(lit.Value[1:len(lit.Value)-1], '\'')
+		if err != nil {
+			return v, err
+		}
+		return reflect.ValueOf(s), nil
+	}
+	return v, fmt.Errorf("unsupported literal %s", lit.Value)
+}
+
+// indirect follows pointers and interfaces to the underlying value.
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v
+}
+
+func selectField(x reflect.Value, name string) (v reflect.Value, err error) {
+	if !x.IsValid() {
+		return v, fmt.Errorf("cannot select %s of nil", name)
+	}
+	if m := x.MethodByName(name); m.IsValid() {
+		return m, nil
+	}
+	if x.Kind() != reflect.Pointer && x.CanAddr() {
+		if m := x.Addr().MethodByName(name); m.IsValid() {
+			return m, nil
+		}
+	}
+	d := indirect(x)
+	switch d.Kind() {
+	case reflect.Struct:
+		if f, ok := d.Type().FieldByName(name); ok && f.IsExported() {
+			return d.FieldByIndex(f.Index), nil
+		}
+	case reflect.Map:
+		if d.Type().Key().Kind() == reflect.String {
+			return mapIndex(d, reflect.ValueOf(name))
+		}
+	case reflect.Pointer, reflect.Interface:
+		return v, fmt.Errorf("cannot select %s of nil %s", name, x.Type())
+	}
+	return v, fmt.Errorf("%s has no field or method %s", typeName(x), name)
+}
+
+func indexValue(x, index reflect.Value) (v reflect.Value, err error) {
+	x = indirect(x)
+	switch x.Kind() {
+	case reflect.Map:
+		return mapIndex(x, index)
+	case reflect.Slice, reflect.Array, reflect.String:
+		i, ok := toInt(index)
+		if !ok {
+			return v, fmt.Errorf("invalid index of type %s", typeName(index))
+		}
+		if i < 0 || i >= int64(x.Len()) {
+			return v, fmt.Errorf("index out of range [%d] with length %d", i, x.Len())
+		}
+		return x.Index(int(i)), nil
+	}
+	return v, fmt.Errorf("cannot index %s", typeName(x))
+}
+
+func mapIndex(m, key reflect.Value) (v reflect.Value, err error) {
+	if key, err = convert(key, m.Type().Key()); err != nil {
+		return v, err
+	}
+	if v = m.MapIndex(key); !v.IsValid() {
+		return reflect.Zero(m.Type().Elem()), nil
+	}
+	return v, nil
+}
+
+// convert converts the value to a type, so that untyped constants, e.g. 1 or
+// "a", can be passed to functions that accept named or sized types.
+func convert(v reflect.Value, t reflect.Type) (reflect.Value, error) {
+	if !v.IsValid() {
+		switch t.Kind() {
+		case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+			return reflect.Zero(t), nil
+		}
+		return v, fmt.Errorf("cannot use nil as %s", t)
+	}
+	if v.Type().AssignableTo(t) {
+		return v, nil
+	}
+	if v.Type().ConvertibleTo(t) && v.Kind() != reflect.Interface && (isNumber(v.Kind()) == isNumber(t.Kind())) {
+		return v.Convert(t), nil
+	}
+	return v, fmt.Errorf("cannot use %s as %s", v.Type(), t)
+}
+
+func (r *renderer) evalCall(s *scope, expr *ast.CallExpr) (v reflect.Value, err error) {
+	if id, ok := expr.Fun.(*ast.Ident); ok && id.Name == "len" && !s.has("len") {
+		if len(expr.Args) != 1 {
+			return v, errors.New("len expects 1 argument")
+		}
+		x, err := r.evalAST(s, expr.Args[0])
+		if err != nil {
+			return v, err
+		}
+		x = indirect(x)
+		switch x.Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map, reflect.String, reflect.Chan:
+			return reflect.ValueOf(x.Len()), nil
+		}
+		return v, fmt.Errorf("invalid argument of type %s for len", typeName(x))
+	}
+	fn, err := r.evalAST(s, expr.Fun)
+	if err != nil {
+		return v, err
+	}
+	if !fn.IsValid() || fn.Kind() != reflect.Func {
+		return v, fmt.Errorf("cannot call %s", typeName(fn))
+	}
+	ft := fn.Type()
+	args := make([]reflect.Value, len(expr.Args))
+	for i, arg := range expr.Args {
+		if args[i], err = r.evalAST(s, arg); err != nil {
+			return v, err
+		}
+	}
+	if expr.Ellipsis.IsValid() {
+		return v, errors.New("calls with ... aren't supported by the interpreter")
+	}
+	if (!ft.IsVariadic() && len(args) != ft.NumIn()) || (ft.IsVariadic() && len(args) < ft.NumIn()-1) {
+		return v, fmt.Errorf("wrong number of arguments in call, expected %d, got %d", ft.NumIn(), len(args))
+	}
+	for i := range args {
+		t := ft.In(min(i, ft.NumIn()-1))
+		if ft.IsVariadic() && i >= ft.NumIn()-1 {
+			t = ft.In(ft.NumIn() - 1).Elem()
+		}
+		if args[i], err = convert(args[i], t); err != nil {
+			return v, err
+		}
+	}
+	results := fn.Call(args)
+	switch {
+	case len(results) == 1:
+		return results[0], nil
+	case len(results) == 2 && ft.Out(1) == errorType:
+		if !results[1].IsNil() {
+			return v, results[1].Interface().(error)
+		}
+		return results[0], nil
+	}
+	return v, fmt.Errorf("functions must return a single value, or a value and an error, got %d values", len(results))
+}
+
+func isNumber(k reflect.Kind) bool {
+	return isInt(k) || isUint(k) || k == reflect.Float32 || k == reflect.Float64
+}
+
+func isInt(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Int64
+}
+
+func isUint(k reflect.Kind) bool {
+	return k >= reflect.Uint && k <= reflect.Uintptr
+}
+
+func toInt(v reflect.Value) (int64, bool) {
+	switch {
+	case !v.IsValid():
+		return 0, false
+	case isInt(v.Kind()):
+		return v.Int(), true
+	case isUint(v.Kind()) && v.Uint() <= math.MaxInt64:
+		return int64(v.Uint()), true
+	}
+	return 0, false
+}
+
+func toFloat(v reflect.Value) (float64, bool) {
+	switch {
+	case !v.IsValid():
+		return 0, false
+	case isInt(v.Kind()):
+		return float64(v.Int()), true
+	case isUint(v.Kind()):
+		return float64(v.Uint()), true
+	case v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+func unaryOp(op token.Token, x reflect.Value) (v reflect.Value, err error) {
+	switch op {
+	case token.NOT:
+		if x.IsValid() && x.Kind() == reflect.Bool {
+			return reflect.ValueOf(!x.Bool()), nil
+		}
+	case token.SUB:
+		if i, ok := toInt(x); ok && isInt(x.Kind()) {
+			return reflect.ValueOf(-i).Convert(x.Type()), nil
+		}
+		if f, ok := toFloat(x); ok {
+			return reflect.ValueOf(-f), nil
+		}
+	case token.ADD:
+		if x.IsValid() && isNumber(x.Kind()) {
+			return x, nil
+		}
+	}
+	return v, fmt.Errorf("invalid operation: %s%s", op, typeName(x))
+}
+
+func (r *renderer) evalBinary(s *scope, expr *ast.BinaryExpr) (v reflect.Value, err error) {
+	x, err := r.evalAST(s, expr.X)
+	if err != nil {
+		return v, err
+	}
+	// Short circuit logical operators.
+	if expr.Op == token.LAND || expr.Op == token.LOR {
+		if !x.IsValid() || x.Kind() != reflect.Bool {
+			return v, fmt.Errorf("invalid operation: %s %s, expected bool", typeName(x), expr.Op)
+		}
+		if (expr.Op == token.LAND) != x.Bool() {
+			return x, nil
+		}
+		y, err := r.evalAST(s, expr.Y)
+		if err != nil {
+			return v, err
+		}
+		if !y.IsValid() || y.Kind() != reflect.Bool {
+			return v, fmt.Errorf("invalid operation: %s %s, expected bool", expr.Op, typeName(y))
+		}
+		return y, nil
+	}
+	y, err := r.evalAST(s, expr.Y)
+	if err != nil {
+		return v, err
+	}
+	switch expr.Op {
+	case token.EQL, token.NEQ:
+		eq, err := equal(x, y)
+		if err != nil {
+			return v, err
+		}
+		return reflect.ValueOf(eq == (expr.Op == token.EQL)), nil
+	}
+	if x.IsValid() && y.IsValid() && x.Kind() == reflect.String && y.Kind() == reflect.String {
+		a, b := x.String(), y.String()
+		switch expr.Op {
+		case token.ADD:
+			return reflect.ValueOf(a + b).Convert(x.Type()), nil
+		case token.LSS:
+			return reflect.ValueOf(a < b), nil
+		case token.LEQ:
+			return reflect.ValueOf(a <= b), nil
+		case token.GTR:
+			return reflect.ValueOf(a > b), nil
+		case token.GEQ:
+			return reflect.ValueOf(a >= b), nil
+		}
+	}
+	if a, ok := toInt(x); ok && isInt(x.Kind()) {
+		if b, ok := toInt(y); ok && isInt(y.Kind()) {
+			return intOp(expr.Op, a, b)
+		}
+	}
+	if a, ok := toFloat(x); ok {
+		if b, ok := toFloat(y); ok {
+			return floatOp(expr.Op, a, b)
+		}
+	}
+	return v, fmt.Errorf("invalid operation: %s %s %s", typeName(x), expr.Op, typeName(y))
+}
+
+func intOp(op token.Token, a, b int64) (v reflect.Value, err error) {
+	switch op {
+	case token.ADD:
+		return reflect.ValueOf(int(a + b)), nil
+	case token.SUB:
+		return reflect.ValueOf(int(a - b)), nil
+	case token.MUL:
+		return reflect.ValueOf(int(a * b)), nil
+	case token.QUO, token.REM:
+		if b == 0 {
+			return v, errors.New("integer divide by zero")
+		}
+		if op == token.QUO {
+			return reflect.ValueOf(int(a / b)), nil
+		}
+		return reflect.ValueOf(int(a % b)), nil
+	case token.LSS:
+		return reflect.ValueOf(a < b), nil
+	case token.LEQ:
+		return reflect.ValueOf(a <= b), nil
+	case token.GTR:
+		return reflect.ValueOf(a > b), nil
+	case token.GEQ:
+		return reflect.ValueOf(a >= b), nil
+	}
+	return v, fmt.Errorf("invalid operation: operator %s not defined on integers", op)
+}
+
+func floatOp(op token.Token, a, b float64) (v reflect.Value, err error) {
+	switch op {
+	case token.ADD:
+		return reflect.ValueOf(a + b), nil
+	case token.SUB:
+		return reflect.ValueOf(a - b), nil
+	case token.MUL:
+		return reflect.ValueOf(a * b), nil
+	case token.QUO:
+		return reflect.ValueOf(a / b), nil
+	case token.LSS:
+		return reflect.ValueOf(a < b), nil
+	case token.LEQ:
+		return reflect.ValueOf(a <= b), nil
+	case token.GTR:
+		return reflect.ValueOf(a > b), nil
+	case token.GEQ:
+		return reflect.ValueOf(a >= b), nil
+	}
+	return v, fmt.Errorf("invalid operation: operator %s not defined on floats", op)
+}
+
+// equal compares two values, converting untyped constants to the type of the other value.
+func equal(x, y reflect.Value) (bool, error) {
+	switch {
+	case !x.IsValid() && !y.IsValid():
+		return true, nil
+	case !x.IsValid():
+		return isNil(y), nil
+	case !y.IsValid():
+		return isNil(x), nil
+	}
+	if isNumber(x.Kind()) && isNumber(y.Kind()) {
+		if a, ok := toInt(x); ok {
+			if b, ok := toInt(y); ok {
+				return a == b, nil
+			}
+		}
+		a, _ := toFloat(x)
+		b, _ := toFloat(y)
+		return a == b, nil
+	}
+	if x.Type() != y.Type() {
+		if y.Type().ConvertibleTo(x.Type()) && x.Kind() == y.Kind() {
+			y = y.Convert(x.Type())
+		} else if x.Kind() != reflect.Interface && y.Kind() != reflect.Interface {
+			return false, fmt.Errorf("mismatched types %s and %s", x.Type(), y.Type())
+		}
+	}
+	if !x.Type().Comparable() || !y.Type().Comparable() {
+		return false, fmt.Errorf("%s cannot be compared", x.Type())
+	}
+	return x.Interface() == y.Interface(), nil
+}
+
+func isNil(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return v.IsNil()
+	}
+	return false
+}
+
+// iterate calls f for each key and value of a range expression. Maps are
+// iterated in key order, so that the output is stable.
+func iterate(x reflect.Value, f func(k, v reflect.Value) error) (err error) {
+	x = indirect(x)
+	switch {
+	case !x.IsValid():
+		return nil
+	case x.Kind() == reflect.Slice || x.Kind() == reflect.Array:
+		for i := 0; i < x.Len(); i++ {
+			if err = f(reflect.ValueOf(i), x.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case x.Kind() == reflect.String:
+		for i, r := range x.String() {
+			if err = f(reflect.ValueOf(i), reflect.ValueOf(r)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case x.Kind() == reflect.Map:
+		keys := x.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, k := range keys {
+			if err = f(k, x.MapIndex(k)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case isInt(x.Kind()):
+		for i := int64(0); i < x.Int(); i++ {
+			if err = f(reflect.ValueOf(i).Convert(x.Type()), reflect.Value{}); err != nil {
+				return err
+			}
+		}
+		return nil
+	case isUint(x.Kind()):
+		for i := uint64(0); i < x.Uint(); i++ {
+			if err = f(reflect.ValueOf(i).Convert(x.Type()), reflect.Value{}); err != nil {
+				return err
+			}
+		}
+		return nil
+	case isIterator(x.Type()):
+		// The loop body is the yield function, which stops the iterator by
+		// returning false if it fails.
+		yieldType := x.Type().In(0)
+		stop, next := reflect.ValueOf(false).Convert(yieldType.Out(0)), reflect.ValueOf(true).Convert(yieldType.Out(0))
+		yield := reflect.MakeFunc(yieldType, func(args []reflect.Value) []reflect.Value {
+			var k, v reflect.Value
+			if len(args) > 0 {
+				k = args[0]
+			}
+			if len(args) > 1 {
+				v = args[1]
+			}
+			if err = f(k, v); err != nil {
+				return []reflect.Value{stop}
+			}
+			return []reflect.Value{next}
+		})
+		x.Call([]reflect.Value{yield})
+		return err
+	}
+	return fmt.Errorf("cannot range over %s", typeName(x))
+}
+
+// isIterator returns true if t is an iterator function that can be ranged
+// over, e.g. func(yield func(K, V) bool).
+func isIterator(t reflect.Type) bool {
+	if t.Kind() != reflect.Func || t.NumIn() != 1 || t.NumOut() != 0 {
+		return false
+	}
+	yield := t.In(0)
+	return yield.Kind() == reflect.Func && yield.NumIn() <= 2 && yield.NumOut() == 1 && yield.Out(0).Kind() == reflect.Bool
+}
+
+// parseStatement parses a statement, e.g. `for _, v := range items {}`.
+func parseStatement(stmt string) (ast.Stmt, error) {
+	f, err := goparser.ParseFile(emptyFileSet(), "", "package p\nfunc _() {\n"+stmt+"\n}", goparser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	body := f.Decls[0].(*ast.FuncDecl).Body.List
+	if len(body) != 1 {
+		return nil, fmt.Errorf("expected a single statement, got %d", len(body))
+	}
+	return body[0], nil
+}
+
+func parseRangeStatement(expr string) (rs *ast.RangeStmt, err error) {
+	stmt, err := parseStatement("for " + expr + " {}")
+	if err != nil {
+		return nil, err
+	}
+	rs, ok := stmt.(*ast.RangeStmt)
+	if !ok || (rs.Tok != token.DEFINE && rs.Tok != token.ILLEGAL) {
+		return nil, errors.New("only for loops in the form `for k, v := range x` are supported by the interpreter")
+	}
+	return rs, nil
+}
+
+// parseSwitchStatement parses the expression of a switch statement, e.g. `x`,
+// or `x := f(); x`.
+func parseSwitchStatement(expr string) (ss *ast.SwitchStmt, err error) {
+	stmt, err := parseStatement("switch " + expr + " {}")
+	if err != nil {
+		return nil, err
+	}
+	ss, ok := stmt.(*ast.SwitchStmt)
+	if !ok {
+		return nil, errors.New("only switch statements in the form `switch x` or `switch v := f(); v` are supported by the interpreter")
+	}
+	return ss, nil
+}
+
+// parseCase parses a case clause, e.g. `case "a", "b":` or `default:`.
+func parseCase(expr string) (exprs []ast.Expr, isDefault bool, err error) {
+	stmt, err := parseStatement("switch {\n" + expr + "\n}")
+	if err != nil {
+		return nil, false, err
+	}
+	ss := stmt.(*ast.SwitchStmt)
+	if len(ss.Body.List) != 1 {
+		return nil, false, fmt.Errorf("invalid case %q", expr)
+	}
+	cc := ss.Body.List[0].(*ast.CaseClause)
+	return cc.List, cc.List == nil, nil
+}
@@ -0,0 +1,13 @@
+//go:build templ_hotreload
+
+package interpreter
+
+import (
+	"time"
+
+	"github.com/a-h/templ"
+)
+
+func init() {
+	templ.SetHotReloader(NewHotReloader(New(), time.Now()))
+}
@@ -0,0 +1,893 @@
+// Package interpreter renders templates directly from templ source files,
+// without generating and compiling Go code.
+//
+// It's intended for development, so that changes to markup are shown as soon
+// as a page is reloaded. Only a restricted subset of Go expressions is
+// supported, see the Interpreter documentation. In production, use the
+// generated code.
+package interpreter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"html"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/a-h/templ"
+	"github.com/a-h/templ/internal/typescript"
+	"github.com/a-h/templ/markdown"
+	"github.com/a-h/templ/parser/v2"
+)
+
+// Interpreter renders templates from templ source files. Files are re-read
+// when they change.
+//
+// Expressions within templates are evaluated against the template's
+// parameters, the variables declared by for loops, and the variables declared
+// by the init statements of if and switch statements. Expressions can use
+// literals, fields, map and slice indexes, method and function calls,
+// comparisons, arithmetic, and the logical operators. Go code blocks,
+// include and defer expressions, and break and continue statements aren't
+// supported.
+//
+// Templates can call other templates declared in the same file, and the
+// inline templates declared within them.
+type Interpreter struct {
+	// Funcs are the functions that can be called from expressions, keyed by
+	// name, e.g. "formatDate", or by qualified name, e.g. "strings.ToUpper".
+	Funcs map[string]any
+	// StylePreprocessor converts the contents of style elements with a lang
+	// attribute, e.g. <style lang="scss">, to CSS, in the same way as the
+	// templ generate -style-preprocessor command, if set.
+	StylePreprocessor func(lang, src string) (css string, err error)
+
+	m     sync.Mutex
+	files map[string]*file
+}
+
+// New creates an Interpreter that can call a default set of functions from
+// the templ, fmt, strings and strconv packages.
+func New() *Interpreter {
+	return &Interpreter{
+		Funcs: map[string]any{
+			"templ.URL":         templ.URL,
+			"templ.SafeURL":     func(s string) templ.SafeURL { return templ.SafeURL(s) },
+			"templ.Raw":         templ.Raw[string],
+			"templ.Classes":     templ.Classes,
+			"templ.KV":          templ.KV[string, bool],
+			"fmt.Sprint":        fmt.Sprint,
+			"fmt.Sprintf":       fmt.Sprintf,
+			"strings.ToUpper":   strings.ToUpper,
+			"strings.ToLower":   strings.ToLower,
+			"strings.TrimSpace": strings.TrimSpace,
+			"strings.Join":      strings.Join,
+			"strings.Contains":  strings.Contains,
+			"strconv.Itoa":      strconv.Itoa,
+		},
+		files: map[string]*file{},
+	}
+}
+
+type file struct {
+	name      string
+	modTime   time.Time
+	templates map[string]*template
+}
+
+type template struct {
+	params   []string
+	variadic bool
+	// defaults are the default values of the last parameters, which can be
+	// omitted by callers.
+	defaults []parser.DefaultParameter
+	children []parser.Node
+	// cssScope is the scope of the classes of the template's scoped style
+	// elements.
+	cssScope parser.CSSScope
+	// xml is true if the template generates XML instead of HTML.
+	xml bool
+}
+
+// Component returns a component that renders the named template of the templ
+// file, with the given arguments.
+func (in *Interpreter) Component(fileName, name string, args ...any) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) (err error) {
+		f, err := in.load(fileName)
+		if err != nil {
+			return err
+		}
+		values := make([]reflect.Value, len(args))
+		for i, arg := range args {
+			values[i] = reflect.ValueOf(arg)
+		}
+		c, err := in.template(f, name, values)
+		if err != nil {
+			return err
+		}
+		return c.Render(ctx, w)
+	})
+}
+
+func (in *Interpreter) load(fileName string) (f *file, err error) {
+	info, err := os.Stat(fileName)
+	if err != nil {
+		return nil, err
+	}
+	in.m.Lock()
+	defer in.m.Unlock()
+	if f, ok := in.files[fileName]; ok && f.modTime.Equal(info.ModTime()) {
+		return f, nil
+	}
+	tf, err := parser.Parse(fileName)
+	if err != nil {
+		return nil, err
+	}
+	f = &file{
+		name:      fileName,
+		modTime:   info.ModTime(),
+		templates: map[string]*template{},
+	}
+	for i, n := range tf.Nodes {
+		ht, ok := n.(parser.HTMLTemplate)
+		if !ok {
+			continue
+		}
+		name, t, ok := parseSignature(ht)
+		if !ok {
+			continue
+		}
+		t.xml = tf.IsXML(i)
+		f.templates[name] = t
+	}
+	in.files[fileName] = f
+	return f, nil
+}
+
+// parseSignature returns the name and parameters of a template. Templates
+// with receivers or type parameters aren't supported.
+func parseSignature(ht parser.HTMLTemplate) (name string, t *template, ok bool) {
+	decl, err := goparser.ParseFile(emptyFileSet(), "", "package p\nfunc "+ht.Signature()+" {}", goparser.SkipObjectResolution)
+	if err != nil || len(decl.Decls) != 1 {
+		return "", nil, false
+	}
+	fn, ok := decl.Decls[0].(*ast.FuncDecl)
+	if !ok || fn.Recv != nil || fn.Type.TypeParams != nil {
+		return "", nil, false
+	}
+	t = &template{children: ht.Children, defaults: ht.Defaults, cssScope: ht.CSSScope()}
+	for _, field := range fn.Type.Params.List {
+		if _, isVariadic := field.Type.(*ast.Ellipsis); isVariadic {
+			t.variadic = true
+		}
+		for _, n := range field.Names {
+			t.params = append(t.params, n.Name)
+		}
+	}
+	return fn.Name.Name, t, true
+}
+
+func (in *Interpreter) template(f *file, name string, args []reflect.Value) (c templ.Component, err error) {
+	t, ok := f.templates[name]
+	if !ok {
+		return nil, fmt.Errorf("%s: template %q not found", f.name, name)
+	}
+	return in.templateComponent(f, nil, t, name, args)
+}
+
+// templateComponent returns a component that renders the template with the given
+// arguments. The template can use the variables of the parent scope, if set.
+func (in *Interpreter) templateComponent(f *file, parent *scope, t *template, name string, args []reflect.Value) (c templ.Component, err error) {
+	s := &scope{vars: map[string]reflect.Value{}, parent: parent}
+	if t.variadic {
+		fixed := len(t.params) - 1
+		if len(args) < fixed {
+			return nil, fmt.Errorf("%s: template %q expects at least %d arguments, got %d", f.name, name, fixed, len(args))
+		}
+		rest := make([]any, 0, len(args)-fixed)
+		for _, arg := range args[fixed:] {
+			rest = append(rest, valueInterface(arg))
+		}
+		s.vars[t.params[fixed]] = reflect.ValueOf(rest)
+		args = args[:fixed]
+	} else if required := len(t.params) - len(t.defaults); len(args) < required || len(args) > len(t.params) {
+		if required == len(t.params) {
+			return nil, fmt.Errorf("%s: template %q expects %d arguments, got %d", f.name, name, len(t.params), len(args))
+		}
+		return nil, fmt.Errorf("%s: template %q expects %d to %d arguments, got %d", f.name, name, required, len(t.params), len(args))
+	}
+	for i, arg := range args {
+		s.vars[t.params[i]] = arg
+	}
+	var omitted []parser.DefaultParameter
+	if len(t.defaults) > 0 {
+		omitted = t.defaults[len(t.defaults)-(len(t.params)-len(args)):]
+	}
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) (err error) {
+		r := &renderer{in: in, file: f, children: templ.GetChildren(ctx), blocks: templ.GetBlocks(ctx), cssScope: t.cssScope, xml: t.xml}
+		ctx = templ.ClearChildren(ctx)
+		// The default values of omitted parameters can use the other parameters.
+		ts := s
+		if len(omitted) > 0 {
+			ts = s.child()
+			for _, d := range omitted {
+				if ts.vars[d.Name.Value], err = r.eval(ts, d.Value); err != nil {
+					return err
+				}
+			}
+		}
+		return r.writeNodes(ctx, w, ts, stripWhitespace(t.children), nil)
+	}), nil
+}
+
+type renderer struct {
+	in       *Interpreter
+	file     *file
+	children templ.Component
+	blocks   templ.Slots
+	cssScope parser.CSSScope
+	xml      bool
+}
+
+// errorAt returns an error that includes the position of the expression in the templ file.
+func (r *renderer) errorAt(e parser.Expression, err error) error {
+	if err == nil {
+		return nil
+	}
+	var te templ.Error
+	if errors.As(err, &te) {
+		return err
+	}
+	return templ.Error{
+		Err:      err,
+		FileName: r.file.name,
+		Line:     int(e.Range.From.Line),
+		Col:      int(e.Range.From.Col),
+	}
+}
+
+func (r *renderer) writeNodes(ctx context.Context, w io.Writer, s *scope, nodes []parser.Node, next parser.Node) error {
+	for i, n := range nodes {
+		nextNode := next
+		if i+1 < len(nodes) {
+			nextNode = nodes[i+1]
+		}
+		if err := r.writeNode(ctx, w, s, n, nextNode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *renderer) writeNode(ctx context.Context, w io.Writer, s *scope, current, next parser.Node) (err error) {
+	switch n := current.(type) {
+	case parser.DocType:
+		_, err = io.WriteString(w, "<!doctype "+n.Value+">")
+	case parser.ProcessingInstruction:
+		_, err = io.WriteString(w, "<?"+n.Value+"?>")
+	case parser.Element:
+		err = r.writeElement(ctx, w, s, n)
+	case parser.RawElement:
+		err = r.writeRawElement(ctx, w, s, n)
+	case parser.HTMLComment:
+		_, err = io.WriteString(w, "<!--"+n.Contents+"-->")
+	case parser.CDATA:
+		err = r.writeCDATA(w, s, n)
+	case parser.RawBlock:
+		_, err = io.WriteString(w, n.Contents)
+	case parser.MarkdownBlock:
+		err = markdown.Component(n.Contents).Render(ctx, w)
+	case parser.Text:
+		if trimsWhitespace(n, next) {
+			n.Value = strings.TrimRightFunc(n.Value, unicode.IsSpace)
+		}
+		_, err = io.WriteString(w, n.Value)
+	case parser.Whitespace:
+		if len(n.Value) > 0 {
+			_, err = io.WriteString(w, " ")
+		}
+	case parser.StringExpression:
+		err = r.writeStringExpression(w, s, n.Expression)
+	case parser.IfExpression:
+		err = r.writeIfExpression(ctx, w, s, n, next)
+	case parser.ForExpression:
+		err = r.writeForExpression(ctx, w, s, n, next)
+	case parser.SwitchExpression:
+		err = r.writeSwitchExpression(ctx, w, s, n, next)
+	case parser.CallTemplateExpression:
+		err = r.writeTemplElementExpression(ctx, w, s, n.Expression, nil)
+	case parser.TemplElementExpression:
+		err = r.writeTemplElementExpression(ctx, w, s, n.Expression, n.Children)
+	case parser.ChildrenExpression:
+		if r.children != nil {
+			err = r.children.Render(ctx, w)
+		}
+	case parser.BlockExpression:
+		if r.blocks.Has(n.Name) {
+			err = r.blocks.Get(n.Name).Render(ctx, w)
+		} else {
+			err = r.writeNodes(ctx, w, s, stripLeadingAndTrailingWhitespace(n.Children), nil)
+		}
+	case parser.GoComment:
+		// Do not render Go comments in the output HTML.
+		return nil
+	case parser.GoCode:
+		return r.errorAt(n.Expression, errors.New("Go code isn't supported by the interpreter"))
+	case parser.BranchStatement:
+		return r.errorAt(n.Expression, errors.New("break and continue aren't supported by the interpreter"))
+	case parser.InlineTemplate:
+		err = r.declareInlineTemplate(s, n)
+	default:
+		return fmt.Errorf("%s: %T isn't supported by the interpreter", r.file.name, current)
+	}
+	if err != nil {
+		return err
+	}
+	// Write trailing whitespace, if the next node might need the space, and
+	// there's no trim marker.
+	if ws, ok := current.(parser.WhitespaceTrailer); ok && isInlineOrText(current) && isInlineOrText(next) && !trimsWhitespace(current, next) {
+		if ws.Trailing() != parser.SpaceNone {
+			_, err = io.WriteString(w, " ")
+		}
+	}
+	return err
+}
+
+// trimsWhitespace returns true if a trim marker, i.e. the {- or -} of a string
+// expression, removes the whitespace between the nodes.
+func trimsWhitespace(current, next parser.Node) bool {
+	if se, ok := current.(parser.StringExpression); ok && se.TrimAfter {
+		return true
+	}
+	se, ok := next.(parser.StringExpression)
+	return ok && se.TrimBefore
+}
+
+func isInlineOrText(n parser.Node) bool {
+	switch n := n.(type) {
+	case parser.IfExpression, parser.SwitchExpression, parser.ForExpression, parser.Text, parser.StringExpression, parser.CDATA:
+		return true
+	case parser.Element:
+		// Whitespace between the elements within <math> is ignored, but the
+		// <math> element itself is inline.
+		return !n.IsBlockElement() && (n.Name == "math" || !parser.IsMathMLElement(n.Name))
+	}
+	return false
+}
+
+func stripWhitespace(input []parser.Node) (output []parser.Node) {
+	for _, n := range input {
+		if _, isWhitespace := n.(parser.Whitespace); !isWhitespace {
+			output = append(output, n)
+		}
+	}
+	return output
+}
+
+func stripLeadingAndTrailingWhitespace(nodes []parser.Node) []parser.Node {
+	for len(nodes) > 0 {
+		if _, isWhitespace := nodes[0].(parser.Whitespace); !isWhitespace {
+			break
+		}
+		nodes = nodes[1:]
+	}
+	for len(nodes) > 0 {
+		if _, isWhitespace := nodes[len(nodes)-1].(parser.Whitespace); !isWhitespace {
+			break
+		}
+		nodes = nodes[:len(nodes)-1]
+	}
+	return nodes
+}
+
+func (r *renderer) writeElement(ctx context.Context, w io.Writer, s *scope, n parser.Element) (err error) {
+	if _, err = io.WriteString(w, "<"+html.EscapeString(n.Name)); err != nil {
+		return err
+	}
+	if err = r.writeAttributes(ctx, w, s, n.Name, n.Attributes); err != nil {
+		return err
+	}
+	// XML elements without children are self-closing.
+	if r.xml && len(stripWhitespace(n.Children)) == 0 {
+		_, err = io.WriteString(w, "/>")
+		return err
+	}
+	if _, err = io.WriteString(w, ">"); err != nil {
+		return err
+	}
+	// Skip children and close tag for void elements.
+	if !r.xml && n.IsVoidElement() && len(n.Children) == 0 {
+		return nil
+	}
+	if err = r.writeNodes(ctx, w, s, stripWhitespace(n.Children), nil); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "</"+html.EscapeString(n.Name)+">")
+	return err
+}
+
+func (r *renderer) writeRawElement(ctx context.Context, w io.Writer, s *scope, n parser.RawElement) (err error) {
+	n = r.cssScope.Style(n)
+	// Match the generated code, which transpiles TypeScript to JavaScript.
+	if n.IsTypeScript() {
+		if n.Contents, err = typescript.Transpile(n.Contents); err != nil {
+			return fmt.Errorf("<%s>: failed to transpile TypeScript: %w", n.Name, err)
+		}
+		n.Attributes = n.JavaScriptAttributes()
+	}
+	if lang := n.StyleLang(); lang != "" && r.in.StylePreprocessor != nil {
+		if n, err = n.TransformContents(func(src string) (string, error) { return r.in.StylePreprocessor(lang, src) }); err != nil {
+			return fmt.Errorf("<%s lang=%q>: failed to preprocess style: %w", n.Name, lang, err)
+		}
+		n.Attributes = n.CSSAttributes()
+	}
+	if _, err = io.WriteString(w, "<"+html.EscapeString(n.Name)); err != nil {
+		return err
+	}
+	if err = r.writeAttributes(ctx, w, s, n.Name, n.Attributes); err != nil {
+		return err
+	}
+	// Match the generated code, which adds the CSP nonce to script and style
+	// elements.
+	if (strings.EqualFold(n.Name, "script") || strings.EqualFold(n.Name, "style")) && !parser.HasAttribute(n.Attributes, "nonce") {
+		if _, err = io.WriteString(w, templ.NonceAttribute(ctx)); err != nil {
+			return err
+		}
+	}
+	if _, err = io.WriteString(w, ">"); err != nil {
+		return err
+	}
+	for _, c := range n.SplitContents() {
+		if c.Expression == nil {
+			if _, err = io.WriteString(w, c.Value); err != nil {
+				return err
+			}
+			continue
+		}
+		v, err := r.eval(s, *c.Expression)
+		if err != nil {
+			return err
+		}
+		if n.IsJSON() {
+			value, err := templ.JSONString(valueInterface(v))
+			if err != nil {
+				return r.errorAt(*c.Expression, err)
+			}
+			if _, err = io.WriteString(w, value); err != nil {
+				return err
+			}
+			continue
+		}
+		value, err := templ.ToString(valueInterface(v))
+		if err != nil {
+			return r.errorAt(*c.Expression, err)
+		}
+		switch {
+		case n.IsCSS() && c.InString:
+			value = templ.EscapeCSSString(value)
+		case n.IsCSS() && c.Property != "":
+			value = templ.EscapeCSSPropertyValue(c.Property, value)
+		case n.IsCSS():
+			value = templ.EscapeCSSValue(value)
+		case n.IsEscapableRawText():
+			value = templ.EscapeString(value)
+		default:
+			value = templ.EscapeJSTemplateLiteral(value)
+		}
+		if _, err = io.WriteString(w, value); err != nil {
+			return err
+		}
+	}
+	_, err = io.WriteString(w, "</"+html.EscapeString(n.Name)+">")
+	return err
+}
+
+func (r *renderer) writeCDATA(w io.Writer, s *scope, n parser.CDATA) (err error) {
+	if _, err = io.WriteString(w, "<![CDATA["); err != nil {
+		return err
+	}
+	for _, c := range n.SplitContents() {
+		if c.Expression == nil {
+			if _, err = io.WriteString(w, c.Value); err != nil {
+				return err
+			}
+			continue
+		}
+		v, err := r.eval(s, *c.Expression)
+		if err != nil {
+			return err
+		}
+		value, err := templ.ToString(valueInterface(v))
+		if err != nil {
+			return r.errorAt(*c.Expression, err)
+		}
+		if _, err = io.WriteString(w, templ.EscapeCDATA(value)); err != nil {
+			return err
+		}
+	}
+	_, err = io.WriteString(w, "]]>")
+	return err
+}
+
+func (r *renderer) writeAttributes(ctx context.Context, w io.Writer, s *scope, elementName string, attrs []parser.Attribute) (err error) {
+	for _, attr := range attrs {
+		switch attr := attr.(type) {
+		case parser.BoolConstantAttribute:
+			_, err = io.WriteString(w, " "+html.EscapeString(attr.Name))
+		case parser.ConstantAttribute:
+			if strings.EqualFold(attr.Name, "class") {
+				attr.Value = r.cssScope.Class(attr.Value)
+			}
+			_, err = io.WriteString(w, " "+html.EscapeString(attr.Name)+`="`+html.EscapeString(attr.Value)+`"`)
+		case parser.BoolExpressionAttribute:
+			var ok bool
+			if ok, err = r.evalBool(s, attr.Expression); err == nil && ok {
+				_, err = io.WriteString(w, " "+html.EscapeString(attr.Name))
+			}
+		case parser.ExpressionAttribute:
+			err = r.writeExpressionAttribute(w, s, elementName, attr)
+		case parser.InterpolatedAttribute:
+			err = r.writeInterpolatedAttribute(w, s, attr)
+		case parser.SpreadAttributes:
+			var v reflect.Value
+			if v, err = r.eval(s, attr.Expression); err != nil {
+				return err
+			}
+			if err = templ.RenderSpreadAttributes(ctx, w, valueInterface(v)); err != nil {
+				return r.errorAt(attr.Expression, err)
+			}
+		case parser.DynamicAttribute:
+			var name, value reflect.Value
+			if name, err = r.eval(s, attr.Name); err != nil {
+				return err
+			}
+			if name.Kind() != reflect.String {
+				return r.errorAt(attr.Name, fmt.Errorf("attribute names must be strings, got %s", typeName(name)))
+			}
+			if value, err = r.eval(s, attr.Value); err != nil {
+				return err
+			}
+			err = templ.RenderAttribute(ctx, w, name.String(), valueInterface(value))
+		case parser.ConditionalAttribute:
+			var ok bool
+			if ok, err = r.evalBool(s, attr.Expression); err != nil {
+				return err
+			}
+			if ok {
+				err = r.writeAttributes(ctx, w, s, elementName, attr.Then)
+			} else {
+				err = r.writeAttributes(ctx, w, s, elementName, attr.Else)
+			}
+		default:
+			err = fmt.Errorf("%s: %T isn't supported by the interpreter", r.file.name, attr)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *renderer) writeExpressionAttribute(w io.Writer, s *scope, elementName string, attr parser.ExpressionAttribute) (err error) {
+	var v any
+	if strings.EqualFold(attr.Name, "class") {
+		classes, err := r.evalClasses(s, attr.Expression)
+		if err != nil {
+			return err
+		}
+		v = templ.CSSClasses(classes)
+		if len(classes) == 1 {
+			v = classes[0]
+		}
+	} else {
+		rv, err := r.eval(s, attr.Expression)
+		if err != nil {
+			return err
+		}
+		v = valueInterface(rv)
+	}
+	var value string
+	if strings.EqualFold(attr.Name, "style") {
+		if value, err = templ.SanitizeStyleAttributeValues(v); err != nil {
+			return r.errorAt(attr.Expression, err)
+		}
+		_, err = io.WriteString(w, " "+html.EscapeString(attr.Name)+`="`+templ.EscapeString(value)+`"`)
+		return err
+	}
+	switch iv := v.(type) {
+	case templ.SafeURL:
+		value = string(iv)
+	case templ.ComponentScript:
+		value = iv.Call
+	case templ.CSSClasses:
+		value = iv.String()
+	case templ.KeyValue[string, bool]:
+		value = templ.Classes(iv).String()
+	case string:
+		value = iv
+		if (elementName == "a" && attr.Name == "href") || (elementName == "form" && attr.Name == "action") {
+			value = string(templ.URL(iv))
+		}
+	default:
+		if value, err = templ.ToString(iv); err != nil {
+			return r.errorAt(attr.Expression, err)
+		}
+	}
+	if strings.EqualFold(attr.Name, "class") {
+		value = r.cssScope.Class(value)
+	}
+	_, err = io.WriteString(w, " "+html.EscapeString(attr.Name)+`="`+templ.EscapeString(value)+`"`)
+	return err
+}
+
+func (r *renderer) writeInterpolatedAttribute(w io.Writer, s *scope, attr parser.InterpolatedAttribute) (err error) {
+	var sb strings.Builder
+	for i, part := range attr.Parts {
+		if part.Expression == nil {
+			sb.WriteString(html.EscapeString(part.Value))
+			continue
+		}
+		v, err := r.eval(s, *part.Expression)
+		if err != nil {
+			return err
+		}
+		value, err := templ.ToString(valueInterface(v))
+		if err != nil {
+			return r.errorAt(*part.Expression, err)
+		}
+		if parser.IsURLAttribute(attr.Name) {
+			switch {
+			case i == 0:
+				value = string(templ.URL(value))
+			case attr.IsURLQuery(i):
+				value = templ.URLQueryValue(value)
+			default:
+				value = templ.URLPathSegment(value)
+			}
+		}
+		sb.WriteString(templ.EscapeString(value))
+	}
+	_, err = io.WriteString(w, " "+html.EscapeString(attr.Name)+`="`+sb.String()+`"`)
+	return err
+}
+
+func (r *renderer) writeStringExpression(w io.Writer, s *scope, e parser.Expression) (err error) {
+	if strings.TrimSpace(e.Value) == "" {
+		return nil
+	}
+	v, err := r.eval(s, e)
+	if err != nil {
+		return err
+	}
+	value, err := templ.ToString(valueInterface(v))
+	if err != nil {
+		return r.errorAt(e, err)
+	}
+	_, err = io.WriteString(w, templ.EscapeString(value))
+	return err
+}
+
+func (r *renderer) writeIfExpression(ctx context.Context, w io.Writer, s *scope, n parser.IfExpression, next parser.Node) (err error) {
+	// Variables declared by init statements are visible to the later branches.
+	ok, s, err := r.evalIf(s, n.Expression)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return r.writeNodes(ctx, w, s, stripLeadingAndTrailingWhitespace(n.Then), next)
+	}
+	for _, elseIf := range n.ElseIfs {
+		if ok, s, err = r.evalIf(s, elseIf.Expression); err != nil {
+			return err
+		}
+		if ok {
+			return r.writeNodes(ctx, w, s, stripLeadingAndTrailingWhitespace(elseIf.Then), next)
+		}
+	}
+	return r.writeNodes(ctx, w, s, stripLeadingAndTrailingWhitespace(n.Else), next)
+}
+
+func (r *renderer) writeForExpression(ctx context.Context, w io.Writer, s *scope, n parser.ForExpression, next parser.Node) (err error) {
+	rs, err := parseRangeStatement(n.Expression.Value)
+	if err != nil {
+		return r.errorAt(n.Expression, err)
+	}
+	x, err := r.evalExpr(s, n.Expression, rs.X)
+	if err != nil {
+		return err
+	}
+	children := stripLeadingAndTrailingWhitespace(n.Children)
+	var ran bool
+	err = r.errorAt(n.Expression, iterate(x, func(k, v reflect.Value) error {
+		ran = true
+		loop := s.child()
+		if id, ok := rs.Key.(*ast.Ident); ok && id.Name != "_" {
+			loop.vars[id.Name] = k
+		}
+		if id, ok := rs.Value.(*ast.Ident); ok && id.Name != "_" {
+			loop.vars[id.Name] = v
+		}
+		return r.writeNodes(ctx, w, loop, children, next)
+	}))
+	if err != nil || ran {
+		return err
+	}
+	return r.writeNodes(ctx, w, s, stripLeadingAndTrailingWhitespace(n.Else), next)
+}
+
+func (r *renderer) writeSwitchExpression(ctx context.Context, w io.Writer, s *scope, n parser.SwitchExpression, next parser.Node) (err error) {
+	ss, err := parseSwitchStatement(n.Expression.Value)
+	if err != nil {
+		return r.errorAt(n.Expression, err)
+	}
+	if ss.Init != nil {
+		s = s.child()
+		if err = r.evalInit(s, n.Expression, ss.Init); err != nil {
+			return err
+		}
+	}
+	value := reflect.ValueOf(true)
+	if ss.Tag != nil {
+		if value, err = r.evalExpr(s, n.Expression, ss.Tag); err != nil {
+			return err
+		}
+	}
+	matched, defaultCase := -1, -1
+cases:
+	for i, c := range n.Cases {
+		exprs, isDefault, err := parseCase(c.Expression.Value)
+		if err != nil {
+			return r.errorAt(c.Expression, err)
+		}
+		if isDefault {
+			defaultCase = i
+			continue
+		}
+		for _, expr := range exprs {
+			cv, err := r.evalExpr(s, c.Expression, expr)
+			if err != nil {
+				return err
+			}
+			eq, err := equal(value, cv)
+			if err != nil {
+				return r.errorAt(c.Expression, err)
+			}
+			if eq {
+				matched = i
+				break cases
+			}
+		}
+	}
+	if matched < 0 {
+		matched = defaultCase
+	}
+	if matched < 0 {
+		return nil
+	}
+	// A case that ends with fallthrough continues with the next case.
+	for _, c := range n.Cases[matched:] {
+		children := stripLeadingAndTrailingWhitespace(c.Children)
+		fallsThrough := false
+		if len(children) > 0 {
+			if bs, ok := children[len(children)-1].(parser.BranchStatement); ok && bs.Expression.Value == "fallthrough" {
+				children, fallsThrough = stripLeadingAndTrailingWhitespace(children[:len(children)-1]), true
+			}
+		}
+		if err = r.writeNodes(ctx, w, s, children, next); err != nil {
+			return err
+		}
+		if !fallsThrough {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (r *renderer) writeTemplElementExpression(ctx context.Context, w io.Writer, s *scope, e parser.Expression, children []parser.Node) (err error) {
+	c, err := r.component(s, e)
+	if err != nil {
+		return err
+	}
+	// Blocks that are children of the element override the blocks of the
+	// component, the other nodes are its children.
+	var blocks templ.Slots
+	var other []parser.Node
+	for _, child := range children {
+		b, ok := child.(parser.BlockExpression)
+		if !ok {
+			other = append(other, child)
+			continue
+		}
+		if blocks == nil {
+			blocks = templ.Slots{}
+		}
+		blockChildren := stripLeadingAndTrailingWhitespace(b.Children)
+		blocks[b.Name] = templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			return r.writeNodes(ctx, w, s, blockChildren, nil)
+		})
+	}
+	if len(other) > 0 {
+		other := stripLeadingAndTrailingWhitespace(other)
+		ctx = templ.WithChildren(ctx, templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			return r.writeNodes(ctx, w, s, other, nil)
+		}))
+	}
+	if blocks != nil {
+		ctx = templ.WithBlocks(ctx, blocks)
+	}
+	return c.Render(ctx, w)
+}
+
+// component evaluates an expression that returns a component. Calls to
+// templates declared in the same file are interpreted.
+func (r *renderer) component(s *scope, e parser.Expression) (c templ.Component, err error) {
+	expr, err := goparser.ParseExpr(e.Value)
+	if err != nil {
+		return nil, r.errorAt(e, err)
+	}
+	if call, ok := expr.(*ast.CallExpr); ok {
+		if id, ok := call.Fun.(*ast.Ident); ok && !s.has(id.Name) {
+			if _, ok := r.file.templates[id.Name]; ok {
+				args, err := r.evalArgs(s, e, call.Args)
+				if err != nil {
+					return nil, err
+				}
+				c, err = r.in.template(r.file, id.Name, args)
+				if err != nil {
+					return nil, r.errorAt(e, err)
+				}
+				return c, nil
+			}
+		}
+	}
+	v, err := r.evalExpr(s, e, expr)
+	if err != nil {
+		return nil, err
+	}
+	c, ok := valueInterface(v).(templ.Component)
+	if !ok {
+		return nil, r.errorAt(e, fmt.Errorf("expected a templ.Component, got %s", typeName(v)))
+	}
+	return c, nil
+}
+
+// declareInlineTemplate adds a function that returns the inline template as a
+// component to the scope, in the same way as the generated code, so that the
+// template can use the variables of the scope.
+func (r *renderer) declareInlineTemplate(s *scope, n parser.InlineTemplate) error {
+	name, t, ok := parseSignature(parser.HTMLTemplate{Expression: n.Expression, Children: n.Children})
+	if !ok {
+		return r.errorAt(n.Expression, errors.New("invalid inline template declaration"))
+	}
+	t.cssScope, t.xml = r.cssScope, r.xml
+	s.vars[name] = reflect.ValueOf(func(args ...any) (templ.Component, error) {
+		values := make([]reflect.Value, len(args))
+		for i, arg := range args {
+			values[i] = reflect.ValueOf(arg)
+		}
+		return r.in.templateComponent(r.file, s, t, name, values)
+	})
+	return nil
+}
+
+// NewHotReloader creates a templ.HotReloader that interprets templates whose
+// files have been modified since the compiled code was built, i.e. since the
+// program started.
+func NewHotReloader(in *Interpreter, since time.Time) templ.HotReloader {
+	return func(templFileName, name string, args ...any) (c templ.Component, ok bool) {
+		info, err := os.Stat(templFileName)
+		if err != nil || !info.ModTime().After(since) {
+			return nil, false
+		}
+		return in.Component(templFileName, name, args...), true
+	}
+}
@@ -0,0 +1,398 @@
+package interpreter
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/google/go-cmp/cmp"
+)
+
+type item struct {
+	Name string
+	Done bool
+	Tags []string
+}
+
+func (i item) Status() string {
+	if i.Done {
+		return "done"
+	}
+	return "open"
+}
+
+func TestInterpreter(t *testing.T) {
+	// The expected output is the output of the generated code for testdata/templates.templ.
+	expected := `<!doctype html><html><head><title>Todo</title></head><body><h1>Todo</h1>` +
+		`<ul class="items"><li id="item-0" class="done">a <b>DONE</b> <span>x, y</span></li>` +
+		`<li id="item-1" class="">b &lt;c&gt; <b>OPEN</b> <span>todo</span></li>` +
+		`<li id="item-2" class="done">d <b>DONE</b> </li></ul>` +
+		`<div class="card"><h2>Summary</h2><p>3 items</p><small>Updated</small></div><p>Busy.</p>` +
+		`<a href="/items?page=Todo">Next</a> <a class="link link-3" href="/items/Todo?q=Todo%26">Last</a> <input type="checkbox" checked data-Todo="3"></body></html>`
+
+	in := New()
+	items := []item{
+		{Name: "a", Done: true, Tags: []string{"x", "y"}},
+		{Name: "b <c>"},
+		{Name: "d", Done: true},
+	}
+	actual, err := templ.ToGoHTML(context.Background(), in.Component("testdata/templates.templ", "page", "Todo", items))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(expected, string(actual)); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestInterpreterCDATAWhitespace(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "t.templ")
+	template := "package main\n\ntempl t(body string) {\n\t<description>\n\t\t<b>Note</b>\n\t\t<![CDATA[{{ body }}]]>\n\t\t<i>end</i>\n\t</description>\n}\n"
+	if err := os.WriteFile(fileName, []byte(template), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	actual, err := templ.ToGoHTML(context.Background(), New().Component(fileName, "t", "x < y"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The whitespace around the CDATA section matches the output of the generated code.
+	if diff := cmp.Diff(`<description><b>Note</b> <![CDATA[x < y]]> <i>end</i></description>`, string(actual)); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestInterpreterForElse(t *testing.T) {
+	in := New()
+	actual, err := templ.ToGoHTML(context.Background(), in.Component("testdata/templates.templ", "page", "Todo", []item{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `<ul class="items"><li>No items</li></ul>`; !strings.Contains(string(actual), expected) {
+		t.Errorf("expected %q in output:\n%s", expected, actual)
+	}
+}
+
+func TestInterpreterIfInit(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "t.templ")
+	template := "package main\n\ntempl t(m map[string]int) {\n\tif v, ok := m[\"a\"]; ok {\n\t\t<p>{ v }</p>\n\t} else if n := len(m); n > 0 {\n\t\t<p>{ n } others</p>\n\t} else {\n\t\t<p>None</p>\n\t}\n}\n"
+	if err := os.WriteFile(fileName, []byte(template), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	for _, test := range []struct {
+		m        map[string]int
+		expected string
+	}{
+		{m: map[string]int{"a": 1}, expected: "<p>1</p>"},
+		{m: map[string]int{"b": 2, "c": 3}, expected: "<p>2 others</p>"},
+		{m: nil, expected: "<p>None</p>"},
+	} {
+		actual, err := templ.ToGoHTML(context.Background(), New().Component(fileName, "t", test.m))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if diff := cmp.Diff(test.expected, string(actual)); diff != "" {
+			t.Error(diff)
+		}
+	}
+}
+
+func TestInterpreterSwitchFallthrough(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "t.templ")
+	template := "package main\n\ntempl t(role string) {\n\tswitch r := role; r {\n\t\tcase \"admin\":\n\t\t\t<li>Delete</li>\n\t\t\tfallthrough\n\t\tcase \"editor\":\n\t\t\t<li>Edit</li>\n\t\t\tfallthrough\n\t\tdefault:\n\t\t\t<li>View as { r }</li>\n\t}\n}\n"
+	if err := os.WriteFile(fileName, []byte(template), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	for _, test := range []struct {
+		role     string
+		expected string
+	}{
+		{role: "admin", expected: "<li>Delete</li><li>Edit</li><li>View as admin</li>"},
+		{role: "editor", expected: "<li>Edit</li><li>View as editor</li>"},
+		{role: "guest", expected: "<li>View as guest</li>"},
+	} {
+		actual, err := templ.ToGoHTML(context.Background(), New().Component(fileName, "t", test.role))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if diff := cmp.Diff(test.expected, string(actual)); diff != "" {
+			t.Error(diff)
+		}
+	}
+}
+
+func TestInterpreterDefaults(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "t.templ")
+	template := "package main\n\ntempl button(label string, variant string = \"primary\", title string = label) {\n\t<button class={ variant } title={ title }>{ label }</button>\n}\n"
+	if err := os.WriteFile(fileName, []byte(template), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	for _, test := range []struct {
+		args     []any
+		expected string
+	}{
+		{args: []any{"Save"}, expected: `<button class="primary" title="Save">Save</button>`},
+		{args: []any{"Save", "secondary"}, expected: `<button class="secondary" title="Save">Save</button>`},
+		{args: []any{"Save", "secondary", "Save changes"}, expected: `<button class="secondary" title="Save changes">Save</button>`},
+	} {
+		actual, err := templ.ToGoHTML(context.Background(), New().Component(fileName, "button", test.args...))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if diff := cmp.Diff(test.expected, string(actual)); diff != "" {
+			t.Error(diff)
+		}
+	}
+	_, err := templ.ToGoHTML(context.Background(), New().Component(fileName, "button"))
+	if err == nil || !strings.Contains(err.Error(), "expects 1 to 3 arguments, got 0") {
+		t.Errorf("expected an argument count error, got %v", err)
+	}
+}
+
+func TestInterpreterTrimMarkers(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "t.templ")
+	template := "package main\n\ntempl t(name string) {\n\t<p>Hello, {- name -} !</p>\n\t<a>1</a>\n\t{- -}\n\t<a>2</a>\n}\n"
+	if err := os.WriteFile(fileName, []byte(template), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	actual, err := templ.ToGoHTML(context.Background(), New().Component(fileName, "t", "World"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff("<p>Hello,World!</p><a>1</a><a>2</a>", string(actual)); diff != "" {
+		t.Error(diff)
+	}
+}
+
+type linkProps struct {
+	Href  string `attr:"href"`
+	Title string `attr:"title"`
+}
+
+func TestInterpreterSpreadStructs(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "t.templ")
+	template := "package main\n\ntempl t(props linkProps) {\n\t<a { props... }>Home</a>\n}\n"
+	if err := os.WriteFile(fileName, []byte(template), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	actual, err := templ.ToGoHTML(context.Background(), New().Component(fileName, "t", linkProps{Href: "/", Title: "Home page"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(`<a href="/" title="Home page">Home</a>`, string(actual)); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestInterpreterClassConditions(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "t.templ")
+	template := "package main\n\ntempl t(isPrimary bool, enabled bool) {\n\t<button class={ \"btn\", \"btn-primary\": isPrimary, \"disabled\": !enabled }>Save</button>\n}\n"
+	if err := os.WriteFile(fileName, []byte(template), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	in := New()
+	for _, test := range []struct {
+		isPrimary, enabled bool
+		expected           string
+	}{
+		{isPrimary: true, enabled: true, expected: `<button class="btn btn-primary">Save</button>`},
+		{isPrimary: false, enabled: false, expected: `<button class="btn disabled">Save</button>`},
+	} {
+		actual, err := templ.ToGoHTML(context.Background(), in.Component(fileName, "t", test.isPrimary, test.enabled))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if diff := cmp.Diff(test.expected, string(actual)); diff != "" {
+			t.Error(diff)
+		}
+	}
+}
+
+func TestInterpreterBoolAttributeShorthand(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "t.templ")
+	template := "package main\n\ntempl t(enabled bool) {\n\t<input ?disabled={ !enabled } ?required={ enabled }/>\n}\n"
+	if err := os.WriteFile(fileName, []byte(template), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	actual, err := templ.ToGoHTML(context.Background(), New().Component(fileName, "t", false))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(`<input disabled>`, string(actual)); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestInterpreterRangeOverIntAndFunc(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "t.templ")
+	template := "package main\n\ntempl t(n int, seq func(yield func(int, string) bool)) {\n\tfor i := range n {\n\t\t<b>{ i }</b>\n\t}\n\tfor i, v := range seq {\n\t\t<i>{ i }{ v }</i>\n\t}\n}\n"
+	if err := os.WriteFile(fileName, []byte(template), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	seq := func(yield func(int, string) bool) {
+		for i, v := range []string{"a", "b"} {
+			if !yield(i, v) {
+				return
+			}
+		}
+	}
+	actual, err := templ.ToGoHTML(context.Background(), New().Component(fileName, "t", 2, seq))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(`<b>0</b> <b>1</b> <i>0a</i><i>1b</i>`, string(actual)); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestInterpreterReloadsChangedFiles(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "hello.templ")
+	write := func(contents string, mod int64) {
+		if err := os.WriteFile(fileName, []byte(contents), 0o644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+		// Set the modification time explicitly, since the file may be written twice within the resolution of the clock.
+		modTime := time.Unix(mod, 0)
+		if err := os.Chtimes(fileName, modTime, modTime); err != nil {
+			t.Fatalf("failed to set modification time: %v", err)
+		}
+	}
+	in := New()
+	c := in.Component(fileName, "hello", "World")
+
+	write("package main\n\ntempl hello(name string) {\n\t<p>Hello, { name }</p>\n}\n", 1)
+	actual, err := templ.ToGoHTML(context.Background(), c)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(actual) != "<p>Hello, World</p>" {
+		t.Errorf("unexpected output %q", actual)
+	}
+
+	write("package main\n\ntempl hello(name string) {\n\t<h1>Goodbye, { name }</h1>\n}\n", 2)
+	actual, err = templ.ToGoHTML(context.Background(), c)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(actual) != "<h1>Goodbye, World</h1>" {
+		t.Errorf("unexpected output %q", actual)
+	}
+}
+
+func TestHotReloader(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "hello.templ")
+	if err := os.WriteFile(fileName, []byte("package main\n\ntempl hello(name string) {\n\t<p>Hello, { name }</p>\n}\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	modTime := time.Unix(100, 0)
+	if err := os.Chtimes(fileName, modTime, modTime); err != nil {
+		t.Fatalf("failed to set modification time: %v", err)
+	}
+	t.Run("files that haven't changed since the program started use the compiled code", func(t *testing.T) {
+		r := NewHotReloader(New(), modTime)
+		if _, ok := r(fileName, "hello", "World"); ok {
+			t.Error("expected the compiled code to be used")
+		}
+	})
+	t.Run("files that have changed are interpreted", func(t *testing.T) {
+		r := NewHotReloader(New(), modTime.Add(-time.Second))
+		c, ok := r(fileName, "hello", "World")
+		if !ok {
+			t.Fatal("expected the template to be hot reloaded")
+		}
+		actual, err := templ.ToGoHTML(context.Background(), c)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(actual) != "<p>Hello, World</p>" {
+			t.Errorf("unexpected output %q", actual)
+		}
+	})
+	t.Run("missing files use the compiled code", func(t *testing.T) {
+		r := NewHotReloader(New(), time.Time{})
+		if _, ok := r(filepath.Join(t.TempDir(), "missing.templ"), "hello", "World"); ok {
+			t.Error("expected the compiled code to be used")
+		}
+	})
+}
+
+func TestInterpreterErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		args     []any
+		expected string
+	}{
+		{
+			name:     "undefined variables",
+			template: "templ t() {\n\t<p>{ missing }</p>\n}",
+			expected: "undefined: missing",
+		},
+		{
+			name:     "unsupported expressions",
+			template: "templ t() {\n\t<p>{ func() string { return \"\" }() }</p>\n}",
+			expected: "*ast.FuncLit expressions aren't supported by the interpreter",
+		},
+		{
+			name:     "non-bool conditions",
+			template: "templ t(s string) {\n\tif s {\n\t\t<p></p>\n\t}\n}",
+			args:     []any{"a"},
+			expected: "expected a bool, got string",
+		},
+		{
+			name:     "wrong number of arguments",
+			template: "templ t(s string) {\n\t<p>{ s }</p>\n}",
+			expected: `template "t" expects 1 arguments, got 0`,
+		},
+		{
+			name:     "wrong number of arguments to inline templates",
+			template: "templ t() {\n\ttempl inner(s string) {\n\t\t<p>{ s }</p>\n\t}\n\t@inner()\n}",
+			expected: `template "inner" expects 1 arguments, got 0`,
+		},
+		{
+			name:     "unsupported init statements",
+			template: "templ t(i int) {\n\tif i++; i > 0 {\n\t\t<p></p>\n\t}\n}",
+			args:     []any{1},
+			expected: "only init statements that declare variables",
+		},
+		{
+			name:     "missing fields",
+			template: "templ t(i item) {\n\t<p>{ i.Missing }</p>\n}",
+			args:     []any{item{}},
+			expected: "interpreter.item has no field or method Missing",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fileName := filepath.Join(t.TempDir(), "t.templ")
+			if err := os.WriteFile(fileName, []byte("package main\n\n"+test.template+"\n"), 0o644); err != nil {
+				t.Fatalf("failed to write file: %v", err)
+			}
+			_, err := templ.ToGoHTML(context.Background(), New().Component(fileName, "t", test.args...))
+			if err == nil {
+				t.Fatal("expected an error, got nil")
+			}
+			if !strings.Contains(err.Error(), test.expected) {
+				t.Errorf("expected error containing %q, got %q", test.expected, err.Error())
+			}
+		})
+	}
+	t.Run("errors include the position of the expression", func(t *testing.T) {
+		fileName := filepath.Join(t.TempDir(), "t.templ")
+		if err := os.WriteFile(fileName, []byte("package main\n\ntempl t() {\n\t<p>{ missing }</p>\n}\n"), 0o644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+		_, err := templ.ToGoHTML(context.Background(), New().Component(fileName, "t"))
+		var te templ.Error
+		if !errors.As(err, &te) {
+			t.Fatalf("expected a templ.Error, got %v", err)
+		}
+		if te.Line != 3 || te.FileName != fileName {
+			t.Errorf("expected error at %s line 3, got %s line %d", fileName, te.FileName, te.Line)
+		}
+	})
+}
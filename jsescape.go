@@ -0,0 +1,19 @@
+package templ
+
+import "strings"
+
+var jsTemplateLiteralReplacer = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	"${", `\${`,
+	"\r", `\r`,
+	// Prevent the value from closing the script element, or starting a
+	// comment, e.g. </script> or <!--.
+	"<", `\u003c`,
+)
+
+// EscapeJSTemplateLiteral escapes s for use within a JavaScript template
+// literal, e.g. `Hello, {{ name }}` in a script element.
+func EscapeJSTemplateLiteral(s string) string {
+	return jsTemplateLiteralReplacer.Replace(s)
+}
@@ -0,0 +1,60 @@
+package templ_test
+
+import (
+	"testing"
+
+	"github.com/a-h/templ"
+)
+
+func TestEscapeJSTemplateLiteral(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "text is unchanged",
+			input:    "Hello, World",
+			expected: "Hello, World",
+		},
+		{
+			name:     "backticks are escaped",
+			input:    "a`b",
+			expected: "a\\`b",
+		},
+		{
+			name:     "substitutions are escaped",
+			input:    "${alert(1)}",
+			expected: `\${alert(1)}`,
+		},
+		{
+			name:     "dollars that don't start substitutions are unchanged",
+			input:    "$10 {x}",
+			expected: "$10 {x}",
+		},
+		{
+			name:     "backslashes are escaped",
+			input:    `C:\path\${x}`,
+			expected: `C:\\path\\\${x}`,
+		},
+		{
+			name:     "script end tags are escaped",
+			input:    "</script><script>alert(1)</script>",
+			expected: `\u003c/script>\u003cscript>alert(1)\u003c/script>`,
+		},
+		{
+			name:     "carriage returns are escaped",
+			input:    "a\r\nb",
+			expected: "a\\r\nb",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			actual := templ.EscapeJSTemplateLiteral(tt.input)
+			if actual != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package templ
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var _ Component = JSONLDElement{}
+
+const schemaOrgContext = "https://schema.org"
+
+// JSONLD renders structured data inside a JSON-LD script element.
+// e.g. <script type="application/ld+json">{"@context":"https://schema.org","@type":"Article"}</script>
+//
+// If the data is an object without an "@context" property, the schema.org
+// context is added.
+func JSONLD(data any) JSONLDElement {
+	return JSONLDElement{
+		Data:  data,
+		Nonce: GetNonce,
+	}
+}
+
+func (j JSONLDElement) WithNonceFromString(nonce string) JSONLDElement {
+	j.Nonce = func(context.Context) string {
+		return nonce
+	}
+	return j
+}
+
+func (j JSONLDElement) WithNonceFrom(f func(context.Context) string) JSONLDElement {
+	j.Nonce = f
+	return j
+}
+
+// WithValidation validates the properties of common schema.org types before
+// rendering. See Validate.
+func (j JSONLDElement) WithValidation() JSONLDElement {
+	j.ValidateTypes = true
+	return j
+}
+
+type JSONLDElement struct {
+	// Data that will be encoded as JSON.
+	Data any
+	// Nonce is a function that returns a CSP nonce.
+	// Defaults to CSPNonceFromContext.
+	// See https://content-security-policy.com/nonce for more information.
+	Nonce func(ctx context.Context) string
+	// ValidateTypes causes Render to return an error if the data is not valid.
+	ValidateTypes bool
+}
+
+func (j JSONLDElement) Render(ctx context.Context, w io.Writer) (err error) {
+	data, err := j.marshal()
+	if err != nil {
+		return err
+	}
+	if j.ValidateTypes {
+		if err = validateJSONLD(data); err != nil {
+			return err
+		}
+	}
+	var nonceAttr string
+	if j.Nonce != nil {
+		if nonce := j.Nonce(ctx); nonce != "" {
+			nonceAttr = fmt.Sprintf(" nonce=\"%s\"", EscapeString(nonce))
+		}
+	}
+	if _, err = fmt.Fprintf(w, "<script type=\"application/ld+json\"%s>", nonceAttr); err != nil {
+		return err
+	}
+	if _, err = w.Write(data); err != nil {
+		return err
+	}
+	if _, err = io.WriteString(w, "</script>"); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Validate checks that objects with a schema.org type of Article, Product or
+// BreadcrumbList have the properties that search engines require.
+func (j JSONLDElement) Validate() error {
+	data, err := j.marshal()
+	if err != nil {
+		return err
+	}
+	return validateJSONLD(data)
+}
+
+// marshal encodes the data as JSON, adding the schema.org context to objects
+// that don't have one. <, > and & are escaped by the encoder, so the output
+// can't close the script element.
+func (j JSONLDElement) marshal() (data []byte, err error) {
+	data, err = json.Marshal(j.Data)
+	if err != nil {
+		return nil, fmt.Errorf("templ: failed to marshal JSON-LD: %w", err)
+	}
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '{' {
+		return data, nil
+	}
+	var properties map[string]json.RawMessage
+	if err = json.Unmarshal(data, &properties); err != nil {
+		return nil, fmt.Errorf("templ: failed to marshal JSON-LD: %w", err)
+	}
+	if _, ok := properties["@context"]; ok {
+		return data, nil
+	}
+	// Prepend the context, keeping the order of the other properties.
+	prefix := `{"@context":"` + schemaOrgContext + `"`
+	if len(properties) > 0 {
+		prefix += ","
+	}
+	return append([]byte(prefix), data[1:]...), nil
+}
+
+// jsonLDRequired are the properties that search engines require for each type.
+var jsonLDRequired = map[string][]string{
+	"Article":        {"headline"},
+	"NewsArticle":    {"headline"},
+	"BlogPosting":    {"headline"},
+	"Product":        {"name"},
+	"BreadcrumbList": {"itemListElement"},
+}
+
+func validateJSONLD(data []byte) error {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("templ: invalid JSON-LD: %w", err)
+	}
+	var errs []error
+	validateJSONLDValue(v, "$", &errs)
+	return errors.Join(errs...)
+}
+
+func validateJSONLDValue(v any, path string, errs *[]error) {
+	switch v := v.(type) {
+	case []any:
+		for i, item := range v {
+			validateJSONLDValue(item, fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	case map[string]any:
+		if graph, ok := v["@graph"]; ok {
+			validateJSONLDValue(graph, path+".@graph", errs)
+		}
+		for _, t := range jsonLDTypes(v["@type"]) {
+			validateJSONLDObject(t, v, path, errs)
+		}
+	}
+}
+
+func validateJSONLDObject(t string, v map[string]any, path string, errs *[]error) {
+	fail := func(format string, a ...any) {
+		*errs = append(*errs, fmt.Errorf("templ: invalid JSON-LD %s at %s: %s", t, path, fmt.Sprintf(format, a...)))
+	}
+	for _, name := range jsonLDRequired[t] {
+		if isEmptyJSONLD(v[name]) {
+			fail("missing required property %q", name)
+		}
+	}
+	switch t {
+	case "Product":
+		// A product must have at least one of these properties to be shown in search results.
+		if isEmptyJSONLD(v["offers"]) && isEmptyJSONLD(v["review"]) && isEmptyJSONLD(v["aggregateRating"]) {
+			fail(`one of "offers", "review" or "aggregateRating" is required`)
+		}
+	case "BreadcrumbList":
+		items, _ := v["itemListElement"].([]any)
+		for i, item := range items {
+			li, ok := item.(map[string]any)
+			if !ok {
+				fail("itemListElement[%d] must be a ListItem", i)
+				continue
+			}
+			if position, ok := li["position"].(float64); !ok || position != float64(i+1) {
+				fail("itemListElement[%d] must have a position of %d", i, i+1)
+			}
+			if isEmptyJSONLD(li["name"]) && !hasJSONLDName(li["item"]) {
+				fail("itemListElement[%d] is missing required property %q", i, "name")
+			}
+			// The item URL is only optional for the last breadcrumb, which is the current page.
+			if i < len(items)-1 && isEmptyJSONLD(li["item"]) {
+				fail("itemListElement[%d] is missing required property %q", i, "item")
+			}
+		}
+	}
+}
+
+func jsonLDTypes(v any) (types []string) {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		for _, t := range v {
+			if s, ok := t.(string); ok {
+				types = append(types, s)
+			}
+		}
+	}
+	return types
+}
+
+func hasJSONLDName(v any) bool {
+	m, ok := v.(map[string]any)
+	return ok && !isEmptyJSONLD(m["name"])
+}
+
+func isEmptyJSONLD(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []any:
+		return len(v) == 0
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package templ_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestJSONLDElement(t *testing.T) {
+	type article struct {
+		Type     string `json:"@type"`
+		Headline string `json:"headline"`
+	}
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		e        templ.JSONLDElement
+		expected string
+	}{
+		{
+			name:     "the schema.org context is added to objects",
+			e:        templ.JSONLD(article{Type: "Article", Headline: "Hello"}),
+			expected: `<script type="application/ld+json">{"@context":"https://schema.org","@type":"Article","headline":"Hello"}</script>`,
+		},
+		{
+			name:     "an existing context is not replaced",
+			e:        templ.JSONLD(map[string]any{"@context": "https://example.com", "@type": "Thing"}),
+			expected: `<script type="application/ld+json">{"@context":"https://example.com","@type":"Thing"}</script>`,
+		},
+		{
+			name:     "empty objects have a context",
+			e:        templ.JSONLD(map[string]any{}),
+			expected: `<script type="application/ld+json">{"@context":"https://schema.org"}</script>`,
+		},
+		{
+			name:     "arrays are not changed",
+			e:        templ.JSONLD([]string{"a"}),
+			expected: `<script type="application/ld+json">["a"]</script>`,
+		},
+		{
+			name:     "HTML is escaped, so that the script element can't be closed",
+			e:        templ.JSONLD(article{Type: "Article", Headline: "</script><script>alert(1)</script>"}),
+			expected: `<script type="application/ld+json">{"@context":"https://schema.org","@type":"Article","headline":"\u003c/script\u003e\u003cscript\u003ealert(1)\u003c/script\u003e"}</script>`,
+		},
+		{
+			name:     "if a nonce is available in the context, it is used",
+			ctx:      templ.WithNonce(context.Background(), "nonce-from-context"),
+			e:        templ.JSONLD([]string{}),
+			expected: `<script type="application/ld+json" nonce="nonce-from-context">[]</script>`,
+		},
+		{
+			name:     "if a nonce is provided, it is used",
+			e:        templ.JSONLD([]string{}).WithNonceFromString("nonce-from-string"),
+			expected: `<script type="application/ld+json" nonce="nonce-from-string">[]</script>`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := new(bytes.Buffer)
+			if err := tt.e.Render(tt.ctx, w); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, w.String()); diff != "" {
+				t.Fatalf("unexpected output (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestJSONLDValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     any
+		expected []string
+	}{
+		{
+			name: "valid types",
+			data: []any{
+				map[string]any{"@type": "Article", "headline": "Hello"},
+				map[string]any{"@type": "Product", "name": "Hat", "offers": map[string]any{"@type": "Offer", "price": 10}},
+				map[string]any{"@type": "BreadcrumbList", "itemListElement": []any{
+					map[string]any{"@type": "ListItem", "position": 1, "name": "Home", "item": "https://example.com"},
+					map[string]any{"@type": "ListItem", "position": 2, "name": "Hats"},
+				}},
+			},
+		},
+		{
+			name: "unknown types are not validated",
+			data: map[string]any{"@type": "Event"},
+		},
+		{
+			name:     "articles require a headline",
+			data:     map[string]any{"@type": []string{"Article", "Thing"}},
+			expected: []string{`templ: invalid JSON-LD Article at $: missing required property "headline"`},
+		},
+		{
+			name: "products require a name and offer, review or rating",
+			data: map[string]any{"@graph": []any{map[string]any{"@type": "Product"}}},
+			expected: []string{
+				`templ: invalid JSON-LD Product at $.@graph[0]: missing required property "name"`,
+				`templ: invalid JSON-LD Product at $.@graph[0]: one of "offers", "review" or "aggregateRating" is required`,
+			},
+		},
+		{
+			name: "breadcrumbs require positions, names and items",
+			data: map[string]any{"@type": "BreadcrumbList", "itemListElement": []any{
+				map[string]any{"@type": "ListItem", "position": 2},
+				map[string]any{"@type": "ListItem", "position": 2, "item": map[string]any{"name": "Hats"}},
+			}},
+			expected: []string{
+				`templ: invalid JSON-LD BreadcrumbList at $: itemListElement[0] must have a position of 1`,
+				`templ: invalid JSON-LD BreadcrumbList at $: itemListElement[0] is missing required property "name"`,
+				`templ: invalid JSON-LD BreadcrumbList at $: itemListElement[0] is missing required property "item"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var actual []string
+			if err := templ.JSONLD(tt.data).Validate(); err != nil {
+				actual = strings.Split(err.Error(), "\n")
+			}
+			if diff := cmp.Diff(tt.expected, actual); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+	t.Run("Render returns validation errors when enabled", func(t *testing.T) {
+		w := new(bytes.Buffer)
+		err := templ.JSONLD(map[string]any{"@type": "Article"}).WithValidation().Render(context.Background(), w)
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+		if w.Len() != 0 {
+			t.Errorf("expected no output, got %q", w.String())
+		}
+	})
+}
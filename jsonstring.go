@@ -4,7 +4,8 @@ import (
 	"encoding/json"
 )
 
-// JSONString returns a JSON encoded string of v.
+// JSONString returns a JSON encoded string of v. <, > and & are escaped as
+// Unicode escape sequences, so the JSON can be used within a script element.
 func JSONString(v any) (string, error) {
 	b, err := json.Marshal(v)
 	if err != nil {
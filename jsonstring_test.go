@@ -18,6 +18,16 @@ func TestJSONString(t *testing.T) {
 			t.Fatalf("unexpected output: want %q, got %q", expected, actual)
 		}
 	})
+	t.Run("escapes HTML, so that the JSON can't close a script element", func(t *testing.T) {
+		actual, err := templ.JSONString("</script><!--&")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := `"\u003c/script\u003e\u003c!--\u0026"`
+		if actual != expected {
+			t.Fatalf("unexpected output: want %q, got %q", expected, actual)
+		}
+	})
 	t.Run("returns an error if the data cannot be marshalled", func(t *testing.T) {
 		data := make(chan int)
 		_, err := templ.JSONString(data)
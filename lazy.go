@@ -0,0 +1,27 @@
+package templ
+
+import (
+	"context"
+	"io"
+)
+
+// Lazy returns a component that calls f each time it's rendered, and renders
+// the component that f returns. f isn't called if the component isn't
+// rendered, e.g. because it's in an if statement whose condition is false, so
+// expensive work, like fetching data, can be declared alongside the markup
+// that uses it.
+//
+// If f returns an error, nothing is written, and the error is returned. If f
+// returns a nil component, nothing is written.
+func Lazy(f func(ctx context.Context) (Component, error)) Component {
+	return ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		c, err := f(ctx)
+		if err != nil {
+			return err
+		}
+		if c == nil {
+			return nil
+		}
+		return c.Render(ctx, w)
+	})
+}
@@ -0,0 +1,74 @@
+package templ_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+)
+
+func TestLazy(t *testing.T) {
+	t.Run("the function is only called when the component is rendered", func(t *testing.T) {
+		var calls int
+		c := templ.Lazy(func(ctx context.Context) (templ.Component, error) {
+			calls++
+			return templ.Raw("<p>loaded</p>"), nil
+		})
+		if calls != 0 {
+			t.Fatalf("expected the function not to be called before rendering, got %d calls", calls)
+		}
+		for i := 1; i <= 2; i++ {
+			var sb strings.Builder
+			if err := c.Render(context.Background(), &sb); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if sb.String() != "<p>loaded</p>" {
+				t.Errorf("unexpected output: %q", sb.String())
+			}
+			if calls != i {
+				t.Errorf("expected %d calls, got %d", i, calls)
+			}
+		}
+	})
+	t.Run("the render context is passed to the function", func(t *testing.T) {
+		type key struct{}
+		ctx := context.WithValue(context.Background(), key{}, "value")
+		c := templ.Lazy(func(ctx context.Context) (templ.Component, error) {
+			return templ.Raw(ctx.Value(key{}).(string)), nil
+		})
+		var sb strings.Builder
+		if err := c.Render(ctx, &sb); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if sb.String() != "value" {
+			t.Errorf("unexpected output: %q", sb.String())
+		}
+	})
+	t.Run("errors are returned", func(t *testing.T) {
+		expected := errors.New("failed to fetch")
+		c := templ.Lazy(func(ctx context.Context) (templ.Component, error) {
+			return nil, expected
+		})
+		var sb strings.Builder
+		if err := c.Render(context.Background(), &sb); !errors.Is(err, expected) {
+			t.Errorf("expected %v, got %v", expected, err)
+		}
+		if sb.Len() != 0 {
+			t.Errorf("expected nothing to be written, got %q", sb.String())
+		}
+	})
+	t.Run("nil components render nothing", func(t *testing.T) {
+		c := templ.Lazy(func(ctx context.Context) (templ.Component, error) {
+			return nil, nil
+		})
+		var sb strings.Builder
+		if err := c.Render(context.Background(), &sb); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if sb.Len() != 0 {
+			t.Errorf("expected nothing to be written, got %q", sb.String())
+		}
+	})
+}
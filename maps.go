@@ -0,0 +1,36 @@
+package templ
+
+import (
+	"cmp"
+	"slices"
+)
+
+// SortedMap returns the key/value pairs of m, ordered by key.
+//
+// Iterating over a Go map returns keys in a random order, so SortedMap can be
+// used to produce deterministic output.
+func SortedMap[K cmp.Ordered, V any](m map[K]V) []KeyValue[K, V] {
+	kvs := make([]KeyValue[K, V], 0, len(m))
+	for k, v := range m {
+		kvs = append(kvs, KeyValue[K, V]{Key: k, Value: v})
+	}
+	slices.SortFunc(kvs, func(a, b KeyValue[K, V]) int {
+		return cmp.Compare(a.Key, b.Key)
+	})
+	return kvs
+}
+
+// Sorted returns an iterator over the key/value pairs of m, ordered by key,
+// e.g. `for k, v := range templ.Sorted(m)`.
+//
+// Within a templ file, ranging over Sorted is rewritten to use SortedMap, so
+// that it can be used with versions of Go that can't range over functions.
+func Sorted[K cmp.Ordered, V any](m map[K]V) func(yield func(K, V) bool) {
+	return func(yield func(K, V) bool) {
+		for _, kv := range SortedMap(m) {
+			if !yield(kv.Key, kv.Value) {
+				return
+			}
+		}
+	}
+}
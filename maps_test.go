@@ -0,0 +1,67 @@
+package templ_test
+
+import (
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSortedMap(t *testing.T) {
+	t.Run("key/value pairs are ordered by key", func(t *testing.T) {
+		m := map[string]int{"c": 3, "a": 1, "b": 2}
+		expected := []templ.KeyValue[string, int]{
+			{Key: "a", Value: 1},
+			{Key: "b", Value: 2},
+			{Key: "c", Value: 3},
+		}
+		if diff := cmp.Diff(expected, templ.SortedMap(m)); diff != "" {
+			t.Error(diff)
+		}
+	})
+	t.Run("numeric keys are ordered numerically", func(t *testing.T) {
+		m := map[int]string{10: "ten", 2: "two", -1: "minus one"}
+		expected := []templ.KeyValue[int, string]{
+			{Key: -1, Value: "minus one"},
+			{Key: 2, Value: "two"},
+			{Key: 10, Value: "ten"},
+		}
+		if diff := cmp.Diff(expected, templ.SortedMap(m)); diff != "" {
+			t.Error(diff)
+		}
+	})
+	t.Run("nil maps return an empty slice", func(t *testing.T) {
+		if actual := templ.SortedMap[string, string](nil); len(actual) != 0 {
+			t.Errorf("expected empty slice, got %v", actual)
+		}
+	})
+}
+
+func TestSorted(t *testing.T) {
+	m := map[string]int{"c": 3, "a": 1, "b": 2}
+	t.Run("key/value pairs are yielded in key order", func(t *testing.T) {
+		var keys []string
+		var values []int
+		templ.Sorted(m)(func(k string, v int) bool {
+			keys = append(keys, k)
+			values = append(values, v)
+			return true
+		})
+		if diff := cmp.Diff([]string{"a", "b", "c"}, keys); diff != "" {
+			t.Error(diff)
+		}
+		if diff := cmp.Diff([]int{1, 2, 3}, values); diff != "" {
+			t.Error(diff)
+		}
+	})
+	t.Run("iteration stops when yield returns false", func(t *testing.T) {
+		var keys []string
+		templ.Sorted(m)(func(k string, v int) bool {
+			keys = append(keys, k)
+			return k != "b"
+		})
+		if diff := cmp.Diff([]string{"a", "b"}, keys); diff != "" {
+			t.Error(diff)
+		}
+	})
+}
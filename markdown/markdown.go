@@ -0,0 +1,65 @@
+// Package markdown renders Markdown as HTML. Templates use it to render
+// markdown blocks, e.g. @markdown { # Title }, and other components can use it
+// to render Markdown from files or databases.
+//
+// Markdown is rendered with goldmark by default, which escapes raw HTML. Use
+// WithRenderer to use another renderer, or goldmark with extensions.
+package markdown
+
+import (
+	"context"
+	"io"
+
+	"github.com/a-h/templ"
+	"github.com/yuin/goldmark"
+)
+
+// Renderer writes the HTML of the Markdown source to w.
+type Renderer func(w io.Writer, src string) error
+
+// Goldmark returns a Renderer that converts Markdown with md, e.g. goldmark
+// configured with extensions.
+func Goldmark(md goldmark.Markdown) Renderer {
+	return func(w io.Writer, src string) error {
+		return md.Convert([]byte(src), w)
+	}
+}
+
+// Default renders CommonMark with goldmark.
+var Default = Goldmark(goldmark.New())
+
+type rendererKey struct{}
+
+// WithRenderer sets the Renderer that's used by the components that are
+// rendered with ctx.
+func WithRenderer(ctx context.Context, r Renderer) context.Context {
+	return context.WithValue(ctx, rendererKey{}, r)
+}
+
+// GetRenderer returns the Renderer set with WithRenderer, or Default.
+func GetRenderer(ctx context.Context) Renderer {
+	if r, ok := ctx.Value(rendererKey{}).(Renderer); ok && r != nil {
+		return r
+	}
+	return Default
+}
+
+// Component renders the Markdown source as HTML, with the Renderer of the
+// context. The source is trusted, so it must not contain user input unless
+// the Renderer escapes raw HTML.
+func Component(src string) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		return GetRenderer(ctx)(w, src)
+	})
+}
+
+// String returns the HTML of the Markdown source, rendered with Default, e.g.
+// to render markdown blocks when templates are generated.
+func String(src string) (html string, err error) {
+	buf := templ.GetBuffer()
+	defer templ.ReleaseBuffer(buf)
+	if err = Default(buf, src); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,48 @@
+package markdown
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestComponent(t *testing.T) {
+	t.Run("Markdown is rendered with goldmark by default", func(t *testing.T) {
+		w := new(strings.Builder)
+		if err := Component("# Title\n\nSome *text* & <b>HTML</b>.").Render(context.Background(), w); err != nil {
+			t.Fatalf("failed to render: %v", err)
+		}
+		expected := "<h1>Title</h1>\n<p>Some <em>text</em> &amp; <!-- raw HTML omitted -->HTML<!-- raw HTML omitted -->.</p>\n"
+		if diff := cmp.Diff(expected, w.String()); diff != "" {
+			t.Error(diff)
+		}
+	})
+	t.Run("the renderer of the context is used", func(t *testing.T) {
+		ctx := WithRenderer(context.Background(), func(w io.Writer, src string) error {
+			_, err := fmt.Fprintf(w, "<pre>%s</pre>", src)
+			return err
+		})
+		w := new(strings.Builder)
+		if err := Component("# Title").Render(ctx, w); err != nil {
+			t.Fatalf("failed to render: %v", err)
+		}
+		if diff := cmp.Diff("<pre># Title</pre>", w.String()); diff != "" {
+			t.Error(diff)
+		}
+	})
+}
+
+func TestString(t *testing.T) {
+	actual, err := String("- a\n- b")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "<ul>\n<li>a</li>\n<li>b</li>\n</ul>\n"
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,239 @@
+// Package meta provides a component that renders the title, description,
+// canonical link, Open Graph and Twitter card tags of a page.
+//
+//	<head>
+//		@meta.Head(meta.Tags{Title: "templ", Description: "Build HTML with Go"})
+//	</head>
+package meta
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/a-h/templ"
+)
+
+const (
+	// MaxTitleLength is the number of characters of a title that search engines display.
+	MaxTitleLength = 60
+	// MaxDescriptionLength is the number of characters of a description that search engines display.
+	MaxDescriptionLength = 160
+	// MaxTwitterTitleLength is the maximum length of twitter:title.
+	MaxTwitterTitleLength = 70
+	// MaxTwitterDescriptionLength is the maximum length of twitter:description.
+	MaxTwitterDescriptionLength = 200
+	// MaxTwitterImageAltLength is the maximum length of twitter:image:alt.
+	MaxTwitterImageAltLength = 420
+)
+
+// Tags are the metadata of a page.
+type Tags struct {
+	// Title of the page, which is required.
+	Title       string
+	Description string
+	// Canonical is the absolute URL of the preferred version of the page.
+	Canonical string
+	// OpenGraph tags are rendered if it's not nil.
+	OpenGraph *OpenGraph
+	// Twitter card tags are rendered if it's not nil.
+	Twitter *Twitter
+}
+
+// OpenGraph is the Open Graph metadata of a page, see https://ogp.me.
+type OpenGraph struct {
+	// Type of the object, e.g. "article". Defaults to "website".
+	Type string
+	// Title defaults to the title of the page.
+	Title string
+	// Description defaults to the description of the page.
+	Description string
+	// URL is the absolute URL of the page. Defaults to the canonical URL.
+	URL string
+	// Image is required.
+	Image    *Image
+	SiteName string
+	// Locale of the page, e.g. "en_GB".
+	Locale string
+}
+
+// Image is an Open Graph image.
+type Image struct {
+	// URL is the absolute URL of the image.
+	URL string
+	Alt string
+	// Width and Height of the image in pixels, which are omitted if they're zero.
+	Width  int
+	Height int
+	// Type is the MIME type of the image, e.g. "image/png".
+	Type string
+}
+
+// Card is the type of a Twitter card.
+type Card string
+
+const (
+	Summary           Card = "summary"
+	SummaryLargeImage Card = "summary_large_image"
+)
+
+// Twitter is the Twitter card metadata of a page. Twitter uses Open Graph
+// tags for fields that aren't set.
+type Twitter struct {
+	// Card is required.
+	Card Card
+	// Site is the @username of the website.
+	Site string
+	// Creator is the @username of the author.
+	Creator     string
+	Title       string
+	Description string
+	// Image is the absolute URL of the image.
+	Image    string
+	ImageAlt string
+}
+
+// Validate returns an error that describes each invalid or missing tag.
+func (t Tags) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, a ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("meta: "+format, a...))
+		}
+	}
+	checkLength := func(name, value string, max int) {
+		n := utf8.RuneCountInString(value)
+		check(n <= max, "%s is %d characters, the maximum is %d", name, n, max)
+	}
+	checkURL := func(name, value string) {
+		if value == "" {
+			return
+		}
+		u, err := url.Parse(value)
+		check(err == nil && u.IsAbs() && u.Host != "", "%s %q must be an absolute URL", name, value)
+	}
+
+	check(t.Title != "", "title is required")
+	checkLength("title", t.Title, MaxTitleLength)
+	checkLength("description", t.Description, MaxDescriptionLength)
+	checkURL("canonical", t.Canonical)
+
+	if og := t.openGraph(); og != nil {
+		check(og.URL != "", "og:url is required, set the canonical URL or the Open Graph URL")
+		checkURL("og:url", og.URL)
+		if check(og.Image != nil, "og:image is required"); og.Image != nil {
+			check(og.Image.URL != "", "og:image is required")
+			checkURL("og:image", og.Image.URL)
+			check(og.Image.Width >= 0 && og.Image.Height >= 0, "og:image dimensions must not be negative")
+		}
+	}
+
+	if tw := t.Twitter; tw != nil {
+		check(tw.Card == Summary || tw.Card == SummaryLargeImage, "twitter:card must be %q or %q, got %q", Summary, SummaryLargeImage, tw.Card)
+		check(tw.Site == "" || strings.HasPrefix(tw.Site, "@"), "twitter:site %q must be an @username", tw.Site)
+		check(tw.Creator == "" || strings.HasPrefix(tw.Creator, "@"), "twitter:creator %q must be an @username", tw.Creator)
+		checkLength("twitter:title", tw.Title, MaxTwitterTitleLength)
+		checkLength("twitter:description", tw.Description, MaxTwitterDescriptionLength)
+		checkURL("twitter:image", tw.Image)
+		checkLength("twitter:image:alt", tw.ImageAlt, MaxTwitterImageAltLength)
+		check(tw.ImageAlt == "" || tw.Image != "", "twitter:image:alt requires twitter:image")
+		if tw.Card == SummaryLargeImage {
+			check(tw.Image != "" || t.OpenGraph != nil && t.OpenGraph.Image != nil, "the %s card requires twitter:image or og:image", tw.Card)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// openGraph returns the Open Graph metadata with defaults applied, or nil.
+func (t Tags) openGraph() *OpenGraph {
+	if t.OpenGraph == nil {
+		return nil
+	}
+	og := *t.OpenGraph
+	if og.Type == "" {
+		og.Type = "website"
+	}
+	if og.Title == "" {
+		og.Title = t.Title
+	}
+	if og.Description == "" {
+		og.Description = t.Description
+	}
+	if og.URL == "" {
+		og.URL = t.Canonical
+	}
+	return &og
+}
+
+type tag struct {
+	Name    string
+	Content string
+}
+
+// openGraphTags returns the og: properties in the order that they're rendered.
+func (t Tags) openGraphTags() (tags []tag) {
+	og := t.openGraph()
+	if og == nil {
+		return nil
+	}
+	add := func(name, content string) {
+		if content != "" {
+			tags = append(tags, tag{Name: name, Content: content})
+		}
+	}
+	add("og:type", og.Type)
+	add("og:title", og.Title)
+	add("og:description", og.Description)
+	add("og:url", og.URL)
+	add("og:site_name", og.SiteName)
+	add("og:locale", og.Locale)
+	if img := og.Image; img != nil {
+		add("og:image", img.URL)
+		add("og:image:alt", img.Alt)
+		add("og:image:type", img.Type)
+		if img.Width > 0 {
+			add("og:image:width", strconv.Itoa(img.Width))
+		}
+		if img.Height > 0 {
+			add("og:image:height", strconv.Itoa(img.Height))
+		}
+	}
+	return tags
+}
+
+// twitterTags returns the twitter: names in the order that they're rendered.
+func (t Tags) twitterTags() (tags []tag) {
+	tw := t.Twitter
+	if tw == nil {
+		return nil
+	}
+	add := func(name, content string) {
+		if content != "" {
+			tags = append(tags, tag{Name: name, Content: content})
+		}
+	}
+	add("twitter:card", string(tw.Card))
+	add("twitter:site", tw.Site)
+	add("twitter:creator", tw.Creator)
+	add("twitter:title", tw.Title)
+	add("twitter:description", tw.Description)
+	add("twitter:image", tw.Image)
+	add("twitter:image:alt", tw.ImageAlt)
+	return tags
+}
+
+// Head renders the <title>, <meta> and <link> tags of the page. Rendering
+// returns an error, without writing any output, if the tags aren't valid.
+func Head(t Tags) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		if err := t.Validate(); err != nil {
+			return err
+		}
+		return head(t).Render(ctx, w)
+	})
+}
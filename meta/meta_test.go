@@ -0,0 +1,145 @@
+package meta
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func render(t *testing.T, tags Tags) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Head(tags).Render(context.Background(), &buf); err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+	return buf.String()
+}
+
+func TestHead(t *testing.T) {
+	t.Run("title and description", func(t *testing.T) {
+		actual := render(t, Tags{
+			Title:       "Tom & Jerry",
+			Description: `A "cartoon"`,
+			Canonical:   "https://example.com/tom-and-jerry",
+		})
+		expected := `<title>Tom &amp; Jerry</title><meta name="description" content="A &#34;cartoon&#34;"><link rel="canonical" href="https://example.com/tom-and-jerry">`
+		if diff := cmp.Diff(expected, actual); diff != "" {
+			t.Error(diff)
+		}
+	})
+	t.Run("Open Graph tags default to the page metadata", func(t *testing.T) {
+		actual := render(t, Tags{
+			Title:     "templ",
+			Canonical: "https://templ.guide",
+			OpenGraph: &OpenGraph{
+				Image: &Image{URL: "https://templ.guide/img/logo.png", Alt: "templ logo", Width: 1200, Height: 630},
+			},
+		})
+		expected := `<title>templ</title>` +
+			`<link rel="canonical" href="https://templ.guide">` +
+			`<meta property="og:type" content="website">` +
+			`<meta property="og:title" content="templ">` +
+			`<meta property="og:url" content="https://templ.guide">` +
+			`<meta property="og:image" content="https://templ.guide/img/logo.png">` +
+			`<meta property="og:image:alt" content="templ logo">` +
+			`<meta property="og:image:width" content="1200">` +
+			`<meta property="og:image:height" content="630">`
+		if diff := cmp.Diff(expected, actual); diff != "" {
+			t.Error(diff)
+		}
+	})
+	t.Run("Twitter cards", func(t *testing.T) {
+		actual := render(t, Tags{
+			Title:   "templ",
+			Twitter: &Twitter{Card: SummaryLargeImage, Site: "@templ", Image: "https://templ.guide/img/logo.png"},
+		})
+		expected := `<title>templ</title>` +
+			`<meta name="twitter:card" content="summary_large_image">` +
+			`<meta name="twitter:site" content="@templ">` +
+			`<meta name="twitter:image" content="https://templ.guide/img/logo.png">`
+		if diff := cmp.Diff(expected, actual); diff != "" {
+			t.Error(diff)
+		}
+	})
+	t.Run("invalid tags are not rendered", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Head(Tags{}).Render(context.Background(), &buf); err == nil {
+			t.Error("expected an error")
+		}
+		if buf.Len() != 0 {
+			t.Errorf("expected no output, got %q", buf.String())
+		}
+	})
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		tags     Tags
+		expected []string
+	}{
+		{
+			name:     "title is required",
+			tags:     Tags{},
+			expected: []string{"meta: title is required"},
+		},
+		{
+			name: "lengths are limited",
+			tags: Tags{Title: strings.Repeat("a", 61), Description: strings.Repeat("é", 161)},
+			expected: []string{
+				"meta: title is 61 characters, the maximum is 60",
+				"meta: description is 161 characters, the maximum is 160",
+			},
+		},
+		{
+			name:     "canonical URLs must be absolute",
+			tags:     Tags{Title: "a", Canonical: "/about"},
+			expected: []string{`meta: canonical "/about" must be an absolute URL`},
+		},
+		{
+			name: "Open Graph requires a URL and image",
+			tags: Tags{Title: "a", OpenGraph: &OpenGraph{}},
+			expected: []string{
+				"meta: og:url is required, set the canonical URL or the Open Graph URL",
+				"meta: og:image is required",
+			},
+		},
+		{
+			name: "Twitter cards require a valid card type and usernames",
+			tags: Tags{Title: "a", Twitter: &Twitter{Site: "templ", ImageAlt: "logo"}},
+			expected: []string{
+				`meta: twitter:card must be "summary" or "summary_large_image", got ""`,
+				`meta: twitter:site "templ" must be an @username`,
+				"meta: twitter:image:alt requires twitter:image",
+			},
+		},
+		{
+			name:     "large image cards require an image",
+			tags:     Tags{Title: "a", Twitter: &Twitter{Card: SummaryLargeImage}},
+			expected: []string{"meta: the summary_large_image card requires twitter:image or og:image"},
+		},
+		{
+			name: "large image cards can use the Open Graph image",
+			tags: Tags{
+				Title:     "a",
+				Canonical: "https://example.com",
+				OpenGraph: &OpenGraph{Image: &Image{URL: "https://example.com/a.png"}},
+				Twitter:   &Twitter{Card: SummaryLargeImage},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var actual []string
+			if err := test.tags.Validate(); err != nil {
+				actual = strings.Split(err.Error(), "\n")
+			}
+			if diff := cmp.Diff(test.expected, actual); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
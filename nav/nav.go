@@ -0,0 +1,140 @@
+// Package nav provides context-based helpers for navigation, so that
+// components can show which links are active, and render breadcrumbs,
+// without the current path being passed through each layout.
+//
+//	http.Handle("/", nav.Middleware(templ.Handler(page())))
+package nav
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/a-h/templ"
+)
+
+type contextKeyType int
+
+const contextKey = contextKeyType(0)
+
+// state is the navigation state of a context. It's copied when modified, so
+// that contexts created by WithPath and WithCrumbs don't affect their parents.
+type state struct {
+	path   string
+	crumbs []Crumb
+}
+
+func getState(ctx context.Context) state {
+	s, _ := ctx.Value(contextKey).(state)
+	return s
+}
+
+// Crumb is a breadcrumb.
+type Crumb struct {
+	Title string
+	// URL of the page. If it's empty, the breadcrumb isn't a link.
+	URL string
+}
+
+// WithPath sets the path of the current page.
+func WithPath(ctx context.Context, path string) context.Context {
+	s := getState(ctx)
+	s.path = cleanPath(path)
+	return context.WithValue(ctx, contextKey, s)
+}
+
+// Path returns the path of the current page, set with WithPath or Middleware.
+func Path(ctx context.Context) string {
+	return getState(ctx).path
+}
+
+// Middleware sets the path of the current page to the path of the request.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(WithPath(r.Context(), r.URL.Path)))
+	})
+}
+
+// IsCurrent returns true if href links to the current page. The query and
+// fragment of href, and trailing slashes, are ignored.
+func IsCurrent(ctx context.Context, href string) bool {
+	path := Path(ctx)
+	return path != "" && path == hrefPath(href)
+}
+
+// IsActive returns true if href links to the current page, or to a section
+// that contains it, e.g. "/docs" is active on "/docs/install". The root path
+// is only active on the home page.
+func IsActive(ctx context.Context, href string) bool {
+	path, target := Path(ctx), hrefPath(href)
+	if path == "" || target == "" {
+		return false
+	}
+	if path == target {
+		return true
+	}
+	return target != "/" && strings.HasPrefix(path, target+"/")
+}
+
+// WithCrumbs adds breadcrumbs to the context.
+func WithCrumbs(ctx context.Context, crumbs ...Crumb) context.Context {
+	s := getState(ctx)
+	s.crumbs = append(s.crumbs[:len(s.crumbs):len(s.crumbs)], crumbs...)
+	return context.WithValue(ctx, contextKey, s)
+}
+
+// Crumbs returns the breadcrumbs added to the context.
+func Crumbs(ctx context.Context) []Crumb {
+	return getState(ctx).crumbs
+}
+
+// Section returns a component that renders its children with a breadcrumb
+// added to the context.
+func Section(title, url string) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		children := templ.GetChildren(ctx)
+		ctx = templ.ClearChildren(ctx)
+		return children.Render(WithCrumbs(ctx, Crumb{Title: title, URL: url}), w)
+	})
+}
+
+// Breadcrumbs renders the breadcrumbs of the context as an ordered list
+// within a <nav> element. The last breadcrumb is marked as the current page.
+// If there are no breadcrumbs, nothing is rendered.
+func Breadcrumbs() templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		crumbs := Crumbs(ctx)
+		if len(crumbs) == 0 {
+			return nil
+		}
+		return breadcrumbs(crumbs).Render(ctx, w)
+	})
+}
+
+// hrefPath returns the cleaned path of a link, or an empty string if it links
+// to another host.
+func hrefPath(href string) string {
+	u, err := url.Parse(href)
+	if err != nil || u.Host != "" {
+		return ""
+	}
+	return cleanPath(u.Path)
+}
+
+func cleanPath(p string) string {
+	if p == "" {
+		return ""
+	}
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	if len(p) > 1 {
+		p = strings.TrimRight(p, "/")
+	}
+	if p == "" {
+		return "/"
+	}
+	return p
+}
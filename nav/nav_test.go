@@ -0,0 +1,93 @@
+package nav
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestIsActive(t *testing.T) {
+	tests := []struct {
+		path            string
+		href            string
+		expectedCurrent bool
+		expectedActive  bool
+	}{
+		{path: "/docs/install", href: "/docs/install", expectedCurrent: true, expectedActive: true},
+		{path: "/docs/install/", href: "/docs/install?tab=mac#top", expectedCurrent: true, expectedActive: true},
+		{path: "/docs/install", href: "/docs", expectedActive: true},
+		{path: "/docs/install", href: "/doc"},
+		{path: "/docs", href: "/", expectedActive: false},
+		{path: "/", href: "/", expectedCurrent: true, expectedActive: true},
+		{path: "/docs", href: "https://example.com/docs"},
+		{path: "", href: "/"},
+	}
+	for _, test := range tests {
+		t.Run(test.path+" "+test.href, func(t *testing.T) {
+			ctx := WithPath(context.Background(), test.path)
+			if actual := IsCurrent(ctx, test.href); actual != test.expectedCurrent {
+				t.Errorf("IsCurrent: expected %v, got %v", test.expectedCurrent, actual)
+			}
+			if actual := IsActive(ctx, test.href); actual != test.expectedActive {
+				t.Errorf("IsActive: expected %v, got %v", test.expectedActive, actual)
+			}
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	var path string
+	h := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = Path(r.Context())
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/docs/?q=1", nil))
+	if path != "/docs" {
+		t.Errorf("expected /docs, got %q", path)
+	}
+}
+
+func TestCrumbs(t *testing.T) {
+	parent := WithCrumbs(context.Background(), Crumb{Title: "Home", URL: "/"})
+	a := WithCrumbs(parent, Crumb{Title: "A"})
+	b := WithCrumbs(parent, Crumb{Title: "B"})
+	if diff := cmp.Diff([]Crumb{{Title: "Home", URL: "/"}}, Crumbs(parent)); diff != "" {
+		t.Error(diff)
+	}
+	if diff := cmp.Diff([]Crumb{{Title: "Home", URL: "/"}, {Title: "A"}}, Crumbs(a)); diff != "" {
+		t.Error(diff)
+	}
+	if diff := cmp.Diff([]Crumb{{Title: "Home", URL: "/"}, {Title: "B"}}, Crumbs(b)); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestBreadcrumbs(t *testing.T) {
+	ctx := WithCrumbs(context.Background(), Crumb{Title: "Home", URL: "/"}, Crumb{Title: "Guides"})
+	ctx = templ.WithChildren(ctx, Breadcrumbs())
+	var buf bytes.Buffer
+	if err := Section("Install & run", "/guides/install").Render(ctx, &buf); err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+	expected := `<nav aria-label="Breadcrumb"><ol>` +
+		`<li><a href="/">Home</a></li>` +
+		`<li><span>Guides</span></li>` +
+		`<li><span aria-current="page">Install &amp; run</span></li>` +
+		`</ol></nav>`
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Error(diff)
+	}
+	t.Run("nothing is rendered without breadcrumbs", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Breadcrumbs().Render(context.Background(), &buf); err != nil {
+			t.Fatalf("failed to render: %v", err)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("expected no output, got %q", buf.String())
+		}
+	})
+}
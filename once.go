@@ -53,6 +53,7 @@ func (o *OnceHandle) Once() Component {
 	return ComponentFunc(func(ctx context.Context, w io.Writer) (err error) {
 		_, v := getContext(ctx)
 		if v.getHasBeenRendered(o) {
+			traceCacheHit(ctx)
 			return nil
 		}
 		v.setHasBeenRendered(o)
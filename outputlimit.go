@@ -0,0 +1,58 @@
+package templ
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// OutputLimitError is returned when the output of a component rendered with
+// LimitOutput exceeds the limit.
+type OutputLimitError struct {
+	// Limit is the maximum size of the output, in bytes.
+	Limit int64
+	// Size is the size of the output when the limit was found to be exceeded.
+	Size int64
+}
+
+func (e *OutputLimitError) Error() string {
+	return fmt.Sprintf("templ: output of %d bytes exceeds the limit of %d bytes", e.Size, e.Limit)
+}
+
+// LimitOutput returns a component that renders c, and returns an
+// *OutputLimitError if its output exceeds max bytes, e.g. because a bug causes
+// a loop to render far more items than expected. Nothing is written if the
+// limit is exceeded.
+//
+// The output is counted as it's written, including output that's rendered into
+// separate buffers first, e.g. by Parallel, or by defer blocks.
+//
+// Limits can be nested, e.g. to limit the size of a page, and of the sections
+// within it.
+func LimitOutput(max int64, c Component) Component {
+	return ComponentFunc(func(ctx context.Context, w io.Writer) (err error) {
+		buf := GetBuffer()
+		defer ReleaseBuffer(buf)
+		if err = c.Render(ctx, &limitWriter{w: buf, max: max}); err != nil {
+			return err
+		}
+		_, err = buf.WriteTo(w)
+		return err
+	})
+}
+
+// limitWriter writes to w, and returns an *OutputLimitError instead if more
+// than max bytes are written.
+type limitWriter struct {
+	w    io.Writer
+	max  int64
+	size int64
+}
+
+func (lw *limitWriter) Write(p []byte) (n int, err error) {
+	lw.size += int64(len(p))
+	if lw.size > lw.max {
+		return 0, &OutputLimitError{Limit: lw.max, Size: lw.size}
+	}
+	return lw.w.Write(p)
+}
@@ -0,0 +1,122 @@
+package templ_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+)
+
+func TestLimitOutput(t *testing.T) {
+	text := func(s string) templ.Component {
+		return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			_, err := io.WriteString(w, s)
+			return err
+		})
+	}
+	tests := []struct {
+		name          string
+		input         templ.Component
+		expected      string
+		expectedLimit int64
+	}{
+		{
+			name:     "output within the limit is written",
+			input:    templ.LimitOutput(5, text("12345")),
+			expected: "12345",
+		},
+		{
+			name:          "output that exceeds the limit returns an error",
+			input:         templ.LimitOutput(4, text("12345")),
+			expectedLimit: 4,
+		},
+		{
+			name:          "nested limits are checked",
+			input:         templ.LimitOutput(100, templ.LimitOutput(2, text("123"))),
+			expectedLimit: 2,
+		},
+		{
+			name:          "outer limits include the output of inner limits",
+			input:         templ.LimitOutput(2, templ.LimitOutput(100, text("123"))),
+			expectedLimit: 2,
+		},
+		{
+			name:          "output rendered into separate buffers is counted",
+			input:         templ.LimitOutput(4, templ.Parallel(text("123"), text("456"))),
+			expectedLimit: 4,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			w := new(bytes.Buffer)
+			err := tt.input.Render(context.Background(), w)
+			if tt.expectedLimit == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if w.String() != tt.expected {
+					t.Errorf("expected %q, got %q", tt.expected, w.String())
+				}
+				return
+			}
+			var limitErr *templ.OutputLimitError
+			if !errors.As(err, &limitErr) {
+				t.Fatalf("expected an *OutputLimitError, got %v", err)
+			}
+			if limitErr.Limit != tt.expectedLimit {
+				t.Errorf("expected a limit of %d, got %d", tt.expectedLimit, limitErr.Limit)
+			}
+			if w.Len() != 0 {
+				t.Errorf("expected nothing to be written, got %q", w.String())
+			}
+		})
+	}
+	t.Run("rendering stops at the first write that exceeds the limit", func(t *testing.T) {
+		var writes int
+		loop := templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			for i := 0; i < 1_000_000; i++ {
+				writes++
+				if _, err := io.WriteString(w, "<li>Item</li>"); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		err := templ.LimitOutput(1024, loop).Render(context.Background(), io.Discard)
+		var limitErr *templ.OutputLimitError
+		if !errors.As(err, &limitErr) {
+			t.Fatalf("expected an *OutputLimitError, got %v", err)
+		}
+		if writes > 100 {
+			t.Errorf("expected rendering to stop soon after the limit was exceeded, but %d writes were made", writes)
+		}
+	})
+	t.Run("the handler returns an error if the output exceeds the limit", func(t *testing.T) {
+		var handlerErr error
+		h := templ.Handler(text(strings.Repeat("x", 100)),
+			templ.WithMaxOutputSize(10),
+			templ.WithErrorHandler(func(r *http.Request, err error) http.Handler {
+				handlerErr = err
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusInternalServerError)
+				})
+			}),
+		)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		var limitErr *templ.OutputLimitError
+		if !errors.As(handlerErr, &limitErr) {
+			t.Errorf("expected an *OutputLimitError, got %v", handlerErr)
+		}
+		if w.Code != http.StatusInternalServerError || w.Body.Len() != 0 {
+			t.Errorf("unexpected response: %d %q", w.Code, w.Body.String())
+		}
+	})
+}
@@ -0,0 +1,93 @@
+package templ
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+)
+
+// Parallel returns a component that renders the components concurrently,
+// each to its own buffer, and writes their output in order once they've all
+// been rendered. This reduces the time taken to render pages where sections
+// are independent, and each one does I/O, e.g. calls a database.
+//
+// If a component returns an error, or panics, the context passed to the other
+// components is cancelled, nothing is written, and the first error is
+// returned. Panics are returned as a *PanicError.
+//
+// Components rendered once per context with OnceHandle, CSS classes and
+// scripts are deduplicated within each component, and with components
+// rendered before Parallel, but not between the parallel components.
+func Parallel(components ...Component) Component {
+	return ComponentFunc(func(ctx context.Context, w io.Writer) (err error) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		ctx, v := getContext(ctx)
+
+		type branch struct {
+			ctx context.Context
+			v   *contextValue
+			buf *bytes.Buffer
+		}
+		branches := make([]branch, len(components))
+		for i := range components {
+			// Each component has its own copy of the state used during
+			// rendering, which isn't safe for concurrent use.
+			bctx := ClearChildren(cloneContextValue(ctx))
+			_, bv := getContext(bctx)
+			buf := GetBuffer()
+			defer ReleaseBuffer(buf)
+			branches[i] = branch{ctx: bctx, v: bv, buf: buf}
+		}
+
+		var wg sync.WaitGroup
+		var errOnce sync.Once
+		setErr := func(e error) {
+			errOnce.Do(func() {
+				err = e
+				cancel()
+			})
+		}
+		for i, c := range components {
+			wg.Add(1)
+			go func(c Component, b branch) {
+				defer wg.Done()
+				defer func() {
+					if r := recover(); r != nil {
+						setErr(newPanicError(r))
+					}
+				}()
+				if e := c.Render(b.ctx, b.buf); e != nil {
+					setErr(e)
+				}
+			}(c, branches[i])
+		}
+		wg.Wait()
+		if err != nil {
+			return err
+		}
+
+		for _, b := range branches {
+			v.merge(b.v)
+			if _, err = b.buf.WriteTo(w); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// merge records the components, CSS classes and scripts rendered using o as
+// rendered by v.
+func (v *contextValue) merge(o *contextValue) {
+	for k := range o.ss {
+		if v.ss == nil {
+			v.ss = map[string]struct{}{}
+		}
+		v.ss[k] = struct{}{}
+	}
+	for h := range o.onceHandles {
+		v.setHasBeenRendered(h)
+	}
+}
@@ -0,0 +1,104 @@
+package templ_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParallel(t *testing.T) {
+	// slow renders its text after the delay, unless the context is cancelled.
+	slow := func(text string, delay time.Duration) templ.Component {
+		return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			_, err := io.WriteString(w, text)
+			return err
+		})
+	}
+	t.Run("output is written in order", func(t *testing.T) {
+		w := new(bytes.Buffer)
+		start := time.Now()
+		err := templ.Parallel(
+			slow("<a>", 50*time.Millisecond),
+			slow("<b>", 10*time.Millisecond),
+			slow("<c>", 30*time.Millisecond),
+		).Render(context.Background(), w)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if diff := cmp.Diff("<a><b><c>", w.String()); diff != "" {
+			t.Error(diff)
+		}
+		if elapsed := time.Since(start); elapsed > 85*time.Millisecond {
+			t.Errorf("expected components to be rendered concurrently, took %v", elapsed)
+		}
+	})
+	t.Run("the first error cancels the other components", func(t *testing.T) {
+		expectedErr := errors.New("failed")
+		w := new(bytes.Buffer)
+		start := time.Now()
+		err := templ.Parallel(
+			slow("<a>", time.Minute),
+			templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+				return expectedErr
+			}),
+		).Render(context.Background(), w)
+		if !errors.Is(err, expectedErr) {
+			t.Errorf("expected %v, got %v", expectedErr, err)
+		}
+		if w.Len() != 0 {
+			t.Errorf("expected no output, got %q", w.String())
+		}
+		if time.Since(start) > time.Second {
+			t.Error("expected the slow component to be cancelled")
+		}
+	})
+	t.Run("panics are returned as errors", func(t *testing.T) {
+		err := templ.Parallel(templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			panic("oops")
+		})).Render(context.Background(), io.Discard)
+		var pe *templ.PanicError
+		if !errors.As(err, &pe) || pe.Value != "oops" {
+			t.Errorf("expected a panic error, got %v", err)
+		}
+	})
+	t.Run("once handles are shared with later components", func(t *testing.T) {
+		once := templ.NewOnceHandle(templ.WithComponent(templ.Raw("<script></script>")))
+		ctx := templ.InitializeContext(context.Background())
+		w := new(bytes.Buffer)
+		err := templ.Parallel(once.Once(), templ.Raw("<p>")).Render(ctx, w)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err = once.Once().Render(ctx, w); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if diff := cmp.Diff("<script></script><p>", w.String()); diff != "" {
+			t.Error(diff)
+		}
+	})
+	t.Run("children aren't passed to the components", func(t *testing.T) {
+		ctx := templ.WithChildren(context.Background(), templ.Raw("children"))
+		w := new(bytes.Buffer)
+		child := templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			return templ.GetChildren(ctx).Render(ctx, w)
+		})
+		if err := templ.Parallel(child, child).Render(ctx, w); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if strings.Contains(w.String(), "children") {
+			t.Errorf("unexpected output: %q", w.String())
+		}
+	})
+}
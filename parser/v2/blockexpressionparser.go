@@ -0,0 +1,54 @@
+package parser
+
+import (
+	"go/token"
+
+	"github.com/a-h/parse"
+)
+
+var blockExpression parse.Parser[Node] = blockExpressionParser{}
+
+type blockExpressionParser struct{}
+
+var blockExpressionStart = parse.All(openBraceWithOptionalPadding, parse.NewLine)
+
+func (blockExpressionParser) Parse(pi *parse.Input) (n Node, ok bool, err error) {
+	var r BlockExpression
+	start := pi.Index()
+
+	// Eat "block ".
+	if _, ok, err = parse.String("block ").Parse(pi); err != nil || !ok {
+		pi.Seek(start)
+		return r, false, err
+	}
+
+	// Block name, followed by " {\n", so that text such as "block quotes are"
+	// isn't a block.
+	from := pi.Position()
+	if r.Name, ok, err = parse.StringUntil(parse.RuneNotIn(goIdentifierRunes)).Parse(pi); err != nil || !ok || !token.IsIdentifier(r.Name) {
+		pi.Seek(start)
+		return r, false, nil
+	}
+	r.NameRange = NewRange(from, pi.Position())
+	if _, ok, err = blockExpressionStart.Parse(pi); err != nil || !ok {
+		pi.Seek(start)
+		return r, false, err
+	}
+
+	// Node contents.
+	tnp := newTemplateNodeParser(closeBraceWithOptionalPadding, "block expression closing brace")
+	var nodes Nodes
+	if nodes, ok, err = tnp.Parse(pi); err != nil || !ok {
+		err = parse.Error("block: expected nodes, but none were found", pi.Position())
+		return
+	}
+	r.Children = nodes.Nodes
+
+	// Read the required closing brace.
+	if _, ok, err = closeBraceWithOptionalPadding.Parse(pi); err != nil || !ok {
+		err = parse.Error("block: "+unterminatedMissingEnd, pi.Position())
+		return
+	}
+
+	return r, true, nil
+}
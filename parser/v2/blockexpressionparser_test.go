@@ -0,0 +1,97 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/a-h/parse"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBlockExpressionParser(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected BlockExpression
+	}{
+		{
+			name: "block: simple",
+			input: `block content {
+	<p>Default</p>
+}`,
+			expected: BlockExpression{
+				Name: "content",
+				NameRange: Range{
+					From: Position{Index: 6, Line: 0, Col: 6},
+					To:   Position{Index: 13, Line: 0, Col: 13},
+				},
+				Children: []Node{
+					Whitespace{Value: "\t"},
+					Element{
+						Name: "p",
+						NameRange: Range{
+							From: Position{Index: 18, Line: 1, Col: 2},
+							To:   Position{Index: 19, Line: 1, Col: 3},
+						},
+						Children: []Node{
+							Text{Value: "Default"},
+						},
+						TrailingSpace: SpaceVertical,
+					},
+				},
+			},
+		},
+		{
+			name: "block: empty",
+			input: `block head {
+}`,
+			expected: BlockExpression{
+				Name: "head",
+				NameRange: Range{
+					From: Position{Index: 6, Line: 0, Col: 6},
+					To:   Position{Index: 10, Line: 0, Col: 10},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			input := parse.NewInput(tt.input)
+			actual, ok, err := blockExpression.Parse(input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !ok {
+				t.Fatalf("unexpected failure for input %q", tt.input)
+			}
+			if diff := cmp.Diff(tt.expected, actual); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestIncompleteBlockExpression(t *testing.T) {
+	for _, text := range []string{"block quotes are great", "block { }", "block", "block content { inline }"} {
+		t.Run("text is not matched: "+text, func(t *testing.T) {
+			input := parse.NewInput(text)
+			_, ok, err := blockExpression.Parse(input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ok {
+				t.Fatal("expected a non match")
+			}
+			if input.Index() != 0 {
+				t.Errorf("expected the input not to be consumed, stopped at %d", input.Index())
+			}
+		})
+	}
+	t.Run("missing closing brace", func(t *testing.T) {
+		input := parse.NewInput("block content {\n<div></div>")
+		_, _, err := blockExpression.Parse(input)
+		if err == nil {
+			t.Fatal("expected an error, got nil")
+		}
+	})
+}
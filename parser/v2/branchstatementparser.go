@@ -0,0 +1,119 @@
+package parser
+
+import (
+	"go/token"
+	"strings"
+	"unicode"
+
+	"github.com/a-h/parse"
+)
+
+// branchContext describes the Go statements that enclose a node, and so the
+// branch statements that are valid within it. Branch statements are only
+// parsed where they're valid, so that elsewhere, text such as "continue" is
+// still text.
+type branchContext struct {
+	// inFor is true within the body of a for loop.
+	inFor bool
+	// inSwitch is true within a case of a switch statement.
+	inSwitch bool
+	// inCase is true directly within a case of a switch statement, where
+	// fallthrough can be used.
+	inCase bool
+}
+
+// nested returns the context of the children of a node such as an if
+// statement, which are no longer directly within a case.
+func (bc branchContext) nested() branchContext {
+	bc.inCase = false
+	return bc
+}
+
+func (bc branchContext) allows(keyword string) bool {
+	switch keyword {
+	case "break":
+		return bc.inFor || bc.inSwitch
+	case "continue":
+		return bc.inFor
+	case "fallthrough":
+		return bc.inCase
+	}
+	return false
+}
+
+type branchStatementParser struct {
+	branches branchContext
+}
+
+func (p branchStatementParser) Parse(pi *parse.Input) (n Node, ok bool, err error) {
+	start := pi.Index()
+
+	// Check the prefix first.
+	if !peekPrefix(pi, "break", "continue", "fallthrough") {
+		return nil, false, nil
+	}
+
+	// The statement must be on its own line, e.g. `break`, `continue outer`, or
+	// `fallthrough`.
+	from := pi.Position()
+	var line string
+	if line, ok, err = stringUntilNewLineOrEOF.Parse(pi); err != nil || !ok {
+		pi.Seek(start)
+		return nil, false, err
+	}
+	stmt := strings.TrimRightFunc(line, unicode.IsSpace)
+	keyword, _, _ := strings.Cut(stmt, " ")
+	if !isBranchStatement(stmt) || !p.branches.allows(keyword) {
+		pi.Seek(start)
+		return nil, false, nil
+	}
+
+	var r BranchStatement
+	r.Expression = NewExpression(stmt, from, pi.PositionAt(start+len(stmt)))
+
+	// Eat the trailing whitespace, including the newline.
+	if _, _, err = parse.OptionalWhitespace.Parse(pi); err != nil {
+		return r, false, err
+	}
+
+	return r, true, nil
+}
+
+func isBranchStatement(s string) bool {
+	if s == "fallthrough" {
+		return true
+	}
+	keyword, label, hasLabel := strings.Cut(s, " ")
+	if keyword != "break" && keyword != "continue" {
+		return false
+	}
+	if !hasLabel {
+		return true
+	}
+	return token.IsIdentifier(strings.TrimLeft(label, " "))
+}
+
+// parseLabel parses an optional statement label, e.g. `outer: `, that is
+// followed by one of the given prefixes.
+func parseLabel(pi *parse.Input, prefixes ...string) (label Expression, ok bool) {
+	start := pi.Index()
+	from := pi.Position()
+	name, ok, err := parse.StringUntil(parse.RuneNotIn(goIdentifierRunes)).Parse(pi)
+	if err != nil || !ok || !token.IsIdentifier(name) {
+		pi.Seek(start)
+		return label, false
+	}
+	to := pi.Position()
+	if _, ok, err = parse.All(parse.String(":"), parse.String(" ")).Parse(pi); err != nil || !ok {
+		pi.Seek(start)
+		return label, false
+	}
+	_, _, _ = parse.ZeroOrMore(parse.String(" ")).Parse(pi)
+	if !peekPrefix(pi, prefixes...) {
+		pi.Seek(start)
+		return label, false
+	}
+	return NewExpression(name, from, to), true
+}
+
+const goIdentifierRunes = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_"
@@ -0,0 +1,226 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/a-h/parse"
+	"github.com/google/go-cmp/cmp"
+)
+
+// branchStatement parses branch statements as if they're directly within a
+// case of a switch statement in a for loop, where they're all valid.
+var branchStatement = branchStatementParser{branches: branchContext{inFor: true, inSwitch: true, inCase: true}}
+
+func TestBranchStatementParser(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected BranchStatement
+	}{
+		{
+			name:  "break",
+			input: "break\n",
+			expected: BranchStatement{
+				Expression: Expression{
+					Value: "break",
+					Range: Range{
+						From: Position{Index: 0, Line: 0, Col: 0},
+						To:   Position{Index: 5, Line: 0, Col: 5},
+					},
+				},
+			},
+		},
+		{
+			name:  "continue",
+			input: "continue",
+			expected: BranchStatement{
+				Expression: Expression{
+					Value: "continue",
+					Range: Range{
+						From: Position{Index: 0, Line: 0, Col: 0},
+						To:   Position{Index: 8, Line: 0, Col: 8},
+					},
+				},
+			},
+		},
+		{
+			name:  "labelled continue, with trailing spaces",
+			input: "continue outer  \n",
+			expected: BranchStatement{
+				Expression: Expression{
+					Value: "continue outer",
+					Range: Range{
+						From: Position{Index: 0, Line: 0, Col: 0},
+						To:   Position{Index: 14, Line: 0, Col: 14},
+					},
+				},
+			},
+		},
+		{
+			name:  "fallthrough",
+			input: "fallthrough\n",
+			expected: BranchStatement{
+				Expression: Expression{
+					Value: "fallthrough",
+					Range: Range{
+						From: Position{Index: 0, Line: 0, Col: 0},
+						To:   Position{Index: 11, Line: 0, Col: 11},
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			input := parse.NewInput(tt.input)
+			actual, ok, err := branchStatement.Parse(input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !ok {
+				t.Fatalf("unexpected failure for input %q", tt.input)
+			}
+			if diff := cmp.Diff(tt.expected, actual); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestBranchStatementParserIgnoresText(t *testing.T) {
+	tests := []string{
+		"breakfast",
+		"break the rules",
+		"continue reading...",
+		"Continue",
+		"fallthrough is a Go keyword",
+		"fallthrough outer",
+	}
+	for _, input := range tests {
+		input := input
+		t.Run(input, func(t *testing.T) {
+			pi := parse.NewInput(input)
+			_, ok, err := branchStatement.Parse(pi)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ok {
+				t.Fatal("expected a non match")
+			}
+			if pi.Index() != 0 {
+				t.Fatalf("expected the input to be rewound, got index %d", pi.Index())
+			}
+		})
+	}
+}
+
+func TestBranchStatementParserContext(t *testing.T) {
+	tests := []struct {
+		name     string
+		branches branchContext
+		input    string
+		expected bool
+	}{
+		{name: "break outside of a loop or switch", input: "break", expected: false},
+		{name: "continue outside of a loop", input: "continue", expected: false},
+		{name: "fallthrough outside of a switch", input: "fallthrough", expected: false},
+		{name: "break in a loop", branches: branchContext{inFor: true}, input: "break", expected: true},
+		{name: "continue in a loop", branches: branchContext{inFor: true}, input: "continue", expected: true},
+		{name: "fallthrough in a loop", branches: branchContext{inFor: true}, input: "fallthrough", expected: false},
+		{name: "break in a switch", branches: branchContext{inSwitch: true}, input: "break", expected: true},
+		{name: "continue in a switch", branches: branchContext{inSwitch: true}, input: "continue", expected: false},
+		{name: "fallthrough in a case", branches: branchContext{inSwitch: true, inCase: true}, input: "fallthrough", expected: true},
+		{name: "fallthrough nested in a case", branches: branchContext{inSwitch: true, inCase: true}.nested(), input: "fallthrough", expected: false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			_, ok, err := branchStatementParser{branches: tt.branches}.Parse(parse.NewInput(tt.input))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ok != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, ok)
+			}
+		})
+	}
+}
+
+func TestBranchStatementsAreTextOutsideOfLoops(t *testing.T) {
+	input := `<button>
+	continue
+</button>
+<p>
+	break
+</p>
+`
+	nodes, ok, err := newTemplateNodeParser[any](nil, "").Parse(parse.NewInput(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !ok {
+		t.Fatal("expected the nodes to be parsed")
+	}
+	for _, n := range nodes.Nodes {
+		e, ok := n.(Element)
+		if !ok {
+			continue
+		}
+		var text string
+		for _, child := range e.Children {
+			switch child := child.(type) {
+			case BranchStatement:
+				t.Errorf("<%s>: expected text, got a branch statement", e.Name)
+			case Text:
+				text += child.Value
+			}
+		}
+		if text = strings.TrimSpace(text); text != "continue" && text != "break" {
+			t.Errorf("<%s>: unexpected text %q", e.Name, text)
+		}
+	}
+}
+
+func TestBranchStatementsAreTextWithinElementsInLoops(t *testing.T) {
+	input := `for _, item := range items {
+	<p>
+		continue
+	</p>
+	if item == "" {
+		continue
+	}
+}`
+	n, ok, err := forExpression.Parse(parse.NewInput(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !ok {
+		t.Fatal("expected the for expression to be parsed")
+	}
+	var p Element
+	var ifExpr IfExpression
+	for _, child := range n.(ForExpression).Children {
+		switch child := child.(type) {
+		case Element:
+			p = child
+		case IfExpression:
+			ifExpr = child
+		}
+	}
+	for _, child := range p.Children {
+		if _, ok := child.(BranchStatement); ok {
+			t.Error("<p>: expected text, got a branch statement")
+		}
+	}
+	var found bool
+	for _, child := range ifExpr.Then {
+		if _, ok := child.(BranchStatement); ok {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("if: expected a branch statement")
+	}
+}
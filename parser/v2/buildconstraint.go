@@ -0,0 +1,25 @@
+package parser
+
+import (
+	"go/build/constraint"
+	"strings"
+)
+
+// BuildConstraint returns the //go:build constraint in the header of the file,
+// if there is one. The generated Go code has the same constraint, so that
+// files can provide variants of templates for different platforms or build
+// tags.
+func (tf TemplateFile) BuildConstraint() (expr constraint.Expr, ok bool) {
+	for _, n := range tf.Header {
+		if !isGoBuild(n) {
+			continue
+		}
+		expr, err := constraint.Parse(strings.TrimSpace(n.Expression.Value))
+		return expr, err == nil
+	}
+	return nil, false
+}
+
+func isGoBuild(n TemplateFileGoExpression) bool {
+	return constraint.IsGoBuild(strings.TrimSpace(n.Expression.Value))
+}
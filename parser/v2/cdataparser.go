@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/a-h/parse"
+)
+
+var cdataStart = parse.String("<![CDATA[")
+var cdataEnd = parse.String("]]>")
+
+var cdata = parse.Func(func(pi *parse.Input) (n Node, ok bool, err error) {
+	start := pi.Position()
+	if _, ok, err = cdataStart.Parse(pi); err != nil || !ok {
+		return
+	}
+	contentsStart := pi.Index()
+
+	// Once the section has started, take everything until the end.
+	var c CDATA
+	if c.Contents, ok, err = parse.StringUntil(cdataEnd).Parse(pi); err != nil || !ok {
+		err = parse.Error("expected end of CDATA section ']]>' not found", start)
+		return
+	}
+	_, _, _ = cdataEnd.Parse(pi)
+
+	if c.Expressions, err = parseCDATAExpressions(pi, contentsStart, c.Contents); err != nil {
+		return
+	}
+
+	// Parse trailing whitespace.
+	ws, _, err := parse.Whitespace.Parse(pi)
+	if err != nil {
+		return c, false, err
+	}
+	if c.TrailingSpace, err = NewTrailingSpace(ws); err != nil {
+		return c, false, err
+	}
+	return c, true, nil
+})
+
+// parseCDATAExpressions parses the Go expressions within the contents of a
+// CDATA section, e.g. {{ post.Body }}. The contents start at index start of
+// the input.
+func parseCDATAExpressions(pi *parse.Input, start int, contents string) (exprs []ScriptExpression, err error) {
+	end := pi.Index()
+	defer pi.Seek(end)
+
+	for i := 0; i < len(contents); i++ {
+		if !strings.HasPrefix(contents[i:], "{{") {
+			continue
+		}
+		e, err := parseScriptExpression(pi, start, i, contents)
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, e)
+		i = e.End - 1
+	}
+	return exprs, nil
+}
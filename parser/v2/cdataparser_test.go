@@ -0,0 +1,101 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/a-h/parse"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCDATAParser(t *testing.T) {
+	var tests = []struct {
+		name     string
+		input    string
+		expected CDATA
+	}{
+		{
+			name:  "CDATA - text",
+			input: `<![CDATA[<p>Hello & goodbye</p>]]>`,
+			expected: CDATA{
+				Contents: "<p>Hello & goodbye</p>",
+			},
+		},
+		{
+			name: "CDATA - multiline",
+			input: `<![CDATA[
+	if (a < b) { draw(); }
+]]>`,
+			expected: CDATA{
+				Contents: "\n\tif (a < b) { draw(); }\n",
+			},
+		},
+		{
+			name:  "CDATA - Go expressions",
+			input: `<![CDATA[<p>{{ post.Body }}</p>]]>`,
+			expected: CDATA{
+				Contents: "<p>{{ post.Body }}</p>",
+				Expressions: []ScriptExpression{
+					{
+						Start: 3,
+						End:   18,
+						Expression: Expression{
+							Value: "post.Body",
+							Range: Range{
+								From: Position{Index: 15, Line: 0, Col: 15},
+								To:   Position{Index: 24, Line: 0, Col: 24},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			input := parse.NewInput(tt.input)
+			result, ok, err := cdata.Parse(input)
+			if err != nil {
+				t.Fatalf("parser error: %v", err)
+			}
+			if !ok {
+				t.Fatalf("failed to parse at %d", input.Index())
+			}
+			if diff := cmp.Diff(tt.expected, result); diff != "" {
+				t.Error(diff)
+			}
+			if input.Index() != len(tt.input) {
+				t.Errorf("expected the input to be consumed, stopped at %d", input.Index())
+			}
+		})
+	}
+}
+
+func TestCDATAParserErrors(t *testing.T) {
+	var tests = []struct {
+		name     string
+		input    string
+		expected error
+	}{
+		{
+			name:  "unclosed CDATA section",
+			input: `<![CDATA[ unclosed`,
+			expected: parse.Error("expected end of CDATA section ']]>' not found",
+				parse.Position{
+					Index: 0,
+					Line:  0,
+					Col:   0,
+				}),
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			input := parse.NewInput(tt.input)
+			_, _, err := cdata.Parse(input)
+			if diff := cmp.Diff(tt.expected, err); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
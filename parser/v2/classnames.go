@@ -0,0 +1,203 @@
+package parser
+
+import (
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ClassNames returns the sorted literal class names of the template file, so
+// that tools such as Tailwind can find the classes that are used without
+// scanning the generated Go code. Classes are collected from constant class
+// attributes, the string literals of class expressions, the arguments of
+// templ.Classes calls, and the keys of templ.KV calls.
+func (tf TemplateFile) ClassNames() []string {
+	c := classNameCollector{seen: map[string]struct{}{}}
+	for _, n := range tf.Nodes {
+		switch n := n.(type) {
+		case HTMLTemplate:
+			walkNodes(n.Children, c.node)
+		case TemplateFileGoExpression:
+			c.goCode(n.Expression.Value)
+		}
+	}
+	slices.Sort(c.classes)
+	return c.classes
+}
+
+type classNameCollector struct {
+	seen    map[string]struct{}
+	classes []string
+}
+
+func (c *classNameCollector) add(value string) {
+	for _, class := range strings.Fields(value) {
+		if _, ok := c.seen[class]; ok {
+			continue
+		}
+		c.seen[class] = struct{}{}
+		c.classes = append(c.classes, class)
+	}
+}
+
+func (c *classNameCollector) node(n Node) bool {
+	switch n := n.(type) {
+	case Element:
+		c.attributes(n.Attributes)
+	case RawElement:
+		c.attributes(n.Attributes)
+	case StringExpression:
+		c.goCode(n.Expression.Value)
+	case CallTemplateExpression:
+		c.goCode(n.Expression.Value)
+	case TemplElementExpression:
+		c.goCode(n.Expression.Value)
+	case GoCode:
+		c.goCode(n.Expression.Value)
+	}
+	return true
+}
+
+func (c *classNameCollector) attributes(attrs []Attribute) {
+	for _, attr := range attrs {
+		switch attr := attr.(type) {
+		case ConstantAttribute:
+			if strings.EqualFold(attr.Name, "class") {
+				c.add(attr.Value)
+			}
+		case InterpolatedAttribute:
+			if strings.EqualFold(attr.Name, "class") {
+				c.interpolated(attr.Parts)
+			}
+		case ExpressionAttribute:
+			if !strings.EqualFold(attr.Name, "class") {
+				c.goCode(attr.Expression.Value)
+				continue
+			}
+			// Class expressions are lists of values, e.g. "a", templ.KV("b", ok).
+			expr, err := goparser.ParseExpr("[]any{" + attr.Expression.Value + "}")
+			if err != nil {
+				continue
+			}
+			for _, elt := range expr.(*ast.CompositeLit).Elts {
+				c.classValue(elt)
+			}
+			c.templCalls(expr)
+		case SpreadAttributes:
+			c.goCode(attr.Expression.Value)
+		case DynamicAttribute:
+			c.goCode(attr.Name.Value)
+			c.goCode(attr.Value.Value)
+		case ConditionalAttribute:
+			c.attributes(attr.Then)
+			c.attributes(attr.Else)
+		}
+	}
+}
+
+// interpolated adds the classes of the constant parts of an interpolated
+// class attribute. Partial classes next to an expression, e.g. text- in
+// text-{ color }, aren't classes.
+func (c *classNameCollector) interpolated(parts []AttributeValuePart) {
+	for i, part := range parts {
+		if part.Expression != nil {
+			continue
+		}
+		words := strings.Fields(part.Value)
+		if len(words) == 0 {
+			continue
+		}
+		if i > 0 && !startsWithSpace(part.Value) {
+			words = words[1:]
+		}
+		if i < len(parts)-1 && len(words) > 0 && !endsWithSpace(part.Value) {
+			words = words[:len(words)-1]
+		}
+		c.add(strings.Join(words, " "))
+	}
+}
+
+func startsWithSpace(s string) bool {
+	return s != "" && strings.TrimLeft(s, " \t\r\n") != s
+}
+
+func endsWithSpace(s string) bool {
+	return s != "" && strings.TrimRight(s, " \t\r\n") != s
+}
+
+// classValue adds the classes of a value within a class expression, e.g. a
+// string literal, or the keys of a map literal.
+func (c *classNameCollector) classValue(e ast.Expr) {
+	switch e := e.(type) {
+	case *ast.BasicLit:
+		if e.Kind == token.STRING {
+			if v, err := strconv.Unquote(e.Value); err == nil {
+				c.add(v)
+			}
+		}
+	case *ast.ParenExpr:
+		c.classValue(e.X)
+	case *ast.CompositeLit:
+		for _, elt := range e.Elts {
+			if kv, ok := elt.(*ast.KeyValueExpr); ok {
+				c.classValue(kv.Key)
+				continue
+			}
+			c.classValue(elt)
+		}
+	}
+}
+
+// goCode adds the classes of the templ.Classes and templ.KV calls within Go
+// code.
+func (c *classNameCollector) goCode(src string) {
+	if n := parseGoCode(src); n != nil {
+		c.templCalls(n)
+	}
+}
+
+// parseGoCode parses Go code that's an expression, a list of statements, or a
+// list of declarations. It returns nil if the code can't be parsed.
+func parseGoCode(src string) ast.Node {
+	if expr, err := goparser.ParseExpr(src); err == nil {
+		return expr
+	}
+	fset := token.NewFileSet()
+	if f, err := goparser.ParseFile(fset, "", "package p\nfunc _() {\n"+src+"\n}", goparser.SkipObjectResolution); err == nil {
+		return f
+	}
+	if f, err := goparser.ParseFile(fset, "", "package p\n"+src, goparser.SkipObjectResolution); err == nil {
+		return f
+	}
+	return nil
+}
+
+func (c *classNameCollector) templCalls(n ast.Node) {
+	ast.Inspect(n, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != "templ" {
+			return true
+		}
+		switch sel.Sel.Name {
+		case "Classes":
+			for _, arg := range call.Args {
+				c.classValue(arg)
+			}
+		case "KV":
+			if len(call.Args) > 0 {
+				c.classValue(call.Args[0])
+			}
+		}
+		return true
+	})
+}
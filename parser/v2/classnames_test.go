@@ -0,0 +1,36 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestClassNames(t *testing.T) {
+	input := `package p
+
+var buttonClasses = templ.Classes("btn", templ.KV("btn-primary", true))
+
+templ page(active bool, size string) {
+	<div class="flex  items-center">
+		<p class="text-${ size } font-bold ${ size } mt-2">Text</p>
+		<a class={ "underline", templ.KV("text-blue-500", active), map[string]bool{"hidden": !active}, size }>Link</a>
+		<button class={ buttonClasses }>Click</button>
+		if active {
+			<span class="rounded" id="not-a-class"></span>
+		}
+		@card(templ.Classes("shadow-lg"))
+	</div>
+}`
+	tf, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	expected := []string{
+		"btn", "btn-primary", "flex", "font-bold", "hidden", "items-center",
+		"mt-2", "rounded", "shadow-lg", "text-blue-500", "underline",
+	}
+	if diff := cmp.Diff(expected, tf.ClassNames()); diff != "" {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,134 @@
+package parser
+
+import (
+	"go/ast"
+	"strings"
+)
+
+// CriticalCSS returns the names of the css components that are used by the
+// template with the given name, and by the templates of the file that it
+// calls, in the order that they're first used. Only css components without
+// parameters, e.g. css button() { ... }, can be collected, because the
+// arguments of other components aren't known until the template is rendered.
+// It returns false if the file doesn't contain the template.
+func (tf TemplateFile) CriticalCSS(name string) (classes []string, ok bool) {
+	c := criticalCSSCollector{
+		css:       map[string]bool{},
+		templates: map[string]HTMLTemplate{},
+		visited:   map[string]bool{},
+		seen:      map[string]bool{},
+	}
+	for _, n := range tf.Nodes {
+		switch n := n.(type) {
+		case CSSTemplate:
+			params := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(n.Expression.Value), n.Name))
+			c.css[n.Name] = params == "()"
+		case HTMLTemplate:
+			if templateName, ok := functionName(n.Expression.Value); ok {
+				c.templates[templateName] = n
+			}
+		}
+	}
+	if _, ok = c.templates[name]; !ok {
+		return nil, false
+	}
+	c.template(name)
+	return c.classes, true
+}
+
+// functionName returns the name of a template, or false if the template is a
+// method, e.g. (p Page) Header().
+func functionName(signature string) (name string, ok bool) {
+	signature = strings.TrimSpace(signature)
+	if strings.HasPrefix(signature, "(") {
+		return "", false
+	}
+	if end := strings.IndexAny(signature, "[("); end >= 0 {
+		signature = signature[:end]
+	}
+	return strings.TrimSpace(signature), true
+}
+
+type criticalCSSCollector struct {
+	// css is true for the css components without parameters.
+	css       map[string]bool
+	templates map[string]HTMLTemplate
+	visited   map[string]bool
+	seen      map[string]bool
+	classes   []string
+}
+
+func (c *criticalCSSCollector) template(name string) {
+	if c.visited[name] {
+		return
+	}
+	c.visited[name] = true
+	walkNodes(c.templates[name].Children, c.node)
+}
+
+func (c *criticalCSSCollector) node(n Node) bool {
+	switch n := n.(type) {
+	case Element:
+		c.attributes(n.Attributes)
+	case RawElement:
+		c.attributes(n.Attributes)
+	case StringExpression:
+		c.goCode(n.Expression.Value)
+	case CallTemplateExpression:
+		c.goCode(n.Expression.Value)
+	case TemplElementExpression:
+		c.goCode(n.Expression.Value)
+	case GoCode:
+		c.goCode(n.Expression.Value)
+	}
+	return true
+}
+
+func (c *criticalCSSCollector) attributes(attrs []Attribute) {
+	for _, attr := range attrs {
+		switch attr := attr.(type) {
+		case ExpressionAttribute:
+			if strings.EqualFold(attr.Name, "class") {
+				// Class expressions are lists of values, e.g. button(), "a".
+				c.goCode("[]any{" + attr.Expression.Value + "}")
+				continue
+			}
+			c.goCode(attr.Expression.Value)
+		case SpreadAttributes:
+			c.goCode(attr.Expression.Value)
+		case DynamicAttribute:
+			c.goCode(attr.Name.Value)
+			c.goCode(attr.Value.Value)
+		case ConditionalAttribute:
+			c.attributes(attr.Then)
+			c.attributes(attr.Else)
+		}
+	}
+}
+
+// goCode adds the css components that are called by the Go code, and the
+// css components of the templates that it calls.
+func (c *criticalCSSCollector) goCode(src string) {
+	n := parseGoCode(src)
+	if n == nil {
+		return
+	}
+	ast.Inspect(n, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		id, ok := call.Fun.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		if c.css[id.Name] && len(call.Args) == 0 && !c.seen[id.Name] {
+			c.seen[id.Name] = true
+			c.classes = append(c.classes, id.Name)
+		}
+		if _, ok := c.templates[id.Name]; ok {
+			c.template(id.Name)
+		}
+		return true
+	})
+}
@@ -0,0 +1,95 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCriticalCSS(t *testing.T) {
+	input := `package p
+
+css layoutClass() {
+	display: flex;
+}
+
+css headerClass() {
+	font-weight: bold;
+}
+
+css cardClass() {
+	border: 1px solid black;
+}
+
+css colorClass(c string) {
+	color: { c };
+}
+
+css unusedClass() {
+	color: red;
+}
+
+templ header() {
+	<h1 class={ headerClass(), colorClass("red") }>Title</h1>
+}
+
+templ card(title string) {
+	<div class={ templ.Classes(cardClass(), "shadow") }>{ title }</div>
+}
+
+templ layout(content templ.Component) {
+	<body class={ layoutClass() }>
+		@header()
+		@content
+	</body>
+}
+
+templ page() {
+	@layout(cards()) {
+	}
+}
+
+templ cards() {
+	for _, title := range []string{"a", "b"} {
+		@card(title)
+	}
+	<div class={ layoutClass() }></div>
+	@cards()
+}
+`
+	tf, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	tests := []struct {
+		name     string
+		expected []string
+		ok       bool
+	}{
+		{
+			name:     "page",
+			expected: []string{"layoutClass", "headerClass", "cardClass"},
+			ok:       true,
+		},
+		{
+			name:     "card",
+			expected: []string{"cardClass"},
+			ok:       true,
+		},
+		{
+			name: "missing",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			actual, ok := tf.CriticalCSS(tt.name)
+			if ok != tt.ok {
+				t.Fatalf("expected ok to be %v, got %v", tt.ok, ok)
+			}
+			if diff := cmp.Diff(tt.expected, actual); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package parser
 
 import (
+	"fmt"
+	"slices"
+	"strings"
+
 	"github.com/a-h/parse"
 )
 
@@ -8,10 +12,6 @@ import (
 
 // CSS Parser.
 var cssParser = parse.Func(func(pi *parse.Input) (r CSSTemplate, ok bool, err error) {
-	r = CSSTemplate{
-		Properties: []CSSProperty{},
-	}
-
 	// Parse the name.
 	var exp cssExpression
 	if exp, ok, err = cssExpressionParser.Parse(pi); err != nil || !ok {
@@ -20,8 +20,31 @@ var cssParser = parse.Func(func(pi *parse.Input) (r CSSTemplate, ok bool, err er
 	r.Name = exp.Name
 	r.Expression = exp.Expression
 
+	if r.Properties, err = parseCSSProperties(pi); err != nil {
+		return r, false, err
+	}
+	return r, true, nil
+})
+
+// parseCSSProperties parses the properties and nested rules of a css
+// component, or of a nested rule, up to and including the closing brace.
+func parseCSSProperties(pi *parse.Input) (properties []CSSProperty, err error) {
+	properties = []CSSProperty{}
 	for {
 		var cssProperty CSSProperty
+		var ok bool
+
+		// Try for a nested rule.
+		// &:hover {
+		var rule CSSRule
+		rule, ok, err = parseCSSRule(pi)
+		if err != nil {
+			return
+		}
+		if ok {
+			properties = append(properties, rule)
+			continue
+		}
 
 		// Try for an expression CSS declaration.
 		// background-color: { constants.BackgroundColor };
@@ -30,7 +53,7 @@ var cssParser = parse.Func(func(pi *parse.Input) (r CSSTemplate, ok bool, err er
 			return
 		}
 		if ok {
-			r.Properties = append(r.Properties, cssProperty)
+			properties = append(properties, cssProperty)
 			continue
 		}
 
@@ -41,7 +64,7 @@ var cssParser = parse.Func(func(pi *parse.Input) (r CSSTemplate, ok bool, err er
 			return
 		}
 		if ok {
-			r.Properties = append(r.Properties, cssProperty)
+			properties = append(properties, cssProperty)
 			continue
 		}
 
@@ -56,9 +79,57 @@ var cssParser = parse.Func(func(pi *parse.Input) (r CSSTemplate, ok bool, err er
 			return
 		}
 
-		return r, true, nil
+		return properties, nil
 	}
-})
+}
+
+// cssRuleAtKeywords are the at-rules that can be nested within css
+// components.
+var cssRuleAtKeywords = []string{"@media ", "@supports ", "@container "}
+
+// &:hover {
+// @media (min-width: 768px) {
+func parseCSSRule(pi *parse.Input) (r CSSRule, ok bool, err error) {
+	start := pi.Index()
+
+	// Optional whitespace.
+	if _, _, err = parse.OptionalWhitespace.Parse(pi); err != nil {
+		return
+	}
+	prefix, _ := pi.Peek(1)
+	if prefix != "&" && prefix != "@" {
+		pi.Seek(start)
+		return r, false, nil
+	}
+	selectorStart := pi.Position()
+	if r.Selector, err = parseCSSSelector("css rule", pi); err != nil {
+		return r, false, err
+	}
+	if prefix == "@" && !slices.ContainsFunc(cssRuleAtKeywords, func(keyword string) bool {
+		return strings.HasPrefix(r.Selector, keyword)
+	}) {
+		return r, false, parse.Error(fmt.Sprintf("css rule: unsupported at-rule %q, expected @media, @supports or @container", r.Selector), selectorStart)
+	}
+
+	if r.Properties, err = parseCSSProperties(pi); err != nil {
+		return r, false, err
+	}
+	return r, true, nil
+}
+
+// parseCSSSelector parses the selector of a rule, and the {\n that follows it.
+func parseCSSSelector(name string, pi *parse.Input) (selector string, err error) {
+	start := pi.Position()
+	untilOpen := parse.All(openBraceWithOptionalPadding, parse.NewLine)
+	selector, ok, err := parse.StringUntil(untilOpen).Parse(pi)
+	if err != nil || !ok || strings.Contains(selector, "\n") {
+		return selector, parse.Error(name+": missing open brace and linebreak ({\\n)", start)
+	}
+	if _, _, err = untilOpen.Parse(pi); err != nil {
+		return selector, err
+	}
+	return strings.TrimSpace(selector), nil
+}
 
 // css Func() {
 type cssExpression struct {
@@ -93,7 +164,7 @@ var cssExpressionParser = parse.Func(func(pi *parse.Input) (r cssExpression, ok
 
 // CSS property name parser.
 var cssPropertyNameFirst = "abcdefghijklmnopqrstuvwxyz-"
-var cssPropertyNameSubsequent = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-"
+var cssPropertyNameSubsequent = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_"
 var cssPropertyNameParser = parse.Func(func(in *parse.Input) (name string, ok bool, err error) {
 	start := in.Position()
 	var prefix, suffix string
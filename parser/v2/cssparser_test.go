@@ -61,6 +61,30 @@ func TestExpressionCSSPropertyParser(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:  "css: custom property",
+			input: `--brand_color: { theme.Brand };`,
+			expected: ExpressionCSSProperty{
+				Name: "--brand_color",
+				Value: StringExpression{
+					Expression: Expression{
+						Value: "theme.Brand",
+						Range: Range{
+							From: Position{
+								Index: 17,
+								Line:  0,
+								Col:   17,
+							},
+							To: Position{
+								Index: 28,
+								Line:  0,
+								Col:   28,
+							},
+						},
+					},
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -297,6 +321,67 @@ background-color: { prop };
 				},
 			},
 		},
+		{
+			name: "css: nested rules",
+			input: `css Name() {
+color: red;
+&:hover {
+	color: white;
+}
+@media (min-width: 768px) {
+	&:hover {
+		color: blue;
+	}
+}
+}`,
+			expected: CSSTemplate{
+				Name: "Name",
+				Expression: Expression{
+					Value: "Name()",
+					Range: Range{
+						From: Position{
+							Index: 4,
+							Line:  0,
+							Col:   4,
+						},
+						To: Position{
+							Index: 10,
+							Line:  0,
+							Col:   10,
+						},
+					},
+				},
+				Properties: []CSSProperty{
+					ConstantCSSProperty{
+						Name:  "color",
+						Value: "red",
+					},
+					CSSRule{
+						Selector: "&:hover",
+						Properties: []CSSProperty{
+							ConstantCSSProperty{
+								Name:  "color",
+								Value: "white",
+							},
+						},
+					},
+					CSSRule{
+						Selector: "@media (min-width: 768px)",
+						Properties: []CSSProperty{
+							CSSRule{
+								Selector: "&:hover",
+								Properties: []CSSProperty{
+									ConstantCSSProperty{
+										Name:  "color",
+										Value: "blue",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -315,3 +400,43 @@ background-color: { prop };
 		})
 	}
 }
+
+func TestCSSParserNestedRuleErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name: "unsupported at-rules are an error",
+			input: `css Name() {
+@font-face {
+	font-family: x;
+}
+}`,
+		},
+		{
+			name: "nested rules must be closed",
+			input: `css Name() {
+&:hover {
+	color: white;
+`,
+		},
+		{
+			name: "nested rules must have an open brace",
+			input: `css Name() {
+&:hover
+	color: white;
+}
+}`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := cssParser.Parse(parse.NewInput(tt.input))
+			if err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package parser
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strings"
+)
+
+// CSSScope is the scope of the classes defined by the scoped style elements
+// of a template, e.g. <style scoped>. Within the template, the names of the
+// classes have the suffix appended, in the same way as CSS modules, so that
+// they can't conflict with the classes of other templates.
+type CSSScope struct {
+	// Suffix is appended to the names of the classes, e.g. _1a2b3c4d. It's
+	// a hash of the template's signature and the CSS, so it only changes when
+	// the template's styles change.
+	Suffix string
+	// Classes are the names of the classes that are scoped.
+	Classes []string
+}
+
+// IsScopedCSS returns true if the element is a CSS style element with the
+// scoped attribute, e.g. <style scoped>.
+func (e RawElement) IsScopedCSS() bool {
+	return e.IsCSS() && HasAttribute(e.Attributes, "scoped")
+}
+
+// CSSScope returns the scope of the classes defined in the scoped style
+// elements of the template. If the template has no scoped style elements,
+// the scope has no classes, and doesn't change anything.
+func (t HTMLTemplate) CSSScope() (scope CSSScope) {
+	h := sha256.New()
+	h.Write([]byte(t.Expression.Value))
+	walkNodes(t.Children, func(n Node) bool {
+		e, ok := n.(RawElement)
+		if !ok || !e.IsScopedCSS() {
+			return true
+		}
+		h.Write([]byte{0})
+		h.Write([]byte(e.Contents))
+		for _, s := range cssClassSelectors(e) {
+			name := e.Contents[s.start:s.end]
+			if !slices.Contains(scope.Classes, name) {
+				scope.Classes = append(scope.Classes, name)
+			}
+		}
+		return true
+	})
+	if len(scope.Classes) == 0 {
+		return CSSScope{}
+	}
+	scope.Suffix = "_" + hex.EncodeToString(h.Sum(nil))[:8]
+	return scope
+}
+
+// Class returns the value of a class attribute, with the suffix appended to
+// the names of the scoped classes, e.g. "button primary" becomes
+// "button_1a2b3c4d primary" if button is scoped.
+func (s CSSScope) Class(value string) string {
+	if len(s.Classes) == 0 {
+		return value
+	}
+	classes := strings.Fields(value)
+	for i, c := range classes {
+		if slices.Contains(s.Classes, c) {
+			classes[i] = c + s.Suffix
+		}
+	}
+	return strings.Join(classes, " ")
+}
+
+// Style returns the scoped style element, with the suffix appended to the
+// names of the classes in its selectors, and without the scoped attribute.
+func (s CSSScope) Style(e RawElement) RawElement {
+	if !e.IsScopedCSS() {
+		return e
+	}
+	selectors := cssClassSelectors(e)
+	var sb strings.Builder
+	var start int
+	// Expressions move by the length of the suffixes written before them.
+	exprs := slices.Clone(e.Expressions)
+	shift := func(end int) {
+		for i, se := range e.Expressions {
+			if se.Start >= start && se.Start < end {
+				exprs[i].Start += sb.Len() - start
+				exprs[i].End += sb.Len() - start
+			}
+		}
+	}
+	for _, sel := range selectors {
+		shift(sel.end)
+		sb.WriteString(e.Contents[start:sel.end])
+		sb.WriteString(s.Suffix)
+		start = sel.end
+	}
+	shift(len(e.Contents) + 1)
+	sb.WriteString(e.Contents[start:])
+	e.Contents = sb.String()
+	e.Expressions = exprs
+	var attrs []Attribute
+	for _, attr := range e.Attributes {
+		if name, ok := attributeName(attr); ok && strings.EqualFold(name, "scoped") {
+			continue
+		}
+		attrs = append(attrs, attr)
+	}
+	e.Attributes = attrs
+	return e
+}
+
+// cssClassSelector is the range of the name of a class within a selector.
+type cssClassSelector struct {
+	start, end int
+}
+
+// cssClassSelectors returns the class selectors within the CSS of the style
+// element, e.g. .button in .button:hover { ... }. Only the preludes of rules,
+// i.e. the text before a {, contain selectors, so values such as 1.5em and
+// url(a.png) aren't class selectors.
+func cssClassSelectors(e RawElement) (selectors []cssClassSelector) {
+	var s cssScanner
+	var candidates []cssClassSelector
+	var expr int
+	src := e.Contents
+	for i := 0; i < len(src); i++ {
+		if expr < len(e.Expressions) && i == e.Expressions[expr].Start {
+			i = e.Expressions[expr].End - 1
+			expr++
+			continue
+		}
+		if s.state == cssCode {
+			switch c := src[i]; {
+			case c == '.':
+				if end := cssIdentifierEnd(src, i+1); end > i+1 {
+					candidates = append(candidates, cssClassSelector{start: i + 1, end: end})
+					i = end - 1
+					continue
+				}
+			case c == '{':
+				selectors = append(selectors, candidates...)
+				candidates = nil
+			case c == ';' || c == '}':
+				candidates = nil
+			}
+		}
+		i += s.next(src, i)
+	}
+	return selectors
+}
+
+// cssIdentifierEnd returns the index of the end of the CSS identifier that
+// starts at index i of src, or i if there isn't one.
+func cssIdentifierEnd(src string, i int) (end int) {
+	end = i
+	for end < len(src) {
+		c := src[end]
+		isStart := c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+		isDigit := c >= '0' && c <= '9'
+		if c == '-' && end == i {
+			// -1 isn't an identifier, but -a is.
+			if end+1 >= len(src) || src[end+1] >= '0' && src[end+1] <= '9' {
+				return i
+			}
+			end++
+			continue
+		}
+		if !isStart && !(end > i && (isDigit || c == '-')) {
+			break
+		}
+		end++
+	}
+	return end
+}
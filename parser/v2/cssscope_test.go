@@ -0,0 +1,94 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/a-h/parse"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCSSScope(t *testing.T) {
+	input := `templ Card(theme Theme) {
+	<style scoped>
+		/* .comment { } */
+		.card, .card > .title:hover {
+			margin: 1.5em;
+			background: url("a.png");
+			color: {{ theme.Color }};
+		}
+		@media (min-width: 768px) {
+			.card { padding: .5em }
+		}
+	</style>
+	<div class="card primary"><h1 class="title">Title</h1></div>
+}`
+	tf, err := ParseString("package p\n\n" + input)
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	ht := tf.Nodes[0].(HTMLTemplate)
+	scope := ht.CSSScope()
+	if diff := cmp.Diff([]string{"card", "title"}, scope.Classes); diff != "" {
+		t.Error(diff)
+	}
+	if len(scope.Suffix) != 9 {
+		t.Errorf("expected a suffix of _ and 8 characters, got %q", scope.Suffix)
+	}
+	if again := ht.CSSScope(); again.Suffix != scope.Suffix {
+		t.Errorf("expected the suffix to be stable, got %q and %q", scope.Suffix, again.Suffix)
+	}
+	t.Run("class attributes", func(t *testing.T) {
+		actual := scope.Class("card  primary")
+		expected := "card" + scope.Suffix + " primary"
+		if actual != expected {
+			t.Errorf("expected %q, got %q", expected, actual)
+		}
+	})
+	t.Run("style elements", func(t *testing.T) {
+		var style RawElement
+		walkNodes(ht.Children, func(n Node) bool {
+			if e, ok := n.(RawElement); ok {
+				style = e
+			}
+			return true
+		})
+		scoped := scope.Style(style)
+		if HasAttribute(scoped.Attributes, "scoped") {
+			t.Error("expected the scoped attribute to be removed")
+		}
+		s := scope.Suffix
+		expected := `
+		/* .comment { } */
+		.card` + s + `, .card` + s + ` > .title` + s + `:hover {
+			margin: 1.5em;
+			background: url("a.png");
+			color: {{ theme.Color }};
+		}
+		@media (min-width: 768px) {
+			.card` + s + ` { padding: .5em }
+		}
+	`
+		if diff := cmp.Diff(expected, scoped.Contents); diff != "" {
+			t.Error(diff)
+		}
+		se := scoped.Expressions[0]
+		if actual := scoped.Contents[se.Start:se.End]; actual != "{{ theme.Color }}" {
+			t.Errorf("expected the expression to move with the contents, got %q", actual)
+		}
+	})
+}
+
+func TestCSSScopeWithoutScopedStyles(t *testing.T) {
+	input := `<div><style>.card { color: red; }</style></div>`
+	e, ok, err := element.Parse(parse.NewInput(input))
+	if err != nil || !ok {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	scope := HTMLTemplate{Children: []Node{e}}.CSSScope()
+	if diff := cmp.Diff(CSSScope{}, scope); diff != "" {
+		t.Error(diff)
+	}
+	if actual := scope.Class("card"); actual != "card" {
+		t.Errorf("expected the class to be unchanged, got %q", actual)
+	}
+}
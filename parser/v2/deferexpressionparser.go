@@ -0,0 +1,39 @@
+package parser
+
+import (
+	"github.com/a-h/parse"
+)
+
+var deferExpression parse.Parser[Node] = deferExpressionParser{}
+
+type deferExpressionParser struct{}
+
+var deferExpressionStart = parse.All(parse.String("defer"), openBraceWithOptionalPadding, parse.NewLine)
+
+func (deferExpressionParser) Parse(pi *parse.Input) (n Node, ok bool, err error) {
+	var r DeferExpression
+	start := pi.Index()
+
+	// Eat "defer {\n".
+	if _, ok, err = deferExpressionStart.Parse(pi); err != nil || !ok {
+		pi.Seek(start)
+		return r, false, err
+	}
+
+	// Node contents.
+	tnp := newTemplateNodeParser(closeBraceWithOptionalPadding, "defer expression closing brace")
+	var nodes Nodes
+	if nodes, ok, err = tnp.Parse(pi); err != nil || !ok {
+		err = parse.Error("defer: expected nodes, but none were found", pi.Position())
+		return
+	}
+	r.Children = nodes.Nodes
+
+	// Read the required closing brace.
+	if _, ok, err = closeBraceWithOptionalPadding.Parse(pi); err != nil || !ok {
+		err = parse.Error("defer: "+unterminatedMissingEnd, pi.Position())
+		return
+	}
+
+	return r, true, nil
+}
@@ -0,0 +1,88 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/a-h/parse"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDeferExpressionParser(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected DeferExpression
+	}{
+		{
+			name: "defer: simple",
+			input: `defer {
+	<span class="footer"></span>
+}`,
+			expected: DeferExpression{
+				Children: []Node{
+					Whitespace{Value: "\t"},
+					Element{
+						Name: "span",
+						NameRange: Range{
+							From: Position{Index: 10, Line: 1, Col: 2},
+							To:   Position{Index: 14, Line: 1, Col: 6},
+						},
+						Attributes: []Attribute{
+							ConstantAttribute{
+								Name:  "class",
+								Value: "footer",
+								NameRange: Range{
+									From: Position{Index: 15, Line: 1, Col: 7},
+									To:   Position{Index: 20, Line: 1, Col: 12},
+								},
+							},
+						},
+						TrailingSpace: SpaceVertical,
+					},
+				},
+			},
+		},
+		{
+			name: "defer: without spaces",
+			input: `defer{
+}`,
+			expected: DeferExpression{},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			input := parse.NewInput(tt.input)
+			actual, ok, err := deferExpression.Parse(input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !ok {
+				t.Fatalf("unexpected failure for input %q", tt.input)
+			}
+			if diff := cmp.Diff(tt.expected, actual); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestIncompleteDefer(t *testing.T) {
+	t.Run("text is not matched", func(t *testing.T) {
+		input := parse.NewInput(`defer the decision`)
+		_, ok, err := deferExpression.Parse(input)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ok {
+			t.Fatal("expected a non match")
+		}
+	})
+	t.Run("missing closing brace", func(t *testing.T) {
+		input := parse.NewInput("defer {\n<div></div>")
+		_, _, err := deferExpression.Parse(input)
+		if err == nil {
+			t.Fatal("expected an error, got nil")
+		}
+	})
+}
@@ -3,6 +3,12 @@ package parser
 import (
 	"errors"
 	"fmt"
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"maps"
+	"strconv"
+	"strings"
 )
 
 type diagnoser func(Node) ([]Diagnostic, error)
@@ -11,15 +17,34 @@ type diagnoser func(Node) ([]Diagnostic, error)
 type Diagnostic struct {
 	Message string
 	Range   Range
+	// Fix is a change to the template file that resolves the diagnostic, if
+	// there is one.
+	Fix *Fix
 }
 
-func walkTemplate(t TemplateFile, f func(Node) bool) {
-	for _, n := range t.Nodes {
+// Fix is a change to a template file, made up of edits to its source.
+type Fix struct {
+	// Title describes the change, e.g. for an LSP code action.
+	Title string
+	Edits []TextEdit
+}
+
+// TextEdit replaces the source within the range with the new text.
+type TextEdit struct {
+	Range   Range
+	NewText string
+}
+
+// walkTemplate walks the nodes of the file's templates, and whether the
+// template that contains them generates XML.
+func walkTemplate(t TemplateFile, f func(n Node, xml bool) bool) {
+	for i, n := range t.Nodes {
 		hn, ok := n.(HTMLTemplate)
 		if !ok {
 			continue
 		}
-		walkNodes(hn.Children, f)
+		xml := t.IsXML(i)
+		walkNodes(hn.Children, func(n Node) bool { return f(n, xml) })
 	}
 }
 func walkNodes(t []Node, f func(Node) bool) {
@@ -35,14 +60,24 @@ func walkNodes(t []Node, f func(Node) bool) {
 
 var diagnosers = []diagnoser{
 	useOfLegacyCallSyntaxDiagnoser,
+	dynamicScriptTypeDiagnoser,
+	duplicateAttributeDiagnoser,
+}
+
+// htmlDiagnosers only apply to templates that generate HTML, not XML.
+var htmlDiagnosers = []diagnoser{
 	voidElementWithChildrenDiagnoser,
 }
 
 func Diagnose(t TemplateFile) ([]Diagnostic, error) {
 	var diags []Diagnostic
 	var errs error
-	walkTemplate(t, func(n Node) bool {
-		for _, d := range diagnosers {
+	walkTemplate(t, func(n Node, xml bool) bool {
+		ds := diagnosers
+		if !xml {
+			ds = append(ds[:len(ds):len(ds)], htmlDiagnosers...)
+		}
+		for _, d := range ds {
 			diag, err := d(n)
 			if err != nil {
 				errs = errors.Join(errs, err)
@@ -76,8 +111,164 @@ func voidElementWithChildrenDiagnoser(n Node) (d []Diagnostic, err error) {
 	if len(e.Children) == 0 {
 		return
 	}
+	// The children can be moved after the element by making it self-closing,
+	// and removing the close tag, e.g. <input>Text</input> becomes
+	// <input/>Text.
 	return []Diagnostic{{
 		Message: fmt.Sprintf("void element <%s> should not have child content", e.Name),
 		Range:   e.NameRange,
+		Fix: &Fix{
+			Title: fmt.Sprintf("Make <%s> self-closing, and move its child content after it", e.Name),
+			Edits: []TextEdit{
+				{Range: e.OpenTagEndRange, NewText: "/>"},
+				{Range: e.CloseTagRange, NewText: ""},
+			},
+		},
 	}}, nil
 }
+
+// dynamicScriptTypeDiagnoser reports script elements whose type or lang
+// attribute isn't a constant. Whether the contents are TypeScript, and need
+// to be transpiled, is decided when the code is generated, so the contents
+// are output as they are.
+func dynamicScriptTypeDiagnoser(n Node) (d []Diagnostic, err error) {
+	e, ok := n.(RawElement)
+	if !ok || !strings.EqualFold(e.Name, "script") {
+		return
+	}
+	for _, r := range dynamicScriptTypeRanges(e.Attributes, false) {
+		d = append(d, Diagnostic{
+			Message: "<script> type and lang attributes should be constant, because TypeScript is only transpiled to JavaScript if the type is known when code is generated",
+			Range:   r,
+		})
+	}
+	return d, nil
+}
+
+func dynamicScriptTypeRanges(attrs []Attribute, conditional bool) (ranges []Range) {
+	isTypeAttribute := func(name string) bool {
+		return strings.EqualFold(name, "type") || strings.EqualFold(name, "lang")
+	}
+	for _, attr := range attrs {
+		switch attr := attr.(type) {
+		case ConstantAttribute:
+			if conditional && isTypeAttribute(attr.Name) {
+				ranges = append(ranges, attr.NameRange)
+			}
+		case ExpressionAttribute:
+			if isTypeAttribute(attr.Name) {
+				ranges = append(ranges, attr.NameRange)
+			}
+		case InterpolatedAttribute:
+			if isTypeAttribute(attr.Name) {
+				ranges = append(ranges, attr.NameRange)
+			}
+		case ConditionalAttribute:
+			ranges = append(ranges, dynamicScriptTypeRanges(attr.Then, true)...)
+			ranges = append(ranges, dynamicScriptTypeRanges(attr.Else, true)...)
+		}
+	}
+	return ranges
+}
+
+// duplicateAttributeDiagnoser reports attributes that are set more than once
+// on an element, e.g. <div id="a" id="b">, because browsers use the first
+// value, and ignore the others. The keys of spread attributes are only known
+// if they're a composite literal, e.g. templ.Attributes{"id": "a"}.
+func duplicateAttributeDiagnoser(n Node) (d []Diagnostic, err error) {
+	switch n := n.(type) {
+	case Element:
+		return duplicateAttributes(n.Name, n.Attributes, map[string]struct{}{}), nil
+	case RawElement:
+		return duplicateAttributes(n.Name, n.Attributes, map[string]struct{}{}), nil
+	}
+	return nil, nil
+}
+
+func duplicateAttributes(elementName string, attrs []Attribute, seen map[string]struct{}) (d []Diagnostic) {
+	for _, attr := range attrs {
+		if ca, ok := attr.(ConditionalAttribute); ok {
+			// Only one of the branches is used, but the attributes of
+			// either can be duplicated by the attributes that follow.
+			thenSeen, elseSeen := maps.Clone(seen), maps.Clone(seen)
+			d = append(d, duplicateAttributes(elementName, ca.Then, thenSeen)...)
+			d = append(d, duplicateAttributes(elementName, ca.Else, elseSeen)...)
+			maps.Copy(seen, thenSeen)
+			maps.Copy(seen, elseSeen)
+			continue
+		}
+		for _, na := range namedAttributes(attr) {
+			key := strings.ToLower(na.name)
+			if _, ok := seen[key]; ok {
+				d = append(d, Diagnostic{
+					Message: fmt.Sprintf("<%s> has more than one %s attribute, browsers use the first, and ignore the others", elementName, na.name),
+					Range:   na.r,
+				})
+				continue
+			}
+			seen[key] = struct{}{}
+		}
+	}
+	return d
+}
+
+type namedAttribute struct {
+	name string
+	r    Range
+}
+
+// namedAttributes returns the names of the attributes that the attribute
+// sets, if they're known before the template is rendered.
+func namedAttributes(attr Attribute) (attrs []namedAttribute) {
+	switch attr := attr.(type) {
+	case BoolConstantAttribute:
+		return []namedAttribute{{attr.Name, attr.NameRange}}
+	case ConstantAttribute:
+		return []namedAttribute{{attr.Name, attr.NameRange}}
+	case BoolExpressionAttribute:
+		return []namedAttribute{{attr.Name, attr.NameRange}}
+	case ExpressionAttribute:
+		return []namedAttribute{{attr.Name, attr.NameRange}}
+	case InterpolatedAttribute:
+		return []namedAttribute{{attr.Name, attr.NameRange}}
+	case DynamicAttribute:
+		if name, ok := stringLiteral(attr.Name.Value); ok {
+			return []namedAttribute{{name, attr.Name.Range}}
+		}
+	case SpreadAttributes:
+		expr, err := goparser.ParseExpr(attr.Expression.Value)
+		if err != nil {
+			return nil
+		}
+		lit, ok := expr.(*ast.CompositeLit)
+		if !ok {
+			return nil
+		}
+		for _, elt := range lit.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				continue
+			}
+			if key, ok := kv.Key.(*ast.BasicLit); ok && key.Kind == token.STRING {
+				if name, err := strconv.Unquote(key.Value); err == nil {
+					attrs = append(attrs, namedAttribute{name, attr.Expression.Range})
+				}
+			}
+		}
+	}
+	return attrs
+}
+
+// stringLiteral returns the value of the Go string literal.
+func stringLiteral(src string) (value string, ok bool) {
+	expr, err := goparser.ParseExpr(src)
+	if err != nil {
+		return "", false
+	}
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	value, err = strconv.Unquote(lit.Value)
+	return value, err == nil
+}
@@ -147,6 +147,132 @@ templ template () {
 			want: []Diagnostic{{
 				Message: "void element <input> should not have child content",
 				Range:   Range{Position{46, 5, 4}, Position{51, 5, 9}},
+				Fix: &Fix{
+					Title: "Make <input> self-closing, and move its child content after it",
+					Edits: []TextEdit{
+						{Range: Range{Position{51, 5, 9}, Position{52, 5, 10}}, NewText: "/>"},
+						{Range: Range{Position{65, 5, 23}, Position{73, 5, 31}}, NewText: ""},
+					},
+				},
+			}},
+		},
+		{
+			name: "voidElementWithChildrenDiagnoser: no diagnostics in XML",
+			template: `
+package main
+
+// templ:xml
+templ template () {
+	<channel>
+	  <link>https://example.com</link>
+	</channel>
+}`,
+			want: nil,
+		},
+
+		// dynamicScriptTypeDiagnoser
+
+		{
+			name: "dynamicScriptTypeDiagnoser: constant type",
+			template: `
+package main
+
+templ template () {
+	<script type="text/typescript">const a: number = 1;</script>
+}`,
+			want: nil,
+		},
+		{
+			name: "dynamicScriptTypeDiagnoser: expression type",
+			template: `
+package main
+
+templ template (scriptType string) {
+	<script type={ scriptType }>const a = 1;</script>
+}`,
+			want: []Diagnostic{{
+				Message: "<script> type and lang attributes should be constant, because TypeScript is only transpiled to JavaScript if the type is known when code is generated",
+				Range:   Range{Position{61, 4, 9}, Position{65, 4, 13}},
+			}},
+		},
+		{
+			name: "dynamicScriptTypeDiagnoser: conditional type",
+			template: `
+package main
+
+templ template (ts bool) {
+	<script
+		if ts {
+			lang="ts"
+		}
+	>const a = 1;</script>
+}`,
+			want: []Diagnostic{{
+				Message: "<script> type and lang attributes should be constant, because TypeScript is only transpiled to JavaScript if the type is known when code is generated",
+				Range:   Range{Position{64, 6, 3}, Position{68, 6, 7}},
+			}},
+		},
+
+		// duplicateAttributeDiagnoser
+
+		{
+			name: "duplicateAttributeDiagnoser: attributes in if and else branches",
+			template: `
+package main
+
+templ template (ok bool) {
+	<div
+		if ok {
+			class="ok"
+		} else {
+			class="error"
+		}
+	></div>
+}`,
+			want: nil,
+		},
+		{
+			name: "duplicateAttributeDiagnoser: constant attributes",
+			template: `
+package main
+
+templ template () {
+	<div id="a" ID="b"></div>
+}`,
+			want: []Diagnostic{{
+				Message: "<div> has more than one ID attribute, browsers use the first, and ignore the others",
+				Range:   Range{Position{48, 4, 13}, Position{50, 4, 15}},
+			}},
+		},
+		{
+			name: "duplicateAttributeDiagnoser: spread attributes",
+			template: `
+package main
+
+templ template () {
+	<input type="text" { templ.Attributes{"type": "email"}... } { attrs... }/>
+}`,
+			want: []Diagnostic{{
+				Message: "<input> has more than one type attribute, browsers use the first, and ignore the others",
+				Range:   Range{Position{57, 4, 22}, Position{90, 4, 55}},
+			}},
+		},
+		{
+			name: "duplicateAttributeDiagnoser: attributes after if",
+			template: `
+package main
+
+templ template (ok bool) {
+	<p
+		if ok {
+			class="ok"
+		}
+		{ "class" }={ "other" }
+	></p>
+}`,
+			want: []Diagnostic{{
+				Message: "<p> has more than one class attribute, browsers use the first, and ignore the others",
+				Range:   Range{Position{78, 8, 4}, Position{85, 8, 11}},
 			}},
 		},
 	}
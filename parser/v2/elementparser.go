@@ -2,8 +2,10 @@ package parser
 
 import (
 	"fmt"
+	goparser "go/parser"
 	"html"
 	"strings"
+	"unicode"
 
 	"github.com/a-h/parse"
 	"github.com/a-h/templ/parser/v2/goexpression"
@@ -75,10 +77,12 @@ var elementOpenTagParser = parse.Func(func(pi *parse.Input) (e elementOpenTag, o
 	return e, true, nil
 })
 
-// Attribute name.
+// Attribute name. As well as HTML attribute names, the shorthands of Alpine.js
+// and Vue.js are allowed, e.g. @click.prevent, :class, #default, and dynamic
+// arguments such as :[key].
 var (
-	attributeNameFirst      = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ:_@"
-	attributeNameSubsequent = attributeNameFirst + "-.0123456789*"
+	attributeNameFirst      = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ:_@#"
+	attributeNameSubsequent = attributeNameFirst + "-.0123456789*[]"
 	attributeNameParser     = parse.Func(func(in *parse.Input) (name string, ok bool, err error) {
 		start := in.Index()
 		var prefix, suffix string
@@ -102,7 +106,17 @@ var (
 var (
 	attributeConstantValueParser            = parse.StringUntil(parse.Rune('"'))
 	attributeConstantValueSingleQuoteParser = parse.StringUntil(parse.Rune('\''))
-	constantAttributeParser                 = parse.Func(func(pi *parse.Input) (attr ConstantAttribute, ok bool, err error) {
+	// Unquoted values can't contain whitespace, quotes, =, <, >, or `.
+	// Braces are used by expression attributes, so they're not allowed either.
+	// A value followed by /> ends before it, so that the element is
+	// self-closing, e.g. <img src=a.png/>.
+	attributeUnquotedValueParser = parse.StringUntilEOF(parse.Any(parse.String("/>"), parse.RuneWhere(func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune("\"'=<>`{}", r)
+	})))
+	// attributeEqualsParser parses the = between the name and value of an
+	// attribute, which can have whitespace around it, e.g. id = "main".
+	attributeEqualsParser   = parse.All(parse.OptionalWhitespace, parse.String("="), parse.OptionalWhitespace)
+	constantAttributeParser = parse.Func(func(pi *parse.Input) (attr ConstantAttribute, ok bool, err error) {
 		start := pi.Index()
 
 		// Optional whitespace leader.
@@ -117,19 +131,36 @@ var (
 		}
 		attr.NameRange = NewRange(pi.PositionAt(pi.Index()-len(attr.Name)), pi.Position())
 
-		// ="
-		result, ok, err := parse.Or(parse.String(`="`), parse.String(`='`)).Parse(pi)
-		if err != nil || !ok {
+		// =
+		if _, ok, err = attributeEqualsParser.Parse(pi); err != nil || !ok {
 			pi.Seek(start)
 			return
 		}
 
+		// "
+		result, ok, err := parse.Or(parse.String(`"`), parse.String(`'`)).Parse(pi)
+		if err != nil {
+			pi.Seek(start)
+			return
+		}
+		if !ok {
+			// Unquoted value, e.g. id=main.
+			if attr.Value, ok, err = attributeUnquotedValueParser.Parse(pi); err != nil || !ok || attr.Value == "" {
+				pi.Seek(start)
+				return attr, false, err
+			}
+			attr.Value = html.UnescapeString(attr.Value)
+			attr.Quote = AttributeQuoteNone
+			return attr, true, nil
+		}
+
 		valueParser := attributeConstantValueParser
 		closeParser := parse.String(`"`)
 		if result.B.OK {
 			valueParser = attributeConstantValueSingleQuoteParser
 			closeParser = parse.String(`'`)
 			attr.SingleQuote = true
+			attr.Quote = AttributeQuoteSingle
 		}
 
 		// Attribute value.
@@ -171,6 +202,10 @@ var boolConstantAttributeParser = parse.Func(func(pi *parse.Input) (attr BoolCon
 	attr.NameRange = NewRange(pi.PositionAt(pi.Index()-len(attr.Name)), pi.Position())
 
 	// We have a name, but if we have an equals sign, it's not a constant boolean attribute.
+	if _, ok, _ = attributeEqualsParser.Parse(pi); ok {
+		pi.Seek(start)
+		return attr, false, nil
+	}
 	next, ok := pi.Peek(1)
 	if !ok {
 		err = parse.Error("boolConstantAttributeParser: unexpected EOF after attribute name", pi.Position())
@@ -189,8 +224,12 @@ var boolConstantAttributeParser = parse.Func(func(pi *parse.Input) (attr BoolCon
 	return attr, true, nil
 })
 
-// BoolExpressionAttribute.
-var boolExpressionStart = parse.Or(parse.String("?={ "), parse.String("?={"))
+// BoolExpressionAttribute, e.g. disabled?={ cond }, or the shorthand ?disabled={ cond }.
+var (
+	boolExpressionStart       = parse.Or(parse.String("?={ "), parse.String("?={"))
+	boolExpressionPrefix      = parse.Rune('?')
+	boolExpressionPrefixStart = parse.Or(parse.String("={ "), parse.String("={"))
+)
 
 var boolExpressionAttributeParser = parse.Func(func(pi *parse.Input) (r BoolExpressionAttribute, ok bool, err error) {
 	start := pi.Index()
@@ -201,15 +240,31 @@ var boolExpressionAttributeParser = parse.Func(func(pi *parse.Input) (r BoolExpr
 		return
 	}
 
+	// Optional ? prefix.
+	if _, r.Prefixed, err = boolExpressionPrefix.Parse(pi); err != nil {
+		pi.Seek(start)
+		return
+	}
+
 	// Attribute name.
 	if r.Name, ok, err = attributeNameParser.Parse(pi); err != nil || !ok {
+		if r.Prefixed && err == nil {
+			err = parse.Error("boolean attribute: expected an attribute name after ?", pi.Position())
+		}
 		pi.Seek(start)
 		return
 	}
 	r.NameRange = NewRange(pi.PositionAt(pi.Index()-len(r.Name)), pi.Position())
 
 	// Check whether this is a boolean expression attribute.
-	if _, ok, err = boolExpressionStart.Parse(pi); err != nil || !ok {
+	startParser := boolExpressionStart
+	if r.Prefixed {
+		startParser = boolExpressionPrefixStart
+	}
+	if _, ok, err = startParser.Parse(pi); err != nil || !ok {
+		if r.Prefixed && err == nil {
+			err = parse.Error(fmt.Sprintf("boolean attribute ?%s: expected ={ after the attribute name", r.Name), pi.Position())
+		}
 		pi.Seek(start)
 		return
 	}
@@ -267,6 +322,106 @@ var expressionAttributeParser = parse.Func(func(pi *parse.Input) (attr Expressio
 	return attr, true, nil
 })
 
+// isInterpolatable returns true if expressions within the value of attributes
+// with the name are interpolated. The values of event handlers, styles,
+// patterns, and framework directives such as Alpine.js's x-data are
+// JavaScript, CSS or regular expressions, so they're always constant.
+func isInterpolatable(name string) bool {
+	name = strings.ToLower(name)
+	if name == "style" || name == "pattern" {
+		return false
+	}
+	for _, prefix := range []string{"on", "hx-on", "x-", "v-", "@", ":", "#"} {
+		if strings.HasPrefix(name, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// interpolationStart is the start of an expression within a quoted attribute
+// value. Braces without the $ prefix are constant text.
+var interpolationStart = parse.String("${")
+
+// interpolatedAttributeParser parses quoted attribute values that contain
+// expressions, e.g. class="btn btn-${ variant }". If any ${ in the value
+// doesn't contain a Go expression, the attribute is left to the constant
+// attribute parser, so that the value is unchanged.
+var interpolatedAttributeParser = parse.Func(func(pi *parse.Input) (attr InterpolatedAttribute, ok bool, err error) {
+	start := pi.Index()
+	notInterpolated := func() (InterpolatedAttribute, bool, error) {
+		pi.Seek(start)
+		return attr, false, nil
+	}
+
+	// Optional whitespace leader.
+	if _, ok, err = parse.OptionalWhitespace.Parse(pi); err != nil || !ok {
+		return
+	}
+
+	// Attribute name.
+	if attr.Name, ok, err = attributeNameParser.Parse(pi); err != nil || !ok {
+		pi.Seek(start)
+		return
+	}
+	attr.NameRange = NewRange(pi.PositionAt(pi.Index()-len(attr.Name)), pi.Position())
+	if !isInterpolatable(attr.Name) {
+		return notInterpolated()
+	}
+
+	// =" or ='
+	if _, ok, _ = attributeEqualsParser.Parse(pi); !ok {
+		return notInterpolated()
+	}
+	result, ok, err := parse.Or(parse.String(`"`), parse.String(`'`)).Parse(pi)
+	if err != nil || !ok {
+		return notInterpolated()
+	}
+	quote := `"`
+	if result.B.OK {
+		quote = `'`
+	}
+
+	var hasExpression bool
+	for {
+		// Constant text.
+		var text string
+		if text, ok, err = parse.StringUntil(parse.Or(parse.String(quote), interpolationStart)).Parse(pi); err != nil || !ok {
+			return notInterpolated()
+		}
+		if text != "" {
+			attr.Parts = append(attr.Parts, AttributeValuePart{Value: html.UnescapeString(text)})
+		}
+		// Closing quote.
+		if _, ok, _ = parse.String(quote).Parse(pi); ok {
+			break
+		}
+		// ${ expression }
+		if _, ok, _ = interpolationStart.Parse(pi); !ok {
+			return notInterpolated()
+		}
+		if _, _, err = optionalSpaces.Parse(pi); err != nil {
+			return notInterpolated()
+		}
+		expr, err := parseGo("attribute value", pi, goexpression.Expression)
+		if err != nil {
+			return notInterpolated()
+		}
+		if _, err = goparser.ParseExpr(expr.Value); err != nil {
+			return notInterpolated()
+		}
+		if _, ok, _ = closeBraceWithOptionalPadding.Parse(pi); !ok {
+			return notInterpolated()
+		}
+		attr.Parts = append(attr.Parts, AttributeValuePart{Expression: &expr})
+		hasExpression = true
+	}
+	if !hasExpression {
+		return notInterpolated()
+	}
+	return attr, true, nil
+})
+
 var spreadAttributesParser = parse.Func(func(pi *parse.Input) (attr SpreadAttributes, ok bool, err error) {
 	start := pi.Index()
 
@@ -308,6 +463,49 @@ var spreadAttributesParser = parse.Func(func(pi *parse.Input) (attr SpreadAttrib
 	return attr, true, nil
 })
 
+// dynamicAttributeParser parses attributes whose name is a Go expression,
+// e.g. { name }={ value }. If the expression isn't followed by ={, it's left
+// to the spread attributes parser.
+var dynamicAttributeParser = parse.Func(func(pi *parse.Input) (attr DynamicAttribute, ok bool, err error) {
+	start := pi.Index()
+
+	// Optional whitespace leader.
+	if _, ok, err = parse.OptionalWhitespace.Parse(pi); err != nil || !ok {
+		return
+	}
+
+	// Name.
+	if _, ok, err = openBraceWithOptionalPadding.Parse(pi); err != nil || !ok {
+		pi.Seek(start)
+		return
+	}
+	if attr.Name, err = parseGo("attribute name", pi, goexpression.Expression); err != nil {
+		pi.Seek(start)
+		return attr, false, nil
+	}
+	if _, ok, _ = closeBraceWithOptionalPadding.Parse(pi); !ok {
+		pi.Seek(start)
+		return attr, false, nil
+	}
+
+	// ={
+	if _, ok, err = parse.Or(parse.String("={ "), parse.String("={")).Parse(pi); err != nil || !ok {
+		pi.Seek(start)
+		return attr, false, nil
+	}
+
+	// Value.
+	if attr.Value, err = parseGo("attribute value", pi, goexpression.Expression); err != nil {
+		return attr, false, err
+	}
+	if _, ok, err = closeBraceWithOptionalPadding.Parse(pi); err != nil || !ok {
+		err = parse.Error("dynamic attribute: missing closing brace", pi.Position())
+		return
+	}
+
+	return attr, true, nil
+})
+
 // Attributes.
 type attributeParser struct{}
 
@@ -324,9 +522,15 @@ func (attributeParser) Parse(in *parse.Input) (out Attribute, ok bool, err error
 	if out, ok, err = boolConstantAttributeParser.Parse(in); err != nil || ok {
 		return
 	}
+	if out, ok, err = dynamicAttributeParser.Parse(in); err != nil || ok {
+		return
+	}
 	if out, ok, err = spreadAttributesParser.Parse(in); err != nil || ok {
 		return
 	}
+	if out, ok, err = interpolatedAttributeParser.Parse(in); err != nil || ok {
+		return
+	}
 	if out, ok, err = constantAttributeParser.Parse(in); err != nil || ok {
 		return
 	}
@@ -407,6 +611,8 @@ func (elementParser) Parse(pi *parse.Input) (n Node, ok bool, err error) {
 	// Void elements _might_ have children, even though it's invalid.
 	// We want to allow this to be parsed.
 	closer := StripType(parse.All(parse.String("</"), parse.String(ot.Name), parse.Rune('>')))
+	// Branch statements can't be used within elements, since they'd skip the
+	// close tag, so the children start without a branch context.
 	tnp := newTemplateNodeParser[any](closer, fmt.Sprintf("<%s>: close tag", ot.Name))
 	nodes, _, err := tnp.Parse(pi)
 	if err != nil {
@@ -424,12 +630,18 @@ func (elementParser) Parse(pi *parse.Input) (n Node, ok bool, err error) {
 		return r, false, err
 	}
 	r.Children = nodes.Nodes
+	if isWhitespaceSensitive(r.Name) || precededByPreservePragma(pi, start.Index) {
+		endOfChildren := pi.Index()
+		pi.Seek(endOfOpenTag)
+		r.RawChildren, _ = pi.Take(endOfChildren - endOfOpenTag)
+	}
 	// If the children are not all on the same line, indent them.
 	if l != pi.Position().Line {
 		r.IndentChildren = true
 	}
 
 	// Close tag.
+	closeTagStart := pi.Position()
 	_, ok, err = closer.Parse(pi)
 	if err != nil {
 		return r, false, err
@@ -438,10 +650,46 @@ func (elementParser) Parse(pi *parse.Input) (n Node, ok bool, err error) {
 		err = parse.Error(fmt.Sprintf("<%s>: expected end tag not present or invalid tag contents", r.Name), pi.Position())
 		return r, false, err
 	}
+	if r.IsVoidElement() && len(r.Children) > 0 {
+		r.OpenTagEndRange = NewRange(pi.PositionAt(endOfOpenTag-1), pi.PositionAt(endOfOpenTag))
+		r.CloseTagRange = NewRange(closeTagStart, pi.Position())
+	}
 
 	return addTrailingSpaceAndValidate(start, r, pi)
 }
 
+// whitespaceSensitiveElements contain text where whitespace is significant,
+// so the formatter doesn't re-indent their children.
+var whitespaceSensitiveElements = map[string]struct{}{
+	"pre": {}, "textarea": {},
+}
+
+func isWhitespaceSensitive(name string) bool {
+	_, ok := whitespaceSensitiveElements[strings.ToLower(name)]
+	return ok
+}
+
+// preservePragma is a Go comment that marks the next element as
+// whitespace-sensitive, e.g. an element styled with white-space: pre.
+const preservePragma = "// templ:preserve"
+
+// precededByPreservePragma returns true if the line before the element that
+// starts at index is the preserve pragma.
+func precededByPreservePragma(pi *parse.Input, index int) bool {
+	current := pi.Index()
+	defer pi.Seek(current)
+	from := max(index-256, 0)
+	if !pi.Seek(from) {
+		return false
+	}
+	before, ok := pi.Peek(index - from)
+	if !ok {
+		return false
+	}
+	lines := strings.Split(strings.TrimRight(before, " \t\r\n"), "\n")
+	return strings.TrimSpace(lines[len(lines)-1]) == preservePragma
+}
+
 func addTrailingSpaceAndValidate(start parse.Position, e Element, pi *parse.Input) (n Node, ok bool, err error) {
 	// Add trailing space.
 	ws, _, err := parse.Whitespace.Parse(pi)
@@ -93,6 +93,51 @@ func TestAttributeParser(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:   "element: open with Alpine.js and Vue.js shorthands",
+			input:  `<div @click.prevent="go()" #item="{ item }" :[key]="v" x-on:keyup.shift.enter>`,
+			parser: StripType(elementOpenTagParser),
+			expected: elementOpenTag{
+				Name: "div",
+				NameRange: Range{
+					From: Position{Index: 1, Line: 0, Col: 1},
+					To:   Position{Index: 4, Line: 0, Col: 4},
+				},
+				Attributes: []Attribute{
+					ConstantAttribute{
+						Name:  "@click.prevent",
+						Value: "go()",
+						NameRange: Range{
+							From: Position{Index: 5, Line: 0, Col: 5},
+							To:   Position{Index: 19, Line: 0, Col: 19},
+						},
+					},
+					ConstantAttribute{
+						Name:  "#item",
+						Value: "{ item }",
+						NameRange: Range{
+							From: Position{Index: 27, Line: 0, Col: 27},
+							To:   Position{Index: 32, Line: 0, Col: 32},
+						},
+					},
+					ConstantAttribute{
+						Name:  ":[key]",
+						Value: "v",
+						NameRange: Range{
+							From: Position{Index: 44, Line: 0, Col: 44},
+							To:   Position{Index: 50, Line: 0, Col: 50},
+						},
+					},
+					BoolConstantAttribute{
+						Name: "x-on:keyup.shift.enter",
+						NameRange: Range{
+							From: Position{Index: 55, Line: 0, Col: 55},
+							To:   Position{Index: 77, Line: 0, Col: 77},
+						},
+					},
+				},
+			},
+		},
 		{
 			name:   "element: open with attributes",
 			input:  `<div id="123" style="padding: 10px">`,
@@ -280,6 +325,26 @@ if test {
 				},
 			},
 		},
+		{
+			name:   "boolean expression attribute with a ? prefix",
+			input:  ` ?disabled={ !enabled }"`,
+			parser: StripType(boolExpressionAttributeParser),
+			expected: BoolExpressionAttribute{
+				Name: "disabled",
+				NameRange: Range{
+					From: Position{Index: 2, Line: 0, Col: 2},
+					To:   Position{Index: 10, Line: 0, Col: 10},
+				},
+				Expression: Expression{
+					Value: "!enabled",
+					Range: Range{
+						From: Position{Index: 13, Line: 0, Col: 13},
+						To:   Position{Index: 21, Line: 0, Col: 21},
+					},
+				},
+				Prefixed: true,
+			},
+		},
 		{
 			name:   "attribute parsing handles boolean expression attributes",
 			input:  ` noshade?={ true }`,
@@ -356,6 +421,67 @@ if test {
 				},
 			},
 		},
+		{
+			name:   "class expression attribute with conditional class names",
+			input:  ` class={ "btn", "active": isActive }"`,
+			parser: StripType(expressionAttributeParser),
+			expected: ExpressionAttribute{
+				Name: "class",
+				NameRange: Range{
+					From: Position{Index: 1, Line: 0, Col: 1},
+					To:   Position{Index: 6, Line: 0, Col: 6},
+				},
+				Expression: Expression{
+					Value: `"btn", "active": isActive`,
+					Range: Range{
+						From: Position{Index: 9, Line: 0, Col: 9},
+						To:   Position{Index: 34, Line: 0, Col: 34},
+					},
+				},
+			},
+		},
+		{
+			name:   "dynamic attribute",
+			input:  ` { name }={ value }"`,
+			parser: StripType(dynamicAttributeParser),
+			expected: DynamicAttribute{
+				Name: Expression{
+					Value: "name",
+					Range: Range{
+						From: Position{Index: 3, Line: 0, Col: 3},
+						To:   Position{Index: 7, Line: 0, Col: 7},
+					},
+				},
+				Value: Expression{
+					Value: "value",
+					Range: Range{
+						From: Position{Index: 12, Line: 0, Col: 12},
+						To:   Position{Index: 17, Line: 0, Col: 17},
+					},
+				},
+			},
+		},
+		{
+			name:   "dynamic attribute without spaces",
+			input:  ` {name}={fmt.Sprint(1)}"`,
+			parser: StripType(dynamicAttributeParser),
+			expected: DynamicAttribute{
+				Name: Expression{
+					Value: "name",
+					Range: Range{
+						From: Position{Index: 2, Line: 0, Col: 2},
+						To:   Position{Index: 6, Line: 0, Col: 6},
+					},
+				},
+				Value: Expression{
+					Value: "fmt.Sprint(1)",
+					Range: Range{
+						From: Position{Index: 9, Line: 0, Col: 9},
+						To:   Position{Index: 22, Line: 0, Col: 22},
+					},
+				},
+			},
+		},
 		{
 			name:   "constant attribute",
 			input:  ` href="test"`,
@@ -381,6 +507,76 @@ if test {
 					From: Position{Index: 1, Line: 0, Col: 1},
 					To:   Position{Index: 5, Line: 0, Col: 5},
 				},
+				Quote: AttributeQuoteSingle,
+			},
+		},
+		{
+			name:   "unquoted constant attribute",
+			input:  ` type=text>`,
+			parser: StripType(constantAttributeParser),
+			expected: ConstantAttribute{
+				Name:  "type",
+				Value: "text",
+				NameRange: Range{
+					From: Position{Index: 1, Line: 0, Col: 1},
+					To:   Position{Index: 5, Line: 0, Col: 5},
+				},
+				Quote: AttributeQuoteNone,
+			},
+		},
+		{
+			name:   "unquoted constant attribute with entities",
+			input:  ` title=a&amp;b>`,
+			parser: StripType(constantAttributeParser),
+			expected: ConstantAttribute{
+				Name:  "title",
+				Value: "a&b",
+				NameRange: Range{
+					From: Position{Index: 1, Line: 0, Col: 1},
+					To:   Position{Index: 6, Line: 0, Col: 6},
+				},
+				Quote: AttributeQuoteNone,
+			},
+		},
+		{
+			name:   "unquoted constant attribute before self-closing tag end",
+			input:  ` src=/a.png/>`,
+			parser: StripType(constantAttributeParser),
+			expected: ConstantAttribute{
+				Name:  "src",
+				Value: "/a.png",
+				NameRange: Range{
+					From: Position{Index: 1, Line: 0, Col: 1},
+					To:   Position{Index: 4, Line: 0, Col: 4},
+				},
+				Quote: AttributeQuoteNone,
+			},
+		},
+		{
+			name:   "unquoted constant attribute with whitespace around equals",
+			input:  ` id = main>`,
+			parser: StripType(constantAttributeParser),
+			expected: ConstantAttribute{
+				Name:  "id",
+				Value: "main",
+				NameRange: Range{
+					From: Position{Index: 1, Line: 0, Col: 1},
+					To:   Position{Index: 3, Line: 0, Col: 3},
+				},
+				Quote: AttributeQuoteNone,
+			},
+		},
+		{
+			name:   "constant attribute with whitespace around equals",
+			input:  ` id= "main"`,
+			parser: StripType(constantAttributeParser),
+			expected: ConstantAttribute{
+				Name:  "id",
+				Value: "main",
+				NameRange: Range{
+					From: Position{Index: 1, Line: 0, Col: 1},
+					To:   Position{Index: 3, Line: 0, Col: 3},
+				},
 			},
 		},
 		{
@@ -395,6 +591,7 @@ if test {
 					From: Position{Index: 1, Line: 0, Col: 1},
 					To:   Position{Index: 5, Line: 0, Col: 5},
 				},
+				Quote: AttributeQuoteSingle,
 			},
 		},
 		{
@@ -600,6 +797,24 @@ func TestElementParser(t *testing.T) {
 				Children: []Node{Text{Value: "Content"}},
 			},
 		},
+		{
+			name:  "element: pre keeps the source of its children",
+			input: "<pre>  a\n    b</pre>",
+			expected: Element{
+				Name: "pre",
+				NameRange: Range{
+					From: Position{Index: 1, Line: 0, Col: 1},
+					To:   Position{Index: 4, Line: 0, Col: 4},
+				},
+				Children: []Node{
+					Whitespace{Value: "  "},
+					Text{Value: "a", TrailingSpace: SpaceVertical},
+					Text{Value: "b"},
+				},
+				IndentChildren: true,
+				RawChildren:    "  a\n    b",
+			},
+		},
 		{
 			name:  "element: void (input)",
 			input: `<input>`,
@@ -655,6 +870,14 @@ func TestElementParser(t *testing.T) {
 					To:   Position{Index: 6, Line: 0, Col: 6},
 				},
 				Children: []Node{Text{Value: "Text"}},
+				OpenTagEndRange: Range{
+					From: Position{Index: 6, Line: 0, Col: 6},
+					To:   Position{Index: 7, Line: 0, Col: 7},
+				},
+				CloseTagRange: Range{
+					From: Position{Index: 11, Line: 0, Col: 11},
+					To:   Position{Index: 19, Line: 0, Col: 19},
+				},
 			},
 		},
 		{
@@ -737,6 +960,14 @@ func TestElementParser(t *testing.T) {
 						},
 					},
 				},
+				OpenTagEndRange: Range{
+					From: Position{Index: 3, Line: 0, Col: 3},
+					To:   Position{Index: 4, Line: 0, Col: 4},
+				},
+				CloseTagRange: Range{
+					From: Position{Index: 8, Line: 0, Col: 8},
+					To:   Position{Index: 13, Line: 0, Col: 13},
+				},
 			},
 		},
 		{
@@ -1541,6 +1772,16 @@ func TestElementParserErrors(t *testing.T) {
 					Col:   3,
 				}),
 		},
+		{
+			name:  "element: boolean attribute with a ? prefix must have an expression",
+			input: `<input ?disabled/>`,
+			expected: parse.Error("boolean attribute ?disabled: expected ={ after the attribute name",
+				parse.Position{
+					Index: 16,
+					Line:  0,
+					Col:   16,
+				}),
+		},
 		{
 			name:  "element: style must only contain text",
 			input: `<style><button /></style>`,
@@ -1561,26 +1802,6 @@ func TestElementParserErrors(t *testing.T) {
 					Col:   0,
 				}),
 		},
-		{
-			name:  "element: attempted use of expression for style attribute (open/close)",
-			input: `<a style={ value }></a>`,
-			expected: parse.Error(`<a>: invalid style attribute: style attributes cannot be a templ expression`,
-				parse.Position{
-					Index: 0,
-					Line:  0,
-					Col:   0,
-				}),
-		},
-		{
-			name:  "element: attempted use of expression for style attribute (self-closing)",
-			input: `<a style={ value }/>`,
-			expected: parse.Error(`<a>: invalid style attribute: style attributes cannot be a templ expression`,
-				parse.Position{
-					Index: 0,
-					Line:  0,
-					Col:   0,
-				}),
-		},
 		{
 			name:  "element: script tags cannot contain non-text nodes",
 			input: `<script>{ "value" }</script>`,
@@ -1639,3 +1860,129 @@ func TestBigElement(t *testing.T) {
 		t.Errorf("unexpected failure to parse")
 	}
 }
+
+func TestInterpolatedAttributeParser(t *testing.T) {
+	expr := func(value string, from, to int) *Expression {
+		return &Expression{
+			Value: value,
+			Range: Range{
+				From: Position{Index: int64(from), Line: 0, Col: uint32(from)},
+				To:   Position{Index: int64(to), Line: 0, Col: uint32(to)},
+			},
+		}
+	}
+	tests := []struct {
+		name     string
+		input    string
+		expected Attribute
+	}{
+		{
+			name:  "constant text and expressions are concatenated",
+			input: ` class="btn btn-${ variant }"`,
+			expected: InterpolatedAttribute{
+				Name: "class",
+				NameRange: Range{
+					From: Position{Index: 1, Line: 0, Col: 1},
+					To:   Position{Index: 6, Line: 0, Col: 6},
+				},
+				Parts: []AttributeValuePart{
+					{Value: "btn btn-"},
+					{Expression: expr("variant", 19, 26)},
+				},
+			},
+		},
+		{
+			name:  "constant text is unescaped",
+			input: ` href='/users/${id}?name=a&amp;b'`,
+			expected: InterpolatedAttribute{
+				Name: "href",
+				NameRange: Range{
+					From: Position{Index: 1, Line: 0, Col: 1},
+					To:   Position{Index: 5, Line: 0, Col: 5},
+				},
+				Parts: []AttributeValuePart{
+					{Value: "/users/"},
+					{Expression: expr("id", 16, 18)},
+					{Value: "?name=a&b"},
+				},
+			},
+		},
+		{
+			name:  "braces that don't contain a Go expression are constant",
+			input: ` data-vals='{"id": 1}'`,
+			expected: ConstantAttribute{
+				Name:        "data-vals",
+				Value:       `{"id": 1}`,
+				SingleQuote: true,
+				NameRange: Range{
+					From: Position{Index: 1, Line: 0, Col: 1},
+					To:   Position{Index: 10, Line: 0, Col: 10},
+				},
+				Quote: AttributeQuoteSingle,
+			},
+		},
+		{
+			name:  "braces without a $ prefix are constant",
+			input: ` title="use {id}"`,
+			expected: ConstantAttribute{
+				Name:  "title",
+				Value: "use {id}",
+				NameRange: Range{
+					From: Position{Index: 1, Line: 0, Col: 1},
+					To:   Position{Index: 6, Line: 0, Col: 6},
+				},
+			},
+		},
+		{
+			name:  "regular expression quantifiers are constant",
+			input: ` pattern="[0-9]{3}-[0-9]{4}"`,
+			expected: ConstantAttribute{
+				Name:  "pattern",
+				Value: "[0-9]{3}-[0-9]{4}",
+				NameRange: Range{
+					From: Position{Index: 1, Line: 0, Col: 1},
+					To:   Position{Index: 8, Line: 0, Col: 8},
+				},
+			},
+		},
+		{
+			name:  "pattern attributes are constant",
+			input: ` pattern="^a\d${3}$"`,
+			expected: ConstantAttribute{
+				Name:  "pattern",
+				Value: `^a\d${3}$`,
+				NameRange: Range{
+					From: Position{Index: 1, Line: 0, Col: 1},
+					To:   Position{Index: 8, Line: 0, Col: 8},
+				},
+			},
+		},
+		{
+			name:  "script attributes are constant",
+			input: ` x-data="{ open }"`,
+			expected: ConstantAttribute{
+				Name:  "x-data",
+				Value: "{ open }",
+				NameRange: Range{
+					From: Position{Index: 1, Line: 0, Col: 1},
+					To:   Position{Index: 7, Line: 0, Col: 7},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			actual, ok, err := attribute.Parse(parse.NewInput(tt.input))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !ok {
+				t.Fatalf("failed to parse %q", tt.input)
+			}
+			if diff := cmp.Diff(tt.expected, actual); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package parser
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+// ElementConfig configures the elements that are parsed, formatted and
+// generated differently to other elements, in addition to the built-in HTML
+// elements.
+type ElementConfig struct {
+	// RawTextElements are the names of elements whose contents are text that
+	// isn't parsed, like <script> and <style>, e.g. custom elements that
+	// contain another template language.
+	RawTextElements []string
+	// VoidElements are the names of elements that don't have children or a
+	// close tag, like <br>.
+	VoidElements []string
+}
+
+type elementSets struct {
+	rawText map[string]struct{}
+	void    map[string]struct{}
+}
+
+var configuredElements atomic.Pointer[elementSets]
+
+// ConfigureElements sets the elements that are parsed as raw text or void
+// elements, in addition to the built-in HTML elements. The configuration
+// applies to all templates parsed by the program, so it should be set before
+// any templates are parsed, e.g. at startup.
+func ConfigureElements(c ElementConfig) {
+	sets := &elementSets{
+		rawText: map[string]struct{}{},
+		void:    map[string]struct{}{},
+	}
+	for _, name := range c.RawTextElements {
+		if name = strings.TrimSpace(name); name != "" {
+			sets.rawText[name] = struct{}{}
+		}
+	}
+	for _, name := range c.VoidElements {
+		if name = strings.TrimSpace(name); name != "" {
+			sets.void[name] = struct{}{}
+		}
+	}
+	configuredElements.Store(sets)
+}
+
+// IsRawTextElement returns true if the contents of elements with the name
+// aren't parsed.
+func IsRawTextElement(name string) bool {
+	if name == "script" || name == "style" {
+		return true
+	}
+	return isConfiguredRawTextElement(name)
+}
+
+func isConfiguredRawTextElement(name string) bool {
+	sets := configuredElements.Load()
+	if sets == nil {
+		return false
+	}
+	_, ok := sets.rawText[name]
+	return ok
+}
+
+// IsVoidElement returns true if elements with the name don't have children or
+// a close tag.
+//
+// https://www.w3.org/TR/2011/WD-html-markup-20110113/syntax.html#void-element
+func IsVoidElement(name string) bool {
+	if _, ok := voidElements[name]; ok {
+		return true
+	}
+	sets := configuredElements.Load()
+	if sets == nil {
+		return false
+	}
+	_, ok := sets.void[name]
+	return ok
+}
+
+// mathMLElements are the MathML elements that can be used within a <math>
+// element.
+//
+// https://w3c.github.io/mathml-core/#mathml-elements-and-attributes
+var mathMLElements = map[string]struct{}{
+	"math": {}, "annotation": {}, "annotation-xml": {}, "maction": {}, "menclose": {}, "merror": {},
+	"mfenced": {}, "mfrac": {}, "mi": {}, "mmultiscripts": {}, "mn": {}, "mo": {}, "mover": {},
+	"mpadded": {}, "mphantom": {}, "mprescripts": {}, "mroot": {}, "mrow": {}, "ms": {}, "mspace": {},
+	"msqrt": {}, "mstyle": {}, "msub": {}, "msubsup": {}, "msup": {}, "mtable": {}, "mtd": {},
+	"mtext": {}, "mtr": {}, "munder": {}, "munderover": {}, "none": {}, "semantics": {},
+}
+
+// IsMathMLElement returns true if elements with the name are MathML elements,
+// e.g. <math> or <mrow>. Whitespace between the elements within a <math>
+// element is ignored by browsers, so it isn't rendered.
+func IsMathMLElement(name string) bool {
+	_, ok := mathMLElements[name]
+	return ok
+}
@@ -0,0 +1,96 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestConfigureElements(t *testing.T) {
+	ConfigureElements(ElementConfig{
+		RawTextElements: []string{"x-template", " "},
+		VoidElements:    []string{"x-icon"},
+	})
+	defer ConfigureElements(ElementConfig{})
+
+	tf, err := ParseString(`package main
+
+templ page() {
+	<x-template id="row"><p>{{ name }}</p></x-template>
+	<x-icon name="home">
+	<p>Home</p>
+}
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var nodes []Node
+	for _, n := range tf.Nodes[0].(HTMLTemplate).Children {
+		if _, isWhitespace := n.(Whitespace); !isWhitespace {
+			nodes = append(nodes, n)
+		}
+	}
+	if len(nodes) != 3 {
+		t.Fatalf("expected 3 nodes, got %d: %#v", len(nodes), nodes)
+	}
+	raw, ok := nodes[0].(RawElement)
+	if !ok {
+		t.Fatalf("expected a raw element, got %T", nodes[0])
+	}
+	if diff := cmp.Diff("<p>{{ name }}</p>", raw.Contents); diff != "" {
+		t.Error(diff)
+	}
+	icon, ok := nodes[1].(Element)
+	if !ok || icon.Name != "x-icon" || len(icon.Children) != 0 {
+		t.Errorf("expected a void x-icon element, got %#v", nodes[1])
+	}
+	if p, ok := nodes[2].(Element); !ok || p.Name != "p" {
+		t.Errorf("expected the paragraph to be a sibling of x-icon, got %#v", nodes[2])
+	}
+
+	var sb strings.Builder
+	if err = tf.Write(&sb); err != nil {
+		t.Fatalf("failed to format: %v", err)
+	}
+	for _, expected := range []string{
+		`<x-template id="row"><p>{{ name }}</p></x-template>`,
+		`<x-icon name="home"/>`,
+	} {
+		if !strings.Contains(sb.String(), expected) {
+			t.Errorf("expected %q in formatted template:\n%s", expected, sb.String())
+		}
+	}
+
+	t.Run("the built-in elements are unchanged", func(t *testing.T) {
+		for _, name := range []string{"script", "style", "x-template"} {
+			if !IsRawTextElement(name) {
+				t.Errorf("expected %s to be a raw text element", name)
+			}
+		}
+		if IsRawTextElement("") {
+			t.Errorf("expected empty names to be ignored")
+		}
+		for _, name := range []string{"br", "x-icon"} {
+			if !IsVoidElement(name) {
+				t.Errorf("expected %s to be a void element", name)
+			}
+		}
+		if IsVoidElement("div") {
+			t.Errorf("expected div not to be a void element")
+		}
+	})
+}
+
+func TestIsMathMLElement(t *testing.T) {
+	for _, name := range []string{"math", "mrow", "mi", "annotation-xml"} {
+		if !IsMathMLElement(name) {
+			t.Errorf("expected <%s> to be a MathML element", name)
+		}
+	}
+	for _, name := range []string{"div", "svg", "m"} {
+		if IsMathMLElement(name) {
+			t.Errorf("expected <%s> not to be a MathML element", name)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/a-h/parse"
+)
+
+// parseEscapableRawTextExpressions parses the string expressions within the
+// contents of a textarea or title element, e.g. { title } | Site. Other
+// braces can't be written as text, in the same way as within other elements.
+// The contents start at index start of the input.
+func parseEscapableRawTextExpressions(pi *parse.Input, start int, contents string) (exprs []ScriptExpression, err error) {
+	end := pi.Index()
+	defer pi.Seek(end)
+
+	for i := 0; i < len(contents); i++ {
+		if !strings.HasPrefix(contents[i:], "{") {
+			continue
+		}
+		pi.Seek(start + i + len("{"))
+		_, _, _ = parse.OptionalWhitespace.Parse(pi)
+		e := ScriptExpression{Start: i}
+		if e.Expression, err = parseGoSliceArgs(pi); err != nil {
+			return nil, err
+		}
+		_, _, _ = parse.OptionalWhitespace.Parse(pi)
+		if _, ok, _ := closeBraceWithOptionalPadding.Parse(pi); !ok {
+			return nil, parse.Error("string expression: missing close brace", pi.Position())
+		}
+		e.End = pi.Index() - start
+		if e.End > len(contents) {
+			return nil, parse.Error("string expression: missing close brace", pi.PositionAt(start+len(contents)))
+		}
+		exprs = append(exprs, e)
+		i = e.End - 1
+	}
+	return exprs, nil
+}
@@ -7,9 +7,13 @@ import (
 
 var forExpression parse.Parser[Node] = forExpressionParser{}
 
-type forExpressionParser struct{}
+var untilElseOrEnd = parse.Any(StripType(elseExpression), StripType(closeBraceWithOptionalPadding))
 
-func (forExpressionParser) Parse(pi *parse.Input) (n Node, ok bool, err error) {
+type forExpressionParser struct {
+	branches branchContext
+}
+
+func (p forExpressionParser) Parse(pi *parse.Input) (n Node, ok bool, err error) {
 	var r ForExpression
 	start := pi.Index()
 
@@ -17,6 +21,9 @@ func (forExpressionParser) Parse(pi *parse.Input) (n Node, ok bool, err error) {
 	if _, _, err = parse.OptionalWhitespace.Parse(pi); err != nil {
 		return r, false, err
 	}
+	// The for statement may be labelled, e.g. `outer: for `.
+	var hasLabel bool
+	r.Label, hasLabel = parseLabel(pi, "for ")
 	if !peekPrefix(pi, "for ") {
 		pi.Seek(start)
 		return r, false, nil
@@ -24,17 +31,27 @@ func (forExpressionParser) Parse(pi *parse.Input) (n Node, ok bool, err error) {
 
 	// Parse the Go for expression.
 	if r.Expression, err = parseGo("for", pi, goexpression.For); err != nil {
+		if hasLabel {
+			// Text such as "Note: for example" is not a labelled for statement.
+			pi.Seek(start)
+			return r, false, nil
+		}
 		return r, false, err
 	}
 
 	// Eat " {\n".
 	if _, ok, err = parse.All(openBraceWithOptionalPadding, parse.NewLine).Parse(pi); err != nil || !ok {
+		if hasLabel {
+			pi.Seek(start)
+			return r, false, nil
+		}
 		err = parse.Error("for: "+unterminatedMissingCurly, pi.PositionAt(start))
 		return
 	}
 
 	// Node contents.
-	tnp := newTemplateNodeParser(closeBraceWithOptionalPadding, "for expression closing brace")
+	tnp := newTemplateNodeParser(untilElseOrEnd, "else expression or for expression closing brace")
+	tnp.branches = branchContext{inFor: true}
 	var nodes Nodes
 	if nodes, ok, err = tnp.Parse(pi); err != nil || !ok {
 		err = parse.Error("for: expected nodes, but none were found", pi.Position())
@@ -42,6 +59,14 @@ func (forExpressionParser) Parse(pi *parse.Input) (n Node, ok bool, err error) {
 	}
 	r.Children = nodes.Nodes
 
+	// Read the optional 'Else' Nodes, which are rendered if the loop body
+	// isn't, so they're outside of the loop.
+	var elseNodes Nodes
+	if elseNodes, _, err = (elseExpressionParser{branches: p.branches}).Parse(pi); err != nil {
+		return
+	}
+	r.Else = elseNodes.Nodes
+
 	// Read the required closing brace.
 	if _, ok, err = closeBraceWithOptionalPadding.Parse(pi); err != nil || !ok {
 		err = parse.Error("for: "+unterminatedMissingEnd, pi.Position())
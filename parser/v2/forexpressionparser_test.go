@@ -119,6 +119,82 @@ func TestForExpressionParser(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "for: labelled",
+			input: `outer: for _, item := range p.Items {
+	{ item }
+}`,
+			expected: ForExpression{
+				Label: Expression{
+					Value: "outer",
+					Range: Range{
+						From: Position{Index: 0, Line: 0, Col: 0},
+						To:   Position{Index: 5, Line: 0, Col: 5},
+					},
+				},
+				Expression: Expression{
+					Value: `_, item := range p.Items`,
+					Range: Range{
+						From: Position{Index: 11, Line: 0, Col: 11},
+						To:   Position{Index: 35, Line: 0, Col: 35},
+					},
+				},
+				Children: []Node{
+					Whitespace{Value: "\t"},
+					StringExpression{
+						Expression: Expression{
+							Value: `item`,
+							Range: Range{
+								From: Position{Index: 41, Line: 1, Col: 3},
+								To:   Position{Index: 45, Line: 1, Col: 7},
+							},
+						},
+						TrailingSpace: SpaceVertical,
+					},
+				},
+			},
+		},
+		{
+			name: "for: else",
+			input: `for _, item := range p.Items {
+	{ item }
+} else {
+	<p>None</p>
+}`,
+			expected: ForExpression{
+				Expression: Expression{
+					Value: `_, item := range p.Items`,
+					Range: Range{
+						From: Position{Index: 4, Line: 0, Col: 4},
+						To:   Position{Index: 28, Line: 0, Col: 28},
+					},
+				},
+				Children: []Node{
+					Whitespace{Value: "\t"},
+					StringExpression{
+						Expression: Expression{
+							Value: `item`,
+							Range: Range{
+								From: Position{Index: 34, Line: 1, Col: 3},
+								To:   Position{Index: 38, Line: 1, Col: 7},
+							},
+						},
+						TrailingSpace: SpaceVertical,
+					},
+				},
+				Else: []Node{
+					Element{
+						Name: "p",
+						NameRange: Range{
+							From: Position{Index: 52, Line: 3, Col: 2},
+							To:   Position{Index: 53, Line: 3, Col: 3},
+						},
+						Children:      []Node{Text{Value: "None"}},
+						TrailingSpace: SpaceVertical,
+					},
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -146,6 +222,19 @@ func TestIncompleteFor(t *testing.T) {
 			t.Fatalf("unexpected error: %v", err)
 		}
 	})
+	t.Run("text that looks like a label", func(t *testing.T) {
+		input := parse.NewInput(`Note: for example`)
+		_, ok, err := forExpression.Parse(input)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ok {
+			t.Fatal("expected a non match")
+		}
+		if input.Index() != 0 {
+			t.Fatalf("expected the input to be rewound, got index %d", input.Index())
+		}
+	})
 	t.Run("capitalised For", func(t *testing.T) {
 		input := parse.NewInput(`For with no brace`)
 		_, ok, err := forExpression.Parse(input)
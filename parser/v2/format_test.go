@@ -2,6 +2,7 @@ package parser
 
 import (
 	"bytes"
+	"encoding/json"
 	"path/filepath"
 	"testing"
 
@@ -20,6 +21,14 @@ func TestFormatting(t *testing.T) {
 			if err != nil {
 				t.Fatal(err)
 			}
+			// An optional "options" file contains the format options as JSON.
+			var opts FormatOptions
+			if len(a.Files) == 3 && a.Files[0].Name == "options" {
+				if err := json.Unmarshal(a.Files[0].Data, &opts); err != nil {
+					t.Fatalf("failed to parse options: %v", err)
+				}
+				a.Files = a.Files[1:]
+			}
 			if len(a.Files) != 2 {
 				t.Fatalf("expected 2 files, got %d", len(a.Files))
 			}
@@ -28,7 +37,7 @@ func TestFormatting(t *testing.T) {
 				t.Fatal(err)
 			}
 			var actual bytes.Buffer
-			if err := tem.Write(&actual); err != nil {
+			if err := tem.WriteWithOptions(&actual, opts); err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
 			if diff := cmp.Diff(string(a.Files[1].Data), actual.String()); diff != "" {
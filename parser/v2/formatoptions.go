@@ -0,0 +1,356 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"os/exec"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// FormatOptions configures the output of the formatter.
+//
+// The zero value formats templates using the default rules.
+type FormatOptions struct {
+	// MaxLineWidth is the maximum width of an element's open tag, before its
+	// attributes are placed on separate lines. Tabs are counted as TabWidth
+	// columns.
+	//
+	// If zero, attributes are placed on separate lines only if they were on
+	// separate lines in the source.
+	MaxLineWidth int `json:"maxLineWidth,omitempty"`
+	// MaxBlankLines is the maximum number of consecutive blank lines between
+	// nodes within a template, and between declarations, that are preserved.
+	//
+	// If zero, blank lines within templates are removed, and declarations are
+	// separated by a single blank line.
+	MaxBlankLines int `json:"maxBlankLines,omitempty"`
+	// SortAttributes sets the order of element attributes. Conditional and
+	// spread attributes are not moved, and attributes are not moved past them.
+	//
+	// If empty, attributes are kept in source order.
+	SortAttributes AttributeOrder `json:"sortAttributes,omitempty"`
+	// SortTailwindClasses sorts the classes of constant class attributes into
+	// the order used by prettier-plugin-tailwindcss with the default Tailwind
+	// config. Classes that aren't Tailwind utilities are placed first.
+	SortTailwindClasses bool `json:"sortTailwindClasses,omitempty"`
+	// InlineShortElements writes elements on a single line if they fit within
+	// MaxLineWidth, or 80 columns if MaxLineWidth is zero, e.g. <li>{ item }</li>.
+	// Elements that contain block elements, or statements such as if and for,
+	// are not written on a single line.
+	InlineShortElements bool `json:"inlineShortElements,omitempty"`
+	// GoFormatter is the command used to format Go code outside templates,
+	// and within {{ }} blocks, e.g. gofumpt. The command must read a Go source
+	// file from stdin, and write the formatted file to stdout. Arguments are
+	// separated by spaces.
+	//
+	// If empty, Go code is formatted with go/format. Go expressions within
+	// elements, attributes and statements are always formatted with go/format.
+	GoFormatter string `json:"goFormatter,omitempty"`
+	// QuoteStyle sets the quotes used around constant attribute values,
+	// including values that are unquoted in the source.
+	//
+	// If empty, double quotes are used, unless the value contains double
+	// quotes.
+	QuoteStyle QuoteStyle `json:"quoteStyle,omitempty"`
+
+	// goStatements holds the {{ }} blocks of the file being formatted, which
+	// have already been formatted with the GoFormatter.
+	goStatements *goStatements
+}
+
+// QuoteStyle is the style of quotes used around constant attribute values.
+type QuoteStyle string
+
+const (
+	// QuoteStyleDouble uses double quotes, unless the value contains double
+	// quotes.
+	QuoteStyleDouble QuoteStyle = "double"
+	// QuoteStyleSingle uses single quotes, unless the value contains single
+	// quotes.
+	QuoteStyleSingle QuoteStyle = "single"
+	// QuoteStylePreserve keeps the quotes used in the source.
+	QuoteStylePreserve QuoteStyle = "preserve"
+)
+
+// ParseQuoteStyle parses a quote style name.
+func ParseQuoteStyle(s string) (QuoteStyle, error) {
+	switch qs := QuoteStyle(s); qs {
+	case "", QuoteStyleDouble, QuoteStyleSingle, QuoteStylePreserve:
+		return qs, nil
+	}
+	return "", fmt.Errorf("unknown quote style %q, expected %q, %q or %q", s, QuoteStyleDouble, QuoteStyleSingle, QuoteStylePreserve)
+}
+
+// AttributeOrder is the order that the formatter sorts attributes in.
+type AttributeOrder string
+
+const (
+	// AttributeOrderSource keeps attributes in source order.
+	AttributeOrderSource AttributeOrder = ""
+	// AttributeOrderAlphabetical sorts attributes by name.
+	AttributeOrderAlphabetical AttributeOrder = "alphabetical"
+	// AttributeOrderGrouped sorts attributes into groups: id, class, other
+	// attributes, data-*, aria-*, and event handlers (on*, and the @, x-on:
+	// and v-on: directives of Alpine.js and Vue.js). Attributes within each
+	// group are kept in source order.
+	AttributeOrderGrouped AttributeOrder = "grouped"
+)
+
+// ParseAttributeOrder parses an attribute order name.
+func ParseAttributeOrder(s string) (AttributeOrder, error) {
+	switch o := AttributeOrder(s); o {
+	case AttributeOrderSource, AttributeOrderAlphabetical, AttributeOrderGrouped:
+		return o, nil
+	}
+	return "", fmt.Errorf("unknown attribute order %q, expected %q or %q", s, AttributeOrderAlphabetical, AttributeOrderGrouped)
+}
+
+// defaultMaxLineWidth is the line width used by InlineShortElements when
+// MaxLineWidth is not set.
+const defaultMaxLineWidth = 80
+
+// tabWidth is the number of columns a tab is counted as when measuring line width.
+const tabWidth = 4
+
+// formattedWriter is implemented by nodes and attributes whose output depends
+// on the format options, or that contain nodes that do. Their Write methods
+// use the default options.
+type formattedWriter interface {
+	writeFormatted(w io.Writer, indent int, opts FormatOptions) error
+}
+
+// writeFormatted writes the node or attribute to w, using the given options.
+func writeFormatted(n interface {
+	Write(w io.Writer, indent int) error
+}, w io.Writer, indent int, opts FormatOptions) error {
+	if fw, ok := n.(formattedWriter); ok {
+		return fw.writeFormatted(w, indent, opts)
+	}
+	return n.Write(w, indent)
+}
+
+// sortAttributes returns the attributes in the given order. Conditional and
+// spread attributes are left in place, since the order of attributes either
+// side of them can change the output.
+func sortAttributes(attrs []Attribute, order AttributeOrder) []Attribute {
+	if order == AttributeOrderSource || len(attrs) < 2 {
+		return attrs
+	}
+	sorted := slices.Clone(attrs)
+	var start int
+	for i := 0; i <= len(sorted); i++ {
+		if i < len(sorted) {
+			if _, ok := attributeName(sorted[i]); ok {
+				continue
+			}
+		}
+		// Sort the run of named attributes before the barrier.
+		run := sorted[start:i]
+		slices.SortStableFunc(run, func(a, b Attribute) int {
+			an, _ := attributeName(a)
+			bn, _ := attributeName(b)
+			if order == AttributeOrderGrouped {
+				return attributeGroup(an) - attributeGroup(bn)
+			}
+			return strings.Compare(strings.ToLower(an), strings.ToLower(bn))
+		})
+		start = i + 1
+	}
+	return sorted
+}
+
+func attributeName(attr Attribute) (name string, ok bool) {
+	switch attr := attr.(type) {
+	case BoolConstantAttribute:
+		return attr.Name, true
+	case ConstantAttribute:
+		return attr.Name, true
+	case BoolExpressionAttribute:
+		return attr.Name, true
+	case ExpressionAttribute:
+		return attr.Name, true
+	case InterpolatedAttribute:
+		return attr.Name, true
+	}
+	return "", false
+}
+
+func attributeGroup(name string) int {
+	name = strings.ToLower(name)
+	switch {
+	case name == "id":
+		return 0
+	case name == "class":
+		return 1
+	case strings.HasPrefix(name, "data-"):
+		return 3
+	case strings.HasPrefix(name, "aria-"):
+		return 4
+	case strings.HasPrefix(name, "on"), strings.HasPrefix(name, "@"), strings.HasPrefix(name, "x-on:"), strings.HasPrefix(name, "v-on:"):
+		return 5
+	}
+	return 2
+}
+
+// sortClassAttributes returns the attributes with the values of constant
+// class attributes sorted into Tailwind order.
+func sortClassAttributes(attrs []Attribute) []Attribute {
+	sorted := slices.Clone(attrs)
+	for i, attr := range sorted {
+		if ca, ok := attr.(ConstantAttribute); ok && strings.EqualFold(ca.Name, "class") {
+			ca.Value = sortTailwindClasses(ca.Value)
+			sorted[i] = ca
+		}
+	}
+	return sorted
+}
+
+// formatGoFile formats a Go source file with the configured Go formatter.
+func (opts FormatOptions) formatGoFile(src []byte) ([]byte, error) {
+	args := strings.Fields(opts.GoFormatter)
+	if len(args) == 0 {
+		return format.Source(src)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(src)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s: %w: %s", opts.GoFormatter, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+const (
+	goFormatterPackage = "package p\n\n"
+	goFormatterFunc    = "templ_7745c5c3_goStatements"
+)
+
+// formatGoDeclarations formats Go code that appears outside templates.
+func (opts FormatOptions) formatGoDeclarations(src string) ([]byte, error) {
+	if opts.GoFormatter == "" {
+		return format.Source([]byte(src))
+	}
+	formatted, err := opts.formatGoFile([]byte(goFormatterPackage + src))
+	if err != nil {
+		return nil, err
+	}
+	body := strings.TrimPrefix(string(formatted), goFormatterPackage)
+	return []byte(withSurroundingSpace(src, body)), nil
+}
+
+// goStatements maps the source of {{ }} blocks to their formatted function
+// bodies.
+type goStatements struct {
+	bodies map[string]string
+}
+
+// withGoStatements returns the options with the {{ }} blocks within the
+// nodes already formatted, so that the GoFormatter command is run once,
+// rather than once per block. If the blocks can't be formatted together,
+// e.g. because one of them is invalid, they're formatted individually, so
+// that the error refers to the invalid block.
+func (opts FormatOptions) withGoStatements(nodes []TemplateFileNode) FormatOptions {
+	if opts.GoFormatter == "" {
+		return opts
+	}
+	var stmts []string
+	seen := map[string]bool{}
+	for _, n := range nodes {
+		t, ok := n.(HTMLTemplate)
+		if !ok {
+			continue
+		}
+		walkNodes(t.Children, func(n Node) bool {
+			if gc, ok := n.(GoCode); ok && gc.Multiline {
+				if stmt := strings.TrimSpace(gc.Expression.Value); !seen[stmt] {
+					seen[stmt] = true
+					stmts = append(stmts, stmt)
+				}
+			}
+			return true
+		})
+	}
+	if len(stmts) == 0 {
+		return opts
+	}
+	bodies, err := opts.formatGoFunctionBodies(stmts)
+	if err != nil {
+		return opts
+	}
+	opts.goStatements = &goStatements{bodies: make(map[string]string, len(stmts))}
+	for i, stmt := range stmts {
+		opts.goStatements.bodies[stmt] = bodies[i]
+	}
+	return opts
+}
+
+// formatGoFunctionBodies formats each of the statements as the body of a
+// function, using a single run of the GoFormatter.
+func (opts FormatOptions) formatGoFunctionBodies(stmts []string) (bodies []string, err error) {
+	var src strings.Builder
+	src.WriteString(goFormatterPackage)
+	for i, stmt := range stmts {
+		fmt.Fprintf(&src, "func %s%d() {\n%s\n}\n\n", goFormatterFunc, i, stmt)
+	}
+	formatted, err := opts.formatGoFile([]byte(src.String()))
+	if err != nil {
+		return nil, err
+	}
+	rest := string(formatted)
+	for i := range stmts {
+		var ok bool
+		if _, rest, ok = strings.Cut(rest, fmt.Sprintf("func %s%d() {\n", goFormatterFunc, i)); !ok {
+			return nil, fmt.Errorf("%s: formatted output is missing function %d", opts.GoFormatter, i)
+		}
+		var body string
+		if body, rest, ok = strings.Cut("\n"+rest, "\n}\n"); !ok {
+			return nil, fmt.Errorf("%s: formatted output is missing the end of function %d", opts.GoFormatter, i)
+		}
+		bodies = append(bodies, body)
+	}
+	return bodies, nil
+}
+
+// formatGoStatements formats the Go statements within a {{ }} block.
+func (opts FormatOptions) formatGoStatements(src string) ([]byte, error) {
+	if opts.GoFormatter == "" {
+		return format.Source([]byte(src))
+	}
+	stmt := strings.TrimSpace(src)
+	body, ok := "", false
+	if opts.goStatements != nil {
+		body, ok = opts.goStatements.bodies[stmt]
+	}
+	if !ok {
+		bodies, err := opts.formatGoFunctionBodies([]string{stmt})
+		if err != nil {
+			return nil, err
+		}
+		body = bodies[0]
+	}
+	// Replace the indentation of the function body with the indentation of
+	// the source, as go/format does.
+	trimmed := strings.TrimLeft(src, "\r\n")
+	indent := trimmed[:len(trimmed)-len(strings.TrimLeft(trimmed, " \t"))]
+	lines := strings.Split(body, "\n")
+	for i, l := range lines {
+		if l != "" {
+			lines[i] = indent + strings.TrimPrefix(l, "\t")
+		}
+	}
+	return []byte(withSurroundingSpace(src, strings.Join(lines, "\n"))), nil
+}
+
+// withSurroundingSpace returns s, with the leading and trailing whitespace of
+// the original source, since the formatter only formats the code within it.
+func withSurroundingSpace(original, s string) string {
+	leading := original[:len(original)-len(strings.TrimLeftFunc(original, unicode.IsSpace))]
+	trailing := original[len(strings.TrimRightFunc(original, unicode.IsSpace)):]
+	return leading + strings.TrimSpace(s) + trailing
+}
@@ -0,0 +1,116 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ/cfg"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFormatGoStatements(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     FormatOptions
+		input    string
+		expected string
+	}{
+		{
+			name:     "go/format is used by default",
+			input:    "\n\tfor i, _ := range items {\n\t\t_ = i\n\t}",
+			expected: "\n\tfor i, _ := range items {\n\t\t_ = i\n\t}",
+		},
+		{
+			name:     "the Go formatter command is used if set",
+			opts:     FormatOptions{GoFormatter: "gofmt -s"},
+			input:    "\n\tfor i, _ := range items {\n\t\t_ = i\n\t}",
+			expected: "\n\tfor i := range items {\n\t\t_ = i\n\t}",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := tt.opts.formatGoStatements(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, string(actual)); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestFormatGoFileCommandErrors(t *testing.T) {
+	opts := FormatOptions{GoFormatter: "gofmt"}
+	if _, err := opts.formatGoFile([]byte("package p\n\nfunc {")); err == nil {
+		t.Error("expected an error for invalid Go code, got nil")
+	}
+}
+
+func TestGoFormatterRunsOncePerFile(t *testing.T) {
+	flagVal := cfg.Experiment.RawGo
+	cfg.Experiment.RawGo = true
+	defer func() {
+		cfg.Experiment.RawGo = flagVal
+	}()
+
+	dir := t.TempDir()
+	log := filepath.Join(dir, "runs.log")
+	script := filepath.Join(dir, "gofmt.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho run >> "+log+"\nexec gofmt -s\n"), 0o755); err != nil {
+		t.Fatalf("failed to write formatter script: %v", err)
+	}
+	tf, err := ParseString(`package test
+
+templ list(items []string) {
+	{{
+	for i, _ := range items {
+		_ = i
+	}
+	}}
+	<ul>
+		{{
+			x := []int{ 1 }
+		}}
+		<li>{ items[0] }</li>
+	</ul>
+}
+`)
+	if err != nil {
+		t.Fatalf("failed to parse template: %v", err)
+	}
+	var sb strings.Builder
+	if err := tf.WriteWithOptions(&sb, FormatOptions{GoFormatter: script}); err != nil {
+		t.Fatalf("failed to format template: %v", err)
+	}
+	expected := `package test
+
+templ list(items []string) {
+	{{
+		for i := range items {
+			_ = i
+		}
+	}}
+	<ul>
+		{{
+			x := []int{1}
+		}}
+		<li>{ items[0] }</li>
+	</ul>
+}
+`
+	if diff := cmp.Diff(expected, sb.String()); diff != "" {
+		t.Error(diff)
+	}
+	runs, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatalf("failed to read formatter log: %v", err)
+	}
+	// Both {{ }} blocks are formatted in a single run.
+	if n := strings.Count(string(runs), "run"); n != 1 {
+		t.Errorf("expected the formatter to run once, ran %d times", n)
+	}
+}
@@ -0,0 +1,57 @@
+package parser
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/a-h/parse"
+)
+
+// FormatRange formats the top-level nodes of the template file source that
+// overlap the byte range from, to. Templates, CSS templates, script templates
+// and Go code are formatted as a whole, so the formatted range may be larger
+// than the requested range. The rest of the source, including the header,
+// package declaration and whitespace between nodes, is left unchanged.
+//
+// The formatted text replaces src[start:end]. If no nodes overlap the range,
+// start and end are equal, and formatted is empty.
+func FormatRange(src string, from, to int, opts FormatOptions) (start, end int, formatted string, err error) {
+	tf, ranges, ok, err := NewTemplateFileParser("main").parseWithRanges(parse.NewInput(src))
+	if err != nil {
+		return from, from, "", err
+	}
+	if !ok {
+		return from, from, "", ErrTemplateNotFound
+	}
+	opts = opts.withGoStatements(tf.Nodes)
+	start, end = -1, -1
+	var sb strings.Builder
+	for i, n := range tf.Nodes {
+		nodeStart, nodeEnd := trimRange(src, int(ranges[i].From.Index), int(ranges[i].To.Index))
+		if nodeEnd < from || nodeStart > to {
+			continue
+		}
+		if start < 0 {
+			start = nodeStart
+		} else {
+			// Keep the whitespace between formatted nodes.
+			sb.WriteString(src[end:nodeStart])
+		}
+		if err = writeFormatted(n, &sb, 0, opts); err != nil {
+			return from, from, "", err
+		}
+		end = nodeEnd
+	}
+	if start < 0 {
+		return from, from, "", nil
+	}
+	return start, end, sb.String(), nil
+}
+
+// trimRange returns the range, excluding leading and trailing whitespace.
+func trimRange(src string, from, to int) (start, end int) {
+	s := src[from:to]
+	start = from + len(s) - len(strings.TrimLeftFunc(s, unicode.IsSpace))
+	end = from + len(strings.TrimRightFunc(s, unicode.IsSpace))
+	return max(start, from), max(end, start)
+}
@@ -0,0 +1,88 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFormatRange(t *testing.T) {
+	src := `package main
+
+templ a() {
+<div>A</div>
+}
+
+
+var   x = 1
+
+templ b() {
+<div>B</div>
+}
+`
+	tests := []struct {
+		name     string
+		from, to int
+		expected string
+	}{
+		{
+			name:     "only the template within the range is formatted",
+			from:     strings.Index(src, "B</div>"),
+			to:       strings.Index(src, "B</div>"),
+			expected: strings.Replace(src, "<div>B</div>", "\t<div>B</div>", 1),
+		},
+		{
+			name:     "go code within the range is formatted",
+			from:     strings.Index(src, "x = 1"),
+			to:       strings.Index(src, "x = 1"),
+			expected: strings.Replace(src, "var   x", "var x", 1),
+		},
+		{
+			name: "whitespace between nodes in the range is unchanged",
+			from: strings.Index(src, "A</div>"),
+			to:   strings.Index(src, "x = 1"),
+			expected: strings.Replace(
+				strings.Replace(src, "<div>A</div>", "\t<div>A</div>", 1),
+				"var   x", "var x", 1),
+		},
+		{
+			name:     "ranges outside nodes are unchanged",
+			from:     0,
+			to:       len("package main"),
+			expected: src,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, formatted, err := FormatRange(src, tt.from, tt.to, FormatOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			actual := src[:start] + formatted + src[end:]
+			if diff := cmp.Diff(tt.expected, actual); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestFormatRangeMatchesFormat(t *testing.T) {
+	src := "package main\n\ntempl a() {\n<div>A</div>\n}\n\nvar   x = 1\n\ntempl b() {\n<div>B</div>\n}\n"
+	tf, err := ParseString(src)
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	var expected strings.Builder
+	if err = tf.Write(&expected); err != nil {
+		t.Fatalf("failed to format: %v", err)
+	}
+	start, end, formatted, err := FormatRange(src, 0, len(src), FormatOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(expected.String(), src[:start]+formatted+src[end:]); diff != "" {
+		t.Error(diff)
+	}
+}
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/a-h/parse"
@@ -91,6 +92,32 @@ func TestGoCodeParser(t *testing.T) {
 				Multiline: true,
 			},
 		},
+		{
+			name: "multiple statements",
+			input: `{{ total := 0
+				for _, item := range items { total += item.Price }
+				currency := "GBP" }}`,
+			expected: GoCode{
+				Expression: Expression{
+					Value: `total := 0
+				for _, item := range items { total += item.Price }
+				currency := "GBP"`,
+					Range: Range{
+						From: Position{
+							Index: 3,
+							Line:  0,
+							Col:   3,
+						},
+						To: Position{
+							Index: 90,
+							Line:  2,
+							Col:   21,
+						},
+					},
+				},
+				Multiline: true,
+			},
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -116,3 +143,101 @@ func TestGoCodeParser(t *testing.T) {
 		})
 	}
 }
+
+func TestGoCodeFormatting(t *testing.T) {
+	flagVal := cfg.Experiment.RawGo
+	cfg.Experiment.RawGo = true
+	defer func() {
+		cfg.Experiment.RawGo = flagVal
+	}()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name: "single line blocks are unchanged",
+			input: `package main
+
+templ list() {
+	{{ a := 1; b := a + 1 }}
+}
+`,
+			expected: `package main
+
+templ list() {
+	{{ a := 1; b := a + 1 }}
+}
+`,
+		},
+		{
+			name: "multiline blocks are indented within the braces",
+			input: `package main
+
+templ list(items []Item) {
+	<ul>
+	{{
+	total := 0
+	for _, item := range items {
+	total += item.Price
+	}
+	}}
+	</ul>
+}
+`,
+			expected: `package main
+
+templ list(items []Item) {
+	<ul>
+		{{
+			total := 0
+			for _, item := range items {
+				total += item.Price
+			}
+		}}
+	</ul>
+}
+`,
+		},
+		{
+			name: "statements on the same line as the braces are moved to their own lines",
+			input: `package main
+
+templ list(items []Item) {
+	{{ total := 0
+	   for _, item := range items { total += item.Price } }}
+	<p>{ total }</p>
+}
+`,
+			expected: `package main
+
+templ list(items []Item) {
+	{{
+		total := 0
+		for _, item := range items {
+			total += item.Price
+		}
+	}}
+	<p>{ total }</p>
+}
+`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tf, err := ParseString(tt.input)
+			if err != nil {
+				t.Fatalf("failed to parse template: %v", err)
+			}
+			var sb strings.Builder
+			if err = tf.Write(&sb); err != nil {
+				t.Fatalf("failed to write template: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, sb.String()); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
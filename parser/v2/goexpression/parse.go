@@ -196,7 +196,11 @@ func SliceArgs(content string) (expr string, err error) {
 	prefix := "package main\nvar templ_args = []any{"
 	src := prefix + content + "}"
 
-	node, parseErr := parser.ParseFile(token.NewFileSet(), "", src, parser.AllErrors)
+	// The optional chaining operator, e.g. user?.Name, and the -} trim marker
+	// aren't valid Go, so mask them before parsing.
+	masked, trimMarker := maskTrimMarker(maskOptionalChaining(content))
+	maskedSrc := prefix + masked + "}"
+	node, parseErr := parser.ParseFile(token.NewFileSet(), "", maskedSrc, parser.AllErrors)
 	if node == nil {
 		return expr, parseErr
 	}
@@ -215,7 +219,7 @@ func SliceArgs(content string) (expr string, err error) {
 		if to > int(decl.Rbrace)-1 {
 			to = int(decl.Rbrace) - 1
 		}
-		betweenEndAndBrace := src[to : decl.Rbrace-1]
+		betweenEndAndBrace := maskedSrc[to : decl.Rbrace-1]
 		var hasCodeBetweenEndAndBrace bool
 		for _, r := range betweenEndAndBrace {
 			if !unicode.IsSpace(r) {
@@ -226,12 +230,124 @@ func SliceArgs(content string) (expr string, err error) {
 		if hasCodeBetweenEndAndBrace {
 			to = int(decl.Rbrace) - 1
 		}
+		// The trim marker isn't part of the expression.
+		if trimMarker >= 0 && to > len(prefix)+trimMarker {
+			to = len(prefix) + trimMarker
+		}
 		return false
 	})
 
 	return src[from:to], err
 }
 
+// maskOptionalChaining replaces the ? of each ?. operator with a space.
+func maskOptionalChaining(content string) string {
+	if !strings.Contains(content, "?.") {
+		return content
+	}
+	masked := []byte(content)
+	var s scanner.Scanner
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(content))
+	s.Init(file, masked, nil, 0)
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		offset := file.Offset(pos)
+		if tok == token.ILLEGAL && lit == "?" && strings.HasPrefix(content[offset+1:], ".") {
+			masked[offset] = ' '
+		}
+	}
+	return string(masked)
+}
+
+// maskTrimMarker replaces the - of a -} trim marker, which ends an expression
+// and trims the whitespace that follows it, with a space, and returns its
+// index, or -1 if the expression doesn't end with a trim marker.
+//
+// Since a - must be followed by an operand, a - directly before the closing
+// brace of the expression can only be a trim marker.
+func maskTrimMarker(content string) (masked string, index int) {
+	if !strings.Contains(content, "-}") {
+		return content, -1
+	}
+	var s scanner.Scanner
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(content))
+	s.Init(file, []byte(content), nil, 0)
+	var depth int
+	prevTok, prevOffset := token.ILLEGAL, -1
+	for {
+		pos, tok, _ := s.Scan()
+		offset := file.Offset(pos)
+		switch tok {
+		case token.EOF:
+			return content, -1
+		case token.LPAREN, token.LBRACK, token.LBRACE:
+			depth++
+		case token.RPAREN, token.RBRACK:
+			depth--
+		case token.RBRACE:
+			if depth == 0 {
+				if prevTok != token.SUB || prevOffset != offset-1 {
+					return content, -1
+				}
+				return content[:prevOffset] + " " + content[offset:], prevOffset
+			}
+			depth--
+		}
+		prevTok, prevOffset = tok, offset
+	}
+}
+
+// KeyValueArg is a key: value element of a list of arguments, e.g.
+// `"btn-primary": isPrimary`.
+type KeyValueArg struct {
+	Key string
+	// KeyIndex is the offset of the key within the arguments.
+	KeyIndex int
+	Value    string
+	// ValueIndex is the offset of the value within the arguments.
+	ValueIndex int
+}
+
+// KeyValueArgs returns the key: value elements of a comma separated list of
+// arguments, such as those returned by SliceArgs, in order. Keys and values
+// within nested expressions, e.g. map literals, aren't returned.
+func KeyValueArgs(content string) (args []KeyValueArg, err error) {
+	prefix := "package main\nvar templ_args = []any{"
+	src := prefix + maskOptionalChaining(content) + "}"
+	node, err := parser.ParseFile(token.NewFileSet(), "", src, parser.AllErrors)
+	if err != nil {
+		return nil, err
+	}
+	offset := func(p token.Pos) int {
+		return int(p) - 1 - len(prefix)
+	}
+	inspectFirstNode(node, func(n ast.Node) bool {
+		lit, ok := n.(*ast.CompositeLit)
+		if !ok {
+			return true
+		}
+		for _, e := range lit.Elts {
+			kv, ok := e.(*ast.KeyValueExpr)
+			if !ok {
+				continue
+			}
+			args = append(args, KeyValueArg{
+				Key:        content[offset(kv.Key.Pos()):offset(kv.Key.End())],
+				KeyIndex:   offset(kv.Key.Pos()),
+				Value:      content[offset(kv.Value.Pos()):offset(kv.Value.End())],
+				ValueIndex: offset(kv.Value.Pos()),
+			})
+		}
+		return false
+	})
+	return args, nil
+}
+
 // Func returns the Go code up to the opening brace of the function body.
 func Func(content string) (name, expr string, err error) {
 	prefix := "package main\n"
@@ -262,6 +378,166 @@ func Func(content string) (name, expr string, err error) {
 	return name, expr, err
 }
 
+var (
+	ErrDefaultNotTrailing = errors.New("parameters with default values must come after the other parameters")
+	ErrDefaultGrouped     = errors.New("parameters with default values can't share a type with other parameters, e.g. `a, b string = \"x\"`")
+	ErrDefaultUnnamed     = errors.New("parameters with default values must have a name and a type")
+	ErrDefaultVariadic    = errors.New("variadic parameters can't have default values")
+	ErrDefaultMethod      = errors.New("methods can't have parameters with default values")
+	ErrDefaultGeneric     = errors.New("functions with type parameters can't have parameters with default values")
+)
+
+// DefaultParam is a function parameter with a default value, e.g. `variant string = "primary"`.
+type DefaultParam struct {
+	Name string
+	Type string
+	// NameIndex is the offset of the name within the function declaration.
+	NameIndex int
+	Value     string
+	// ValueIndex is the offset of the value within the function declaration.
+	ValueIndex int
+}
+
+// FuncDefaults replaces the default parameter values of a function declaration,
+// e.g. the ` = "primary"` of `func Button(variant string = "primary")`, with
+// spaces so that the declaration can be parsed as Go, and returns the defaults.
+//
+// Declarations without default parameter values are returned unchanged.
+func FuncDefaults(content string) (masked string, defaults []DefaultParam, err error) {
+	type param struct {
+		offset int
+		tok    token.Token
+		lit    string
+	}
+	var s scanner.Scanner
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(content))
+	s.Init(file, []byte(content), nil, 0)
+	scan := func() (offset int, tok token.Token, lit string) {
+		for {
+			pos, tok, lit := s.Scan()
+			// Skip the semicolons inserted at the end of lines.
+			if tok == token.SEMICOLON && lit == "\n" {
+				continue
+			}
+			return file.Offset(pos), tok, lit
+		}
+	}
+	// skipGroup reads up to the token that closes the group.
+	skipGroup := func(open token.Token) bool {
+		depth := 1
+		for depth > 0 {
+			_, tok, _ := scan()
+			switch tok {
+			case token.EOF:
+				return false
+			case open:
+				depth++
+			case goTokenOpenToClose[open]:
+				depth--
+			}
+		}
+		return true
+	}
+
+	// func (r Receiver) Name[T any](
+	if _, tok, _ := scan(); tok != token.FUNC {
+		return content, nil, nil
+	}
+	_, tok, _ := scan()
+	isMethod := tok == token.LPAREN
+	if isMethod {
+		if !skipGroup(token.LPAREN) {
+			return content, nil, nil
+		}
+		_, tok, _ = scan()
+	}
+	if tok != token.IDENT {
+		return content, nil, nil
+	}
+	_, tok, _ = scan()
+	isGeneric := tok == token.LBRACK
+	if isGeneric {
+		if !skipGroup(token.LBRACK) {
+			return content, nil, nil
+		}
+		_, tok, _ = scan()
+	}
+	if tok != token.LPAREN {
+		return content, nil, nil
+	}
+
+	// Split the parameters at the commas, and note the position of each =.
+	b := []byte(content)
+	var names []param
+	assign := -1
+	depth := 1
+	ungroupedName := false
+	for depth > 0 {
+		offset, tok, lit := scan()
+		switch tok {
+		case token.EOF:
+			return content, nil, nil
+		case token.LPAREN, token.LBRACK, token.LBRACE:
+			depth++
+		case token.RPAREN, token.RBRACK, token.RBRACE:
+			depth--
+		}
+		if depth > 1 || (depth == 1 && tok != token.COMMA && tok != token.ASSIGN) {
+			if assign < 0 && len(names) < 2 {
+				names = append(names, param{offset: offset, tok: tok, lit: lit})
+			}
+			continue
+		}
+		if tok == token.ASSIGN {
+			assign = offset
+			continue
+		}
+		// End of a parameter.
+		if assign < 0 {
+			if len(defaults) > 0 && len(names) > 0 {
+				return content, nil, ErrDefaultNotTrailing
+			}
+			ungroupedName = len(names) == 1 && names[0].tok == token.IDENT
+			names, assign = nil, -1
+			continue
+		}
+		if isMethod {
+			return content, nil, ErrDefaultMethod
+		}
+		if isGeneric {
+			return content, nil, ErrDefaultGeneric
+		}
+		if ungroupedName {
+			return content, nil, ErrDefaultGrouped
+		}
+		if len(names) < 2 || names[0].tok != token.IDENT {
+			return content, nil, ErrDefaultUnnamed
+		}
+		if names[1].tok == token.ELLIPSIS {
+			return content, nil, ErrDefaultVariadic
+		}
+		value := strings.TrimSpace(content[assign+1 : offset])
+		if value == "" {
+			return content, nil, ErrDefaultUnnamed
+		}
+		defaults = append(defaults, DefaultParam{
+			Name:       names[0].lit,
+			Type:       strings.TrimSpace(content[names[1].offset:assign]),
+			NameIndex:  names[0].offset,
+			Value:      value,
+			ValueIndex: assign + 1 + strings.Index(content[assign+1:offset], value),
+		})
+		for i := assign; i < offset; i++ {
+			if b[i] != '\n' {
+				b[i] = ' '
+			}
+		}
+		names, assign = nil, -1
+	}
+	return string(b), defaults, nil
+}
+
 func latestEnd(start int, nodes ...ast.Node) (end int) {
 	end = start
 	for _, n := range nodes {
@@ -20,6 +20,18 @@ var ifTests = []testInput{
 		name:  "compound",
 		input: "x := val(); x > 3",
 	},
+	{
+		name:  "map lookup",
+		input: "v, ok := m[k]; ok",
+	},
+	{
+		name:  "type assertion",
+		input: "s, ok := v.(fmt.Stringer); ok && s.String() != \"\"",
+	},
+	{
+		name:  "composite literal key",
+		input: "v, ok := m[key{a: 1}]; ok",
+	},
 	{
 		name:  "if multiple",
 		input: `x && y && (!z)`,
@@ -92,6 +104,26 @@ var forTests = []testInput{
 		name:  "channel receive",
 		input: `x := range channel`,
 	},
+	{
+		name:  "range over int",
+		input: `i := range 10`,
+	},
+	{
+		name:  "range over int without variables",
+		input: `range len(items)`,
+	},
+	{
+		name:  "range over func",
+		input: `v := range seq`,
+	},
+	{
+		name:  "range over func with key and value",
+		input: `k, v := range maps.All(m)`,
+	},
+	{
+		name:  "range over func literal",
+		input: `v := range func(yield func(int) bool) { yield(1) }`,
+	},
 }
 
 func TestFor(t *testing.T) {
@@ -592,6 +624,10 @@ var sliceArgsTests = []testInput{
 		name:  "package name, but no variable or function",
 		input: `fmt.`,
 	},
+	{
+		name:  "optional chaining",
+		input: `user?.Profile?.Name`,
+	},
 }
 
 func TestSliceArgs(t *testing.T) {
@@ -615,6 +651,84 @@ func TestSliceArgs(t *testing.T) {
 	}
 }
 
+func TestSliceArgsTrimMarker(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: `x -}`, expected: `x`},
+		{input: `x-}`, expected: `x`},
+		{input: ` -}`, expected: ` `},
+		{input: `f(func() { return -1 }) -}`, expected: `f(func() { return -1 })`},
+		{input: `a - b }`, expected: `a - b`},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.input, func(t *testing.T) {
+			expr, err := SliceArgs(test.input + "</a>")
+			if err != nil {
+				t.Fatalf("failed to parse slice args: %v", err)
+			}
+			if diff := cmp.Diff(test.expected, expr); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestKeyValueArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []KeyValueArg
+	}{
+		{
+			name:     "no key value pairs",
+			input:    `"btn", templ.KV("active", isActive)`,
+			expected: nil,
+		},
+		{
+			name:  "key value pairs",
+			input: `"btn", "btn-primary": isPrimary, "disabled": !enabled`,
+			expected: []KeyValueArg{
+				{Key: `"btn-primary"`, KeyIndex: 7, Value: `isPrimary`, ValueIndex: 22},
+				{Key: `"disabled"`, KeyIndex: 33, Value: `!enabled`, ValueIndex: 45},
+			},
+		},
+		{
+			name:     "nested key value pairs are ignored",
+			input:    `map[string]bool{"btn": true}`,
+			expected: nil,
+		},
+		{
+			name:  "multiline",
+			input: "\"btn\",\n\"active\": p.Active(),\n",
+			expected: []KeyValueArg{
+				{Key: `"active"`, KeyIndex: 7, Value: `p.Active()`, ValueIndex: 17},
+			},
+		},
+		{
+			name:  "optional chaining",
+			input: `"admin": user?.IsAdmin`,
+			expected: []KeyValueArg{
+				{Key: `"admin"`, KeyIndex: 0, Value: `user?.IsAdmin`, ValueIndex: 9},
+			},
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := KeyValueArgs(test.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(test.expected, actual); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
 func FuzzSliceArgs(f *testing.F) {
 	suffixes := []string{
 		"",
@@ -735,6 +849,96 @@ func TestFunc(t *testing.T) {
 	}
 }
 
+func TestFuncDefaults(t *testing.T) {
+	tests := []struct {
+		name             string
+		input            string
+		expectedMasked   string
+		expectedDefaults []DefaultParam
+		expectedErr      error
+	}{
+		{
+			name:           "no defaults",
+			input:          `func myfunc(a string, b int) {`,
+			expectedMasked: `func myfunc(a string, b int) {`,
+		},
+		{
+			name:           "single default",
+			input:          `func Button(label string, variant string = "primary") {`,
+			expectedMasked: `func Button(label string, variant string            ) {`,
+			expectedDefaults: []DefaultParam{
+				{Name: "variant", Type: "string", NameIndex: 26, Value: `"primary"`, ValueIndex: 43},
+			},
+		},
+		{
+			name: "multiline defaults with composite values",
+			input: `func List(
+	items []string = []string{"a", "b"},
+	sep string = ", ",
+) {`,
+			expectedMasked: `func List(
+	items []string                     ,
+	sep string       ,
+) {`,
+			expectedDefaults: []DefaultParam{
+				{Name: "items", Type: "[]string", NameIndex: 12, Value: `[]string{"a", "b"}`, ValueIndex: 29},
+				{Name: "sep", Type: "string", NameIndex: 50, Value: `", "`, ValueIndex: 63},
+			},
+		},
+		{
+			name:        "defaults must be trailing",
+			input:       `func Button(variant string = "primary", label string) {`,
+			expectedErr: ErrDefaultNotTrailing,
+		},
+		{
+			name:        "grouped parameters can't have defaults",
+			input:       `func Button(a, b string = "x") {`,
+			expectedErr: ErrDefaultGrouped,
+		},
+		{
+			name:        "variadic parameters can't have defaults",
+			input:       `func Button(a ...string = nil) {`,
+			expectedErr: ErrDefaultVariadic,
+		},
+		{
+			name:        "methods can't have defaults",
+			input:       `func (p Page) Button(a string = "x") {`,
+			expectedErr: ErrDefaultMethod,
+		},
+		{
+			name:        "generic functions can't have defaults",
+			input:       `func List[T any](sep string = ",") {`,
+			expectedErr: ErrDefaultGeneric,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			masked, defaults, err := FuncDefaults(tt.input)
+			if err != tt.expectedErr {
+				t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tt.expectedMasked, masked); diff != "" {
+				t.Error(diff)
+			}
+			if diff := cmp.Diff(tt.expectedDefaults, defaults); diff != "" {
+				t.Error(diff)
+			}
+			for _, d := range defaults {
+				if got := tt.input[d.NameIndex : d.NameIndex+len(d.Name)]; got != d.Name {
+					t.Errorf("expected name at index %d, got %q", d.NameIndex, got)
+				}
+				if got := tt.input[d.ValueIndex : d.ValueIndex+len(d.Value)]; got != d.Value {
+					t.Errorf("expected value at index %d, got %q", d.ValueIndex, got)
+				}
+			}
+		})
+	}
+}
+
 type testInput struct {
 	name        string
 	input       string
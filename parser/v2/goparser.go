@@ -22,6 +22,37 @@ func parseGoFuncDecl(prefix string, pi *parse.Input) (name string, expression Ex
 	return name, NewExpression(expr, pi.PositionAt(from+len(prefix)), to), nil
 }
 
+// parseTemplComponentDecl parses the declaration of a templ component, which,
+// unlike other Go function declarations, can have default parameter values.
+func parseTemplComponentDecl(pi *parse.Input) (expression Expression, defaults []DefaultParameter, err error) {
+	prefix := "templ "
+	from := pi.Index()
+	src, _ := pi.Peek(-1)
+	src = strings.TrimPrefix(src, prefix)
+	masked, params, err := goexpression.FuncDefaults("func " + src)
+	if err != nil {
+		return expression, nil, parse.Error("invalid templ declaration: "+err.Error(), pi.Position())
+	}
+	_, expr, err := goexpression.Func(masked)
+	if err != nil {
+		return expression, nil, parse.Error("invalid templ declaration: "+err.Error(), pi.Position())
+	}
+	// The masked declaration is the same length as the source.
+	expr = src[:len(expr)]
+	// Convert indices within "func " + src to indices within the input.
+	index := func(i int) int { return from + len(prefix) + i - len("func ") }
+	for _, p := range params {
+		defaults = append(defaults, DefaultParameter{
+			Name:  NewExpression(p.Name, pi.PositionAt(index(p.NameIndex)), pi.PositionAt(index(p.NameIndex+len(p.Name)))),
+			Type:  p.Type,
+			Value: NewExpression(p.Value, pi.PositionAt(index(p.ValueIndex)), pi.PositionAt(index(p.ValueIndex+len(p.Value)))),
+		})
+	}
+	pi.Take(len(prefix) + len(expr))
+	to := pi.Position()
+	return NewExpression(expr, pi.PositionAt(from+len(prefix)), to), defaults, nil
+}
+
 func parseTemplFuncDecl(pi *parse.Input) (name string, expression Expression, err error) {
 	return parseGoFuncDecl("templ", pi)
 }
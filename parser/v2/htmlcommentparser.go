@@ -1,12 +1,18 @@
 package parser
 
 import (
+	"strings"
+
 	"github.com/a-h/parse"
 )
 
 var htmlCommentStart = parse.String("<!--")
 var htmlCommentEnd = parse.String("--")
 
+var conditionalCommentStart = parse.String("[if ")
+var conditionalCommentConditionEnd = parse.String("]>")
+var conditionalCommentEnd = parse.String("<![endif]-->")
+
 type htmlCommentParser struct {
 }
 
@@ -20,6 +26,11 @@ func (p htmlCommentParser) Parse(pi *parse.Input) (n Node, ok bool, err error) {
 		return
 	}
 
+	// Conditional comments contain HTML that's only shown by Outlook.
+	if c.Contents, ok, err = parseConditionalComment(pi, start); err != nil || ok {
+		return c, ok, err
+	}
+
 	// Once we've got the comment start sequence, parse anything until the end
 	// sequence as the comment contents.
 	if c.Contents, ok, err = parse.StringUntil(htmlCommentEnd).Parse(pi); err != nil || !ok {
@@ -37,3 +48,35 @@ func (p htmlCommentParser) Parse(pi *parse.Input) (n Node, ok bool, err error) {
 
 	return c, true, nil
 }
+
+// parseConditionalComment parses the contents of a conditional comment, e.g.
+// <!--[if mso]><table><tr><td><![endif]-->, which HTML email uses to render
+// HTML in Outlook. The HTML is passed through as is, and may contain --, e.g.
+// in CSS custom properties.
+//
+// Downlevel-revealed conditional comments, e.g. <!--[if !mso]><!-->, close
+// the comment straight away, so that other clients show the HTML after it, so
+// they're parsed as ordinary comments.
+func parseConditionalComment(pi *parse.Input, start parse.Position) (contents string, ok bool, err error) {
+	from := pi.Index()
+	if _, ok, err = conditionalCommentStart.Parse(pi); err != nil || !ok {
+		return
+	}
+	condition, ok, err := parse.StringUntil(conditionalCommentConditionEnd).Parse(pi)
+	if err != nil || !ok || strings.Contains(condition, "--") {
+		pi.Seek(from)
+		return "", false, err
+	}
+	_, _, _ = conditionalCommentConditionEnd.Parse(pi)
+	if next, _ := pi.Peek(2); next == "<!" {
+		pi.Seek(from)
+		return "", false, nil
+	}
+	html, ok, err := parse.StringUntil(conditionalCommentEnd).Parse(pi)
+	if err != nil || !ok {
+		err = parse.Error("expected end of conditional comment '<![endif]-->' not found", start)
+		return "", false, err
+	}
+	_, _, _ = conditionalCommentEnd.Parse(pi)
+	return "[if " + condition + "]>" + html + "<![endif]", true, nil
+}
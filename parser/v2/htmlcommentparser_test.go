@@ -52,6 +52,31 @@ func TestHTMLCommentParser(t *testing.T) {
 				Contents: ` <div> hello world </div> `,
 			},
 		},
+		{
+			name: "comment - conditional",
+			input: `<!--[if mso]>
+	<table role="presentation"><tr><td>
+<![endif]-->`,
+			expected: HTMLComment{
+				Contents: `[if mso]>
+	<table role="presentation"><tr><td>
+<![endif]`,
+			},
+		},
+		{
+			name:  "comment - conditional comments can contain --",
+			input: `<!--[if gte mso 9]><style>td { --gap: 1px; }</style><![endif]-->`,
+			expected: HTMLComment{
+				Contents: `[if gte mso 9]><style>td { --gap: 1px; }</style><![endif]`,
+			},
+		},
+		{
+			name:  "comment - downlevel-revealed conditional",
+			input: `<!--[if !mso]><!--><p>Not Outlook</p><!--<![endif]-->`,
+			expected: HTMLComment{
+				Contents: `[if !mso]><!`,
+			},
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -87,6 +112,16 @@ func TestHTMLCommentParserErrors(t *testing.T) {
 					Col:   0,
 				}),
 		},
+		{
+			name:  "unclosed conditional comment",
+			input: `<!--[if mso]><table> -->`,
+			expected: parse.Error("expected end of conditional comment '<![endif]-->' not found",
+				parse.Position{
+					Index: 0,
+					Line:  0,
+					Col:   0,
+				}),
+		},
 		{
 			name:  "comment in comment",
 			input: `<!-- <-- other --> -->`,
@@ -9,9 +9,11 @@ var ifExpression ifExpressionParser
 
 var untilElseIfElseOrEnd = parse.Any(StripType(elseIfExpression), StripType(elseExpression), StripType(closeBraceWithOptionalPadding))
 
-type ifExpressionParser struct{}
+type ifExpressionParser struct {
+	branches branchContext
+}
 
-func (ifExpressionParser) Parse(pi *parse.Input) (n Node, ok bool, err error) {
+func (p ifExpressionParser) Parse(pi *parse.Input) (n Node, ok bool, err error) {
 	var r IfExpression
 	start := pi.Index()
 
@@ -35,6 +37,7 @@ func (ifExpressionParser) Parse(pi *parse.Input) (n Node, ok bool, err error) {
 	// Read the 'Then' nodes.
 	// If there's no match, there's a problem in the template nodes.
 	np := newTemplateNodeParser(untilElseIfElseOrEnd, "else expression or closing brace")
+	np.branches = p.branches
 	var thenNodes Nodes
 	if thenNodes, ok, err = np.Parse(pi); err != nil || !ok {
 		err = parse.Error("if: expected nodes, but none were found", pi.Position())
@@ -43,13 +46,13 @@ func (ifExpressionParser) Parse(pi *parse.Input) (n Node, ok bool, err error) {
 	r.Then = thenNodes.Nodes
 
 	// Read the optional 'ElseIf' Nodes.
-	if r.ElseIfs, _, err = parse.ZeroOrMore(elseIfExpression).Parse(pi); err != nil {
+	if r.ElseIfs, _, err = parse.ZeroOrMore[ElseIfExpression](elseIfExpressionParser{branches: p.branches}).Parse(pi); err != nil {
 		return
 	}
 
 	// Read the optional 'Else' Nodes.
 	var elseNodes Nodes
-	if elseNodes, _, err = elseExpression.Parse(pi); err != nil {
+	if elseNodes, _, err = (elseExpressionParser{branches: p.branches}).Parse(pi); err != nil {
 		return
 	}
 	r.Else = elseNodes.Nodes
@@ -65,9 +68,11 @@ func (ifExpressionParser) Parse(pi *parse.Input) (n Node, ok bool, err error) {
 
 var elseIfExpression parse.Parser[ElseIfExpression] = elseIfExpressionParser{}
 
-type elseIfExpressionParser struct{}
+type elseIfExpressionParser struct {
+	branches branchContext
+}
 
-func (elseIfExpressionParser) Parse(pi *parse.Input) (r ElseIfExpression, ok bool, err error) {
+func (p elseIfExpressionParser) Parse(pi *parse.Input) (r ElseIfExpression, ok bool, err error) {
 	start := pi.Index()
 
 	// Check the prefix first.
@@ -94,6 +99,7 @@ func (elseIfExpressionParser) Parse(pi *parse.Input) (r ElseIfExpression, ok boo
 	// Read the 'Then' nodes.
 	// If there's no match, there's a problem in the template nodes.
 	np := newTemplateNodeParser(untilElseIfElseOrEnd, "else expression or closing brace")
+	np.branches = p.branches
 	var thenNodes Nodes
 	if thenNodes, ok, err = np.Parse(pi); err != nil || !ok {
 		err = parse.Error("if: expected nodes, but none were found", pi.Position())
@@ -114,9 +120,11 @@ var endElseParser = parse.All(
 
 var elseExpression parse.Parser[Nodes] = elseExpressionParser{}
 
-type elseExpressionParser struct{}
+type elseExpressionParser struct {
+	branches branchContext
+}
 
-func (elseExpressionParser) Parse(in *parse.Input) (r Nodes, ok bool, err error) {
+func (p elseExpressionParser) Parse(in *parse.Input) (r Nodes, ok bool, err error) {
 	start := in.Index()
 
 	// } else {
@@ -126,7 +134,9 @@ func (elseExpressionParser) Parse(in *parse.Input) (r Nodes, ok bool, err error)
 	}
 
 	// Else contents
-	if r, ok, err = newTemplateNodeParser(closeBraceWithOptionalPadding, "else expression closing brace").Parse(in); err != nil || !ok {
+	np := newTemplateNodeParser(closeBraceWithOptionalPadding, "else expression closing brace")
+	np.branches = p.branches
+	if r, ok, err = np.Parse(in); err != nil || !ok {
 		in.Seek(start)
 		return
 	}
@@ -0,0 +1,52 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/a-h/parse"
+)
+
+var includeExpression parse.Parser[Node] = includeExpressionParser{}
+
+type includeExpressionParser struct{}
+
+var includeExpressionStart = parse.StringFrom(parse.String("@include"), parse.StringFrom(parse.OneOrMore(parse.String(" "))))
+
+func (includeExpressionParser) Parse(pi *parse.Input) (n Node, ok bool, err error) {
+	var r IncludeExpression
+	start := pi.Index()
+
+	// Check the prefix first.
+	if _, ok, err = includeExpressionStart.Parse(pi); err != nil || !ok {
+		pi.Seek(start)
+		return r, false, err
+	}
+
+	// Read the quoted path, e.g. "partials/footer.templ".
+	from := pi.Index()
+	var path string
+	if path, ok, err = stringUntilNewLineOrEOF.Parse(pi); err != nil || !ok {
+		err = parse.Error("include: missing path", pi.PositionAt(start))
+		return r, false, err
+	}
+	path = strings.TrimRight(path, " \t")
+	if _, err = strconv.Unquote(path); err != nil {
+		err = parse.Error("include: expected a quoted path, e.g. @include \"partials/footer.templ\"", pi.PositionAt(from))
+		return r, false, err
+	}
+	r.Path = NewExpression(path, pi.PositionAt(from), pi.PositionAt(from+len(path)))
+
+	return r, true, nil
+}
+
+// ParseNodes parses a fragment of template content, such as a partial
+// included with @include, into nodes.
+func ParseNodes(s string) ([]Node, error) {
+	tnp := newTemplateNodeParser(parse.EOF[string](), "end of file")
+	nodes, _, err := tnp.Parse(parse.NewInput(s))
+	if err != nil {
+		return nil, err
+	}
+	return nodes.Nodes, nil
+}
@@ -0,0 +1,64 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/a-h/parse"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestIncludeExpressionParser(t *testing.T) {
+	input := parse.NewInput(`@include "partials/footer.templ"  ` + "\n")
+	actual, ok, err := includeExpression.Parse(input)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !ok {
+		t.Fatal("unexpected failure")
+	}
+	expected := IncludeExpression{
+		Path: Expression{
+			Value: `"partials/footer.templ"`,
+			Range: Range{
+				From: Position{Index: 9, Line: 0, Col: 9},
+				To:   Position{Index: 32, Line: 0, Col: 32},
+			},
+		},
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestIncludeExpressionParserErrors(t *testing.T) {
+	t.Run("component calls are not matched", func(t *testing.T) {
+		input := parse.NewInput(`@includeFooter()`)
+		_, ok, err := includeExpression.Parse(input)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ok {
+			t.Fatal("expected a non match")
+		}
+	})
+	t.Run("unquoted paths are an error", func(t *testing.T) {
+		input := parse.NewInput(`@include partials/footer.templ`)
+		_, _, err := includeExpression.Parse(input)
+		if err == nil {
+			t.Fatal("expected an error, got nil")
+		}
+	})
+}
+
+func TestParseNodes(t *testing.T) {
+	nodes, err := ParseNodes("<footer>{ name }</footer>\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(nodes) != 1 {
+		t.Fatalf("expected 1 node, got %d: %#v", len(nodes), nodes)
+	}
+	if _, ok := nodes[0].(Element); !ok {
+		t.Errorf("expected an element, got %T", nodes[0])
+	}
+}
@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"go/token"
+	"strings"
+
+	"github.com/a-h/parse"
+)
+
+// inlineTemplate parses a template that's declared within the body of
+// another template, e.g. templ row(item Item) { ... }.
+var inlineTemplate parse.Parser[Node] = inlineTemplateParser{}
+
+type inlineTemplateParser struct{}
+
+// isInlineTemplateStart returns true if the input starts with templ, a name,
+// and the parameters of a template, so that text such as "templ is great"
+// isn't parsed as a template.
+func isInlineTemplateStart(pi *parse.Input) bool {
+	start := pi.Index()
+	defer pi.Seek(start)
+	if _, ok, _ := parse.String("templ ").Parse(pi); !ok {
+		return false
+	}
+	name, ok, err := parse.StringUntil(parse.RuneNotIn(goIdentifierRunes)).Parse(pi)
+	if err != nil || !ok || !token.IsIdentifier(name) {
+		return false
+	}
+	return peekPrefix(pi, "(", "[")
+}
+
+func (inlineTemplateParser) Parse(pi *parse.Input) (n Node, ok bool, err error) {
+	if !isInlineTemplateStart(pi) {
+		return nil, false, nil
+	}
+	var r InlineTemplate
+	start := pi.Position()
+
+	// templ row(item Item) {
+	var name string
+	if name, r.Expression, err = parseTemplFuncDecl(pi); err != nil {
+		return r, false, err
+	}
+	// Go function literals can't have type parameters.
+	if strings.HasPrefix(strings.TrimPrefix(r.Expression.Value, name), "[") {
+		err = parse.Error("inline templ: type parameters aren't supported", start)
+		return r, false, err
+	}
+	if _, ok, err = parse.All(openBraceWithOptionalPadding, parse.NewLine).Parse(pi); err != nil || !ok {
+		err = parse.Error("inline templ: "+unterminatedMissingCurly, pi.Position())
+		return r, false, err
+	}
+
+	// Node contents.
+	tnp := newTemplateNodeParser(closeBraceWithOptionalPadding, "inline templ closing brace")
+	var nodes Nodes
+	if nodes, ok, err = tnp.Parse(pi); err != nil || !ok {
+		err = parse.Error("inline templ: expected nodes, but none were found", pi.Position())
+		return r, false, err
+	}
+	r.Children = nodes.Nodes
+
+	// Read the required closing brace.
+	if _, ok, err = closeBraceWithOptionalPadding.Parse(pi); err != nil || !ok {
+		err = parse.Error("inline templ: "+unterminatedMissingEnd, pi.Position())
+		return r, false, err
+	}
+
+	return r, true, nil
+}
@@ -0,0 +1,117 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/a-h/parse"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestInlineTemplateParser(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected InlineTemplate
+	}{
+		{
+			name: "inline templ: simple",
+			input: `templ row(item Item) {
+	<td>{ item.Name }</td>
+}`,
+			expected: InlineTemplate{
+				Expression: Expression{
+					Value: "row(item Item)",
+					Range: Range{
+						From: Position{Index: 6, Line: 0, Col: 6},
+						To:   Position{Index: 20, Line: 0, Col: 20},
+					},
+				},
+				Children: []Node{
+					Whitespace{Value: "\t"},
+					Element{
+						Name: "td",
+						NameRange: Range{
+							From: Position{Index: 25, Line: 1, Col: 2},
+							To:   Position{Index: 27, Line: 1, Col: 4},
+						},
+						Children: []Node{
+							StringExpression{
+								Expression: Expression{
+									Value: "item.Name",
+									Range: Range{
+										From: Position{Index: 30, Line: 1, Col: 7},
+										To:   Position{Index: 39, Line: 1, Col: 16},
+									},
+								},
+							},
+						},
+						TrailingSpace: SpaceVertical,
+					},
+				},
+			},
+		},
+		{
+			name: "inline templ: without parameters or spaces",
+			input: `templ empty(){
+}`,
+			expected: InlineTemplate{
+				Expression: Expression{
+					Value: "empty()",
+					Range: Range{
+						From: Position{Index: 6, Line: 0, Col: 6},
+						To:   Position{Index: 13, Line: 0, Col: 13},
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			input := parse.NewInput(tt.input)
+			actual, ok, err := inlineTemplate.Parse(input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !ok {
+				t.Fatalf("unexpected failure for input %q", tt.input)
+			}
+			if diff := cmp.Diff(tt.expected, actual); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestIncompleteInlineTemplate(t *testing.T) {
+	for _, text := range []string{"templ is great", "templ (x X) Method() {", "templ"} {
+		t.Run("text is not matched: "+text, func(t *testing.T) {
+			input := parse.NewInput(text)
+			_, ok, err := inlineTemplate.Parse(input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ok {
+				t.Fatal("expected a non match")
+			}
+			if input.Index() != 0 {
+				t.Errorf("expected the input not to be consumed, stopped at %d", input.Index())
+			}
+		})
+	}
+	t.Run("type parameters", func(t *testing.T) {
+		input := parse.NewInput("templ list[T any](items []T) {\n}")
+		_, _, err := inlineTemplate.Parse(input)
+		expected := parse.Error("inline templ: type parameters aren't supported", parse.Position{Index: 0, Line: 0, Col: 0})
+		if diff := cmp.Diff(expected, err); diff != "" {
+			t.Error(diff)
+		}
+	})
+	t.Run("missing closing brace", func(t *testing.T) {
+		input := parse.NewInput("templ row() {\n<div></div>")
+		_, _, err := inlineTemplate.Parse(input)
+		if err == nil {
+			t.Fatal("expected an error, got nil")
+		}
+	})
+}
@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/a-h/parse"
+)
+
+// keyframes Name() {
+//
+//	from {
+//	  opacity: 0;
+//	}
+//
+// }
+var keyframesParser = parse.Func(func(pi *parse.Input) (r KeyframesTemplate, ok bool, err error) {
+	start := pi.Index()
+
+	if !peekPrefix(pi, "keyframes ") {
+		return r, false, nil
+	}
+
+	// Once we have the prefix, everything to the brace is Go.
+	// e.g.
+	// keyframes fadeIn() {
+	// becomes:
+	// func fadeIn() templ.CSSKeyframes {
+	if r.Name, r.Expression, err = parseGoFuncDecl("keyframes", pi); err != nil {
+		return r, false, err
+	}
+
+	// Eat " {\n".
+	if _, ok, err = parse.All(openBraceWithOptionalPadding, parse.NewLine).Parse(pi); err != nil || !ok {
+		err = parse.Error("keyframes expression: parameters missing open bracket", pi.PositionAt(start))
+		return
+	}
+
+	r.Keyframes = []CSSRule{}
+	for {
+		// Eat any whitespace.
+		if _, _, err = parse.OptionalWhitespace.Parse(pi); err != nil {
+			return
+		}
+
+		// Try for }
+		if _, ok, _ = closeBrace.Parse(pi); ok {
+			return r, true, nil
+		}
+
+		// from {
+		var kf CSSRule
+		selectorStart := pi.Position()
+		if kf.Selector, err = parseCSSSelector("keyframes", pi); err != nil {
+			return r, false, err
+		}
+		if !keyframeSelectorRegexp.MatchString(kf.Selector) {
+			return r, false, parse.Error(fmt.Sprintf("keyframes: invalid keyframe selector %q, expected from, to or a percentage", kf.Selector), selectorStart)
+		}
+		if kf.Properties, err = parseCSSProperties(pi); err != nil {
+			return r, false, err
+		}
+		if slices.ContainsFunc(kf.Properties, func(p CSSProperty) bool {
+			_, isRule := p.(CSSRule)
+			return isRule
+		}) {
+			return r, false, parse.Error("keyframes: keyframes can't contain nested rules", selectorStart)
+		}
+		r.Keyframes = append(r.Keyframes, kf)
+	}
+})
+
+// keyframeSelectorRegexp matches keyframe selectors, e.g. from, 50% or 0%, 100%.
+var keyframeSelectorRegexp = regexp.MustCompile(`^(from|to|\d+(\.\d+)?%)(\s*,\s*(from|to|\d+(\.\d+)?%))*$`)
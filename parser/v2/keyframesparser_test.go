@@ -0,0 +1,174 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/a-h/parse"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestKeyframesParser(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected KeyframesTemplate
+	}{
+		{
+			name: "keyframes: from and to",
+			input: `keyframes fadeIn() {
+	from {
+		opacity: 0;
+	}
+	to {
+		opacity: 1;
+	}
+}`,
+			expected: KeyframesTemplate{
+				Name: "fadeIn",
+				Expression: Expression{
+					Value: "fadeIn()",
+					Range: Range{
+						From: Position{
+							Index: 10,
+							Line:  0,
+							Col:   10,
+						},
+						To: Position{
+							Index: 18,
+							Line:  0,
+							Col:   18,
+						},
+					},
+				},
+				Keyframes: []CSSRule{
+					{
+						Selector: "from",
+						Properties: []CSSProperty{
+							ConstantCSSProperty{
+								Name:  "opacity",
+								Value: "0",
+							},
+						},
+					},
+					{
+						Selector: "to",
+						Properties: []CSSProperty{
+							ConstantCSSProperty{
+								Name:  "opacity",
+								Value: "1",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "keyframes: percentages",
+			input: `keyframes pulse() {
+	0%, 100% {
+		opacity: 1;
+	}
+	50.5% {
+		opacity: 0.5;
+	}
+}`,
+			expected: KeyframesTemplate{
+				Name: "pulse",
+				Expression: Expression{
+					Value: "pulse()",
+					Range: Range{
+						From: Position{
+							Index: 10,
+							Line:  0,
+							Col:   10,
+						},
+						To: Position{
+							Index: 17,
+							Line:  0,
+							Col:   17,
+						},
+					},
+				},
+				Keyframes: []CSSRule{
+					{
+						Selector: "0%, 100%",
+						Properties: []CSSProperty{
+							ConstantCSSProperty{
+								Name:  "opacity",
+								Value: "1",
+							},
+						},
+					},
+					{
+						Selector: "50.5%",
+						Properties: []CSSProperty{
+							ConstantCSSProperty{
+								Name:  "opacity",
+								Value: "0.5",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			input := parse.NewInput(tt.input)
+			result, ok, err := keyframesParser.Parse(input)
+			if err != nil {
+				t.Fatalf("parser error: %v", err)
+			}
+			if !ok {
+				t.Fatalf("failed to parse at %d", input.Index())
+			}
+			if diff := cmp.Diff(tt.expected, result); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestKeyframesParserErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name: "selectors must be from, to or percentages",
+			input: `keyframes fadeIn() {
+	.a {
+		opacity: 0;
+	}
+}`,
+		},
+		{
+			name: "keyframes can't contain nested rules",
+			input: `keyframes fadeIn() {
+	from {
+		&:hover {
+			opacity: 0;
+		}
+	}
+}`,
+		},
+		{
+			name: "keyframes must be closed",
+			input: `keyframes fadeIn() {
+	from {
+		opacity: 0;
+	}
+`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := keyframesParser.Parse(parse.NewInput(tt.input))
+			if err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/a-h/parse"
+)
+
+// markdownBlock parses a block of Markdown, e.g. @markdown { # Title }. The
+// contents aren't parsed, so they can contain braces and Go code examples.
+var markdownBlock parse.Parser[Node] = markdownBlockParser{}
+
+type markdownBlockParser struct{}
+
+var markdownBlockStart = parse.All(parse.String("@markdown"), openBraceWithOptionalPadding, parse.NewLine)
+
+func (markdownBlockParser) Parse(pi *parse.Input) (n Node, ok bool, err error) {
+	var r MarkdownBlock
+	start := pi.Index()
+	if _, ok, err = markdownBlockStart.Parse(pi); err != nil || !ok {
+		pi.Seek(start)
+		return r, false, err
+	}
+
+	// The block ends at the first line that only contains a closing brace, and
+	// is indented no further than @markdown, so that Markdown code examples
+	// can contain closing braces.
+	indent := int(pi.PositionAt(start).Col)
+	rest, _ := pi.Peek(-1)
+	var lines []string
+	var length int
+	for {
+		line, remainder, found := strings.Cut(rest, "\n")
+		if strings.TrimSpace(line) == "}" && len(line)-len(strings.TrimLeft(line, " \t")) <= indent {
+			pi.Take(length + len(line))
+			r.Contents = dedent(lines)
+			return r, true, nil
+		}
+		if !found {
+			break
+		}
+		lines = append(lines, strings.TrimRight(line, " \t\r"))
+		length += len(line) + 1
+		rest = remainder
+	}
+	err = parse.Error("markdown: "+unterminatedMissingEnd, pi.PositionAt(start))
+	return r, false, err
+}
+
+// dedent joins the lines, after removing the leading and trailing blank lines,
+// and the indentation that the lines have in common.
+func dedent(lines []string) string {
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	var prefix string
+	for i, line := range lines {
+		if line == "" {
+			continue
+		}
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if i == 0 {
+			prefix = indent
+			continue
+		}
+		for !strings.HasPrefix(indent, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	for i, line := range lines {
+		lines[i] = strings.TrimPrefix(line, prefix)
+	}
+	return strings.Join(lines, "\n")
+}
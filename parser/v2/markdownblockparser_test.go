@@ -0,0 +1,104 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/a-h/parse"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMarkdownBlockParser(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected MarkdownBlock
+	}{
+		{
+			name: "markdown: indentation is removed",
+			input: `@markdown {
+		# Title
+
+		Some *text*.
+}`,
+			expected: MarkdownBlock{
+				Contents: "# Title\n\nSome *text*.",
+			},
+		},
+		{
+			name: "markdown: relative indentation is kept",
+			input: `@markdown {
+	- a
+		- b
+}`,
+			expected: MarkdownBlock{
+				Contents: "- a\n\t- b",
+			},
+		},
+		{
+			name: "markdown: braces that are indented further than the block are contents",
+			input: `	@markdown {
+		` + "```go" + `
+		func main() {
+		}
+		` + "```" + `
+	}`,
+			expected: MarkdownBlock{
+				Contents: "```go\nfunc main() {\n}\n```",
+			},
+		},
+		{
+			name: "markdown: empty",
+			input: `@markdown {
+}`,
+			expected: MarkdownBlock{},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			input := parse.NewInput(tt.input)
+			if tt.input[0] == '\t' {
+				input.Take(1)
+			}
+			actual, ok, err := markdownBlock.Parse(input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !ok {
+				t.Fatalf("unexpected failure for input %q", tt.input)
+			}
+			if diff := cmp.Diff(tt.expected, actual); diff != "" {
+				t.Error(diff)
+			}
+			if _, ok := input.Peek(1); ok {
+				t.Errorf("expected the input to be consumed, stopped at %d", input.Index())
+			}
+		})
+	}
+}
+
+func TestIncompleteMarkdownBlock(t *testing.T) {
+	for _, text := range []string{"@markdown", "@markdown(src)", "@markdownPage() {\n}"} {
+		t.Run("text is not matched: "+text, func(t *testing.T) {
+			input := parse.NewInput(text)
+			_, ok, err := markdownBlock.Parse(input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ok {
+				t.Fatal("expected a non match")
+			}
+			if input.Index() != 0 {
+				t.Errorf("expected the input not to be consumed, stopped at %d", input.Index())
+			}
+		})
+	}
+	t.Run("missing closing brace", func(t *testing.T) {
+		input := parse.NewInput("@markdown {\n# Title\n")
+		_, _, err := markdownBlock.Parse(input)
+		expected := parse.Error("markdown: "+unterminatedMissingEnd, parse.Position{Index: 0, Line: 0, Col: 0})
+		if diff := cmp.Diff(expected, err); diff != "" {
+			t.Error(diff)
+		}
+	})
+}
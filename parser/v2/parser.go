@@ -16,6 +16,7 @@ var template = parse.Func(func(pi *parse.Input) (r HTMLTemplate, ok bool, err er
 		return
 	}
 	r.Expression = te.Expression
+	r.Defaults = te.Defaults
 
 	// Once we're in a template, we should expect some template whitespace, if/switch/for,
 	// or node string expressions etc.
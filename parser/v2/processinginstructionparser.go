@@ -0,0 +1,25 @@
+package parser
+
+import (
+	"github.com/a-h/parse"
+)
+
+var processingInstructionStart = parse.String("<?")
+var processingInstructionEnd = parse.String("?>")
+
+var processingInstruction = parse.Func(func(pi *parse.Input) (n Node, ok bool, err error) {
+	start := pi.Position()
+	var r ProcessingInstruction
+	if _, ok, err = processingInstructionStart.Parse(pi); err != nil || !ok {
+		return
+	}
+
+	// Once an instruction has started, take everything until the end.
+	if r.Value, ok, err = parse.StringUntil(processingInstructionEnd).Parse(pi); err != nil || !ok {
+		err = parse.Error("unclosed processing instruction, expected '?>'", start)
+		return
+	}
+	_, _, _ = processingInstructionEnd.Parse(pi)
+
+	return r, true, nil
+})
@@ -0,0 +1,56 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/a-h/parse"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestProcessingInstructionParser(t *testing.T) {
+	var tests = []struct {
+		name     string
+		input    string
+		expected ProcessingInstruction
+	}{
+		{
+			name:  "XML declaration",
+			input: `<?xml version="1.0" encoding="UTF-8"?>`,
+			expected: ProcessingInstruction{
+				Value: `xml version="1.0" encoding="UTF-8"`,
+			},
+		},
+		{
+			name:  "stylesheet",
+			input: `<?xml-stylesheet href="/feed.xsl" type="text/xsl"?>`,
+			expected: ProcessingInstruction{
+				Value: `xml-stylesheet href="/feed.xsl" type="text/xsl"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			input := parse.NewInput(tt.input)
+			result, ok, err := processingInstruction.Parse(input)
+			if err != nil {
+				t.Fatalf("parser error: %v", err)
+			}
+			if !ok {
+				t.Fatalf("failed to parse at %d", input.Index())
+			}
+			if diff := cmp.Diff(tt.expected, result); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestProcessingInstructionParserErrors(t *testing.T) {
+	input := parse.NewInput(`<?xml version="1.0"`)
+	_, _, err := processingInstruction.Parse(input)
+	expected := parse.Error("unclosed processing instruction, expected '?>'", parse.Position{Index: 0, Line: 0, Col: 0})
+	if diff := cmp.Diff(expected, err); diff != "" {
+		t.Error(diff)
+	}
+}
@@ -14,7 +14,20 @@ var scriptElement = rawElementParser{
 	name: "script",
 }
 
+var textareaElement = rawElementParser{
+	name: "textarea",
+}
+
+var titleElement = rawElementParser{
+	name: "title",
+}
+
+// configuredRawElement parses the raw text elements configured with
+// ConfigureElements.
+var configuredRawElement = rawElementParser{}
+
 type rawElementParser struct {
+	// name of the element, or empty to match configured raw text elements.
 	name string
 }
 
@@ -33,7 +46,7 @@ func (p rawElementParser) Parse(pi *parse.Input) (n Node, ok bool, err error) {
 		return
 	}
 
-	if e.Name != p.name {
+	if (p.name == "" && !isConfiguredRawTextElement(e.Name)) || (p.name != "" && e.Name != p.name) {
 		pi.Seek(start)
 		ok = false
 		return
@@ -58,13 +71,41 @@ func (p rawElementParser) Parse(pi *parse.Input) (n Node, ok bool, err error) {
 
 	// Once we've got an open tag, parse anything until the end tag as the tag contents.
 	// It's going to be rendered out raw.
-	end := parse.All(parse.String("</"), parse.String(p.name), parse.String(">"))
-	if e.Contents, ok, err = parse.StringUntil(end).Parse(pi); err != nil || !ok {
-		err = parse.Error(fmt.Sprintf("<%s>: expected end tag not present", e.Name), pi.Position())
-		return
+	end := parse.All(parse.String("</"), parse.String(e.Name), parse.String(">"))
+	contentsStart := pi.Index()
+	if e.IsJavaScript() || e.IsTypeScript() {
+		// End tags within strings and comments are part of the script, and
+		// are escaped when the script is output.
+		src, _ := pi.Peek(-1)
+		if i, ok := scriptEndTagIndex(src, "</"+e.Name+">"); ok {
+			e.Contents, _ = pi.Take(i)
+		}
+	}
+	if pi.Index() == contentsStart {
+		if e.Contents, ok, err = parse.StringUntil(end).Parse(pi); err != nil || !ok {
+			err = parse.Error(fmt.Sprintf("<%s>: expected end tag not present", e.Name), pi.Position())
+			return
+		}
 	}
 	// Cut the end element.
 	_, _, _ = end.Parse(pi)
 
+	// JavaScript template literals and CSS can contain Go expressions, JSON
+	// script elements can contain a Go expression that is encoded as JSON,
+	// and textarea and title elements can contain string expressions.
+	switch {
+	case e.IsCSS():
+		e.Expressions, err = parseStyleExpressions(pi, contentsStart, e.Contents)
+	case e.IsJavaScript():
+		e.Expressions, err = parseScriptExpressions(pi, contentsStart, e.Contents)
+	case e.IsJSON():
+		e.Expressions, err = parseJSONScriptExpression(pi, contentsStart, e.Contents)
+	case e.IsEscapableRawText():
+		e.Expressions, err = parseEscapableRawTextExpressions(pi, contentsStart, e.Contents)
+	}
+	if err != nil {
+		return e, false, err
+	}
+
 	return e, true, nil
 }
@@ -1,15 +1,17 @@
 package parser
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/a-h/parse"
 	"github.com/google/go-cmp/cmp"
 )
 
+// ignoredContent has mismatched braces. {{ starts a Go expression in CSS.
 var ignoredContent = `{
 	fjkjkl: 123,
-	{{
+	{
 }`
 
 func TestRawElementParser(t *testing.T) {
@@ -123,3 +125,310 @@ func TestRawElementParserIsNotGreedy(t *testing.T) {
 		})
 	}
 }
+
+func TestRawElementIsTypeScript(t *testing.T) {
+	tests := []struct {
+		input              string
+		expected           bool
+		expectedAttributes int
+	}{
+		{input: `<script type="text/typescript"></script>`, expected: true, expectedAttributes: 0},
+		{input: `<script lang="ts" type="module"></script>`, expected: true, expectedAttributes: 1},
+		{input: `<script type="text/javascript"></script>`, expected: false, expectedAttributes: 1},
+		{input: `<script type={ scriptType }></script>`, expected: false, expectedAttributes: 1},
+		{input: `<style lang="ts"></style>`, expected: false, expectedAttributes: 1},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.input, func(t *testing.T) {
+			actual, ok, err := rawElements.Parse(parse.NewInput(tt.input))
+			if err != nil || !ok {
+				t.Fatalf("failed to parse: %v", err)
+			}
+			e := actual.(RawElement)
+			if e.IsTypeScript() != tt.expected {
+				t.Errorf("expected IsTypeScript to return %v", tt.expected)
+			}
+			if !tt.expected {
+				return
+			}
+			if attrs := e.JavaScriptAttributes(); len(attrs) != tt.expectedAttributes {
+				t.Errorf("expected %d attributes, got %#v", tt.expectedAttributes, attrs)
+			}
+		})
+	}
+}
+
+func TestRawElementScriptExpressions(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "expressions within template literals are parsed",
+			input:    "<script>const msg = `Hello, {{ name }}! You have {{ strconv.Itoa(count) }} messages.`;</script>",
+			expected: []string{"name", "strconv.Itoa(count)"},
+		},
+		{
+			name:     "expressions within nested template literals are parsed",
+			input:    "<script>const msg = `${ items.map(i => `<li>{{ prefix }}${i}</li>`) }`;</script>",
+			expected: []string{"prefix"},
+		},
+		{
+			name:     "braces within template literals aren't expressions",
+			input:    "<script>const msg = `${ {a: 1}.a } {{ name }}`;</script>",
+			expected: []string{"name"},
+		},
+		{
+			name:  "braces outside template literals aren't expressions",
+			input: "<script>if (x) {{ y() }}; const s = \"{{ a }}\"; // `{{ b }}`\n /* `{{ c }}` */</script>",
+		},
+		{
+			name:  "backticks in regular expressions don't start template literals",
+			input: "<script>const re = /`{{ a }}/; const b = x / 2;</script>",
+		},
+		{
+			name:  "escaped braces aren't expressions",
+			input: "<script>const msg = `\\{{ a }}`;</script>",
+		},
+		{
+			name:  "non-JavaScript scripts aren't parsed",
+			input: "<script type=\"text/x-template\">`{{ name }}`</script>",
+		},
+		{
+			name:     "JSON script elements can contain an expression",
+			input:    "<script type=\"application/json\">\n\t{{ data }}\n</script>",
+			expected: []string{"data"},
+		},
+		{
+			name:     "JSON-LD script elements can contain an expression",
+			input:    "<script type=\"application/ld+json\">{{ map[string]any{\"name\": name} }}</script>",
+			expected: []string{"map[string]any{\"name\": name}"},
+		},
+		{
+			name:  "JSON script elements can contain JSON",
+			input: "<script type=\"application/json\">{\"a\": {\"b\": 1}}</script>",
+		},
+		{
+			name:     "expressions within style elements are parsed",
+			input:    "<style>a { color: {{ theme.Color }}; } a::after { content: \"{{ label }}\" }</style>",
+			expected: []string{"theme.Color", "label"},
+		},
+		{
+			name:  "braces within CSS comments aren't expressions",
+			input: "<style>/* {{ a }} */ a { color: red }</style>",
+		},
+		{
+			name:  "non-CSS style elements aren't parsed",
+			input: "<style type=\"text/x-scss\">a { color: {{ color }} }</style>",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			actual, ok, err := rawElements.Parse(parse.NewInput(tt.input))
+			if err != nil || !ok {
+				t.Fatalf("failed to parse: %v", err)
+			}
+			e := actual.(RawElement)
+			var exprs []string
+			for _, se := range e.Expressions {
+				exprs = append(exprs, se.Expression.Value)
+				r := se.Expression.Range
+				if actual := tt.input[r.From.Index:r.To.Index]; actual != se.Expression.Value {
+					t.Errorf("expected the range of %q to be the expression, got %q", se.Expression.Value, actual)
+				}
+			}
+			if diff := cmp.Diff(tt.expected, exprs); diff != "" {
+				t.Error(diff)
+			}
+			var contents string
+			for _, c := range e.SplitContents() {
+				if c.Expression != nil {
+					contents += "{{ " + c.Expression.Value + " }}"
+					continue
+				}
+				contents += c.Value
+			}
+			if !strings.Contains(tt.input, contents) {
+				t.Errorf("expected the split contents to match the input, got %q", contents)
+			}
+		})
+	}
+	for _, input := range []string{
+		"<script>`{{ name`</script>",
+		"<script type=\"application/json\">{{ a }}, {{ b }}</script>",
+		"<style>a { color: {{ color; }</style>",
+	} {
+		t.Run("error: "+input, func(t *testing.T) {
+			_, _, err := rawElements.Parse(parse.NewInput(input))
+			if err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestRawElementEscapableRawText(t *testing.T) {
+	tests := []struct {
+		name             string
+		input            string
+		expectedContents string
+		expected         []string
+	}{
+		{
+			name:             "elements within title elements are text",
+			input:            "<title>{ title } | <b>Site</b></title>",
+			expectedContents: "{ title } | <b>Site</b>",
+			expected:         []string{"title"},
+		},
+		{
+			name:             "whitespace within textarea elements is kept",
+			input:            "<textarea name=\"bio\">\n  Line one <em>\n    {bio}\n</textarea>",
+			expectedContents: "\n  Line one <em>\n    {bio}\n",
+			expected:         []string{"bio"},
+		},
+		{
+			name:             "expressions can return errors",
+			input:            "<title>{ title(), err } - { map[string]string{\"a\": \"b\"}[\"a\"] }</title>",
+			expectedContents: "{ title(), err } - { map[string]string{\"a\": \"b\"}[\"a\"] }",
+			expected:         []string{"title(), err", "map[string]string{\"a\": \"b\"}[\"a\"]"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			actual, ok, err := rawElements.Parse(parse.NewInput(tt.input))
+			if err != nil || !ok {
+				t.Fatalf("failed to parse: %v", err)
+			}
+			e := actual.(RawElement)
+			if !e.IsEscapableRawText() {
+				t.Error("expected the element to be escapable raw text")
+			}
+			if diff := cmp.Diff(tt.expectedContents, e.Contents); diff != "" {
+				t.Error(diff)
+			}
+			var exprs []string
+			for _, se := range e.Expressions {
+				exprs = append(exprs, se.Expression.Value)
+				r := se.Expression.Range
+				if actual := tt.input[r.From.Index:r.To.Index]; actual != se.Expression.Value {
+					t.Errorf("expected the range of %q to be the expression, got %q", se.Expression.Value, actual)
+				}
+			}
+			if diff := cmp.Diff(tt.expected, exprs); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+	for _, input := range []string{
+		"<title>{ title</title>",
+		"<textarea>{ value )</textarea>",
+		"<title>{ title }",
+	} {
+		t.Run("error: "+input, func(t *testing.T) {
+			_, _, err := rawElements.Parse(parse.NewInput(input))
+			if err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestRawElementStyleExpressionsInString(t *testing.T) {
+	input := "<style>a { color: {{ color }}; } a::after { content: '{{ label }}' }</style>"
+	actual, ok, err := rawElements.Parse(parse.NewInput(input))
+	if err != nil || !ok {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	var inString []bool
+	for _, se := range actual.(RawElement).Expressions {
+		inString = append(inString, se.InString)
+	}
+	if diff := cmp.Diff([]bool{false, true}, inString); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestRawElementStyleExpressionsProperty(t *testing.T) {
+	input := "<style>:root { --brand: {{ brand }}; } a { margin: 0 {{ gap }}; content: '{{ label }}' }</style>"
+	actual, ok, err := rawElements.Parse(parse.NewInput(input))
+	if err != nil || !ok {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	var properties []string
+	for _, se := range actual.(RawElement).Expressions {
+		properties = append(properties, se.Property)
+	}
+	if diff := cmp.Diff([]string{"--brand", "margin", ""}, properties); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestRawElementParserScriptEndTags(t *testing.T) {
+	tests := []struct {
+		name             string
+		input            string
+		expectedContents string
+		expectedOutput   string
+	}{
+		{
+			name:             "end tags within strings are part of the script",
+			input:            `<script>const s = "</script>"; const t = '</script>';</script>`,
+			expectedContents: `const s = "</script>"; const t = '</script>';`,
+			expectedOutput:   `const s = "<\/script>"; const t = '<\/script>';`,
+		},
+		{
+			name:             "end tags within template literals are part of the script",
+			input:            "<script>const s = `</script>${ \"</script>\" }`;</script>",
+			expectedContents: "const s = `</script>${ \"</script>\" }`;",
+			expectedOutput:   "const s = `<\\/script>${ \"<\\/script>\" }`;",
+		},
+		{
+			name:             "end tags within comments are part of the script",
+			input:            "<script>// </script>\n/* </SCRIPT> */</script>",
+			expectedContents: "// </script>\n/* </SCRIPT> */",
+			expectedOutput:   "// <\\/script>\n/* <\\/SCRIPT> */",
+		},
+		{
+			name:             "quotes within regular expressions don't start strings",
+			input:            `<script>const ok = /"/.test(s) && "</script>";</script>`,
+			expectedContents: `const ok = /"/.test(s) && "</script>";`,
+			expectedOutput:   `const ok = /"/.test(s) && "<\/script>";`,
+		},
+		{
+			name:             "unterminated template literals end at the first end tag",
+			input:            "<script>const s = `</script>",
+			expectedContents: "const s = `",
+			expectedOutput:   "const s = `",
+		},
+		{
+			name:             "end tags within non-JavaScript scripts end the element",
+			input:            `<script type="text/x-template">"</script>"</script>`,
+			expectedContents: `"`,
+			expectedOutput:   `"`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			actual, ok, err := rawElements.Parse(parse.NewInput(tt.input))
+			if err != nil || !ok {
+				t.Fatalf("failed to parse: %v", err)
+			}
+			e := actual.(RawElement)
+			if diff := cmp.Diff(tt.expectedContents, e.Contents); diff != "" {
+				t.Error(diff)
+			}
+			var output string
+			for _, c := range e.SplitContents() {
+				output += c.Value
+			}
+			if diff := cmp.Diff(tt.expectedOutput, output); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
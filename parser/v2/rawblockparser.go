@@ -0,0 +1,31 @@
+package parser
+
+import (
+	"github.com/a-h/parse"
+)
+
+// rawBlockComment parses a comment that marks the start or end of a raw
+// block, e.g. <!-- templ:raw -->.
+func rawBlockComment(directive string) parse.Parser[[]string] {
+	return parse.All(parse.String("<!--"), parse.OptionalWhitespace, parse.String(directive), parse.OptionalWhitespace, parse.String("-->"))
+}
+
+var rawBlockStart = rawBlockComment("templ:raw")
+var rawBlockEnd = rawBlockComment("templ:end")
+
+var rawBlock = parse.Func(func(pi *parse.Input) (n Node, ok bool, err error) {
+	start := pi.Position()
+	if _, ok, err = rawBlockStart.Parse(pi); err != nil || !ok {
+		return
+	}
+
+	// Take everything until the end comment, without parsing it.
+	var b RawBlock
+	if b.Contents, ok, err = parse.StringUntil(rawBlockEnd).Parse(pi); err != nil || !ok {
+		err = parse.Error("expected end of raw block '<!-- templ:end -->' not found", start)
+		return
+	}
+	_, _, _ = rawBlockEnd.Parse(pi)
+
+	return b, true, nil
+})
@@ -0,0 +1,85 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/a-h/parse"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRawBlockParser(t *testing.T) {
+	var tests = []struct {
+		name     string
+		input    string
+		expected RawBlock
+	}{
+		{
+			name:  "raw block - braces and @",
+			input: `<!-- templ:raw -->{{#each items}}@{{this}}{{/each}}<!-- templ:end -->`,
+			expected: RawBlock{
+				Contents: "{{#each items}}@{{this}}{{/each}}",
+			},
+		},
+		{
+			name: "raw block - malformed markup",
+			input: `<!--templ:raw-->
+	<div class=unclosed><p>
+<!--  templ:end  -->`,
+			expected: RawBlock{
+				Contents: "\n\t<div class=unclosed><p>\n",
+			},
+		},
+		{
+			name:  "raw block - comments",
+			input: `<!-- templ:raw --><!-- Start of snippet --><!-- templ:end -->`,
+			expected: RawBlock{
+				Contents: "<!-- Start of snippet -->",
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			input := parse.NewInput(tt.input)
+			result, ok, err := rawBlock.Parse(input)
+			if err != nil {
+				t.Fatalf("parser error: %v", err)
+			}
+			if !ok {
+				t.Fatalf("failed to parse at %d", input.Index())
+			}
+			if diff := cmp.Diff(tt.expected, result); diff != "" {
+				t.Error(diff)
+			}
+			if input.Index() != len(tt.input) {
+				t.Errorf("expected the input to be consumed, stopped at %d", input.Index())
+			}
+		})
+	}
+}
+
+func TestRawBlockParserErrors(t *testing.T) {
+	input := parse.NewInput(`<div><!-- templ:raw -->{ unclosed </div>`)
+	input.Seek(5)
+	_, _, err := rawBlock.Parse(input)
+	expected := parse.Error("expected end of raw block '<!-- templ:end -->' not found",
+		parse.Position{
+			Index: 5,
+			Line:  0,
+			Col:   5,
+		})
+	if diff := cmp.Diff(expected, err); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestRawBlockIsNotAComment(t *testing.T) {
+	input := parse.NewInput(`<!-- templ:rawness -->`)
+	_, ok, err := rawBlock.Parse(input)
+	if err != nil || ok {
+		t.Fatalf("expected the comment not to be a raw block, got ok=%v, err=%v", ok, err)
+	}
+	if input.Index() != 0 {
+		t.Errorf("expected the input not to be consumed, stopped at %d", input.Index())
+	}
+}
@@ -0,0 +1,272 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/a-h/parse"
+	"github.com/a-h/templ/parser/v2/goexpression"
+)
+
+// parseScriptExpressions parses the Go expressions within the template
+// literals of a script element's contents, e.g. `Hello, {{ name }}`. The
+// contents start at index start of the input.
+func parseScriptExpressions(pi *parse.Input, start int, contents string) (exprs []ScriptExpression, err error) {
+	end := pi.Index()
+	defer pi.Seek(end)
+
+	var s jsScanner
+	for i := 0; i < len(contents); i++ {
+		if s.state != jsTemplateLiteral || !strings.HasPrefix(contents[i:], "{{") {
+			i += s.next(contents, i)
+			continue
+		}
+		e, err := parseScriptExpression(pi, start, i, contents)
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, e)
+		i = e.End - 1
+	}
+	return exprs, nil
+}
+
+// parseJSONScriptExpression parses the Go expression that is the contents of
+// a JSON script element, e.g. <script type="application/json">{{ data }}</script>.
+// The contents start at index start of the input.
+func parseJSONScriptExpression(pi *parse.Input, start int, contents string) (exprs []ScriptExpression, err error) {
+	end := pi.Index()
+	defer pi.Seek(end)
+
+	trimmed := strings.TrimLeft(contents, " \t\r\n")
+	if !strings.HasPrefix(trimmed, "{{") {
+		return nil, nil
+	}
+	e, err := parseScriptExpression(pi, start, len(contents)-len(trimmed), contents)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(contents[e.End:]) != "" {
+		return nil, parse.Error("script expression: JSON script elements can only contain a single expression", pi.PositionAt(start+e.End))
+	}
+	return []ScriptExpression{e}, nil
+}
+
+// parseScriptExpression parses the {{ }} expression at index i of the
+// contents, which start at index start of the input.
+func parseScriptExpression(pi *parse.Input, start, i int, contents string) (e ScriptExpression, err error) {
+	pi.Seek(start + i + len("{{"))
+	_, _, _ = parse.OptionalWhitespace.Parse(pi)
+	e.Start = i
+	if e.Expression, err = parseGo("script expression", pi, goexpression.Expression); err != nil {
+		return e, err
+	}
+	if _, ok, _ := dblCloseBraceWithOptionalPadding.Parse(pi); !ok {
+		return e, parse.Error("script expression: missing close braces", pi.Position())
+	}
+	e.End = pi.Index() - start
+	if e.End > len(contents) {
+		return e, parse.Error("script expression: missing close braces", pi.PositionAt(start+len(contents)))
+	}
+	return e, nil
+}
+
+// scriptEndTagIndex returns the index of the end tag of a script element
+// whose contents are JavaScript, ignoring end tags within strings, template
+// literals, comments and regular expressions, e.g. const s = "</script>".
+func scriptEndTagIndex(src, endTag string) (index int, ok bool) {
+	var s jsScanner
+	for i := 0; i < len(src); i++ {
+		if s.state == jsCode && strings.HasPrefix(src[i:], endTag) {
+			return i, true
+		}
+		i += s.next(src, i)
+	}
+	return 0, false
+}
+
+// scriptEndTagRegexp matches the start of script end tags, which end script
+// elements wherever they are in the script, e.g. within strings.
+var scriptEndTagRegexp = regexp.MustCompile(`(?i)</script`)
+
+// escapeScriptEndTags escapes the script end tags within JavaScript, e.g.
+// "</script>" becomes "<\/script>", which is the same string.
+func escapeScriptEndTags(js string) string {
+	return scriptEndTagRegexp.ReplaceAllStringFunc(js, func(s string) string {
+		return s[:1] + `\` + s[1:]
+	})
+}
+
+type jsState int
+
+const (
+	jsCode jsState = iota
+	jsString
+	jsTemplateLiteral
+	jsLineComment
+	jsBlockComment
+	jsRegex
+)
+
+// jsToken is the type of the previous token of JavaScript code, which
+// determines whether a / starts a regular expression, or is division.
+type jsToken int
+
+const (
+	// jsTokenOperator is an operator, punctuation or keyword, e.g. =, ( or
+	// return, after which a / starts a regular expression. The start of the
+	// script is treated as an operator.
+	jsTokenOperator jsToken = iota
+	// jsTokenOperand is a value, e.g. an identifier, number, literal, ) or ],
+	// after which a / is division.
+	jsTokenOperand
+	// jsTokenDot is a . before a property name, e.g. a.return, which is
+	// never a keyword.
+	jsTokenDot
+)
+
+// jsScanner tracks whether each character of JavaScript source is code, or
+// within a string, template literal, comment or regular expression.
+type jsScanner struct {
+	state jsState
+	// prev is the type of the previous token of code.
+	prev jsToken
+	// quote that ends the current string.
+	quote byte
+	// inClass is true within a character class of a regular expression.
+	inClass bool
+	// templates has an item for each template literal that the current code
+	// is within, e.g. `${ a }`, which counts the unclosed braces within the
+	// current ${ } expression.
+	templates []int
+}
+
+// next updates the state with the character at index i of src, and returns the
+// number of additional characters that were consumed, e.g. by escape
+// sequences.
+func (s *jsScanner) next(src string, i int) (skip int) {
+	c := src[i]
+	var n byte
+	if i+1 < len(src) {
+		n = src[i+1]
+	}
+	switch s.state {
+	case jsCode:
+		return s.nextCode(src, i, c, n)
+	case jsString:
+		switch c {
+		case '\\':
+			return 1
+		case s.quote, '\n':
+			s.state, s.prev = jsCode, jsTokenOperand
+		}
+	case jsTemplateLiteral:
+		switch {
+		case c == '\\':
+			return 1
+		case c == '`':
+			s.state, s.prev = jsCode, jsTokenOperand
+		case c == '$' && n == '{':
+			s.templates = append(s.templates, 0)
+			s.state, s.prev = jsCode, jsTokenOperator
+			return 1
+		}
+	case jsLineComment:
+		if c == '\n' {
+			s.state = jsCode
+		}
+	case jsBlockComment:
+		if c == '*' && n == '/' {
+			s.state = jsCode
+			return 1
+		}
+	case jsRegex:
+		switch {
+		case c == '\\':
+			return 1
+		case c == '[':
+			s.inClass = true
+		case c == ']':
+			s.inClass = false
+		case c == '/' && !s.inClass, c == '\n':
+			s.state, s.prev = jsCode, jsTokenOperand
+		}
+	}
+	return 0
+}
+
+func (s *jsScanner) nextCode(src string, i int, c, n byte) (skip int) {
+	switch {
+	case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+		// Whitespace doesn't change the previous token.
+	case isJSWordChar(c):
+		end := i + 1
+		for end < len(src) && isJSWordChar(src[end]) {
+			end++
+		}
+		if s.prev != jsTokenDot && regexKeywords[src[i:end]] {
+			s.prev = jsTokenOperator
+		} else {
+			s.prev = jsTokenOperand
+		}
+		return end - i - 1
+	case c == '\'' || c == '"':
+		s.state, s.quote = jsString, c
+	case c == '`':
+		s.state = jsTemplateLiteral
+	case c == '/' && n == '/':
+		s.state = jsLineComment
+		return 1
+	case c == '/' && n == '*':
+		s.state = jsBlockComment
+		return 1
+	case c == '/' && s.prev != jsTokenOperand:
+		s.state, s.inClass = jsRegex, false
+	case (c == '+' || c == '-') && n == c:
+		// a++ is an operand, but ++a is an operator.
+		if s.prev != jsTokenOperand {
+			s.prev = jsTokenOperator
+		}
+		return 1
+	case c == '.' && !(n >= '0' && n <= '9'):
+		s.prev = jsTokenDot
+	case c == ')' || c == ']':
+		s.prev = jsTokenOperand
+	case c == '{':
+		s.prev = jsTokenOperator
+		if len(s.templates) > 0 {
+			s.templates[len(s.templates)-1]++
+		}
+	case c == '}':
+		// The end of a block, after which a / starts a regular expression.
+		s.prev = jsTokenOperator
+		if len(s.templates) == 0 {
+			break
+		}
+		top := len(s.templates) - 1
+		if s.templates[top] == 0 {
+			// The end of a ${ } expression.
+			s.templates = s.templates[:top]
+			s.state = jsTemplateLiteral
+			break
+		}
+		s.templates[top]--
+	default:
+		s.prev = jsTokenOperator
+	}
+	return 0
+}
+
+// isJSWordChar returns true if c is part of an identifier, keyword or number.
+// Non-ASCII characters are treated as part of identifiers.
+func isJSWordChar(c byte) bool {
+	return c == '_' || c == '$' || c >= 0x80 ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// regexKeywords are the keywords that can be followed by a regular expression.
+var regexKeywords = map[string]bool{
+	"await": true, "case": true, "delete": true, "do": true, "else": true,
+	"in": true, "instanceof": true, "new": true, "of": true, "return": true,
+	"throw": true, "typeof": true, "void": true, "yield": true,
+}
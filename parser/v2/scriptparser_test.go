@@ -0,0 +1,56 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestJSScannerRegex(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		// expected is the indexes of the / characters that start regular
+		// expressions.
+		expected []int
+	}{
+		{name: "division", input: "a / b / c"},
+		{name: "regex after assignment", input: "x = /ab/g", expected: []int{4}},
+		{name: "regex at the start", input: "/a/.test(s)", expected: []int{0}},
+		{name: "regex after return", input: "return /a/.test(s)", expected: []int{7}},
+		{name: "regex after typeof", input: "typeof /a/", expected: []int{7}},
+		{name: "division after identifiers ending in keywords", input: "main / 2; redo / 2; typeofx / 2"},
+		{name: "division after properties named like keywords", input: "obj.return / 2"},
+		{name: "division after postfix increments", input: "a++ / b; c-- / d"},
+		{name: "regex after operators", input: "x = y ? /a/ : /b/", expected: []int{8, 14}},
+		{name: "division after brackets", input: "(a) / 2; b[0] / 2"},
+		{name: "division after numbers", input: "1.5 / 2; .5 / 2"},
+		{name: "division after strings", input: `"a" / 2; 'b' / 2`},
+		{name: "division after template literals", input: "`${a}` / 2"},
+		{name: "regex after blocks", input: "if (x) {} /re/.test(s)", expected: []int{10}},
+		{name: "regex with a slash in a character class", input: "f(/[/]/, a / b)", expected: []int{2}},
+		{name: "regex with escaped slashes", input: `f(/\//, a / b)`, expected: []int{2}},
+		{name: "regex within template literal substitutions", input: "`${ /a/.source }` / 2", expected: []int{4}},
+		{name: "slashes in comments", input: "a // b / c\n/* / */ / 2"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var s jsScanner
+			var actual []int
+			for i := 0; i < len(tt.input); i++ {
+				prev := s.state
+				i += s.next(tt.input, i)
+				if prev != jsRegex && s.state == jsRegex {
+					actual = append(actual, i)
+				}
+			}
+			if diff := cmp.Diff(tt.expected, actual); diff != "" {
+				t.Error(diff)
+			}
+			if s.state != jsCode {
+				t.Errorf("expected the scanner to end in code, got state %d", s.state)
+			}
+		})
+	}
+}
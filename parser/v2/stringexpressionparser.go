@@ -5,13 +5,20 @@ import (
 )
 
 var stringExpression = parse.Func(func(pi *parse.Input) (n Node, ok bool, err error) {
+	var r StringExpression
+
 	// Check the prefix first.
-	if _, ok, err = parse.Or(parse.String("{ "), parse.String("{")).Parse(pi); err != nil || !ok {
+	// {- trims the whitespace before the expression. It must be followed by
+	// whitespace, to distinguish it from a negative number, e.g. {-1}.
+	if peekPrefix(pi, "{- ", "{-\t", "{-\n", "{-\r\n") {
+		pi.Take(len("{-"))
+		_, _, _ = parse.OptionalWhitespace.Parse(pi)
+		r.TrimBefore = true
+	} else if _, ok, err = parse.Or(parse.String("{ "), parse.String("{")).Parse(pi); err != nil || !ok {
 		return
 	}
 
 	// Once we have a prefix, we must have an expression that returns a string, with optional err.
-	var r StringExpression
 	if r.Expression, err = parseGoSliceArgs(pi); err != nil {
 		return r, false, err
 	}
@@ -19,6 +26,12 @@ var stringExpression = parse.Func(func(pi *parse.Input) (n Node, ok bool, err er
 	// Clear any optional whitespace.
 	_, _, _ = parse.OptionalWhitespace.Parse(pi)
 
+	// -} trims the whitespace after the expression.
+	if peekPrefix(pi, "-}") {
+		pi.Take(len("-"))
+		r.TrimAfter = true
+	}
+
 	// }
 	if _, ok, err = closeBraceWithOptionalPadding.Parse(pi); err != nil || !ok {
 		err = parse.Error("string expression: missing close brace", pi.Position())
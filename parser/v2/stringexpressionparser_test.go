@@ -81,6 +81,49 @@ func TestStringExpressionParser(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:  "trim markers",
+			input: `{- "this" -}`,
+			expected: StringExpression{
+				Expression: Expression{
+					Value: `"this"`,
+					Range: Range{
+						From: Position{Index: 3, Line: 0, Col: 3},
+						To:   Position{Index: 9, Line: 0, Col: 9},
+					},
+				},
+				TrimBefore: true,
+				TrimAfter:  true,
+			},
+		},
+		{
+			name:  "trim marker without an expression",
+			input: `{- -}`,
+			expected: StringExpression{
+				Expression: Expression{
+					Value: ``,
+					Range: Range{
+						From: Position{Index: 3, Line: 0, Col: 3},
+						To:   Position{Index: 3, Line: 0, Col: 3},
+					},
+				},
+				TrimBefore: true,
+				TrimAfter:  true,
+			},
+		},
+		{
+			name:  "negative numbers aren't trim markers",
+			input: `{-1}`,
+			expected: StringExpression{
+				Expression: Expression{
+					Value: `-1`,
+					Range: Range{
+						From: Position{Index: 1, Line: 0, Col: 1},
+						To:   Position{Index: 3, Line: 0, Col: 3},
+					},
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -4,6 +4,8 @@ package parser
 var (
 	// css name() { ... }
 	_ TemplateFileNode = CSSTemplate{}
+	// keyframes name() { ... }
+	_ TemplateFileNode = KeyframesTemplate{}
 	// templ name() { ... }
 	_ TemplateFileNode = HTMLTemplate{}
 	// script name() { ... }
@@ -19,16 +21,22 @@ var (
 	_ Node = RawElement{}
 	_ Node = GoComment{}
 	_ Node = HTMLComment{}
+	_ Node = CDATA{}
+	_ Node = RawBlock{}
 	_ Node = CallTemplateExpression{}
 	_ Node = TemplElementExpression{}
 	_ Node = ChildrenExpression{}
 	_ Node = IfExpression{}
 	_ Node = SwitchExpression{}
 	_ Node = ForExpression{}
+	_ Node = InlineTemplate{}
+	_ Node = BlockExpression{}
+	_ Node = MarkdownBlock{}
 	_ Node = StringExpression{}
 	_ Node = GoCode{}
 	_ Node = Whitespace{}
 	_ Node = DocType{}
+	_ Node = ProcessingInstruction{}
 )
 
 // Element nodes can have the following attributes.
@@ -37,6 +45,8 @@ var (
 	_ Attribute = ConstantAttribute{}
 	_ Attribute = BoolExpressionAttribute{}
 	_ Attribute = ExpressionAttribute{}
+	_ Attribute = InterpolatedAttribute{}
 	_ Attribute = SpreadAttributes{}
+	_ Attribute = DynamicAttribute{}
 	_ Attribute = ConditionalAttribute{}
 )
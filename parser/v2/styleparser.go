@@ -0,0 +1,109 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/a-h/parse"
+)
+
+// parseStyleExpressions parses the Go expressions within the contents of a
+// CSS style element, e.g. color: {{ theme.Color }};, ignoring {{ within
+// comments. The contents start at index start of the input.
+func parseStyleExpressions(pi *parse.Input, start int, contents string) (exprs []ScriptExpression, err error) {
+	end := pi.Index()
+	defer pi.Seek(end)
+
+	var s cssScanner
+	for i := 0; i < len(contents); i++ {
+		if s.state == cssComment || !strings.HasPrefix(contents[i:], "{{") {
+			i += s.next(contents, i)
+			continue
+		}
+		e, err := parseScriptExpression(pi, start, i, contents)
+		if err != nil {
+			return nil, err
+		}
+		e.InString = s.state == cssString
+		if !e.InString {
+			e.Property = cssDeclarationProperty(contents[s.declarationStart:i])
+		}
+		exprs = append(exprs, e)
+		i = e.End - 1
+	}
+	return exprs, nil
+}
+
+// cssDeclarationProperty returns the property of a declaration whose value is
+// being written, e.g. "--brand" for "\n  --brand: ", or an empty string if
+// the text isn't the start of a declaration.
+func cssDeclarationProperty(text string) string {
+	property, _, ok := strings.Cut(text, ":")
+	if !ok {
+		return ""
+	}
+	property = strings.TrimSpace(property)
+	if !cssDeclarationPropertyRegexp.MatchString(property) {
+		return ""
+	}
+	return property
+}
+
+var cssDeclarationPropertyRegexp = regexp.MustCompile(`^-{0,2}[_a-zA-Z][-_a-zA-Z0-9]*$`)
+
+type cssState int
+
+const (
+	cssCode cssState = iota
+	cssString
+	cssComment
+)
+
+// cssScanner tracks whether each character of CSS source is code, or within a
+// string or comment.
+type cssScanner struct {
+	state cssState
+	// quote that ends the current string.
+	quote byte
+	// declarationStart is the index after the last ;, { or } of code, where
+	// the current declaration starts.
+	declarationStart int
+}
+
+// next updates the state with the character at index i of src, and returns the
+// number of additional characters that were consumed, e.g. by escape
+// sequences.
+func (s *cssScanner) next(src string, i int) (skip int) {
+	c := src[i]
+	var n byte
+	if i+1 < len(src) {
+		n = src[i+1]
+	}
+	switch s.state {
+	case cssCode:
+		switch {
+		case c == '\\':
+			return 1
+		case c == '\'' || c == '"':
+			s.state, s.quote = cssString, c
+		case c == '/' && n == '*':
+			s.state = cssComment
+			return 1
+		case c == ';' || c == '{' || c == '}':
+			s.declarationStart = i + 1
+		}
+	case cssString:
+		switch c {
+		case '\\':
+			return 1
+		case s.quote, '\n':
+			s.state = cssCode
+		}
+	case cssComment:
+		if c == '*' && n == '/' {
+			s.state = cssCode
+			return 1
+		}
+	}
+	return 0
+}
@@ -7,13 +7,17 @@ import (
 
 var switchExpression parse.Parser[Node] = switchExpressionParser{}
 
-type switchExpressionParser struct{}
+type switchExpressionParser struct {
+	branches branchContext
+}
 
-func (switchExpressionParser) Parse(pi *parse.Input) (n Node, ok bool, err error) {
+func (p switchExpressionParser) Parse(pi *parse.Input) (n Node, ok bool, err error) {
 	var r SwitchExpression
 	start := pi.Index()
 
-	// Check the prefix first.
+	// Check the prefix first. The switch statement may be labelled, e.g. `outer: switch `.
+	var hasLabel bool
+	r.Label, hasLabel = parseLabel(pi, "switch ")
 	if !peekPrefix(pi, "switch ") {
 		pi.Seek(start)
 		return
@@ -21,11 +25,19 @@ func (switchExpressionParser) Parse(pi *parse.Input) (n Node, ok bool, err error
 
 	// Parse the Go switch expresion.
 	if r.Expression, err = parseGo("switch", pi, goexpression.Switch); err != nil {
+		if hasLabel {
+			pi.Seek(start)
+			return r, false, nil
+		}
 		return r, false, err
 	}
 
 	// Eat " {\n".
 	if _, ok, err = parse.All(openBraceWithOptionalPadding, parse.NewLine).Parse(pi); err != nil || !ok {
+		if hasLabel {
+			pi.Seek(start)
+			return r, false, nil
+		}
 		err = parse.Error("switch: "+unterminatedMissingCurly, pi.PositionAt(start))
 		return
 	}
@@ -33,9 +45,10 @@ func (switchExpressionParser) Parse(pi *parse.Input) (n Node, ok bool, err error
 	// Once we've had the start of a switch block, we must conclude the block.
 
 	// Read the optional 'case' nodes.
+	cp := caseExpressionParser{branches: branchContext{inFor: p.branches.inFor, inSwitch: true, inCase: true}}
 	for {
 		var ce CaseExpression
-		ce, ok, err = caseExpressionParser.Parse(pi)
+		ce, ok, err = cp.Parse(pi)
 		if err != nil {
 			return
 		}
@@ -45,6 +58,19 @@ func (switchExpressionParser) Parse(pi *parse.Input) (n Node, ok bool, err error
 		r.Cases = append(r.Cases, ce)
 	}
 
+	// As in Go, fallthrough must be the last statement of a case, and can't
+	// be used in the last case.
+	for i, c := range r.Cases {
+		for j, child := range c.Children {
+			if bs, ok := child.(BranchStatement); ok && bs.Expression.Value == "fallthrough" {
+				if i == len(r.Cases)-1 || !isWhitespaceOnly(c.Children[j+1:]) {
+					err = parse.Error("switch: fallthrough must be the last statement of a case, and can't be used in the last case", pi.PositionAt(int(bs.Expression.Range.From.Index)))
+					return r, false, err
+				}
+			}
+		}
+	}
+
 	// Read the required closing brace.
 	if _, ok, err = closeBraceWithOptionalPadding.Parse(pi); err != nil || !ok {
 		err = parse.Error("switch: "+unterminatedMissingEnd, pi.Position())
@@ -54,6 +80,15 @@ func (switchExpressionParser) Parse(pi *parse.Input) (n Node, ok bool, err error
 	return r, true, nil
 }
 
+func isWhitespaceOnly(nodes []Node) bool {
+	for _, n := range nodes {
+		if _, ok := n.(Whitespace); !ok {
+			return false
+		}
+	}
+	return true
+}
+
 var caseExpressionStartParser = parse.Func(func(pi *parse.Input) (r Expression, ok bool, err error) {
 	start := pi.Index()
 
@@ -79,13 +114,18 @@ var caseExpressionStartParser = parse.Func(func(pi *parse.Input) (r Expression,
 	return r, true, nil
 })
 
-var caseExpressionParser = parse.Func(func(pi *parse.Input) (r CaseExpression, ok bool, err error) {
+type caseExpressionParser struct {
+	branches branchContext
+}
+
+func (p caseExpressionParser) Parse(pi *parse.Input) (r CaseExpression, ok bool, err error) {
 	if r.Expression, ok, err = caseExpressionStartParser.Parse(pi); err != nil || !ok {
 		return
 	}
 
 	// Read until the next case statement, default, or end of the block.
 	pr := newTemplateNodeParser(parse.Any(StripType(closeBraceWithOptionalPadding), StripType(caseExpressionStartParser)), "closing brace or case expression")
+	pr.branches = p.branches
 	var nodes Nodes
 	if nodes, ok, err = pr.Parse(pi); err != nil || !ok {
 		err = parse.Error("case: expected nodes, but none were found", pi.Position())
@@ -99,4 +139,4 @@ var caseExpressionParser = parse.Func(func(pi *parse.Input) (r CaseExpression, o
 	}
 
 	return r, true, nil
-})
+}
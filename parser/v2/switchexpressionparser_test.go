@@ -298,6 +298,69 @@ default:
 				},
 			},
 		},
+		{
+			name: "switch: init statement and fallthrough",
+			input: `switch x := f(); x {
+	case 1:
+		fallthrough
+	default:
+		{ x }
+}`,
+			expected: SwitchExpression{
+				Expression: Expression{
+					Value: `x := f(); x`,
+					Range: Range{
+						From: Position{Index: 7, Line: 0, Col: 7},
+						To:   Position{Index: 18, Line: 0, Col: 18},
+					},
+				},
+				Cases: []CaseExpression{
+					{
+						Expression: Expression{
+							Value: "case 1:",
+							Range: Range{
+								From: Position{Index: 22, Line: 1, Col: 1},
+								To:   Position{Index: 29, Line: 1, Col: 8},
+							},
+						},
+						Children: []Node{
+							Whitespace{Value: "\t\t"},
+							BranchStatement{
+								Expression: Expression{
+									Value: "fallthrough",
+									Range: Range{
+										From: Position{Index: 32, Line: 2, Col: 2},
+										To:   Position{Index: 43, Line: 2, Col: 13},
+									},
+								},
+							},
+						},
+					},
+					{
+						Expression: Expression{
+							Value: "default:",
+							Range: Range{
+								From: Position{Index: 45, Line: 3, Col: 1},
+								To:   Position{Index: 53, Line: 3, Col: 9},
+							},
+						},
+						Children: []Node{
+							Whitespace{Value: "\t\t"},
+							StringExpression{
+								Expression: Expression{
+									Value: `x`,
+									Range: Range{
+										From: Position{Index: 58, Line: 4, Col: 4},
+										To:   Position{Index: 59, Line: 4, Col: 5},
+									},
+								},
+								TrailingSpace: SpaceVertical,
+							},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -326,6 +389,21 @@ func TestIncompleteSwitch(t *testing.T) {
 			t.Fatalf("unexpected error: %v", err)
 		}
 	})
+	t.Run("fallthrough in the last case", func(t *testing.T) {
+		input := parse.NewInput("switch x {\n\tcase 1:\n\t\tfallthrough\n}")
+		_, _, err := switchExpression.Parse(input)
+		expected := parse.Error("switch: fallthrough must be the last statement of a case, and can't be used in the last case", parse.Position{Index: 22, Line: 2, Col: 2})
+		if diff := cmp.Diff(expected, err); diff != "" {
+			t.Error(diff)
+		}
+	})
+	t.Run("fallthrough that isn't the last statement of a case", func(t *testing.T) {
+		input := parse.NewInput("switch x {\n\tcase 1:\n\t\tfallthrough\n\t\t<p></p>\n\tdefault:\n}")
+		_, _, err := switchExpression.Parse(input)
+		if err == nil {
+			t.Fatal("expected an error, got nil")
+		}
+	})
 	t.Run("capitalised Switch", func(t *testing.T) {
 		input := parse.NewInput(`Switch with no brace`)
 		_, ok, err := switchExpression.Parse(input)
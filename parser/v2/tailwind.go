@@ -0,0 +1,278 @@
+package parser
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// sortTailwindClasses sorts the classes in a class attribute value into the
+// order used by prettier-plugin-tailwindcss with the default Tailwind config.
+//
+// Classes that aren't Tailwind utilities are placed first, in source order.
+// Utilities without variants come next, followed by utilities with variants,
+// e.g. hover:, or md:. Utilities are ordered by the CSS properties they set.
+func sortTailwindClasses(value string) string {
+	classes := strings.Fields(value)
+	if len(classes) < 2 {
+		return value
+	}
+	type sortKey struct {
+		class   string
+		utility bool
+		variant int
+		order   int
+	}
+	keys := make([]sortKey, len(classes))
+	for i, class := range classes {
+		variant, base := tailwindVariantOrder(class)
+		order, utility := tailwindUtilityOrder(base)
+		keys[i] = sortKey{class: class, utility: utility, variant: variant, order: order}
+	}
+	slices.SortStableFunc(keys, func(a, b sortKey) int {
+		if a.utility != b.utility {
+			if !a.utility {
+				return -1
+			}
+			return 1
+		}
+		if !a.utility {
+			return 0
+		}
+		if a.variant != b.variant {
+			return a.variant - b.variant
+		}
+		return a.order - b.order
+	})
+	for i, k := range keys {
+		classes[i] = k.class
+	}
+	return strings.Join(classes, " ")
+}
+
+// tailwindVariants in the order they're generated by Tailwind.
+var tailwindVariants = []string{
+	"first", "last", "only", "odd", "even", "first-of-type", "last-of-type", "only-of-type",
+	"visited", "target", "open", "default", "checked", "indeterminate", "placeholder-shown",
+	"autofill", "optional", "required", "valid", "invalid", "in-range", "out-of-range",
+	"read-only", "empty", "focus-within", "hover", "focus", "focus-visible", "active",
+	"enabled", "disabled", "group", "peer", "ltr", "rtl", "motion-safe", "motion-reduce",
+	"dark", "print", "portrait", "landscape", "sm", "md", "lg", "xl", "2xl",
+}
+
+// tailwindVariantOrder returns the sort order of the class's variants, and
+// the class without variants. Classes without variants return zero.
+func tailwindVariantOrder(class string) (order int, base string) {
+	parts := splitTailwindVariants(class)
+	base = parts[len(parts)-1]
+	for _, v := range parts[:len(parts)-1] {
+		// Unknown variants, e.g. arbitrary variants, are sorted after known variants.
+		o := len(tailwindVariants) + 1
+		if i := slices.Index(tailwindVariants, strings.SplitN(v, "/", 2)[0]); i >= 0 {
+			o = i + 1
+		}
+		order = max(order, o)
+	}
+	return order, base
+}
+
+// splitTailwindVariants splits a class on colons that aren't within brackets,
+// e.g. md:[&>*]:p-4.
+func splitTailwindVariants(class string) (parts []string) {
+	var depth, start int
+	for i, r := range class {
+		switch r {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ':':
+			if depth == 0 {
+				parts = append(parts, class[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, class[start:])
+}
+
+type tailwindUtility func(base string) bool
+
+func twExact(names ...string) tailwindUtility {
+	return func(base string) bool {
+		return slices.Contains(names, base)
+	}
+}
+
+func twPrefix(prefixes ...string) tailwindUtility {
+	return func(base string) bool {
+		for _, p := range prefixes {
+			if base == p || strings.HasPrefix(base, p+"-") {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func twMatch(re string) tailwindUtility {
+	r := regexp.MustCompile("^(?:" + re + ")$")
+	return r.MatchString
+}
+
+const twSizes = `xs|sm|base|md|lg|xl|[2-9]xl`
+
+// tailwindUtilities are matched in order. The index of the first match is
+// the sort order of the utility.
+var tailwindUtilities = []tailwindUtility{
+	twExact("container"),
+	twExact("sr-only", "not-sr-only"),
+	twPrefix("pointer-events"),
+	twExact("visible", "invisible", "collapse"),
+	twExact("static", "fixed", "absolute", "relative", "sticky"),
+	twPrefix("inset", "inset-x", "inset-y", "start", "end", "top", "right", "bottom", "left"),
+	twExact("isolate", "isolation-auto"),
+	twPrefix("z"),
+	twPrefix("order"),
+	twPrefix("col", "col-span", "col-start", "col-end"),
+	twPrefix("row", "row-span", "row-start", "row-end"),
+	twPrefix("float"),
+	twPrefix("clear"),
+	twPrefix("m", "mx", "my", "ms", "me", "mt", "mr", "mb", "ml"),
+	twExact("box-border", "box-content"),
+	twPrefix("line-clamp"),
+	twExact("block", "inline-block", "inline", "flex", "inline-flex", "table", "inline-table", "table-caption", "table-cell", "table-column", "table-column-group", "table-footer-group", "table-header-group", "table-row-group", "table-row", "flow-root", "grid", "inline-grid", "contents", "list-item", "hidden"),
+	twPrefix("aspect"),
+	twPrefix("size"),
+	twPrefix("h"),
+	twPrefix("max-h"),
+	twPrefix("min-h"),
+	twPrefix("w"),
+	twPrefix("min-w"),
+	twPrefix("max-w"),
+	twMatch(`flex-(?:1|auto|initial|none|\[.*\])`),
+	twPrefix("shrink", "flex-shrink"),
+	twPrefix("grow", "flex-grow"),
+	twPrefix("basis"),
+	twExact("table-auto", "table-fixed"),
+	twPrefix("caption"),
+	twExact("border-collapse", "border-separate"),
+	twPrefix("border-spacing"),
+	twPrefix("origin"),
+	twPrefix("translate-x", "translate-y"),
+	twPrefix("rotate"),
+	twPrefix("skew-x", "skew-y"),
+	twPrefix("scale", "scale-x", "scale-y"),
+	twPrefix("transform"),
+	twPrefix("animate"),
+	twPrefix("cursor"),
+	twPrefix("touch"),
+	twPrefix("select"),
+	twPrefix("resize"),
+	twPrefix("snap"),
+	twPrefix("scroll-m", "scroll-mx", "scroll-my", "scroll-ms", "scroll-me", "scroll-mt", "scroll-mr", "scroll-mb", "scroll-ml"),
+	twPrefix("scroll-p", "scroll-px", "scroll-py", "scroll-ps", "scroll-pe", "scroll-pt", "scroll-pr", "scroll-pb", "scroll-pl"),
+	twPrefix("list"),
+	twPrefix("appearance"),
+	twPrefix("columns"),
+	twPrefix("break-before", "break-inside", "break-after"),
+	twPrefix("auto-cols"),
+	twPrefix("grid-flow"),
+	twPrefix("auto-rows"),
+	twPrefix("grid-cols"),
+	twPrefix("grid-rows"),
+	twExact("flex-row", "flex-row-reverse", "flex-col", "flex-col-reverse"),
+	twExact("flex-wrap", "flex-wrap-reverse", "flex-nowrap"),
+	twPrefix("place-content"),
+	twPrefix("place-items"),
+	twPrefix("content"),
+	twPrefix("items"),
+	twMatch(`justify-(?:normal|start|end|center|between|around|evenly|stretch)`),
+	twPrefix("justify-items"),
+	twPrefix("gap", "gap-x", "gap-y"),
+	twPrefix("space-x", "space-y"),
+	twPrefix("divide-x", "divide-y", "divide"),
+	twPrefix("self"),
+	twPrefix("justify-self"),
+	twPrefix("place-self"),
+	twPrefix("overflow", "overflow-x", "overflow-y"),
+	twPrefix("overscroll"),
+	twExact("scroll-auto", "scroll-smooth"),
+	twExact("truncate", "text-ellipsis", "text-clip"),
+	twPrefix("whitespace"),
+	twExact("text-wrap", "text-nowrap", "text-balance", "text-pretty"),
+	twExact("break-normal", "break-words", "break-all", "break-keep"),
+	twPrefix("rounded"),
+	twMatch(`border(?:-[xytrblse])?(?:-(?:0|2|4|8|\[\d.*\]))?`),
+	twExact("border-solid", "border-dashed", "border-dotted", "border-double", "border-hidden", "border-none"),
+	twPrefix("border"),
+	twMatch(`bg-(?:none|gradient-to-.*)`),
+	func(base string) bool {
+		return twPrefix("bg")(base) && !twPrefix("bg-blend")(base)
+	},
+	twPrefix("from"),
+	twPrefix("via"),
+	twPrefix("to"),
+	twPrefix("fill"),
+	twPrefix("stroke"),
+	twPrefix("object"),
+	twPrefix("p", "px", "py", "ps", "pe", "pt", "pr", "pb", "pl"),
+	twExact("text-left", "text-center", "text-right", "text-justify", "text-start", "text-end"),
+	twPrefix("indent"),
+	twPrefix("align"),
+	twExact("font-sans", "font-serif", "font-mono"),
+	twMatch(`text-(?:` + twSizes + `)(?:/.*)?`),
+	twPrefix("font"),
+	twExact("uppercase", "lowercase", "capitalize", "normal-case"),
+	twExact("italic", "not-italic"),
+	twExact("normal-nums", "ordinal", "slashed-zero", "lining-nums", "oldstyle-nums", "proportional-nums", "tabular-nums", "diagonal-fractions", "stacked-fractions"),
+	twPrefix("leading"),
+	twPrefix("tracking"),
+	twPrefix("text"),
+	twExact("underline", "overline", "line-through", "no-underline"),
+	twPrefix("decoration"),
+	twPrefix("underline-offset"),
+	twExact("antialiased", "subpixel-antialiased"),
+	twPrefix("placeholder"),
+	twPrefix("caret"),
+	twPrefix("accent"),
+	twPrefix("opacity"),
+	twPrefix("bg-blend"),
+	twPrefix("mix-blend"),
+	twPrefix("shadow"),
+	twPrefix("outline"),
+	twPrefix("ring"),
+	twPrefix("blur"),
+	twPrefix("brightness"),
+	twPrefix("contrast"),
+	twPrefix("drop-shadow"),
+	twPrefix("grayscale"),
+	twPrefix("hue-rotate"),
+	twPrefix("invert"),
+	twPrefix("saturate"),
+	twPrefix("sepia"),
+	twPrefix("filter"),
+	twPrefix("backdrop"),
+	twPrefix("transition"),
+	twPrefix("delay"),
+	twPrefix("duration"),
+	twPrefix("ease"),
+	twPrefix("will-change"),
+}
+
+// tailwindUtilityOrder returns the sort order of a Tailwind utility class
+// without variants, or false if the class isn't a Tailwind utility.
+func tailwindUtilityOrder(base string) (order int, ok bool) {
+	base = strings.TrimPrefix(base, "!")
+	base = strings.TrimPrefix(base, "-")
+	// Arbitrary properties, e.g. [mask-type:luminance], are sorted last.
+	if strings.HasPrefix(base, "[") && strings.HasSuffix(base, "]") {
+		return len(tailwindUtilities), true
+	}
+	for i, u := range tailwindUtilities {
+		if u(base) {
+			return i, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,56 @@
+package parser
+
+import "testing"
+
+func TestSortTailwindClasses(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "single classes are unchanged",
+			input:    "p-4",
+			expected: "p-4",
+		},
+		{
+			name:     "custom classes are placed first",
+			input:    "p-4 card flex",
+			expected: "card flex p-4",
+		},
+		{
+			name:     "utilities are sorted by property",
+			input:    "text-white font-bold text-lg text-center rounded border border-red-500 bg-red-500",
+			expected: "rounded border border-red-500 bg-red-500 text-center text-lg font-bold text-white",
+		},
+		{
+			name:     "variants are placed after utilities",
+			input:    "lg:w-1/2 md:w-full hover:underline w-8 focus:outline-none",
+			expected: "w-8 hover:underline focus:outline-none md:w-full lg:w-1/2",
+		},
+		{
+			name:     "negative and important utilities are sorted",
+			input:    "!p-0 -mt-2 absolute",
+			expected: "absolute -mt-2 !p-0",
+		},
+		{
+			name:     "arbitrary properties are placed last",
+			input:    "[mask-type:luminance] flex",
+			expected: "flex [mask-type:luminance]",
+		},
+		{
+			name:     "arbitrary variants are not split within brackets",
+			input:    "[&>*]:p-4 p-2",
+			expected: "p-2 [&>*]:p-4",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			actual := sortTailwindClasses(tt.input)
+			if actual != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, actual)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package parser
 
 import (
 	"errors"
+	"go/build/constraint"
 	"os"
 	"path/filepath"
 	"strings"
@@ -70,13 +71,31 @@ type TemplateFileParser struct {
 var legacyPackageParser = parse.String("{% package")
 
 func (p TemplateFileParser) Parse(pi *parse.Input) (tf TemplateFile, ok bool, err error) {
+	tf, _, ok, err = p.parseWithRanges(pi)
+	return tf, ok, err
+}
+
+// parseWithRanges parses the template file, and returns the source range of
+// each of the template file's nodes, excluding surrounding whitespace.
+func (p TemplateFileParser) parseWithRanges(pi *parse.Input) (tf TemplateFile, ranges []Range, ok bool, err error) {
+	addNode := func(n TemplateFileNode, from parse.Position) {
+		tf.Nodes = append(tf.Nodes, n)
+		ranges = append(ranges, NewRange(from, pi.Position()))
+	}
+	// Record the blank lines after each node, so that they can be preserved
+	// by the formatter.
+	skipWhitespace := func() {
+		ws, _, _ := parse.OptionalWhitespace.Parse(pi)
+		tf.BlankLines = append(tf.BlankLines, countBlankLines(ws))
+	}
+
 	// If we're parsing a legacy file, complain that migration needs to happen.
 	_, ok, err = legacyPackageParser.Parse(pi)
 	if err != nil {
 		return
 	}
 	if ok {
-		return tf, false, ErrLegacyFileFormat
+		return tf, ranges, false, ErrLegacyFileFormat
 	}
 
 	// Read until the package.
@@ -100,6 +119,14 @@ func (p TemplateFileParser) Parse(pi *parse.Input) (tf TemplateFile, ok bool, er
 		if !ok {
 			break
 		}
+		if constraint.IsGoBuild(strings.TrimSpace(line)) {
+			if _, err = constraint.Parse(strings.TrimSpace(line)); err != nil {
+				return tf, ranges, false, parse.Error("invalid //go:build constraint: "+err.Error(), from)
+			}
+			if _, hasConstraint := tf.BuildConstraint(); hasConstraint {
+				return tf, ranges, false, parse.Error("multiple //go:build constraints", from)
+			}
+		}
 		var newLine string
 		newLine, _, _ = parse.NewLine.Parse(pi)
 		tf.Header = append(tf.Header, TemplateFileGoExpression{Expression: NewExpression(line+newLine, from, pi.Position()), BeforePackage: true})
@@ -112,14 +139,15 @@ outer:
 	for {
 		// Optional templates, CSS, and script templates.
 		// templ Name(p Parameter)
+		from := pi.Position()
 		var tn HTMLTemplate
 		tn, ok, err = template.Parse(pi)
 		if err != nil {
-			return tf, false, err
+			return tf, ranges, false, err
 		}
 		if ok {
-			tf.Nodes = append(tf.Nodes, tn)
-			_, _, _ = parse.OptionalWhitespace.Parse(pi)
+			addNode(tn, from)
+			skipWhitespace()
 			continue
 		}
 
@@ -127,11 +155,23 @@ outer:
 		var cn CSSTemplate
 		cn, ok, err = cssParser.Parse(pi)
 		if err != nil {
-			return tf, false, err
+			return tf, ranges, false, err
 		}
 		if ok {
-			tf.Nodes = append(tf.Nodes, cn)
-			_, _, _ = parse.OptionalWhitespace.Parse(pi)
+			addNode(cn, from)
+			skipWhitespace()
+			continue
+		}
+
+		// keyframes Name()
+		var kn KeyframesTemplate
+		kn, ok, err = keyframesParser.Parse(pi)
+		if err != nil {
+			return tf, ranges, false, err
+		}
+		if ok {
+			addNode(kn, from)
+			skipWhitespace()
 			continue
 		}
 
@@ -139,17 +179,16 @@ outer:
 		var sn ScriptTemplate
 		sn, ok, err = scriptTemplateParser.Parse(pi)
 		if err != nil {
-			return tf, false, err
+			return tf, ranges, false, err
 		}
 		if ok {
-			tf.Nodes = append(tf.Nodes, sn)
-			_, _, _ = parse.OptionalWhitespace.Parse(pi)
+			addNode(sn, from)
+			skipWhitespace()
 			continue
 		}
 
 		// Anything that isn't template content is Go code.
 		code := new(strings.Builder)
-		from := pi.Position()
 	inner:
 		for {
 			// Check to see if this line isn't Go code.
@@ -158,14 +197,15 @@ outer:
 			if l, ok, err = stringUntilNewLineOrEOF.Parse(pi); err != nil {
 				return
 			}
-			hasTemplatePrefix := strings.HasPrefix(l, "templ ") || strings.HasPrefix(l, "css ") || strings.HasPrefix(l, "script ")
+			hasTemplatePrefix := strings.HasPrefix(l, "templ ") || strings.HasPrefix(l, "css ") || strings.HasPrefix(l, "keyframes ") || strings.HasPrefix(l, "script ")
 			if hasTemplatePrefix && strings.Contains(l, "(") {
 				// Unread the line.
 				pi.Seek(last)
 				// Take the code so far.
 				if code.Len() > 0 {
 					expr := NewExpression(strings.TrimSpace(code.String()), from, pi.Position())
-					tf.Nodes = append(tf.Nodes, TemplateFileGoExpression{Expression: expr})
+					addNode(TemplateFileGoExpression{Expression: expr}, from)
+					tf.BlankLines = append(tf.BlankLines, countBlankLines(code.String()[len(strings.TrimRightFunc(code.String(), unicode.IsSpace)):]))
 				}
 				// Carry on parsing.
 				break inner
@@ -181,7 +221,8 @@ outer:
 			if _, isEOF, _ := parse.EOF[string]().Parse(pi); isEOF {
 				if code.Len() > 0 {
 					expr := NewExpression(strings.TrimSpace(code.String()), from, pi.Position())
-					tf.Nodes = append(tf.Nodes, TemplateFileGoExpression{Expression: expr})
+					addNode(TemplateFileGoExpression{Expression: expr}, from)
+					tf.BlankLines = append(tf.BlankLines, 0)
 				}
 				// Stop parsing.
 				break outer
@@ -189,5 +230,10 @@ outer:
 		}
 	}
 
-	return tf, true, nil
+	return tf, ranges, true, nil
+}
+
+// countBlankLines returns the number of blank lines in the whitespace ws.
+func countBlankLines(ws string) int {
+	return max(strings.Count(ws, "\n")-1, 0)
 }
@@ -155,6 +155,60 @@ templ template(
 			t.Errorf("2: expected expression, got %t", tf.Nodes[2])
 		}
 	})
+	t.Run("build constraints are parsed from the header", func(t *testing.T) {
+		input := `// Debug components.
+//go:build dev && !prod
+
+package goof
+
+templ Hello() {
+	Hello
+}`
+		tf, err := ParseString(input)
+		if err != nil {
+			t.Fatalf("failed to parse template, with t.Fatalf(parser %v", err)
+		}
+		expr, ok := tf.BuildConstraint()
+		if !ok {
+			t.Fatal("expected a build constraint")
+		}
+		if expr.String() != "dev && !prod" {
+			t.Errorf("unexpected build constraint: %q", expr.String())
+		}
+		dev := func(tag string) bool { return tag == "dev" }
+		if !expr.Eval(dev) {
+			t.Error("expected the constraint to be satisfied by the dev tag")
+		}
+	})
+	t.Run("files without build constraints don't have one", func(t *testing.T) {
+		tf, err := ParseString("// Comment.\npackage goof\n")
+		if err != nil {
+			t.Fatalf("failed to parse template, with t.Fatalf(parser %v", err)
+		}
+		if _, ok := tf.BuildConstraint(); ok {
+			t.Error("expected no build constraint")
+		}
+	})
+	t.Run("invalid build constraints are an error", func(t *testing.T) {
+		_, err := ParseString("//go:build dev &&\n\npackage goof\n")
+		if err == nil {
+			t.Fatal("expected an error, got nil")
+		}
+		expected := "invalid //go:build constraint: unexpected end of expression: line 0, col 0"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
+	t.Run("multiple build constraints are an error", func(t *testing.T) {
+		_, err := ParseString("//go:build dev\n//go:build linux\n\npackage goof\n")
+		if err == nil {
+			t.Fatal("expected an error, got nil")
+		}
+		expected := "multiple //go:build constraints: line 1, col 0"
+		if err.Error() != expected {
+			t.Errorf("expected error %q, got %q", expected, err.Error())
+		}
+	})
 }
 
 func TestDefaultPackageName(t *testing.T) {
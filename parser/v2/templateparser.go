@@ -14,6 +14,7 @@ import (
 // templ (data []string) Func(p Parameter) {
 type templateExpression struct {
 	Expression Expression
+	Defaults   []DefaultParameter
 }
 
 var templateExpressionParser = parse.Func(func(pi *parse.Input) (r templateExpression, ok bool, err error) {
@@ -28,7 +29,7 @@ var templateExpressionParser = parse.Func(func(pi *parse.Input) (r templateExpre
 	// templ (x []string) Test() {
 	// becomes:
 	// func (x []string) Test() templ.Component {
-	if _, r.Expression, err = parseTemplFuncDecl(pi); err != nil {
+	if r.Expression, r.Defaults, err = parseTemplComponentDecl(pi); err != nil {
 		return r, false, err
 	}
 
@@ -57,29 +58,60 @@ func newTemplateNodeParser[TUntil any](until parse.Parser[TUntil], untilName str
 type templateNodeParser[TUntil any] struct {
 	until     parse.Parser[TUntil]
 	untilName string
+	// branches is the context of the nodes, which determines whether
+	// break, continue, and fallthrough are parsed as branch statements.
+	branches branchContext
 }
 
-var rawElements = parse.Any[Node](styleElement, scriptElement)
-
-var templateNodeParsers = []parse.Parser[Node]{
-	docType,                // <!DOCTYPE html>
-	htmlComment,            // <!--
-	goComment,              // // or /*
-	rawElements,            // <text>, <>, or <style> element (special behaviour - contents are not parsed).
-	element,                // <a>, <br/> etc.
-	ifExpression,           // if {}
-	forExpression,          // for {}
-	switchExpression,       // switch {}
-	callTemplateExpression, // {! TemplateName(a, b, c) }
-	templElementExpression, // @TemplateName(a, b, c) { <div>Children</div> }
-	childrenExpression,     // { children... }
-	goCode,                 // {{ myval := x.myval }}
-	stringExpression,       // { "abc" }
-	whitespaceExpression,   // { " " }
-	textParser,             // anything &amp; everything accepted...
+var rawElements = parse.Any[Node](styleElement, scriptElement, textareaElement, titleElement, configuredRawElement)
+
+func templateNodeParsers(bc branchContext) []parse.Parser[Node] {
+	return []parse.Parser[Node]{
+		docType,               // <!DOCTYPE html>
+		processingInstruction, // <?xml version="1.0"?>
+		rawBlock,              // <!-- templ:raw -->
+		htmlComment,           // <!--
+		cdata,                 // <![CDATA[
+		goComment,             // // or /*
+		rawElements,           // <text>, <>, or <style> element (special behaviour - contents are not parsed).
+		element,               // <a>, <br/> etc.
+		ifExpressionParser{branches: bc.nested()},     // if {}
+		forExpressionParser{branches: bc.nested()},    // for {}
+		switchExpressionParser{branches: bc.nested()}, // switch {}
+		branchStatementParser{branches: bc},           // break or continue, with an optional label.
+		deferExpression,                               // defer {}
+		inlineTemplate,                                // templ name() {}
+		blockExpression,                               // block name {}
+		includeExpression,                             // @include "partials/footer.templ"
+		markdownBlock,                                 // @markdown { # Title }
+		callTemplateExpression,                        // {! TemplateName(a, b, c) }
+		templElementExpression,                        // @TemplateName(a, b, c) { <div>Children</div> }
+		childrenExpression,                            // { children... }
+		goCode,                                        // {{ myval := x.myval }}
+		stringExpression,                              // { "abc" }
+		whitespaceExpression,                          // { " " }
+		textParser,                                    // anything &amp; everything accepted...
+	}
+}
+
+// nodeParsers are the parsers of template nodes for each branch context,
+// built once, rather than for each list of nodes that's parsed.
+var nodeParsers = map[branchContext][]parse.Parser[Node]{}
+
+// The parsers are built in init, since they refer back to templateNodeParser.
+func init() {
+	for _, inFor := range []bool{false, true} {
+		for _, inSwitch := range []bool{false, true} {
+			for _, inCase := range []bool{false, true} {
+				bc := branchContext{inFor: inFor, inSwitch: inSwitch, inCase: inCase}
+				nodeParsers[bc] = templateNodeParsers(bc)
+			}
+		}
+	}
 }
 
 func (p templateNodeParser[T]) Parse(pi *parse.Input) (op Nodes, ok bool, err error) {
+	parsers := nodeParsers[p.branches]
 	for {
 		// Check if we've reached the end.
 		if p.until != nil {
@@ -97,7 +129,7 @@ func (p templateNodeParser[T]) Parse(pi *parse.Input) (op Nodes, ok bool, err er
 		// Attempt to parse a node.
 		// Loop through the parsers and try to parse a node.
 		var matched bool
-		for _, p := range templateNodeParsers {
+		for _, p := range parsers {
 			var node Node
 			node, matched, err = p.Parse(pi)
 			if err != nil {
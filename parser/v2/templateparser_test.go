@@ -58,6 +58,50 @@ func TestTemplateParser(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "template: with type parameters",
+			input: `templ List[T any, K comparable](items []T, key func(T) K) {
+}`,
+			expected: HTMLTemplate{
+				Expression: Expression{
+					Value: "List[T any, K comparable](items []T, key func(T) K)",
+					Range: Range{
+						From: Position{
+							Index: 6,
+							Line:  0,
+							Col:   6,
+						},
+						To: Position{
+							Index: 57,
+							Line:  0,
+							Col:   57,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "template: with a receiver that has type parameters",
+			input: `templ (p Pair[K, V]) Name() {
+}`,
+			expected: HTMLTemplate{
+				Expression: Expression{
+					Value: "(p Pair[K, V]) Name()",
+					Range: Range{
+						From: Position{
+							Index: 6,
+							Line:  0,
+							Col:   6,
+						},
+						To: Position{
+							Index: 27,
+							Line:  0,
+							Col:   27,
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "template: no spaces",
 			input: `templ Name(){
@@ -732,6 +776,39 @@ func TestTemplateParser(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "template: with default parameter values",
+			input: `templ Button(label string, variant string = "primary") {
+}`,
+			expected: HTMLTemplate{
+				Expression: Expression{
+					Value: `Button(label string, variant string = "primary")`,
+					Range: Range{
+						From: Position{Index: 6, Line: 0, Col: 6},
+						To:   Position{Index: 54, Line: 0, Col: 54},
+					},
+				},
+				Defaults: []DefaultParameter{
+					{
+						Name: Expression{
+							Value: "variant",
+							Range: Range{
+								From: Position{Index: 27, Line: 0, Col: 27},
+								To:   Position{Index: 34, Line: 0, Col: 34},
+							},
+						},
+						Type: "string",
+						Value: Expression{
+							Value: `"primary"`,
+							Range: Range{
+								From: Position{Index: 44, Line: 0, Col: 44},
+								To:   Position{Index: 53, Line: 0, Col: 53},
+							},
+						},
+					},
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -766,6 +843,12 @@ func TestTemplateParserErrors(t *testing.T) {
 }`,
 			expected: "<span>: malformed open element: line 2, col 0",
 		},
+		{
+			name: "template: default parameter values must be trailing",
+			input: `templ Button(variant string = "primary", label string) {
+}`,
+			expected: "invalid templ declaration: parameters with default values must come after the other parameters: line 0, col 0",
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
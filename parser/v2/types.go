@@ -6,8 +6,11 @@ import (
 	"fmt"
 	"go/format"
 	"io"
+	"slices"
+	"strconv"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/a-h/parse"
 )
@@ -121,13 +124,29 @@ type TemplateFile struct {
 	Package Package
 	// Nodes in the file.
 	Nodes []TemplateFileNode
+	// BlankLines is the number of blank lines after each of the Nodes in the
+	// source. It's used to preserve blank lines between declarations when
+	// FormatOptions.MaxBlankLines is set.
+	BlankLines []int
 }
 
 func (tf TemplateFile) Write(w io.Writer) error {
-	for _, n := range tf.Header {
-		if err := n.Write(w, 0); err != nil {
+	return tf.WriteWithOptions(w, FormatOptions{})
+}
+
+// WriteWithOptions formats the template file to w, using the given options.
+func (tf TemplateFile) WriteWithOptions(w io.Writer, opts FormatOptions) error {
+	opts = opts.withGoStatements(tf.Nodes)
+	for i, n := range tf.Header {
+		if err := n.writeFormatted(w, 0, opts); err != nil {
 			return err
 		}
+		// Build constraints must be followed by a blank line.
+		if isGoBuild(n) && (i+1 == len(tf.Header) || strings.TrimSpace(tf.Header[i+1].Expression.Value) != "") {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
 	}
 	var indent int
 	if err := tf.Package.Write(w, indent); err != nil {
@@ -137,26 +156,32 @@ func (tf TemplateFile) Write(w io.Writer) error {
 		return err
 	}
 	for i := 0; i < len(tf.Nodes); i++ {
-		if err := tf.Nodes[i].Write(w, indent); err != nil {
+		if err := writeFormatted(tf.Nodes[i], w, indent, opts); err != nil {
 			return err
 		}
-		if _, err := io.WriteString(w, getNodeWhitespace(tf.Nodes, i)); err != nil {
+		if _, err := io.WriteString(w, tf.getNodeWhitespace(i, opts)); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func getNodeWhitespace(nodes []TemplateFileNode, i int) string {
-	if i == len(nodes)-1 {
+func (tf TemplateFile) getNodeWhitespace(i int, opts FormatOptions) string {
+	if i == len(tf.Nodes)-1 {
 		return "\n"
 	}
-	if _, nextIsTemplate := nodes[i+1].(HTMLTemplate); nextIsTemplate {
-		if e, isGo := nodes[i].(TemplateFileGoExpression); isGo && endsWithComment(e.Expression.Value) {
+	if _, nextIsTemplate := tf.Nodes[i+1].(HTMLTemplate); nextIsTemplate {
+		if e, isGo := tf.Nodes[i].(TemplateFileGoExpression); isGo && endsWithComment(e.Expression.Value) {
 			return "\n"
 		}
 	}
-	return "\n\n"
+	// Declarations are separated by a blank line, or by up to the configured
+	// maximum number of blank lines from the source.
+	blankLines := 1
+	if opts.MaxBlankLines > 0 && i < len(tf.BlankLines) {
+		blankLines = max(min(tf.BlankLines[i], opts.MaxBlankLines), 1)
+	}
+	return strings.Repeat("\n", 1+blankLines)
 }
 
 func endsWithComment(s string) bool {
@@ -178,12 +203,18 @@ type TemplateFileGoExpression struct {
 
 func (exp TemplateFileGoExpression) IsTemplateFileNode() bool { return true }
 func (exp TemplateFileGoExpression) Write(w io.Writer, indent int) error {
+	return exp.writeFormatted(w, indent, FormatOptions{})
+}
+
+func (exp TemplateFileGoExpression) writeFormatted(w io.Writer, indent int, opts FormatOptions) error {
 	in := exp.Expression.Value
 
 	if exp.BeforePackage {
 		in += "\\\\formatstring\npackage p\n\\\\formatstring"
+		// Code before the package declaration is always formatted with go/format.
+		opts.GoFormatter = ""
 	}
-	data, err := format.Source([]byte(in))
+	data, err := opts.formatGoDeclarations(in)
 	if err != nil {
 		return writeIndent(w, indent, exp.Expression.Value)
 	}
@@ -286,12 +317,77 @@ func (css CSSTemplate) Write(w io.Writer, indent int) error {
 	return nil
 }
 
+// KeyframesTemplate is a @keyframes rule, whose name is unique to its
+// contents, for use in animation-name.
+//
+//	keyframes fadeIn() {
+//	  from {
+//	    opacity: 0;
+//	  }
+//	  to {
+//	    opacity: 1;
+//	  }
+//	}
+type KeyframesTemplate struct {
+	Name       string
+	Expression Expression
+	// Keyframes are the rules of the keyframes, whose selectors are from, to
+	// or percentages.
+	Keyframes []CSSRule
+}
+
+func (k KeyframesTemplate) IsTemplateFileNode() bool { return true }
+func (k KeyframesTemplate) Write(w io.Writer, indent int) error {
+	source := formatFunctionArguments(k.Expression.Value)
+	if err := writeIndent(w, indent, "keyframes ", string(source), " {\n"); err != nil {
+		return err
+	}
+	for _, kf := range k.Keyframes {
+		if err := kf.Write(w, indent+1); err != nil {
+			return err
+		}
+	}
+	return writeIndent(w, indent, "}")
+}
+
 // CSSProperty is a CSS property and value pair.
 type CSSProperty interface {
 	IsCSSProperty() bool
 	Write(w io.Writer, indent int) error
 }
 
+// CSSRule is a rule nested within a css component, whose properties apply to
+// the elements that match its selector, e.g. &:hover, or whose properties
+// apply within an at-rule, e.g. @media (min-width: 768px).
+//
+//	&:hover {
+//	  color: red;
+//	}
+type CSSRule struct {
+	// Selector of the rule, where & is the class of the component, or an
+	// at-rule, e.g. @media (min-width: 768px).
+	Selector   string
+	Properties []CSSProperty
+}
+
+func (r CSSRule) IsCSSProperty() bool { return true }
+func (r CSSRule) Write(w io.Writer, indent int) error {
+	if err := writeIndent(w, indent, r.Selector, " {\n"); err != nil {
+		return err
+	}
+	for _, p := range r.Properties {
+		if err := p.Write(w, indent+1); err != nil {
+			return err
+		}
+	}
+	return writeIndent(w, indent, "}\n")
+}
+
+// IsAtRule returns true if the rule is an at-rule, e.g. @media.
+func (r CSSRule) IsAtRule() bool {
+	return strings.HasPrefix(r.Selector, "@")
+}
+
 // color: #ffffff;
 type ConstantCSSProperty struct {
 	Name  string
@@ -352,6 +448,25 @@ func (dt DocType) Write(w io.Writer, indent int) error {
 	return writeIndent(w, indent, "<!DOCTYPE ", dt.Value, ">")
 }
 
+// <?xml version="1.0" encoding="UTF-8"?>
+type ProcessingInstruction struct {
+	// Value is the target and contents of the instruction, e.g.
+	// xml-stylesheet href="/feed.xsl" type="text/xsl".
+	Value string
+}
+
+func (pi ProcessingInstruction) IsNode() bool { return true }
+func (pi ProcessingInstruction) Write(w io.Writer, indent int) error {
+	return writeIndent(w, indent, "<?", pi.Value, "?>")
+}
+
+// IsXMLDeclaration returns true if the instruction is an XML declaration,
+// e.g. <?xml version="1.0"?>.
+func (pi ProcessingInstruction) IsXMLDeclaration() bool {
+	target, _, _ := strings.Cut(strings.TrimSpace(pi.Value), " ")
+	return target == "xml"
+}
+
 // HTMLTemplate definition.
 //
 //	templ Name(p Parameter) {
@@ -361,17 +476,51 @@ func (dt DocType) Write(w io.Writer, indent int) error {
 //	}
 type HTMLTemplate struct {
 	Expression Expression
-	Children   []Node
+	// Defaults are the parameters that have default values, e.g.
+	// `variant string = "primary"`. They're always the last parameters.
+	Defaults []DefaultParameter
+	Children []Node
+}
+
+// DefaultParameter is a template parameter with a default value.
+//
+//	templ Button(label string, variant string = "primary") {
+type DefaultParameter struct {
+	Name  Expression
+	Type  string
+	Value Expression
+}
+
+// Signature returns the Go function signature of the template, with the
+// default values removed from the parameters.
+func (t HTMLTemplate) Signature() string {
+	if len(t.Defaults) == 0 {
+		return t.Expression.Value
+	}
+	var sb strings.Builder
+	sb.WriteString(t.Expression.Value[:t.Defaults[0].Name.Range.From.Index-t.Expression.Range.From.Index])
+	for i, d := range t.Defaults {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(d.Name.Value + " " + d.Type)
+	}
+	sb.WriteString(")")
+	return sb.String()
 }
 
 func (t HTMLTemplate) IsTemplateFileNode() bool { return true }
 
 func (t HTMLTemplate) Write(w io.Writer, indent int) error {
+	return t.writeFormatted(w, indent, FormatOptions{})
+}
+
+func (t HTMLTemplate) writeFormatted(w io.Writer, indent int, opts FormatOptions) error {
 	source := formatFunctionArguments(t.Expression.Value)
 	if err := writeIndent(w, indent, "templ ", string(source), " {\n"); err != nil {
 		return err
 	}
-	if err := writeNodesIndented(w, indent+1, t.Children); err != nil {
+	if err := writeNodesIndented(w, indent+1, t.Children, opts); err != nil {
 		return err
 	}
 	if err := writeIndent(w, indent, "}"); err != nil {
@@ -390,12 +539,29 @@ const (
 	SpaceVertical   TrailingSpace = "\n"
 )
 
+// IsVertical returns true if the trailing space contains a line break.
+func (ts TrailingSpace) IsVertical() bool {
+	return strings.HasPrefix(string(ts), string(SpaceVertical))
+}
+
+// BlankLines returns the number of blank lines within the trailing space.
+func (ts TrailingSpace) BlankLines() int {
+	if !ts.IsVertical() {
+		return 0
+	}
+	return len(ts) - 1
+}
+
 var ErrNonSpaceCharacter = errors.New("non space character found")
 
 func NewTrailingSpace(s string) (ts TrailingSpace, err error) {
 	var hasHorizontalSpace bool
 	for _, r := range s {
 		if r == '\n' {
+			// Keep track of blank lines, so that the formatter can preserve them.
+			if n := strings.Count(s, "\n"); n > 1 {
+				return TrailingSpace(strings.Repeat("\n", n)), nil
+			}
 			return SpaceVertical, nil
 		}
 		if unicode.IsSpace(r) {
@@ -434,6 +600,7 @@ var (
 	_ WhitespaceTrailer = Element{}
 	_ WhitespaceTrailer = Text{}
 	_ WhitespaceTrailer = StringExpression{}
+	_ WhitespaceTrailer = CDATA{}
 )
 
 // Text node within the document.
@@ -462,6 +629,14 @@ type Element struct {
 	IndentChildren bool
 	TrailingSpace  TrailingSpace
 	NameRange      Range
+	// RawChildren is the source of the children of whitespace-sensitive
+	// elements, e.g. <pre>, which the formatter writes unchanged.
+	RawChildren string
+	// OpenTagEndRange is the range of the > at the end of the open tag, and
+	// CloseTagRange is the range of the close tag, of a void element that
+	// has children, e.g. <input>Text</input>, so that it can be fixed.
+	OpenTagEndRange Range
+	CloseTagRange   Range
 }
 
 func (e Element) Trailing() TrailingSpace {
@@ -472,10 +647,10 @@ var voidElements = map[string]struct{}{
 	"area": {}, "base": {}, "br": {}, "col": {}, "command": {}, "embed": {}, "hr": {}, "img": {}, "input": {}, "keygen": {}, "link": {}, "meta": {}, "param": {}, "source": {}, "track": {}, "wbr": {},
 }
 
-// https://www.w3.org/TR/2011/WD-html-markup-20110113/syntax.html#void-element
+// IsVoidElement returns true if the element is an HTML void element, or one
+// configured with ConfigureElements.
 func (e Element) IsVoidElement() bool {
-	_, ok := voidElements[e.Name]
-	return ok
+	return IsVoidElement(e.Name)
 }
 
 func (e Element) hasNonWhitespaceChildren() bool {
@@ -500,14 +675,6 @@ func (e Element) IsBlockElement() bool {
 
 // Validate that no invalid expressions have been used.
 func (e Element) Validate() (msgs []string, ok bool) {
-	// Validate that style attributes are constant.
-	for _, attr := range e.Attributes {
-		if exprAttr, isExprAttr := attr.(ExpressionAttribute); isExprAttr {
-			if strings.EqualFold(exprAttr.Name, "style") {
-				msgs = append(msgs, "invalid style attribute: style attributes cannot be a templ expression")
-			}
-		}
-	}
 	// Validate that script and style tags don't contain expressions.
 	if strings.EqualFold(e.Name, "script") || strings.EqualFold(e.Name, "style") {
 		if containsNonTextNodes(e.Children) {
@@ -537,6 +704,22 @@ func (e Element) ChildNodes() []Node {
 }
 func (e Element) IsNode() bool { return true }
 func (e Element) Write(w io.Writer, indent int) error {
+	return e.writeFormatted(w, indent, FormatOptions{})
+}
+
+func (e Element) writeFormatted(w io.Writer, indent int, opts FormatOptions) error {
+	e.Attributes = sortAttributes(e.Attributes, opts.SortAttributes)
+	if opts.SortTailwindClasses {
+		e.Attributes = sortClassAttributes(e.Attributes)
+	}
+	if opts.MaxLineWidth > 0 {
+		e.IndentAttrs = e.openTagExceedsWidth(indent, opts)
+	}
+	if opts.InlineShortElements && e.IndentChildren && e.RawChildren == "" {
+		if inline, ok := e.inline(indent, opts); ok {
+			return writeIndent(w, indent, inline)
+		}
+	}
 	if err := writeIndent(w, indent, "<", e.Name); err != nil {
 		return err
 	}
@@ -554,7 +737,7 @@ func (e Element) Write(w io.Writer, indent int) error {
 				return err
 			}
 		}
-		if err := a.Write(w, attrIndent); err != nil {
+		if err := writeFormatted(a, w, attrIndent, opts); err != nil {
 			return err
 		}
 	}
@@ -565,12 +748,18 @@ func (e Element) Write(w io.Writer, indent int) error {
 		}
 		closeAngleBracketIndent = indent
 	}
+	if e.RawChildren != "" {
+		if err := writeIndent(w, closeAngleBracketIndent, ">", e.RawChildren, "</", e.Name, ">"); err != nil {
+			return err
+		}
+		return nil
+	}
 	if e.hasNonWhitespaceChildren() {
 		if e.IndentChildren {
 			if err := writeIndent(w, closeAngleBracketIndent, ">\n"); err != nil {
 				return err
 			}
-			if err := writeNodesIndented(w, indent+1, e.Children); err != nil {
+			if err := writeNodesIndented(w, indent+1, e.Children, opts); err != nil {
 				return err
 			}
 			if err := writeIndent(w, indent, "</", e.Name, ">"); err != nil {
@@ -581,7 +770,7 @@ func (e Element) Write(w io.Writer, indent int) error {
 		if err := writeIndent(w, closeAngleBracketIndent, ">"); err != nil {
 			return err
 		}
-		if err := writeNodesWithoutIndentation(w, e.Children); err != nil {
+		if err := writeNodesWithoutIndentation(w, e.Children, opts); err != nil {
 			return err
 		}
 		if _, err := w.Write([]byte("</" + e.Name + ">")); err != nil {
@@ -601,16 +790,80 @@ func (e Element) Write(w io.Writer, indent int) error {
 	return nil
 }
 
-func writeNodesWithoutIndentation(w io.Writer, nodes []Node) error {
-	return writeNodes(w, 0, nodes, false)
+// openTagExceedsWidth returns true if the open tag of the element, with all
+// attributes on a single line, would be wider than maxWidth, or if any
+// attribute spans multiple lines.
+func (e Element) openTagExceedsWidth(indent int, opts FormatOptions) bool {
+	if len(e.Attributes) == 0 {
+		return false
+	}
+	width := indent*tabWidth + utf8.RuneCountInString("<"+e.Name)
+	for _, a := range e.Attributes {
+		var sb strings.Builder
+		if err := writeFormatted(a, &sb, 0, opts); err != nil || strings.Contains(sb.String(), "\n") {
+			return true
+		}
+		width += 1 + utf8.RuneCountInString(sb.String())
+	}
+	if !e.hasNonWhitespaceChildren() && e.IsVoidElement() {
+		width += len("/>")
+	} else {
+		width += len(">")
+	}
+	return width > opts.MaxLineWidth
+}
+
+// inline returns the element written on a single line, if it fits within
+// the maximum line width. Elements that contain block elements or
+// statements are not written inline.
+func (e Element) inline(indent int, opts FormatOptions) (s string, ok bool) {
+	if e.IndentAttrs {
+		return "", false
+	}
+	for _, c := range e.Children {
+		if shouldAlwaysBreakAfter(c) {
+			return "", false
+		}
+		// Single line comments run to the end of the line.
+		if gc, isComment := c.(GoComment); isComment && !gc.Multiline {
+			return "", false
+		}
+		if el, isElement := c.(Element); isElement && el.IsBlockElement() || !isElement && isBlockNode(c) {
+			return "", false
+		}
+	}
+	e.IndentChildren = false
+	var sb strings.Builder
+	if err := e.writeFormatted(&sb, 0, opts); err != nil {
+		return "", false
+	}
+	// Drop the line break between the last child and the close tag.
+	closeTag := "</" + e.Name + ">"
+	s = strings.TrimRight(strings.TrimSuffix(sb.String(), closeTag), "\n") + closeTag
+	if strings.Contains(s, "\n") {
+		return "", false
+	}
+	maxWidth := opts.MaxLineWidth
+	if maxWidth == 0 {
+		maxWidth = defaultMaxLineWidth
+	}
+	return s, indent*tabWidth+utf8.RuneCountInString(s) <= maxWidth
+}
+
+func writeNodesWithoutIndentation(w io.Writer, nodes []Node, opts FormatOptions) error {
+	return writeNodes(w, 0, nodes, false, opts)
 }
 
-func writeNodesIndented(w io.Writer, level int, nodes []Node) error {
-	return writeNodes(w, level, nodes, true)
+func writeNodesIndented(w io.Writer, level int, nodes []Node, opts FormatOptions) error {
+	return writeNodes(w, level, nodes, true, opts)
 }
 
-func writeNodes(w io.Writer, level int, nodes []Node, indent bool) error {
+func writeNodes(w io.Writer, level int, nodes []Node, indent bool, opts FormatOptions) error {
 	startLevel := level
+	var commentPadding map[int]int
+	if indent {
+		commentPadding = trailingCommentPadding(opts, nodes)
+	}
 	for i := 0; i < len(nodes); i++ {
 		_, isWhitespace := nodes[i].(Whitespace)
 
@@ -618,7 +871,7 @@ func writeNodes(w io.Writer, level int, nodes []Node, indent bool) error {
 		if isWhitespace {
 			continue
 		}
-		if err := nodes[i].Write(w, level); err != nil {
+		if err := writeFormatted(reindentComment(nodes[i], startLevel), w, level, opts); err != nil {
 			return err
 		}
 
@@ -628,16 +881,22 @@ func writeNodes(w io.Writer, level int, nodes []Node, indent bool) error {
 			trailing = wst.Trailing()
 		}
 		// Put a newline after the last node in indentation mode.
-		if indent && ((nextNodeIsBlock(nodes, i) || i == len(nodes)-1) || shouldAlwaysBreakAfter(nodes[i])) {
+		if indent && ((nextNodeIsBlock(nodes, i) || i == len(nodes)-1) || shouldAlwaysBreakAfter(nodes[i])) && !trailing.IsVertical() && !hasTrailingComment(nodes, i) {
 			trailing = SpaceVertical
 		}
-		switch trailing {
-		case SpaceNone:
-			level = 0
-		case SpaceHorizontal:
-			level = 0
-		case SpaceVertical:
+		// Align trailing comments on consecutive lines.
+		if padding, ok := commentPadding[i]; ok {
+			trailing = TrailingSpace(strings.Repeat(" ", padding))
+		}
+		if trailing.IsVertical() {
 			level = startLevel
+			trailing = SpaceVertical
+			// Preserve blank lines between nodes, up to the configured maximum.
+			if opts.MaxBlankLines > 0 && !isLastNode(nodes, i) {
+				trailing = TrailingSpace(strings.Repeat("\n", 1+min(blankLinesAfter(nodes, i), opts.MaxBlankLines)))
+			}
+		} else {
+			level = 0
 		}
 		if _, err := w.Write([]byte(trailing)); err != nil {
 			return err
@@ -646,6 +905,134 @@ func writeNodes(w io.Writer, level int, nodes []Node, indent bool) error {
 	return nil
 }
 
+// isLastNode returns true if there are no nodes after i, other than whitespace.
+func isLastNode(nodes []Node, i int) bool {
+	for _, n := range nodes[i+1:] {
+		if _, isWhitespace := n.(Whitespace); !isWhitespace {
+			return false
+		}
+	}
+	return true
+}
+
+// hasTrailingComment returns true if the node at i is followed by a single
+// line Go comment on the same line.
+func hasTrailingComment(nodes []Node, i int) bool {
+	wst, isWhitespaceTrailer := nodes[i].(WhitespaceTrailer)
+	if !isWhitespaceTrailer || wst.Trailing() != SpaceHorizontal || i+1 >= len(nodes) {
+		return false
+	}
+	c, isComment := nodes[i+1].(GoComment)
+	return isComment && !c.Multiline
+}
+
+// trailingCommentPadding returns the number of spaces to write between each
+// node and its trailing comment, so that the trailing comments of consecutive
+// lines are aligned, keyed by node index.
+func trailingCommentPadding(opts FormatOptions, nodes []Node) map[int]int {
+	padding := map[int]int{}
+	var run []int
+	widths := map[int]int{}
+	endRun := func() {
+		if len(run) > 1 {
+			var maxWidth int
+			for _, i := range run {
+				maxWidth = max(maxWidth, widths[i])
+			}
+			for _, i := range run {
+				padding[i] = 1 + maxWidth - widths[i]
+			}
+		}
+		run = nil
+	}
+	startsLine := true
+	for i := 0; i < len(nodes); i++ {
+		if ws, isWhitespace := nodes[i].(Whitespace); isWhitespace {
+			// A blank line ends the run.
+			if strings.Contains(ws.Value, "\n") {
+				endRun()
+			}
+			continue
+		}
+		if startsLine && hasTrailingComment(nodes, i) {
+			var sb strings.Builder
+			if err := writeFormatted(nodes[i], &sb, 0, opts); err == nil && !strings.Contains(sb.String(), "\n") {
+				widths[i] = utf8.RuneCountInString(sb.String())
+				run = append(run, i)
+				// Skip the comment, which ends the line.
+				i++
+				continue
+			}
+		}
+		endRun()
+		startsLine = true
+		if wst, isWhitespaceTrailer := nodes[i].(WhitespaceTrailer); isWhitespaceTrailer {
+			startsLine = wst.Trailing().IsVertical()
+		}
+	}
+	endRun()
+	return padding
+}
+
+// reindentComment returns the node with the lines of a multiline comment
+// shifted to the indentation level of the comment, keeping the alignment of
+// the lines relative to each other. Other nodes are returned unchanged.
+func reindentComment(n Node, indent int) Node {
+	switch c := n.(type) {
+	case GoComment:
+		if c.Multiline {
+			c.Contents = reindentLines(c.Contents, indent)
+		}
+		return c
+	case HTMLComment:
+		c.Contents = reindentLines(c.Contents, indent)
+		return c
+	}
+	return n
+}
+
+// reindentLines removes the tabs common to the start of all lines after the
+// first, and indents them to the given level. Blank lines are emptied, but
+// the last line is kept, since it contains the indentation of the comment's
+// closing sequence.
+func reindentLines(s string, indent int) string {
+	lines := strings.Split(s, "\n")
+	if len(lines) == 1 {
+		return s
+	}
+	common := -1
+	for i, l := range lines[1:] {
+		if strings.TrimSpace(l) == "" && i < len(lines)-2 {
+			continue
+		}
+		tabs := len(l) - len(strings.TrimLeft(l, "\t"))
+		if common < 0 || tabs < common {
+			common = tabs
+		}
+	}
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "" && i < len(lines)-1 {
+			lines[i] = ""
+			continue
+		}
+		lines[i] = strings.Repeat("\t", indent) + lines[i][common:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// blankLinesAfter returns the number of blank lines in the source after the node at i.
+func blankLinesAfter(nodes []Node, i int) int {
+	if wst, isWhitespaceTrailer := nodes[i].(WhitespaceTrailer); isWhitespaceTrailer {
+		return wst.Trailing().BlankLines()
+	}
+	if i+1 < len(nodes) {
+		if ws, isWhitespace := nodes[i+1].(Whitespace); isWhitespace {
+			return max(strings.Count(ws.Value, "\n")-1, 0)
+		}
+	}
+	return 0
+}
+
 func shouldAlwaysBreakAfter(node Node) bool {
 	if el, isElement := node.(Element); isElement {
 		return strings.EqualFold(el.Name, "br") || strings.EqualFold(el.Name, "hr")
@@ -668,6 +1055,14 @@ func isBlockNode(node Node) bool {
 		return true
 	case ForExpression:
 		return true
+	case DeferExpression:
+		return true
+	case InlineTemplate:
+		return true
+	case BlockExpression:
+		return true
+	case MarkdownBlock:
+		return true
 	case Element:
 		return n.IsBlockElement() || n.IndentChildren
 	}
@@ -678,10 +1073,263 @@ type RawElement struct {
 	Name       string
 	Attributes []Attribute
 	Contents   string
+	// Expressions are the Go expressions within the template literals of a
+	// JavaScript script element, e.g. `Hello, {{ name }}`, the expression
+	// that is the contents of a JSON script element, or the expressions
+	// within a CSS style element, e.g. color: {{ color }};, or the string
+	// expressions within a textarea or title element, e.g. { title }.
+	Expressions []ScriptExpression
 }
 
 func (e RawElement) IsNode() bool { return true }
+
+// ScriptExpression is a Go expression within a template literal of a script
+// element, or within a style element.
+type ScriptExpression struct {
+	// Start and End are the indexes of the {{ and }} within the contents.
+	Start, End int
+	Expression Expression
+	// InString is true if the expression is within a CSS string, e.g.
+	// content: "{{ label }}";.
+	InString bool
+	// Property is the CSS property whose value the expression is within, if
+	// any, e.g. --brand for --brand: {{ color }};.
+	Property string
+}
+
+// ScriptContents is a part of the contents of a script or style element.
+type ScriptContents struct {
+	// Value is JavaScript or CSS, if Expression is nil.
+	Value string
+	// Expression is a Go expression, whose value is escaped for the template
+	// literal or CSS that it's within, or encoded as JSON.
+	Expression *Expression
+	// InString is true if the expression is within a CSS string.
+	InString bool
+	// Property is the CSS property whose value the expression is within.
+	Property string
+}
+
+// SplitContents splits the contents of the element into JavaScript, and the
+// Go expressions within its template literals. Script end tags within the
+// strings and comments of JavaScript are escaped, so that they don't end the
+// element when it's output.
+func (e RawElement) SplitContents() (contents []ScriptContents) {
+	value := func(s string) ScriptContents {
+		if e.IsJavaScript() {
+			s = escapeScriptEndTags(s)
+		}
+		return ScriptContents{Value: s}
+	}
+	var start int
+	for i := range e.Expressions {
+		se := e.Expressions[i]
+		if se.Start > start {
+			contents = append(contents, value(e.Contents[start:se.Start]))
+		}
+		contents = append(contents, ScriptContents{Expression: &se.Expression, InString: se.InString, Property: se.Property})
+		start = se.End
+	}
+	if start < len(e.Contents) {
+		contents = append(contents, value(e.Contents[start:]))
+	}
+	return contents
+}
+
+// TransformContents returns the element with its contents transformed, e.g.
+// minified. The Go expressions are replaced with placeholders while the
+// contents are transformed, so that their positions within the transformed
+// contents are known.
+func (e RawElement) TransformContents(transform func(src string) (string, error)) (RawElement, error) {
+	placeholder := func(i int) string {
+		return fmt.Sprintf("templ_7745c5c3_ScriptExpression%d_", i)
+	}
+	var src strings.Builder
+	var start int
+	for i, se := range e.Expressions {
+		src.WriteString(e.Contents[start:se.Start])
+		src.WriteString(placeholder(i))
+		start = se.End
+	}
+	src.WriteString(e.Contents[start:])
+	transformed, err := transform(src.String())
+	if err != nil {
+		return e, err
+	}
+	// Keep the trailing newline of the transformed contents out of the output.
+	e.Contents = strings.TrimSuffix(transformed, "\n")
+	e.Expressions = slices.Clone(e.Expressions)
+	start = 0
+	for i := range e.Expressions {
+		p := placeholder(i)
+		index := strings.Index(e.Contents[start:], p)
+		if index < 0 {
+			return e, fmt.Errorf("the position of Go expression %q was lost", e.Expressions[i].Expression.Value)
+		}
+		e.Expressions[i].Start = start + index
+		e.Expressions[i].End = start + index + len(p)
+		start = e.Expressions[i].End
+	}
+	return e, nil
+}
+
+// IsJavaScript returns true if the element is a script element that contains
+// JavaScript, i.e. it has no type, or a constant JavaScript type.
+func (e RawElement) IsJavaScript() bool {
+	if !strings.EqualFold(e.Name, "script") || e.IsTypeScript() {
+		return false
+	}
+	t, ok := e.constantType()
+	return ok && isJavaScriptType(t)
+}
+
+// IsJSON returns true if the element is a script element that contains JSON,
+// e.g. <script type="application/json">, whose Go expression is encoded as
+// JSON.
+func (e RawElement) IsJSON() bool {
+	if !strings.EqualFold(e.Name, "script") {
+		return false
+	}
+	t, ok := e.constantType()
+	return ok && isJSONType(t)
+}
+
+// IsCSS returns true if the element is a style element that contains CSS,
+// i.e. it has no type, or a constant type of text/css.
+func (e RawElement) IsCSS() bool {
+	if !strings.EqualFold(e.Name, "style") {
+		return false
+	}
+	t, ok := e.constantType()
+	if !ok {
+		return false
+	}
+	t = strings.ToLower(strings.TrimSpace(t))
+	return t == "" || t == "text/css"
+}
+
+// StyleLang returns the language of the contents of a CSS style element,
+// set with the lang attribute, e.g. "scss" for <style lang="scss">, or an
+// empty string if the contents are CSS.
+func (e RawElement) StyleLang() string {
+	if !e.IsCSS() {
+		return ""
+	}
+	for _, attr := range e.Attributes {
+		if attr, ok := attr.(ConstantAttribute); ok && strings.EqualFold(attr.Name, "lang") {
+			if lang := strings.ToLower(strings.TrimSpace(attr.Value)); lang != "css" {
+				return lang
+			}
+		}
+	}
+	return ""
+}
+
+// CSSAttributes returns the attributes of a style element, without the lang
+// attribute, so that browsers use the preprocessed CSS.
+func (e RawElement) CSSAttributes() (attrs []Attribute) {
+	for _, attr := range e.Attributes {
+		if ca, ok := attr.(ConstantAttribute); ok && strings.EqualFold(ca.Name, "lang") {
+			continue
+		}
+		attrs = append(attrs, attr)
+	}
+	return attrs
+}
+
+// constantType returns the value of the type attribute, or false if it isn't
+// constant.
+func (e RawElement) constantType() (value string, ok bool) {
+	ok = true
+	for _, attr := range e.Attributes {
+		switch attr := attr.(type) {
+		case ConstantAttribute:
+			if strings.EqualFold(attr.Name, "type") {
+				value = attr.Value
+			}
+		case ExpressionAttribute:
+			if strings.EqualFold(attr.Name, "type") {
+				ok = false
+			}
+		case InterpolatedAttribute:
+			if strings.EqualFold(attr.Name, "type") {
+				ok = false
+			}
+		}
+	}
+	return value, ok
+}
+
+func isJSONType(value string) bool {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch {
+	case value == "application/json", value == "importmap", value == "speculationrules":
+		return true
+	case strings.HasPrefix(value, "application/") && strings.HasSuffix(value, "+json"):
+		return true
+	}
+	return false
+}
+
+func isJavaScriptType(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "module", "text/javascript", "application/javascript", "text/ecmascript", "application/ecmascript":
+		return true
+	}
+	return false
+}
+
+// IsEscapableRawText returns true if the element is a textarea or title
+// element, whose contents are text, even if they look like elements, e.g.
+// <title>{ name } | <Site></title>. String expressions within the text are
+// HTML escaped.
+func (e RawElement) IsEscapableRawText() bool {
+	return strings.EqualFold(e.Name, "textarea") || strings.EqualFold(e.Name, "title")
+}
+
+// IsTypeScript returns true if the element is a script element that contains
+// TypeScript, i.e. <script type="text/typescript"> or <script lang="ts">.
+func (e RawElement) IsTypeScript() bool {
+	if !strings.EqualFold(e.Name, "script") {
+		return false
+	}
+	for _, attr := range e.Attributes {
+		if attr, ok := attr.(ConstantAttribute); ok && isTypeScriptAttribute(attr) {
+			return true
+		}
+	}
+	return false
+}
+
+// JavaScriptAttributes returns the attributes of a TypeScript script element,
+// without the attributes that mark it as TypeScript, so that browsers run the
+// transpiled JavaScript.
+func (e RawElement) JavaScriptAttributes() (attrs []Attribute) {
+	for _, attr := range e.Attributes {
+		if ca, ok := attr.(ConstantAttribute); ok && isTypeScriptAttribute(ca) {
+			continue
+		}
+		attrs = append(attrs, attr)
+	}
+	return attrs
+}
+
+func isTypeScriptAttribute(attr ConstantAttribute) bool {
+	value := strings.ToLower(strings.TrimSpace(attr.Value))
+	switch strings.ToLower(attr.Name) {
+	case "type":
+		return value == "text/typescript" || value == "application/typescript"
+	case "lang":
+		return value == "ts" || value == "typescript"
+	}
+	return false
+}
 func (e RawElement) Write(w io.Writer, indent int) error {
+	return e.writeFormatted(w, indent, FormatOptions{})
+}
+
+func (e RawElement) writeFormatted(w io.Writer, indent int, opts FormatOptions) error {
+	e.Attributes = sortAttributes(e.Attributes, opts.SortAttributes)
 	// Start.
 	if err := writeIndent(w, indent, "<", e.Name); err != nil {
 		return err
@@ -692,7 +1340,7 @@ func (e RawElement) Write(w io.Writer, indent int) error {
 		}
 		a := e.Attributes[i]
 		// Don't indent the attributes, only the conditional attributes get indented.
-		if err := a.Write(w, 0); err != nil {
+		if err := writeFormatted(a, w, 0, opts); err != nil {
 			return err
 		}
 	}
@@ -715,6 +1363,17 @@ type Attribute interface {
 	Write(w io.Writer, indent int) error
 }
 
+// HasAttribute returns true if the named attribute is set, other than within
+// conditional attributes or spread attributes.
+func HasAttribute(attrs []Attribute, name string) bool {
+	for _, attr := range attrs {
+		if attrName, ok := attributeName(attr); ok && strings.EqualFold(attrName, name) {
+			return true
+		}
+	}
+	return false
+}
+
 // <hr noshade/>
 type BoolConstantAttribute struct {
 	Name      string
@@ -735,18 +1394,72 @@ type ConstantAttribute struct {
 	Value       string
 	SingleQuote bool
 	NameRange   Range
+	// Quote is the quote used around the value in the source.
+	Quote AttributeQuote
 }
 
+// AttributeQuote is the quote used around a constant attribute value.
+type AttributeQuote int
+
+const (
+	AttributeQuoteDouble AttributeQuote = iota
+	AttributeQuoteSingle
+	AttributeQuoteNone
+)
+
 func (ca ConstantAttribute) String() string {
+	return ca.quoted(QuoteStyleDouble)
+}
+
+// quoted returns the attribute, with its value quoted in the given style.
+// Quotes within the value are escaped, if the value can't be quoted with
+// the other quote character.
+func (ca ConstantAttribute) quoted(style QuoteStyle) string {
+	hasDouble, hasSingle := strings.Contains(ca.Value, `"`), strings.Contains(ca.Value, `'`)
 	quote := `"`
-	if ca.SingleQuote {
-		quote = `'`
+	switch style {
+	case QuoteStyleSingle:
+		if !hasSingle || hasDouble {
+			quote = `'`
+		}
+	case QuoteStylePreserve:
+		switch ca.Quote {
+		case AttributeQuoteSingle:
+			quote = `'`
+		case AttributeQuoteNone:
+			if isUnquotedAttributeValue(ca.Value) {
+				return ca.Name + `=` + ca.Value
+			}
+		}
+	default:
+		// Prefer double quotes, unless single quotes are required.
+		if ca.SingleQuote || hasDouble && !hasSingle {
+			quote = `'`
+		}
+	}
+	value := ca.Value
+	if quote == `"` && hasDouble {
+		value = strings.ReplaceAll(value, `"`, "&quot;")
 	}
-	return ca.Name + `=` + quote + ca.Value + quote
+	if quote == `'` && hasSingle {
+		value = strings.ReplaceAll(value, `'`, "&#39;")
+	}
+	return ca.Name + `=` + quote + value + quote
+}
+
+// isUnquotedAttributeValue returns true if the value can be written without quotes.
+func isUnquotedAttributeValue(s string) bool {
+	return s != "" && !strings.ContainsFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune("\"'=<>`{}&", r)
+	})
 }
 
 func (ca ConstantAttribute) Write(w io.Writer, indent int) error {
-	return writeIndent(w, indent, ca.String())
+	return ca.writeFormatted(w, indent, FormatOptions{})
+}
+
+func (ca ConstantAttribute) writeFormatted(w io.Writer, indent int, opts FormatOptions) error {
+	return writeIndent(w, indent, ca.quoted(opts.QuoteStyle))
 }
 
 // noshade={ templ.Bool(...) }
@@ -754,9 +1467,14 @@ type BoolExpressionAttribute struct {
 	Name       string
 	Expression Expression
 	NameRange  Range
+	// Prefixed is true if the attribute is written as ?name={ ... }.
+	Prefixed bool
 }
 
 func (bea BoolExpressionAttribute) String() string {
+	if bea.Prefixed {
+		return `?` + bea.Name + `={ ` + bea.Expression.Value + ` }`
+	}
 	return bea.Name + `?={ ` + bea.Expression.Value + ` }`
 }
 
@@ -823,6 +1541,66 @@ func (ea ExpressionAttribute) Write(w io.Writer, indent int) (err error) {
 	return writeIndent(w, indent, "}")
 }
 
+// class="btn btn-${ variant }"
+type InterpolatedAttribute struct {
+	Name      string
+	NameRange Range
+	// Parts of the value, in order. Each part is either constant text, or an
+	// expression.
+	Parts []AttributeValuePart
+}
+
+// AttributeValuePart is part of the value of an InterpolatedAttribute.
+type AttributeValuePart struct {
+	// Value is the text of a constant part.
+	Value string
+	// Expression is set if the part is an expression.
+	Expression *Expression
+}
+
+func (ia InterpolatedAttribute) String() string {
+	var sb strings.Builder
+	sb.WriteString(ia.Name)
+	sb.WriteString(`="`)
+	for _, p := range ia.Parts {
+		if p.Expression != nil {
+			sb.WriteString("${ ")
+			sb.WriteString(strings.TrimSpace(p.Expression.Value))
+			sb.WriteString(" }")
+			continue
+		}
+		sb.WriteString(strings.ReplaceAll(p.Value, `"`, "&quot;"))
+	}
+	sb.WriteString(`"`)
+	return sb.String()
+}
+
+func (ia InterpolatedAttribute) Write(w io.Writer, indent int) error {
+	return writeIndent(w, indent, ia.String())
+}
+
+// IsURLAttribute returns true if the value of attributes with the name is a
+// URL, so expressions within an InterpolatedAttribute are URL escaped.
+func IsURLAttribute(name string) bool {
+	switch strings.ToLower(name) {
+	case "href", "src", "action", "formaction":
+		return true
+	}
+	return false
+}
+
+// IsURLQuery returns true if part i of the attribute's value is within the
+// query or fragment of a URL, because an earlier constant part contains a ?
+// or #.
+func (ia InterpolatedAttribute) IsURLQuery(i int) bool {
+	for _, p := range ia.Parts[:i] {
+		if p.Expression == nil && strings.ContainsAny(p.Value, "?#") {
+			return true
+		}
+	}
+	return false
+}
+
 // <a { spread... } />
 type SpreadAttributes struct {
 	Expression Expression
@@ -836,6 +1614,23 @@ func (sa SpreadAttributes) Write(w io.Writer, indent int) error {
 	return writeIndent(w, indent, sa.String())
 }
 
+// <a { name }={ value }/>
+type DynamicAttribute struct {
+	// Name is a Go expression that returns the name of the attribute.
+	Name Expression
+	// Value is a Go expression that returns the value of the attribute, which
+	// can be any of the types of the values of templ.Attributes.
+	Value Expression
+}
+
+func (da DynamicAttribute) String() string {
+	return `{ ` + da.Name.Value + ` }={ ` + da.Value.Value + ` }`
+}
+
+func (da DynamicAttribute) Write(w io.Writer, indent int) error {
+	return writeIndent(w, indent, da.String())
+}
+
 //	<a href="test" \
 //		if active {
 //	   class="isActive"
@@ -853,6 +1648,10 @@ func (ca ConditionalAttribute) String() string {
 }
 
 func (ca ConditionalAttribute) Write(w io.Writer, indent int) error {
+	return ca.writeFormatted(w, indent, FormatOptions{})
+}
+
+func (ca ConditionalAttribute) writeFormatted(w io.Writer, indent int, opts FormatOptions) error {
 	if err := writeIndent(w, indent, "if "); err != nil {
 		return err
 	}
@@ -865,7 +1664,7 @@ func (ca ConditionalAttribute) Write(w io.Writer, indent int) error {
 	{
 		indent++
 		for _, attr := range ca.Then {
-			if err := attr.Write(w, indent); err != nil {
+			if err := writeFormatted(attr, w, indent, opts); err != nil {
 				return err
 			}
 			if _, err := w.Write([]byte("\n")); err != nil {
@@ -887,7 +1686,7 @@ func (ca ConditionalAttribute) Write(w io.Writer, indent int) error {
 	{
 		indent++
 		for _, attr := range ca.Else {
-			if err := attr.Write(w, indent); err != nil {
+			if err := writeFormatted(attr, w, indent, opts); err != nil {
 				return err
 			}
 			if _, err := w.Write([]byte("\n")); err != nil {
@@ -926,6 +1725,88 @@ func (c HTMLComment) Write(w io.Writer, indent int) error {
 	return writeIndent(w, indent, "<!--", c.Contents, "-->")
 }
 
+// RawBlock is content between <!-- templ:raw --> and <!-- templ:end -->
+// comments, which isn't parsed, and is output as it is, without the comments,
+// e.g. a third party snippet that contains braces.
+type RawBlock struct {
+	Contents string
+}
+
+func (b RawBlock) IsNode() bool { return true }
+func (b RawBlock) Write(w io.Writer, indent int) error {
+	return writeIndent(w, indent, "<!-- templ:raw -->", b.Contents, "<!-- templ:end -->")
+}
+
+// MarkdownBlock is Markdown that's rendered as HTML, e.g.
+//
+//	@markdown {
+//	  # Title
+//	}
+//
+// The contents aren't parsed, and don't include the indentation of the block.
+type MarkdownBlock struct {
+	Contents string
+}
+
+func (b MarkdownBlock) IsNode() bool { return true }
+func (b MarkdownBlock) Write(w io.Writer, indent int) error {
+	if err := writeIndent(w, indent, "@markdown {\n"); err != nil {
+		return err
+	}
+	if b.Contents != "" {
+		for _, line := range strings.Split(b.Contents, "\n") {
+			if line == "" {
+				if _, err := io.WriteString(w, "\n"); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := writeIndent(w, indent+1, line, "\n"); err != nil {
+				return err
+			}
+		}
+	}
+	return writeIndent(w, indent, "}")
+}
+
+// CDATA is a CDATA section, e.g. <![CDATA[ ... ]]>, which is used in XML,
+// such as RSS feeds and SVG. The contents are output as they are, apart from
+// the Go expressions within them, e.g. {{ post.Body }}.
+type CDATA struct {
+	Contents string
+	// Expressions are the Go expressions within the contents.
+	Expressions []ScriptExpression
+	// TrailingSpace lists what happens after the section.
+	TrailingSpace TrailingSpace
+}
+
+func (c CDATA) Trailing() TrailingSpace {
+	return c.TrailingSpace
+}
+
+func (c CDATA) IsNode() bool { return true }
+func (c CDATA) Write(w io.Writer, indent int) error {
+	return writeIndent(w, indent, "<![CDATA[", c.Contents, "]]>")
+}
+
+// SplitContents splits the contents of the section into text, and the Go
+// expressions within it.
+func (c CDATA) SplitContents() (contents []ScriptContents) {
+	var start int
+	for i := range c.Expressions {
+		se := c.Expressions[i]
+		if se.Start > start {
+			contents = append(contents, ScriptContents{Value: c.Contents[start:se.Start]})
+		}
+		contents = append(contents, ScriptContents{Expression: &se.Expression})
+		start = se.End
+	}
+	if start < len(c.Contents) {
+		contents = append(contents, ScriptContents{Value: c.Contents[start:]})
+	}
+	return contents
+}
+
 // Nodes.
 
 // CallTemplateExpression can be used to create and render a template using data.
@@ -959,6 +1840,10 @@ func (tee TemplElementExpression) ChildNodes() []Node {
 }
 func (tee TemplElementExpression) IsNode() bool { return true }
 func (tee TemplElementExpression) Write(w io.Writer, indent int) error {
+	return tee.writeFormatted(w, indent, FormatOptions{})
+}
+
+func (tee TemplElementExpression) writeFormatted(w io.Writer, indent int, opts FormatOptions) error {
 	source, err := format.Source([]byte(tee.Expression.Value))
 	if err != nil {
 		source = []byte(tee.Expression.Value)
@@ -972,7 +1857,7 @@ func (tee TemplElementExpression) Write(w io.Writer, indent int) error {
 	if _, err = io.WriteString(w, " {\n"); err != nil {
 		return err
 	}
-	if err := writeNodesIndented(w, indent+1, tee.Children); err != nil {
+	if err := writeNodesIndented(w, indent+1, tee.Children, opts); err != nil {
 		return err
 	}
 	if err := writeIndent(w, indent, "}"); err != nil {
@@ -1018,11 +1903,15 @@ func (n IfExpression) ChildNodes() []Node {
 }
 func (n IfExpression) IsNode() bool { return true }
 func (n IfExpression) Write(w io.Writer, indent int) error {
+	return n.writeFormatted(w, indent, FormatOptions{})
+}
+
+func (n IfExpression) writeFormatted(w io.Writer, indent int, opts FormatOptions) error {
 	if err := writeIndent(w, indent, "if ", n.Expression.Value, " {\n"); err != nil {
 		return err
 	}
 	indent++
-	if err := writeNodesIndented(w, indent, n.Then); err != nil {
+	if err := writeNodesIndented(w, indent, n.Then, opts); err != nil {
 		return err
 	}
 	indent--
@@ -1031,7 +1920,7 @@ func (n IfExpression) Write(w io.Writer, indent int) error {
 			return err
 		}
 		indent++
-		if err := writeNodesIndented(w, indent, elseIf.Then); err != nil {
+		if err := writeNodesIndented(w, indent, elseIf.Then, opts); err != nil {
 			return err
 		}
 		indent--
@@ -1040,7 +1929,7 @@ func (n IfExpression) Write(w io.Writer, indent int) error {
 		if err := writeIndent(w, indent, "} else {\n"); err != nil {
 			return err
 		}
-		if err := writeNodesIndented(w, indent+1, n.Else); err != nil {
+		if err := writeNodesIndented(w, indent+1, n.Else, opts); err != nil {
 			return err
 		}
 	}
@@ -1054,6 +1943,8 @@ func (n IfExpression) Write(w io.Writer, indent int) error {
 //	 case "Something":
 //	}
 type SwitchExpression struct {
+	// Label is the optional label of the switch statement, e.g. outer: switch ...
+	Label      Expression
 	Expression Expression
 	Cases      []CaseExpression
 }
@@ -1067,7 +1958,11 @@ func (se SwitchExpression) ChildNodes() []Node {
 }
 func (se SwitchExpression) IsNode() bool { return true }
 func (se SwitchExpression) Write(w io.Writer, indent int) error {
-	if err := writeIndent(w, indent, "switch ", se.Expression.Value, " {\n"); err != nil {
+	return se.writeFormatted(w, indent, FormatOptions{})
+}
+
+func (se SwitchExpression) writeFormatted(w io.Writer, indent int, opts FormatOptions) error {
+	if err := writeIndent(w, indent, labelPrefix(se.Label), "switch ", se.Expression.Value, " {\n"); err != nil {
 		return err
 	}
 	indent++
@@ -1076,7 +1971,7 @@ func (se SwitchExpression) Write(w io.Writer, indent int) error {
 		if err := writeIndent(w, indent, c.Expression.Value, "\n"); err != nil {
 			return err
 		}
-		if err := writeNodesIndented(w, indent+1, c.Children); err != nil {
+		if err := writeNodesIndented(w, indent+1, c.Children, opts); err != nil {
 			return err
 		}
 	}
@@ -1097,19 +1992,173 @@ type CaseExpression struct {
 //	  {! Address(v) }
 //	}
 type ForExpression struct {
+	// Label is the optional label of the for statement, e.g. outer: for ...
+	Label      Expression
 	Expression Expression
 	Children   []Node
+	// Else is rendered if the loop body isn't, e.g. because the collection is
+	// empty.
+	Else []Node
 }
 
 func (fe ForExpression) ChildNodes() []Node {
-	return fe.Children
+	var nodes []Node
+	nodes = append(nodes, fe.Children...)
+	nodes = append(nodes, fe.Else...)
+	return nodes
 }
 func (fe ForExpression) IsNode() bool { return true }
 func (fe ForExpression) Write(w io.Writer, indent int) error {
-	if err := writeIndent(w, indent, "for ", fe.Expression.Value, " {\n"); err != nil {
+	return fe.writeFormatted(w, indent, FormatOptions{})
+}
+
+func (fe ForExpression) writeFormatted(w io.Writer, indent int, opts FormatOptions) error {
+	if err := writeIndent(w, indent, labelPrefix(fe.Label), "for ", fe.Expression.Value, " {\n"); err != nil {
+		return err
+	}
+	if err := writeNodesIndented(w, indent+1, fe.Children, opts); err != nil {
+		return err
+	}
+	if len(fe.Else) > 0 {
+		if err := writeIndent(w, indent, "} else {\n"); err != nil {
+			return err
+		}
+		if err := writeNodesIndented(w, indent+1, fe.Else, opts); err != nil {
+			return err
+		}
+	}
+	if err := writeIndent(w, indent, "}"); err != nil {
+		return err
+	}
+	return nil
+}
+
+func labelPrefix(label Expression) string {
+	if label.Value == "" {
+		return ""
+	}
+	return label.Value + ": "
+}
+
+// BranchStatement is a break or continue statement, with an optional label, or
+// a fallthrough statement.
+//
+//	break
+//	continue outer
+type BranchStatement struct {
+	Expression Expression
+}
+
+func (bs BranchStatement) IsNode() bool { return true }
+func (bs BranchStatement) Write(w io.Writer, indent int) error {
+	return writeIndent(w, indent, bs.Expression.Value)
+}
+
+// IncludeExpression splices the contents of another file into the template
+// when the template is generated.
+//
+//	@include "partials/footer.templ"
+type IncludeExpression struct {
+	// Path is the quoted path of the included file, relative to the including file.
+	Path Expression
+}
+
+func (ie IncludeExpression) IsNode() bool { return true }
+func (ie IncludeExpression) Write(w io.Writer, indent int) error {
+	return writeIndent(w, indent, "@include ", ie.Path.Value)
+}
+
+// FilePath returns the unquoted path of the included file.
+func (ie IncludeExpression) FilePath() (string, error) {
+	return strconv.Unquote(ie.Path.Value)
+}
+
+// DeferExpression contains nodes that are rendered at the end of the
+// component, after all other content.
+//
+//	defer {
+//	  <script src="app.js"></script>
+//	}
+type DeferExpression struct {
+	Children []Node
+}
+
+func (de DeferExpression) ChildNodes() []Node {
+	return de.Children
+}
+func (de DeferExpression) IsNode() bool { return true }
+func (de DeferExpression) Write(w io.Writer, indent int) error {
+	return de.writeFormatted(w, indent, FormatOptions{})
+}
+
+func (de DeferExpression) writeFormatted(w io.Writer, indent int, opts FormatOptions) error {
+	if err := writeIndent(w, indent, "defer {\n"); err != nil {
+		return err
+	}
+	if err := writeNodesIndented(w, indent+1, de.Children, opts); err != nil {
+		return err
+	}
+	if err := writeIndent(w, indent, "}"); err != nil {
+		return err
+	}
+	return nil
+}
+
+// BlockExpression is a named block, e.g. block content { ... }. Within a
+// template, it renders the block of the same name that's passed to the
+// template, or its children if there isn't one. As a child of a templ element,
+// e.g. @layout() { block content { ... } }, it overrides the block of the
+// template.
+type BlockExpression struct {
+	Name      string
+	NameRange Range
+	Children  []Node
+}
+
+func (be BlockExpression) ChildNodes() []Node {
+	return be.Children
+}
+func (be BlockExpression) IsNode() bool { return true }
+func (be BlockExpression) Write(w io.Writer, indent int) error {
+	return be.writeFormatted(w, indent, FormatOptions{})
+}
+
+func (be BlockExpression) writeFormatted(w io.Writer, indent int, opts FormatOptions) error {
+	if err := writeIndent(w, indent, "block ", be.Name, " {\n"); err != nil {
+		return err
+	}
+	if err := writeNodesIndented(w, indent+1, be.Children, opts); err != nil {
+		return err
+	}
+	if err := writeIndent(w, indent, "}"); err != nil {
+		return err
+	}
+	return nil
+}
+
+// InlineTemplate is a template that's declared within the body of another
+// template, e.g. templ row(item Item) { ... }. It's a closure, so it can use
+// the parameters and variables of the template it's declared in.
+type InlineTemplate struct {
+	// Expression is the name and parameters of the template, e.g. row(item Item).
+	Expression Expression
+	Children   []Node
+}
+
+func (it InlineTemplate) ChildNodes() []Node {
+	return it.Children
+}
+func (it InlineTemplate) IsNode() bool { return true }
+func (it InlineTemplate) Write(w io.Writer, indent int) error {
+	return it.writeFormatted(w, indent, FormatOptions{})
+}
+
+func (it InlineTemplate) writeFormatted(w io.Writer, indent int, opts FormatOptions) error {
+	source := formatFunctionArguments(it.Expression.Value)
+	if err := writeIndent(w, indent, "templ ", string(source), " {\n"); err != nil {
 		return err
 	}
-	if err := writeNodesIndented(w, indent+1, fe.Children); err != nil {
+	if err := writeNodesIndented(w, indent+1, it.Children, opts); err != nil {
 		return err
 	}
 	if err := writeIndent(w, indent, "}"); err != nil {
@@ -1133,13 +2182,20 @@ func (gc GoCode) Trailing() TrailingSpace {
 
 func (gc GoCode) IsNode() bool { return true }
 func (gc GoCode) Write(w io.Writer, indent int) error {
+	return gc.writeFormatted(w, indent, FormatOptions{})
+}
+
+func (gc GoCode) writeFormatted(w io.Writer, indent int, opts FormatOptions) error {
 	if isWhitespace(gc.Expression.Value) {
 		gc.Expression.Value = ""
 	}
 	if !gc.Multiline {
 		return writeIndent(w, indent, `{{ `, gc.Expression.Value, ` }}`)
 	}
-	formatted, err := format.Source([]byte(gc.Expression.Value))
+	// The statements start on the line after the {{, even if they started on
+	// the same line in the source, and are indented within the braces.
+	src := "\n" + strings.Repeat("\t", indent+1) + strings.TrimSpace(gc.Expression.Value)
+	formatted, err := opts.formatGoStatements(src)
 	if err != nil {
 		return err
 	}
@@ -1155,6 +2211,14 @@ type StringExpression struct {
 	Expression Expression
 	// TrailingSpace lists what happens after the expression.
 	TrailingSpace TrailingSpace
+	// TrimBefore is true if the expression starts with the {- trim marker,
+	// which removes the whitespace between the expression and the node
+	// before it from the output.
+	TrimBefore bool
+	// TrimAfter is true if the expression ends with the -} trim marker,
+	// which removes the whitespace between the expression and the node
+	// after it from the output.
+	TrimAfter bool
 }
 
 func (se StringExpression) Trailing() TrailingSpace {
@@ -1167,7 +2231,18 @@ func (se StringExpression) Write(w io.Writer, indent int) error {
 	if isWhitespace(se.Expression.Value) {
 		se.Expression.Value = ""
 	}
-	return writeIndent(w, indent, `{ `, se.Expression.Value, ` }`)
+	open, close := `{ `, ` }`
+	if se.TrimBefore {
+		open = `{- `
+	}
+	if se.TrimAfter {
+		close = ` -}`
+	}
+	if se.Expression.Value == "" && se.TrimBefore && se.TrimAfter {
+		// {- -}
+		close = `-}`
+	}
+	return writeIndent(w, indent, open, se.Expression.Value, close)
 }
 
 // ScriptTemplate is a script block.
@@ -0,0 +1,50 @@
+package parser
+
+import "strings"
+
+// xmlPragma is a Go comment that marks templates as generating XML. Before
+// the package, it applies to all of the templates of the file, otherwise it
+// applies to the template on the next line.
+const xmlPragma = "// templ:xml"
+
+// IsXML returns true if the template at index i of the file's nodes generates
+// XML, e.g. a sitemap, RSS feed or SVG document, instead of HTML. Templates
+// generate XML if the file's header contains the templ:xml pragma, the line
+// before the template is the pragma, or the template starts with an XML
+// declaration, e.g. <?xml version="1.0"?>.
+//
+// Within XML, elements without children are self-closing, e.g. <link/>, and
+// the elements that are void in HTML, e.g. <link>, aren't treated
+// differently to other elements.
+func (tf TemplateFile) IsXML(i int) bool {
+	if i < 0 || i >= len(tf.Nodes) {
+		return false
+	}
+	t, ok := tf.Nodes[i].(HTMLTemplate)
+	if !ok {
+		return false
+	}
+	for _, h := range tf.Header {
+		for _, line := range strings.Split(h.Expression.Value, "\n") {
+			if strings.TrimSpace(line) == xmlPragma {
+				return true
+			}
+		}
+	}
+	if i > 0 {
+		if e, ok := tf.Nodes[i-1].(TemplateFileGoExpression); ok {
+			lines := strings.Split(strings.TrimRight(e.Expression.Value, " \t\r\n"), "\n")
+			if strings.TrimSpace(lines[len(lines)-1]) == xmlPragma {
+				return true
+			}
+		}
+	}
+	for _, n := range t.Children {
+		if _, ok := n.(Whitespace); ok {
+			continue
+		}
+		pi, ok := n.(ProcessingInstruction)
+		return ok && pi.IsXMLDeclaration()
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"testing"
+)
+
+func TestIsXML(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{
+			name: "HTML templates aren't XML",
+			input: `package p
+
+templ page() {
+	<link rel="stylesheet" href="/styles.css">
+}`,
+			expected: false,
+		},
+		{
+			name: "templates that start with an XML declaration are XML",
+			input: `package p
+
+templ sitemap() {
+	<?xml version="1.0" encoding="UTF-8"?>
+	<urlset></urlset>
+}`,
+			expected: true,
+		},
+		{
+			name: "other processing instructions don't make the template XML",
+			input: `package p
+
+templ page() {
+	<?xml-stylesheet href="/feed.xsl" type="text/xsl"?>
+}`,
+			expected: false,
+		},
+		{
+			name: "the pragma before a template makes it XML",
+			input: `package p
+
+// icon is an SVG document.
+// templ:xml
+templ icon() {
+	<svg></svg>
+}`,
+			expected: true,
+		},
+		{
+			name: "the pragma before the package makes all templates XML",
+			input: `// templ:xml
+
+package p
+
+templ icon() {
+	<svg></svg>
+}`,
+			expected: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tf, err := ParseString(tt.input)
+			if err != nil {
+				t.Fatalf("failed to parse: %v", err)
+			}
+			i := len(tf.Nodes) - 1
+			if actual := tf.IsXML(i); actual != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, actual)
+			}
+		})
+	}
+}
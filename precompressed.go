@@ -0,0 +1,66 @@
+package templ
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// PrecompressedContent is the compressed output of a component that has no
+// dynamic content, generated by templ generate with the -precompress flag.
+type PrecompressedContent struct {
+	// Gzip is the output compressed with gzip.
+	Gzip string
+	// Brotli is the output compressed with brotli.
+	Brotli string
+}
+
+// PrecompressedComponent is a component with precompressed output. When it's
+// rendered by a ComponentHandler, and the client accepts gzip or brotli, the
+// compressed output is written, instead of rendering the component.
+type PrecompressedComponent struct {
+	Component
+	Content PrecompressedContent
+}
+
+// Precompressed returns a component that renders c, with precompressed output.
+// It's used by generated code.
+func Precompressed(content PrecompressedContent, c Component) *PrecompressedComponent {
+	return &PrecompressedComponent{
+		Component: c,
+		Content:   content,
+	}
+}
+
+// encoding returns the Content-Encoding of the compressed output that's
+// accepted by the request, preferring brotli, and the output.
+func (pc *PrecompressedComponent) encoding(r *http.Request) (encoding, content string, ok bool) {
+	accepted := acceptedEncodings(r.Header.Get("Accept-Encoding"))
+	if accepted["br"] && pc.Content.Brotli != "" {
+		return "br", pc.Content.Brotli, true
+	}
+	if accepted["gzip"] && pc.Content.Gzip != "" {
+		return "gzip", pc.Content.Gzip, true
+	}
+	return "", "", false
+}
+
+// acceptedEncodings parses an Accept-Encoding header, e.g. "gzip, br;q=0.8".
+// Encodings with a quality of zero are not accepted.
+func acceptedEncodings(header string) (accepted map[string]bool) {
+	accepted = map[string]bool{}
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if v, err := strconv.ParseFloat(q, 64); err == nil && v == 0 {
+				continue
+			}
+		}
+		accepted[name] = true
+	}
+	return accepted
+}
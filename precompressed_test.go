@@ -0,0 +1,86 @@
+package templ_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/a-h/templ"
+)
+
+func TestPrecompressedHandler(t *testing.T) {
+	component := templ.Precompressed(templ.PrecompressedContent{
+		Gzip:   "gzip content",
+		Brotli: "brotli content",
+	}, templ.Raw("<p>content</p>"))
+	tests := []struct {
+		name             string
+		acceptEncoding   string
+		nonce            string
+		expectedEncoding string
+		expectedBody     string
+	}{
+		{
+			name:         "the component is rendered if the client doesn't accept compressed content",
+			expectedBody: "<p>content</p>",
+		},
+		{
+			name:             "gzip content is written if the client accepts gzip",
+			acceptEncoding:   "gzip, deflate",
+			expectedEncoding: "gzip",
+			expectedBody:     "gzip content",
+		},
+		{
+			name:             "brotli content is preferred",
+			acceptEncoding:   "gzip, deflate, br",
+			expectedEncoding: "br",
+			expectedBody:     "brotli content",
+		},
+		{
+			name:             "encodings with a quality of zero are not accepted",
+			acceptEncoding:   "gzip;q=1.0, br;q=0",
+			expectedEncoding: "gzip",
+			expectedBody:     "gzip content",
+		},
+		{
+			name:           "the component is rendered if the request has a CSP nonce",
+			acceptEncoding: "gzip, deflate, br",
+			nonce:          "abc",
+			expectedBody:   "<p>content</p>",
+		},
+		{
+			name:           "unsupported encodings are ignored",
+			acceptEncoding: "deflate",
+			expectedBody:   "<p>content</p>",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.acceptEncoding != "" {
+				r.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			if tt.nonce != "" {
+				r = r.WithContext(templ.WithNonce(r.Context(), tt.nonce))
+			}
+			templ.Handler(component, templ.WithStatus(http.StatusAccepted)).ServeHTTP(w, r)
+			if w.Code != http.StatusAccepted {
+				t.Errorf("expected status %d, got %d", http.StatusAccepted, w.Code)
+			}
+			if actual := w.Header().Get("Content-Encoding"); actual != tt.expectedEncoding {
+				t.Errorf("expected encoding %q, got %q", tt.expectedEncoding, actual)
+			}
+			if actual := w.Header().Get("Vary"); actual != "Accept-Encoding" {
+				t.Errorf("expected Vary: Accept-Encoding, got %q", actual)
+			}
+			if actual := w.Header().Get("Content-Type"); actual != "text/html; charset=utf-8" {
+				t.Errorf("unexpected content type %q", actual)
+			}
+			if actual := w.Body.String(); actual != tt.expectedBody {
+				t.Errorf("expected body %q, got %q", tt.expectedBody, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+// Package rediscache provides a templ.FragmentCache that stores rendered
+// fragments in Redis, so that they're shared between instances of an app.
+//
+//	cache := rediscache.New("localhost:6379")
+//	fragments := templ.NewCachedFragments(templ.WithCacheVersion(cache, commitHash))
+//
+// The cache uses the GET and SET commands, so it also works with servers that
+// are compatible with Redis, e.g. Valkey, KeyDB and Dragonfly.
+package rediscache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/a-h/templ"
+)
+
+var _ templ.FragmentCache = &Cache{}
+
+// Cache is a templ.FragmentCache that stores fragments in Redis.
+type Cache struct {
+	// Addr is the host and port of the server, e.g. "localhost:6379".
+	Addr string
+	// Username and Password are used to authenticate, if the password is set.
+	Username string
+	Password string
+	// DB is the number of the database to use.
+	DB int
+	// Prefix is added to the start of each key, e.g. "fragments:".
+	Prefix string
+	// MaxIdle is the maximum number of idle connections kept open. New sets it to 10.
+	MaxIdle int
+	// DialTimeout is the maximum time taken to connect. New sets it to 5 seconds.
+	DialTimeout time.Duration
+
+	m      sync.Mutex
+	idle   []*conn
+	closed bool
+}
+
+// New creates a cache that connects to the Redis server at addr.
+func New(addr string) *Cache {
+	return &Cache{
+		Addr:        addr,
+		MaxIdle:     10,
+		DialTimeout: 5 * time.Second,
+	}
+}
+
+// Get returns the fragment for the key.
+func (c *Cache) Get(ctx context.Context, key string) (value []byte, ok bool, err error) {
+	value, isNil, err := c.do(ctx, "GET", c.Prefix+key)
+	if err != nil {
+		return nil, false, err
+	}
+	return value, !isNil, nil
+}
+
+// Set stores the fragment for the key, with an expiry of the ttl, if it's set.
+func (c *Cache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) (err error) {
+	args := []string{"SET", c.Prefix + key, string(value)}
+	if ttl > 0 {
+		ms := ttl.Milliseconds()
+		if ms < 1 {
+			ms = 1
+		}
+		args = append(args, "PX", strconv.FormatInt(ms, 10))
+	}
+	_, _, err = c.do(ctx, args...)
+	return err
+}
+
+// Close closes the idle connections. Connections in use are closed when
+// they're returned.
+func (c *Cache) Close() (err error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.closed = true
+	for _, cn := range c.idle {
+		err = errors.Join(err, cn.Close())
+	}
+	c.idle = nil
+	return err
+}
+
+// Error is an error reply from the server.
+type Error string
+
+func (e Error) Error() string {
+	return "rediscache: " + string(e)
+}
+
+type conn struct {
+	net.Conn
+	r *bufio.Reader
+	w *bufio.Writer
+}
+
+// do sends a command, and returns the reply. isNil is true if the reply is a
+// nil bulk string, e.g. when a key doesn't exist.
+func (c *Cache) do(ctx context.Context, args ...string) (reply []byte, isNil bool, err error) {
+	cn, err := c.get(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	reply, isNil, err = cn.do(ctx, args...)
+	var redisErr Error
+	if err != nil && !errors.As(err, &redisErr) {
+		// The state of the connection is unknown after an I/O error.
+		cn.Close()
+		return nil, false, err
+	}
+	c.put(cn)
+	return reply, isNil, err
+}
+
+func (c *Cache) get(ctx context.Context) (cn *conn, err error) {
+	c.m.Lock()
+	if n := len(c.idle); n > 0 {
+		cn = c.idle[n-1]
+		c.idle = c.idle[:n-1]
+	}
+	c.m.Unlock()
+	if cn != nil {
+		return cn, nil
+	}
+	return c.dial(ctx)
+}
+
+func (c *Cache) put(cn *conn) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed || len(c.idle) >= c.MaxIdle {
+		cn.Close()
+		return
+	}
+	c.idle = append(c.idle, cn)
+}
+
+func (c *Cache) dial(ctx context.Context) (cn *conn, err error) {
+	d := net.Dialer{Timeout: c.DialTimeout}
+	nc, err := d.DialContext(ctx, "tcp", c.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("rediscache: failed to connect: %w", err)
+	}
+	cn = &conn{Conn: nc, r: bufio.NewReader(nc), w: bufio.NewWriter(nc)}
+	if c.Password != "" {
+		args := []string{"AUTH", c.Password}
+		if c.Username != "" {
+			args = []string{"AUTH", c.Username, c.Password}
+		}
+		if _, _, err = cn.do(ctx, args...); err != nil {
+			cn.Close()
+			return nil, err
+		}
+	}
+	if c.DB != 0 {
+		if _, _, err = cn.do(ctx, "SELECT", strconv.Itoa(c.DB)); err != nil {
+			cn.Close()
+			return nil, err
+		}
+	}
+	return cn, nil
+}
+
+func (cn *conn) do(ctx context.Context, args ...string) (reply []byte, isNil bool, err error) {
+	deadline, _ := ctx.Deadline()
+	if err = cn.SetDeadline(deadline); err != nil {
+		return nil, false, err
+	}
+	// Commands are sent as an array of bulk strings.
+	fmt.Fprintf(cn.w, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(cn.w, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if err = cn.w.Flush(); err != nil {
+		return nil, false, err
+	}
+	return readReply(cn.r)
+}
+
+func readReply(r *bufio.Reader) (reply []byte, isNil bool, err error) {
+	b, err := r.ReadSlice('\n')
+	if err != nil {
+		return nil, false, err
+	}
+	if len(b) < 3 || b[len(b)-2] != '\r' {
+		return nil, false, fmt.Errorf("rediscache: invalid reply %q", b)
+	}
+	kind, line := b[0], string(b[1:len(b)-2])
+	switch kind {
+	case '+', ':':
+		return []byte(line), false, nil
+	case '-':
+		return nil, false, Error(line)
+	case '$':
+		n, err := strconv.Atoi(line)
+		if err != nil {
+			return nil, false, fmt.Errorf("rediscache: invalid bulk string length %q", line)
+		}
+		if n < 0 {
+			return nil, true, nil
+		}
+		reply = make([]byte, n+2)
+		if _, err = io.ReadFull(r, reply); err != nil {
+			return nil, false, err
+		}
+		return reply[:n], false, nil
+	}
+	return nil, false, fmt.Errorf("rediscache: unexpected reply type %q", kind)
+}
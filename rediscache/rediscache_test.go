@@ -0,0 +1,227 @@
+package rediscache
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/a-h/templ"
+)
+
+// server is a minimal Redis server that supports the commands used by the cache.
+type server struct {
+	l        net.Listener
+	password string
+
+	m        sync.Mutex
+	values   map[string]string
+	expiry   map[string]time.Duration
+	commands []string
+	conns    int
+}
+
+func newServer(t *testing.T, password string) *server {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("failed to listen: %v", err)
+	}
+	s := &server{l: l, password: password, values: map[string]string{}, expiry: map[string]time.Duration{}}
+	go s.serve()
+	t.Cleanup(func() { l.Close() })
+	return s
+}
+
+func (s *server) serve() {
+	for {
+		c, err := s.l.Accept()
+		if err != nil {
+			return
+		}
+		s.m.Lock()
+		s.conns++
+		s.m.Unlock()
+		go s.handle(c)
+	}
+}
+
+func (s *server) handle(c net.Conn) {
+	defer c.Close()
+	r := bufio.NewReader(c)
+	authenticated := s.password == ""
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		s.m.Lock()
+		s.commands = append(s.commands, args[0])
+		var reply string
+		switch {
+		case args[0] == "AUTH":
+			authenticated = args[len(args)-1] == s.password
+			reply = "+OK\r\n"
+			if !authenticated {
+				reply = "-WRONGPASS invalid password\r\n"
+			}
+		case !authenticated:
+			reply = "-NOAUTH Authentication required.\r\n"
+		case args[0] == "SELECT":
+			reply = "+OK\r\n"
+		case args[0] == "GET":
+			if v, ok := s.values[args[1]]; ok {
+				reply = fmt.Sprintf("$%d\r\n%s\r\n", len(v), v)
+			} else {
+				reply = "$-1\r\n"
+			}
+		case args[0] == "SET":
+			s.values[args[1]] = args[2]
+			if len(args) == 5 && args[3] == "PX" {
+				ms, _ := strconv.Atoi(args[4])
+				s.expiry[args[1]] = time.Duration(ms) * time.Millisecond
+			}
+			reply = "+OK\r\n"
+		default:
+			reply = "-ERR unknown command\r\n"
+		}
+		s.m.Unlock()
+		if _, err = io.WriteString(c, reply); err != nil {
+			return
+		}
+	}
+}
+
+func readCommand(r *bufio.Reader) (args []string, err error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < n; i++ {
+		line, err = r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+		if err != nil {
+			return nil, err
+		}
+		arg := make([]byte, size+2)
+		if _, err = io.ReadFull(r, arg); err != nil {
+			return nil, err
+		}
+		args = append(args, string(arg[:size]))
+	}
+	return args, nil
+}
+
+func TestCache(t *testing.T) {
+	s := newServer(t, "secret")
+	c := New(s.l.Addr().String())
+	c.Password = "secret"
+	c.DB = 2
+	c.Prefix = "fragments:"
+	defer c.Close()
+	ctx := context.Background()
+
+	if _, ok, err := c.Get(ctx, "missing"); err != nil || ok {
+		t.Fatalf("expected a miss, got ok=%v, err=%v", ok, err)
+	}
+	value := []byte("<p>Hello\r\nWorld</p>")
+	if err := c.Set(ctx, "a", value, time.Minute); err != nil {
+		t.Fatalf("failed to set: %v", err)
+	}
+	actual, ok, err := c.Get(ctx, "a")
+	if err != nil || !ok {
+		t.Fatalf("expected a hit, got ok=%v, err=%v", ok, err)
+	}
+	if !bytes.Equal(value, actual) {
+		t.Errorf("expected %q, got %q", value, actual)
+	}
+	if err := c.Set(ctx, "empty", nil, 0); err != nil {
+		t.Fatalf("failed to set: %v", err)
+	}
+	if actual, ok, err := c.Get(ctx, "empty"); err != nil || !ok || len(actual) != 0 {
+		t.Errorf("expected an empty hit, got %q, ok=%v, err=%v", actual, ok, err)
+	}
+
+	s.m.Lock()
+	defer s.m.Unlock()
+	if _, ok := s.values["fragments:a"]; !ok {
+		t.Errorf("expected the key to be prefixed, got %v", s.values)
+	}
+	if s.expiry["fragments:a"] != time.Minute {
+		t.Errorf("expected an expiry of 1m, got %v", s.expiry["fragments:a"])
+	}
+	if _, ok := s.expiry["fragments:empty"]; ok {
+		t.Error("expected no expiry without a ttl")
+	}
+	expected := "AUTH SELECT GET SET GET SET GET"
+	if actual := strings.Join(s.commands, " "); actual != expected {
+		t.Errorf("expected commands %q, got %q", expected, actual)
+	}
+	if s.conns != 1 {
+		t.Errorf("expected the connection to be reused, got %d connections", s.conns)
+	}
+}
+
+func TestCacheErrors(t *testing.T) {
+	s := newServer(t, "secret")
+	c := New(s.l.Addr().String())
+	defer c.Close()
+	_, _, err := c.Get(context.Background(), "a")
+	var redisErr Error
+	if !errors.As(err, &redisErr) || !strings.HasPrefix(string(redisErr), "NOAUTH") {
+		t.Errorf("expected a NOAUTH error, got %v", err)
+	}
+
+	t.Run("fragments are rendered if the server is unavailable", func(t *testing.T) {
+		c := New("127.0.0.1:1")
+		c.DialTimeout = time.Second
+		fragments := templ.NewCachedFragments(c)
+		var buf bytes.Buffer
+		if err := fragments.Fragment("a", 0, templ.Raw("<p>a</p>")).Render(context.Background(), &buf); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if buf.String() != "<p>a</p>" {
+			t.Errorf("unexpected output: %q", buf.String())
+		}
+		if stats := fragments.Stats(); stats.Errors != 2 || stats.Misses != 1 {
+			t.Errorf("unexpected stats: %+v", stats)
+		}
+	})
+}
+
+func TestCachedFragments(t *testing.T) {
+	s := newServer(t, "")
+	cache := templ.WithCacheVersion(New(s.l.Addr().String()), "v1")
+	fragments := templ.NewCachedFragments(cache)
+	for i := 0; i < 2; i++ {
+		var buf bytes.Buffer
+		if err := fragments.Fragment("menu", time.Minute, templ.Raw("<nav></nav>")).Render(context.Background(), &buf); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if buf.String() != "<nav></nav>" {
+			t.Errorf("unexpected output: %q", buf.String())
+		}
+	}
+	if stats := fragments.Stats(); stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	s.m.Lock()
+	defer s.m.Unlock()
+	if s.values["v1:menu"] != "<nav></nav>" {
+		t.Errorf("expected a versioned key, got %v", s.values)
+	}
+}
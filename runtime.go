@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"crypto/sha256"
+	"encoding"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
@@ -20,6 +21,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/a-h/templ/safehtml"
 )
@@ -58,15 +61,28 @@ func GetNonce(ctx context.Context) (nonce string) {
 	return v.nonce
 }
 
+// NonceAttribute returns the nonce attribute of the CSP nonce set with
+// WithNonce, e.g. ` nonce="abc"`, or an empty string if none has been set.
+// It's used by generated code to add the nonce to script and style elements.
+func NonceAttribute(ctx context.Context) string {
+	if nonce := GetNonce(ctx); nonce != "" {
+		return ` nonce="` + EscapeString(nonce) + `"`
+	}
+	return ""
+}
+
 func WithChildren(ctx context.Context, children Component) context.Context {
 	ctx, v := getContext(ctx)
 	v.children = &children
 	return ctx
 }
 
+// ClearChildren clears the children and blocks passed to the component, so
+// that they aren't passed on to the components that it renders.
 func ClearChildren(ctx context.Context) context.Context {
 	_, v := getContext(ctx)
 	v.children = nil
+	v.blocks = nil
 	return ctx
 }
 
@@ -88,24 +104,56 @@ type ComponentHandler struct {
 	Status       int
 	ContentType  string
 	ErrorHandler func(r *http.Request, err error) http.Handler
+	// ErrorComponent returns the component rendered if rendering fails, e.g.
+	// an error page. It's only used if ErrorHandler is nil.
+	ErrorComponent func(r *http.Request, err error) Component
+	// ErrorStatus returns the HTTP status code used if rendering fails. If
+	// it's nil, or returns 0, the status is 500.
+	ErrorStatus func(err error) int
+	// MaxOutputSize is the maximum size of the rendered component, in bytes.
+	// If it's exceeded, the error handler is called with an *OutputLimitError.
+	MaxOutputSize int64
+	// RenderBudget is the soft deadline for rendering the component, after
+	// which sections rendered with Fallback write their fallback content.
+	RenderBudget time.Duration
+	// StatsHandler is called with the stats of each request, once the
+	// response has been written.
+	StatsHandler func(r *http.Request, stats RenderStats)
 }
 
 const componentHandlerErrorMessage = "templ: failed to render template"
 
 // ServeHTTP implements the http.Handler interface.
 func (ch ComponentHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if ch.StatsHandler != nil {
+		ctx, stats := CollectRenderStats(r.Context())
+		r = r.WithContext(ctx)
+		defer func() {
+			ch.StatsHandler(r, stats.Stats())
+		}()
+	}
+	if pc, ok := ch.Component.(*PrecompressedComponent); ok {
+		w.Header().Add("Vary", "Accept-Encoding")
+		// The precompressed output doesn't have the CSP nonce of the request
+		// on its script and style elements.
+		if encoding, content, ok := pc.encoding(r); ok && GetNonce(r.Context()) == "" {
+			w.Header().Set("Content-Type", ch.ContentType)
+			w.Header().Set("Content-Encoding", encoding)
+			if ch.Status != 0 {
+				w.WriteHeader(ch.Status)
+			}
+			n, _ := io.WriteString(w, content)
+			addBytesWritten(r.Context(), n)
+			return
+		}
+	}
 	// Since the component may error, write to a buffer first.
 	// This prevents partial responses from being written to the client.
 	buf := GetBuffer()
 	defer ReleaseBuffer(buf)
-	err := ch.Component.Render(r.Context(), buf)
+	err := ch.render(r, buf)
 	if err != nil {
-		if ch.ErrorHandler != nil {
-			w.Header().Set("Content-Type", ch.ContentType)
-			ch.ErrorHandler(r, err).ServeHTTP(w, r)
-			return
-		}
-		http.Error(w, componentHandlerErrorMessage, http.StatusInternalServerError)
+		ch.serveError(w, r, err)
 		return
 	}
 	w.Header().Set("Content-Type", ch.ContentType)
@@ -114,7 +162,63 @@ func (ch ComponentHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 	// Ignore write error like http.Error() does, because there is
 	// no way to recover at this point.
-	_, _ = w.Write(buf.Bytes())
+	n, _ := w.Write(buf.Bytes())
+	addBytesWritten(r.Context(), n)
+}
+
+// serveError writes the response for a component that failed to render.
+func (ch ComponentHandler) serveError(w http.ResponseWriter, r *http.Request, err error) {
+	if ch.ErrorHandler != nil {
+		w.Header().Set("Content-Type", ch.ContentType)
+		ch.ErrorHandler(r, err).ServeHTTP(w, r)
+		return
+	}
+	status := http.StatusInternalServerError
+	if ch.ErrorStatus != nil {
+		if s := ch.ErrorStatus(err); s != 0 {
+			status = s
+		}
+	}
+	if ch.ErrorComponent != nil {
+		buf := GetBuffer()
+		defer ReleaseBuffer(buf)
+		// If the error page fails to render too, fall back to the plain text
+		// message.
+		if ch.ErrorComponent(r, err).Render(r.Context(), buf) == nil {
+			w.Header().Set("Content-Type", ch.ContentType)
+			w.WriteHeader(status)
+			n, _ := w.Write(buf.Bytes())
+			addBytesWritten(r.Context(), n)
+			return
+		}
+	}
+	http.Error(w, componentHandlerErrorMessage, status)
+}
+
+// render the component to buf. If the component panics, and there's an
+// ErrorHandler or ErrorComponent to handle it, the panic is returned as a
+// *PanicError, whose stack is mapped to templ file positions. Otherwise, the
+// original value is re-panicked, so that recovery middleware receives it
+// unchanged.
+func (ch ComponentHandler) render(r *http.Request, buf *bytes.Buffer) (err error) {
+	defer func() {
+		v := recover()
+		if v == nil {
+			return
+		}
+		if v == http.ErrAbortHandler || ch.ErrorHandler == nil && ch.ErrorComponent == nil {
+			panic(v)
+		}
+		err = newPanicError(v)
+	}()
+	c := ch.Component
+	if ch.MaxOutputSize > 0 {
+		c = LimitOutput(ch.MaxOutputSize, c)
+	}
+	if ch.RenderBudget > 0 {
+		c = RenderBudget(ch.RenderBudget, c)
+	}
+	return c.Render(r.Context(), buf)
 }
 
 // Handler creates a http.Handler that renders the template.
@@ -150,6 +254,48 @@ func WithErrorHandler(eh func(r *http.Request, err error) http.Handler) func(*Co
 	}
 }
 
+// WithErrorComponent sets the component rendered if rendering fails, e.g. a
+// branded error page, with the status set by WithErrorStatus. It's ignored if
+// there's an error handler set with WithErrorHandler.
+func WithErrorComponent(f func(r *http.Request, err error) Component) func(*ComponentHandler) {
+	return func(ch *ComponentHandler) {
+		ch.ErrorComponent = f
+	}
+}
+
+// WithErrorStatus sets the function that maps the error returned by a failed
+// render to the HTTP status code of the response, e.g. 404 for a not found
+// error. If it returns 0, the status is 500.
+func WithErrorStatus(f func(err error) int) func(*ComponentHandler) {
+	return func(ch *ComponentHandler) {
+		ch.ErrorStatus = f
+	}
+}
+
+// WithMaxOutputSize sets the maximum size of the output of the ComponentHandler,
+// in bytes. See LimitOutput.
+func WithMaxOutputSize(max int64) func(*ComponentHandler) {
+	return func(ch *ComponentHandler) {
+		ch.MaxOutputSize = max
+	}
+}
+
+// WithRenderBudget sets the soft deadline for rendering the component of the
+// ComponentHandler. See RenderBudget.
+func WithRenderBudget(d time.Duration) func(*ComponentHandler) {
+	return func(ch *ComponentHandler) {
+		ch.RenderBudget = d
+	}
+}
+
+// WithRenderStats calls f with the stats of each request, once the response
+// has been written, e.g. to log them. See CollectRenderStats.
+func WithRenderStats(f func(r *http.Request, stats RenderStats)) func(*ComponentHandler) {
+	return func(ch *ComponentHandler) {
+		ch.StatsHandler = f
+	}
+}
+
 // EscapeString escapes HTML text within templates.
 func EscapeString(s string) string {
 	return html.EscapeString(s)
@@ -277,6 +423,34 @@ func KV[TKey comparable, TValue any](key TKey, value TValue) KeyValue[TKey, TVal
 	}
 }
 
+// Ternary returns a if cond is true, otherwise b.
+//
+// It can be used for short conditional output, e.g. { templ.Ternary(ok, "yes", "no") }.
+func Ternary[T any](cond bool, a, b T) T {
+	if cond {
+		return a
+	}
+	return b
+}
+
+// Default returns the value that v points to, or def if v is nil.
+//
+// Generated code uses it to apply the default values of template parameters.
+func Default[T any](v *T, def T) T {
+	if v == nil {
+		return def
+	}
+	return *v
+}
+
+// Ptr returns a pointer to v.
+//
+// It can be used to set the optional parameters of templates, including to
+// the zero value of their type, e.g. ButtonOptions{Disabled: templ.Ptr(false)}.
+func Ptr[T any](v T) *T {
+	return &v
+}
+
 const unknownTypeClassName = "--templ-css-class-unknown-type"
 
 // Class returns a CSS class name.
@@ -318,7 +492,42 @@ func (css ComponentCSSClass) ClassName() string {
 	return css.ID
 }
 
-// CSSID calculates an ID.
+// CSSKeyframes is a @keyframes rule created by a keyframes component, whose
+// name is unique to its contents.
+type CSSKeyframes struct {
+	// ID is the name of the keyframes, for use in animation-name.
+	ID string
+	// Keyframes is the @keyframes rule.
+	Keyframes SafeCSS
+}
+
+// String returns the name of the keyframes.
+func (k CSSKeyframes) String() string {
+	return k.ID
+}
+
+// Render the @keyframes rule in a <style> element, unless it has already been
+// rendered.
+func (k CSSKeyframes) Render(ctx context.Context, w io.Writer) error {
+	return RenderCSSItems(ctx, w, k)
+}
+
+// SanitizeCSSAnimation sanitizes the value of an animation or animation-name
+// CSS property, which can be keyframes created by a keyframes component. The
+// @keyframes rule is written to keyframes, so that it's output with the CSS.
+func SanitizeCSSAnimation[T ~string | CSSKeyframes](keyframes *strings.Builder, property string, value T) SafeCSS {
+	switch v := any(value).(type) {
+	case CSSKeyframes:
+		keyframes.WriteString(string(v.Keyframes))
+		return SafeCSS(safehtml.SanitizeCSSProperty(property) + ":" + v.ID + ";")
+	case SafeCSSProperty:
+		return SanitizeCSS(property, v)
+	}
+	return SanitizeCSS(property, reflect.ValueOf(value).String())
+}
+
+// CSSID calculates the ID of a CSS class from its name and a hash of its CSS,
+// so that the ID is stable, and only changes when the CSS changes.
 func CSSID(name string, css string) string {
 	sum := sha256.Sum256([]byte(css))
 	hp := hex.EncodeToString(sum[:])[0:4]
@@ -328,6 +537,23 @@ func CSSID(name string, css string) string {
 	return name + "_" + hp
 }
 
+// ScopeCSSClasses appends the suffix to the names of the scoped classes in
+// the value of a class attribute, e.g. "button primary" becomes
+// "button_1a2b3c4d primary" if button is scoped. It's used by templates that
+// contain scoped style elements, e.g. <style scoped>.
+func ScopeCSSClasses(value, suffix string, scoped ...string) string {
+	classes := strings.Fields(value)
+	for i, c := range classes {
+		for _, s := range scoped {
+			if c == s {
+				classes[i] = c + suffix
+				break
+			}
+		}
+	}
+	return strings.Join(classes, " ")
+}
+
 // NewCSSMiddleware creates HTTP middleware that renders a global stylesheet of ComponentCSSClass
 // CSS if the request path matches, or updates the HTTP context to ensure that any handlers that
 // use templ.Components skip rendering <style> elements for classes that are included in the global
@@ -396,6 +622,33 @@ func (cssh CSSHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// CriticalCSS is the CSS of the css components that a page uses, which can
+// be rendered in the <head> element, so that the page can be painted without
+// waiting for the <style> elements within the page. Functions that return the
+// CriticalCSS of a page component are created by templ generate -critical-css.
+type CriticalCSS []CSSClass
+
+// String returns the combined CSS of the classes.
+func (c CriticalCSS) String() string {
+	sb := new(strings.Builder)
+	renderCSSItemsToBuilder(sb, &contextValue{}, c.items()...)
+	return sb.String()
+}
+
+// Render the CSS of the classes within a <style> element. The classes are
+// marked as rendered, so the components that use them don't render them again.
+func (c CriticalCSS) Render(ctx context.Context, w io.Writer) error {
+	return RenderCSSItems(ctx, w, c.items()...)
+}
+
+func (c CriticalCSS) items() []any {
+	items := make([]any, len(c))
+	for i, class := range c {
+		items[i] = class
+	}
+	return items
+}
+
 // RenderCSSItems renders the CSS to the writer, if the items haven't already been rendered.
 func RenderCSSItems(ctx context.Context, w io.Writer, classes ...any) (err error) {
 	if len(classes) == 0 {
@@ -426,6 +679,11 @@ func renderCSSItemsToBuilder(sb *strings.Builder, v *contextValue, classes ...an
 				sb.WriteString(string(ccc.Class))
 				v.addClass(ccc.ID)
 			}
+		case CSSKeyframes:
+			if !v.hasClassBeenRendered(ccc.ID) {
+				sb.WriteString(string(ccc.Keyframes))
+				v.addClass(ccc.ID)
+			}
 		case KeyValue[ComponentCSSClass, bool]:
 			if !ccc.Value {
 				continue
@@ -504,68 +762,278 @@ func writeStrings(w io.Writer, ss ...string) (err error) {
 
 func RenderAttributes(ctx context.Context, w io.Writer, attributes Attributes) (err error) {
 	for _, key := range sortedKeys(attributes) {
-		value := attributes[key]
-		switch value := value.(type) {
-		case string:
-			if err = writeStrings(w, ` `, EscapeString(key), `="`, EscapeString(value), `"`); err != nil {
-				return err
-			}
-		case *string:
-			if value != nil {
-				if err = writeStrings(w, ` `, EscapeString(key), `="`, EscapeString(*value), `"`); err != nil {
-					return err
-				}
-			}
-		case bool:
-			if value {
-				if err = writeStrings(w, ` `, EscapeString(key)); err != nil {
-					return err
-				}
-			}
-		case *bool:
-			if value != nil && *value {
-				if err = writeStrings(w, ` `, EscapeString(key)); err != nil {
-					return err
-				}
-			}
-		case KeyValue[string, bool]:
-			if value.Value {
-				if err = writeStrings(w, ` `, EscapeString(key), `="`, EscapeString(value.Key), `"`); err != nil {
-					return err
-				}
-			}
-		case KeyValue[bool, bool]:
-			if value.Value && value.Key {
-				if err = writeStrings(w, ` `, EscapeString(key)); err != nil {
-					return err
-				}
-			}
-		case func() bool:
-			if value() {
-				if err = writeStrings(w, ` `, EscapeString(key)); err != nil {
-					return err
-				}
-			}
+		if err = renderAttribute(w, key, attributes[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderAttribute renders an attribute with any of the types of the values of
+// Attributes. Values of other types aren't rendered.
+func renderAttribute(w io.Writer, key string, value any) (err error) {
+	switch value := value.(type) {
+	case string:
+		return writeStrings(w, ` `, EscapeString(key), `="`, EscapeString(value), `"`)
+	case *string:
+		if value != nil {
+			return writeStrings(w, ` `, EscapeString(key), `="`, EscapeString(*value), `"`)
+		}
+	case bool:
+		if value {
+			return writeStrings(w, ` `, EscapeString(key))
+		}
+	case *bool:
+		if value != nil && *value {
+			return writeStrings(w, ` `, EscapeString(key))
+		}
+	case KeyValue[string, bool]:
+		if value.Value {
+			return writeStrings(w, ` `, EscapeString(key), `="`, EscapeString(value.Key), `"`)
+		}
+	case KeyValue[bool, bool]:
+		if value.Value && value.Key {
+			return writeStrings(w, ` `, EscapeString(key))
+		}
+	case func() bool:
+		if value() {
+			return writeStrings(w, ` `, EscapeString(key))
 		}
 	}
 	return nil
 }
 
+// RenderAttribute renders an attribute whose name is the value of a Go
+// expression, e.g. { name }={ value }. The value can be any of the types of
+// the values of Attributes, a number, or a type whose underlying type is a
+// string, bool or number. The attribute isn't rendered if the name isn't a
+// valid attribute name, or if it's an event handler, e.g. onclick, because
+// its value would be run as JavaScript. The values of URL attributes, e.g.
+// href, are sanitized, unless they're a SafeURL.
+func RenderAttribute(ctx context.Context, w io.Writer, name string, value any) error {
+	if !isValidAttributeName(name) || isEventHandlerAttribute(name) {
+		return nil
+	}
+	if v, ok := value.(SafeURL); ok {
+		return renderAttribute(w, name, string(v))
+	}
+	value = attributeValue(value)
+	if v, ok := value.(string); ok && isURLAttribute(name) {
+		value = string(URL(v))
+	}
+	return renderAttribute(w, name, value)
+}
+
+// attributeValue converts numbers to strings, and values whose underlying
+// type is a string or bool to a string or bool, so that they can be rendered.
+func attributeValue(value any) any {
+	switch value.(type) {
+	case string, *string, bool, *bool, KeyValue[string, bool], KeyValue[bool, bool], func() bool, nil:
+		return value
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits())
+	}
+	return value
+}
+
+// Attributer is implemented by values that can be spread into the attributes
+// of an element, e.g. <div { props... }>. templ generate implements it for the
+// struct types declared in templ files that have fields with attr tags.
+type Attributer interface {
+	RenderAttributes(ctx context.Context, w io.Writer) error
+}
+
+// RenderSpreadAttributes renders spread attributes, e.g. <div { attrs... }>.
+// The value can be Attributes, an Attributer, or a struct, or a pointer to a
+// struct, whose fields have attr tags, e.g.
+//
+//	type ButtonProps struct {
+//		ID       string `attr:"id"`
+//		Disabled bool   `attr:"disabled"`
+//	}
+//
+// Each field with an attr tag is rendered in the same way as a dynamic
+// attribute, e.g. { "id" }={ props.ID }, in the order that the fields are
+// declared. Fields with the tag `attr:"-"` aren't rendered. An error is
+// returned if a tag names an event handler, e.g. `attr:"onclick"` or
+// `attr:"hx-on:click"`, or isn't a valid attribute name, since the attribute
+// couldn't be rendered.
+func RenderSpreadAttributes(ctx context.Context, w io.Writer, attrs any) (err error) {
+	switch attrs := attrs.(type) {
+	case Attributes:
+		return RenderAttributes(ctx, w, attrs)
+	case map[string]any:
+		return RenderAttributes(ctx, w, attrs)
+	}
+	v := reflect.ValueOf(attrs)
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if a, ok := attrs.(Attributer); ok {
+		return a.RenderAttributes(ctx, w)
+	}
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("templ: can't spread a value of type %T into attributes", attrs)
+	}
+	fields, err := attributeFields(v.Type())
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		if err = RenderAttribute(ctx, w, f.name, v.Field(f.index).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type attributeField struct {
+	name  string
+	index int
+}
+
+type attributeFieldsResult struct {
+	fields []attributeField
+	err    error
+}
+
+// attributeFieldCache maps struct types to their fields that have attr tags.
+var attributeFieldCache sync.Map
+
+// attributeFields returns the exported fields of the struct type that have
+// attr tags. The fields of embedded structs aren't included. An error is
+// returned if a tag is an event handler, or an invalid attribute name.
+func attributeFields(t reflect.Type) (fields []attributeField, err error) {
+	if cached, ok := attributeFieldCache.Load(t); ok {
+		r := cached.(attributeFieldsResult)
+		return r.fields, r.err
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, ok := f.Tag.Lookup("attr")
+		if !ok || name == "-" || name == "" || !f.IsExported() {
+			continue
+		}
+		if !isValidAttributeName(name) || isEventHandlerAttribute(name) {
+			fields, err = nil, fmt.Errorf("templ: field %s of %v has the attr tag %q, which can't be rendered because it's an event handler or an invalid attribute name", f.Name, t, name)
+			break
+		}
+		fields = append(fields, attributeField{name: name, index: i})
+	}
+	attributeFieldCache.Store(t, attributeFieldsResult{fields: fields, err: err})
+	return fields, err
+}
+
+// isValidAttributeName returns true if the name can be written as an
+// attribute name without ending the attribute, or the element.
+//
+// https://html.spec.whatwg.org/multipage/syntax.html#attributes-2
+func isValidAttributeName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if unicode.IsSpace(r) || unicode.IsControl(r) || strings.ContainsRune("\"'<>/=`", r) || r == utf8.RuneError {
+			return false
+		}
+	}
+	return true
+}
+
+func isEventHandlerAttribute(name string) bool {
+	name = strings.ToLower(name)
+	return strings.HasPrefix(name, "on") || strings.HasPrefix(name, "hx-on")
+}
+
+func isURLAttribute(name string) bool {
+	switch strings.ToLower(name) {
+	case "href", "src", "action", "formaction", "poster", "cite", "xlink:href":
+		return true
+	}
+	return false
+}
+
 // Script handling.
 
 func safeEncodeScriptParams(escapeHTML bool, params []any) []string {
 	encodedParams := make([]string, len(params))
 	for i := 0; i < len(encodedParams); i++ {
-		enc, _ := json.Marshal(params[i])
+		enc, err := encodeScriptParam(params[i])
+		if err != nil {
+			// An empty parameter would be a JavaScript syntax error.
+			enc = "null"
+		}
 		if !escapeHTML {
-			encodedParams[i] = string(enc)
+			encodedParams[i] = enc
 			continue
 		}
-		encodedParams[i] = EscapeString(string(enc))
+		encodedParams[i] = EscapeString(enc)
 	}
 	return encodedParams
 }
 
+// encodeScriptParam encodes the parameter as JSON. The characters <, > and &
+// are escaped, so the output is safe to use within a script element.
+func encodeScriptParam(param any) (string, error) {
+	// Values whose MarshalJSON or MarshalText method has a pointer receiver
+	// aren't encoded with the method, because they aren't addressable, so
+	// encode a pointer to a copy instead.
+	if v := reflect.ValueOf(param); v.IsValid() && v.Kind() != reflect.Pointer {
+		pt := reflect.PointerTo(v.Type())
+		if pt.Implements(jsonMarshalerType) || pt.Implements(textMarshalerType) {
+			p := reflect.New(v.Type())
+			p.Elem().Set(v)
+			param = p.Interface()
+		}
+	}
+	enc, err := json.Marshal(param)
+	if err != nil {
+		return "", err
+	}
+	return string(enc), nil
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// NewComponentScript creates a ComponentScript that calls the function with
+// the params, which are encoded as JSON. If a param can't be encoded, the
+// error is returned when the script is rendered.
+func NewComponentScript(name, function string, params ...any) ComponentScript {
+	cs := ComponentScript{
+		Name:     name,
+		Function: function,
+	}
+	encodedParams := make([]string, len(params))
+	for i, param := range params {
+		enc, err := encodeScriptParam(param)
+		if err != nil {
+			cs.Err = fmt.Errorf("templ: failed to encode parameter %d of script %s: %w", i, name, err)
+			return cs
+		}
+		encodedParams[i] = enc
+	}
+	cs.CallInline = name + "(" + strings.Join(encodedParams, ",") + ")"
+	cs.Call = EscapeString(cs.CallInline)
+	return cs
+}
+
 // SafeScript encodes unknown parameters for safety for inside HTML attributes.
 func SafeScript(functionName string, params ...any) string {
 	encodedParams := safeEncodeScriptParams(true, params)
@@ -596,7 +1064,10 @@ type contextValue struct {
 	ss          map[string]struct{}
 	onceHandles map[*OnceHandle]struct{}
 	children    *Component
+	blocks      Slots
 	nonce       string
+	// stats collects the render stats, if they're being collected.
+	stats *RenderStatsCollector
 }
 
 func (v *contextValue) setHasBeenRendered(h *OnceHandle) {
@@ -646,7 +1117,11 @@ func (v *contextValue) hasClassBeenRendered(s string) (ok bool) {
 
 // InitializeContext initializes context used to store internal state used during rendering.
 func InitializeContext(ctx context.Context) context.Context {
-	if _, ok := ctx.Value(contextKey).(*contextValue); ok {
+	if v, ok := ctx.Value(contextKey).(*contextValue); ok {
+		// Generated components initialize the context when they start rendering.
+		if v.stats != nil {
+			v.stats.components.Add(1)
+		}
 		return ctx
 	}
 	v := &contextValue{}
@@ -693,20 +1168,22 @@ type ComponentScript struct {
 	// This is can be used to call the function inside a script tag:
 	//    <script>__templ_functionName_sha("some string",12345))</script>
 	CallInline string
+	// Err is the error encoding the parameters of the call, if any. It's
+	// returned when the script is rendered.
+	Err error
 }
 
 var _ Component = ComponentScript{}
 
 func writeScriptHeader(ctx context.Context, w io.Writer) (err error) {
-	var nonceAttr string
-	if nonce := GetNonce(ctx); nonce != "" {
-		nonceAttr = " nonce=\"" + EscapeString(nonce) + "\""
-	}
-	_, err = fmt.Fprintf(w, `<script type="text/javascript"%s>`, nonceAttr)
+	_, err = fmt.Fprintf(w, `<script type="text/javascript"%s>`, NonceAttribute(ctx))
 	return err
 }
 
 func (c ComponentScript) Render(ctx context.Context, w io.Writer) error {
+	if c.Err != nil {
+		return c.Err
+	}
 	err := RenderScriptItems(ctx, w, c)
 	if err != nil {
 		return err
@@ -730,6 +1207,13 @@ func RenderScriptItems(ctx context.Context, w io.Writer, scripts ...ComponentScr
 	if len(scripts) == 0 {
 		return nil
 	}
+	for _, s := range scripts {
+		// Scripts used in attributes are rendered before the element, so any
+		// error is returned before the attribute is written.
+		if s.Err != nil {
+			return s.Err
+		}
+	}
 	_, v := getContext(ctx)
 	sb := new(strings.Builder)
 	for _, s := range scripts {
@@ -767,9 +1251,12 @@ func ReleaseBuffer(b *bytes.Buffer) {
 	bufferPool.Put(b)
 }
 
-// JoinStringErrs joins an optional list of errors.
-func JoinStringErrs(s string, errs ...error) (string, error) {
-	return s, errors.Join(errs...)
+// JoinStringErrs converts v to a string using ToString, and joins an optional list of errors.
+func JoinStringErrs[T any](v T, errs ...error) (s string, err error) {
+	if err = errors.Join(errs...); err != nil {
+		return "", err
+	}
+	return ToString(v)
 }
 
 // Error returned during template rendering.
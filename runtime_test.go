@@ -3,13 +3,16 @@ package templ_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"html/template"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/a-h/templ"
 	"github.com/google/go-cmp/cmp"
@@ -246,6 +249,111 @@ func TestRenderCSS(t *testing.T) {
 	}
 }
 
+func TestCriticalCSS(t *testing.T) {
+	c1 := templ.ComponentCSSClass{
+		ID:    "c1",
+		Class: ".c1{color:red}",
+	}
+	c2 := templ.ComponentCSSClass{
+		ID:    "c2",
+		Class: ".c2{color:blue}",
+	}
+	critical := templ.CriticalCSS{c1, c2, c1}
+	t.Run("String returns the combined CSS", func(t *testing.T) {
+		if diff := cmp.Diff(".c1{color:red}.c2{color:blue}", critical.String()); diff != "" {
+			t.Error(diff)
+		}
+	})
+	t.Run("classes aren't rendered again after the critical CSS", func(t *testing.T) {
+		ctx := templ.InitializeContext(context.Background())
+		b := new(bytes.Buffer)
+		if err := critical.Render(ctx, b); err != nil {
+			t.Fatalf("failed to render critical CSS: %v", err)
+		}
+		if err := templ.RenderCSSItems(ctx, b, c2); err != nil {
+			t.Fatalf("failed to render CSS: %v", err)
+		}
+		expected := `<style type="text/css">.c1{color:red}.c2{color:blue}</style>`
+		if diff := cmp.Diff(expected, b.String()); diff != "" {
+			t.Error(diff)
+		}
+	})
+}
+
+func TestCSSKeyframes(t *testing.T) {
+	fadeIn := templ.CSSKeyframes{
+		ID:        "fadeIn_1234",
+		Keyframes: "@keyframes fadeIn_1234{from{opacity:0;}to{opacity:1;}}",
+	}
+	t.Run("keyframes are rendered once", func(t *testing.T) {
+		ctx := templ.InitializeContext(context.Background())
+		b := new(bytes.Buffer)
+		if err := fadeIn.Render(ctx, b); err != nil {
+			t.Fatalf("failed to render: %v", err)
+		}
+		if err := fadeIn.Render(ctx, b); err != nil {
+			t.Fatalf("failed to render: %v", err)
+		}
+		expected := `<style type="text/css">@keyframes fadeIn_1234{from{opacity:0;}to{opacity:1;}}</style>`
+		if diff := cmp.Diff(expected, b.String()); diff != "" {
+			t.Error(diff)
+		}
+	})
+	t.Run("animations of keyframes write the @keyframes rule", func(t *testing.T) {
+		var keyframes strings.Builder
+		actual := templ.SanitizeCSSAnimation(&keyframes, "animation-name", fadeIn)
+		if diff := cmp.Diff(templ.SafeCSS("animation-name:fadeIn_1234;"), actual); diff != "" {
+			t.Error(diff)
+		}
+		if diff := cmp.Diff(string(fadeIn.Keyframes), keyframes.String()); diff != "" {
+			t.Error(diff)
+		}
+	})
+	t.Run("animations of strings are sanitized", func(t *testing.T) {
+		var keyframes strings.Builder
+		actual := templ.SanitizeCSSAnimation(&keyframes, "animation", "spin 1s; } body {")
+		if diff := cmp.Diff(templ.SafeCSS("animation:zTemplUnsafeCSSPropertyValue;"), actual); diff != "" {
+			t.Error(diff)
+		}
+		if keyframes.Len() != 0 {
+			t.Errorf("expected no keyframes, got %q", keyframes.String())
+		}
+	})
+}
+
+func TestScopeCSSClasses(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "scoped classes have the suffix appended",
+			input:    "card title",
+			expected: "card_1234 title_1234",
+		},
+		{
+			name:     "other classes are unchanged",
+			input:    "card  primary",
+			expected: "card_1234 primary",
+		},
+		{
+			name:     "empty values are unchanged",
+			input:    "",
+			expected: "",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			actual := templ.ScopeCSSClasses(tt.input, "_1234", "card", "title")
+			if actual != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, actual)
+			}
+		})
+	}
+}
+
 func TestClassesFunction(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -376,6 +484,136 @@ func TestClassesFunction(t *testing.T) {
 	}
 }
 
+func TestRenderAttribute(t *testing.T) {
+	tests := []struct {
+		name     string
+		attrName string
+		value    any
+		expected string
+	}{
+		{name: "string values are escaped", attrName: "data-x", value: `"a" & b`, expected: ` data-x="&#34;a&#34; &amp; b"`},
+		{name: "true bool values render the name", attrName: "hidden", value: true, expected: ` hidden`},
+		{name: "false bool values aren't rendered", attrName: "hidden", value: false, expected: ``},
+		{name: "URL values are sanitized", attrName: "href", value: "javascript:alert(1)", expected: ` href="about:invalid#TemplFailedSanitizationURL"`},
+		{name: "safe URL values aren't sanitized", attrName: "href", value: templ.SafeURL("javascript:void(0)"), expected: ` href="javascript:void(0)"`},
+		{name: "event handlers aren't rendered", attrName: "onClick", value: "alert(1)", expected: ``},
+		{name: "names with spaces aren't rendered", attrName: "a onclick", value: "alert(1)", expected: ``},
+		{name: "names that close the element aren't rendered", attrName: "a><script", value: "b", expected: ``},
+		{name: "empty names aren't rendered", attrName: "", value: "b", expected: ``},
+		{name: "numbers are rendered", attrName: "tabindex", value: -1, expected: ` tabindex="-1"`},
+		{name: "floats are rendered", attrName: "data-ratio", value: 1.5, expected: ` data-ratio="1.5"`},
+		{name: "named string types are rendered", attrName: "data-variant", value: testVariant("primary"), expected: ` data-variant="primary"`},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			w := new(strings.Builder)
+			if err := templ.RenderAttribute(context.Background(), w, tt.attrName, tt.value); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, w.String()); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+type testVariant string
+
+type testProps struct {
+	ID       string  `attr:"id"`
+	Disabled bool    `attr:"disabled"`
+	Title    *string `attr:"title"`
+	Label    string  `attr:"-"`
+	Size     int     `attr:"data-size"`
+	Ignored  string
+}
+
+type testHandlerProps struct {
+	ID      string `attr:"id"`
+	OnClick string `attr:"onclick"`
+}
+
+type testAttributer struct{}
+
+func (testAttributer) RenderAttributes(ctx context.Context, w io.Writer) error {
+	_, err := io.WriteString(w, ` data-attributer`)
+	return err
+}
+
+func TestRenderSpreadAttributes(t *testing.T) {
+	tests := []struct {
+		name     string
+		attrs    any
+		expected string
+	}{
+		{name: "attributes", attrs: templ.Attributes{"b": "2", "a": "1"}, expected: ` a="1" b="2"`},
+		{name: "maps", attrs: map[string]any{"a": true}, expected: ` a`},
+		{name: "attributers", attrs: testAttributer{}, expected: ` data-attributer`},
+		{name: "structs", attrs: testProps{ID: "a", Disabled: true, Label: "x", Size: 2, Ignored: "y"}, expected: ` id="a" disabled data-size="2"`},
+		{name: "pointers to structs", attrs: &testProps{ID: "a"}, expected: ` id="a" data-size="0"`},
+		{name: "nil pointers", attrs: (*testProps)(nil), expected: ``},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			w := new(strings.Builder)
+			if err := templ.RenderSpreadAttributes(context.Background(), w, tt.attrs); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.expected, w.String()); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+	t.Run("other types are an error", func(t *testing.T) {
+		err := templ.RenderSpreadAttributes(context.Background(), io.Discard, 42)
+		if err == nil || err.Error() != "templ: can't spread a value of type int into attributes" {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+	t.Run("struct tags that name event handlers are an error", func(t *testing.T) {
+		w := new(strings.Builder)
+		err := templ.RenderSpreadAttributes(context.Background(), w, testHandlerProps{ID: "a", OnClick: "alert(1)"})
+		if err == nil || !strings.Contains(err.Error(), `"onclick"`) {
+			t.Errorf("expected an error for the onclick field, got %v", err)
+		}
+		if w.Len() != 0 {
+			t.Errorf("expected nothing to be written, got %q", w.String())
+		}
+	})
+}
+
+func TestTernary(t *testing.T) {
+	if actual := templ.Ternary(true, "yes", "no"); actual != "yes" {
+		t.Errorf("expected %q, got %q", "yes", actual)
+	}
+	if actual := templ.Ternary(false, "yes", "no"); actual != "no" {
+		t.Errorf("expected %q, got %q", "no", actual)
+	}
+	if actual := templ.Ternary(false, 1, 2); actual != 2 {
+		t.Errorf("expected %d, got %d", 2, actual)
+	}
+}
+
+func TestDefault(t *testing.T) {
+	if actual := templ.Default(nil, "primary"); actual != "primary" {
+		t.Errorf("expected %q, got %q", "primary", actual)
+	}
+	if actual := templ.Default(templ.Ptr("secondary"), "primary"); actual != "secondary" {
+		t.Errorf("expected %q, got %q", "secondary", actual)
+	}
+	if actual := templ.Default(templ.Ptr(""), "primary"); actual != "" {
+		t.Errorf("expected the zero value to override the default, got %q", actual)
+	}
+	if actual := templ.Default(templ.Ptr(false), true); actual {
+		t.Error("expected false to override the default")
+	}
+	if actual := templ.Default((*[]string)(nil), []string{"a"}); len(actual) != 1 {
+		t.Errorf("expected the default slice, got %v", actual)
+	}
+}
+
 func TestHandler(t *testing.T) {
 	hello := templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
 		if _, err := io.WriteString(w, "Hello"); err != nil {
@@ -448,6 +686,62 @@ func TestHandler(t *testing.T) {
 			expectedMIMEType: "text/html; charset=utf-8",
 			expectedBody:     "custom body",
 		},
+		{
+			name: "error pages can be rendered with a component",
+			input: templ.Handler(errorComponent, templ.WithErrorComponent(func(r *http.Request, err error) templ.Component {
+				return templ.Raw("<h1>Error at " + r.URL.Path + ": " + err.Error() + "</h1>")
+			})),
+			expectedStatus:   http.StatusInternalServerError,
+			expectedMIMEType: "text/html; charset=utf-8",
+			expectedBody:     "<h1>Error at /test: handler error</h1>",
+		},
+		{
+			name: "error statuses can be mapped",
+			input: templ.Handler(errorComponent,
+				templ.WithErrorComponent(func(r *http.Request, err error) templ.Component {
+					return templ.Raw("<h1>Not found</h1>")
+				}),
+				templ.WithErrorStatus(func(err error) int {
+					if err.Error() == "handler error" {
+						return http.StatusNotFound
+					}
+					return 0
+				}),
+			),
+			expectedStatus:   http.StatusNotFound,
+			expectedMIMEType: "text/html; charset=utf-8",
+			expectedBody:     "<h1>Not found</h1>",
+		},
+		{
+			name: "error statuses are used by the default error response",
+			input: templ.Handler(errorComponent, templ.WithErrorStatus(func(err error) int {
+				return http.StatusServiceUnavailable
+			})),
+			expectedStatus:   http.StatusServiceUnavailable,
+			expectedMIMEType: "text/plain; charset=utf-8",
+			expectedBody:     "templ: failed to render template\n",
+		},
+		{
+			name: "if the error component fails, the default error response is used",
+			input: templ.Handler(errorComponent, templ.WithErrorComponent(func(r *http.Request, err error) templ.Component {
+				return errorComponent
+			})),
+			expectedStatus:   http.StatusInternalServerError,
+			expectedMIMEType: "text/plain; charset=utf-8",
+			expectedBody:     "templ: failed to render template\n",
+		},
+		{
+			name: "panics are passed to the error component",
+			input: templ.Handler(templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+				panic("oops")
+			}), templ.WithErrorComponent(func(r *http.Request, err error) templ.Component {
+				var pe *templ.PanicError
+				return templ.Raw(fmt.Sprintf("panic: %v", errors.As(err, &pe)))
+			})),
+			expectedStatus:   http.StatusInternalServerError,
+			expectedMIMEType: "text/html; charset=utf-8",
+			expectedBody:     "panic: true",
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -551,6 +845,83 @@ func TestRenderScriptItems(t *testing.T) {
 	}
 }
 
+type pointerMarshaler struct {
+	Name string
+}
+
+func (m *pointerMarshaler) MarshalJSON() ([]byte, error) {
+	return json.Marshal("custom:" + m.Name)
+}
+
+func TestNewComponentScript(t *testing.T) {
+	tests := []struct {
+		name               string
+		params             []any
+		expectedCall       string
+		expectedCallInline string
+	}{
+		{
+			name:               "no params",
+			expectedCall:       "fn()",
+			expectedCallInline: "fn()",
+		},
+		{
+			name:               "strings are escaped for attributes and script elements",
+			params:             []any{`</script><b>"&"</b>`, 1},
+			expectedCall:       `fn(&#34;\u003c/script\u003e\u003cb\u003e\&#34;\u0026\&#34;\u003c/b\u003e&#34;,1)`,
+			expectedCallInline: `fn("\u003c/script\u003e\u003cb\u003e\"\u0026\"\u003c/b\u003e",1)`,
+		},
+		{
+			name: "structs and times are encoded as JSON",
+			params: []any{
+				struct {
+					Name string `json:"name"`
+				}{Name: "a"},
+				time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			},
+			expectedCall:       `fn({&#34;name&#34;:&#34;a&#34;},&#34;2024-01-02T03:04:05Z&#34;)`,
+			expectedCallInline: `fn({"name":"a"},"2024-01-02T03:04:05Z")`,
+		},
+		{
+			name:               "marshalers with pointer receivers are used for values",
+			params:             []any{pointerMarshaler{Name: "a"}, &pointerMarshaler{Name: "b"}, nil},
+			expectedCall:       `fn(&#34;custom:a&#34;,&#34;custom:b&#34;,null)`,
+			expectedCallInline: `fn("custom:a","custom:b",null)`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			cs := templ.NewComponentScript("fn", "function fn(){}", tt.params...)
+			if cs.Err != nil {
+				t.Fatalf("unexpected error: %v", cs.Err)
+			}
+			if diff := cmp.Diff(tt.expectedCall, cs.Call); diff != "" {
+				t.Errorf("Call:\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.expectedCallInline, cs.CallInline); diff != "" {
+				t.Errorf("CallInline:\n%s", diff)
+			}
+		})
+	}
+	t.Run("params that can't be encoded return an error when rendered", func(t *testing.T) {
+		cs := templ.NewComponentScript("fn", "function fn(){}", "a", make(chan int))
+		if cs.Err == nil {
+			t.Fatal("expected an error")
+		}
+		b := new(bytes.Buffer)
+		if err := cs.Render(context.Background(), b); err == nil {
+			t.Error("expected Render to return an error")
+		}
+		if err := templ.RenderScriptItems(context.Background(), b, cs); err == nil {
+			t.Error("expected RenderScriptItems to return an error")
+		}
+		if b.Len() != 0 {
+			t.Errorf("expected nothing to be written, got %q", b.String())
+		}
+	})
+}
+
 type baseError struct {
 	Value int
 }
@@ -742,4 +1113,16 @@ func TestNonce(t *testing.T) {
 			t.Errorf("expected %q got %q", expected, actual)
 		}
 	})
+	t.Run("the nonce attribute is empty if not set", func(t *testing.T) {
+		if actual := templ.NonceAttribute(ctx); actual != "" {
+			t.Errorf("expected empty string got %q", actual)
+		}
+	})
+	t.Run("the nonce attribute is escaped", func(t *testing.T) {
+		ctx := templ.WithNonce(context.Background(), `a"b`)
+		expected := ` nonce="a&#34;b"`
+		if actual := templ.NonceAttribute(ctx); actual != expected {
+			t.Errorf("expected %q got %q", expected, actual)
+		}
+	})
 }
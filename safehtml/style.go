@@ -24,6 +24,9 @@ func SanitizeCSS(property, value string) (string, string) {
 }
 
 func SanitizeCSSValue(property, value string) string {
+	if IsCSSCustomProperty(property) {
+		return sanitizeCustomPropertyValue(value)
+	}
 	if sanitizer, ok := cssPropertyNameToValueSanitizer[property]; ok {
 		return sanitizer(value)
 	}
@@ -31,6 +34,13 @@ func SanitizeCSSValue(property, value string) string {
 }
 
 func SanitizeCSSProperty(property string) string {
+	if IsCSSCustomProperty(property) {
+		// Custom property names are case sensitive.
+		if !customPropertyPattern.MatchString(property) {
+			return InnocuousPropertyName
+		}
+		return property
+	}
 	if !identifierPattern.MatchString(property) {
 		return InnocuousPropertyName
 	}
@@ -42,6 +52,61 @@ func SanitizeCSSProperty(property string) string {
 // keywords defined in https://drafts.csswg.org/css-fonts-3/#family-name-value.
 var identifierPattern = regexp.MustCompile(`^[-a-zA-Z]+$`)
 
+// IsCSSCustomProperty returns true if the property is a custom property, e.g.
+// --brand-color, which is used with var(--brand-color).
+func IsCSSCustomProperty(property string) bool {
+	return strings.HasPrefix(property, "--")
+}
+
+// customPropertyPattern matches custom property names.
+var customPropertyPattern = regexp.MustCompile(`^--[-_a-zA-Z0-9]+$`)
+
+// safeCustomPropertyValuePattern matches the runes that are allowed in custom
+// property values. Unlike regular properties, parentheses and commas are
+// allowed, so that values can be colors or calculations, e.g. rgb(0, 0, 0).
+var safeCustomPropertyValuePattern = regexp.MustCompile(`^[0-9a-zA-Z+\-.!#%_ \t,()/*]*$`)
+
+// customPropertyFunctionPattern matches the names of functions in custom
+// property values.
+var customPropertyFunctionPattern = regexp.MustCompile(`([-_a-zA-Z0-9]*)\(`)
+
+// safeCustomPropertyFunctions are the functions that can be used in custom
+// property values. Functions that load resources, e.g. url(), aren't allowed.
+var safeCustomPropertyFunctions = map[string]bool{
+	"": true, "calc": true, "clamp": true, "min": true, "max": true, "var": true,
+	"rgb": true, "rgba": true, "hsl": true, "hsla": true, "hwb": true,
+	"lab": true, "lch": true, "oklab": true, "oklch": true, "color": true, "color-mix": true,
+}
+
+func sanitizeCustomPropertyValue(v string) string {
+	if !safeCustomPropertyValuePattern.MatchString(v) ||
+		strings.Contains(v, "/*") || strings.Contains(v, "*/") || strings.Contains(v, "//") {
+		return InnocuousPropertyValue
+	}
+	// Parentheses must be balanced, so the value can't close a function.
+	var depth int
+	for _, r := range v {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+		if depth < 0 {
+			return InnocuousPropertyValue
+		}
+	}
+	if depth != 0 {
+		return InnocuousPropertyValue
+	}
+	for _, m := range customPropertyFunctionPattern.FindAllStringSubmatch(v, -1) {
+		if !safeCustomPropertyFunctions[strings.ToLower(m[1])] {
+			return InnocuousPropertyValue
+		}
+	}
+	return v
+}
+
 var cssPropertyNameToValueSanitizer = map[string]func(string) string{
 	"background-image":    sanitizeBackgroundImage,
 	"font-family":         sanitizeFontFamily,
@@ -346,6 +346,55 @@ func TestSanitizeCSS(t *testing.T) {
 			inputValue:       "*+/-.!#%_ \t",
 			expectedValue:    InnocuousPropertyValue,
 		},
+		{
+			name:             "custom properties are allowed",
+			inputProperty:    "--Brand-color_1",
+			expectedProperty: "--Brand-color_1",
+			inputValue:       "#ff0000",
+			expectedValue:    "#ff0000",
+		},
+		{
+			name:             "custom property names must be valid",
+			inputProperty:    "--brand:red;--x",
+			expectedProperty: InnocuousPropertyName,
+			inputValue:       "#ff0000",
+			expectedValue:    InnocuousPropertyValue,
+		},
+		{
+			name:             "custom properties can contain colors and calculations",
+			inputProperty:    "--spacing",
+			expectedProperty: "--spacing",
+			inputValue:       "calc(var(--base, 4px) * 2) rgb(0 0 0 / 50%)",
+			expectedValue:    "calc(var(--base, 4px) * 2) rgb(0 0 0 / 50%)",
+		},
+		{
+			name:             "custom properties cannot contain urls",
+			inputProperty:    "--image",
+			expectedProperty: "--image",
+			inputValue:       "url(https://example.com/track)",
+			expectedValue:    InnocuousPropertyValue,
+		},
+		{
+			name:             "custom properties cannot end the declaration",
+			inputProperty:    "--brand",
+			expectedProperty: "--brand",
+			inputValue:       "red; } body { display: none",
+			expectedValue:    InnocuousPropertyValue,
+		},
+		{
+			name:             "custom properties must have balanced parentheses",
+			inputProperty:    "--brand",
+			expectedProperty: "--brand",
+			inputValue:       "rgb(0, 0, 0))",
+			expectedValue:    InnocuousPropertyValue,
+		},
+		{
+			name:             "custom properties cannot contain comments",
+			inputProperty:    "--brand",
+			expectedProperty: "--brand",
+			inputValue:       "red /* a */",
+			expectedValue:    InnocuousPropertyValue,
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
@@ -0,0 +1,212 @@
+// Package sitemap provides components that render sitemaps and sitemap
+// indexes, as described at https://www.sitemaps.org/protocol.html.
+//
+//	templ.Handler(sitemap.Sitemap(urls), templ.WithContentType(sitemap.ContentType))
+package sitemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/a-h/templ/internal/xmlwriter"
+)
+
+const (
+	// ContentType is the content type of sitemaps and sitemap indexes.
+	ContentType = "application/xml; charset=utf-8"
+	// GzipContentType is the content type of sitemaps compressed with Gzip.
+	GzipContentType = "application/gzip"
+)
+
+const (
+	// MaxURLs is the maximum number of URLs in a sitemap, or sitemaps in a sitemap index.
+	MaxURLs = 50_000
+	// MaxSize is the maximum size of an uncompressed sitemap in bytes.
+	MaxSize = 50 * 1024 * 1024
+	// MaxLocLength is the maximum length of a URL.
+	MaxLocLength = 2048
+)
+
+const namespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// ChangeFreq is how frequently the page is likely to change.
+type ChangeFreq string
+
+const (
+	Always  ChangeFreq = "always"
+	Hourly  ChangeFreq = "hourly"
+	Daily   ChangeFreq = "daily"
+	Weekly  ChangeFreq = "weekly"
+	Monthly ChangeFreq = "monthly"
+	Yearly  ChangeFreq = "yearly"
+	Never   ChangeFreq = "never"
+)
+
+// URL is a page in a sitemap.
+type URL struct {
+	// Loc is the absolute URL of the page.
+	Loc string
+	// LastMod is the time the page was last modified. It's omitted if it's the zero time.
+	LastMod    time.Time
+	ChangeFreq ChangeFreq
+	// Priority of the page relative to other pages of the site, from 0.0 to
+	// 1.0. It's omitted if it's zero.
+	Priority float64
+}
+
+// Entry is a sitemap in a sitemap index.
+type Entry struct {
+	// Loc is the absolute URL of the sitemap.
+	Loc string
+	// LastMod is the time the sitemap was last modified. It's omitted if it's the zero time.
+	LastMod time.Time
+}
+
+// Sitemap renders a sitemap of the URLs. It returns an error, without writing
+// any output, if a URL is invalid, or if the sitemap would exceed MaxURLs or
+// MaxSize. Use Split to divide URLs between multiple sitemaps.
+func Sitemap(urls []URL) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) (err error) {
+		if len(urls) > MaxURLs {
+			return fmt.Errorf("sitemap: %d URLs exceeds the maximum of %d, use sitemap.Split to create multiple sitemaps", len(urls), MaxURLs)
+		}
+		var buf bytes.Buffer
+		xw := xmlwriter.New(&buf)
+		xw.Raw(xmlwriter.Declaration)
+		xw.Start("urlset", xmlwriter.Attr{Name: "xmlns", Value: namespace})
+		for _, u := range urls {
+			if err = writeURL(xw, u); err != nil {
+				return err
+			}
+		}
+		xw.End("urlset")
+		return writeLimited(w, &buf, xw.Err())
+	})
+}
+
+// Index renders a sitemap index of the sitemaps. It returns an error, without
+// writing any output, if a URL is invalid, or if the index would exceed
+// MaxURLs or MaxSize.
+func Index(sitemaps []Entry) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) (err error) {
+		if len(sitemaps) > MaxURLs {
+			return fmt.Errorf("sitemap: %d sitemaps exceeds the maximum of %d in a sitemap index", len(sitemaps), MaxURLs)
+		}
+		var buf bytes.Buffer
+		xw := xmlwriter.New(&buf)
+		xw.Raw(xmlwriter.Declaration)
+		xw.Start("sitemapindex", xmlwriter.Attr{Name: "xmlns", Value: namespace})
+		for _, s := range sitemaps {
+			if err = validateLoc(s.Loc); err != nil {
+				return err
+			}
+			xw.Start("sitemap")
+			xw.Text("loc", s.Loc)
+			xw.Text("lastmod", FormatLastMod(s.LastMod))
+			xw.End("sitemap")
+		}
+		xw.End("sitemapindex")
+		return writeLimited(w, &buf, xw.Err())
+	})
+}
+
+func writeURL(xw *xmlwriter.Writer, u URL) (err error) {
+	if err = validateLoc(u.Loc); err != nil {
+		return err
+	}
+	if u.Priority < 0 || u.Priority > 1 {
+		return fmt.Errorf("sitemap: priority %v of %q must be between 0.0 and 1.0", u.Priority, u.Loc)
+	}
+	xw.Start("url")
+	xw.Text("loc", u.Loc)
+	xw.Text("lastmod", FormatLastMod(u.LastMod))
+	xw.Text("changefreq", string(u.ChangeFreq))
+	if u.Priority > 0 {
+		xw.Text("priority", strconv.FormatFloat(u.Priority, 'f', 1, 64))
+	}
+	xw.End("url")
+	return nil
+}
+
+func validateLoc(loc string) error {
+	if len(loc) > MaxLocLength {
+		return fmt.Errorf("sitemap: URL %q exceeds the maximum length of %d", loc, MaxLocLength)
+	}
+	u, err := url.Parse(loc)
+	if err != nil {
+		return fmt.Errorf("sitemap: invalid URL %q: %w", loc, err)
+	}
+	if !u.IsAbs() || u.Host == "" {
+		return fmt.Errorf("sitemap: URL %q must be absolute", loc)
+	}
+	return nil
+}
+
+func writeLimited(w io.Writer, buf *bytes.Buffer, err error) error {
+	if err != nil {
+		return err
+	}
+	if buf.Len() > MaxSize {
+		return fmt.Errorf("sitemap: size of %d bytes exceeds the maximum of %d, use sitemap.Split to create multiple sitemaps", buf.Len(), MaxSize)
+	}
+	_, err = buf.WriteTo(w)
+	return err
+}
+
+// Split divides URLs into groups that are each within the MaxURLs and
+// MaxSize limits of a single sitemap.
+func Split(urls []URL) (sitemaps [][]URL, err error) {
+	// Leave space for the XML declaration and urlset element.
+	const overhead = 256
+	var current []URL
+	size := overhead
+	for _, u := range urls {
+		var buf bytes.Buffer
+		xw := xmlwriter.New(&buf)
+		if err = writeURL(xw, u); err != nil {
+			return nil, err
+		}
+		if len(current) == MaxURLs || size+buf.Len() > MaxSize {
+			sitemaps = append(sitemaps, current)
+			current, size = nil, overhead
+		}
+		current = append(current, u)
+		size += buf.Len()
+	}
+	if len(current) > 0 {
+		sitemaps = append(sitemaps, current)
+	}
+	return sitemaps, nil
+}
+
+// FormatLastMod formats a time in the W3C Datetime format used by sitemaps.
+// Times at midnight UTC are formatted as a date, e.g. "2024-03-01", other
+// times are formatted with RFC 3339, e.g. "2024-03-01T09:30:00Z". The zero
+// time is formatted as an empty string.
+func FormatLastMod(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	if t.Location() == time.UTC && t.Equal(t.Truncate(24*time.Hour)) {
+		return t.Format(time.DateOnly)
+	}
+	return t.Format(time.RFC3339)
+}
+
+// Gzip compresses the output of a component, e.g. a sitemap served as sitemap.xml.gz.
+func Gzip(c templ.Component) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) (err error) {
+		gw := gzip.NewWriter(w)
+		if err = c.Render(ctx, gw); err != nil {
+			return err
+		}
+		return gw.Close()
+	})
+}
@@ -0,0 +1,131 @@
+package sitemap
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSitemap(t *testing.T) {
+	var buf bytes.Buffer
+	err := Sitemap([]URL{
+		{Loc: "https://example.com/", LastMod: time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), ChangeFreq: Daily, Priority: 1},
+		{Loc: "https://example.com/search?q=a&b"},
+	}).Render(context.Background(), &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"><url><loc>https://example.com/</loc><lastmod>2024-03-01</lastmod><changefreq>daily</changefreq><priority>1.0</priority></url><url><loc>https://example.com/search?q=a&amp;b</loc></url></urlset>`
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestSitemapErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		urls     []URL
+		expected string
+	}{
+		{name: "relative URLs", urls: []URL{{Loc: "/about"}}, expected: "must be absolute"},
+		{name: "long URLs", urls: []URL{{Loc: "https://example.com/" + strings.Repeat("a", MaxLocLength)}}, expected: "exceeds the maximum length"},
+		{name: "invalid priority", urls: []URL{{Loc: "https://example.com/", Priority: 1.5}}, expected: "must be between 0.0 and 1.0"},
+		{name: "too many URLs", urls: make([]URL, MaxURLs+1), expected: "exceeds the maximum of 50000"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := Sitemap(test.urls).Render(context.Background(), &buf)
+			if err == nil || !strings.Contains(err.Error(), test.expected) {
+				t.Fatalf("expected error %q, got %v", test.expected, err)
+			}
+			if buf.Len() != 0 {
+				t.Errorf("expected no output, got %q", buf.String())
+			}
+		})
+	}
+}
+
+func TestIndex(t *testing.T) {
+	var buf bytes.Buffer
+	err := Index([]Entry{
+		{Loc: "https://example.com/sitemap-1.xml.gz", LastMod: time.Date(2024, time.March, 1, 9, 30, 0, 0, time.UTC)},
+	}).Render(context.Background(), &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"><sitemap><loc>https://example.com/sitemap-1.xml.gz</loc><lastmod>2024-03-01T09:30:00Z</lastmod></sitemap></sitemapindex>`
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestSplit(t *testing.T) {
+	urls := make([]URL, MaxURLs*2+1)
+	for i := range urls {
+		urls[i].Loc = "https://example.com/"
+	}
+	sitemaps, err := Split(urls)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var lengths []int
+	for _, s := range sitemaps {
+		lengths = append(lengths, len(s))
+	}
+	if diff := cmp.Diff([]int{MaxURLs, MaxURLs, 1}, lengths); diff != "" {
+		t.Error(diff)
+	}
+	t.Run("invalid URLs are an error", func(t *testing.T) {
+		if _, err := Split([]URL{{Loc: "about"}}); err == nil {
+			t.Error("expected an error")
+		}
+	})
+}
+
+func TestFormatLastMod(t *testing.T) {
+	tests := []struct {
+		name     string
+		t        time.Time
+		expected string
+	}{
+		{name: "zero", t: time.Time{}, expected: ""},
+		{name: "midnight UTC", t: time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), expected: "2024-03-01"},
+		{name: "time", t: time.Date(2024, time.March, 1, 9, 30, 0, 0, time.UTC), expected: "2024-03-01T09:30:00Z"},
+		{name: "time zone", t: time.Date(2024, time.March, 1, 0, 0, 0, 0, time.FixedZone("", 3600)), expected: "2024-03-01T00:00:00+01:00"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if actual := FormatLastMod(test.t); actual != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestGzip(t *testing.T) {
+	var buf bytes.Buffer
+	err := Gzip(Sitemap([]URL{{Loc: "https://example.com/"}})).Render(context.Background(), &buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	r, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatalf("failed to read gzip: %v", err)
+	}
+	actual, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("failed to read gzip: %v", err)
+	}
+	if !strings.Contains(string(actual), "<loc>https://example.com/</loc>") {
+		t.Errorf("unexpected output: %s", actual)
+	}
+}
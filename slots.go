@@ -0,0 +1,51 @@
+package templ
+
+import "context"
+
+// Slots are named components passed to a layout that has more than one
+// region, e.g. a sidebar and a footer, to complement its children.
+//
+//	templ layout(slots templ.Slots) {
+//		<aside>@slots.GetOr("sidebar", defaultSidebar())</aside>
+//		<main>{ children... }</main>
+//		if slots.Has("footer") {
+//			<footer>@slots.Get("footer")</footer>
+//		}
+//	}
+type Slots map[string]Component
+
+// Has returns true if the slot is set to a non-nil component.
+func (s Slots) Has(name string) bool {
+	return s[name] != nil
+}
+
+// Get returns the component in the slot, or NopComponent if the slot isn't
+// set, so that missing slots render nothing.
+func (s Slots) Get(name string) Component {
+	return s.GetOr(name, NopComponent)
+}
+
+// GetOr returns the component in the slot, or the default component if the
+// slot isn't set.
+func (s Slots) GetOr(name string, defaultComponent Component) Component {
+	if c := s[name]; c != nil {
+		return c
+	}
+	return defaultComponent
+}
+
+// WithBlocks passes blocks to the next component that's rendered, which
+// renders them in place of the blocks of the same name that it declares, e.g.
+// block content { ... }. Generated code passes the blocks that are the
+// children of a templ element, e.g. @layout() { block content { ... } }.
+func WithBlocks(ctx context.Context, blocks Slots) context.Context {
+	ctx, v := getContext(ctx)
+	v.blocks = blocks
+	return ctx
+}
+
+// GetBlocks returns the blocks passed to the component with WithBlocks.
+func GetBlocks(ctx context.Context) Slots {
+	_, v := getContext(ctx)
+	return v.blocks
+}
@@ -0,0 +1,90 @@
+package templ_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+)
+
+func TestSlots(t *testing.T) {
+	slots := templ.Slots{
+		"sidebar": templ.Raw("<nav>Sidebar</nav>"),
+		"footer":  nil,
+	}
+	render := func(c templ.Component) string {
+		t.Helper()
+		var sb strings.Builder
+		if err := c.Render(context.Background(), &sb); err != nil {
+			t.Fatalf("failed to render: %v", err)
+		}
+		return sb.String()
+	}
+
+	tests := []struct {
+		name        string
+		slot        string
+		expectedHas bool
+		expected    string
+		expectedOr  string
+	}{
+		{
+			name:        "set slots are rendered",
+			slot:        "sidebar",
+			expectedHas: true,
+			expected:    "<nav>Sidebar</nav>",
+			expectedOr:  "<nav>Sidebar</nav>",
+		},
+		{
+			name:       "nil slots are not set",
+			slot:       "footer",
+			expected:   "",
+			expectedOr: "default",
+		},
+		{
+			name:       "missing slots are not set",
+			slot:       "header",
+			expected:   "",
+			expectedOr: "default",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := slots.Has(tt.slot); actual != tt.expectedHas {
+				t.Errorf("expected Has to return %v, got %v", tt.expectedHas, actual)
+			}
+			if actual := render(slots.Get(tt.slot)); actual != tt.expected {
+				t.Errorf("expected Get to render %q, got %q", tt.expected, actual)
+			}
+			if actual := render(slots.GetOr(tt.slot, templ.Raw("default"))); actual != tt.expectedOr {
+				t.Errorf("expected GetOr to render %q, got %q", tt.expectedOr, actual)
+			}
+		})
+	}
+	t.Run("nil slots can be used", func(t *testing.T) {
+		var slots templ.Slots
+		if slots.Has("sidebar") {
+			t.Error("expected Has to return false")
+		}
+		if actual := render(slots.Get("sidebar")); actual != "" {
+			t.Errorf("expected nothing to be rendered, got %q", actual)
+		}
+	})
+}
+
+func TestBlocks(t *testing.T) {
+	blocks := templ.Slots{"content": templ.Raw("<p>Content</p>")}
+	ctx := templ.WithBlocks(context.Background(), blocks)
+	if actual := templ.GetBlocks(ctx); !actual.Has("content") {
+		t.Errorf("expected the blocks to be set, got %v", actual)
+	}
+	ctx = templ.ClearChildren(ctx)
+	if actual := templ.GetBlocks(ctx); actual != nil {
+		t.Errorf("expected the blocks to be cleared, got %v", actual)
+	}
+	if actual := templ.GetBlocks(context.Background()); actual != nil {
+		t.Errorf("expected no blocks, got %v", actual)
+	}
+}
@@ -0,0 +1,123 @@
+package templ
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// stackSourceMap maps the lines of a generated _templ.go file to positions in
+// the templ file it was generated from.
+type stackSourceMap struct {
+	templFileName string
+	encoded       string
+	once          sync.Once
+	lines         map[int]string
+}
+
+// positions decodes the line table on first use, so that registration at
+// program start is cheap.
+func (sm *stackSourceMap) positions() map[int]string {
+	sm.once.Do(func() {
+		sm.lines = make(map[int]string)
+		for _, entry := range strings.Split(sm.encoded, ",") {
+			goLine, templPos, ok := strings.Cut(entry, ":")
+			if !ok {
+				continue
+			}
+			line, err := strconv.Atoi(goLine)
+			if err != nil {
+				continue
+			}
+			sm.lines[line] = templPos
+		}
+	})
+	return sm.lines
+}
+
+// stackSourceMaps is keyed by the path of the generated Go file, as it appears in stack traces.
+var stackSourceMaps sync.Map
+
+// RegisterSourceMap is used by code generated with templ generate -include-source-map
+// to register the positions of the templ expressions in the generated file, so that
+// stack traces can be mapped to templ files with MapStack.
+//
+// lines is a comma separated list of generatedLine:templLine:templCol entries.
+func RegisterSourceMap(lines string) {
+	_, goFileName, _, ok := runtime.Caller(1)
+	if !ok || !strings.HasSuffix(goFileName, "_templ.go") {
+		return
+	}
+	stackSourceMaps.Store(goFileName, &stackSourceMap{
+		templFileName: strings.TrimSuffix(goFileName, "_templ.go") + ".templ",
+		encoded:       lines,
+	})
+}
+
+var stackFrameFileLine = regexp.MustCompile(`^\t(.+_templ\.go):(\d+)`)
+
+// MapStack adds the templ file position to each frame of a stack trace, as
+// returned by runtime/debug.Stack, that is within a generated _templ.go file
+// that registered its source map.
+//
+//	/app/components/header_templ.go:42 (/app/components/header.templ:7:12) +0x1d
+func MapStack(stack []byte) []byte {
+	lines := bytes.Split(stack, []byte("\n"))
+	for i, line := range lines {
+		m := stackFrameFileLine.FindSubmatchIndex(line)
+		if m == nil {
+			continue
+		}
+		v, ok := stackSourceMaps.Load(string(line[m[2]:m[3]]))
+		if !ok {
+			continue
+		}
+		sm := v.(*stackSourceMap)
+		goLine, _ := strconv.Atoi(string(line[m[4]:m[5]]))
+		templPos, ok := sm.positions()[goLine]
+		if !ok {
+			continue
+		}
+		mapped := make([]byte, 0, len(line)+len(sm.templFileName)+len(templPos)+4)
+		mapped = append(mapped, line[:m[1]]...)
+		mapped = append(mapped, " ("+sm.templFileName+":"+templPos+")"...)
+		mapped = append(mapped, line[m[1]:]...)
+		lines[i] = mapped
+	}
+	return bytes.Join(lines, []byte("\n"))
+}
+
+// PanicError is the error that a panic during rendering is converted into by
+// the ComponentHandler.
+type PanicError struct {
+	// Value passed to panic.
+	Value any
+	// Stack of the panicking goroutine, mapped to templ file positions with MapStack.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("templ: panic during render: %v\n\n%s", e.Value, e.Stack)
+}
+
+// Unwrap returns the panic value if it's an error.
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// newPanicError creates a PanicError from a recovered panic value. It must be
+// called from the deferred function that recovered the panic.
+func newPanicError(v any) *PanicError {
+	return &PanicError{
+		Value: v,
+		Stack: MapStack(debug.Stack()),
+	}
+}
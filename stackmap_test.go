@@ -0,0 +1,79 @@
+package templ
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMapStack(t *testing.T) {
+	stackSourceMaps.Store("/app/components/header_templ.go", &stackSourceMap{
+		templFileName: "/app/components/header.templ",
+		encoded:       "12:3:7,30:4:8",
+	})
+	defer stackSourceMaps.Delete("/app/components/header_templ.go")
+
+	stack := strings.Join([]string{
+		"goroutine 1 [running]:",
+		"main.header.func1({0x1, 0x2}, {0x3, 0x4})",
+		"\t/app/components/header_templ.go:30 +0x1d",
+		"main.footer.func1({0x1, 0x2}, {0x3, 0x4})",
+		"\t/app/components/header_templ.go:31 +0x2e",
+		"main.main()",
+		"\t/app/main.go:10 +0x3f",
+	}, "\n")
+	expected := strings.Join([]string{
+		"goroutine 1 [running]:",
+		"main.header.func1({0x1, 0x2}, {0x3, 0x4})",
+		"\t/app/components/header_templ.go:30 (/app/components/header.templ:4:8) +0x1d",
+		"main.footer.func1({0x1, 0x2}, {0x3, 0x4})",
+		"\t/app/components/header_templ.go:31 +0x2e",
+		"main.main()",
+		"\t/app/main.go:10 +0x3f",
+	}, "\n")
+	if actual := string(MapStack([]byte(stack))); actual != expected {
+		t.Errorf("expected:\n%s\n\ngot:\n%s", expected, actual)
+	}
+}
+
+func TestHandlerPanic(t *testing.T) {
+	panicky := ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		panic("oops")
+	})
+	t.Run("panics are returned to the error handler", func(t *testing.T) {
+		var handlerErr error
+		h := Handler(panicky, WithErrorHandler(func(r *http.Request, err error) http.Handler {
+			handlerErr = err
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			})
+		}))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("expected status %d, got %d", http.StatusInternalServerError, w.Code)
+		}
+		var pe *PanicError
+		if !errors.As(handlerErr, &pe) {
+			t.Fatalf("expected a *PanicError, got %v", handlerErr)
+		}
+		if pe.Value != "oops" {
+			t.Errorf("expected panic value %q, got %v", "oops", pe.Value)
+		}
+		if !strings.Contains(string(pe.Stack), "TestHandlerPanic") {
+			t.Errorf("expected the stack to include the test, got:\n%s", pe.Stack)
+		}
+	})
+	t.Run("the original value is re-panicked without an error handler", func(t *testing.T) {
+		defer func() {
+			if v := recover(); v != "oops" {
+				t.Errorf("expected the handler to panic with %q, got %#v", "oops", v)
+			}
+		}()
+		Handler(panicky).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
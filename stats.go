@@ -0,0 +1,95 @@
+package templ
+
+import (
+	"context"
+	"runtime/metrics"
+	"sync/atomic"
+	"time"
+)
+
+// RenderStats are the metrics of a render, e.g. to log for each request.
+type RenderStats struct {
+	// Components is the number of components generated by templ that were rendered.
+	Components int64
+	// Bytes is the size of the response body written by a ComponentHandler.
+	Bytes int64
+	// Allocs is the number of heap allocations made by the program while
+	// rendering. The runtime counts allocations in batches, and the count
+	// includes allocations made concurrently by other requests, so it's an
+	// approximation for each request, but totals over many requests are
+	// accurate.
+	Allocs int64
+	// CacheHits is the number of fragments written from a cache.
+	CacheHits int64
+	// CacheMisses is the number of fragments that weren't in a cache.
+	CacheMisses int64
+	// Duration is the time since the stats started being collected.
+	Duration time.Duration
+}
+
+// RenderStatsCollector collects the RenderStats of components rendered with
+// a context returned by CollectRenderStats. It's safe for concurrent use.
+type RenderStatsCollector struct {
+	start       time.Time
+	startAllocs uint64
+
+	components  atomic.Int64
+	bytes       atomic.Int64
+	cacheHits   atomic.Int64
+	cacheMisses atomic.Int64
+}
+
+// CollectRenderStats returns a context that collects the stats of components
+// rendered with it, and the collector to read them from once rendering is
+// complete.
+//
+// To collect the stats of each request rendered by templ.Handler, use the
+// WithRenderStats option.
+func CollectRenderStats(ctx context.Context) (context.Context, *RenderStatsCollector) {
+	c := &RenderStatsCollector{
+		start:       time.Now(),
+		startAllocs: heapAllocs(),
+	}
+	ctx, v := getContext(ctx)
+	v.stats = c
+	return ctx, c
+}
+
+// Stats returns the stats collected so far.
+func (c *RenderStatsCollector) Stats() RenderStats {
+	return RenderStats{
+		Components:  c.components.Load(),
+		Bytes:       c.bytes.Load(),
+		Allocs:      int64(heapAllocs() - c.startAllocs),
+		CacheHits:   c.cacheHits.Load(),
+		CacheMisses: c.cacheMisses.Load(),
+		Duration:    time.Since(c.start),
+	}
+}
+
+// getRenderStats returns the collector of the context, or nil.
+func getRenderStats(ctx context.Context) *RenderStatsCollector {
+	if v, ok := ctx.Value(contextKey).(*contextValue); ok {
+		return v.stats
+	}
+	return nil
+}
+
+func addBytesWritten(ctx context.Context, n int) {
+	if s := getRenderStats(ctx); s != nil {
+		s.bytes.Add(int64(n))
+	}
+}
+
+const heapAllocsMetric = "/gc/heap/allocs:objects"
+
+// heapAllocs returns the total number of heap allocations made by the program.
+// Unlike runtime.ReadMemStats, it doesn't stop the world.
+func heapAllocs() uint64 {
+	sample := []metrics.Sample{{Name: heapAllocsMetric}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return sample[0].Value.Uint64()
+}
@@ -0,0 +1,92 @@
+package templ_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/a-h/templ"
+)
+
+// generated returns a component that initializes the context like components
+// generated by templ, and renders its children.
+func generated(text string, children ...templ.Component) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		ctx = templ.InitializeContext(ctx)
+		if _, err := io.WriteString(w, text); err != nil {
+			return err
+		}
+		for _, c := range children {
+			if err := c.Render(ctx, w); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func TestRenderStats(t *testing.T) {
+	t.Run("components and cache hits are counted", func(t *testing.T) {
+		cache := templ.NewMemoryCache(1024)
+		page := generated("<main>",
+			generated("<h1>"),
+			cache.Fragment("menu", time.Minute, generated("<nav>")),
+			cache.Fragment("menu", time.Minute, generated("<nav>")),
+		)
+		ctx, collector := templ.CollectRenderStats(context.Background())
+		if err := page.Render(ctx, io.Discard); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		stats := collector.Stats()
+		// The second menu is written from the cache.
+		if stats.Components != 3 {
+			t.Errorf("expected 3 components, got %d", stats.Components)
+		}
+		if stats.CacheHits != 1 || stats.CacheMisses != 1 {
+			t.Errorf("expected 1 cache hit and 1 miss, got %d and %d", stats.CacheHits, stats.CacheMisses)
+		}
+		if stats.Duration <= 0 {
+			t.Errorf("expected a duration, got %v", stats.Duration)
+		}
+	})
+	t.Run("components rendered in parallel are counted", func(t *testing.T) {
+		ctx, collector := templ.CollectRenderStats(context.Background())
+		page := generated("", templ.Parallel(generated("a"), generated("b")))
+		if err := page.Render(ctx, io.Discard); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if stats := collector.Stats(); stats.Components != 3 {
+			t.Errorf("expected 3 components, got %d", stats.Components)
+		}
+	})
+	t.Run("the handler passes the stats of each request to the stats handler", func(t *testing.T) {
+		var stats templ.RenderStats
+		// Allocations are counted by the runtime in batches, so allocate enough to be counted.
+		allocating := templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			var items []*[64]byte
+			for i := 0; i < 100_000; i++ {
+				items = append(items, new([64]byte))
+			}
+			_, err := io.WriteString(w, strconv.Itoa(len(items)))
+			return err
+		})
+		h := templ.Handler(generated("<p>Hello</p>", generated(" world"), allocating), templ.WithRenderStats(func(r *http.Request, s templ.RenderStats) {
+			stats = s
+		}))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		if stats.Components != 2 {
+			t.Errorf("expected 2 components, got %d", stats.Components)
+		}
+		if stats.Bytes != int64(w.Body.Len()) {
+			t.Errorf("expected %d bytes, got %d", w.Body.Len(), stats.Bytes)
+		}
+		if stats.Allocs <= 0 {
+			t.Errorf("expected allocations to be counted, got %d", stats.Allocs)
+		}
+	})
+}
@@ -0,0 +1,170 @@
+package templ
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+)
+
+// StringConverter converts a value into the string that is rendered by a
+// string expression, e.g. { v }.
+type StringConverter func(v any) (string, error)
+
+var (
+	stringConverters = map[reflect.Type]StringConverter{}
+	// interfaceStringConverters are the converters registered for interface
+	// types, in the order they were registered.
+	interfaceStringConverters []interfaceStringConverter
+	stringConvertersMutex     sync.RWMutex
+)
+
+type interfaceStringConverter struct {
+	t reflect.Type
+	f StringConverter
+}
+
+// RegisterStringConverter registers a function used to convert values of type T
+// into strings when they're used in string expressions or attribute values.
+//
+// Registered converters take precedence over fmt.Stringer and
+// encoding.TextMarshaler implementations. If T is an interface type, the
+// converter is used for values of the types that implement it, unless a
+// converter is registered for the type itself. Converters can't be registered
+// for string, bool, and numeric types, but can be registered for named types,
+// e.g. type Celsius float64.
+func RegisterStringConverter[T any](f func(v T) (string, error)) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	converter := func(v any) (string, error) {
+		return f(v.(T))
+	}
+	stringConvertersMutex.Lock()
+	defer stringConvertersMutex.Unlock()
+	if t.Kind() != reflect.Interface {
+		stringConverters[t] = converter
+		return
+	}
+	for i, ic := range interfaceStringConverters {
+		if ic.t == t {
+			interfaceStringConverters[i].f = converter
+			return
+		}
+	}
+	interfaceStringConverters = append(interfaceStringConverters, interfaceStringConverter{t: t, f: converter})
+}
+
+func getStringConverter(v any) (f StringConverter, ok bool) {
+	stringConvertersMutex.RLock()
+	defer stringConvertersMutex.RUnlock()
+	if len(stringConverters) == 0 && len(interfaceStringConverters) == 0 {
+		return nil, false
+	}
+	t := reflect.TypeOf(v)
+	if f, ok = stringConverters[t]; ok {
+		return f, true
+	}
+	for _, ic := range interfaceStringConverters {
+		if t.Implements(ic.t) {
+			return ic.f, true
+		}
+	}
+	return nil, false
+}
+
+// ErrUnsupportedStringType is returned when a value used in a string expression
+// can't be converted to a string.
+type ErrUnsupportedStringType struct {
+	Type reflect.Type
+}
+
+func (e ErrUnsupportedStringType) Error() string {
+	return fmt.Sprintf("templ: cannot convert value of type %v to string, implement fmt.Stringer or encoding.TextMarshaler, or use templ.RegisterStringConverter", e.Type)
+}
+
+// ToString converts v to a string.
+//
+// Strings are returned as-is, and numeric and boolean values are formatted
+// using the strconv package. Values with a converter registered using
+// RegisterStringConverter are converted using the converter, followed by
+// values that implement fmt.Stringer or encoding.TextMarshaler. Values of
+// other types whose underlying type is a string, numeric or boolean type are
+// formatted in the same way as the underlying type.
+func ToString(v any) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int8:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int16:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int32:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint8:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint16:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case uintptr:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	}
+	if f, ok := getStringConverter(v); ok {
+		return f(v)
+	}
+	switch v := v.(type) {
+	case fmt.Stringer:
+		return v.String(), nil
+	case encoding.TextMarshaler:
+		b, err := v.MarshalText()
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	case []byte:
+		return string(v), nil
+	case error:
+		return v.Error(), nil
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(rv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, 32), nil
+	case reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, 64), nil
+	case reflect.Complex64:
+		return strconv.FormatComplex(rv.Complex(), 'f', -1, 64), nil
+	case reflect.Complex128:
+		return strconv.FormatComplex(rv.Complex(), 'f', -1, 128), nil
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return "", nil
+		}
+		return ToString(rv.Elem().Interface())
+	}
+	return "", ErrUnsupportedStringType{Type: rv.Type()}
+}
@@ -0,0 +1,108 @@
+package templ_test
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/a-h/templ"
+)
+
+type stringerValue struct{}
+
+func (stringerValue) String() string { return "stringer" }
+
+type namedString string
+
+type registeredValue struct {
+	Name string
+}
+
+type labeler interface {
+	Label() string
+}
+
+type labeledValue struct{}
+
+func (labeledValue) Label() string  { return "label" }
+func (labeledValue) String() string { return "stringer" }
+
+type celsius float64
+
+func TestToString(t *testing.T) {
+	templ.RegisterStringConverter(func(v registeredValue) (string, error) {
+		return "registered:" + v.Name, nil
+	})
+	templ.RegisterStringConverter(func(v labeler) (string, error) {
+		return "labeler:" + v.Label(), nil
+	})
+	templ.RegisterStringConverter(func(v celsius) (string, error) {
+		return strconv.FormatFloat(float64(v), 'f', 1, 64) + "°C", nil
+	})
+	s := "pointer"
+	tests := []struct {
+		name     string
+		input    any
+		expected string
+	}{
+		{name: "string", input: "abc", expected: "abc"},
+		{name: "named string", input: namedString("named"), expected: "named"},
+		{name: "int", input: 123, expected: "123"},
+		{name: "negative int64", input: int64(-5), expected: "-5"},
+		{name: "uint8", input: uint8(255), expected: "255"},
+		{name: "float64", input: 1.25, expected: "1.25"},
+		{name: "float32", input: float32(0.1), expected: "0.1"},
+		{name: "bool", input: true, expected: "true"},
+		{name: "fmt.Stringer", input: stringerValue{}, expected: "stringer"},
+		{name: "time.Duration", input: time.Second * 90, expected: "1m30s"},
+		{name: "encoding.TextMarshaler", input: net.IPv4(127, 0, 0, 1), expected: "127.0.0.1"},
+		{name: "registered converter", input: registeredValue{Name: "a"}, expected: "registered:a"},
+		{name: "registered converter for an interface", input: labeledValue{}, expected: "labeler:label"},
+		{name: "registered converter for a named numeric type", input: celsius(21), expected: "21.0°C"},
+		{name: "uintptr", input: uintptr(7), expected: "7"},
+		{name: "complex128", input: complex(1, 2), expected: "(1+2i)"},
+		{name: "pointer", input: &s, expected: "pointer"},
+		{name: "nil pointer", input: (*string)(nil), expected: ""},
+		{name: "nil", input: nil, expected: ""},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := templ.ToString(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, actual)
+			}
+		})
+	}
+	t.Run("unsupported types return an error", func(t *testing.T) {
+		_, err := templ.ToString(struct{}{})
+		var unsupportedErr templ.ErrUnsupportedStringType
+		if !errors.As(err, &unsupportedErr) {
+			t.Fatalf("expected ErrUnsupportedStringType, got %v", err)
+		}
+	})
+}
+
+func TestJoinStringErrs(t *testing.T) {
+	t.Run("values are converted to strings", func(t *testing.T) {
+		actual, err := templ.JoinStringErrs(42)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if actual != "42" {
+			t.Errorf("expected %q, got %q", "42", actual)
+		}
+	})
+	t.Run("errors are returned", func(t *testing.T) {
+		errExpected := errors.New("failed")
+		_, err := templ.JoinStringErrs("value", errExpected)
+		if !errors.Is(err, errExpected) {
+			t.Errorf("expected %v, got %v", errExpected, err)
+		}
+	})
+}
@@ -0,0 +1,120 @@
+package templ
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/a-h/templ/safehtml"
+)
+
+// SanitizeStyleAttributeValues returns the value of a style attribute, e.g.
+// style={ map[string]any{"width": templ.Px(10)} }, whose declarations are
+// sanitized. Supported types are:
+//
+//   - string, e.g. "color: red; width: 10px", whose declarations are sanitized.
+//   - SafeCSS, which is output as it is.
+//   - map[string]string and map[string]any, whose keys are properties.
+//   - KeyValue[string, string], and slices of them.
+//   - KeyValue[string, bool] and KeyValue[SafeCSS, bool], which are output if
+//     the value is true.
+//
+// The values of map[string]any can be strings, numbers, or values that
+// implement CSSValue, e.g. templ.Px(10), templ.Percent(50) or templ.RGB(255, 0, 0).
+// Non-nil errors are returned, so functions that return a value and an error
+// can be used.
+func SanitizeStyleAttributeValues(values ...any) (string, error) {
+	var sb strings.Builder
+	for _, v := range values {
+		if err := writeStyleAttributeValue(&sb, v); err != nil {
+			return "", err
+		}
+	}
+	return sb.String(), nil
+}
+
+func writeStyleAttributeValue(sb *strings.Builder, v any) error {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case error:
+		return v
+	case string:
+		for _, declaration := range strings.Split(v, ";") {
+			if strings.TrimSpace(declaration) == "" {
+				continue
+			}
+			property, value, ok := strings.Cut(declaration, ":")
+			if !ok {
+				sb.WriteString(safehtml.InnocuousPropertyName + ":" + safehtml.InnocuousPropertyValue + ";")
+				continue
+			}
+			sb.WriteString(string(SanitizeCSS(strings.TrimSpace(property), strings.TrimSpace(value))))
+		}
+	case SafeCSS:
+		sb.WriteString(string(v))
+	case map[string]string:
+		for _, k := range sortedStringKeys(v) {
+			sb.WriteString(string(SanitizeCSS(k, v[k])))
+		}
+	case map[string]any:
+		for _, k := range sortedStringKeys(v) {
+			css, err := sanitizeStyleProperty(k, v[k])
+			if err != nil {
+				return err
+			}
+			sb.WriteString(string(css))
+		}
+	case KeyValue[string, string]:
+		sb.WriteString(string(SanitizeCSS(v.Key, v.Value)))
+	case []KeyValue[string, string]:
+		for _, kv := range v {
+			sb.WriteString(string(SanitizeCSS(kv.Key, kv.Value)))
+		}
+	case KeyValue[string, bool]:
+		if v.Value {
+			return writeStyleAttributeValue(sb, v.Key)
+		}
+	case KeyValue[SafeCSS, bool]:
+		if v.Value {
+			sb.WriteString(string(v.Key))
+		}
+	case CSSValue:
+		return fmt.Errorf("templ: style attribute value %q has no property, use a map, e.g. map[string]any{\"width\": value}", v.CSSValue())
+	default:
+		return fmt.Errorf("templ: unsupported type for style attribute: %T", v)
+	}
+	return nil
+}
+
+// sanitizeStyleProperty returns the declaration of a property whose value is a
+// string, number or CSSValue.
+func sanitizeStyleProperty(property string, value any) (SafeCSS, error) {
+	switch v := value.(type) {
+	case CSSValue:
+		return SanitizeCSS(property, v.CSSValue()), nil
+	case SafeCSSProperty:
+		return SanitizeCSS(property, v), nil
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.String:
+		return SanitizeCSS(property, rv.String()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return SanitizeCSS(property, SafeCSSProperty(fmt.Sprint(value))), nil
+	case reflect.Float32, reflect.Float64:
+		return SanitizeCSS(property, SafeCSSProperty(formatCSSNumber(rv.Float()))), nil
+	}
+	return "", fmt.Errorf("templ: unsupported type for style property %q: %T", property, value)
+}
+
+func sortedStringKeys[V any](m map[string]V) (keys []string) {
+	keys = make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,109 @@
+package templ_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/a-h/templ"
+)
+
+func TestSanitizeStyleAttributeValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []any
+		expected string
+	}{
+		{
+			name:     "declarations of strings are sanitized",
+			input:    []any{"color: red; background-image: url(javascript:alert(1));"},
+			expected: "color:red;background-image:zTemplUnsafeCSSPropertyValue;",
+		},
+		{
+			name:     "strings without a property are replaced",
+			input:    []any{"red"},
+			expected: "zTemplUnsafeCSSPropertyName:zTemplUnsafeCSSPropertyValue;",
+		},
+		{
+			name:     "safe CSS is unchanged",
+			input:    []any{templ.SafeCSS("color:red;")},
+			expected: "color:red;",
+		},
+		{
+			name:     "maps are sorted by property",
+			input:    []any{map[string]string{"width": "10px", "color": "red"}},
+			expected: "color:red;width:10px;",
+		},
+		{
+			name: "typed values are formatted",
+			input: []any{map[string]any{
+				"width":   templ.Percent(50),
+				"height":  templ.Px(10),
+				"color":   templ.RGB(255, 0, 0),
+				"z-index": 2,
+				"opacity": 0.5,
+			}},
+			expected: "color:#ff0000;height:10px;opacity:0.5;width:50%;z-index:2;",
+		},
+		{
+			name:     "string values of maps are sanitized",
+			input:    []any{map[string]any{"color": "red; } body {"}},
+			expected: "color:zTemplUnsafeCSSPropertyValue;",
+		},
+		{
+			name:     "key values are output",
+			input:    []any{templ.KV("color", "red"), []templ.KeyValue[string, string]{templ.KV("width", "1px")}},
+			expected: "color:red;width:1px;",
+		},
+		{
+			name:     "conditional key values are output if true",
+			input:    []any{templ.KV("color: red", true), templ.KV("width: 1px", false), templ.KV(templ.SafeCSS("height:1px;"), true)},
+			expected: "color:red;height:1px;",
+		},
+		{
+			name:     "nil errors are ignored",
+			input:    []any{"color: red", error(nil)},
+			expected: "color:red;",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := templ.SanitizeStyleAttributeValues(tt.input...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, actual)
+			}
+		})
+	}
+	errorTests := []struct {
+		name  string
+		input []any
+	}{
+		{
+			name:  "errors are returned",
+			input: []any{"color: red", errors.New("failed")},
+		},
+		{
+			name:  "typed values without a property are an error",
+			input: []any{templ.Px(10)},
+		},
+		{
+			name:  "unsupported types are an error",
+			input: []any{10},
+		},
+		{
+			name:  "unsupported map values are an error",
+			input: []any{map[string]any{"width": []int{1}}},
+		},
+	}
+	for _, tt := range errorTests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := templ.SanitizeStyleAttributeValues(tt.input...); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
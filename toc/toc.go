@@ -0,0 +1,127 @@
+// Package toc provides heading components that are listed in a table of
+// contents. The table of contents is rendered before the headings, so pages
+// must be rendered with templ.TwoPass.
+//
+//	templ.Handler(templ.TwoPass(page()))
+package toc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/a-h/templ"
+)
+
+// Entry is a heading in a table of contents.
+type Entry struct {
+	// Level of the heading, from 1 for <h1> to 6 for <h6>.
+	Level int
+	// ID of the heading element, which the table of contents links to.
+	ID    string
+	Title string
+}
+
+var entries = templ.NewCollectKey[Entry]()
+
+// Add adds an entry to the table of contents, for headings that aren't
+// rendered with the Heading component.
+func Add(ctx context.Context, e Entry) {
+	entries.Add(ctx, e)
+}
+
+// Entries returns the entries of the table of contents, in the order that
+// they're rendered. Outside of templ.TwoPass, it returns nil.
+func Entries(ctx context.Context) []Entry {
+	return entries.Values(ctx)
+}
+
+// Heading renders a heading element, and adds it to the table of contents.
+// If id is empty, an ID is created from the title with Slug. If the
+// component has children, they're rendered within the heading instead of the
+// title.
+func Heading(level int, id, title string) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) (err error) {
+		if level < 1 || level > 6 {
+			return fmt.Errorf("toc: heading level %d of %q must be between 1 and 6", level, title)
+		}
+		if id == "" {
+			id = Slug(title)
+		}
+		Add(ctx, Entry{Level: level, ID: id, Title: title})
+		children := templ.GetChildren(ctx)
+		ctx = templ.ClearChildren(ctx)
+		if _, err = fmt.Fprintf(w, `<h%d id="%s">`, level, templ.EscapeString(id)); err != nil {
+			return err
+		}
+		buf := templ.GetBuffer()
+		defer templ.ReleaseBuffer(buf)
+		if err = children.Render(ctx, buf); err != nil {
+			return err
+		}
+		if buf.Len() == 0 {
+			buf.WriteString(templ.EscapeString(title))
+		}
+		if _, err = buf.WriteTo(w); err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "</h%d>", level)
+		return err
+	})
+}
+
+// List renders the entries of the table of contents as nested lists of links.
+// Entries are nested within the closest preceding entry with a lower level.
+// If there are no entries, nothing is rendered.
+func List() templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		nodes := outline(Entries(ctx))
+		if len(nodes) == 0 {
+			return nil
+		}
+		return list(nodes).Render(ctx, w)
+	})
+}
+
+type node struct {
+	Entry
+	Children []*node
+}
+
+func outline(entries []Entry) (roots []*node) {
+	var stack []*node
+	for _, e := range entries {
+		n := &node{Entry: e}
+		for len(stack) > 0 && stack[len(stack)-1].Level >= e.Level {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			roots = append(roots, n)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, n)
+		}
+		stack = append(stack, n)
+	}
+	return roots
+}
+
+// Slug creates an ID from a title, e.g. "Getting started" becomes "getting-started".
+func Slug(title string) string {
+	var sb strings.Builder
+	separate := false
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if separate && sb.Len() > 0 {
+				sb.WriteRune('-')
+			}
+			separate = false
+			sb.WriteRune(r)
+			continue
+		}
+		separate = true
+	}
+	return sb.String()
+}
@@ -0,0 +1,92 @@
+package toc
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/google/go-cmp/cmp"
+)
+
+func render(t *testing.T, c templ.Component) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := c.Render(context.Background(), &buf); err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+	return buf.String()
+}
+
+func TestList(t *testing.T) {
+	page := templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		for _, c := range []templ.Component{
+			List(),
+			Heading(1, "", "Getting started"),
+			Heading(2, "install", "Install & run"),
+			Heading(3, "", "On macOS"),
+			Heading(2, "", "Next steps"),
+			Heading(1, "", "Reference"),
+		} {
+			if err := c.Render(ctx, w); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	actual := render(t, templ.TwoPass(page))
+	headings := `<h1 id="getting-started">Getting started</h1>` +
+		`<h2 id="install">Install &amp; run</h2>` +
+		`<h3 id="on-macos">On macOS</h3>` +
+		`<h2 id="next-steps">Next steps</h2>` +
+		`<h1 id="reference">Reference</h1>`
+	expected := `<ul>` +
+		`<li><a href="#getting-started">Getting started</a> <ul>` +
+		`<li><a href="#install">Install &amp; run</a> <ul><li><a href="#on-macos">On macOS</a> </li></ul></li>` +
+		`<li><a href="#next-steps">Next steps</a> </li>` +
+		`</ul></li>` +
+		`<li><a href="#reference">Reference</a> </li>` +
+		`</ul>` + headings
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Error(diff)
+	}
+	t.Run("outside of TwoPass, the list is empty", func(t *testing.T) {
+		if diff := cmp.Diff(headings, render(t, page)); diff != "" {
+			t.Error(diff)
+		}
+	})
+}
+
+func TestHeading(t *testing.T) {
+	t.Run("children are rendered instead of the title", func(t *testing.T) {
+		ctx := templ.WithChildren(context.Background(), templ.Raw("<code>templ</code>"))
+		var buf bytes.Buffer
+		if err := Heading(2, "", "templ").Render(ctx, &buf); err != nil {
+			t.Fatalf("failed to render: %v", err)
+		}
+		if diff := cmp.Diff(`<h2 id="templ"><code>templ</code></h2>`, buf.String()); diff != "" {
+			t.Error(diff)
+		}
+	})
+	t.Run("levels must be between 1 and 6", func(t *testing.T) {
+		if err := Heading(7, "", "Title").Render(context.Background(), io.Discard); err == nil {
+			t.Error("expected an error")
+		}
+	})
+}
+
+func TestSlug(t *testing.T) {
+	tests := map[string]string{
+		"Getting started": "getting-started",
+		"  What's new?  ": "what-s-new",
+		"Go 1.22 & templ": "go-1-22-templ",
+		"Überblick":       "überblick",
+		"---":             "",
+	}
+	for title, expected := range tests {
+		if actual := Slug(title); actual != expected {
+			t.Errorf("Slug(%q): expected %q, got %q", title, expected, actual)
+		}
+	}
+}
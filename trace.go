@@ -0,0 +1,121 @@
+package templ
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+)
+
+// RenderTrace records the components rendered within a context, and how
+// long each one took to render. Components generated by templ generate in
+// watch mode add themselves to the trace set with WithRenderTrace.
+type RenderTrace struct {
+	// Root contains the top level components.
+	Root  *TraceNode
+	start time.Time
+	m     sync.Mutex
+}
+
+// TraceNode is a component within a RenderTrace.
+type TraceNode struct {
+	// Name of the component, e.g. "header", or "Page.header" for methods.
+	Name string
+	// Location of the component's declaration, e.g. "components/header.templ:12".
+	Location string
+	// Start is the time that the component started rendering, relative to the
+	// start of the trace.
+	Start time.Duration
+	// Duration of the render, including the component's children.
+	Duration time.Duration
+	// Bytes written by the component, including its children.
+	Bytes int
+	// CacheHits is the number of Once components within the component that
+	// were skipped because they had already been rendered.
+	CacheHits int
+	Children  []*TraceNode
+}
+
+// NewRenderTrace creates a RenderTrace that starts at the current time.
+func NewRenderTrace() *RenderTrace {
+	return &RenderTrace{
+		Root:  &TraceNode{},
+		start: time.Now(),
+	}
+}
+
+// Count returns the number of components within the trace.
+func (t *RenderTrace) Count() (count int) {
+	t.m.Lock()
+	defer t.m.Unlock()
+	var walk func(n *TraceNode)
+	walk = func(n *TraceNode) {
+		for _, c := range n.Children {
+			count++
+			walk(c)
+		}
+	}
+	walk(t.Root)
+	return count
+}
+
+type renderTraceContextKey struct{}
+
+// traceFrame is the component currently being rendered within a trace.
+type traceFrame struct {
+	trace *RenderTrace
+	node  *TraceNode
+}
+
+// WithRenderTrace records the components rendered using the context to the trace.
+func WithRenderTrace(ctx context.Context, t *RenderTrace) context.Context {
+	return context.WithValue(ctx, renderTraceContextKey{}, &traceFrame{trace: t, node: t.Root})
+}
+
+// GetRenderTrace returns the trace set with WithRenderTrace, or nil if none has been set.
+func GetRenderTrace(ctx context.Context) *RenderTrace {
+	if f, ok := ctx.Value(renderTraceContextKey{}).(*traceFrame); ok {
+		return f.trace
+	}
+	return nil
+}
+
+// TraceStart is used by generated code to add a component to the render
+// trace of the context, and call its render hooks, if there are any. The
+// returned function must be called when the component has been rendered to w.
+func TraceStart(ctx context.Context, name, location string, w *bytes.Buffer) (context.Context, func()) {
+	ctx, hooksEnd := hooksStart(ctx, name, location, w)
+	parent, ok := ctx.Value(renderTraceContextKey{}).(*traceFrame)
+	if !ok {
+		return ctx, hooksEnd
+	}
+	t := parent.trace
+	start := time.Now()
+	startLen := w.Len()
+	node := &TraceNode{
+		Name:     name,
+		Location: location,
+		Start:    start.Sub(t.start),
+	}
+	t.m.Lock()
+	parent.node.Children = append(parent.node.Children, node)
+	t.m.Unlock()
+	return context.WithValue(ctx, renderTraceContextKey{}, &traceFrame{trace: t, node: node}), func() {
+		t.m.Lock()
+		node.Duration = time.Since(start)
+		node.Bytes = w.Len() - startLen
+		t.m.Unlock()
+		hooksEnd()
+	}
+}
+
+// traceCacheHit records that a component was skipped because it had already been rendered.
+func traceCacheHit(ctx context.Context) {
+	f, ok := ctx.Value(renderTraceContextKey{}).(*traceFrame)
+	if !ok {
+		return
+	}
+	f.trace.m.Lock()
+	defer f.trace.m.Unlock()
+	f.node.CacheHits++
+}
@@ -0,0 +1,173 @@
+// Package trace records the components rendered by recent HTTP requests, and
+// serves a page to view them, to help diagnose slow pages during development.
+//
+// Components are only added to render traces when their code is generated by
+// templ generate in watch mode.
+package trace
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/a-h/templ"
+)
+
+// Request is the render trace of an HTTP request.
+type Request struct {
+	ID       int
+	Method   string
+	URL      string
+	Time     time.Time
+	Duration time.Duration
+	Trace    *templ.RenderTrace
+}
+
+// Recorder records the render traces of recent requests.
+type Recorder struct {
+	// Limit is the number of requests to keep.
+	Limit    int
+	m        sync.Mutex
+	requests []*Request
+	nextID   int
+}
+
+// NewRecorder creates a Recorder that keeps the last 100 requests.
+func NewRecorder() *Recorder {
+	return &Recorder{
+		Limit: 100,
+	}
+}
+
+// Middleware records the components rendered by requests to next. Requests
+// that don't render any components aren't recorded.
+func (rec *Recorder) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t := templ.NewRenderTrace()
+		start := time.Now()
+		next.ServeHTTP(w, r.WithContext(templ.WithRenderTrace(r.Context(), t)))
+		if t.Count() == 0 {
+			return
+		}
+		rec.add(&Request{
+			Method:   r.Method,
+			URL:      r.URL.String(),
+			Time:     start,
+			Duration: time.Since(start),
+			Trace:    t,
+		})
+	})
+}
+
+func (rec *Recorder) add(req *Request) {
+	rec.m.Lock()
+	defer rec.m.Unlock()
+	rec.nextID++
+	req.ID = rec.nextID
+	rec.requests = append(rec.requests, req)
+	if rec.Limit > 0 && len(rec.requests) > rec.Limit {
+		rec.requests = rec.requests[len(rec.requests)-rec.Limit:]
+	}
+}
+
+// Requests returns the recorded requests, most recent first.
+func (rec *Recorder) Requests() (requests []*Request) {
+	rec.m.Lock()
+	defer rec.m.Unlock()
+	for i := len(rec.requests) - 1; i >= 0; i-- {
+		requests = append(requests, rec.requests[i])
+	}
+	return requests
+}
+
+// Request returns the recorded request with the ID.
+func (rec *Recorder) Request(id int) (req *Request, ok bool) {
+	rec.m.Lock()
+	defer rec.m.Unlock()
+	for _, req := range rec.requests {
+		if req.ID == id {
+			return req, true
+		}
+	}
+	return nil, false
+}
+
+// ServeHTTP serves a list of the recorded requests, or the render trace of
+// the request given by the id query string parameter.
+func (rec *Recorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	// Render the viewer with a separate trace, so that it's not recorded if
+	// the Recorder is also used as middleware.
+	ctx := templ.WithRenderTrace(r.Context(), templ.NewRenderTrace())
+	if idParam := r.URL.Query().Get("id"); idParam != "" {
+		id, err := strconv.Atoi(idParam)
+		if err != nil {
+			http.Error(w, "invalid id", http.StatusBadRequest)
+			return
+		}
+		req, ok := rec.Request(id)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_ = requestPage(req, flatten(req.Trace)).Render(ctx, w)
+		return
+	}
+	_ = listPage(rec.Requests()).Render(ctx, w)
+}
+
+// bar is a component drawn in the flame graph.
+type bar struct {
+	Node  *templ.TraceNode
+	Depth int
+	// Left and Width are percentages of the duration of the trace.
+	Left  float64
+	Width float64
+}
+
+// flatten returns the bars of the flame graph, in depth-first order.
+func flatten(t *templ.RenderTrace) (bars []bar) {
+	var end time.Duration
+	for _, n := range t.Root.Children {
+		end = max(end, n.Start+n.Duration)
+	}
+	if end == 0 {
+		end = 1
+	}
+	var walk func(nodes []*templ.TraceNode, depth int)
+	walk = func(nodes []*templ.TraceNode, depth int) {
+		for _, n := range nodes {
+			bars = append(bars, bar{
+				Node:  n,
+				Depth: depth,
+				Left:  float64(n.Start) / float64(end) * 100,
+				Width: float64(n.Duration) / float64(end) * 100,
+			})
+			walk(n.Children, depth+1)
+		}
+	}
+	walk(t.Root.Children, 0)
+	return bars
+}
+
+// barHeight is the height of a bar in the flame graph, in pixels.
+const barHeight = 22
+
+// style returns a style attribute. Style attributes can't be expressions, so
+// they're passed as spread attributes.
+func style(format string, a ...any) templ.Attributes {
+	return templ.Attributes{"style": fmt.Sprintf(format, a...)}
+}
+
+func maxDepth(bars []bar) (depth int) {
+	for _, b := range bars {
+		depth = max(depth, b.Depth)
+	}
+	return depth
+}
+
+func barTitle(b bar) string {
+	return fmt.Sprintf("%s (%s)\n%s, %d bytes, %d cache hits", b.Node.Name, b.Node.Location, b.Node.Duration, b.Node.Bytes, b.Node.CacheHits)
+}
@@ -0,0 +1,105 @@
+package trace
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+)
+
+func traced(name string, children ...templ.Component) templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) (err error) {
+		var buf bytes.Buffer
+		ctx, end := templ.TraceStart(ctx, name, name+".templ:1", &buf)
+		buf.WriteString("<div>")
+		for _, c := range children {
+			if err = c.Render(ctx, &buf); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("</div>")
+		end()
+		_, err = buf.WriteTo(w)
+		return err
+	})
+}
+
+func TestRecorder(t *testing.T) {
+	rec := NewRecorder()
+	rec.Limit = 2
+	mux := http.NewServeMux()
+	mux.Handle("/page", templ.Handler(traced("page", traced("header"), traced("footer"))))
+	mux.Handle("/static", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "static")
+	}))
+	h := rec.Middleware(mux)
+
+	for _, path := range []string{"/page", "/static", "/page", "/page"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	requests := rec.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("expected the last 2 requests that rendered components to be kept, got %d", len(requests))
+	}
+	if requests[0].ID != 3 || requests[1].ID != 2 {
+		t.Errorf("expected requests 3 and 2, most recent first, got %d and %d", requests[0].ID, requests[1].ID)
+	}
+	if requests[0].URL != "/page" || requests[0].Trace.Count() != 3 {
+		t.Errorf("unexpected request %s with %d components", requests[0].URL, requests[0].Trace.Count())
+	}
+
+	t.Run("the list of requests is served", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		rec.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		if !strings.Contains(w.Body.String(), `href="?id=3"`) {
+			t.Errorf("expected a link to request 3, got:\n%s", w.Body.String())
+		}
+	})
+	t.Run("the trace of a request is served", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		rec.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?id=3", nil))
+		body := w.Body.String()
+		for _, expected := range []string{">page</div>", ">header</div>", ">footer</div>", "<td>header.templ:1</td>"} {
+			if !strings.Contains(body, expected) {
+				t.Errorf("expected %q in body:\n%s", expected, body)
+			}
+		}
+	})
+	t.Run("unknown requests are not found", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		rec.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?id=1", nil))
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected status 404, got %d", w.Code)
+		}
+	})
+	t.Run("the viewer isn't recorded", func(t *testing.T) {
+		rec.Middleware(rec).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		if len(rec.Requests()) != 2 || rec.Requests()[0].ID != 3 {
+			t.Error("expected the viewer request not to be recorded")
+		}
+	})
+}
+
+func TestFlatten(t *testing.T) {
+	trace := templ.NewRenderTrace()
+	child := &templ.TraceNode{Name: "child", Start: 25, Duration: 50}
+	trace.Root.Children = []*templ.TraceNode{
+		{Name: "page", Start: 0, Duration: 100, Children: []*templ.TraceNode{child}},
+	}
+	bars := flatten(trace)
+	if len(bars) != 2 {
+		t.Fatalf("expected 2 bars, got %d", len(bars))
+	}
+	if bars[0].Left != 0 || bars[0].Width != 100 || bars[0].Depth != 0 {
+		t.Errorf("unexpected page bar %+v", bars[0])
+	}
+	if bars[1].Left != 25 || bars[1].Width != 50 || bars[1].Depth != 1 {
+		t.Errorf("unexpected child bar %+v", bars[1])
+	}
+}
@@ -0,0 +1,87 @@
+package templ
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+)
+
+// tracedComponent renders like a component generated in watch mode.
+func tracedComponent(name string, body func(ctx context.Context, w *bytes.Buffer) error) Component {
+	return ComponentFunc(func(ctx context.Context, w io.Writer) (err error) {
+		buf, isBuffer := w.(*bytes.Buffer)
+		if !isBuffer {
+			buf = GetBuffer()
+			defer ReleaseBuffer(buf)
+		}
+		ctx = InitializeContext(ctx)
+		ctx, end := TraceStart(ctx, name, name+".templ:1", buf)
+		if err = body(ctx, buf); err != nil {
+			return err
+		}
+		end()
+		if !isBuffer {
+			_, err = buf.WriteTo(w)
+		}
+		return err
+	})
+}
+
+func TestRenderTrace(t *testing.T) {
+	once := NewOnceHandle(WithComponent(Raw("<script></script>")))
+	child := tracedComponent("child", func(ctx context.Context, w *bytes.Buffer) error {
+		w.WriteString("<p>child</p>")
+		return once.Once().Render(ctx, w)
+	})
+	page := tracedComponent("page", func(ctx context.Context, w *bytes.Buffer) error {
+		w.WriteString("<main>")
+		if err := child.Render(ctx, w); err != nil {
+			return err
+		}
+		if err := child.Render(ctx, w); err != nil {
+			return err
+		}
+		w.WriteString("</main>")
+		return nil
+	})
+
+	t.Run("components are not traced without a trace in the context", func(t *testing.T) {
+		if err := page.Render(context.Background(), io.Discard); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+	t.Run("components are added to the trace in the context", func(t *testing.T) {
+		trace := NewRenderTrace()
+		ctx := WithRenderTrace(context.Background(), trace)
+		if GetRenderTrace(ctx) != trace {
+			t.Fatal("expected the trace to be returned from the context")
+		}
+		if err := page.Render(ctx, io.Discard); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if trace.Count() != 3 {
+			t.Fatalf("expected 3 components, got %d", trace.Count())
+		}
+		root := trace.Root.Children[0]
+		if root.Name != "page" || root.Location != "page.templ:1" {
+			t.Errorf("unexpected root %s at %s", root.Name, root.Location)
+		}
+		if expected := len("<main><p>child</p><script></script><p>child</p></main>"); root.Bytes != expected {
+			t.Errorf("expected %d bytes, got %d", expected, root.Bytes)
+		}
+		if len(root.Children) != 2 {
+			t.Fatalf("expected 2 children, got %d", len(root.Children))
+		}
+		first, second := root.Children[0], root.Children[1]
+		if first.Bytes != len("<p>child</p><script></script>") || first.CacheHits != 0 {
+			t.Errorf("unexpected first child: %d bytes, %d cache hits", first.Bytes, first.CacheHits)
+		}
+		if second.Bytes != len("<p>child</p>") || second.CacheHits != 1 {
+			t.Errorf("unexpected second child: %d bytes, %d cache hits", second.Bytes, second.CacheHits)
+		}
+		if second.Start < first.Start {
+			t.Errorf("expected the second child to start after the first")
+		}
+	})
+}
@@ -0,0 +1,154 @@
+package templ
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+type twoPassContextKeyType int
+
+const twoPassContextKey = twoPassContextKeyType(0)
+
+// twoPassState holds the values collected during the first pass of a TwoPass render.
+type twoPassState struct {
+	m          sync.Mutex
+	collecting bool
+	values     map[int64][]any
+}
+
+func getTwoPassState(ctx context.Context) *twoPassState {
+	s, _ := ctx.Value(twoPassContextKey).(*twoPassState)
+	return s
+}
+
+// TwoPass returns a component that renders c twice. The output of the first,
+// collect, pass is discarded, and the second, emit, pass is written to w.
+//
+// Values added to a CollectKey during the collect pass can be read during the
+// emit pass, by components that are rendered before the components that added
+// them, e.g. a table of contents can list the headings of a page.
+//
+// Within TwoPass, tags registered with HeadTitle, HeadMeta, HeadLink and
+// HeadScript are written by HeadContents without buffering the output.
+//
+// Components are rendered twice, so they should not have side effects, or
+// should check IsCollectPass.
+func TwoPass(c Component) Component {
+	return ComponentFunc(func(ctx context.Context, w io.Writer) (err error) {
+		if getTwoPassState(ctx) != nil {
+			return c.Render(ctx, w)
+		}
+		state := &twoPassState{collecting: true, values: map[int64][]any{}}
+		hm := getHeadManager(ctx)
+		ownHead := hm == nil
+		if ownHead {
+			hm = &headManager{}
+		}
+		pass := func() context.Context {
+			ctx := context.WithValue(cloneContextValue(ctx), twoPassContextKey, state)
+			return context.WithValue(ctx, headContextKey, hm)
+		}
+
+		// The collect pass isn't included in render traces, render hooks, or render stats.
+		collectCtx := context.WithValue(pass(), renderTraceContextKey{}, nil)
+		collectCtx = context.WithValue(collectCtx, renderHooksContextKey{}, nil)
+		_, cv := getContext(collectCtx)
+		cv.stats = nil
+		if err = c.Render(collectCtx, io.Discard); err != nil {
+			return err
+		}
+
+		state.m.Lock()
+		state.collecting = false
+		state.m.Unlock()
+		if ownHead {
+			hm.freeze()
+		}
+		return c.Render(pass(), w)
+	})
+}
+
+// cloneContextValue returns a context with a copy of the internal state used
+// during rendering, so that components rendered once per context, and CSS
+// classes and scripts, are rendered by each pass.
+func cloneContextValue(ctx context.Context) context.Context {
+	_, v := getContext(ctx)
+	clone := &contextValue{
+		children: v.children,
+		nonce:    v.nonce,
+		stats:    v.stats,
+	}
+	if v.ss != nil {
+		clone.ss = make(map[string]struct{}, len(v.ss))
+		for k := range v.ss {
+			clone.ss[k] = struct{}{}
+		}
+	}
+	if v.onceHandles != nil {
+		clone.onceHandles = make(map[*OnceHandle]struct{}, len(v.onceHandles))
+		for k := range v.onceHandles {
+			clone.onceHandles[k] = struct{}{}
+		}
+	}
+	return context.WithValue(ctx, contextKey, clone)
+}
+
+// IsCollectPass returns true during the collect pass of a TwoPass render,
+// when output is discarded.
+func IsCollectPass(ctx context.Context) bool {
+	s := getTwoPassState(ctx)
+	if s == nil {
+		return false
+	}
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.collecting
+}
+
+// collectKeyIndex is used to identify unique collect keys in a program run.
+var collectKeyIndex int64
+
+// CollectKey identifies values of type T that are collected during the
+// collect pass of a TwoPass render.
+type CollectKey[T any] struct {
+	// id is used to identify the key, see OnceHandle.
+	id int64
+}
+
+// NewCollectKey creates a key that's used to collect values during a TwoPass render.
+func NewCollectKey[T any]() *CollectKey[T] {
+	return &CollectKey[T]{
+		id: atomic.AddInt64(&collectKeyIndex, 1),
+	}
+}
+
+// Add records the value during the collect pass of a TwoPass render. During
+// the emit pass, and outside of TwoPass, it does nothing.
+func (k *CollectKey[T]) Add(ctx context.Context, v T) {
+	s := getTwoPassState(ctx)
+	if s == nil {
+		return
+	}
+	s.m.Lock()
+	defer s.m.Unlock()
+	if s.collecting {
+		s.values[k.id] = append(s.values[k.id], v)
+	}
+}
+
+// Values returns the values added during the collect pass, in the order that
+// they were added. Outside of TwoPass, it returns nil.
+func (k *CollectKey[T]) Values(ctx context.Context) (values []T) {
+	s := getTwoPassState(ctx)
+	if s == nil {
+		return nil
+	}
+	s.m.Lock()
+	defer s.m.Unlock()
+	for _, v := range s.values[k.id] {
+		values = append(values, v.(T))
+	}
+	return values
+}
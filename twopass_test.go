@@ -0,0 +1,91 @@
+package templ_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/a-h/templ"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTwoPass(t *testing.T) {
+	headings := templ.NewCollectKey[string]()
+	heading := func(text string) templ.Component {
+		return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			headings.Add(ctx, text)
+			_, err := fmt.Fprintf(w, "<h2>%s</h2>", templ.EscapeString(text))
+			return err
+		})
+	}
+	toc := templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		_, err := fmt.Fprintf(w, "<nav>%s</nav>", strings.Join(headings.Values(ctx), ","))
+		return err
+	})
+	once := templ.NewOnceHandle(templ.WithComponent(templ.Raw("<script>once</script>")))
+	var passes []bool
+	page := templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		passes = append(passes, templ.IsCollectPass(ctx))
+		for _, c := range []templ.Component{
+			templ.HeadContents(),
+			toc,
+			once.Once(),
+			heading("One"),
+			templ.HeadTitle("Page"),
+			heading("Two"),
+			once.Once(),
+		} {
+			if err := c.Render(ctx, w); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	t.Run("values collected in the first pass are available in the second", func(t *testing.T) {
+		passes = nil
+		w := new(bytes.Buffer)
+		if err := templ.TwoPass(page).Render(context.Background(), w); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := `<title>Page</title><nav>One,Two</nav><script>once</script><h2>One</h2><h2>Two</h2>`
+		if diff := cmp.Diff(expected, w.String()); diff != "" {
+			t.Errorf("unexpected output (-want +got):\n%s", diff)
+		}
+		if diff := cmp.Diff([]bool{true, false}, passes); diff != "" {
+			t.Errorf("unexpected passes (-want +got):\n%s", diff)
+		}
+	})
+	t.Run("outside of TwoPass, nothing is collected", func(t *testing.T) {
+		passes = nil
+		w := new(bytes.Buffer)
+		if err := page.Render(templ.InitializeContext(context.Background()), w); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := `<nav></nav><script>once</script><h2>One</h2><title>Page</title><h2>Two</h2>`
+		if diff := cmp.Diff(expected, w.String()); diff != "" {
+			t.Errorf("unexpected output (-want +got):\n%s", diff)
+		}
+		if diff := cmp.Diff([]bool{false}, passes); diff != "" {
+			t.Errorf("unexpected passes (-want +got):\n%s", diff)
+		}
+	})
+	t.Run("the collect pass is not traced", func(t *testing.T) {
+		trace := templ.NewRenderTrace()
+		ctx := templ.WithRenderTrace(context.Background(), trace)
+		traced := templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+			_, end := templ.TraceStart(ctx, "traced", "", new(bytes.Buffer))
+			end()
+			return nil
+		})
+		if err := templ.TwoPass(traced).Render(ctx, io.Discard); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if trace.Count() != 1 {
+			t.Errorf("expected 1 traced component, got %d", trace.Count())
+		}
+	})
+}
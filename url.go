@@ -1,6 +1,9 @@
 package templ
 
-import "strings"
+import (
+	"net/url"
+	"strings"
+)
 
 // FailedSanitizationURL is returned if a URL fails sanitization checks.
 const FailedSanitizationURL = SafeURL("about:invalid#TemplFailedSanitizationURL")
@@ -18,3 +21,16 @@ func URL(s string) SafeURL {
 
 // SafeURL is a URL that has been sanitized.
 type SafeURL string
+
+// URLPathSegment escapes s so that it can be placed within the path of a URL,
+// e.g. href="/users/{ id }". It's used by generated code.
+func URLPathSegment(s string) string {
+	return url.PathEscape(s)
+}
+
+// URLQueryValue escapes s so that it can be placed within the query or
+// fragment of a URL, e.g. href="/search?q={ query }". It's used by generated
+// code.
+func URLQueryValue(s string) string {
+	return url.QueryEscape(s)
+}
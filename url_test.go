@@ -55,3 +55,25 @@ func BenchmarkURL(b *testing.B) {
 		}
 	}
 }
+
+func TestURLParts(t *testing.T) {
+	tests := []struct {
+		name     string
+		escape   func(string) string
+		input    string
+		expected string
+	}{
+		{name: "path segments can't contain slashes", escape: URLPathSegment, input: "../admin", expected: "..%2Fadmin"},
+		{name: "path segments can't start a query", escape: URLPathSegment, input: "a?b=c", expected: "a%3Fb=c"},
+		{name: "query values can't add parameters", escape: URLQueryValue, input: "a&b=c", expected: "a%26b%3Dc"},
+		{name: "spaces in query values are encoded as +", escape: URLQueryValue, input: "a b", expected: "a+b"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := tt.escape(tt.input); actual != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, actual)
+			}
+		})
+	}
+}